@@ -1,44 +1,261 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"math"
+	"net"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/DhirajZope/lazytodo/internal/config"
+	"github.com/DhirajZope/lazytodo/internal/gitsync"
+	"github.com/DhirajZope/lazytodo/internal/models"
+	"github.com/DhirajZope/lazytodo/internal/server"
 	"github.com/DhirajZope/lazytodo/internal/storage"
 	"github.com/DhirajZope/lazytodo/internal/ui"
+	"github.com/DhirajZope/lazytodo/pkg/lazytodo"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
 )
 
 func main() {
+	var opts ui.Options
+	command, file, addr, openList, openTask := "", "", "", "", ""
+	listName, jsonOutput, dryRun, byUpdate := "", false, false, false
+	since := ""
+
 	// Check for command line arguments
-	if len(os.Args) > 1 {
-		switch os.Args[1] {
-		case "--info", "-i":
-			showStorageInfo()
-			return
-		case "--migrate", "-m":
-			runMigration()
-			return
-		case "--help", "-h":
-			showHelp()
-			return
-		case "--version", "-v":
-			showVersion()
-			return
+	args := os.Args[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "--readonly", "-r":
+			opts.ReadOnly = true
+		case "--ascii":
+			opts.ASCII = true
+		case "--light":
+			opts.Light = true
+		case "--yes", "-y":
+			opts.MigrateJSON = true
+		case "--info", "-i", "--migrate", "-m", "--help", "-h", "--version", "-v", "--encrypt", "--decrypt", "--sync", "--stale", "--maintenance", "--activity", "--migration-status", "--config-path", "--check-config", "list":
+			command = arg
+		case "--list":
+			if i+1 >= len(args) {
+				fmt.Println("Option --list needs the name of a list")
+				os.Exit(1)
+			}
+			i++
+			listName = args[i]
+		case "--since":
+			if i+1 >= len(args) {
+				fmt.Println("Option --since needs a time span, such as 24h or 7d")
+				os.Exit(1)
+			}
+			i++
+			since = args[i]
+		case "--json":
+			jsonOutput = true
+		case "--dry-run":
+			dryRun = true
+		case "--by-update":
+			byUpdate = true
+		case "--export", "--import":
+			if i+1 >= len(args) {
+				fmt.Printf("Option %s needs a file name, or - for standard input/output\n", arg)
+				os.Exit(1)
+			}
+			command = arg
+			i++
+			file = args[i]
+		case "--backup", "--restore":
+			if i+1 >= len(args) {
+				fmt.Printf("Option %s needs the name of an archive, such as lazytodo-backup.tar.gz\n", arg)
+				os.Exit(1)
+			}
+			command = arg
+			i++
+			file = args[i]
+		case "--open":
+			if i+1 >= len(args) {
+				fmt.Println("Option --open needs the name of a list")
+				os.Exit(1)
+			}
+			i++
+			openList = args[i]
+		case "--task":
+			if i+1 >= len(args) {
+				fmt.Println("Option --task needs the ID of a task, as printed by lazytodo list")
+				os.Exit(1)
+			}
+			i++
+			openTask = args[i]
+		case "--profile":
+			if i+1 >= len(args) {
+				fmt.Println("Option --profile needs the name of a profile, such as work")
+				os.Exit(1)
+			}
+			i++
+			if err := storage.SetProfile(args[i]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		case "--serve":
+			if i+1 >= len(args) {
+				fmt.Println("Option --serve needs an address to listen on, such as :8080")
+				os.Exit(1)
+			}
+			command = arg
+			i++
+			addr = args[i]
 		default:
-			fmt.Printf("Unknown option: %s\n", os.Args[1])
+			fmt.Printf("Unknown option: %s\n", arg)
 			showHelp()
 			os.Exit(1)
 		}
 	}
 
-	// Initialize the model
-	model, err := ui.NewModel()
-	if err != nil {
-		log.Fatalf("Failed to initialize application: %v", err)
+	if listName != "" && command != "list" {
+		fmt.Println("Option --list only works with the list command")
+		os.Exit(1)
+	}
+	if jsonOutput && command != "list" && command != "--info" && command != "-i" {
+		fmt.Println("Option --json only works with the list command and --info")
+		os.Exit(1)
+	}
+	if dryRun && command != "--import" && command != "--migrate" && command != "-m" {
+		fmt.Println("Option --dry-run only works with --import and --migrate")
+		os.Exit(1)
+	}
+	if byUpdate && command != "--stale" {
+		fmt.Println("Option --by-update only works with --stale")
+		os.Exit(1)
+	}
+	if since != "" && command != "--activity" {
+		fmt.Println("Option --since only works with --activity")
+		os.Exit(1)
+	}
+
+	switch command {
+	case "--help", "-h":
+		showHelp()
+		return
+	case "--version", "-v":
+		showVersion()
+		return
+	case "--config-path":
+		fmt.Println(config.Path())
+		return
+	case "--check-config":
+		runCheckConfig()
+		return
+	}
+
+	// The config file may move the data directory, so it is read before storage is touched
+	cfg := loadConfig()
+	opts.Config = cfg
+
+	switch command {
+	case "--encrypt":
+		runEncrypt()
+		return
+	case "--decrypt":
+		runDecrypt()
+		return
+	case "--backup":
+		runBackup(file)
+		return
+	case "--restore":
+		runRestore(file, opts.MigrateJSON, os.Stdin)
+		return
+	}
+
+	// An encrypted database cannot be opened without its passphrase
+	if storage.EncryptionEnabled() {
+		passphrase, err := readPassphrase(false)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Passphrase = passphrase
+	}
+
+	storageOpts := storage.Options{ReadOnly: opts.ReadOnly, Passphrase: opts.Passphrase, MigrateJSON: opts.MigrateJSON}
+
+	// Only the TUI can ask what to do with v1.x JSON data; --migrate is the answer itself
+	if (command != "" || openList != "" || openTask != "") && command != "--migrate" && command != "-m" {
+		requireMigrationChoice(storageOpts)
+	}
+
+	switch command {
+	case "--info", "-i":
+		showStorageInfo(storageOpts, jsonOutput)
+		return
+	case "--migrate", "-m":
+		runMigration(storageOpts, dryRun)
+		return
+	case "--export":
+		runExport(storageOpts, file)
+		return
+	case "--import":
+		runImport(storageOpts, file, dryRun)
+		return
+	case "--sync":
+		runSync(storageOpts)
+		return
+	case "--stale":
+		runStale(storageOpts, byUpdate)
+		return
+	case "--maintenance":
+		runMaintenance(storageOpts)
+		return
+	case "--activity":
+		runActivity(storageOpts, since)
+		return
+	case "--migration-status":
+		runMigrationStatus(storageOpts)
+		return
+	case "--serve":
+		runServe(storageOpts, addr)
+		return
+	case "list":
+		runList(storageOpts, listName, jsonOutput)
+		return
 	}
 
+	if openList != "" {
+		opts.OpenListID = resolveOpenList(storageOpts, openList)
+	}
+	opts.OpenTaskID = openTask
+
+	// Fall back to ascii markers on terminals that can't draw emoji
+	if !opts.ASCII {
+		opts.ASCII = ui.DetectASCII()
+	}
+
+	// Draw the palette for a light background when the terminal has one;
+	// the config file's theme counts unless the environment decides
+	if !opts.Light {
+		if _, set := os.LookupEnv(ui.LightEnv); !set && cfg.Theme != "" && cfg.Theme != "auto" {
+			opts.Light = cfg.Theme == "light"
+		} else {
+			opts.Light = ui.DetectLight()
+		}
+	}
+
+	// Initialize the model; data is loaded once the program starts
+	model := ui.NewModel(opts)
+
 	// Create the program
 	program := tea.NewProgram(
 		model,
@@ -47,62 +264,241 @@ func main() {
 	)
 
 	// Run the program
-	if _, err := program.Run(); err != nil {
+	_, err := program.Run()
+
+	// Closing storage writes out an encrypted database
+	if closeErr := model.Close(); closeErr != nil {
+		fmt.Printf("Error closing storage: %v\n", closeErr)
+		os.Exit(1)
+	}
+
+	if err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func showStorageInfo() {
-	fmt.Println("🎯 LazyTodo - Storage Information")
-	fmt.Println("===============================")
+// configKnown returns the names of other packages a config file is checked against
+func configKnown() config.Known {
+	return config.Known{IconSets: ui.IconSetNames, Actions: ui.KeyActions()}
+}
 
-	// Try to initialize storage to get info
-	storageInstance, err := storage.NewWithMigration()
+// loadConfig reads the config file, exiting with its problems if it has any,
+// and moves the data directory to the one it names unless $LAZYTODO_HOME does
+func loadConfig() *config.Config {
+	cfg, err := config.Load(config.Path(), configKnown())
 	if err != nil {
-		fmt.Printf("Error initializing storage: %v\n", err)
+		fmt.Printf("Error in config file:\n%v\n", err)
+		fmt.Println("Run lazytodo --check-config after fixing it to check it again.")
 		os.Exit(1)
 	}
-	defer storageInstance.Close()
+	if cfg.DataDir != "" && os.Getenv(storage.HomeEnv) == "" {
+		storage.SetDataDir(cfg.DataDir)
+	}
+	return cfg
+}
 
-	fmt.Printf("Storage Backend: %s\n", storage.GetStorageInfo(storageInstance))
+// runCheckConfig reports every problem of the config file with its line
+func runCheckConfig() {
+	path := config.Path()
+	if path == "" {
+		fmt.Println("Error: no config file location; set " + config.Env + " to choose one")
+		os.Exit(1)
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		fmt.Printf("No config file at %s; the defaults are used\n", path)
+		return
+	}
+	if _, err := config.Load(path, configKnown()); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s is valid\n", path)
+}
+
+// requireMigrationChoice exits with an explanation when v1.x JSON data has no
+// database yet and --yes did not agree to migrating it
+func requireMigrationChoice(opts storage.Options) {
+	if opts.ReadOnly || opts.MigrateJSON || !storage.MigrationPending() {
+		return
+	}
+
+	fmt.Printf("Error: %v\n", storage.ErrMigrationPending)
+	fmt.Printf("Found %s, and there is no database yet.\n", storage.LegacyJSONPath())
+	fmt.Println("Run lazytodo without options to choose whether to migrate it or keep using JSON,")
+	fmt.Println("or pass --yes to migrate it to the database now.")
+	os.Exit(1)
+}
+
+// storageFailed exits with the error opening storage ran into, and what to do
+// about a damaged database
+func storageFailed(w io.Writer, err error) {
+	fmt.Fprintf(w, "Error initializing storage: %v\n", err)
+	if errors.Is(err, storage.ErrDatabaseCorrupt) {
+		fmt.Fprintln(w, "Run lazytodo without options to move the damaged file aside and start with an empty database;")
+		fmt.Fprintln(w, "lazytodo --import FILE then merges an export into it.")
+		if backups := storage.Backups(); len(backups) > 0 {
+			fmt.Fprintln(w, "Backups of the data found in the data directory:")
+			for _, path := range backups {
+				fmt.Fprintf(w, "  %s\n", path)
+			}
+		}
+	}
+	os.Exit(1)
+}
+
+// resolveOpenList finds the list given with --open before the TUI starts, so
+// a mistyped name is reported on the command line with suggestions
+func resolveOpenList(opts storage.Options, name string) string {
+	storageInstance, err := storage.NewWithMigration(opts)
+	if err != nil {
+		storageFailed(os.Stdout, err)
+	}
+	defer storageInstance.Close()
 
-	// Load data to show statistics
 	app, err := storageInstance.Load()
 	if err != nil {
 		fmt.Printf("Error loading data: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Todo Lists: %d\n", len(app.TodoLists))
+	todoList, err := storage.ResolveList(app, name)
+	if err != nil {
+		fmt.Printf("Cannot open list: %v\n", err)
+		os.Exit(1)
+	}
+	return todoList.ID
+}
 
-	totalTasks := 0
-	completedTasks := 0
-	for _, list := range app.TodoLists {
-		totalTasks += len(list.Tasks)
-		completedTasks += list.GetCompletedCount()
+func showStorageInfo(opts storage.Options, asJSON bool) {
+	// Progress messages must not end up in the JSON
+	errOut := os.Stdout
+	if asJSON {
+		errOut = os.Stderr
+		opts.Output = os.Stderr
+	}
+
+	storageInstance, err := storage.NewWithMigration(opts)
+	if err != nil {
+		storageFailed(errOut, err)
+	}
+	defer storageInstance.Close()
+
+	info, err := storage.CollectInfo(storageInstance, time.Now())
+	if err != nil {
+		fmt.Fprintf(errOut, "Error loading data: %v\n", err)
+		os.Exit(1)
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(info); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing info: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Println("🎯 LazyTodo - Storage Information")
+	fmt.Println("===============================")
+	fmt.Printf("Storage Backend: %s\n", storage.GetStorageInfo(storageInstance))
+	fmt.Printf("Profile: %s\n", info.Profile)
+	if info.ReadOnly {
+		fmt.Println("Mode: read-only")
+	}
+	if info.Encrypted {
+		fmt.Printf("Encrypted File: %s\n", info.Path)
 	}
+	fmt.Printf("File Size: %s\n", models.FormatBytes(info.SizeBytes))
+	if info.SchemaVersion > 0 {
+		fmt.Printf("Schema Version: %d\n", info.SchemaVersion)
+	}
+	if info.ModifiedAt != nil {
+		fmt.Printf("Last Modified: %s\n", info.ModifiedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+
+	totals := info.Totals
+	fmt.Printf("\nTodo Lists: %d\n", len(info.Lists))
+	fmt.Printf("Total Tasks: %d\n", totals.Total)
+	fmt.Printf("Completed Tasks: %d\n", totals.Completed)
+	if totals.Total > 0 {
+		fmt.Printf("Completion Rate: %.1f%%\n", float64(totals.Completed)/float64(totals.Total)*100)
+	}
+	fmt.Printf("Overdue Tasks: %d\n", totals.Overdue)
+	fmt.Printf("Due This Week: %d\n", totals.DueThisWeek)
+
+	if len(info.Lists) > 0 {
+		// Names are padded by the columns they take, which counts wide characters right
+		names := make([]string, len(info.Lists))
+		width := len("List")
+		for i, list := range info.Lists {
+			names[i] = ansi.Truncate(models.CleanText(list.Name, false), 32, "…")
+			width = max(width, ansi.StringWidth(names[i]))
+		}
+		pad := func(name string) string {
+			return name + strings.Repeat(" ", width-ansi.StringWidth(name))
+		}
 
-	fmt.Printf("Total Tasks: %d\n", totalTasks)
-	fmt.Printf("Completed Tasks: %d\n", completedTasks)
-	if totalTasks > 0 {
-		fmt.Printf("Completion Rate: %.1f%%\n", float64(completedTasks)/float64(totalTasks)*100)
+		fmt.Printf("\n  %s  %6s  %9s  %7s  %9s\n", pad("List"), "Total", "Completed", "Overdue", "This Week")
+		for i, list := range info.Lists {
+			fmt.Printf("  %s  %6d  %9d  %7d  %9d\n", pad(names[i]), list.Total, list.Completed, list.Overdue, list.DueThisWeek)
+		}
+	}
+
+	// Estimated against actual time, for lists that track any
+	header := false
+	for _, list := range info.Lists {
+		estimate := time.Duration(list.EstimateSeconds) * time.Second
+		spent := time.Duration(list.SpentSeconds) * time.Second
+		if estimate == 0 && spent == 0 {
+			continue
+		}
+		if !header {
+			fmt.Printf("\nTime Tracking (spent / estimated):\n")
+			header = true
+		}
+		fmt.Printf("  %s: %s / %s", list.Name, models.FormatDuration(spent), models.FormatDuration(estimate))
+		if remaining := time.Duration(list.RemainingSeconds) * time.Second; remaining > 0 {
+			fmt.Printf(", %s remaining", models.FormatDuration(remaining))
+		}
+		fmt.Println()
 	}
 
 	fmt.Printf("\nSettings:\n")
-	fmt.Printf("  Reminder Minutes: %d\n", app.Settings.ReminderMinutes)
-	fmt.Printf("  Show Completed: %v\n", app.Settings.ShowCompleted)
-	fmt.Printf("  Auto Save: %v\n", app.Settings.AutoSave)
+	fmt.Printf("  Reminder Minutes: %d\n", info.Settings.ReminderMinutes)
+	fmt.Printf("  Show Completed: %v\n", info.Settings.ShowCompleted)
+	fmt.Printf("  Auto Save: %v\n", info.Settings.AutoSave)
 }
 
-func runMigration() {
+func runMigration(opts storage.Options, dryRun bool) {
 	fmt.Println("🎯 LazyTodo - Manual Migration")
 	fmt.Println("=============================")
 
-	dbStorage, err := storage.NewDatabase()
+	// Show what migrating would add, and stop there on a dry run
+	if storage.HasLegacyJSON() {
+		plan, err := storage.PlanMigration(opts)
+		if err != nil {
+			fmt.Printf("Migration failed: %v\n", err)
+			os.Exit(1)
+		}
+		printPlan(plan)
+		if dryRun {
+			fmt.Println("Dry run: nothing was migrated.")
+			return
+		}
+		if !opts.MigrateJSON && !confirmApply(os.Stdin, "Migrate these changes to the database?") {
+			fmt.Println("Migration cancelled.")
+			return
+		}
+	} else if dryRun {
+		fmt.Printf("No JSON data to migrate: %s does not exist.\n", storage.LegacyJSONPath())
+		return
+	}
+
+	dbStorage, err := storage.NewDatabase(opts)
 	if err != nil {
-		fmt.Printf("Error creating database storage: %v\n", err)
-		os.Exit(1)
+		storageFailed(os.Stdout, err)
 	}
 	defer dbStorage.Close()
 
@@ -114,21 +510,755 @@ func runMigration() {
 	fmt.Println("Migration completed successfully!")
 }
 
+func runExport(opts storage.Options, file string) {
+	// Progress messages must not end up in an export written to stdout
+	opts.Output = os.Stderr
+
+	storageInstance, err := storage.NewWithMigration(opts)
+	if err != nil {
+		storageFailed(os.Stderr, err)
+	}
+	defer storageInstance.Close()
+
+	data, err := storage.Export(storageInstance)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	if file == "-" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Exported to %s\n", file)
+}
+
+func runImport(opts storage.Options, file string, dryRun bool) {
+	fmt.Println("🎯 LazyTodo - Import")
+	fmt.Println("===================")
+
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		fmt.Printf("Error reading %s: %v\n", file, err)
+		os.Exit(1)
+	}
+
+	incoming, err := storage.ParseExport(data)
+	if err != nil {
+		fmt.Printf("Import failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Show what the merge would change, and stop there on a dry run
+	existing, err := storage.LoadForPreview(opts)
+	if err != nil {
+		storageFailed(os.Stdout, err)
+	}
+	plan := storage.PlanImport(existing, incoming)
+	printPlan(plan)
+	if dryRun {
+		fmt.Println("Dry run: nothing was imported.")
+		return
+	}
+	if !plan.Changed() {
+		fmt.Println("Import completed: no changes")
+		return
+	}
+	// Answers cannot come from stdin when the import does
+	answers := io.Reader(os.Stdin)
+	if file == "-" {
+		answers = nil
+	}
+	if !opts.MigrateJSON && !confirmApply(answers, "Import these changes?") {
+		fmt.Println("Import cancelled.")
+		return
+	}
+
+	storageInstance, err := storage.NewWithMigration(opts)
+	if err != nil {
+		storageFailed(os.Stdout, err)
+	}
+	defer storageInstance.Close()
+
+	app, err := storageInstance.Load()
+	if err != nil {
+		fmt.Printf("Error loading data: %v\n", err)
+		os.Exit(1)
+	}
+
+	result, err := storageInstance.Merge(app, incoming)
+	if err != nil {
+		fmt.Printf("Import failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Import completed: %s\n", result)
+}
+
+// printPlan prints what an import or migration would change, diff-style: a
+// line per change, + for additions in green and ~ for updates in yellow on a
+// terminal, then the counts
+func printPlan(plan storage.ImportPlan) {
+	color := term.IsTerminal(os.Stdout.Fd())
+	for _, change := range plan.Changes {
+		mark, code := "+", "32"
+		if change.Kind == storage.PlanUpdate {
+			mark, code = "~", "33"
+		}
+
+		line := fmt.Sprintf("%s %s '%s'", mark, change.What, change.Name)
+		if change.List != "" {
+			line += " in '" + change.List + "'"
+		}
+		if change.Details != "" {
+			line += " (" + change.Details + ")"
+		}
+		if color {
+			line = "\x1b[" + code + "m" + line + "\x1b[0m"
+		}
+		fmt.Println(line)
+	}
+
+	fmt.Printf("Changes: %s\n", plan.Summary())
+}
+
+// confirmApply asks on in whether to go ahead with the changes just printed.
+// Without a terminal to ask on, as in scripts or with input piped in, it does
+// not go ahead: those agree up front with --yes.
+func confirmApply(in io.Reader, question string) bool {
+	if f, ok := in.(*os.File); !ok || !term.IsTerminal(f.Fd()) {
+		fmt.Printf("%s Not without a terminal to ask on; re-run with --yes to go ahead.\n", question)
+		return false
+	}
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// listedTask is a task as printed by the list command
+type listedTask struct {
+	ID        string     `json:"id"`
+	ListID    string     `json:"list_id"`
+	List      string     `json:"list"`
+	Title     string     `json:"title"`
+	Completed bool       `json:"completed"`
+	Priority  string     `json:"priority"`
+	Deadline  *time.Time `json:"deadline,omitempty"`
+	Starred   bool       `json:"starred"`
+}
+
+// runList prints the tasks of every list, or of the list called name, for
+// scripts: tab-separated lines as writeTaskLines writes them, or a JSON array
+// with asJSON
+func runList(opts storage.Options, name string, asJSON bool) {
+	// Progress messages must not end up in the output
+	client, err := lazytodo.Open("", lazytodo.Options{
+		ReadOnly:    opts.ReadOnly,
+		Passphrase:  opts.Passphrase,
+		MigrateJSON: opts.MigrateJSON,
+		Output:      os.Stderr,
+	})
+	if err != nil {
+		storageFailed(os.Stderr, err)
+	}
+	defer client.Close()
+
+	lists := client.Lists()
+	if name != "" {
+		todoList, err := client.FindList(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot list tasks: %v\n", err)
+			os.Exit(1)
+		}
+		lists = []lazytodo.List{todoList}
+	}
+
+	tasks := []listedTask{}
+	for _, todoList := range lists {
+		listTasks, err := client.Tasks(todoList.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading tasks: %v\n", err)
+			os.Exit(1)
+		}
+		for _, task := range listTasks {
+			tasks = append(tasks, listedTask{
+				ID:        task.ID,
+				ListID:    todoList.ID,
+				List:      todoList.Name,
+				Title:     task.Title,
+				Completed: task.Completed,
+				Priority:  task.Priority.String(),
+				Deadline:  task.Deadline,
+				Starred:   task.Starred,
+			})
+		}
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(tasks); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing tasks: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := writeTaskLines(os.Stdout, tasks); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing tasks: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// writeTaskLines writes one tab-separated line per task: id, list, title,
+// completed, priority and deadline, then the columns added since, starred and
+// list id. Scripts pick fields by position, so new columns only ever go at
+// the end.
+func writeTaskLines(w io.Writer, tasks []listedTask) error {
+	// Tabs and line breaks inside a field would split it
+	field := strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+	out := bufio.NewWriter(w)
+	for _, task := range tasks {
+		deadline := ""
+		if task.Deadline != nil {
+			deadline = task.Deadline.Format(models.DeadlineLayout)
+		}
+		fmt.Fprintf(out, "%s\t%s\t%s\t%t\t%s\t%s\t%t\t%s\n", task.ID, field.Replace(task.List), field.Replace(task.Title),
+			task.Completed, task.Priority, deadline, task.Starred, task.ListID)
+	}
+	return out.Flush()
+}
+
+// runStale prints the open tasks of every list that have sat longer than the
+// stale_days setting allows, oldest first. Age counts from creation or, with
+// byUpdate, from the last change.
+func runStale(opts storage.Options, byUpdate bool) {
+	storageInstance, err := storage.NewWithMigration(opts)
+	if err != nil {
+		storageFailed(os.Stdout, err)
+	}
+	defer storageInstance.Close()
+
+	app, err := storageInstance.Load()
+	if err != nil {
+		fmt.Printf("Error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	if app.Settings.StaleDays <= 0 {
+		fmt.Println("Stale tasks are turned off; set stale_days to a number of days to report them.")
+		return
+	}
+
+	type staleTask struct {
+		list string
+		task models.Task
+		age  int
+	}
+	now := time.Now()
+	var tasks []staleTask
+	for i := range app.TodoLists {
+		todoList := &app.TodoLists[i]
+		if err := storageInstance.LoadTasks(app, todoList.ID); err != nil {
+			fmt.Printf("Error loading tasks: %v\n", err)
+			os.Exit(1)
+		}
+		for _, task := range todoList.Tasks {
+			if age, stale := app.Settings.StaleAge(&task, now, byUpdate); stale {
+				tasks = append(tasks, staleTask{list: todoList.Name, task: task, age: age})
+			}
+		}
+	}
+
+	since := "created"
+	if byUpdate {
+		since = "last changed"
+	}
+	if len(tasks) == 0 {
+		fmt.Printf("No open task was %s more than %d days ago.\n", since, app.Settings.StaleDays)
+		return
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].age > tasks[j].age })
+	fmt.Printf("Open tasks %s more than %d days ago, oldest first:\n", since, app.Settings.StaleDays)
+	for _, stale := range tasks {
+		fmt.Printf("  %4dd  %s: %s\n", stale.age, stale.list, stale.task.Title)
+	}
+}
+
+// runActivity prints the changes of the activity log made within the span
+// given by since, e.g. "24h" or "7d", newest first
+func runActivity(opts storage.Options, since string) {
+	if since == "" {
+		since = "24h"
+	}
+	span, err := models.ParseOffset(since)
+	if err != nil || span <= 0 {
+		fmt.Printf("Invalid time span for --since: %q (e.g. 90m, 24h, 7d or 2w)\n", since)
+		os.Exit(1)
+	}
+
+	storageInstance, err := storage.NewWithMigration(opts)
+	if err != nil {
+		storageFailed(os.Stdout, err)
+	}
+	defer storageInstance.Close()
+
+	app, err := storageInstance.Load()
+	if err != nil {
+		fmt.Printf("Error loading data: %v\n", err)
+		os.Exit(1)
+	}
+
+	changes, err := storageInstance.ActivityLog(app, time.Now().Add(-span), math.MaxInt32)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(changes) == 0 {
+		fmt.Printf("Nothing changed in the last %s.\n", since)
+		return
+	}
+
+	fmt.Printf("Changes in the last %s, newest first:\n", since)
+	for _, change := range changes {
+		fmt.Printf("  %s  %s\n", change.At.Local().Format("2006-01-02 15:04"), change.Describe())
+	}
+}
+
+func runSync(opts storage.Options) {
+	fmt.Println("🎯 LazyTodo - Git Sync")
+	fmt.Println("=====================")
+
+	storageInstance, err := storage.NewWithMigration(opts)
+	if err != nil {
+		storageFailed(os.Stdout, err)
+	}
+	defer storageInstance.Close()
+
+	app, err := storageInstance.Load()
+	if err != nil {
+		fmt.Printf("Error loading data: %v\n", err)
+		os.Exit(1)
+	}
+
+	result, err := gitsync.Sync(storageInstance, app.Settings.SyncRepo)
+	if err != nil {
+		fmt.Printf("Sync failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Sync completed: %s\n", result)
+}
+
+// runServe serves the HTTP API until interrupted. Unless it is read-only it
+// takes the data directory's lock, so it does not run while a TUI session may
+// be writing the data.
+func runServe(opts storage.Options, addr string) {
+	fmt.Println("🎯 LazyTodo - API Server")
+	fmt.Println("=======================")
+
+	var lock *storage.Lock
+	if !opts.ReadOnly {
+		var err error
+		if lock, err = storage.AcquireLock(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			if errors.Is(err, storage.ErrInstanceRunning) {
+				fmt.Println("Quit it first, or serve the data read-only with --readonly.")
+			}
+			os.Exit(1)
+		}
+		defer lock.Release()
+	}
+
+	storageInstance, err := storage.NewWithMigration(opts)
+	if err != nil {
+		storageFailed(os.Stdout, err)
+	}
+	defer storageInstance.Close()
+
+	token := os.Getenv(server.TokenEnv)
+	if token == "" {
+		fmt.Printf("Warning: %s is not set, so requests are not authenticated\n", server.TokenEnv)
+	}
+
+	// Ctrl+C stops accepting requests and lets running ones finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = server.New(storageInstance, token).ListenAndServe(ctx, addr, func(bound net.Addr) {
+		fmt.Printf("Serving on http://%s (press Ctrl+C to stop)\n", bound)
+	})
+	if err != nil {
+		fmt.Printf("Server failed: %v\n", err)
+		storageInstance.Close()
+		lock.Release()
+		os.Exit(1)
+	}
+
+	fmt.Println("Server stopped")
+}
+
+func runEncrypt() {
+	fmt.Println("🎯 LazyTodo - Encrypt Database")
+	fmt.Println("=============================")
+
+	passphrase, err := readPassphrase(true)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	path, err := storage.EncryptDatabase(passphrase)
+	if err != nil {
+		fmt.Printf("Encryption failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Database encrypted: %s\n", path)
+	fmt.Printf("LazyTodo will ask for the passphrase on start (or read it from %s).\n", storage.PassphraseEnv)
+}
+
+func runDecrypt() {
+	fmt.Println("🎯 LazyTodo - Decrypt Database")
+	fmt.Println("=============================")
+
+	passphrase, err := readPassphrase(false)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	path, err := storage.DecryptDatabase(passphrase)
+	if err != nil {
+		fmt.Printf("Decryption failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Database decrypted: %s\n", path)
+}
+
+func runBackup(file string) {
+	manifest, err := storage.WriteBackup(file)
+	if err != nil {
+		fmt.Printf("Backup failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	database := manifest.Files[0]
+	fmt.Printf("Backed up %s (%s) to %s\n", database.Name, models.FormatBytes(database.Size), file)
+}
+
+// runRestore replaces the database with the one in a backup archive, after
+// showing what the archive holds and asking on in, unless yes is set; without
+// a terminal to ask on it only goes ahead with yes. The restored
+// database is opened afterwards, which checks it again and brings its schema
+// up to date.
+func runRestore(file string, yes bool, in io.Reader) {
+	fmt.Println("🎯 LazyTodo - Restore Backup")
+	fmt.Println("===========================")
+
+	manifest, err := storage.InspectBackup(file)
+	if err != nil {
+		fmt.Printf("Restore failed: %v\n", err)
+		os.Exit(1)
+	}
+	database := manifest.Files[0]
+	fmt.Printf("Backup made %s: %s (%s), checksum verified\n",
+		manifest.Created.Local().Format("2006-01-02 15:04"), database.Name, models.FormatBytes(database.Size))
+	if manifest.Encrypted() {
+		fmt.Println("The database is encrypted; it needs the passphrase it was backed up with.")
+	}
+
+	lock, err := storage.AcquireLock()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		if errors.Is(err, storage.ErrInstanceRunning) {
+			fmt.Println("Quit it first, then restore.")
+		}
+		os.Exit(1)
+	}
+	if lock != nil {
+		defer lock.Release()
+	}
+
+	if !yes && !confirmApply(in, "Replace the current data with this backup?") {
+		fmt.Println("Restore cancelled.")
+		return
+	}
+
+	asidePath, err := storage.RestoreBackup(file)
+	if err != nil {
+		fmt.Printf("Restore failed: %v\n", err)
+		if asidePath != "" {
+			fmt.Printf("The previous database was moved to %s\n", asidePath)
+		}
+		os.Exit(1)
+	}
+	if asidePath != "" {
+		fmt.Printf("The previous database was kept as %s\n", asidePath)
+	}
+
+	var opts storage.Options
+	if manifest.Encrypted() {
+		if opts.Passphrase, err = readPassphrase(false); err != nil {
+			fmt.Printf("Restored; the database could not be checked: %v\n", err)
+			return
+		}
+	}
+	storageInstance, err := storage.NewDatabase(opts)
+	if err != nil {
+		storageFailed(os.Stdout, err)
+	}
+	defer storageInstance.Close()
+
+	app, err := storageInstance.Load()
+	if err != nil {
+		fmt.Printf("Error loading the restored data: %v\n", err)
+		os.Exit(1)
+	}
+	tasks := 0
+	for _, list := range app.TodoLists {
+		tasks += list.GetTotalCount()
+	}
+	fmt.Printf("Restore completed: %d lists, %d tasks\n", len(app.TodoLists), tasks)
+}
+
+// readPassphrase returns the passphrase from the environment or prompts for it
+// without echo. With confirm set it is asked for twice, for setting a new one.
+func readPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(storage.PassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return "", fmt.Errorf("cannot prompt for a passphrase without a terminal; set %s", storage.PassphraseEnv)
+	}
+
+	passphrase, err := promptPassphrase("Passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errors.New("passphrase must not be empty")
+	}
+
+	if confirm {
+		again, err := promptPassphrase("Confirm passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", errors.New("passphrases do not match")
+		}
+	}
+
+	return passphrase, nil
+}
+
+func promptPassphrase(prompt string) (string, error) {
+	fmt.Print(prompt)
+	input, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return string(input), nil
+}
+
+// runMaintenance checks and compacts the database and prints what changed.
+// It takes the data directory's lock, so it does not run while a TUI session
+// may be writing the data.
+func runMaintenance(opts storage.Options) {
+	if opts.ReadOnly {
+		fmt.Println("Maintenance changes the database, so it cannot run read-only")
+		os.Exit(1)
+	}
+	lock, err := storage.AcquireLock()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		if errors.Is(err, storage.ErrInstanceRunning) {
+			fmt.Println("Quit it first, or run maintenance from its settings view.")
+		}
+		os.Exit(1)
+	}
+	if lock != nil {
+		defer lock.Release()
+	}
+
+	storageInstance, err := storage.NewWithMigration(opts)
+	if err != nil {
+		storageFailed(os.Stdout, err)
+	}
+	defer storageInstance.Close()
+
+	app, err := storageInstance.Load()
+	if err != nil {
+		fmt.Printf("Error loading data: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("🎯 LazyTodo - Database Maintenance")
+	fmt.Println("=================================")
+	report, err := storage.Maintain(storageInstance, app)
+	if err != nil {
+		fmt.Printf("Maintenance failed: %v\n", err)
+		os.Exit(1)
+	}
+	printMaintenance(os.Stdout, report)
+}
+
+// printMaintenance writes a maintenance report: the file size and the rows of
+// each table before and after, and what was purged or removed
+func printMaintenance(w io.Writer, report storage.MaintenanceReport) {
+	fmt.Fprintf(w, "Database: %s\n", report.Path)
+	fmt.Fprintln(w, "Integrity check: ok")
+	fmt.Fprintf(w, "File size: %s -> %s\n", models.FormatBytes(report.SizeBefore), models.FormatBytes(report.SizeAfter))
+	fmt.Fprintf(w, "Tasks purged from the trash: %d\n", report.PurgedTasks)
+
+	fmt.Fprintln(w, "\nRows per table (before -> after):")
+	after := make(map[string]int, len(report.RowsAfter))
+	for _, table := range report.RowsAfter {
+		after[table.Table] = table.Rows
+	}
+	for _, table := range report.RowsBefore {
+		fmt.Fprintf(w, "  %-24s %6d -> %d\n", table.Table, table.Rows, after[table.Table])
+	}
+
+	if len(report.PrunedBackups) > 0 {
+		fmt.Fprintln(w, "\nOld backups removed:")
+		for _, path := range report.PrunedBackups {
+			fmt.Fprintf(w, "  %s\n", path)
+		}
+	}
+}
+
+// runMigrationStatus prints the schema migrations applied to the database,
+// with the checksums recorded for them, and those still pending. It opens
+// the database read-only, so nothing is applied.
+func runMigrationStatus(opts storage.Options) {
+	opts.ReadOnly = true
+	db, err := storage.NewDatabase(opts)
+	if err != nil {
+		storageFailed(os.Stdout, err)
+	}
+	defer db.Close()
+
+	states, err := db.MigrationStatus()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("🎯 LazyTodo - Migration Status")
+	fmt.Println("=============================")
+	pending, modified := 0, 0
+	for _, state := range states {
+		if !state.Applied {
+			pending++
+			fmt.Printf("  %03d  %-44s %s\n", state.Version, "pending", state.Name)
+			continue
+		}
+
+		checksum := state.Checksum
+		if checksum == "" {
+			checksum = "(not recorded yet)"
+		} else if len(checksum) > 12 {
+			checksum = checksum[:12]
+		}
+		note := ""
+		if state.Modified {
+			modified++
+			note = "  MODIFIED"
+		}
+		applied := fmt.Sprintf("applied %s  %s", state.AppliedAt.Local().Format("2006-01-02 15:04"), checksum)
+		fmt.Printf("  %03d  %-44s %s%s\n", state.Version, applied, state.Name, note)
+	}
+
+	fmt.Printf("\n%d applied, %d pending\n", len(states)-pending, pending)
+	if modified > 0 {
+		fmt.Printf("%d applied migrations have been modified since; LazyTodo will refuse to start until they are restored.\n", modified)
+		os.Exit(1)
+	}
+}
+
 func showHelp() {
 	fmt.Println("🎯 LazyTodo - Smart Todo Application")
 	fmt.Println("===================================")
 	fmt.Println()
 	fmt.Println("Usage:")
 	fmt.Println("  lazytodo                Run the TUI application")
-	fmt.Println("  lazytodo --info, -i     Show storage information and statistics")
+	fmt.Println("  lazytodo --info, -i     Show storage information and statistics, per list too;")
+	fmt.Println("                          --json for JSON")
 	fmt.Println("  lazytodo --migrate, -m  Manually run JSON to database migration")
+	fmt.Println("  lazytodo --encrypt      Encrypt the database with a passphrase")
+	fmt.Println("  lazytodo --decrypt      Remove encryption from the database")
+	fmt.Println("  lazytodo --export FILE  Write all lists, tasks and templates as JSON (- for stdout)")
+	fmt.Println("  lazytodo --import FILE  Merge a JSON export into the data (- for stdin)")
+	fmt.Println("  lazytodo --backup FILE  Write a consistent copy of the database to a .tar.gz archive")
+	fmt.Println("  lazytodo --restore FILE Replace the database with the one in a backup archive,")
+	fmt.Println("                          after checking it and asking (--yes to skip asking)")
+	fmt.Println("  lazytodo --sync         Commit, pull and push the export in the sync_repo git repository")
+	fmt.Println("  lazytodo --serve ADDR   Serve an HTTP JSON API on ADDR, e.g. :8080")
+	fmt.Println("  lazytodo --stale        List open tasks older than stale_days, oldest first;")
+	fmt.Println("                          --by-update counts from the last change instead of creation")
+	fmt.Println("  lazytodo --maintenance  Check and compact the database, purge the expired trash")
+	fmt.Println("                          and remove old JSON backups")
+	fmt.Println("  lazytodo --activity     List the changes made to tasks and lists, newest first;")
+	fmt.Println("                          --since SPAN for how far back, e.g. 7d (default 24h)")
+	fmt.Println("  lazytodo --migration-status")
+	fmt.Println("                          List the applied schema migrations with their checksums,")
+	fmt.Println("                          and those still pending")
+	fmt.Println("  lazytodo list           Print tasks as tab-separated id, list, title, completed,")
+	fmt.Println("                          priority, deadline, starred and list id; --list NAME for one")
+	fmt.Println("                          list, --json for JSON")
+	fmt.Println("  lazytodo --config-path  Print where the config file is read from")
+	fmt.Println("  lazytodo --check-config Check the config file and report problems by line")
 	fmt.Println("  lazytodo --help, -h     Show this help message")
 	fmt.Println("  lazytodo --version, -v  Show version information")
 	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --readonly, -r          Open the data without write access (browse only)")
+	fmt.Println("  --ascii                 Draw plain ASCII markers instead of emoji")
+	fmt.Println("  --light                 Use the colors for light terminal backgrounds")
+	fmt.Println("  --open NAME             Start in the list called NAME (case-insensitive) or with ID NAME")
+	fmt.Println("  --task ID               Start at the task with ID, as printed by lazytodo list")
+	fmt.Println("  --profile NAME          Use the data of profile NAME, kept apart from the default one")
+	fmt.Println("  --yes, -y               Migrate old JSON data to the database, import or restore without")
+	fmt.Println("                          asking; without a terminal to ask on they only go ahead with it")
+	fmt.Println("  --dry-run               With --import or --migrate, only show what would change")
+	fmt.Println()
 	fmt.Println("Storage:")
 	fmt.Println("  LazyTodo now uses SQLite database for improved reliability and performance.")
-	fmt.Println("  Data is stored in: %USERPROFILE%\\.lazytodo\\lazytodo.db")
-	fmt.Println("  Old JSON data will be automatically migrated on first run.")
+	fmt.Println("  Data is stored in: " + filepath.Join("~", storage.DatabaseDir, storage.DatabaseName))
+	fmt.Println("  When old JSON data is found, the first run asks whether to migrate it.")
+	fmt.Println("  Encrypted databases are stored in: " + filepath.Join("~", storage.DatabaseDir, storage.EncryptedDatabaseName))
+	fmt.Println("  Set " + storage.PassphraseEnv + " to skip the passphrase prompt.")
+	fmt.Println("  Set " + storage.HomeEnv + " to store data in another directory.")
+	fmt.Println("  Profiles other than " + storage.DefaultProfile + " are stored in: " + filepath.Join("~", storage.DatabaseDir, storage.ProfilesDir, "NAME", storage.DatabaseName))
+	fmt.Println("  Set " + server.TokenEnv + " to require a bearer token for --serve.")
+	fmt.Println()
+	fmt.Println("Display:")
+	fmt.Println("  Plain ASCII markers are used on the Linux console, dumb terminals and")
+	fmt.Println("  non-UTF-8 locales. Set " + ui.ASCIIEnv + "=1 to force them, =0 to never use them.")
+	fmt.Println("  Colors follow the terminal background when it can be told. Set " + ui.LightEnv + "=1")
+	fmt.Println("  for the light colors, =0 for the dark ones.")
+	fmt.Println()
+	fmt.Println("Config file:")
+	fmt.Println("  data_dir, theme, icons, date_format and a [keys] table are read from")
+	fmt.Println("  " + filepath.Join("$XDG_CONFIG_HOME", "lazytodo", config.FileName) + " (~/.config by default; " + config.Env + " to choose")
+	fmt.Println("  another file). Its settings win over the database; options and environment")
+	fmt.Println("  variables win over it.")
 	fmt.Println()
 	fmt.Println("For more information, visit: https://github.com/DhirajZope/lazytodo")
 }
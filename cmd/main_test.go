@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/config"
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// listNames opens the database of the data directory and returns the names of
+// its lists
+func listNames(t *testing.T) []string {
+	t.Helper()
+	db, err := storage.NewDatabase(storage.Options{})
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+	defer db.Close()
+
+	app, err := db.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var names []string
+	for _, list := range app.TodoLists {
+		names = append(names, list.Name)
+	}
+	return names
+}
+
+func TestRunRestoreWithoutTerminalKeepsDatabase(t *testing.T) {
+	dir := t.TempDir()
+	storage.SetDataDir(dir)
+	t.Cleanup(func() { storage.SetDataDir("") })
+
+	db, err := storage.NewDatabase(storage.Options{})
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+	app, err := db.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if _, err := db.CreateTodoList(app, "Backed up", "", ""); err != nil {
+		t.Fatalf("CreateTodoList: %v", err)
+	}
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	if _, err := storage.WriteBackup(archive); err != nil {
+		t.Fatalf("WriteBackup: %v", err)
+	}
+	if _, err := db.CreateTodoList(app, "Added later", "", ""); err != nil {
+		t.Fatalf("CreateTodoList: %v", err)
+	}
+	db.Close()
+	want := strings.Join(listNames(t), ",")
+
+	for _, answer := range []string{"n\n", "y\n", ""} {
+		runRestore(archive, false, strings.NewReader(answer))
+		if got := strings.Join(listNames(t), ","); got != want {
+			t.Errorf("after answering %q without a terminal the lists are %q, want %q", answer, got, want)
+		}
+	}
+	if backups, _ := filepath.Glob(filepath.Join(dir, "*.before-restore.*")); len(backups) > 0 {
+		t.Errorf("database was set aside for a restore: %v", backups)
+	}
+}
+
+func TestLoadConfigDataDirPrecedence(t *testing.T) {
+	fileDir, envDir := t.TempDir(), t.TempDir()
+	path := filepath.Join(t.TempDir(), config.FileName)
+	if err := os.WriteFile(path, []byte("data_dir = "+strconv.Quote(fileDir)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.Env, path)
+	t.Cleanup(func() { storage.SetDataDir("") })
+
+	tests := []struct {
+		name, env, want string
+	}{
+		{"config file", "", fileDir},
+		{storage.HomeEnv + " over the config file", envDir, envDir},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			storage.SetDataDir("")
+			t.Setenv(storage.HomeEnv, test.env)
+			loadConfig()
+
+			db, err := storage.NewDatabase(storage.Options{})
+			if err != nil {
+				t.Fatalf("NewDatabase: %v", err)
+			}
+			defer db.Close()
+			if got := filepath.Dir(db.GetDataPath()); got != test.want {
+				t.Errorf("data directory = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWriteTaskLinesKeepsColumnOrder(t *testing.T) {
+	deadline := time.Date(2026, time.March, 13, 17, 0, 0, 0, time.UTC)
+	tasks := []listedTask{
+		{ID: "t1", ListID: "l1", List: "Work", Title: "Write\treport\r\nnow", Completed: false, Priority: "High", Deadline: &deadline, Starred: true},
+		{ID: "t2", ListID: "l2", List: "Home\nstuff", Title: "Water plants", Completed: true, Priority: "Low"},
+	}
+	var out bytes.Buffer
+	if err := writeTaskLines(&out, tasks); err != nil {
+		t.Fatalf("writeTaskLines: %v", err)
+	}
+
+	// The columns of the first version come first, in their order; later
+	// ones follow
+	want := "t1\tWork\tWrite report  now\tfalse\tHigh\t2026-03-13 17:00\ttrue\tl1\n" +
+		"t2\tHome stuff\tWater plants\ttrue\tLow\t\tfalse\tl2\n"
+	if got := out.String(); got != want {
+		t.Errorf("writeTaskLines wrote\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,321 @@
+// Package config reads the settings kept in a file for editing by hand, such
+// as in a dotfiles repository: the data directory, theme, icon set, date
+// format and key bindings.
+//
+// A value set in the file takes precedence over the same setting stored in
+// the database, and gives way to a command line option or environment
+// variable that sets it.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+const (
+	// FileName is the name of the config file inside its directory
+	FileName = "config.toml"
+
+	// Env names the config file to use instead of the usual location
+	Env = "LAZYTODO_CONFIG"
+
+	// KeysTable is the table of the file rebinding actions to other keys
+	KeysTable = "keys"
+)
+
+// Themes lists the values accepted by the theme key
+var Themes = []string{"auto", "dark", "light"}
+
+// Config holds the settings read from the config file; a setting the file
+// leaves out is empty
+type Config struct {
+	// Path is the file the settings were read from
+	Path string
+
+	// DataDir is the data directory, with a leading ~ expanded
+	DataDir string
+
+	// Theme is auto, dark or light
+	Theme string
+
+	// Icons is the name of an icon set
+	Icons string
+
+	// DateFormat is a date format preset or layout
+	DateFormat string
+
+	// Keys holds the keys bound to each action renamed in the keys table
+	Keys map[string][]string
+}
+
+// Known lists the names a config file is checked against that belong to
+// other packages
+type Known struct {
+	IconSets []string
+	Actions  []string
+}
+
+// Error is a problem found on a line of the config file
+type Error struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// Path returns the config file to use: $LAZYTODO_CONFIG, then lazytodo/config.toml
+// under $XDG_CONFIG_HOME, and finally under ~/.config
+func Path() string {
+	if path := os.Getenv(Env); path != "" {
+		return path
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "lazytodo", FileName)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".config", "lazytodo", FileName)
+	}
+	return ""
+}
+
+// Load reads the config file at path and checks it against known. A missing
+// file is an empty config. The returned error joins every problem found,
+// each an *Error naming its line, alongside the settings that were valid.
+func Load(path string, known Known) (*Config, error) {
+	cfg := &Config{Path: path, Keys: map[string][]string{}}
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	doc, errs := parseTOML(path, data)
+	report := func(line int, format string, args ...any) {
+		errs = append(errs, &Error{File: path, Line: line, Msg: fmt.Sprintf(format, args...)})
+	}
+	str := func(key string, v value) (string, bool) {
+		if v.kind != kindString {
+			report(v.line, "%s must be a string, not %s", key, v.kind)
+			return "", false
+		}
+		return v.str, true
+	}
+	oneOf := func(key string, v value, names []string) string {
+		s, ok := str(key, v)
+		if ok && !slices.Contains(names, s) {
+			report(v.line, "%s must be one of %s, not %q", key, strings.Join(names, ", "), s)
+			return ""
+		}
+		return s
+	}
+
+	for _, key := range sortedKeys(doc[""].values) {
+		v := doc[""].values[key]
+		switch key {
+		case "data_dir":
+			if s, ok := str(key, v); ok {
+				if s == "" {
+					report(v.line, "data_dir must not be empty")
+				} else if cfg.DataDir, err = expandHome(s); err != nil {
+					report(v.line, "data_dir: %v", err)
+				}
+			}
+		case "theme":
+			cfg.Theme = oneOf(key, v, Themes)
+		case "icons":
+			cfg.Icons = oneOf(key, v, known.IconSets)
+		case "date_format":
+			// A layout of the time package is accepted as well as a preset
+			if s, ok := str(key, v); ok {
+				if models.ResolveDateFormat(s) == models.DeadlineLayout && s != models.DeadlineLayout && s != "iso" {
+					report(v.line, "date_format must be one of %s or a Go layout to the minute, not %q", strings.Join(models.DateFormatNames, ", "), s)
+				} else {
+					cfg.DateFormat = s
+				}
+			}
+		default:
+			report(v.line, "unknown setting %s", key)
+		}
+	}
+
+	for name, t := range doc {
+		switch name {
+		case "":
+		case KeysTable:
+			for _, action := range sortedKeys(t.values) {
+				v := t.values[action]
+				switch {
+				case !slices.Contains(known.Actions, action):
+					report(v.line, "unknown action %s in [%s]", action, KeysTable)
+				case v.kind == kindString && v.str != "":
+					cfg.Keys[action] = []string{v.str}
+				case v.kind == kindArray && len(v.array) > 0 && !slices.Contains(v.array, ""):
+					cfg.Keys[action] = v.array
+				default:
+					report(v.line, "%s must be a key or a non-empty array of keys, such as [\"ctrl+n\", \"n\"]", action)
+				}
+			}
+		default:
+			report(t.line, "unknown table [%s]", name)
+		}
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		var a, b *Error
+		return errors.As(errs[i], &a) && errors.As(errs[j], &b) && a.Line < b.Line
+	})
+	return cfg, errors.Join(errs...)
+}
+
+// FileScoped reports whether the setting key is set in the file, so a change
+// made in the settings view has to be written back to it to stick
+func (c *Config) FileScoped(key string) bool {
+	switch key {
+	case "data_dir":
+		return c.DataDir != ""
+	case "theme":
+		return c.Theme != ""
+	case "icons":
+		return c.Icons != ""
+	case "date_format":
+		return c.DateFormat != ""
+	}
+	return false
+}
+
+// Set writes the string s for the top-level setting key to the file and the
+// config. The line setting it is rewritten in place, keeping any comment after
+// the value; a setting the file lacks is added ahead of its first table.
+func (c *Config) Set(key, s string) error {
+	if err := setValue(c.Path, key, s); err != nil {
+		return err
+	}
+	switch key {
+	case "theme":
+		c.Theme = s
+	case "icons":
+		c.Icons = s
+	case "date_format":
+		c.DateFormat = s
+	}
+	return nil
+}
+
+// setValue rewrites the config file at path with key set to the string s,
+// changing nothing else in it
+func setValue(path, key, s string) error {
+	if path == "" {
+		return fmt.Errorf("no config file location")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	assignment := key + " = " + quoteString(s)
+	replaced, firstTable := false, len(lines)
+	for i, text := range lines {
+		trimmed := strings.TrimSpace(text)
+		if strings.HasPrefix(trimmed, "[") {
+			firstTable = i
+			break
+		}
+		name, rest, ok := strings.Cut(trimmed, "=")
+		if !ok || strings.TrimSpace(name) != key {
+			continue
+		}
+		// Keep the indentation and the comment after the old value
+		indent := text[:len(text)-len(strings.TrimLeft(text, " \t"))]
+		comment := ""
+		if _, after, err := parseValue(strings.TrimSpace(rest)); err == nil && isComment(after) {
+			if strings.TrimSpace(after) != "" {
+				comment = strings.TrimRight(after, " \t\r")
+			}
+		}
+		lines[i] = indent + assignment + comment
+		replaced = true
+		break
+	}
+	if !replaced {
+		if firstTable == len(lines) && len(lines) > 0 && lines[len(lines)-1] == "" {
+			firstTable-- // Keep the file's final newline last
+		}
+		lines = slices.Insert(lines, firstTable, assignment)
+	}
+
+	out := strings.Join(lines, "\n")
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	return writeFile(path, []byte(out))
+}
+
+// writeFile replaces the file at path with data through a temporary file, so
+// a failed write never leaves half a config behind
+func writeFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+FileName+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// expandHome replaces a leading ~ of path with the home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+func sortedKeys(values map[string]value) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
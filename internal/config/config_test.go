@@ -0,0 +1,201 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testKnown = Known{IconSets: []string{"emoji", "ascii"}, Actions: []string{"new_task", "quit"}}
+
+// writeConfig writes data to a config file of its own and returns its path
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		name     string
+		env, xdg string
+		want     string
+	}{
+		{"default", "", "", filepath.Join(home, ".config", "lazytodo", FileName)},
+		{"XDG_CONFIG_HOME", "", "/xdg", filepath.Join("/xdg", "lazytodo", FileName)},
+		{"relative XDG_CONFIG_HOME is ignored", "", "xdg", filepath.Join(home, ".config", "lazytodo", FileName)},
+		{Env + " over XDG_CONFIG_HOME", "/etc/lazytodo.toml", "/xdg", "/etc/lazytodo.toml"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(Env, test.env)
+			t.Setenv("XDG_CONFIG_HOME", test.xdg)
+			if got := Path(); got != test.want {
+				t.Errorf("Path() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want Config
+		errs []string // Each error, with its line number
+	}{
+		{
+			name: "every setting",
+			data: `# Dotfiles
+data_dir = "~/todo"
+theme = "dark" # After dark
+icons = 'ascii'
+date_format = "02.01.2006 15:04"
+
+[keys]
+new_task = "ctrl+n"
+quit = ["q", "ctrl+c"]
+`,
+			want: Config{
+				DataDir:    filepath.Join(home, "todo"),
+				Theme:      "dark",
+				Icons:      "ascii",
+				DateFormat: "02.01.2006 15:04",
+				Keys:       map[string][]string{"new_task": {"ctrl+n"}, "quit": {"q", "ctrl+c"}},
+			},
+		},
+		{
+			name: "unknown keys, tables and actions",
+			data: `theme = "light"
+colour = "blue"
+
+[keys]
+fly = "f"
+
+[plugins]
+`,
+			want: Config{Theme: "light", Keys: map[string][]string{}},
+			errs: []string{":2: unknown setting colour", ":5: unknown action fly in [keys]", ":7: unknown table [plugins]"},
+		},
+		{
+			name: "values of the wrong kind or out of range",
+			data: `theme = "sepia"
+icons = true
+data_dir = ""
+date_format = "someday"
+
+[keys]
+quit = []
+`,
+			want: Config{Keys: map[string][]string{}},
+			errs: []string{
+				`:1: theme must be one of auto, dark, light, not "sepia"`,
+				":2: icons must be a string, not a boolean",
+				":3: data_dir must not be empty",
+				`:4: date_format must be one of iso, us, eu or a Go layout to the minute, not "someday"`,
+				":7: quit must be a key or a non-empty array of keys",
+			},
+		},
+		{
+			name: "lines that are not TOML",
+			data: `theme = "dark"
+theme = "light"
+just words
+icons = "emoji" trailing
+`,
+			want: Config{Theme: "dark", Keys: map[string][]string{}},
+			errs: []string{":2: theme is set twice", ":3: expected key = value, got just words", ":4: icons: unexpected trailing after the value"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeConfig(t, test.data)
+			cfg, err := Load(path, testKnown)
+
+			test.want.Path = path
+			if !reflect.DeepEqual(*cfg, test.want) {
+				t.Errorf("Load() = %+v, want %+v", *cfg, test.want)
+			}
+
+			var got []string
+			if err != nil {
+				got = strings.Split(err.Error(), "\n")
+			}
+			if len(got) != len(test.errs) {
+				t.Fatalf("Load() errors = %q, want %d of them", got, len(test.errs))
+			}
+			for i, want := range test.errs {
+				if !strings.HasPrefix(got[i], path+want) {
+					t.Errorf("error %d = %q, want %q", i, got[i], path+want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), FileName), testKnown)
+	if err != nil {
+		t.Fatalf("Load() of a missing file: %v", err)
+	}
+	if cfg.FileScoped("theme") {
+		t.Error("theme is file-scoped without a file")
+	}
+}
+
+func TestLoadReportsLines(t *testing.T) {
+	path := writeConfig(t, "\n\ntheme = 1\n")
+	_, err := Load(path, testKnown)
+	var lineErr *Error
+	if !errors.As(err, &lineErr) || lineErr.Line != 3 {
+		t.Fatalf("Load() error = %v, want an *Error on line 3", err)
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name, data, key, value, want string
+	}{
+		{"keeps the comment", "theme = \"dark\" # After dark\n", "theme", "light", "theme = \"light\" # After dark\n"},
+		{"keeps the indentation", "  theme = \"dark\"\n", "theme", "auto", "  theme = \"auto\"\n"},
+		{"adds a missing setting ahead of the tables", "theme = \"dark\"\n\n[keys]\nquit = \"q\"\n", "icons", "ascii",
+			"theme = \"dark\"\n\nicons = \"ascii\"\n[keys]\nquit = \"q\"\n"},
+		{"adds to the end", "# Mine\n", "icons", "emoji", "# Mine\nicons = \"emoji\"\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeConfig(t, test.data)
+			cfg, err := Load(path, testKnown)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if err := cfg.Set(test.key, test.value); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != test.want {
+				t.Errorf("file after Set = %q, want %q", data, test.want)
+			}
+			if !cfg.FileScoped(test.key) {
+				t.Errorf("%s is not file-scoped after Set", test.key)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// value is a value of the config file: a string, a boolean, an integer or
+// an array of strings, with the line it was set on
+type value struct {
+	str     string
+	boolean bool
+	integer int64
+	array   []string
+	kind    valueKind
+	line    int
+}
+
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindBool
+	kindInteger
+	kindArray
+)
+
+func (k valueKind) String() string {
+	switch k {
+	case kindBool:
+		return "a boolean"
+	case kindInteger:
+		return "an integer"
+	case kindArray:
+		return "an array"
+	default:
+		return "a string"
+	}
+}
+
+// table holds the values of a table of the config file by key, and the line
+// of its header
+type table struct {
+	values map[string]value
+	line   int
+}
+
+// document is a parsed config file: its tables by name, the top-level values
+// in the one with the empty name
+type document map[string]*table
+
+// parseTOML reads the subset of TOML a config file needs: comments, [table]
+// headers, and bare keys set to basic or literal strings, booleans, integers
+// and one-line arrays of strings. Every line it cannot read is reported,
+// prefixed with name and the line number, and skipped.
+func parseTOML(name string, data []byte) (document, []error) {
+	doc := document{"": {values: map[string]value{}}}
+	current := doc[""]
+	var errs []error
+	report := func(line int, format string, args ...any) {
+		errs = append(errs, &Error{File: name, Line: line, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	if !utf8.Valid(data) {
+		report(1, "the file is not valid UTF-8")
+		return doc, errs
+	}
+
+	for i, text := range strings.Split(string(data), "\n") {
+		line := i + 1
+		text = strings.TrimSpace(strings.TrimSuffix(text, "\r"))
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") {
+			header, rest, ok := strings.Cut(text[1:], "]")
+			header = strings.TrimSpace(header)
+			if !ok || !bareKey(header) || !isComment(rest) {
+				report(line, "malformed table header %s", text)
+				continue
+			}
+			if _, seen := doc[header]; seen {
+				report(line, "table [%s] is defined twice", header)
+			}
+			current = &table{values: map[string]value{}, line: line}
+			doc[header] = current
+			continue
+		}
+
+		key, rest, ok := strings.Cut(text, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !bareKey(key) {
+			report(line, "expected key = value, got %s", text)
+			continue
+		}
+		v, rest, err := parseValue(strings.TrimSpace(rest))
+		if err != nil {
+			report(line, "%s: %v", key, err)
+			continue
+		}
+		if !isComment(rest) {
+			report(line, "%s: unexpected %s after the value", key, strings.TrimSpace(rest))
+			continue
+		}
+		if _, seen := current.values[key]; seen {
+			report(line, "%s is set twice", key)
+			continue
+		}
+		v.line = line
+		current.values[key] = v
+	}
+	return doc, errs
+}
+
+// bareKey reports whether s is a bare TOML key: letters, digits, - and _
+func bareKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// isComment reports whether what follows a value is only a comment or blank
+func isComment(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || strings.HasPrefix(s, "#")
+}
+
+// parseValue reads the value at the start of s and returns what follows it
+func parseValue(s string) (value, string, error) {
+	switch {
+	case s == "":
+		return value{}, "", fmt.Errorf("missing value")
+	case s[0] == '"' || s[0] == '\'':
+		str, rest, err := parseString(s)
+		return value{str: str, kind: kindString}, rest, err
+	case s[0] == '[':
+		return parseArray(s[1:])
+	}
+
+	end := strings.IndexAny(s, " \t#")
+	if end < 0 {
+		end = len(s)
+	}
+	word, rest := s[:end], s[end:]
+	switch word {
+	case "true", "false":
+		return value{boolean: word == "true", kind: kindBool}, rest, nil
+	}
+	n, err := strconv.ParseInt(strings.ReplaceAll(word, "_", ""), 10, 64)
+	if err != nil {
+		return value{}, "", fmt.Errorf("%s is not a string, boolean or integer; strings need quotes", word)
+	}
+	return value{integer: n, kind: kindInteger}, rest, nil
+}
+
+// parseString reads the basic ("...") or literal ('...') string at the start
+// of s and returns what follows it
+func parseString(s string) (string, string, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote:
+			return b.String(), s[i+1:], nil
+		case c == '\\' && quote == '"':
+			if i+1 >= len(s) {
+				return "", "", fmt.Errorf("unterminated string")
+			}
+			i++
+			switch s[i] {
+			case '"', '\\':
+				b.WriteByte(s[i])
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'u', 'U':
+				digits := 4
+				if s[i] == 'U' {
+					digits = 8
+				}
+				if i+digits >= len(s) {
+					return "", "", fmt.Errorf("short \\%c escape", s[i])
+				}
+				code, err := strconv.ParseUint(s[i+1:i+1+digits], 16, 32)
+				if err != nil || !utf8.ValidRune(rune(code)) {
+					return "", "", fmt.Errorf("invalid \\%c escape", s[i])
+				}
+				b.WriteRune(rune(code))
+				i += digits
+			default:
+				return "", "", fmt.Errorf("unknown escape \\%c", s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string")
+}
+
+// parseArray reads the rest of a one-line array of strings after its [
+func parseArray(s string) (value, string, error) {
+	v := value{kind: kindArray}
+	for {
+		s = strings.TrimSpace(s)
+		if strings.HasPrefix(s, "]") {
+			return v, s[1:], nil
+		}
+		if s == "" || (s[0] != '"' && s[0] != '\'') {
+			return value{}, "", fmt.Errorf("arrays may only hold strings, on one line")
+		}
+		str, rest, err := parseString(s)
+		if err != nil {
+			return value{}, "", err
+		}
+		v.array = append(v.array, str)
+
+		rest = strings.TrimSpace(rest)
+		if after, ok := strings.CutPrefix(rest, ","); ok {
+			rest = after
+		} else if !strings.HasPrefix(rest, "]") {
+			return value{}, "", fmt.Errorf("expected , or ] in the array")
+		}
+		s = rest
+	}
+}
+
+// quoteString writes s as a basic TOML string
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,309 @@
+// Package gitsync keeps a canonical JSON export of the data in a git repository
+// and merges in exports committed from other machines.
+package gitsync
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// ExportName is the file written to the root of the sync repository
+const ExportName = "lazytodo.json"
+
+// ErrNotConfigured is returned when no sync repository has been set
+var ErrNotConfigured = errors.New("git sync is not configured: set sync_repo to a git repository path")
+
+// Result describes what a sync did
+type Result struct {
+	Merged    storage.MergeResult // Changes merged in from the remote
+	Committed bool                // A new commit was made
+	Pushed    bool                // The branch was pushed to its upstream
+}
+
+func (r Result) String() string {
+	parts := []string{}
+	if r.Merged.Changed() {
+		parts = append(parts, "merged "+r.Merged.String())
+	}
+	if r.Committed {
+		parts = append(parts, "committed")
+	}
+	if r.Pushed {
+		parts = append(parts, "pushed")
+	}
+
+	if len(parts) == 0 {
+		return "already up to date"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// RepoPath resolves the configured repository path, expanding a leading ~
+func RepoPath(repo string) (string, error) {
+	if repo == "" {
+		return "", ErrNotConfigured
+	}
+
+	if repo == "~" || strings.HasPrefix(repo, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to expand %s: %w", repo, err)
+		}
+		repo = filepath.Join(home, repo[1:])
+	}
+
+	return repo, nil
+}
+
+// WriteExport writes the canonical export into the repository directory. The
+// file is left alone when its contents would not change.
+func WriteExport(s storage.StorageInterface, repo string) error {
+	dir, err := RepoPath(repo)
+	if err != nil {
+		return err
+	}
+
+	data, err := storage.Export(s)
+	if err != nil {
+		return err
+	}
+
+	return writeIfChanged(filepath.Join(dir, ExportName), data)
+}
+
+// Sync exports the data, commits it, pulls with rebase and pushes. When the
+// checkout or the pull holds a different export it is merged into storage with
+// the same rules as an import and the merged export is committed on top. A conflicting
+// rebase is resolved the same way; any other failure aborts the rebase.
+func Sync(s storage.StorageInterface, repo string) (Result, error) {
+	var result Result
+
+	dir, err := RepoPath(repo)
+	if err != nil {
+		return result, err
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return result, errors.New("git is not installed")
+	}
+	if _, err := runGit(dir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return result, fmt.Errorf("%s is not a git repository", dir)
+	}
+
+	// The checked out export may hold edits this machine has not seen, such as
+	// on the first sync from a fresh clone, so merge it before overwriting it
+	exportPath := filepath.Join(dir, ExportName)
+	if _, err := os.Stat(exportPath); err == nil {
+		if result.Merged, err = mergeFile(s, exportPath); err != nil {
+			return result, err
+		}
+	}
+
+	if err := WriteExport(s, repo); err != nil {
+		return result, err
+	}
+	if result.Committed, err = commitExport(dir, "Update lazytodo export"); err != nil {
+		return result, err
+	}
+
+	// Without a remote the repository is only a local history
+	remotes, err := runGit(dir, "remote")
+	if err != nil {
+		return result, err
+	}
+	if strings.TrimSpace(remotes) == "" {
+		return result, nil
+	}
+	if _, err := runGit(dir, "rev-parse", "--abbrev-ref", "@{upstream}"); err != nil {
+		return result, errors.New("current branch has no upstream; push it once with git push -u")
+	}
+
+	if _, err := runGit(dir, "pull", "--rebase"); err != nil {
+		merged, resolveErr := resolveConflict(s, dir, exportPath)
+		if resolveErr != nil {
+			runGit(dir, "rebase", "--abort")
+			return result, fmt.Errorf("pull failed: %w", err)
+		}
+		result.Merged = addResults(result.Merged, merged)
+	}
+
+	// A clean rebase can still bring in edits made elsewhere
+	merged, err := mergeFile(s, exportPath)
+	if err != nil {
+		return result, err
+	}
+	if merged.Changed() {
+		result.Merged = addResults(result.Merged, merged)
+		if err := WriteExport(s, repo); err != nil {
+			return result, err
+		}
+		committed, err := commitExport(dir, "Merge lazytodo export")
+		if err != nil {
+			return result, err
+		}
+		result.Committed = result.Committed || committed
+	}
+
+	if _, err := runGit(dir, "push"); err != nil {
+		return result, err
+	}
+	result.Pushed = true
+
+	return result, nil
+}
+
+// resolveConflict settles a rebase stopped on the export: the upstream copy is
+// merged into storage, the merged export replaces the conflicted file and the
+// rebase continues, once for every local commit that conflicts. Conflicts in
+// any other file are left for the user.
+func resolveConflict(s storage.StorageInterface, dir, exportPath string) (storage.MergeResult, error) {
+	var total storage.MergeResult
+	for {
+		unmerged, err := runGit(dir, "diff", "--name-only", "--diff-filter=U")
+		if err != nil {
+			return total, err
+		}
+		if strings.TrimSpace(unmerged) != ExportName {
+			return total, errors.New("conflict is not limited to the export")
+		}
+
+		// While rebasing, stage 2 holds the upstream side of the conflict
+		upstream, err := runGit(dir, "show", ":2:"+ExportName)
+		if err != nil {
+			return total, err
+		}
+
+		merged, err := mergeExport(s, []byte(upstream))
+		if err != nil {
+			return total, err
+		}
+		total = addResults(total, merged)
+
+		data, err := storage.Export(s)
+		if err != nil {
+			return total, err
+		}
+		if err := os.WriteFile(exportPath, data, 0644); err != nil {
+			return total, fmt.Errorf("failed to write export: %w", err)
+		}
+		if _, err := runGit(dir, "add", "--", ExportName); err != nil {
+			return total, err
+		}
+		if _, err := runGit(dir, "rebase", "--continue"); err == nil {
+			return total, nil
+		}
+	}
+}
+
+// addResults sums the counts of two merges
+func addResults(a, b storage.MergeResult) storage.MergeResult {
+	return storage.MergeResult{
+		ListsAdded:     a.ListsAdded + b.ListsAdded,
+		ListsUpdated:   a.ListsUpdated + b.ListsUpdated,
+		TasksAdded:     a.TasksAdded + b.TasksAdded,
+		TasksUpdated:   a.TasksUpdated + b.TasksUpdated,
+		NotesAdded:     a.NotesAdded + b.NotesAdded,
+		TemplatesAdded: a.TemplatesAdded + b.TemplatesAdded,
+	}
+}
+
+// mergeFile merges the export on disk into storage when it differs from a fresh export
+func mergeFile(s storage.StorageInterface, exportPath string) (storage.MergeResult, error) {
+	data, err := os.ReadFile(exportPath)
+	if err != nil {
+		return storage.MergeResult{}, fmt.Errorf("failed to read export: %w", err)
+	}
+
+	current, err := storage.Export(s)
+	if err != nil {
+		return storage.MergeResult{}, err
+	}
+	if bytes.Equal(data, current) {
+		return storage.MergeResult{}, nil
+	}
+
+	return mergeExport(s, data)
+}
+
+// mergeExport merges export data into a freshly loaded copy of storage
+func mergeExport(s storage.StorageInterface, data []byte) (storage.MergeResult, error) {
+	incoming, err := storage.ParseExport(data)
+	if err != nil {
+		return storage.MergeResult{}, err
+	}
+
+	app, err := s.Load()
+	if err != nil {
+		return storage.MergeResult{}, fmt.Errorf("failed to load data: %w", err)
+	}
+
+	return s.Merge(app, incoming)
+}
+
+// commitExport stages the export and commits it if it changed
+func commitExport(dir, message string) (bool, error) {
+	if _, err := runGit(dir, "add", "--", ExportName); err != nil {
+		return false, err
+	}
+
+	// diff --quiet exits 1 when something is staged
+	if _, err := runGit(dir, "diff", "--cached", "--quiet", "--", ExportName); err == nil {
+		return false, nil
+	}
+
+	if _, err := runGit(dir, "commit", "-m", message, "--", ExportName); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// runGit runs git in dir and returns its output. Prompts and editors are
+// disabled so git fails instead of waiting on a terminal the TUI owns.
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_EDITOR=true")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		detail := strings.TrimSpace(stderr.String())
+		if detail == "" {
+			detail = strings.TrimSpace(stdout.String())
+		}
+		if detail == "" {
+			detail = err.Error()
+		}
+		return stdout.String(), fmt.Errorf("git %s: %s", args[0], firstLine(detail))
+	}
+
+	return stdout.String(), nil
+}
+
+// writeIfChanged writes data to path unless the file already holds exactly data
+func writeIfChanged(path string, data []byte) error {
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
+
+// firstLine keeps error messages short enough for the status bar
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,245 @@
+package gitsync
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// machine is the data and sync checkout of one computer
+type machine struct {
+	store storage.StorageInterface
+	dir   string // Checkout of the sync repository
+}
+
+// newMachine opens an empty database in a data directory of its own, with a
+// clone of remote as its sync checkout when remote is set
+func newMachine(t *testing.T, remote string) *machine {
+	t.Helper()
+	storage.SetDataDir(t.TempDir())
+	t.Cleanup(func() { storage.SetDataDir("") })
+	store, err := storage.NewDatabase(storage.Options{Output: io.Discard})
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	m := &machine{store: store}
+	if remote != "" {
+		m.dir = filepath.Join(t.TempDir(), "sync")
+		mustGit(t, "", "clone", "--quiet", remote, m.dir)
+	}
+	return m
+}
+
+// addTask creates a task in the list called listName, creating the list first
+// when there is none
+func (m *machine) addTask(t *testing.T, listName, title string) models.Task {
+	t.Helper()
+	app, err := m.store.Load() // Merges change storage behind any copy loaded before
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var listID string
+	for _, list := range app.TodoLists {
+		if list.Name == listName {
+			listID = list.ID
+		}
+	}
+	if listID == "" {
+		if listID, err = m.store.CreateTodoList(app, listName, "", ""); err != nil {
+			t.Fatalf("CreateTodoList: %v", err)
+		}
+	}
+	task, err := m.store.CreateTask(app, listID, title, "", models.Medium, nil, "", models.SourceTUI)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	return task
+}
+
+// commit writes the export into the checkout and commits it
+func (m *machine) commit(t *testing.T, message string) {
+	t.Helper()
+	if err := WriteExport(m.store, m.dir); err != nil {
+		t.Fatalf("WriteExport: %v", err)
+	}
+	if _, err := commitExport(m.dir, message); err != nil {
+		t.Fatalf("commitExport: %v", err)
+	}
+}
+
+// titles lists the titles of the tasks in storage by list, in export order
+func (m *machine) titles(t *testing.T) []string {
+	t.Helper()
+	data, err := storage.Export(m.store)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	exported, err := storage.ParseExport(data)
+	if err != nil {
+		t.Fatalf("ParseExport: %v", err)
+	}
+	var titles []string
+	for _, list := range exported.TodoLists {
+		for _, task := range list.Tasks {
+			titles = append(titles, list.Name+": "+task.Title)
+		}
+	}
+	return titles
+}
+
+// mustGit runs git in dir, failing the test when it fails
+func mustGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := runGit(dir, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// newRemote returns a bare repository to clone, with git kept away from the
+// user's configuration
+func newRemote(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, name := range []string{"AUTHOR", "COMMITTER"} {
+		t.Setenv("GIT_"+name+"_NAME", "Test")
+		t.Setenv("GIT_"+name+"_EMAIL", "test@example.com")
+	}
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	mustGit(t, "", "init", "--quiet", "--bare", remote)
+	return remote
+}
+
+func TestMergeExport(t *testing.T) {
+	local := newMachine(t, "")
+	report := local.addTask(t, "Work", "Write report")
+	data, err := storage.Export(local.store)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	// Another machine renamed the task a minute later and added one
+	var export storage.ExportFile
+	if err := json.Unmarshal(data, &export); err != nil {
+		t.Fatal(err)
+	}
+	work := &export.TodoLists[0]
+	work.Tasks[0].Title = "Write the report"
+	work.Tasks[0].UpdatedAt = report.UpdatedAt.Add(time.Minute)
+	work.Tasks = append(work.Tasks, models.Task{ID: "slides", Title: "Make slides", Priority: models.Low,
+		CreatedAt: report.CreatedAt.Add(time.Minute), UpdatedAt: report.CreatedAt.Add(time.Minute)})
+	incoming, err := json.Marshal(export)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := mergeExport(local.store, incoming)
+	if err != nil {
+		t.Fatalf("mergeExport: %v", err)
+	}
+	if want := (storage.MergeResult{TasksAdded: 1, TasksUpdated: 1}); result != want {
+		t.Errorf("result = %+v, want %+v", result, want)
+	}
+	if got, want := local.titles(t), []string{"Work: Write the report", "Work: Make slides"}; !slices.Equal(got, want) {
+		t.Errorf("tasks after the merge = %q, want %q", got, want)
+	}
+
+	// Merging the same export again changes nothing
+	if result, err := mergeExport(local.store, incoming); err != nil || result.Changed() {
+		t.Errorf("merging again: %+v, %v; want no changes", result, err)
+	}
+	if _, err := mergeExport(local.store, []byte("{not json")); err == nil {
+		t.Error("merging a broken export succeeded")
+	}
+}
+
+func TestResolveConflict(t *testing.T) {
+	remote := newRemote(t)
+	laptop := newMachine(t, remote)
+	laptop.addTask(t, "Work", "Write report")
+	laptop.commit(t, "First export")
+	mustGit(t, laptop.dir, "push", "--quiet", "-u", "origin", "HEAD")
+
+	// The desktop starts from the laptop's export and adds a task of its own
+	desktop := newMachine(t, remote)
+	if _, err := mergeFile(desktop.store, filepath.Join(desktop.dir, ExportName)); err != nil {
+		t.Fatalf("mergeFile: %v", err)
+	}
+	desktop.addTask(t, "Work", "Call plumber")
+	desktop.commit(t, "Desktop export")
+	mustGit(t, desktop.dir, "push", "--quiet")
+
+	// The laptop adds another task meanwhile, so both exports change the same lines
+	laptop.addTask(t, "Work", "Book flights")
+	laptop.commit(t, "Laptop export")
+	if _, err := runGit(laptop.dir, "pull", "--rebase"); err == nil {
+		t.Fatal("pull --rebase succeeded, want a conflict on the export")
+	}
+
+	exportPath := filepath.Join(laptop.dir, ExportName)
+	result, err := resolveConflict(laptop.store, laptop.dir, exportPath)
+	if err != nil {
+		t.Fatalf("resolveConflict: %v", err)
+	}
+	if want := (storage.MergeResult{TasksAdded: 1}); result != want {
+		t.Errorf("result = %+v, want %+v", result, want)
+	}
+	titles := laptop.titles(t)
+	for _, title := range []string{"Work: Write report", "Work: Call plumber", "Work: Book flights"} {
+		if !slices.Contains(titles, title) {
+			t.Errorf("tasks after resolving = %q, want %s among them", titles, title)
+		}
+	}
+
+	// The rebase finished on top of the desktop's commit with the merged export
+	if status := mustGit(t, laptop.dir, "status", "--porcelain"); status != "" {
+		t.Errorf("checkout not clean after resolving:\n%s", status)
+	}
+	if log := mustGit(t, laptop.dir, "log", "--format=%s"); log != "Laptop export\nDesktop export\nFirst export\n" {
+		t.Errorf("history after resolving:\n%s", log)
+	}
+	committed := mustGit(t, laptop.dir, "show", "HEAD:"+ExportName)
+	if current, err := storage.Export(laptop.store); err != nil || committed != string(current) {
+		t.Errorf("committed export differs from storage (%v):\n%s", err, committed)
+	}
+}
+
+func TestResolveConflictLeavesOtherFilesAlone(t *testing.T) {
+	remote := newRemote(t)
+	laptop := newMachine(t, remote)
+	laptop.commit(t, "First export")
+	mustGit(t, laptop.dir, "push", "--quiet", "-u", "origin", "HEAD")
+
+	desktop := newMachine(t, remote)
+	for _, m := range []*machine{desktop, laptop} {
+		if err := os.WriteFile(filepath.Join(m.dir, "notes.txt"), []byte(m.dir+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mustGit(t, m.dir, "add", "notes.txt")
+		mustGit(t, m.dir, "commit", "--quiet", "-m", "Notes")
+	}
+	mustGit(t, desktop.dir, "push", "--quiet")
+	if _, err := runGit(laptop.dir, "pull", "--rebase"); err == nil {
+		t.Fatal("pull --rebase succeeded, want a conflict on notes.txt")
+	}
+
+	_, err := resolveConflict(laptop.store, laptop.dir, filepath.Join(laptop.dir, ExportName))
+	if err == nil || !strings.Contains(err.Error(), "not limited to the export") {
+		t.Errorf("resolveConflict = %v, want an error about the other file", err)
+	}
+}
@@ -1,6 +1,16 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,6 +24,8 @@ const (
 	Critical
 )
 
+// String names the priority, e.g. "High". The UI, the CLI, exports and the
+// API all write this name; ParsePriority reads it back in any case.
 func (p Priority) String() string {
 	switch p {
 	case Low:
@@ -29,32 +41,559 @@ func (p Priority) String() string {
 	}
 }
 
+// Valid reports whether p is one of the priority levels
+func (p Priority) Valid() bool {
+	return p >= Low && p <= Critical
+}
+
+// Clamp returns p limited to the priority levels, for values read from
+// storage that were never validated
+func (p Priority) Clamp() Priority {
+	return min(max(p, Low), Critical)
+}
+
+// ParsePriority parses a priority given by name ("high"), by its first letter
+// ("h") or by its number ("2"), ignoring case and surrounding space
+func ParsePriority(value string) (Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "low", "l", "0":
+		return Low, nil
+	case "medium", "m", "1":
+		return Medium, nil
+	case "high", "h", "2":
+		return High, nil
+	case "critical", "c", "3":
+		return Critical, nil
+	}
+	return Low, fmt.Errorf("invalid priority %q: use low, medium, high or critical", value)
+}
+
+// MarshalJSON writes the priority by name, e.g. "High"
+func (p Priority) MarshalJSON() ([]byte, error) {
+	if !p.Valid() {
+		return nil, fmt.Errorf("invalid priority %d", int(p))
+	}
+	return json.Marshal(p.String())
+}
+
+// UnmarshalJSON reads a priority written by name or in any form ParsePriority
+// accepts, and the plain numbers of files from before priorities had names
+func (p *Priority) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var number int
+	if err := json.Unmarshal(data, &number); err == nil {
+		if !Priority(number).Valid() {
+			return fmt.Errorf("invalid priority %d: use 0 (low) to 3 (critical)", number)
+		}
+		*p = Priority(number)
+		return nil
+	}
+
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("invalid priority %s: use a name or a number", data)
+	}
+	parsed, err := ParsePriority(name)
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
 // Task represents a single todo task
 type Task struct {
-	ID          string     `json:"id"`
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	Completed   bool       `json:"completed"`
-	Priority    Priority   `json:"priority"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	Deadline    *time.Time `json:"deadline,omitempty"`
+	ID             string         `json:"id"`
+	Title          string         `json:"title"`
+	Description    string         `json:"description"`
+	Completed      bool           `json:"completed"`
+	Priority       Priority       `json:"priority"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	Deadline       *time.Time     `json:"deadline,omitempty"`
+	Label          string         `json:"label,omitempty"`           // User-chosen emoji/color marker
+	SnoozeCount    int            `json:"snooze_count,omitempty"`    // How often the deadline was snoozed
+	Estimate       time.Duration  `json:"estimate,omitempty"`        // Planned effort
+	Spent          time.Duration  `json:"spent,omitempty"`           // Time tracked so far
+	Link           string         `json:"link,omitempty"`            // URL or file path the task refers to
+	ReminderOffset *time.Duration `json:"reminder_offset,omitempty"` // Reminder lead time; nil uses the global setting
+	Progress       int            `json:"progress,omitempty"`        // How far the task is done, 0 to 100 percent
+	Starred        bool           `json:"starred,omitempty"`         // Part of the daily plan shown in the Starred view
+	Source         string         `json:"source,omitempty"`          // Where the task was created, one of the Source constants
+	DeletedAt      *time.Time     `json:"deleted_at,omitempty"`      // When the task was moved to the trash
+	CompletedAt    *time.Time     `json:"completed_at,omitempty"`    // When the task was last completed; nil while open
+	Notes          []Note         `json:"notes,omitempty"`
+}
+
+// Sources a task can be created from
+const (
+	SourceTUI      = "tui"
+	SourceCLI      = "cli"
+	SourceImport   = "import"
+	SourceAPI      = "api"
+	SourceTemplate = "template"
+	SourceUnknown  = "unknown" // Created before sources were recorded
+)
+
+// Duplicate returns an open copy of the task under a new ID, created at now.
+// It keeps what describes the work and the task's source; completion, time
+// spent, snoozes and notes stay with the original.
+func (t *Task) Duplicate(id string, now time.Time) Task {
+	duplicate := Task{
+		ID:          id,
+		Title:       t.Title,
+		Description: t.Description,
+		Priority:    t.Priority,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Label:       t.Label,
+		Estimate:    t.Estimate,
+		Link:        t.Link,
+		Source:      t.Source,
+	}
+	if t.Deadline != nil {
+		deadline := *t.Deadline
+		duplicate.Deadline = &deadline
+	}
+	if t.ReminderOffset != nil {
+		offset := *t.ReminderOffset
+		duplicate.ReminderOffset = &offset
+	}
+	return duplicate
+}
+
+// CreationSource returns where the task was created, or SourceUnknown
+func (t *Task) CreationSource() string {
+	if t.Source == "" {
+		return SourceUnknown
+	}
+	return t.Source
+}
+
+// ClampProgress limits a task's progress to 0 through 100 percent
+func ClampProgress(progress int) int {
+	return min(max(progress, 0), 100)
+}
+
+// Note represents a dated journal entry attached to a task
+type Note struct {
+	ID        string    `json:"id"`
+	TaskID    string    `json:"task_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DeadlineLayout is the default format deadlines are entered and displayed in
+const DeadlineLayout = "2006-01-02 15:04"
+
+// DateFormatNames lists the named presets accepted by Settings.DateFormat
+var DateFormatNames = []string{"iso", "us", "eu"}
+
+var dateFormatPresets = map[string]string{
+	"iso": DeadlineLayout,
+	"us":  "01/02/2006 3:04pm",
+	"eu":  "02/01/2006 15:04",
+}
+
+// ResolveDateFormat returns the layout for a date format setting, which is a
+// preset name or a Go layout. Empty or unusable layouts fall back to DeadlineLayout.
+func ResolveDateFormat(format string) string {
+	if layout, ok := dateFormatPresets[format]; ok {
+		return layout
+	}
+
+	// A usable layout keeps the date and time to the minute
+	reference := time.Date(2026, time.November, 23, 19, 45, 0, 0, time.UTC)
+	if parsed, err := time.Parse(format, reference.Format(format)); err != nil || !parsed.Equal(reference) {
+		return DeadlineLayout
+	}
+	return format
+}
+
+// ParseDeadline parses a deadline entered in layout, also accepting
+// DeadlineLayout. An empty value means no deadline and returns nil.
+func ParseDeadline(value, layout string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	deadline, err := time.Parse(layout, value)
+	if err != nil {
+		iso, isoErr := time.Parse(DeadlineLayout, value)
+		if isoErr != nil {
+			return nil, err
+		}
+		deadline = iso
+	}
+	return &deadline, nil
+}
+
+// ParseDuration parses a task estimate or time spent such as "30m", "2h" or
+// "1h30m"; a bare number is taken as minutes. An empty value means zero.
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	if minutes, err := strconv.Atoi(value); err == nil {
+		value = strconv.Itoa(minutes) + "m"
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration must not be negative: %s", value)
+	}
+	return d.Round(time.Minute), nil
+}
+
+// WorkDay is the effort a day of an estimate stands for, so "1d" is 8h
+const WorkDay = 8 * time.Hour
+
+// ParseEffort parses a task estimate or time spent as ParseDuration does,
+// with whole or fractional work days in front, as in "1d", "1.5d" or "2d4h"
+func ParseEffort(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	dayPart, rest, found := strings.Cut(value, "d")
+	if !found {
+		return ParseDuration(value)
+	}
+
+	days, err := strconv.ParseFloat(dayPart, 64)
+	if err != nil || !(days >= 0) { // Also rejects NaN
+		return 0, fmt.Errorf("invalid number of days: %q", value)
+	}
+	d, err := ParseDuration(rest)
+	if err != nil {
+		return 0, err
+	}
+	if days*float64(WorkDay)+float64(d) >= math.MaxInt64 {
+		return 0, fmt.Errorf("duration too long: %q", value)
+	}
+	return (time.Duration(days*float64(WorkDay)) + d).Round(time.Minute), nil
+}
+
+// ParseOffset parses a relative time shift such as "+3d", "-1w", "2h" or
+// "+90m". Units are minutes, hours, days and weeks; without a sign the shift
+// is forward.
+func ParseOffset(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 {
+		return 0, fmt.Errorf("invalid offset: %q", value)
+	}
+
+	sign := time.Duration(1)
+	switch value[0] {
+	case '-':
+		sign = -1
+		value = value[1:]
+	case '+':
+		value = value[1:]
+	}
+
+	units := map[byte]time.Duration{'m': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	unit, ok := units[value[len(value)-1]]
+	if !ok {
+		return 0, fmt.Errorf("invalid offset unit in %q: use m, h, d or w", value)
+	}
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid offset: %q", value)
+	}
+
+	return sign * time.Duration(n) * unit, nil
+}
+
+// ParseReminderOffset parses a per-task reminder lead time such as "10m",
+// "2h", "1h30m", "1d" or "1w"; a bare number is taken as minutes. An empty
+// value means no offset of its own, so the global setting applies.
+func ParseReminderOffset(value string) (*time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	offset, err := ParseDuration(value)
+	if err != nil {
+		// Days and weeks are not understood by time.ParseDuration
+		if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+			return nil, fmt.Errorf("invalid reminder offset: %q", value)
+		}
+		if offset, err = ParseOffset(value); err != nil {
+			return nil, err
+		}
+	}
+	if offset <= 0 {
+		return nil, fmt.Errorf("reminder offset must be positive: %q", value)
+	}
+	return &offset, nil
+}
+
+// FormatReminderOffset renders a reminder lead time, using days for whole days
+func FormatReminderOffset(d time.Duration) string {
+	day := 24 * time.Hour
+	if d >= day && d%day == 0 {
+		return fmt.Sprintf("%dd", int(d/day))
+	}
+	return FormatDuration(d)
+}
+
+// ParseLink checks a task link: a URL with a scheme, such as
+// https://example.com/ticket/42, or the path of an existing file or directory,
+// which is made absolute with a leading ~ expanded. An empty value means no link.
+func ParseLink(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+
+	// Paths are checked first so that Windows drive letters are not taken for a scheme
+	path := value
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	if _, err := os.Stat(path); err == nil {
+		return filepath.Abs(path)
+	}
+
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && (u.Host != "" || u.Opaque != "") {
+		return value, nil
+	}
+	return "", fmt.Errorf("not a URL or an existing path: %s", value)
+}
+
+// urlPattern matches web URLs written in free text
+var urlPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"'\x60]+`)
+
+// URLIndexes returns the start and end of each web URL in text, leaving out
+// punctuation that ends a sentence or closes a parenthesis around the URL
+func URLIndexes(text string) [][2]int {
+	var spans [][2]int
+	for _, match := range urlPattern.FindAllStringIndex(text, -1) {
+		start, end := match[0], match[1]
+		for end > start {
+			last := text[end-1]
+			if strings.IndexByte(".,;:!?", last) >= 0 ||
+				(last == ')' && strings.Count(text[start:end], "(") < strings.Count(text[start:end], ")")) {
+				end--
+				continue
+			}
+			break
+		}
+		spans = append(spans, [2]int{start, end})
+	}
+	return spans
+}
+
+// FindURLs returns the web URLs in text in the order they appear
+func FindURLs(text string) []string {
+	var urls []string
+	for _, span := range URLIndexes(text) {
+		urls = append(urls, text[span[0]:span[1]])
+	}
+	return urls
+}
+
+// FormatDuration renders an estimate or time spent to the minute, e.g. "1h30m", "2h" or "45m"
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours, minutes := int(d/time.Hour), int(d%time.Hour/time.Minute)
+
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dh%02dm", hours, minutes)
+	}
+}
+
+// FormatBytes renders a file size, e.g. "12.5 KB"
+func FormatBytes(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d bytes", size)
+	}
+}
+
+// URLs returns the web URLs in the task's title and description, each once,
+// in the order they appear
+func (t *Task) URLs() []string {
+	seen := make(map[string]bool)
+	var urls []string
+	for _, u := range FindURLs(t.Title + "\n" + t.Description) {
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// Groupings of a task list, kept in TodoList.Grouping; the empty grouping shows no sections
+const (
+	GroupByPriority = "priority"
+	GroupByDeadline = "deadline"
+)
+
+// Deadline buckets of a task list grouped by deadline, in display order
+const (
+	BucketOverdue = iota
+	BucketToday
+	BucketThisWeek // Due in the 7 days after today
+	BucketLater
+	BucketNoDeadline
+)
+
+// DeadlineBucket returns the deadline bucket that deadline falls in at now
+func DeadlineBucket(deadline *time.Time, now time.Time) int {
+	if deadline == nil {
+		return BucketNoDeadline
+	}
+	now = WallClock(now)
+	_, tomorrow := CalendarDay(now)
+	switch {
+	case deadline.Before(now):
+		return BucketOverdue
+	case deadline.Before(tomorrow):
+		return BucketToday
+	case deadline.Before(tomorrow.AddDate(0, 0, 7)):
+		return BucketThisWeek
+	default:
+		return BucketLater
+	}
 }
 
 // IsOverdue checks if the task is overdue
 func (t *Task) IsOverdue() bool {
+	return t.IsOverdueAt(time.Now())
+}
+
+// IsOverdueAt checks if the task is overdue at now, compared in the deadline
+// representation like every check of deadlines against the time
+func (t *Task) IsOverdueAt(now time.Time) bool {
 	if t.Deadline == nil || t.Completed {
 		return false
 	}
-	return time.Now().After(*t.Deadline)
+	return WallClock(now).After(*t.Deadline)
+}
+
+// Age returns how many calendar days the task has been around at now,
+// counted from its creation or, with fromUpdate, from its last change. Days
+// are counted on now's calendar, so anything from today is 0 days old.
+func (t *Task) Age(now time.Time, fromUpdate bool) int {
+	since := t.CreatedAt
+	if fromUpdate {
+		since = t.UpdatedAt
+	}
+	return DaysBetween(since, now)
+}
+
+// DaysBetween returns how many calendar days from lies before to, on to's
+// calendar; 0 when from is the same day or later. Whole dates are compared,
+// so times of day and daylight saving changes cannot shift the count.
+func DaysBetween(from, to time.Time) int {
+	from = from.In(to.Location())
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return max(int(end.Sub(start)/(24*time.Hour)), 0)
+}
+
+// ReminderLead returns how long before its deadline the task is reminded
+// about: its own offset if it has one, otherwise fallback
+func (t *Task) ReminderLead(fallback time.Duration) time.Duration {
+	if t.ReminderOffset != nil {
+		return *t.ReminderOffset
+	}
+	return fallback
 }
 
-// IsDueSoon checks if the task is due within the next 24 hours
+// IsDueSoon checks if the task is due within the next 24 hours, the default
+// due-soon window
 func (t *Task) IsDueSoon() bool {
-	if t.Deadline == nil || t.Completed {
+	return t.IsDueSoonWithin(24 * time.Hour)
+}
+
+// IsDueSoonWithin checks if the task is due within window from now and is
+// not overdue yet. Nothing is due soon within a window of zero or less.
+func (t *Task) IsDueSoonWithin(window time.Duration) bool {
+	if t.Deadline == nil || t.Completed || window <= 0 {
 		return false
 	}
-	return time.Now().Add(24*time.Hour).After(*t.Deadline) && !t.IsOverdue()
+	now := time.Now()
+	return t.Deadline.Sub(WallClock(now)) < window && !t.IsOverdueAt(now)
+}
+
+// SnoozePreset is a quick choice for pushing a deadline back
+type SnoozePreset int
+
+const (
+	SnoozeHour SnoozePreset = iota
+	SnoozeThreeHours
+	SnoozeTomorrow
+	SnoozeNextWeek
+)
+
+// SnoozePresets lists the presets in the order they are offered
+var SnoozePresets = []SnoozePreset{SnoozeHour, SnoozeThreeHours, SnoozeTomorrow, SnoozeNextWeek}
+
+func (p SnoozePreset) String() string {
+	switch p {
+	case SnoozeHour:
+		return "1 hour"
+	case SnoozeThreeHours:
+		return "3 hours"
+	case SnoozeTomorrow:
+		return "Tomorrow 9am"
+	case SnoozeNextWeek:
+		return "Next week"
+	default:
+		return "Unknown"
+	}
+}
+
+// Apply returns the deadline after snoozing. Durations are added to the
+// current deadline while it is still ahead, otherwise to now, so an overdue
+// task always lands in the future.
+func (p SnoozePreset) Apply(deadline *time.Time, now time.Time) time.Time {
+	current := WallClock(now)
+	base := current
+	if deadline != nil && deadline.After(current) {
+		base = *deadline
+	}
+
+	switch p {
+	case SnoozeHour:
+		return base.Add(time.Hour)
+	case SnoozeThreeHours:
+		return base.Add(3 * time.Hour)
+	case SnoozeTomorrow:
+		return startOfDay(now).Add(24*time.Hour + 9*time.Hour)
+	default:
+		return base.AddDate(0, 0, 7)
+	}
+}
+
+// WallClock returns now in the deadline representation: the local wall-clock
+// time to the minute, stored as UTC like a parsed deadline. A deadline is
+// compared with the time only in this representation, so a deadline typed as
+// 15:00 comes due at 15:00 on the local clock in every time zone. Applying it
+// again changes nothing.
+func WallClock(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 0, 0, time.UTC)
 }
 
 // TodoList represents a collection of tasks
@@ -62,13 +601,103 @@ type TodoList struct {
 	ID          string    `json:"id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
+	Color       string    `json:"color,omitempty"`    // Accent color as a hex string, e.g. "#3B82F6"
+	Group       string    `json:"group,omitempty"`    // Sidebar heading the list is shown under; empty for none
+	Grouping    string    `json:"grouping,omitempty"` // How the task list is divided into sections, see GroupByPriority
+	Pinned      bool      `json:"pinned,omitempty"`   // Shown at the top of the sidebar, ahead of the lists not pinned
 	Tasks       []Task    `json:"tasks"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Summary holds task counts while Tasks has not been loaded yet; nil once loaded
+	Summary *TaskSummary `json:"-"`
+}
+
+// TaskSummary holds aggregate task counts for a list whose tasks are loaded lazily
+type TaskSummary struct {
+	Total     int
+	Completed int
+	Overdue   int // Counted when the list was loaded
+	DueSoon   int
+	Estimate  time.Duration
+	Remaining time.Duration // Estimate of the incomplete tasks
+	Spent     time.Duration
+}
+
+// TasksLoaded reports whether Tasks holds the list's tasks
+func (tl *TodoList) TasksLoaded() bool {
+	return tl.Summary == nil
+}
+
+// PutTask stores a task as storage returned it, replacing the task with the
+// same ID or adding it at the end. A list whose tasks are not loaded is left
+// alone; it reads the task with the rest once it is loaded.
+func (tl *TodoList) PutTask(task Task) {
+	if !tl.TasksLoaded() {
+		return
+	}
+
+	tl.UpdatedAt = time.Now()
+	for i := range tl.Tasks {
+		if tl.Tasks[i].ID == task.ID {
+			tl.Tasks[i] = task
+			return
+		}
+	}
+	tl.Tasks = append(tl.Tasks, task)
+}
+
+// PutTaskAfter is PutTask that adds a task new to the list right after the
+// task with the ID afterID rather than at the end
+func (tl *TodoList) PutTaskAfter(task Task, afterID string) {
+	after := slices.IndexFunc(tl.Tasks, func(t Task) bool { return t.ID == afterID })
+	if after < 0 || slices.ContainsFunc(tl.Tasks, func(t Task) bool { return t.ID == task.ID }) {
+		tl.PutTask(task)
+		return
+	}
+
+	tl.Tasks = slices.Insert(tl.Tasks, after+1, task)
+	tl.UpdatedAt = time.Now()
+}
+
+// RemoveTask drops a task from the list and reports whether it was there
+func (tl *TodoList) RemoveTask(taskID string) bool {
+	for i := range tl.Tasks {
+		if tl.Tasks[i].ID == taskID {
+			tl.Tasks = append(tl.Tasks[:i], tl.Tasks[i+1:]...)
+			tl.UpdatedAt = time.Now()
+			return true
+		}
+	}
+	return false
+}
+
+// DropTask takes a task storage moved out of the list off it: out of Tasks
+// once they are loaded, or out of the counts of Summary until they are. The
+// overdue and due soon counts of Summary are left as they were loaded.
+func (tl *TodoList) DropTask(task Task) {
+	if tl.TasksLoaded() {
+		tl.RemoveTask(task.ID)
+		return
+	}
+
+	tl.Summary.Total--
+	if task.Completed {
+		tl.Summary.Completed--
+	} else {
+		tl.Summary.Remaining -= task.Estimate
+	}
+	tl.Summary.Estimate -= task.Estimate
+	tl.Summary.Spent -= task.Spent
+	tl.UpdatedAt = time.Now()
 }
 
 // GetCompletedCount returns the number of completed tasks
 func (tl *TodoList) GetCompletedCount() int {
+	if tl.Summary != nil {
+		return tl.Summary.Completed
+	}
+
 	count := 0
 	for _, task := range tl.Tasks {
 		if task.Completed {
@@ -80,35 +709,486 @@ func (tl *TodoList) GetCompletedCount() int {
 
 // GetTotalCount returns the total number of tasks
 func (tl *TodoList) GetTotalCount() int {
+	if tl.Summary != nil {
+		return tl.Summary.Total
+	}
 	return len(tl.Tasks)
 }
 
+// GetDeadlineCounts returns the number of overdue tasks and of tasks due within
+// window. A list that is not loaded yet returns the counts it was
+// loaded with.
+func (tl *TodoList) GetDeadlineCounts(window time.Duration) (overdue, dueSoon int) {
+	if tl.Summary != nil {
+		return tl.Summary.Overdue, tl.Summary.DueSoon
+	}
+
+	for i := range tl.Tasks {
+		if tl.Tasks[i].IsOverdue() {
+			overdue++
+		} else if tl.Tasks[i].IsDueSoonWithin(window) {
+			dueSoon++
+		}
+	}
+	return overdue, dueSoon
+}
+
+// GetTimeTotals returns the estimated and spent time summed over all tasks
+func (tl *TodoList) GetTimeTotals() (estimate, spent time.Duration) {
+	if tl.Summary != nil {
+		return tl.Summary.Estimate, tl.Summary.Spent
+	}
+
+	for _, task := range tl.Tasks {
+		estimate += task.Estimate
+		spent += task.Spent
+	}
+	return estimate, spent
+}
+
+// GetRemainingEstimate returns the estimate summed over the incomplete tasks,
+// the work left on the list
+func (tl *TodoList) GetRemainingEstimate() time.Duration {
+	if tl.Summary != nil {
+		return tl.Summary.Remaining
+	}
+
+	var remaining time.Duration
+	for _, task := range tl.Tasks {
+		if !task.Completed {
+			remaining += task.Estimate
+		}
+	}
+	return remaining
+}
+
 // GetProgress returns the completion percentage
 func (tl *TodoList) GetProgress() float64 {
-	if len(tl.Tasks) == 0 {
+	total := tl.GetTotalCount()
+	if total == 0 {
 		return 0
 	}
-	return float64(tl.GetCompletedCount()) / float64(len(tl.Tasks)) * 100
+	return float64(tl.GetCompletedCount()) / float64(total) * 100
+}
+
+// Template is a reusable set of tasks that new lists can be created from
+type Template struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	Tasks     []TemplateTask `json:"tasks"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// TemplateTask is a task stored in a template. Its deadline is kept as an
+// offset from the start of the day the template is used.
+type TemplateTask struct {
+	Title          string         `json:"title"`
+	Description    string         `json:"description"`
+	Priority       Priority       `json:"priority"`
+	Label          string         `json:"label,omitempty"`
+	DeadlineOffset *time.Duration `json:"deadline_offset,omitempty"`
+}
+
+// NewTemplateTask captures a task for a template saved at now. Deadlines that
+// already passed keep their time of day and come due on the day of use.
+func NewTemplateTask(task Task, now time.Time) TemplateTask {
+	templateTask := TemplateTask{
+		Title:       task.Title,
+		Description: task.Description,
+		Priority:    task.Priority,
+		Label:       task.Label,
+	}
+
+	if task.Deadline != nil {
+		offset := task.Deadline.Sub(startOfDay(now))
+		if offset < 0 {
+			offset = offset%(24*time.Hour) + 24*time.Hour
+		}
+		templateTask.DeadlineOffset = &offset
+	}
+
+	return templateTask
+}
+
+// ResolveDeadline returns the deadline of the task for a template used at now
+func (t TemplateTask) ResolveDeadline(now time.Time) *time.Time {
+	if t.DeadlineOffset == nil {
+		return nil
+	}
+
+	deadline := startOfDay(now).Add(*t.DeadlineOffset)
+	return &deadline
+}
+
+// FormatOffset describes the relative deadline, e.g. "+3 days 17:00"
+func (t TemplateTask) FormatOffset() string {
+	if t.DeadlineOffset == nil {
+		return ""
+	}
+
+	days := int(*t.DeadlineOffset / (24 * time.Hour))
+	clock := *t.DeadlineOffset % (24 * time.Hour)
+	at := fmt.Sprintf("%02d:%02d", int(clock/time.Hour), int(clock%time.Hour/time.Minute))
+
+	switch days {
+	case 0:
+		return "same day " + at
+	case 1:
+		return "+1 day " + at
+	default:
+		return fmt.Sprintf("+%d days %s", days, at)
+	}
+}
+
+// startOfDay returns midnight of the day now falls on, in the same wall-clock
+// form deadlines are entered as by ParseDeadline
+func startOfDay(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ActivityKind describes what happened in an activity feed entry
+type ActivityKind int
+
+const (
+	TaskCreated ActivityKind = iota
+	TaskEdited
+	TaskCompleted
+	ListCreated
+)
+
+func (k ActivityKind) String() string {
+	switch k {
+	case TaskCreated:
+		return "Created"
+	case TaskEdited:
+		return "Edited"
+	case TaskCompleted:
+		return "Completed"
+	case ListCreated:
+		return "List created"
+	default:
+		return "Unknown"
+	}
+}
+
+// Activity is an entry of the recent activity feed. Entries are derived from
+// list and task timestamps, so each item contributes only its latest change.
+type Activity struct {
+	Kind     ActivityKind
+	ListID   string
+	ListName string
+	TaskID   string // Empty for list entries
+	Title    string
+	At       time.Time
+}
+
+// ChangeAction is what a change recorded in the activity log did
+type ChangeAction string
+
+const (
+	ActionCreated   ChangeAction = "created"
+	ActionEdited    ChangeAction = "edited"
+	ActionCompleted ChangeAction = "completed"
+	ActionReopened  ChangeAction = "reopened"
+	ActionDeleted   ChangeAction = "deleted"
+	ActionRestored  ChangeAction = "restored"
+	ActionMoved     ChangeAction = "moved"
+)
+
+// Kinds of item a change recorded in the activity log applies to
+const (
+	EntityTask = "task"
+	EntityList = "list"
+)
+
+// Change is an entry of the activity log, recorded by the storage layer when
+// a task or list changes. Unlike Activity, every change is kept. Old and New
+// summarize what an edit or move changed, e.g. "priority Low" and
+// "priority High".
+type Change struct {
+	At       time.Time    `json:"at"`
+	Action   ChangeAction `json:"action"`
+	Entity   string       `json:"entity"` // EntityTask or EntityList
+	EntityID string       `json:"entity_id"`
+	ListID   string       `json:"list_id"`
+	Title    string       `json:"title"` // Title of the task or name of the list after the change
+	Old      string       `json:"old,omitempty"`
+	New      string       `json:"new,omitempty"`
+}
+
+// Describe phrases the change for people, e.g. "Completed 'Pay rent'" or
+// "Moved list 'Work' from position 3 to 2"
+func (c Change) Describe() string {
+	what := fmt.Sprintf("'%s'", c.Title)
+	if c.Entity == EntityList {
+		what = "list " + what
+	}
+
+	var verb string
+	switch c.Action {
+	case ActionCreated:
+		verb = "Created"
+	case ActionEdited:
+		verb = "Edited"
+	case ActionCompleted:
+		verb = "Completed"
+	case ActionReopened:
+		verb = "Reopened"
+	case ActionDeleted:
+		verb = "Deleted"
+	case ActionRestored:
+		verb = "Restored"
+	case ActionMoved:
+		return fmt.Sprintf("Moved %s from position %s to %s", what, c.Old, c.New)
+	default:
+		verb = string(c.Action)
+	}
+
+	if c.Old != "" || c.New != "" {
+		return fmt.Sprintf("%s %s: %s → %s", verb, what, c.Old, c.New)
+	}
+	return verb + " " + what
+}
+
+// ListTask is a task together with the list it belongs to, for views that span all lists
+type ListTask struct {
+	ListID   string `json:"list_id"`
+	ListName string `json:"list_name"`
+	Task     Task   `json:"task"`
+}
+
+// Digest is what happened while the app was closed, for the welcome-back summary
+type Digest struct {
+	Overdue   []ListTask // Incomplete tasks whose deadline passed since the last session
+	DueToday  []ListTask // Incomplete tasks due during the rest of today
+	Completed []ListTask // Tasks completed since the last session started, latest first
+}
+
+// Empty reports whether the digest has nothing to show
+func (d Digest) Empty() bool {
+	return len(d.Overdue) == 0 && len(d.DueToday) == 0 && len(d.Completed) == 0
 }
 
 // Application represents the entire application state
 type Application struct {
 	TodoLists []TodoList `json:"todo_lists"`
+	Templates []Template `json:"templates,omitempty"`
+	Trash     []ListTask `json:"trash,omitempty"` // Deleted tasks kept by the JSON storage; the database keeps its own
 	Settings  Settings   `json:"settings"`
 }
 
 // Settings represents application settings
 type Settings struct {
-	ReminderMinutes int  `json:"reminder_minutes"` // Minutes before deadline to remind
-	ShowCompleted   bool `json:"show_completed"`   // Whether to show completed tasks
-	AutoSave        bool `json:"auto_save"`        // Whether to auto-save changes
+	ReminderMinutes        int    `json:"reminder_minutes"`          // Minutes before deadline to remind
+	ShowCompleted          bool   `json:"show_completed"`            // Whether to show completed tasks
+	AutoSave               bool   `json:"auto_save"`                 // Whether to auto-save changes
+	Icons                  string `json:"icons"`                     // Icon set: emoji, nerd or ascii
+	DateFormat             string `json:"date_format"`               // Deadline format: iso, us, eu or a Go layout
+	SyncRepo               string `json:"sync_repo"`                 // Git repository for syncing an export; empty disables sync
+	DesktopNotify          bool   `json:"desktop_notify"`            // Also show reminders as desktop notifications
+	SetupComplete          bool   `json:"setup_complete"`            // The first-run setup wizard was finished or skipped
+	TrashDays              int    `json:"trash_days"`                // Days deleted tasks stay in the trash before they are purged
+	DayTaskLimit           int    `json:"day_task_limit"`            // Incomplete tasks due on one day before a new deadline there warns; below 0 never warns
+	SidebarWidth           int    `json:"sidebar_width"`             // Sidebar width in columns; 0 sizes it to the screen
+	DueSoonHours           int    `json:"due_soon_hours"`            // Hours before its deadline a task counts as due soon; below 0 never
+	Streak                 int    `json:"streak"`                    // Consecutive days with a completed task, as of StreakDay
+	StreakDay              string `json:"streak_day"`                // Last day of the streak as YYYY-MM-DD; empty for none
+	ReviewDay              int    `json:"review_day"`                // Weekday of the weekly review reminder, 1 for Monday to 7 for Sunday; 0 turns it off
+	ReviewHour             int    `json:"review_hour"`               // Hour of the day the weekly review reminder fires
+	LastReview             string `json:"last_review"`               // When the weekly review reminder last fired, as RFC 3339; empty for never
+	UniqueListNames        bool   `json:"unique_list_names"`         // Refuse a list name another list has, rather than only warning
+	QuietHours             string `json:"quiet_hours"`               // Daily span without reminders, e.g. "22:00-07:00"; empty for none
+	ShowHeatmap            bool   `json:"show_heatmap"`              // Show the completions of the last weeks at the top of the sidebar
+	StaleDays              int    `json:"stale_days"`                // Days an open task may sit before it counts as stale; below 0 never
+	CompletedLinger        int    `json:"completed_linger"`          // Seconds a task just completed stays listed while completed tasks are hidden; 0 hides it at once
+	CriticalRepeatMinutes  int    `json:"critical_repeat_minutes"`   // Minutes between repeated reminders about overdue Critical tasks; below 0 never
+	CompleteAtFullProgress bool   `json:"complete_at_full_progress"` // Complete a task when its progress reaches 100%
+	BulkConfirmThreshold   int    `json:"bulk_confirm_threshold"`    // Tasks a bulk completion may change before it asks first; below 0 never asks
+	LastOpenedAt           string `json:"last_opened_at"`            // When the app was last started, as RFC 3339; empty for never
+	WelcomeBackHours       int    `json:"welcome_back_hours"`        // Hours away before the welcome-back summary shows at startup; below 0 never
+	ClearStarsDaily        bool   `json:"clear_stars_daily"`         // Unstar the tasks completed before today at midnight
+	PurgeCompletedDays     int    `json:"purge_completed_days"`      // Days after completion a task is moved to the trash at startup; 0 never
+}
+
+// CalendarDay returns the start of the calendar day t falls on, in t's
+// location, and the start of the next day
+func CalendarDay(t time.Time) (time.Time, time.Time) {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 0, 1)
+}
+
+// StreakDayLayout is the format of Settings.StreakDay
+const StreakDayLayout = "2006-01-02"
+
+// CompletionStreak returns how many consecutive days up to now had at least
+// one completion, using the calendar of now's location. A streak with nothing
+// completed today yet still counts from yesterday until the day is over. It
+// returns the last day of the streak too, which is zero without a streak.
+func CompletionStreak(completions []time.Time, now time.Time) (int, time.Time) {
+	days := make(map[string]bool, len(completions))
+	for _, completed := range completions {
+		days[completed.In(now.Location()).Format(StreakDayLayout)] = true
+	}
+
+	// Days are stepped at midnight so a daylight saving change cannot skip one
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if !days[day.Format(StreakDayLayout)] {
+		day = day.AddDate(0, 0, -1)
+	}
+	last := day
+
+	streak := 0
+	for days[day.Format(StreakDayLayout)] {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	if streak == 0 {
+		return 0, time.Time{}
+	}
+	return streak, last
+}
+
+// CompletionCounts counts the completions from from up to, but not including,
+// to per day, keyed by the day in StreakDayLayout on the calendar of from's
+// location. Days without completions are left out.
+func CompletionCounts(completions []time.Time, from, to time.Time) map[string]int {
+	counts := make(map[string]int)
+	for _, completed := range completions {
+		if completed.Before(from) || !completed.Before(to) {
+			continue
+		}
+		counts[completed.In(from.Location()).Format(StreakDayLayout)]++
+	}
+	return counts
+}
+
+// StaleAge returns how many days task has sat at now, counted as Task.Age
+// counts them, and whether that makes it stale: it is open and older than
+// the stale_days setting allows
+func (s Settings) StaleAge(task *Task, now time.Time, fromUpdate bool) (int, bool) {
+	age := task.Age(now, fromUpdate)
+	return age, s.StaleDays > 0 && !task.Completed && task.DeletedAt == nil && age > s.StaleDays
+}
+
+// DueSoonWindow returns how long before its deadline a task counts as due
+// soon, or 0 when the due_soon_hours setting turns that off
+func (s Settings) DueSoonWindow() time.Duration {
+	if s.DueSoonHours <= 0 {
+		return 0
+	}
+	// Cap the hours so a huge setting cannot overflow the duration
+	return time.Duration(min(int64(s.DueSoonHours), math.MaxInt64/int64(time.Hour))) * time.Hour
+}
+
+// CurrentStreak returns the saved streak if it is still running at now,
+// meaning its last day is today or yesterday, and 0 otherwise
+func (s Settings) CurrentStreak(now time.Time) int {
+	last, err := time.ParseInLocation(StreakDayLayout, s.StreakDay, now.Location())
+	if err != nil || s.Streak <= 0 {
+		return 0
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if last.Equal(today) || last.Equal(today.AddDate(0, 0, -1)) {
+		return s.Streak
+	}
+	return 0
+}
+
+// ReviewStart returns when the weekly review period now falls in began: the
+// latest ReviewDay at ReviewHour that is not after now. It returns false when
+// the weekly review is off.
+func (s Settings) ReviewStart(now time.Time) (time.Time, bool) {
+	if s.ReviewDay < 1 || s.ReviewDay > 7 {
+		return time.Time{}, false
+	}
+
+	weekday := time.Weekday(s.ReviewDay % 7)
+	daysBack := (int(now.Weekday()) - int(weekday) + 7) % 7
+	start := time.Date(now.Year(), now.Month(), now.Day()-daysBack, s.ReviewHour, 0, 0, 0, now.Location())
+	if start.After(now) {
+		start = start.AddDate(0, 0, -7)
+	}
+	return start, true
+}
+
+// ReviewDue reports whether the weekly review reminder should fire at now,
+// which it does once per period: when it has not fired since the period began
+func (s Settings) ReviewDue(now time.Time) bool {
+	start, ok := s.ReviewStart(now)
+	if !ok {
+		return false
+	}
+	last, err := time.Parse(time.RFC3339, s.LastReview)
+	return err != nil || last.Before(start)
+}
+
+// QuietHours is a daily span of wall-clock time, which may run past midnight
+type QuietHours struct {
+	Start, End int // Minutes after midnight
+}
+
+// ParseQuietHours parses a span such as "22:00-07:00"
+func ParseQuietHours(value string) (QuietHours, error) {
+	invalid := fmt.Errorf("invalid quiet hours %q: use e.g. 22:00-07:00", value)
+	clock := func(text string) (int, bool) {
+		t, err := time.Parse("15:04", strings.TrimSpace(text))
+		return t.Hour()*60 + t.Minute(), err == nil
+	}
+
+	startText, endText, ok := strings.Cut(strings.TrimSpace(value), "-")
+	if !ok {
+		return QuietHours{}, invalid
+	}
+	start, startOK := clock(startText)
+	end, endOK := clock(endText)
+	if !startOK || !endOK {
+		return QuietHours{}, invalid
+	}
+	if start == end {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours %q: start and end are the same", value)
+	}
+	return QuietHours{Start: start, End: end}, nil
+}
+
+// Contains reports whether the wall-clock time of t falls within the span
+func (q QuietHours) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if q.Start < q.End {
+		return minute >= q.Start && minute < q.End
+	}
+	return minute >= q.Start || minute < q.End
+}
+
+// String renders the span as it is written in the settings, e.g. "22:00-07:00"
+func (q QuietHours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", q.Start/60, q.Start%60, q.End/60, q.End%60)
+}
+
+// InQuietHours reports whether now falls within the quiet hours; without
+// valid quiet hours it never does
+func (s Settings) InQuietHours(now time.Time) bool {
+	if s.QuietHours == "" {
+		return false
+	}
+	q, err := ParseQuietHours(s.QuietHours)
+	return err == nil && q.Contains(now)
 }
 
 // DefaultSettings returns default application settings
 func DefaultSettings() Settings {
 	return Settings{
-		ReminderMinutes: 60, // 1 hour before deadline
-		ShowCompleted:   true,
-		AutoSave:        true,
+		ReminderMinutes:       60, // 1 hour before deadline
+		ShowCompleted:         true,
+		AutoSave:              true,
+		Icons:                 "emoji",
+		DateFormat:            "iso",
+		TrashDays:             30,
+		DayTaskLimit:          5,
+		DueSoonHours:          24,
+		ReviewHour:            9,
+		StaleDays:             14,
+		CriticalRepeatMinutes: 30,
+		BulkConfirmThreshold:  10,
+		WelcomeBackHours:      12,
 	}
 }
@@ -0,0 +1,382 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+// inTimeZone runs the test with name as the local time zone
+func inTimeZone(t *testing.T, name string) {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", name, err)
+	}
+	local := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = local })
+}
+
+var testTimeZones = []string{"UTC", "America/New_York", "America/Los_Angeles", "Europe/Berlin", "Asia/Kolkata", "Pacific/Auckland"}
+
+func TestSnoozeClearsOverdue(t *testing.T) {
+	for _, zone := range testTimeZones {
+		t.Run(zone, func(t *testing.T) {
+			inTimeZone(t, zone)
+			now := time.Now()
+			due := WallClock(now).Add(-2 * time.Hour)
+			task := Task{Deadline: &due}
+			if !task.IsOverdue() {
+				t.Fatalf("task due %s is not overdue at %s", due, now)
+			}
+
+			for _, preset := range SnoozePresets {
+				deadline := preset.Apply(task.Deadline, now)
+				snoozed := Task{Deadline: &deadline}
+				if snoozed.IsOverdue() {
+					t.Errorf("%s: snoozed to %s, still overdue at %s", preset, deadline, now)
+				}
+				if !snoozed.IsOverdueAt(now.AddDate(0, 0, 8)) {
+					t.Errorf("%s: snoozed to %s, not overdue 8 days later", preset, deadline)
+				}
+			}
+
+			deadline := SnoozeHour.Apply(task.Deadline, now)
+			if want := WallClock(now).Add(time.Hour); !deadline.Equal(want) {
+				t.Errorf("1 hour snooze gave %s, want %s", deadline, want)
+			}
+			snoozed := Task{Deadline: &deadline}
+			if !snoozed.IsOverdueAt(now.Add(61 * time.Minute)) {
+				t.Errorf("1 hour snooze to %s is not overdue 61 minutes later", deadline)
+			}
+		})
+	}
+}
+
+func TestDeadlineTypedAsLocalTime(t *testing.T) {
+	for _, zone := range testTimeZones {
+		t.Run(zone, func(t *testing.T) {
+			inTimeZone(t, zone)
+			now := time.Date(2026, 3, 10, 14, 30, 0, 0, time.Local)
+			deadline, err := ParseDeadline("2026-03-10 15:00", DeadlineLayout)
+			if err != nil {
+				t.Fatalf("ParseDeadline: %v", err)
+			}
+			task := Task{Deadline: deadline}
+
+			tests := []struct {
+				at      time.Time
+				overdue bool
+			}{
+				{now, false},
+				{now.Add(30 * time.Minute), false},
+				{now.Add(31 * time.Minute), true},
+			}
+			for _, tt := range tests {
+				if got := task.IsOverdueAt(tt.at); got != tt.overdue {
+					t.Errorf("IsOverdueAt(%s) = %v, want %v", tt.at, got, tt.overdue)
+				}
+			}
+			if bucket := DeadlineBucket(deadline, now); bucket != BucketToday {
+				t.Errorf("DeadlineBucket at %s = %d, want BucketToday", now, bucket)
+			}
+		})
+	}
+}
+
+func TestCompletionStreak(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone America/New_York not available: %v", err)
+	}
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skipf("time zone Asia/Kolkata not available: %v", err)
+	}
+	at := func(loc *time.Location, day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name        string
+		completions []time.Time
+		now         time.Time
+		want        int
+		wantLast    string
+	}{
+		{"nothing completed", nil, at(newYork, 10, 12, 0), 0, ""},
+		{"today only", []time.Time{at(newYork, 10, 9, 0)}, at(newYork, 10, 12, 0), 1, "2026-03-10"},
+		{"runs on from yesterday", []time.Time{at(newYork, 8, 9, 0), at(newYork, 9, 23, 59)}, at(newYork, 10, 0, 1), 2, "2026-03-09"},
+		{"broken by a gap", []time.Time{at(newYork, 6, 9, 0), at(newYork, 8, 9, 0), at(newYork, 9, 9, 0), at(newYork, 10, 9, 0)},
+			at(newYork, 10, 12, 0), 3, "2026-03-10"},
+		{"over by the day after yesterday", []time.Time{at(newYork, 7, 9, 0), at(newYork, 8, 9, 0)}, at(newYork, 10, 12, 0), 0, ""},
+		{"several on a day count once", []time.Time{at(newYork, 10, 8, 0), at(newYork, 10, 9, 0), at(newYork, 10, 10, 0)},
+			at(newYork, 10, 12, 0), 1, "2026-03-10"},
+		// Clocks go forward on 8 March in New York; the day is still a day
+		{"across daylight saving", []time.Time{at(newYork, 7, 22, 0), at(newYork, 8, 3, 30), at(newYork, 9, 1, 0)},
+			at(newYork, 9, 12, 0), 3, "2026-03-09"},
+		// 20:00 UTC is already the next day in Kolkata, and the days follow its calendar
+		{"days of now's time zone", []time.Time{time.Date(2026, 3, 8, 20, 0, 0, 0, time.UTC), time.Date(2026, 3, 10, 3, 0, 0, 0, time.UTC)},
+			at(kolkata, 10, 12, 0), 2, "2026-03-10"},
+		{"same completions in New York", []time.Time{time.Date(2026, 3, 8, 20, 0, 0, 0, time.UTC), time.Date(2026, 3, 10, 3, 0, 0, 0, time.UTC)},
+			at(newYork, 10, 12, 0), 2, "2026-03-09"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, last := CompletionStreak(test.completions, test.now)
+			gotLast := ""
+			if !last.IsZero() {
+				gotLast = last.Format(StreakDayLayout)
+			}
+			if got != test.want || gotLast != test.wantLast {
+				t.Errorf("CompletionStreak() = %d ending %q, want %d ending %q", got, gotLast, test.want, test.wantLast)
+			}
+		})
+	}
+}
+
+func TestCurrentStreak(t *testing.T) {
+	now := time.Date(2026, 3, 10, 0, 30, 0, 0, time.UTC)
+	tests := []struct {
+		day    string
+		streak int
+		want   int
+	}{
+		{"2026-03-10", 4, 4},
+		{"2026-03-09", 4, 4},
+		{"2026-03-08", 4, 0},
+		{"", 4, 0},
+		{"2026-03-10", 0, 0},
+	}
+	for _, test := range tests {
+		settings := Settings{Streak: test.streak, StreakDay: test.day}
+		if got := settings.CurrentStreak(now); got != test.want {
+			t.Errorf("CurrentStreak() of %d days ending %q = %d, want %d", test.streak, test.day, got, test.want)
+		}
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	valid := map[string]Priority{
+		"low": Low, "Medium": Medium, "HIGH": High, "critical": Critical,
+		"l": Low, "M": Medium, "h": High, "C": Critical,
+		"0": Low, "1": Medium, "2": High, "3": Critical,
+		"  high\t": High,
+	}
+	for value, want := range valid {
+		got, err := ParsePriority(value)
+		if err != nil || got != want {
+			t.Errorf("ParsePriority(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "urgent", "4", "-1", "hi", "1.0"} {
+		if got, err := ParsePriority(value); err == nil {
+			t.Errorf("ParsePriority(%q) = %v, want an error", value, got)
+		}
+	}
+}
+
+func TestPriorityLevels(t *testing.T) {
+	for _, tt := range []struct {
+		priority Priority
+		valid    bool
+		clamped  Priority
+	}{
+		{Low, true, Low},
+		{Critical, true, Critical},
+		{-1, false, Low},
+		{4, false, Critical},
+		{100, false, Critical},
+	} {
+		if got := tt.priority.Valid(); got != tt.valid {
+			t.Errorf("Priority(%d).Valid() = %v, want %v", int(tt.priority), got, tt.valid)
+		}
+		if got := tt.priority.Clamp(); got != tt.clamped {
+			t.Errorf("Priority(%d).Clamp() = %v, want %v", int(tt.priority), got, tt.clamped)
+		}
+	}
+}
+
+func TestPriorityJSON(t *testing.T) {
+	for _, priority := range []Priority{Low, Medium, High, Critical} {
+		data, err := json.Marshal(priority)
+		if err != nil {
+			t.Fatalf("marshalling %v: %v", priority, err)
+		}
+		var read Priority
+		if err := json.Unmarshal(data, &read); err != nil || read != priority {
+			t.Errorf("%s read back as %v, %v, want %v", data, read, err, priority)
+		}
+	}
+	// JSON uses the name the UI and the CLI show
+	if data, _ := json.Marshal(High); string(data) != `"`+High.String()+`"` {
+		t.Errorf("High marshals to %s, want %q", data, High.String())
+	}
+	if _, err := json.Marshal(Priority(4)); err == nil {
+		t.Error("marshalling priority 4 succeeded, want an error")
+	}
+
+	// Files from before priorities had names hold numbers
+	for data, want := range map[string]Priority{`2`: High, `"H"`: High, `"3"`: Critical, `0`: Low, `"high"`: High, `"CRITICAL"`: Critical} {
+		var read Priority
+		if err := json.Unmarshal([]byte(data), &read); err != nil || read != want {
+			t.Errorf("reading %s = %v, %v, want %v", data, read, err, want)
+		}
+	}
+	for _, data := range []string{`4`, `-1`, `"urgent"`, `true`, `2.5`} {
+		var read Priority
+		if err := json.Unmarshal([]byte(data), &read); err == nil {
+			t.Errorf("reading %s = %v, want an error", data, read)
+		}
+	}
+	read := High
+	if err := json.Unmarshal([]byte(`null`), &read); err != nil || read != High {
+		t.Errorf("reading null over High = %v, %v, want it left alone", read, err)
+	}
+}
+
+func TestDueSoonWindow(t *testing.T) {
+	for hours, want := range map[int]time.Duration{
+		-1:  0,
+		0:   0,
+		1:   time.Hour,
+		24:  24 * time.Hour,
+		168: 7 * 24 * time.Hour,
+	} {
+		if got := (Settings{DueSoonHours: hours}).DueSoonWindow(); got != want {
+			t.Errorf("DueSoonWindow() with %d hours = %v, want %v", hours, got, want)
+		}
+	}
+	// Hours beyond what a duration holds are capped rather than wrapping round
+	largest := time.Duration(math.MaxInt64/int64(time.Hour)) * time.Hour
+	for _, hours := range []int{math.MaxInt64 / int(time.Hour), math.MaxInt64/int(time.Hour) + 1, math.MaxInt} {
+		if got := (Settings{DueSoonHours: hours}).DueSoonWindow(); got != largest {
+			t.Errorf("DueSoonWindow() with %d hours = %v, want %v", hours, got, largest)
+		}
+	}
+}
+
+func TestIsDueSoonWithin(t *testing.T) {
+	now := WallClock(time.Now())
+	at := func(d time.Duration) *time.Time {
+		deadline := now.Add(d)
+		return &deadline
+	}
+	huge := (Settings{DueSoonHours: math.MaxInt}).DueSoonWindow()
+	for _, tt := range []struct {
+		name     string
+		task     Task
+		window   time.Duration
+		expected bool
+	}{
+		{"inside the window", Task{Deadline: at(30 * time.Minute)}, time.Hour, true},
+		{"past the window", Task{Deadline: at(2 * time.Hour)}, time.Hour, false},
+		{"a minute past the window", Task{Deadline: at(time.Hour + time.Minute)}, time.Hour, false},
+		{"window turned off", Task{Deadline: at(time.Minute)}, 0, false},
+		{"negative window", Task{Deadline: at(time.Minute)}, -time.Hour, false},
+		{"huge window", Task{Deadline: at(100 * 365 * 24 * time.Hour)}, huge, true},
+		{"overdue", Task{Deadline: at(-time.Hour)}, huge, false},
+		{"completed", Task{Deadline: at(time.Minute), Completed: true}, time.Hour, false},
+		{"no deadline", Task{}, huge, false},
+	} {
+		if got := tt.task.IsDueSoonWithin(tt.window); got != tt.expected {
+			t.Errorf("%s: IsDueSoonWithin(%v) = %v, want %v", tt.name, tt.window, got, tt.expected)
+		}
+	}
+}
+
+func TestParseQuietHours(t *testing.T) {
+	for value, want := range map[string]QuietHours{
+		"22:00-07:00":     {22 * 60, 7 * 60},
+		" 09:30 - 17:45 ": {9*60 + 30, 17*60 + 45},
+		"00:00-08:00":     {0, 8 * 60},
+	} {
+		got, err := ParseQuietHours(value)
+		if err != nil || got != want {
+			t.Errorf("ParseQuietHours(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "22:00", "22:00-07:00-08:00", "25:00-07:00", "22:00-7", "10pm-7am", "08:00-08:00"} {
+		if got, err := ParseQuietHours(value); err == nil {
+			t.Errorf("ParseQuietHours(%q) = %v, want an error", value, got)
+		}
+	}
+	if got := (QuietHours{22 * 60, 7*60 + 5}).String(); got != "22:00-07:05" {
+		t.Errorf("String() = %q, want 22:00-07:05", got)
+	}
+}
+
+func TestInQuietHours(t *testing.T) {
+	day := func(hour, minute int) time.Time {
+		return time.Date(2026, 3, 8, hour, minute, 0, 0, time.Local)
+	}
+	for _, tt := range []struct {
+		quiet    string
+		at       time.Time
+		expected bool
+	}{
+		{"22:00-07:00", day(23, 30), true},
+		{"22:00-07:00", day(3, 0), true},
+		{"22:00-07:00", day(22, 0), true},
+		{"22:00-07:00", day(7, 0), false},
+		{"22:00-07:00", day(12, 0), false},
+		{"09:00-17:00", day(12, 0), true},
+		{"09:00-17:00", day(17, 0), false},
+		{"09:00-17:00", day(8, 59), false},
+		{"", day(3, 0), false},
+		{"not hours", day(3, 0), false},
+	} {
+		if got := (Settings{QuietHours: tt.quiet}).InQuietHours(tt.at); got != tt.expected {
+			t.Errorf("InQuietHours(%s) with %q = %v, want %v", tt.at.Format("15:04"), tt.quiet, got, tt.expected)
+		}
+	}
+}
+
+func TestParseEffort(t *testing.T) {
+	for value, want := range map[string]time.Duration{
+		"":      0,
+		"45":    45 * time.Minute,
+		"30m":   30 * time.Minute,
+		"1h30m": 90 * time.Minute,
+		" 2h ":  2 * time.Hour,
+		"1d":    WorkDay,
+		"1.5d":  12 * time.Hour,
+		"2d4h":  20 * time.Hour,
+		"0d30":  30 * time.Minute,
+		"0.1d":  48 * time.Minute,
+		"90s":   2 * time.Minute,
+	} {
+		got, err := ParseEffort(value)
+		if err != nil || got != want {
+			t.Errorf("ParseEffort(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"d", "-1d", "-30m", "-5", "1d-2h", "xd", "NaNd", "Infd", "1e12d", "1d1d", "two hours", "999999999h"} {
+		if got, err := ParseEffort(value); err == nil {
+			t.Errorf("ParseEffort(%q) = %v, want an error", value, got)
+		}
+	}
+}
+
+func TestRemainingEstimate(t *testing.T) {
+	list := TodoList{Tasks: []Task{
+		{Estimate: 2 * time.Hour, Spent: time.Hour},
+		{Estimate: 30 * time.Minute},
+		{Estimate: 4 * time.Hour, Completed: true},
+		{},
+	}}
+	if got := list.GetRemainingEstimate(); got != 150*time.Minute {
+		t.Errorf("GetRemainingEstimate() = %v, want 2h30m", got)
+	}
+	if estimate, spent := list.GetTimeTotals(); estimate != 390*time.Minute || spent != time.Hour {
+		t.Errorf("GetTimeTotals() = %v, %v, want 6h30m, 1h", estimate, spent)
+	}
+
+	// A list that is not loaded yet answers from its summary
+	list.Summary = &TaskSummary{Remaining: 5 * time.Hour}
+	if got := list.GetRemainingEstimate(); got != 5*time.Hour {
+		t.Errorf("GetRemainingEstimate() of a summary = %v, want 5h", got)
+	}
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/rivo/uniseg"
+)
+
+// MaxTitleLength is the most characters a task, list or template title may have
+const MaxTitleLength = 200
+
+// UntitledTitle replaces an imported title that is empty once normalized
+const UntitledTitle = "Untitled"
+
+var (
+	// ErrEmptyTitle is returned for a title with nothing left once normalized
+	ErrEmptyTitle = errors.New("title must not be empty")
+
+	// ErrTitleTooLong is returned for a title longer than MaxTitleLength characters
+	ErrTitleTooLong = fmt.Errorf("title must be at most %d characters", MaxTitleLength)
+)
+
+// CleanText drops what pasted text can carry that would garble rendering:
+// invalid UTF-8, ANSI escape sequences and control characters. With
+// multiline set, newlines and tabs are kept, and CRLF line endings become
+// LF; otherwise they become spaces. Format characters such as the zero width
+// joiner of emoji sequences and combining marks are left alone. The UI runs
+// stored text through it too, as text saved before it was cleaned on entry
+// may still carry any of these.
+func CleanText(text string, multiline bool) string {
+	text = ansi.Strip(strings.ToValidUTF8(text, ""))
+	if multiline {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case multiline && (r == '\n' || r == '\t'):
+			return r
+		case r == '\n' || r == '\r' || r == '\t':
+			return ' '
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// collapseSpaces trims surrounding whitespace and shortens each run of
+// whitespace inside to its first character, so a single ideographic or
+// no-break space stays what it is
+func collapseSpaces(text string) string {
+	var collapsed strings.Builder
+	inSpace := false
+	for _, r := range strings.TrimSpace(text) {
+		if unicode.IsSpace(r) {
+			if inSpace {
+				continue
+			}
+			inSpace = true
+		} else {
+			inSpace = false
+		}
+		collapsed.WriteRune(r)
+	}
+	return collapsed.String()
+}
+
+// NormalizeTitle cleans a task, list or template title for storing: control
+// characters and escape sequences are dropped, surrounding whitespace is
+// trimmed and runs of whitespace inside are collapsed to one character. It
+// returns ErrEmptyTitle when nothing is left, and ErrTitleTooLong when more
+// than MaxTitleLength characters are.
+func NormalizeTitle(title string) (string, error) {
+	title = collapseSpaces(CleanText(title, false))
+	if title == "" {
+		return "", ErrEmptyTitle
+	}
+	if length := utf8.RuneCountInString(title); length > MaxTitleLength {
+		return "", fmt.Errorf("%w (%d now)", ErrTitleTooLong, length)
+	}
+	return title, nil
+}
+
+// NormalizeText cleans a description or note for storing: control characters
+// and escape sequences are dropped, keeping newlines and tabs, and
+// surrounding whitespace is trimmed
+func NormalizeText(text string) string {
+	return strings.TrimSpace(CleanText(text, true))
+}
+
+// FitTitle normalizes a title that cannot be turned down, as in imported or
+// migrated data: one that is too long is cut at the last whole character,
+// emoji sequences and combining marks included, that fits, and one with
+// nothing left becomes UntitledTitle
+func FitTitle(title string) string {
+	title = collapseSpaces(CleanText(title, false))
+	if utf8.RuneCountInString(title) > MaxTitleLength {
+		var fitted strings.Builder
+		length := 0
+		graphemes := uniseg.NewGraphemes(title)
+		for graphemes.Next() {
+			runes := graphemes.Runes()
+			if length+len(runes) > MaxTitleLength {
+				break
+			}
+			length += len(runes)
+			fitted.WriteString(graphemes.Str())
+		}
+		title = strings.TrimSpace(fitted.String())
+	}
+	if title == "" {
+		return UntitledTitle
+	}
+	return title
+}
+
+// Normalize cleans the titles and text of imported or migrated data as
+// NormalizeTitle and NormalizeText would when they are entered. Titles that
+// would be turned down are fitted with FitTitle instead, so no record is lost.
+func (a *Application) Normalize() {
+	for i := range a.TodoLists {
+		list := &a.TodoLists[i]
+		list.Name = FitTitle(list.Name)
+		list.Description = NormalizeText(list.Description)
+		for j := range list.Tasks {
+			task := &list.Tasks[j]
+			task.Title = FitTitle(task.Title)
+			task.Description = NormalizeText(task.Description)
+			for k := range task.Notes {
+				task.Notes[k].Body = NormalizeText(task.Notes[k].Body)
+			}
+		}
+	}
+	for i := range a.Templates {
+		template := &a.Templates[i]
+		template.Name = FitTitle(template.Name)
+		for j := range template.Tasks {
+			template.Tasks[j].Title = FitTitle(template.Tasks[j].Title)
+			template.Tasks[j].Description = NormalizeText(template.Tasks[j].Description)
+		}
+	}
+}
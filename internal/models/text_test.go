@@ -0,0 +1,125 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	for _, tt := range []struct {
+		title string
+		want  string
+	}{
+		{"  Write   report  ", "Write report"},
+		{"Write\treport\r\nnow", "Write report now"},
+		{"\x1b[31mRed\x1b[0m task", "Red task"},
+		{"\x1b]8;;https://example.com\x07Linked\x1b]8;;\x07 task", "Linked task"},
+		{"Bell\x07 and\x00 null", "Bell and null"},
+		{"bad \xff\xfe bytes", "bad bytes"},
+		{"Family 👨‍👩‍👧 trip", "Family 👨‍👩‍👧 trip"},
+		{"éclair", "éclair"},
+		{"A  B", "A B"},
+		{"全角　　スペース", "全角　スペース"},
+		{strings.Repeat("é", MaxTitleLength), strings.Repeat("é", MaxTitleLength)},
+	} {
+		got, err := NormalizeTitle(tt.title)
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeTitle(%q) = %q, %v, want %q", tt.title, got, err, tt.want)
+		}
+	}
+
+	for title, want := range map[string]error{
+		"":                                    ErrEmptyTitle,
+		" \t\r\n ":                            ErrEmptyTitle,
+		"\x1b[2J\x1b[H":                       ErrEmptyTitle,
+		strings.Repeat("é", MaxTitleLength+1): ErrTitleTooLong,
+		// Escape sequences do not count towards the length
+		strings.Repeat("\x1b[1ma\x1b[0m", MaxTitleLength) + "b": ErrTitleTooLong,
+	} {
+		if got, err := NormalizeTitle(title); !errors.Is(err, want) {
+			t.Errorf("NormalizeTitle(%q) = %q, %v, want %v", title, got, err, want)
+		}
+	}
+	if _, err := NormalizeTitle(strings.Repeat("\x1b[1ma\x1b[0m", MaxTitleLength)); err != nil {
+		t.Errorf("a title of %d characters in escape sequences: %v", MaxTitleLength, err)
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	for text, want := range map[string]string{
+		"":                                   "",
+		"  One line  ":                       "One line",
+		"First\r\nSecond\n\tIndented":        "First\nSecond\n\tIndented",
+		"\x1b[1mBold\x1b[0m and\x00 \x07odd": "Bold and odd",
+		"\n\nKeeps   inner  spacing\n\n":     "Keeps   inner  spacing",
+		"Lone\rreturn":                       "Lone return",
+	} {
+		if got := NormalizeText(text); got != want {
+			t.Errorf("NormalizeText(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestFitTitle(t *testing.T) {
+	family := "👨‍👩‍👧" // Five runes that are one character on screen
+	for _, tt := range []struct {
+		title string
+		want  string
+	}{
+		{"", UntitledTitle},
+		{"\x1b[0m \t", UntitledTitle},
+		{" Short  title ", "Short title"},
+		{strings.Repeat("é", MaxTitleLength+50), strings.Repeat("é", MaxTitleLength)},
+		// A sequence that does not fit is left out whole rather than split
+		{strings.Repeat("a", MaxTitleLength-2) + family, strings.Repeat("a", MaxTitleLength-2)},
+		{strings.Repeat("a", MaxTitleLength-5) + family + "b", strings.Repeat("a", MaxTitleLength-5) + family},
+		{strings.Repeat("a", MaxTitleLength-1) + " b", strings.Repeat("a", MaxTitleLength-1)},
+	} {
+		got := FitTitle(tt.title)
+		if got != tt.want {
+			t.Errorf("FitTitle(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+		if length := utf8.RuneCountInString(got); length > MaxTitleLength {
+			t.Errorf("FitTitle(%q) has %d characters", tt.title, length)
+		}
+	}
+}
+
+func TestApplicationNormalize(t *testing.T) {
+	app := Application{
+		TodoLists: []TodoList{{
+			Name:        "  \x1b[1mWork\x1b[0m ",
+			Description: "Things\r\nto do ",
+			Tasks: []Task{{
+				Title:       "",
+				Description: "\tDetails\x00",
+				Notes:       []Note{{Body: " Called \x1b[32mBob\x1b[0m\r\n"}},
+			}},
+		}},
+		Templates: []Template{{
+			Name:  strings.Repeat("x", MaxTitleLength+1),
+			Tasks: []TemplateTask{{Title: "Step\none", Description: "  "}},
+		}},
+	}
+	app.Normalize()
+
+	list := app.TodoLists[0]
+	task := list.Tasks[0]
+	template := app.Templates[0]
+	for _, tt := range []struct{ field, got, want string }{
+		{"list name", list.Name, "Work"},
+		{"list description", list.Description, "Things\nto do"},
+		{"task title", task.Title, UntitledTitle},
+		{"task description", task.Description, "Details"},
+		{"note", task.Notes[0].Body, "Called Bob"},
+		{"template name", template.Name, strings.Repeat("x", MaxTitleLength)},
+		{"template task title", template.Tasks[0].Title, "Step one"},
+		{"template task description", template.Tasks[0].Description, ""},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.field, tt.got, tt.want)
+		}
+	}
+}
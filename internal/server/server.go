@@ -0,0 +1,453 @@
+// Package server exposes the todo lists as a small HTTP JSON API backed by the
+// same storage layer as the TUI.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// TokenEnv is the environment variable holding the bearer token the API requires;
+// when it is unset requests are not authenticated
+const TokenEnv = "LAZYTODO_API_TOKEN"
+
+// shutdownTimeout is how long in-flight requests get to finish on shutdown
+const shutdownTimeout = 5 * time.Second
+
+// maxBodySize limits request bodies, which only ever hold a single task
+const maxBodySize = 64 << 10
+
+// Server serves the API. Requests are handled one at a time and each reloads
+// the data, so edits made through another store on the same data show up
+// right away.
+type Server struct {
+	storage storage.StorageInterface
+	token   string
+	mu      sync.Mutex
+}
+
+// New creates a server backed by s. An empty token disables authentication.
+func New(s storage.StorageInterface, token string) *Server {
+	return &Server{storage: s, token: token}
+}
+
+// Handler returns the API routes
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /lists", s.handleLists)
+	mux.HandleFunc("GET /lists/{id}/tasks", s.handleListTasks)
+	mux.HandleFunc("POST /lists/{id}/tasks", s.handleCreateTask)
+	mux.HandleFunc("PATCH /tasks/{id}", s.handleUpdateTask)
+	mux.HandleFunc("DELETE /tasks/{id}", s.handleDeleteTask)
+	return s.authenticate(mux)
+}
+
+// ListenAndServe serves the API on addr until ctx is cancelled, then shuts
+// down gracefully. ready, if set, is called with the bound address.
+func (s *Server) ListenAndServe(ctx context.Context, addr string, ready func(net.Addr)) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	httpServer := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpServer.Serve(listener)
+	}()
+	if ready != nil {
+		ready(listener.Addr())
+	}
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	return nil
+}
+
+// authenticate rejects requests without the configured bearer token
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.token == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="lazytodo"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listResponse describes a list without its tasks, which have their own endpoint
+type listResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Color       string    `json:"color,omitempty"`
+	Group       string    `json:"group,omitempty"`
+	Total       int       `json:"total"`
+	Completed   int       `json:"completed"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// taskRequest is the body accepted when creating a task
+type taskRequest struct {
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Priority    models.Priority `json:"priority"`
+	Deadline    string          `json:"deadline"`
+	Label       string          `json:"label"`
+}
+
+// taskPatch is the body accepted when updating a task; absent fields are left
+// alone and a null deadline clears it
+type taskPatch struct {
+	Title     *string          `json:"title"`
+	Completed *bool            `json:"completed"`
+	Priority  *models.Priority `json:"priority"`
+	Deadline  optionalDeadline `json:"deadline"`
+}
+
+// optionalDeadline tells an absent deadline apart from a null one
+type optionalDeadline struct {
+	Set   bool
+	Value *string
+}
+
+func (d *optionalDeadline) UnmarshalJSON(data []byte) error {
+	d.Set = true
+	return json.Unmarshal(data, &d.Value)
+}
+
+func (s *Server) handleLists(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	app, ok := s.load(w)
+	if !ok {
+		return
+	}
+
+	lists := make([]listResponse, 0, len(app.TodoLists))
+	for i := range app.TodoLists {
+		list := &app.TodoLists[i]
+		lists = append(lists, listResponse{
+			ID:          list.ID,
+			Name:        list.Name,
+			Description: list.Description,
+			Color:       list.Color,
+			Group:       list.Group,
+			Total:       list.GetTotalCount(),
+			Completed:   list.GetCompletedCount(),
+			CreatedAt:   list.CreatedAt,
+			UpdatedAt:   list.UpdatedAt,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, lists)
+}
+
+func (s *Server) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	app, ok := s.load(w)
+	if !ok {
+		return
+	}
+	list, ok := s.findList(w, app, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	tasks := list.Tasks
+	if tasks == nil {
+		tasks = []models.Task{}
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request) {
+	var req taskRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		writeError(w, http.StatusBadRequest, "title is required")
+		return
+	}
+	if !req.Priority.Valid() {
+		writeError(w, http.StatusBadRequest, "priority must be low, medium, high or critical, or 0 to 3")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	app, ok := s.load(w)
+	if !ok {
+		return
+	}
+	list, ok := s.findList(w, app, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	deadline, err := parseDeadline(req.Deadline, app.Settings.DateFormat)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	task, err := s.storage.CreateTask(app, list.ID, title, strings.TrimSpace(req.Description), req.Priority, deadline, req.Label, models.SourceAPI)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if !s.save(w, app) {
+		return
+	}
+
+	w.Header().Set("Location", "/tasks/"+task.ID)
+	writeJSON(w, http.StatusCreated, task)
+}
+
+func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
+	var patch taskPatch
+	if !decodeBody(w, r, &patch) {
+		return
+	}
+
+	if patch.Title != nil && strings.TrimSpace(*patch.Title) == "" {
+		writeError(w, http.StatusBadRequest, "title must not be empty")
+		return
+	}
+	if patch.Priority != nil && !patch.Priority.Valid() {
+		writeError(w, http.StatusBadRequest, "priority must be low, medium, high or critical, or 0 to 3")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	app, ok := s.load(w)
+	if !ok {
+		return
+	}
+	listID, task, ok := s.findTask(w, app, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	title, priority, deadline := task.Title, task.Priority, task.Deadline
+	if patch.Title != nil {
+		title = strings.TrimSpace(*patch.Title)
+	}
+	if patch.Priority != nil {
+		priority = *patch.Priority
+	}
+	if patch.Deadline.Set {
+		value := ""
+		if patch.Deadline.Value != nil {
+			value = *patch.Deadline.Value
+		}
+		parsed, err := parseDeadline(value, app.Settings.DateFormat)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		deadline = parsed
+	}
+
+	updated := *task
+	if title != task.Title || priority != task.Priority || patch.Deadline.Set {
+		var err error
+		if updated, err = s.storage.UpdateTask(app, listID, task.ID, title, task.Description, priority, deadline, task.Label); err != nil {
+			writeStorageError(w, err)
+			return
+		}
+	}
+	if patch.Completed != nil && *patch.Completed != updated.Completed {
+		var err error
+		if updated, err = s.storage.ToggleTask(app, listID, task.ID); err != nil {
+			writeStorageError(w, err)
+			return
+		}
+	}
+	if !s.save(w, app) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) handleDeleteTask(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	app, ok := s.load(w)
+	if !ok {
+		return
+	}
+	listID, task, ok := s.findTask(w, app, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	if err := s.storage.DeleteTask(app, listID, task.ID); err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if !s.save(w, app) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// load reads a fresh copy of the data for a request
+func (s *Server) load(w http.ResponseWriter) (*models.Application, bool) {
+	app, err := s.storage.Load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to load data: %v", err))
+		return nil, false
+	}
+	return app, true
+}
+
+// save writes the data back after a change; the database backend has already
+// stored the change, but an encrypted database is only written out here
+func (s *Server) save(w http.ResponseWriter, app *models.Application) bool {
+	if err := s.storage.Save(app); err != nil {
+		writeStorageError(w, err)
+		return false
+	}
+	return true
+}
+
+// findList returns a list with its tasks loaded, or responds 404
+func (s *Server) findList(w http.ResponseWriter, app *models.Application, listID string) (*models.TodoList, bool) {
+	for i := range app.TodoLists {
+		if app.TodoLists[i].ID != listID {
+			continue
+		}
+		if err := s.storage.LoadTasks(app, listID); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return nil, false
+		}
+		return &app.TodoLists[i], true
+	}
+
+	writeError(w, http.StatusNotFound, "list not found")
+	return nil, false
+}
+
+// findTask looks a task up across all lists, or responds 404
+func (s *Server) findTask(w http.ResponseWriter, app *models.Application, taskID string) (string, *models.Task, bool) {
+	for i := range app.TodoLists {
+		if err := s.storage.LoadTasks(app, app.TodoLists[i].ID); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return "", nil, false
+		}
+	}
+
+	listID, task := lookupTask(app, taskID)
+	if task == nil {
+		writeError(w, http.StatusNotFound, "task not found")
+		return "", nil, false
+	}
+	return listID, task, true
+}
+
+// lookupTask returns a loaded task and the ID of its list
+func lookupTask(app *models.Application, taskID string) (string, *models.Task) {
+	for i := range app.TodoLists {
+		list := &app.TodoLists[i]
+		for j := range list.Tasks {
+			if list.Tasks[j].ID == taskID {
+				return list.ID, &list.Tasks[j]
+			}
+		}
+	}
+	return "", nil
+}
+
+// parseDeadline accepts a deadline in the configured date format, as
+// "YYYY-MM-DD HH:MM" or as RFC 3339 like the API returns it. Deadlines are
+// wall clock times, so an RFC 3339 offset is dropped.
+func parseDeadline(value, dateFormat string) (*time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(value)); err == nil {
+		deadline := models.WallClock(parsed)
+		return &deadline, nil
+	}
+
+	deadline, err := models.ParseDeadline(value, models.ResolveDateFormat(dateFormat))
+	if err != nil {
+		return nil, fmt.Errorf("invalid deadline %q: use RFC 3339 or YYYY-MM-DD HH:MM", value)
+	}
+	return deadline, nil
+}
+
+// decodeBody reads a JSON request body into v, or responds 400
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+// writeStorageError maps a failed storage operation to a status code
+func writeStorageError(w http.ResponseWriter, err error) {
+	if errors.Is(err, storage.ErrReadOnly) {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if errors.Is(err, models.ErrEmptyTitle) || errors.Is(err, models.ErrTitleTooLong) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// testToken is the bearer token the test servers require
+const testToken = "s3cret"
+
+// newTestServer serves the API over an empty store of the named backend,
+// "json" or "database", with a Work list, and returns its URL and the list
+// ID
+func newTestServer(t *testing.T, backend string) (string, string) {
+	t.Helper()
+	storage.SetDataDir(t.TempDir())
+	t.Cleanup(func() { storage.SetDataDir("") })
+
+	opts := storage.Options{Output: io.Discard}
+	var store storage.StorageInterface
+	var err error
+	if backend == "json" {
+		store, err = storage.New(opts)
+	} else {
+		store, err = storage.NewDatabase(opts)
+	}
+	if err != nil {
+		t.Fatalf("opening the %s backend: %v", backend, err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	app, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	listID, err := store.CreateTodoList(app, "Work", "", "")
+	if err != nil {
+		t.Fatalf("CreateTodoList: %v", err)
+	}
+	if err := store.Save(app); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	server := httptest.NewServer(New(store, testToken).Handler())
+	t.Cleanup(server.Close)
+	return server.URL, listID
+}
+
+// call sends a request with the test token and a JSON body, unless body is
+// empty, and decodes the JSON response into out when it is not nil
+func call(t *testing.T, method, url, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: decoding the response: %v", method, url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestAuthentication(t *testing.T) {
+	url, _ := newTestServer(t, "database")
+	for _, tt := range []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer guess", http.StatusUnauthorized},
+		{"not a bearer token", "Basic " + testToken, http.StatusUnauthorized},
+		{"right token", "Bearer " + testToken, http.StatusOK},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, url+"/lists", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.status == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+				t.Error("401 without a WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestTaskRoundTrip(t *testing.T) {
+	for _, backend := range []string{"json", "database"} {
+		t.Run(backend, func(t *testing.T) {
+			url, listID := newTestServer(t, backend)
+
+			var created models.Task
+			status := call(t, http.MethodPost, url+"/lists/"+listID+"/tasks",
+				`{"title": " Call back ", "priority": "high", "deadline": "2026-10-20 17:00"}`, &created)
+			if status != http.StatusCreated {
+				t.Fatalf("creating: status %d, want %d", status, http.StatusCreated)
+			}
+			if created.Title != "Call back" || created.Priority != models.High || created.Source != models.SourceAPI {
+				t.Errorf("created %+v, want the trimmed title, high priority and the api source", created)
+			}
+
+			var lists []listResponse
+			if status := call(t, http.MethodGet, url+"/lists", "", &lists); status != http.StatusOK {
+				t.Fatalf("listing lists: status %d", status)
+			}
+			if len(lists) != 1 || lists[0].Total != 1 || lists[0].Completed != 0 {
+				t.Errorf("lists = %+v, want Work with 1 open task", lists)
+			}
+
+			var completed models.Task
+			if status := call(t, http.MethodPatch, url+"/tasks/"+created.ID, `{"completed": true}`, &completed); status != http.StatusOK {
+				t.Fatalf("completing: status %d", status)
+			}
+			if !completed.Completed || completed.Title != "Call back" {
+				t.Errorf("completed %+v, want the same task completed", completed)
+			}
+
+			var tasks []models.Task
+			if status := call(t, http.MethodGet, url+"/lists/"+listID+"/tasks", "", &tasks); status != http.StatusOK {
+				t.Fatalf("listing tasks: status %d", status)
+			}
+			want := time.Date(2026, time.October, 20, 17, 0, 0, 0, time.UTC)
+			if len(tasks) != 1 || !tasks[0].Completed || tasks[0].Deadline == nil || !tasks[0].Deadline.Equal(want) {
+				t.Errorf("tasks = %+v, want the completed task due %s", tasks, want)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	url, listID := newTestServer(t, "database")
+	for _, tt := range []struct {
+		name         string
+		method, path string
+		body         string
+		status       int
+		message      string
+	}{
+		{"bad RFC 3339 deadline", http.MethodPost, "/lists/" + listID + "/tasks", `{"title": "Call back", "deadline": "2026-02-30T17:00:00Z"}`, http.StatusBadRequest, "invalid deadline"},
+		{"no title", http.MethodPost, "/lists/" + listID + "/tasks", `{"title": "  "}`, http.StatusBadRequest, "title is required"},
+		{"unknown field", http.MethodPost, "/lists/" + listID + "/tasks", `{"title": "Call back", "due": "tomorrow"}`, http.StatusBadRequest, "unknown field"},
+		{"unknown list", http.MethodPost, "/lists/nope/tasks", `{"title": "Call back"}`, http.StatusNotFound, "list not found"},
+		{"tasks of an unknown list", http.MethodGet, "/lists/nope/tasks", "", http.StatusNotFound, "list not found"},
+		{"unknown task", http.MethodPatch, "/tasks/nope", `{"completed": true}`, http.StatusNotFound, "task not found"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var body struct{ Error string }
+			if status := call(t, tt.method, url+tt.path, tt.body, &body); status != tt.status {
+				t.Errorf("status = %d, want %d", status, tt.status)
+			}
+			if !strings.Contains(body.Error, tt.message) {
+				t.Errorf("error = %q, want it to mention %q", body.Error, tt.message)
+			}
+		})
+	}
+}
+
+func TestParseDeadline(t *testing.T) {
+	seventeen := time.Date(2026, time.October, 20, 17, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		value string
+		want  *time.Time
+	}{
+		{"", nil},
+		{"2026-10-20 17:00", &seventeen},
+		{"2026-10-20T17:00:00Z", &seventeen},
+		{"2026-10-20T17:00:00+05:30", &seventeen}, // The wall clock time, not 11:30 UTC
+		{"2026-10-20T17:00:59-08:00", &seventeen}, // Deadlines keep the minute
+	} {
+		got, err := parseDeadline(tt.value, "iso")
+		if err != nil {
+			t.Errorf("parseDeadline(%q): %v", tt.value, err)
+			continue
+		}
+		if (got == nil) != (tt.want == nil) || got != nil && !got.Equal(*tt.want) || got != nil && got.Location() != time.UTC {
+			t.Errorf("parseDeadline(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"2026-10-20T17:00:00", "2026-10-20T25:00:00Z", "tomorrow"} {
+		if _, err := parseDeadline(value, "iso"); err == nil {
+			t.Errorf("parseDeadline(%q) succeeded, want an error", value)
+		}
+	}
+}
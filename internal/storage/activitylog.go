@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// ErrNoActivityLog is returned for the activity log by the JSON backend,
+// which has nowhere to keep it
+var ErrNoActivityLog = errors.New("the activity log is only kept in the database")
+
+// activityDetailLength is how much of a description an edit summary quotes
+const activityDetailLength = 40
+
+// logChange records a change in the activity log. The change has been made
+// by then, so failing to record it only warns rather than failing the change.
+func (s *DatabaseStorage) logChange(change models.Change) {
+	s.logChangeIn(s.conn(), change)
+}
+
+// logChangeIn records a change through conn, so that an operation with a
+// transaction of its own records its changes in it
+func (s *DatabaseStorage) logChangeIn(conn dbConn, change models.Change) {
+	_, err := conn.Exec(`
+		INSERT INTO activity_log (at, action, entity, entity_id, list_id, title, old_value, new_value)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, time.Now().UTC().Format(timestampLayout), string(change.Action), change.Entity, change.EntityID,
+		change.ListID, change.Title, change.Old, change.New)
+	if err != nil {
+		fmt.Fprintf(s.out, "Warning: failed to record a change in the activity log: %v\n", err)
+	}
+}
+
+// logTaskChange records a change of a task in the activity log
+func (s *DatabaseStorage) logTaskChange(action models.ChangeAction, listID string, task models.Task) {
+	s.logChange(models.Change{Action: action, Entity: models.EntityTask, EntityID: task.ID, ListID: listID, Title: task.Title})
+}
+
+// logListChange records a change of a todo list in the activity log
+func (s *DatabaseStorage) logListChange(action models.ChangeAction, listID, name string) {
+	s.logChange(models.Change{Action: action, Entity: models.EntityList, EntityID: listID, ListID: listID, Title: name})
+}
+
+// logMerge records what a merge adds and updates, one change per list and
+// task as if each were made on its own, through the merge's transaction.
+// existing is the data before the merge, with its tasks loaded.
+func (s *DatabaseStorage) logMerge(conn dbConn, existing *models.Application, plan mergePlan) {
+	logTask := func(action models.ChangeAction, listID string, task models.Task, old, new string) {
+		s.logChangeIn(conn, models.Change{Action: action, Entity: models.EntityTask, EntityID: task.ID, ListID: listID,
+			Title: task.Title, Old: old, New: new})
+	}
+
+	for _, list := range plan.newLists {
+		s.logChangeIn(conn, models.Change{Action: models.ActionCreated, Entity: models.EntityList, EntityID: list.ID,
+			ListID: list.ID, Title: list.Name})
+		for _, task := range list.Tasks {
+			logTask(models.ActionCreated, list.ID, task, "", "")
+		}
+	}
+
+	for _, list := range plan.updatedLists {
+		change := models.Change{Action: models.ActionEdited, Entity: models.EntityList, EntityID: list.ID,
+			ListID: list.ID, Title: list.Name}
+		if before := findList(existing, list.ID); before != nil {
+			change.Old, change.New = describeListEdit(*before, list.Name, list.Description, list.Color)
+		}
+		s.logChangeIn(conn, change)
+	}
+
+	for _, entry := range plan.newTasks {
+		logTask(models.ActionCreated, entry.ListID, entry.Task, "", "")
+	}
+
+	for _, entry := range plan.updatedTasks {
+		task := entry.Task
+		before, err := findTask(existing, entry.ListID, task.ID)
+		if err != nil {
+			logTask(models.ActionEdited, entry.ListID, task, "", "")
+			continue
+		}
+		if before.Completed != task.Completed {
+			action := models.ActionReopened
+			if task.Completed {
+				action = models.ActionCompleted
+			}
+			logTask(action, entry.ListID, task, "", "")
+		}
+		// Changes the summary leaves out, such as progress, are still
+		// recorded as an edit
+		if old, new := describeTaskEdit(*before, task); old != "" || before.Completed == task.Completed {
+			logTask(models.ActionEdited, entry.ListID, task, old, new)
+		}
+	}
+}
+
+// taskTitle returns the title of a task, or "" when it cannot be read
+func (s *DatabaseStorage) taskTitle(listID, taskID string) string {
+	var title string
+	s.conn().QueryRow("SELECT title FROM tasks WHERE id = ? AND list_id = ?", taskID, listID).Scan(&title)
+	return title
+}
+
+// ActivityLog returns up to limit of the changes recorded since the given
+// time, newest first
+func (s *DatabaseStorage) ActivityLog(app *models.Application, since time.Time, limit int) ([]models.Change, error) {
+	rows, err := s.conn().Query(`
+		SELECT at, action, entity, entity_id, list_id, title, old_value, new_value
+		FROM activity_log
+		WHERE at >= ?
+		ORDER BY at DESC, id DESC
+		LIMIT ?
+	`, since.UTC().Format(timestampLayout), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the activity log: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []models.Change
+	for rows.Next() {
+		var change models.Change
+		var at, action string
+		if err := rows.Scan(&at, &action, &change.Entity, &change.EntityID, &change.ListID,
+			&change.Title, &change.Old, &change.New); err != nil {
+			s.skipRow("activity log", err)
+			continue
+		}
+		change.At, _ = parseTimestamp(at)
+		change.Action = models.ChangeAction(action)
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// ActivityLog is not kept by the JSON file; see ErrNoActivityLog
+func (s *Storage) ActivityLog(app *models.Application, since time.Time, limit int) ([]models.Change, error) {
+	return nil, ErrNoActivityLog
+}
+
+// describeTaskEdit summarizes what an edit changed of a task, as it was and
+// as it is, e.g. "priority Low, no deadline" and "priority High, due
+// 2026-03-01 12:00"; both are empty when nothing changed
+func describeTaskEdit(before, after models.Task) (string, string) {
+	var old, new []string
+	changed := func(from, to string) {
+		old = append(old, from)
+		new = append(new, to)
+	}
+
+	if before.Title != after.Title {
+		changed(fmt.Sprintf("'%s'", before.Title), fmt.Sprintf("'%s'", after.Title))
+	}
+	if before.Description != after.Description {
+		changed(describeText("description", before.Description), describeText("description", after.Description))
+	}
+	if before.Priority != after.Priority {
+		changed("priority "+before.Priority.String(), "priority "+after.Priority.String())
+	}
+	if !sameTime(before.Deadline, after.Deadline) {
+		changed(describeDeadline(before.Deadline), describeDeadline(after.Deadline))
+	}
+	if before.Label != after.Label {
+		changed(describeText("label", before.Label), describeText("label", after.Label))
+	}
+	return strings.Join(old, ", "), strings.Join(new, ", ")
+}
+
+// describeListEdit summarizes what an edit changed of a todo list, like
+// describeTaskEdit
+func describeListEdit(before models.TodoList, name, description, color string) (string, string) {
+	var old, new []string
+	if before.Name != name {
+		old = append(old, fmt.Sprintf("'%s'", before.Name))
+		new = append(new, fmt.Sprintf("'%s'", name))
+	}
+	if before.Description != description {
+		old = append(old, describeText("description", before.Description))
+		new = append(new, describeText("description", description))
+	}
+	if before.Color != color {
+		old = append(old, "color "+before.Color)
+		new = append(new, "color "+color)
+	}
+	return strings.Join(old, ", "), strings.Join(new, ", ")
+}
+
+// describeText quotes a text field for an edit summary, shortened, or says it
+// is empty
+func describeText(field, text string) string {
+	if text == "" {
+		return "no " + field
+	}
+	if utf8.RuneCountInString(text) > activityDetailLength {
+		text = string([]rune(text)[:activityDetailLength-1]) + "…"
+	}
+	return fmt.Sprintf("%s '%s'", field, text)
+}
+
+// describeDeadline names a deadline for an edit summary
+func describeDeadline(deadline *time.Time) string {
+	if deadline == nil {
+		return "no deadline"
+	}
+	return "due " + deadline.Format("2006-01-02 15:04")
+}
+
+// sameTime reports whether two optional times are both unset or equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// listPosition returns the sidebar position of a list counting from 1, as
+// the activity log shows moves
+func listPosition(lists []models.TodoList, listID string) string {
+	for i, list := range lists {
+		if list.ID == listID {
+			return strconv.Itoa(i + 1)
+		}
+	}
+	return "?"
+}
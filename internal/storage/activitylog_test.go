@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// describeActivity returns the changes of the activity log, oldest first
+func describeActivity(t *testing.T, store StorageInterface, app *models.Application) []string {
+	t.Helper()
+	changes, err := store.ActivityLog(app, time.Time{}, 100)
+	if err != nil {
+		t.Fatalf("ActivityLog: %v", err)
+	}
+	var described []string
+	for _, change := range changes {
+		described = append(described, change.Describe())
+	}
+	slices.Reverse(described)
+	return described
+}
+
+func TestMergeRecordsEachChange(t *testing.T) {
+	store := openBackend(t, "database")
+	app, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	work := mustCreateList(t, store, app, "Work")
+	report := mustCreateTask(t, store, app, work, "Write report", nil)
+	before := len(describeActivity(t, store, app))
+
+	later := time.Now().Add(time.Hour).UTC()
+	edited := report
+	edited.Title = "Write the report"
+	edited.Priority = models.High
+	edited.Completed = true
+	edited.CompletedAt = &later
+	edited.UpdatedAt = later
+	incoming := &models.Application{TodoLists: []models.TodoList{
+		{ID: work, Name: "Work", Tasks: []models.Task{
+			edited,
+			{ID: "imported-task", Title: "Book flights", CreatedAt: later, UpdatedAt: later},
+		}},
+		{ID: "imported-list", Name: "Home", CreatedAt: later, UpdatedAt: later, Tasks: []models.Task{
+			{ID: "imported-home-task", Title: "Water plants", CreatedAt: later, UpdatedAt: later},
+		}},
+	}}
+	if _, err := store.Merge(app, incoming); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+
+	got := describeActivity(t, store, app)[before:]
+	want := []string{
+		"Created list 'Home'",
+		"Created 'Water plants'",
+		"Created 'Book flights'",
+		"Completed 'Write the report'",
+		"Edited 'Write the report': 'Write report', priority Medium → 'Write the report', priority High",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("activity after the merge =\n%q\nwant\n%q", got, want)
+	}
+}
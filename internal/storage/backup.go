@@ -0,0 +1,303 @@
+package storage
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// backupManifestName is the archive member describing the files of a backup;
+// it comes first so the files can be checked as they are read
+const backupManifestName = "manifest.json"
+
+// ErrNoDatabase is returned by WriteBackup when the data directory has no
+// database to back up, as when the JSON backend is kept
+var ErrNoDatabase = errors.New("there is no database to back up")
+
+// BackupManifest describes a backup archive
+type BackupManifest struct {
+	Created time.Time    `json:"created"`
+	Files   []BackupFile `json:"files"`
+}
+
+// BackupFile is a file of a backup archive, as it was written
+type BackupFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Encrypted reports whether the backup holds an encrypted database
+func (m BackupManifest) Encrypted() bool {
+	for _, file := range m.Files {
+		if file.Name == EncryptedDatabaseName {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteBackup writes the database in the data directory to a gzipped tar
+// archive at path. A plain database is copied with VACUUM INTO, which reads
+// it in one transaction, so the copy is consistent while LazyTodo has it
+// open. An encrypted database is only ever replaced whole and is copied as it
+// is. The settings live in the database, so they are in the archive too.
+func WriteBackup(path string) (BackupManifest, error) {
+	dataDir := resolveDataDir(io.Discard)
+
+	var name string
+	var data []byte
+	encryptedPath := filepath.Join(dataDir, EncryptedDatabaseName)
+	if _, err := os.Stat(encryptedPath); err == nil {
+		name = EncryptedDatabaseName
+		if data, err = os.ReadFile(encryptedPath); err != nil {
+			return BackupManifest{}, fmt.Errorf("failed to read the encrypted database: %w", err)
+		}
+	} else {
+		name = DatabaseName
+		if data, err = snapshotDatabase(filepath.Join(dataDir, DatabaseName)); err != nil {
+			return BackupManifest{}, err
+		}
+	}
+
+	sum := sha256.Sum256(data)
+	manifest := BackupManifest{
+		Created: time.Now().UTC(),
+		Files:   []BackupFile{{Name: name, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])}},
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return BackupManifest{}, fmt.Errorf("failed to encode the backup manifest: %w", err)
+	}
+
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	for _, member := range []struct {
+		name string
+		data []byte
+	}{{backupManifestName, manifestData}, {name, data}} {
+		header := &tar.Header{Name: member.name, Mode: 0600, Size: int64(len(member.data)), ModTime: manifest.Created}
+		if err := tw.WriteHeader(header); err != nil {
+			return BackupManifest{}, fmt.Errorf("failed to write the backup: %w", err)
+		}
+		if _, err := tw.Write(member.data); err != nil {
+			return BackupManifest{}, fmt.Errorf("failed to write the backup: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return BackupManifest{}, fmt.Errorf("failed to write the backup: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return BackupManifest{}, fmt.Errorf("failed to write the backup: %w", err)
+	}
+
+	if err := writeFileAtomic(path, archive.Bytes()); err != nil {
+		return BackupManifest{}, err
+	}
+	return manifest, nil
+}
+
+// snapshotDatabase returns a consistent copy of the database at dataPath,
+// made with VACUUM INTO over a read-only connection
+func snapshotDatabase(dataPath string) ([]byte, error) {
+	if _, err := os.Stat(dataPath); err != nil {
+		return nil, ErrNoDatabase
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+dataPath+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	tmpDir, err := os.MkdirTemp("", "lazytodo-backup-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a directory for the copy: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	copyPath := filepath.Join(tmpDir, DatabaseName)
+	if _, err := db.Exec("VACUUM INTO ?", copyPath); err != nil {
+		return nil, classifyOpenError(dataPath, fmt.Errorf("failed to copy the database: %w", err))
+	}
+	return os.ReadFile(copyPath)
+}
+
+// backupContents is a backup archive read into memory
+type backupContents struct {
+	manifest BackupManifest
+	files    map[string][]byte
+}
+
+// readBackup reads the archive at path and checks each of its files against
+// the manifest. Only the manifest and a database are accepted, so a file of
+// the archive cannot be written anywhere else.
+func readBackup(path string) (backupContents, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return backupContents{}, fmt.Errorf("failed to open the backup: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return backupContents{}, fmt.Errorf("%s is not a LazyTodo backup: %w", path, err)
+	}
+	defer gz.Close()
+
+	contents := backupContents{files: make(map[string][]byte)}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return backupContents{}, fmt.Errorf("failed to read the backup: %w", err)
+		}
+		switch header.Name {
+		case backupManifestName, DatabaseName, EncryptedDatabaseName:
+		default:
+			return backupContents{}, fmt.Errorf("%s is not a LazyTodo backup: unexpected file %q", path, header.Name)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return backupContents{}, fmt.Errorf("failed to read %s from the backup: %w", header.Name, err)
+		}
+		contents.files[header.Name] = data
+	}
+
+	manifestData, ok := contents.files[backupManifestName]
+	if !ok {
+		return backupContents{}, fmt.Errorf("%s is not a LazyTodo backup: it has no manifest", path)
+	}
+	delete(contents.files, backupManifestName)
+	if err := json.Unmarshal(manifestData, &contents.manifest); err != nil {
+		return backupContents{}, fmt.Errorf("failed to read the backup manifest: %w", err)
+	}
+	if len(contents.manifest.Files) != 1 || len(contents.files) != 1 {
+		return backupContents{}, fmt.Errorf("%s does not hold exactly one database", path)
+	}
+
+	for _, file := range contents.manifest.Files {
+		data, ok := contents.files[file.Name]
+		if !ok {
+			return backupContents{}, fmt.Errorf("the backup is missing %s", file.Name)
+		}
+		sum := sha256.Sum256(data)
+		if int64(len(data)) != file.Size || hex.EncodeToString(sum[:]) != file.SHA256 {
+			return backupContents{}, fmt.Errorf("%s in the backup does not match its checksum; the archive is damaged", file.Name)
+		}
+	}
+	return contents, nil
+}
+
+// InspectBackup reads the archive at path and checks its files against their
+// checksums, without changing anything
+func InspectBackup(path string) (BackupManifest, error) {
+	contents, err := readBackup(path)
+	if err != nil {
+		return BackupManifest{}, err
+	}
+	return contents.manifest, nil
+}
+
+// RestoreBackup replaces the database in the data directory with the one in
+// the archive at path. The database is written next to the current one and
+// checked first: a plain one with SQLite's full integrity check, an encrypted
+// one by its header, as its passphrase is needed to read further. Only then
+// is the current database, with its journals, moved aside to
+// lazytodo.db.before-restore.<date>, whose name is returned; it is empty when
+// there was no database.
+func RestoreBackup(path string) (string, error) {
+	contents, err := readBackup(path)
+	if err != nil {
+		return "", err
+	}
+	name := contents.manifest.Files[0].Name
+	data := contents.files[name]
+
+	dataDir := resolveDataDir(io.Discard)
+	if !prepareDataDir(dataDir) {
+		return "", fmt.Errorf("data directory %s is not writable", dataDir)
+	}
+	targetPath := filepath.Join(dataDir, name)
+	stagedPath := targetPath + ".restore"
+	if err := os.WriteFile(stagedPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", stagedPath, err)
+	}
+	defer os.Remove(stagedPath)
+
+	if name == EncryptedDatabaseName {
+		if !bytes.HasPrefix(data, []byte(encryptionMagic)) {
+			return "", fmt.Errorf("%w: %s", ErrDatabaseCorrupt, name)
+		}
+	} else if err := checkDatabaseFile(stagedPath); err != nil {
+		return "", err
+	}
+
+	asidePath, err := setAsideForRestore(dataDir)
+	if err != nil {
+		return asidePath, err
+	}
+	if err := os.Rename(stagedPath, targetPath); err != nil {
+		return asidePath, fmt.Errorf("failed to put the restored database in place: %w", err)
+	}
+	return asidePath, nil
+}
+
+// checkDatabaseFile runs SQLite's full integrity check on the database file at path
+func checkDatabaseFile(path string) error {
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	s := &DatabaseStorage{db: db, dataPath: path}
+	if err := s.checkIntegrity(); err != nil {
+		return classifyOpenError(path, err)
+	}
+	return nil
+}
+
+// setAsideForRestore moves the databases of the data directory, plain and
+// encrypted, aside for a restore along with their journals. It returns where
+// the main one went, or an empty string when there was none.
+func setAsideForRestore(dataDir string) (string, error) {
+	suffix := ".before-restore." + time.Now().Format("20060102-150405")
+	var asidePath string
+
+	encryptedPath := filepath.Join(dataDir, EncryptedDatabaseName)
+	if _, err := os.Stat(encryptedPath); err == nil {
+		asidePath = encryptedPath + suffix
+		if err := os.Rename(encryptedPath, asidePath); err != nil {
+			return "", fmt.Errorf("failed to move the current database aside: %w", err)
+		}
+	}
+
+	dataPath := filepath.Join(dataDir, DatabaseName)
+	if _, err := os.Stat(dataPath); err == nil {
+		asidePath, err = setAsideDatabase(dataPath + suffix)
+		if err != nil {
+			return asidePath, err
+		}
+	} else if asidePath != "" {
+		journalPath := filepath.Join(dataDir, JournalName)
+		if err := os.Rename(journalPath, asidePath+"."+JournalName); err != nil && !os.IsNotExist(err) {
+			return asidePath, fmt.Errorf("failed to move %s aside: %w", JournalName, err)
+		}
+	}
+	return asidePath, nil
+}
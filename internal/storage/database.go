@@ -3,10 +3,10 @@ package storage
 import (
 	"database/sql"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -21,28 +21,181 @@ const (
 	DatabaseDir  = ".lazytodo"
 )
 
+// timestampLayout is the format used for DATETIME values written by the application
+const timestampLayout = "2006-01-02 15:04:05"
+
+// deadlineArg formats t for comparing with the deadline column: in the
+// deadline representation, as every comparison of deadlines with the time is
+// made, so the queries agree with Task.IsOverdue
+func deadlineArg(t time.Time) string {
+	return models.WallClock(t).Format(timestampLayout)
+}
+
+// nextTaskPosition is the SQL for the position of a task added at the end of
+// the list given by its one parameter
+const nextTaskPosition = "(SELECT COALESCE(MAX(position), -1) + 1 FROM tasks WHERE list_id = ?)"
+
+// builtinMigration is a schema change applied when the migrations directory is unavailable
+type builtinMigration struct {
+	version int
+	sql     string
+}
+
+// builtinMigrations mirrors migrations/*.up.sql after the initial schema so that
+// binaries installed without the migrations directory still get schema updates
+var builtinMigrations = []builtinMigration{
+	{2, `
+CREATE TABLE IF NOT EXISTS task_notes (
+    id TEXT PRIMARY KEY,
+    task_id TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_task_notes_task_id ON task_notes(task_id);
+`},
+	{3, `ALTER TABLE tasks ADD COLUMN label TEXT NOT NULL DEFAULT '';`},
+	{4, `
+ALTER TABLE todo_lists ADD COLUMN sort_order INTEGER NOT NULL DEFAULT 0;
+UPDATE todo_lists SET sort_order = (
+    SELECT COUNT(*) FROM todo_lists AS earlier
+    WHERE earlier.created_at < todo_lists.created_at
+       OR (earlier.created_at = todo_lists.created_at AND earlier.id < todo_lists.id)
+);
+`},
+	{5, `ALTER TABLE todo_lists ADD COLUMN color TEXT NOT NULL DEFAULT '#10B981';`},
+	{6, `
+CREATE TABLE IF NOT EXISTS templates (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS template_tasks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    template_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT DEFAULT '',
+    priority INTEGER NOT NULL DEFAULT 0,
+    label TEXT NOT NULL DEFAULT '',
+    deadline_offset INTEGER NULL,
+    FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_template_tasks_template_id ON template_tasks(template_id);
+`},
+	{7, `ALTER TABLE tasks ADD COLUMN snooze_count INTEGER NOT NULL DEFAULT 0;`},
+	{8, `
+DROP TRIGGER IF EXISTS update_todo_lists_timestamp;
+DROP TRIGGER IF EXISTS update_tasks_timestamp;
+`},
+	{9, `
+ALTER TABLE tasks ADD COLUMN estimate INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN spent INTEGER NOT NULL DEFAULT 0;
+`},
+	{10, `
+ALTER TABLE tasks ADD COLUMN link TEXT NOT NULL DEFAULT '';
+`},
+	{11, `
+ALTER TABLE tasks ADD COLUMN reminder_offset INTEGER;
+`},
+	{12, `
+ALTER TABLE tasks ADD COLUMN source TEXT NOT NULL DEFAULT 'tui';
+UPDATE tasks SET source = 'unknown';
+`},
+	{13, `
+ALTER TABLE tasks ADD COLUMN deleted_at DATETIME;
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(deleted_at);
+`},
+	{14, `
+ALTER TABLE todo_lists ADD COLUMN group_name TEXT NOT NULL DEFAULT '';
+`},
+	{15, `
+ALTER TABLE tasks ADD COLUMN completed_at DATETIME;
+UPDATE tasks SET completed_at = updated_at WHERE completed = 1;
+`},
+	{16, `
+ALTER TABLE tasks ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+UPDATE tasks SET position = (
+    SELECT COUNT(*) FROM tasks AS earlier
+    WHERE earlier.list_id = tasks.list_id
+      AND (earlier.created_at < tasks.created_at
+       OR (earlier.created_at = tasks.created_at AND earlier.id < tasks.id))
+);
+`},
+	{17, `
+ALTER TABLE todo_lists ADD COLUMN grouping TEXT NOT NULL DEFAULT '';
+`},
+	{18, `
+CREATE TABLE IF NOT EXISTS activity_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    at DATETIME NOT NULL,
+    action TEXT NOT NULL,
+    entity TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    list_id TEXT NOT NULL DEFAULT '',
+    title TEXT NOT NULL DEFAULT '',
+    old_value TEXT NOT NULL DEFAULT '',
+    new_value TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_activity_log_at ON activity_log(at);
+`},
+	{19, `
+ALTER TABLE tasks ADD COLUMN progress INTEGER NOT NULL DEFAULT 0;
+`},
+	{20, `
+ALTER TABLE todo_lists ADD COLUMN pinned INTEGER NOT NULL DEFAULT 0;
+`},
+	{21, `
+ALTER TABLE tasks ADD COLUMN starred INTEGER NOT NULL DEFAULT 0;
+`},
+}
+
 // DatabaseStorage handles data persistence using SQLite
 type DatabaseStorage struct {
 	db       *sql.DB
 	dataPath string
+	readOnly bool
+	out      io.Writer
+
+	// Set when the database is encrypted at rest; see encryption.go
+	encryptionKey  []byte
+	encryptionSalt []byte
+
+	// Set on the copy WithTransaction hands its function; see transaction.go
+	tx *sql.Tx
+
+	// Set on the copy withIDs returns; see journal.go
+	assigned *idQueue
 }
 
 // NewDatabase creates a new database storage instance
-func NewDatabase() (*DatabaseStorage, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user home directory: %w", err)
-	}
-
-	dataDir := filepath.Join(homeDir, DatabaseDir)
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create data directory: %w", err)
+func NewDatabase(opts Options) (*DatabaseStorage, error) {
+	dataDir := resolveDataDir(opts.output())
+	readOnly := opts.ReadOnly
+	if !readOnly && !prepareDataDir(dataDir) {
+		fmt.Fprintf(opts.output(), "Warning: data directory %s is not writable, opening read-only\n", dataDir)
+		readOnly = true
 	}
 
 	dataPath := filepath.Join(dataDir, DatabaseName)
 
+	// An encrypted database is decrypted into memory instead of opened directly
+	encryptedPath := filepath.Join(dataDir, EncryptedDatabaseName)
+	if _, err := os.Stat(encryptedPath); err == nil {
+		return openEncryptedDatabase(encryptedPath, opts, readOnly)
+	}
+
 	// Open database connection
-	db, err := sql.Open("sqlite3", dataPath+"?_foreign_keys=on")
+	dsn := dataPath + "?_foreign_keys=on"
+	if readOnly {
+		// A read-only connection cannot create the file, so it must already exist
+		if _, err := os.Stat(dataPath); err != nil {
+			return nil, fmt.Errorf("cannot open database read-only: %w", err)
+		}
+		dsn = "file:" + dataPath + "?mode=ro&_foreign_keys=on"
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -50,11 +203,21 @@ func NewDatabase() (*DatabaseStorage, error) {
 	storage := &DatabaseStorage{
 		db:       db,
 		dataPath: dataPath,
+		readOnly: readOnly,
+		out:      opts.output(),
 	}
 
-	// Run migrations
-	if err := storage.runMigrations(); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	if err := storage.checkDatabase(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	// Run migrations (a read-only database is used with whatever schema it has)
+	if !readOnly {
+		if err := storage.runMigrations(); err != nil {
+			db.Close()
+			return nil, classifyOpenError(dataPath, fmt.Errorf("failed to run migrations: %w", err))
+		}
 	}
 
 	return storage, nil
@@ -62,85 +225,82 @@ func NewDatabase() (*DatabaseStorage, error) {
 
 // Close closes the database connection
 func (s *DatabaseStorage) Close() error {
-	if s.db != nil {
-		return s.db.Close()
+	if s.db == nil {
+		return nil
+	}
+
+	// Write the final state of an encrypted database before the in-memory copy is gone
+	if s.encrypted() && !s.readOnly {
+		if err := s.writeEncrypted(); err != nil {
+			s.db.Close()
+			return err
+		}
 	}
-	return nil
-}
 
-// runMigrations applies database migrations
-func (s *DatabaseStorage) runMigrations() error {
-	// Create migrations table if it doesn't exist
-	createMigrationsTable := `
-		CREATE TABLE IF NOT EXISTS schema_migrations (
-			version INTEGER PRIMARY KEY,
-			applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
-		);
-	`
+	return s.db.Close()
+}
 
-	if _, err := s.db.Exec(createMigrationsTable); err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
+// checkUniqueIDs makes sure every table keyed by a generated ID has a unique
+// index on it, so a duplicate ID fails its insert instead of shadowing a row
+func (s *DatabaseStorage) checkUniqueIDs() error {
+	for _, table := range []string{"todo_lists", "tasks", "task_notes", "templates"} {
+		unique, err := s.hasUniqueIndex(table, "id")
+		if err != nil {
+			return err
+		}
+		if !unique {
+			return fmt.Errorf("table %s has no unique index on id", table)
+		}
 	}
+	return nil
+}
 
-	// Get applied migrations
-	appliedMigrations := make(map[int]bool)
-	rows, err := s.db.Query("SELECT version FROM schema_migrations")
+// hasUniqueIndex reports whether a unique index covers exactly the given column
+func (s *DatabaseStorage) hasUniqueIndex(table, column string) (bool, error) {
+	rows, err := s.conn().Query("SELECT name, \"unique\" FROM pragma_index_list(?)", table)
 	if err != nil {
-		return fmt.Errorf("failed to query applied migrations: %w", err)
+		return false, fmt.Errorf("failed to list indexes of %s: %w", table, err)
 	}
-	defer rows.Close()
-
+	var indexes []string
 	for rows.Next() {
-		var version int
-		if err := rows.Scan(&version); err != nil {
-			return fmt.Errorf("failed to scan migration version: %w", err)
+		var name string
+		var unique bool
+		if err := rows.Scan(&name, &unique); err != nil {
+			rows.Close()
+			return false, fmt.Errorf("failed to scan index of %s: %w", table, err)
+		}
+		if unique {
+			indexes = append(indexes, name)
 		}
-		appliedMigrations[version] = true
 	}
-
-	// Apply new migrations from filesystem
-	migrationsDir := "migrations"
-	migrationEntries, err := os.ReadDir(migrationsDir)
-	if err != nil {
-		// If migrations directory doesn't exist, create tables directly
-		return s.createInitialSchema()
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
 	}
 
-	for _, entry := range migrationEntries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".up.sql") {
-			// Extract version number from filename (e.g., "001_initial_schema.up.sql" -> 1)
-			versionStr := strings.Split(entry.Name(), "_")[0]
-			version, err := strconv.Atoi(versionStr)
-			if err != nil {
-				continue // Skip invalid migration files
-			}
-
-			if !appliedMigrations[version] {
-				// Read and execute migration
-				migrationPath := filepath.Join(migrationsDir, entry.Name())
-				migrationSQL, err := os.ReadFile(migrationPath)
-				if err != nil {
-					return fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
-				}
-
-				if _, err := s.db.Exec(string(migrationSQL)); err != nil {
-					return fmt.Errorf("failed to apply migration %s: %w", entry.Name(), err)
-				}
-
-				// Record migration as applied
-				if _, err := s.db.Exec("INSERT INTO schema_migrations (version) VALUES (?)", version); err != nil {
-					return fmt.Errorf("failed to record migration %s: %w", entry.Name(), err)
-				}
+	for _, index := range indexes {
+		var columns []string
+		infoRows, err := s.conn().Query("SELECT name FROM pragma_index_info(?)", index)
+		if err != nil {
+			return false, fmt.Errorf("failed to read index %s: %w", index, err)
+		}
+		for infoRows.Next() {
+			var name string
+			if err := infoRows.Scan(&name); err == nil {
+				columns = append(columns, name)
 			}
 		}
+		infoRows.Close()
+		if len(columns) == 1 && columns[0] == column {
+			return true, nil
+		}
 	}
-
-	return nil
+	return false, nil
 }
 
-// createInitialSchema creates the initial database schema when migrations are not available
-func (s *DatabaseStorage) createInitialSchema() error {
-	schema := `
+// initialSchema is migration 001, the initial database schema, for when the
+// migrations directory is not available
+const initialSchema = `
 -- Create todo_lists table
 CREATE TABLE IF NOT EXISTS todo_lists (
     id TEXT PRIMARY KEY,
@@ -182,20 +342,7 @@ CREATE INDEX IF NOT EXISTS idx_tasks_list_id ON tasks(list_id);
 CREATE INDEX IF NOT EXISTS idx_tasks_completed ON tasks(completed);
 CREATE INDEX IF NOT EXISTS idx_tasks_deadline ON tasks(deadline);
 CREATE INDEX IF NOT EXISTS idx_tasks_priority ON tasks(priority);
-	`
-
-	_, err := s.db.Exec(schema)
-	if err != nil {
-		return fmt.Errorf("failed to create initial schema: %w", err)
-	}
-
-	// Record initial migration as applied
-	if _, err := s.db.Exec("INSERT INTO schema_migrations (version) VALUES (1)"); err != nil {
-		return fmt.Errorf("failed to record initial migration: %w", err)
-	}
-
-	return nil
-}
+`
 
 // Load loads the application data from database
 func (s *DatabaseStorage) Load() (*models.Application, error) {
@@ -211,20 +358,147 @@ func (s *DatabaseStorage) Load() (*models.Application, error) {
 	app.Settings = settings
 
 	// Load todo lists
-	todoLists, err := s.loadTodoLists()
+	todoLists, err := s.loadTodoLists(settings.DueSoonWindow())
 	if err != nil {
 		return nil, fmt.Errorf("failed to load todo lists: %w", err)
 	}
 	app.TodoLists = todoLists
 
+	// Load templates
+	templates, err := s.loadTemplates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load templates: %w", err)
+	}
+	app.Templates = templates
+
 	return app, nil
 }
 
+// loadTemplates loads every template with its tasks
+func (s *DatabaseStorage) loadTemplates() ([]models.Template, error) {
+	var templates []models.Template
+	indexByID := make(map[string]int)
+
+	rows, err := s.conn().Query("SELECT id, name, created_at FROM templates ORDER BY created_at ASC, id ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query templates: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var template models.Template
+		var createdAt string
+		if err := rows.Scan(&template.ID, &template.Name, &createdAt); err != nil {
+			s.skipRow("template", err)
+			continue
+		}
+		template.CreatedAt, _ = parseTimestamp(createdAt)
+
+		indexByID[template.ID] = len(templates)
+		templates = append(templates, template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	taskRows, err := s.conn().Query(`
+		SELECT template_id, title, description, priority, label, deadline_offset
+		FROM template_tasks
+		ORDER BY template_id, position ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query template tasks: %w", err)
+	}
+	defer taskRows.Close()
+
+	for taskRows.Next() {
+		var templateID string
+		var task models.TemplateTask
+		var offset sql.NullInt64
+		if err := taskRows.Scan(&templateID, &task.Title, &task.Description, &task.Priority, &task.Label, &offset); err != nil {
+			s.skipRow("template task", err)
+			continue
+		}
+		task.Priority = task.Priority.Clamp()
+		if offset.Valid {
+			duration := time.Duration(offset.Int64) * time.Second
+			task.DeadlineOffset = &duration
+		}
+
+		if i, ok := indexByID[templateID]; ok {
+			templates[i].Tasks = append(templates[i].Tasks, task)
+		}
+	}
+
+	return templates, taskRows.Err()
+}
+
+// loadNotes loads the notes of every task in a list in a single query
+func (s *DatabaseStorage) loadNotes(list *models.TodoList) error {
+	tasksByID := make(map[string]*models.Task, len(list.Tasks))
+	for i := range list.Tasks {
+		tasksByID[list.Tasks[i].ID] = &list.Tasks[i]
+	}
+
+	rows, err := s.conn().Query(`
+		SELECT n.id, n.task_id, n.body, n.created_at
+		FROM task_notes n
+		JOIN tasks t ON t.id = n.task_id
+		WHERE t.list_id = ?
+		ORDER BY n.created_at ASC, n.id ASC
+	`, list.ID)
+	if err != nil {
+		return fmt.Errorf("failed to query notes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		note, err := scanNote(rows)
+		if err != nil {
+			s.skipRow("note", err)
+			continue
+		}
+
+		if task, ok := tasksByID[note.TaskID]; ok {
+			task.Notes = append(task.Notes, note)
+		}
+	}
+
+	return rows.Err()
+}
+
+// scanNote reads a task_notes row selected as (id, task_id, body, created_at)
+func scanNote(rows *sql.Rows) (models.Note, error) {
+	var note models.Note
+	var createdAt string
+
+	if err := rows.Scan(&note.ID, &note.TaskID, &note.Body, &createdAt); err != nil {
+		return note, err
+	}
+
+	if ct, ok := parseTimestamp(createdAt); ok {
+		note.CreatedAt = ct
+	}
+	return note, nil
+}
+
+// parseTimestamp parses a DATETIME value as returned by the SQLite driver.
+// Columns declared DATETIME come back as time.Time and are formatted as RFC 3339
+// when scanned into a string, while raw text values use timestampLayout.
+func parseTimestamp(value string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, timestampLayout} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
 // loadSettings loads application settings from database
 func (s *DatabaseStorage) loadSettings() (models.Settings, error) {
 	settings := models.DefaultSettings()
 
-	rows, err := s.db.Query("SELECT key, value FROM settings")
+	rows, err := s.conn().Query("SELECT key, value FROM settings")
 	if err != nil {
 		return settings, fmt.Errorf("failed to query settings: %w", err)
 	}
@@ -233,7 +507,8 @@ func (s *DatabaseStorage) loadSettings() (models.Settings, error) {
 	for rows.Next() {
 		var key, value string
 		if err := rows.Scan(&key, &value); err != nil {
-			continue // Skip invalid settings
+			s.skipRow("setting", err)
+			continue
 		}
 
 		switch key {
@@ -243,23 +518,117 @@ func (s *DatabaseStorage) loadSettings() (models.Settings, error) {
 			}
 		case "show_completed":
 			settings.ShowCompleted = value == "true"
+		case "unique_list_names":
+			settings.UniqueListNames = value == "true"
+		case "quiet_hours":
+			settings.QuietHours = value
+		case "show_heatmap":
+			settings.ShowHeatmap = value == "true"
 		case "auto_save":
 			settings.AutoSave = value == "true"
+		case "icons":
+			settings.Icons = value
+		case "date_format":
+			settings.DateFormat = value
+		case "sync_repo":
+			settings.SyncRepo = value
+		case "desktop_notify":
+			settings.DesktopNotify = value == "true"
+		case "setup_complete":
+			settings.SetupComplete = value == "true"
+		case "trash_days":
+			if days, err := strconv.Atoi(value); err == nil {
+				settings.TrashDays = days
+			}
+		case "day_task_limit":
+			if limit, err := strconv.Atoi(value); err == nil {
+				settings.DayTaskLimit = limit
+			}
+		case "sidebar_width":
+			if width, err := strconv.Atoi(value); err == nil {
+				settings.SidebarWidth = width
+			}
+		case "due_soon_hours":
+			if hours, err := strconv.Atoi(value); err == nil {
+				settings.DueSoonHours = hours
+			}
+		case "stale_days":
+			if days, err := strconv.Atoi(value); err == nil {
+				settings.StaleDays = days
+			}
+		case "completed_linger":
+			if seconds, err := strconv.Atoi(value); err == nil {
+				settings.CompletedLinger = seconds
+			}
+		case "critical_repeat_minutes":
+			if minutes, err := strconv.Atoi(value); err == nil {
+				settings.CriticalRepeatMinutes = minutes
+			}
+		case "complete_at_full_progress":
+			settings.CompleteAtFullProgress = value == "true"
+		case "bulk_confirm_threshold":
+			if tasks, err := strconv.Atoi(value); err == nil {
+				settings.BulkConfirmThreshold = tasks
+			}
+		case "streak":
+			if streak, err := strconv.Atoi(value); err == nil {
+				settings.Streak = streak
+			}
+		case "streak_day":
+			settings.StreakDay = value
+		case "review_day":
+			if day, err := strconv.Atoi(value); err == nil {
+				settings.ReviewDay = day
+			}
+		case "review_hour":
+			if hour, err := strconv.Atoi(value); err == nil {
+				settings.ReviewHour = hour
+			}
+		case "last_review":
+			settings.LastReview = value
+		case "last_opened_at":
+			settings.LastOpenedAt = value
+		case "welcome_back_hours":
+			if hours, err := strconv.Atoi(value); err == nil {
+				settings.WelcomeBackHours = hours
+			}
+		case "clear_stars_daily":
+			settings.ClearStarsDaily = value == "true"
+		case "purge_completed_days":
+			if days, err := strconv.Atoi(value); err == nil {
+				settings.PurgeCompletedDays = days
+			}
 		}
 	}
 
 	return settings, nil
 }
 
-// loadTodoLists loads all todo lists with their tasks
-func (s *DatabaseStorage) loadTodoLists() ([]models.TodoList, error) {
+// skipRow reports a row that could not be read and is left out of the results
+func (s *DatabaseStorage) skipRow(what string, err error) {
+	fmt.Fprintf(s.out, "Warning: skipped an unreadable %s row: %v\n", what, err)
+}
+
+// loadTodoLists loads list metadata with per-list task counts, counting tasks
+// due within dueSoon as due soon; the tasks themselves are loaded on demand by
+// LoadTasks
+func (s *DatabaseStorage) loadTodoLists(dueSoon time.Duration) ([]models.TodoList, error) {
 	var todoLists []models.TodoList
 
-	rows, err := s.db.Query(`
-		SELECT id, name, description, created_at, updated_at 
-		FROM todo_lists 
-		ORDER BY created_at ASC
-	`)
+	// Due soon as in Task.IsDueSoonWithin; a window of zero counts nothing
+	now := time.Now()
+	rows, err := s.conn().Query(`
+		SELECT l.id, l.name, l.description, l.color, l.group_name, l.grouping, l.pinned, l.created_at, l.updated_at,
+			COUNT(t.id), COALESCE(SUM(t.completed), 0),
+			COALESCE(SUM(t.completed = 0 AND t.deadline < ?), 0),
+			COALESCE(SUM(t.completed = 0 AND t.deadline >= ? AND t.deadline < ?), 0),
+			COALESCE(SUM(t.estimate), 0), COALESCE(SUM(CASE WHEN t.completed = 0 THEN t.estimate ELSE 0 END), 0),
+			COALESCE(SUM(t.spent), 0)
+		FROM todo_lists l
+		LEFT JOIN tasks t ON t.list_id = l.id AND t.deleted_at IS NULL
+		GROUP BY l.id
+		ORDER BY l.pinned DESC, l.sort_order ASC, l.created_at ASC, l.id ASC
+	`, deadlineArg(now), deadlineArg(now), deadlineArg(now.Add(dueSoon)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query todo lists: %w", err)
 	}
@@ -267,42 +636,84 @@ func (s *DatabaseStorage) loadTodoLists() ([]models.TodoList, error) {
 
 	for rows.Next() {
 		var list models.TodoList
+		var summary models.TaskSummary
+		var estimate, remaining, spent int64
 		var createdAt, updatedAt string
 
-		if err := rows.Scan(&list.ID, &list.Name, &list.Description, &createdAt, &updatedAt); err != nil {
-			continue // Skip invalid lists
+		if err := rows.Scan(
+			&list.ID, &list.Name, &list.Description, &list.Color, &list.Group, &list.Grouping, &list.Pinned, &createdAt, &updatedAt,
+			&summary.Total, &summary.Completed, &summary.Overdue, &summary.DueSoon, &estimate, &remaining, &spent,
+		); err != nil {
+			s.skipRow("list", err)
+			continue
 		}
+		summary.Estimate = time.Duration(estimate) * time.Second
+		summary.Remaining = time.Duration(remaining) * time.Second
+		summary.Spent = time.Duration(spent) * time.Second
 
 		// Parse timestamps
-		if ct, err := time.Parse("2006-01-02 15:04:05", createdAt); err == nil {
+		if ct, ok := parseTimestamp(createdAt); ok {
 			list.CreatedAt = ct
 		}
-		if ut, err := time.Parse("2006-01-02 15:04:05", updatedAt); err == nil {
+		if ut, ok := parseTimestamp(updatedAt); ok {
 			list.UpdatedAt = ut
 		}
 
-		// Load tasks for this list
-		tasks, err := s.loadTasksForList(list.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load tasks for list %s: %w", list.ID, err)
-		}
-		list.Tasks = tasks
-
+		list.Summary = &summary
 		todoLists = append(todoLists, list)
 	}
 
 	return todoLists, nil
 }
 
+// LoadTasks fetches a list's tasks and their notes the first time the list is
+// used and caches them on the in-memory list
+func (s *DatabaseStorage) LoadTasks(app *models.Application, listID string) error {
+	for i := range app.TodoLists {
+		list := &app.TodoLists[i]
+		if list.ID != listID {
+			continue
+		}
+		if list.TasksLoaded() {
+			return nil
+		}
+		return s.reloadTasks(list)
+	}
+
+	return fmt.Errorf("todo list with ID %s not found", listID)
+}
+
+// reloadTasks reads the tasks and notes of a list from the database, leaving
+// the list as it was if that fails
+func (s *DatabaseStorage) reloadTasks(list *models.TodoList) error {
+	tasks, err := s.loadTasksForList(list.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load tasks for list %s: %w", list.ID, err)
+	}
+
+	loaded := *list
+	loaded.Tasks = tasks
+	if err := s.loadNotes(&loaded); err != nil {
+		return fmt.Errorf("failed to load notes for list %s: %w", list.ID, err)
+	}
+
+	loaded.Summary = nil
+	*list = loaded
+	return nil
+}
+
+// taskColumns are the tasks columns scanTask reads, in its order
+const taskColumns = "id, list_id, title, description, completed, priority, deadline, label, snooze_count, estimate, spent, link, reminder_offset, progress, starred, source, completed_at, created_at, updated_at"
+
 // loadTasksForList loads all tasks for a specific todo list
 func (s *DatabaseStorage) loadTasksForList(listID string) ([]models.Task, error) {
 	var tasks []models.Task
 
-	rows, err := s.db.Query(`
-		SELECT id, title, description, completed, priority, deadline, created_at, updated_at
+	rows, err := s.conn().Query(`
+		SELECT `+taskColumns+`
 		FROM tasks 
-		WHERE list_id = ? 
-		ORDER BY created_at ASC
+		WHERE list_id = ? AND deleted_at IS NULL
+		ORDER BY position ASC, created_at ASC, id ASC
 	`, listID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query tasks: %w", err)
@@ -310,265 +721,1644 @@ func (s *DatabaseStorage) loadTasksForList(listID string) ([]models.Task, error)
 	defer rows.Close()
 
 	for rows.Next() {
-		var task models.Task
-		var deadline sql.NullString
-		var createdAt, updatedAt string
-
-		if err := rows.Scan(
-			&task.ID, &task.Title, &task.Description, &task.Completed,
-			&task.Priority, &deadline, &createdAt, &updatedAt,
-		); err != nil {
-			continue // Skip invalid tasks
+		task, _, err := scanTask(rows)
+		if err != nil {
+			s.skipRow("task", err)
+			continue
 		}
+		tasks = append(tasks, task)
+	}
 
-		// Parse deadline
-		if deadline.Valid {
-			if dl, err := time.Parse("2006-01-02 15:04:05", deadline.String); err == nil {
-				task.Deadline = &dl
-			}
-		}
+	return tasks, rows.Err()
+}
 
-		// Parse timestamps
-		if ct, err := time.Parse("2006-01-02 15:04:05", createdAt); err == nil {
-			task.CreatedAt = ct
-		}
-		if ut, err := time.Parse("2006-01-02 15:04:05", updatedAt); err == nil {
-			task.UpdatedAt = ut
-		}
+// getTask reads a task and its notes back from the database. Mutations return
+// it so callers see what was stored, including the database's timestamps.
+func (s *DatabaseStorage) getTask(listID, taskID string) (models.Task, error) {
+	rows, err := s.conn().Query(`
+		SELECT `+taskColumns+`
+		FROM tasks
+		WHERE id = ? AND list_id = ? AND deleted_at IS NULL
+	`, taskID, listID)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to query task: %w", err)
+	}
 
-		tasks = append(tasks, task)
+	// The rows are closed before the notes are queried, as an encrypted
+	// database has a single connection
+	var task models.Task
+	found := rows.Next()
+	if found {
+		task, _, err = scanTask(rows)
+	} else {
+		err = rows.Err()
+	}
+	rows.Close()
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to read task: %w", err)
+	}
+	if !found {
+		return models.Task{}, fmt.Errorf("task with ID %s not found in list %s", taskID, listID)
 	}
 
-	return tasks, nil
+	task.Notes, err = s.ListNotes(nil, listID, taskID)
+	if err != nil {
+		return models.Task{}, err
+	}
+	return task, nil
 }
 
-// Save saves the application data to database
-func (s *DatabaseStorage) Save(app *models.Application) error {
-	tx, err := s.db.Begin()
+// DueTasks returns incomplete tasks across all lists whose reminder is due at
+// now, queried directly so lists that are not loaded yet are included
+func (s *DatabaseStorage) DueTasks(app *models.Application, now time.Time, defaultLead time.Duration) ([]models.Task, error) {
+	rows, err := s.conn().Query(`
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE completed = 0 AND deadline IS NOT NULL AND deleted_at IS NULL
+		ORDER BY deadline ASC, created_at ASC, id ASC
+	`)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to query due tasks: %w", err)
 	}
-	defer tx.Rollback()
-
-	// This method is used for auto-save, but with database we save immediately
-	// on each operation, so this can be a no-op or just ensure data consistency
+	defer rows.Close()
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	var tasks []models.Task
+	for rows.Next() {
+		task, _, err := scanTask(rows)
+		if err != nil {
+			s.skipRow("task", err)
+			continue
+		}
+		if reminderDue(&task, now, defaultLead) {
+			tasks = append(tasks, task)
+		}
 	}
 
-	return nil
+	return tasks, rows.Err()
 }
 
-// GetDataPath returns the path to the database file
-func (s *DatabaseStorage) GetDataPath() string {
-	return s.dataPath
+// CountOverdue returns the number of overdue tasks across all lists with a
+// single aggregate query, so lists that are not loaded yet are included
+func (s *DatabaseStorage) CountOverdue(app *models.Application) (int, error) {
+	var count int
+	err := s.conn().QueryRow(`
+		SELECT COUNT(*) FROM tasks
+		WHERE completed = 0 AND deadline IS NOT NULL AND deadline < ? AND deleted_at IS NULL
+	`, deadlineArg(time.Now())).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count overdue tasks: %w", err)
+	}
+	return count, nil
 }
 
-// CreateTodoList creates a new todo list
-func (s *DatabaseStorage) CreateTodoList(app *models.Application, name, description string) string {
-	id := generateDatabaseID()
-
-	_, err := s.db.Exec(`
-		INSERT INTO todo_lists (id, name, description) 
-		VALUES (?, ?, ?)
-	`, id, name, description)
-
+// OverdueTasks returns the overdue tasks across all lists, oldest deadline first
+func (s *DatabaseStorage) OverdueTasks(app *models.Application) ([]models.ListTask, error) {
+	overdue, err := s.queryDueTasks("t.deadline < ?", deadlineArg(time.Now()))
 	if err != nil {
-		return "" // Return empty string on error
+		return nil, fmt.Errorf("failed to query overdue tasks: %w", err)
 	}
+	return overdue, nil
+}
 
-	// Add to in-memory structure for consistency
-	newList := models.TodoList{
-		ID:          id,
-		Name:        name,
-		Description: description,
-		Tasks:       []models.Task{},
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+// TasksDueBetween returns the incomplete tasks across all lists due from from
+// up to, but not including, to; earliest deadline first
+func (s *DatabaseStorage) TasksDueBetween(app *models.Application, from, to time.Time) ([]models.ListTask, error) {
+	due, err := s.queryDueTasks("t.deadline >= ? AND t.deadline < ?", deadlineArg(from), deadlineArg(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks due: %w", err)
 	}
-	app.TodoLists = append(app.TodoLists, newList)
+	return due, nil
+}
 
-	return id
+// StarredTasks returns the starred tasks across all lists, open ones first,
+// then by deadline, queried directly so lists that are not loaded yet are included
+func (s *DatabaseStorage) StarredTasks(app *models.Application) ([]models.ListTask, error) {
+	starred, err := s.queryListTasks(`
+		WHERE t.starred = 1 AND t.deleted_at IS NULL
+		ORDER BY t.completed ASC, t.deadline IS NULL, t.deadline ASC, t.created_at ASC, t.id ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query starred tasks: %w", err)
+	}
+	return starred, nil
 }
 
-// UpdateTodoList updates an existing todo list
-func (s *DatabaseStorage) UpdateTodoList(app *models.Application, listID, name, description string) error {
-	_, err := s.db.Exec(`
-		UPDATE todo_lists 
-		SET name = ?, description = ? 
-		WHERE id = ?
-	`, name, description, listID)
+// clearedStars selects the starred tasks completed before a time; tasks
+// completed before completion times were kept count too
+const clearedStars = `
+	starred = 1 AND completed = 1 AND deleted_at IS NULL
+	AND (completed_at IS NULL OR datetime(completed_at) < datetime(?))
+`
+
+// ClearCompletedStars unstars the tasks across all lists completed before
+// the given time. Completion times are stored in more than one layout, so
+// they are compared through datetime().
+func (s *DatabaseStorage) ClearCompletedStars(app *models.Application, before time.Time) ([]models.ListTask, error) {
+	if s.readOnly {
+		return nil, ErrReadOnly
+	}
 
+	cutoff := before.UTC().Format(timestampLayout)
+	cleared, err := s.queryListTasks(`
+		WHERE t.id IN (SELECT id FROM tasks WHERE `+clearedStars+`)
+	`, cutoff)
 	if err != nil {
-		return fmt.Errorf("failed to update todo list: %w", err)
+		return nil, fmt.Errorf("failed to query completed starred tasks: %w", err)
+	}
+	if len(cleared) == 0 {
+		return nil, nil
 	}
 
-	// Update in-memory structure
-	for i := range app.TodoLists {
-		if app.TodoLists[i].ID == listID {
-			app.TodoLists[i].Name = name
-			app.TodoLists[i].Description = description
-			app.TodoLists[i].UpdatedAt = time.Now()
-			break
-		}
+	now := time.Now()
+	_, err = s.conn().Exec(`
+		UPDATE tasks
+		SET starred = 0, updated_at = ?
+		WHERE `+clearedStars, now.UTC().Format(timestampLayout), cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clear stars: %w", err)
 	}
 
-	return nil
+	for i := range cleared {
+		cleared[i].Task.Starred = false
+		cleared[i].Task.UpdatedAt = now
+	}
+	return cleared, nil
 }
 
-// DeleteTodoList deletes a todo list and all its tasks
-func (s *DatabaseStorage) DeleteTodoList(app *models.Application, listID string) error {
-	_, err := s.db.Exec("DELETE FROM todo_lists WHERE id = ?", listID)
+// trashedCompleted selects the tasks completed before a time, leaving out
+// those completed before completion times were kept
+const trashedCompleted = `
+	completed = 1 AND deleted_at IS NULL
+	AND completed_at IS NOT NULL AND datetime(completed_at) < datetime(?)
+`
+
+// TrashCompleted moves the tasks across all lists completed before the given
+// time to the trash, recording each in the activity log. Completion times are
+// stored in more than one layout, so they are compared through datetime().
+func (s *DatabaseStorage) TrashCompleted(app *models.Application, before time.Time) ([]models.ListTask, error) {
+	if s.readOnly {
+		return nil, ErrReadOnly
+	}
+
+	cutoff := before.UTC().Format(timestampLayout)
+	trashed, err := s.queryListTasks(`
+		WHERE t.id IN (SELECT id FROM tasks WHERE `+trashedCompleted+`)
+	`, cutoff)
 	if err != nil {
-		return fmt.Errorf("failed to delete todo list: %w", err)
+		return nil, fmt.Errorf("failed to query completed tasks: %w", err)
+	}
+	if len(trashed) == 0 {
+		return nil, nil
 	}
 
-	// Remove from in-memory structure
-	for i, list := range app.TodoLists {
-		if list.ID == listID {
-			app.TodoLists = append(app.TodoLists[:i], app.TodoLists[i+1:]...)
-			break
-		}
+	_, err = s.conn().Exec(`
+		UPDATE tasks
+		SET deleted_at = ?
+		WHERE `+trashedCompleted, time.Now().UTC().Format(timestampLayout), cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move completed tasks to the trash: %w", err)
 	}
 
-	return nil
+	for _, entry := range trashed {
+		s.logTaskChange(models.ActionDeleted, entry.ListID, entry.Task)
+	}
+	return trashed, nil
 }
 
-// CreateTask creates a new task in a todo list
-func (s *DatabaseStorage) CreateTask(app *models.Application, listID, title, description string, priority models.Priority, deadline *time.Time) (string, error) {
-	taskID := generateDatabaseID()
+// WelcomeDigest returns what happened between since and now, with a query per
+// section so lists that are not loaded yet are included. Completion times are
+// stored in more than one layout, so they are compared through datetime().
+func (s *DatabaseStorage) WelcomeDigest(app *models.Application, since, now time.Time) (models.Digest, error) {
+	_, endOfDay := models.CalendarDay(models.WallClock(now))
 
-	var deadlineStr sql.NullString
-	if deadline != nil {
-		deadlineStr = sql.NullString{String: deadline.Format("2006-01-02 15:04:05"), Valid: true}
+	var digest models.Digest
+	var err error
+	digest.Overdue, err = s.queryDueTasks("t.deadline >= ? AND t.deadline < ?", deadlineArg(since), deadlineArg(now))
+	if err != nil {
+		return digest, fmt.Errorf("failed to query tasks overdue since the last session: %w", err)
 	}
-
-	_, err := s.db.Exec(`
-		INSERT INTO tasks (id, list_id, title, description, priority, deadline) 
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, taskID, listID, title, description, int(priority), deadlineStr)
-
+	digest.DueToday, err = s.queryDueTasks("t.deadline >= ? AND t.deadline < ?", deadlineArg(now), deadlineArg(endOfDay))
 	if err != nil {
-		return "", fmt.Errorf("failed to create task: %w", err)
+		return digest, fmt.Errorf("failed to query tasks due today: %w", err)
 	}
-
-	// Add to in-memory structure
+	digest.Completed, err = s.queryListTasks(`
+		WHERE t.completed = 1 AND t.completed_at IS NOT NULL AND datetime(t.completed_at) >= datetime(?) AND t.deleted_at IS NULL
+		ORDER BY datetime(t.completed_at) DESC, t.id ASC
+	`, since.UTC().Format(timestampLayout))
+	if err != nil {
+		return digest, fmt.Errorf("failed to query tasks completed since the last session: %w", err)
+	}
+	return digest, nil
+}
+
+// queryDueTasks returns the incomplete tasks across all lists whose deadline
+// matches the condition, earliest deadline first
+func (s *DatabaseStorage) queryDueTasks(condition string, args ...any) ([]models.ListTask, error) {
+	return s.queryListTasks(`
+		WHERE t.completed = 0 AND t.deadline IS NOT NULL AND `+condition+` AND t.deleted_at IS NULL
+		ORDER BY t.deadline ASC, t.created_at ASC, t.id ASC
+	`, args...)
+}
+
+// queryListTasks returns the tasks across all lists, with the names of their
+// lists, that the WHERE and ORDER BY clauses given select
+func (s *DatabaseStorage) queryListTasks(clauses string, args ...any) ([]models.ListTask, error) {
+	rows, err := s.conn().Query(`
+		SELECT t.id, t.list_id, t.title, t.description, t.completed, t.priority, t.deadline, t.label, t.snooze_count, t.estimate, t.spent, t.link, t.reminder_offset, t.progress, t.starred, t.source, t.completed_at, t.created_at, t.updated_at, l.name
+		FROM tasks t
+		JOIN todo_lists l ON l.id = t.list_id
+	`+clauses, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []models.ListTask
+	for rows.Next() {
+		var entry models.ListTask
+		var deadline, completedAt sql.NullString
+		var estimate, spent int64
+		var reminderOffset sql.NullInt64
+		var createdAt, updatedAt string
+		task := &entry.Task
+		if err := rows.Scan(
+			&task.ID, &entry.ListID, &task.Title, &task.Description, &task.Completed,
+			&task.Priority, &deadline, &task.Label, &task.SnoozeCount, &estimate, &spent, &task.Link,
+			&reminderOffset, &task.Progress, &task.Starred, &task.Source, &completedAt, &createdAt, &updatedAt, &entry.ListName,
+		); err != nil {
+			s.skipRow("task", err)
+			continue
+		}
+		task.Priority = task.Priority.Clamp()
+		task.Estimate = time.Duration(estimate) * time.Second
+		task.Spent = time.Duration(spent) * time.Second
+		task.ReminderOffset = offsetFromSeconds(reminderOffset)
+
+		if dl, ok := parseTimestamp(deadline.String); ok {
+			task.Deadline = &dl
+		}
+		if ct, ok := parseTimestamp(completedAt.String); ok {
+			task.CompletedAt = &ct
+		}
+		task.CreatedAt, _ = parseTimestamp(createdAt)
+		task.UpdatedAt, _ = parseTimestamp(updatedAt)
+		tasks = append(tasks, entry)
+	}
+
+	return tasks, rows.Err()
+}
+
+// CompletionTimes returns when each completed task was completed, across all
+// lists and the trash, queried directly so lists that are not loaded yet are included
+func (s *DatabaseStorage) CompletionTimes(app *models.Application) ([]time.Time, error) {
+	rows, err := s.conn().Query("SELECT completed_at FROM tasks WHERE completed = 1 AND completed_at IS NOT NULL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query completions: %w", err)
+	}
+	defer rows.Close()
+
+	var times []time.Time
+	for rows.Next() {
+		var completedAt string
+		if err := rows.Scan(&completedAt); err != nil {
+			s.skipRow("completion", err)
+			continue
+		}
+		if completed, ok := parseTimestamp(completedAt); ok {
+			times = append(times, completed)
+		}
+	}
+	return times, rows.Err()
+}
+
+// CompletionCounts returns how many tasks were completed on each day from
+// from up to to. Timestamps are stored in more than one layout, so they are
+// compared once parsed rather than in the query.
+func (s *DatabaseStorage) CompletionCounts(app *models.Application, from, to time.Time) (map[string]int, error) {
+	times, err := s.CompletionTimes(app)
+	if err != nil {
+		return nil, err
+	}
+	return models.CompletionCounts(times, from, to), nil
+}
+
+// RecentActivity returns the most recent changes across all lists, newest first.
+// It queries the database directly so lists that are not loaded yet are included.
+func (s *DatabaseStorage) RecentActivity(app *models.Application, limit int) ([]models.Activity, error) {
+	var activity []models.Activity
+
+	listRows, err := s.conn().Query(`
+		SELECT id, name, created_at FROM todo_lists
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query list activity: %w", err)
+	}
+	defer listRows.Close()
+
+	for listRows.Next() {
+		var listID, name, createdAt string
+		if err := listRows.Scan(&listID, &name, &createdAt); err != nil {
+			s.skipRow("list", err)
+			continue
+		}
+		created, _ := parseTimestamp(createdAt)
+		activity = append(activity, listActivity(listID, name, created))
+	}
+	if err := listRows.Err(); err != nil {
+		return nil, err
+	}
+
+	taskRows, err := s.conn().Query(`
+		SELECT t.id, t.title, t.completed, t.created_at, t.updated_at, l.id, l.name
+		FROM tasks t
+		JOIN todo_lists l ON l.id = t.list_id
+		WHERE t.deleted_at IS NULL
+		ORDER BY t.updated_at DESC, t.id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task activity: %w", err)
+	}
+	defer taskRows.Close()
+
+	for taskRows.Next() {
+		var task models.Task
+		var createdAt, updatedAt, listID, listName string
+		if err := taskRows.Scan(&task.ID, &task.Title, &task.Completed, &createdAt, &updatedAt, &listID, &listName); err != nil {
+			s.skipRow("task", err)
+			continue
+		}
+		task.CreatedAt, _ = parseTimestamp(createdAt)
+		task.UpdatedAt, _ = parseTimestamp(updatedAt)
+		activity = append(activity, taskActivity(task, listID, listName))
+	}
+	if err := taskRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return newestActivity(activity, limit), nil
+}
+
+// scanTask reads a tasks row selected as (id, list_id, title, description,
+// completed, priority, deadline, label, snooze_count, estimate, spent, link,
+// reminder_offset, progress, starred, source, completed_at, created_at,
+// updated_at)
+func scanTask(rows *sql.Rows) (models.Task, string, error) {
+	var task models.Task
+	var listID string
+	var deadline sql.NullString
+	var estimate, spent int64
+	var reminderOffset sql.NullInt64
+	var completedAt sql.NullString
+	var createdAt, updatedAt string
+
+	if err := rows.Scan(
+		&task.ID, &listID, &task.Title, &task.Description, &task.Completed,
+		&task.Priority, &deadline, &task.Label, &task.SnoozeCount, &estimate, &spent, &task.Link,
+		&reminderOffset, &task.Progress, &task.Starred, &task.Source, &completedAt, &createdAt, &updatedAt,
+	); err != nil {
+		return task, "", err
+	}
+	// Priorities outside the levels would fall through the styling switches
+	task.Priority = task.Priority.Clamp()
+	task.Estimate = time.Duration(estimate) * time.Second
+	task.Spent = time.Duration(spent) * time.Second
+	task.ReminderOffset = offsetFromSeconds(reminderOffset)
+
+	// Parse deadline
+	if deadline.Valid {
+		if dl, ok := parseTimestamp(deadline.String); ok {
+			task.Deadline = &dl
+		}
+	}
+
+	// Parse timestamps
+	if completedAt.Valid {
+		if ct, ok := parseTimestamp(completedAt.String); ok {
+			task.CompletedAt = &ct
+		}
+	}
+	if ct, ok := parseTimestamp(createdAt); ok {
+		task.CreatedAt = ct
+	}
+	if ut, ok := parseTimestamp(updatedAt); ok {
+		task.UpdatedAt = ut
+	}
+
+	return task, listID, nil
+}
+
+// Save saves the application data to database
+func (s *DatabaseStorage) Save(app *models.Application) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// This method is used for auto-save, but with database we save immediately
+	// on each operation, so only settings (which have no dedicated operations)
+	// need to be written here
+	for key, value := range settingsValues(app.Settings) {
+		if _, err := tx.Exec("INSERT OR REPLACE INTO settings (key, value) VALUES (?, ?)", key, value); err != nil {
+			return fmt.Errorf("failed to save setting %s: %w", key, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Encrypted databases live in memory, so saving writes a new encrypted
+	// snapshot; inside a transaction that waits for the next save after it
+	if s.encrypted() && s.tx == nil {
+		return s.writeEncrypted()
+	}
+
+	return nil
+}
+
+// settingsValues serializes settings into settings table rows
+func settingsValues(settings models.Settings) map[string]string {
+	return map[string]string{
+		"reminder_minutes":          strconv.Itoa(settings.ReminderMinutes),
+		"show_completed":            strconv.FormatBool(settings.ShowCompleted),
+		"auto_save":                 strconv.FormatBool(settings.AutoSave),
+		"icons":                     settings.Icons,
+		"date_format":               settings.DateFormat,
+		"sync_repo":                 settings.SyncRepo,
+		"desktop_notify":            strconv.FormatBool(settings.DesktopNotify),
+		"trash_days":                strconv.Itoa(settings.TrashDays),
+		"day_task_limit":            strconv.Itoa(settings.DayTaskLimit),
+		"sidebar_width":             strconv.Itoa(settings.SidebarWidth),
+		"due_soon_hours":            strconv.Itoa(settings.DueSoonHours),
+		"streak":                    strconv.Itoa(settings.Streak),
+		"streak_day":                settings.StreakDay,
+		"review_day":                strconv.Itoa(settings.ReviewDay),
+		"review_hour":               strconv.Itoa(settings.ReviewHour),
+		"last_review":               settings.LastReview,
+		"unique_list_names":         strconv.FormatBool(settings.UniqueListNames),
+		"quiet_hours":               settings.QuietHours,
+		"show_heatmap":              strconv.FormatBool(settings.ShowHeatmap),
+		"stale_days":                strconv.Itoa(settings.StaleDays),
+		"completed_linger":          strconv.Itoa(settings.CompletedLinger),
+		"critical_repeat_minutes":   strconv.Itoa(settings.CriticalRepeatMinutes),
+		"complete_at_full_progress": strconv.FormatBool(settings.CompleteAtFullProgress),
+		"bulk_confirm_threshold":    strconv.Itoa(settings.BulkConfirmThreshold),
+		"last_opened_at":            settings.LastOpenedAt,
+		"welcome_back_hours":        strconv.Itoa(settings.WelcomeBackHours),
+		"clear_stars_daily":         strconv.FormatBool(settings.ClearStarsDaily),
+		"purge_completed_days":      strconv.Itoa(settings.PurgeCompletedDays),
+		"setup_complete":            strconv.FormatBool(settings.SetupComplete),
+	}
+}
+
+// GetDataPath returns the path to the database file
+func (s *DatabaseStorage) GetDataPath() string {
+	return s.dataPath
+}
+
+// IsReadOnly reports whether mutations are disabled
+func (s *DatabaseStorage) IsReadOnly() bool {
+	return s.readOnly
+}
+
+// DefersWrites reports whether changes wait for Save, which only an encrypted
+// database does: it lives in memory until Save writes the encrypted file
+func (s *DatabaseStorage) DefersWrites() bool {
+	return s.encrypted()
+}
+
+// CreateTodoList creates a new todo list
+func (s *DatabaseStorage) CreateTodoList(app *models.Application, name, description, color string) (string, error) {
+	if s.readOnly {
+		return "", ErrReadOnly
+	}
+	name, err := models.NormalizeTitle(name)
+	if err != nil {
+		return "", err
+	}
+	description = models.NormalizeText(description)
+	if err := checkListName(app, name, ""); err != nil {
+		return "", err
+	}
+
+	id := s.newID()
+
+	// New lists go to the end of the sidebar
+	_, err = s.conn().Exec(`
+		INSERT INTO todo_lists (id, name, description, color, sort_order) 
+		VALUES (?, ?, ?, ?, (SELECT COALESCE(MAX(sort_order), -1) + 1 FROM todo_lists))
+	`, id, name, description, color)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to create todo list: %w", err)
+	}
+
+	// Add to in-memory structure for consistency
+	newList := models.TodoList{
+		ID:          id,
+		Name:        name,
+		Description: description,
+		Color:       color,
+		Tasks:       []models.Task{},
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	app.TodoLists = append(app.TodoLists, newList)
+	s.logListChange(models.ActionCreated, id, name)
+
+	return id, nil
+}
+
+// ReorderList moves a todo list by offset positions (negative moves it up)
+func (s *DatabaseStorage) ReorderList(app *models.Application, listID string, offset int) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	reordered, err := moveList(app.TodoLists, listID, offset)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, list := range reordered {
+		if _, err := tx.Exec("UPDATE todo_lists SET sort_order = ? WHERE id = ?", i, list.ID); err != nil {
+			return fmt.Errorf("failed to reorder todo list: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logChange(models.Change{
+		Action: models.ActionMoved, Entity: models.EntityList, EntityID: listID, ListID: listID,
+		Title: findList(app, listID).Name,
+		Old:   listPosition(app.TodoLists, listID), New: listPosition(reordered, listID),
+	})
+	app.TodoLists = reordered
+	return nil
+}
+
+// UpdateTodoList updates an existing todo list
+func (s *DatabaseStorage) UpdateTodoList(app *models.Application, listID, name, description, color string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+	name, err := models.NormalizeTitle(name)
+	if err != nil {
+		return err
+	}
+	description = models.NormalizeText(description)
+	if err := checkListName(app, name, listID); err != nil {
+		return err
+	}
+
+	_, err = s.conn().Exec(`
+		UPDATE todo_lists 
+		SET name = ?, description = ?, color = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, name, description, color, listID)
+
+	if err != nil {
+		return fmt.Errorf("failed to update todo list: %w", err)
+	}
+
+	if before := findList(app, listID); before != nil {
+		if old, new := describeListEdit(*before, name, description, color); old != "" {
+			s.logChange(models.Change{Action: models.ActionEdited, Entity: models.EntityList, EntityID: listID, ListID: listID,
+				Title: name, Old: old, New: new})
+		}
+	}
+
+	// Update in-memory structure
 	for i := range app.TodoLists {
 		if app.TodoLists[i].ID == listID {
-			newTask := models.Task{
-				ID:          taskID,
-				Title:       title,
-				Description: description,
-				Completed:   false,
-				Priority:    priority,
-				CreatedAt:   time.Now(),
-				UpdatedAt:   time.Now(),
-				Deadline:    deadline,
-			}
-			app.TodoLists[i].Tasks = append(app.TodoLists[i].Tasks, newTask)
+			app.TodoLists[i].Name = name
+			app.TodoLists[i].Description = description
+			app.TodoLists[i].Color = color
+			app.TodoLists[i].UpdatedAt = time.Now()
+			break
+		}
+	}
+
+	return nil
+}
+
+// SetListGrouping sets how the tasks of a todo list are divided into sections.
+// It is a view preference, so the list does not count as updated for merges.
+func (s *DatabaseStorage) SetListGrouping(app *models.Application, listID, grouping string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	if _, err := s.conn().Exec("UPDATE todo_lists SET grouping = ? WHERE id = ?", grouping, listID); err != nil {
+		return fmt.Errorf("failed to update list grouping: %w", err)
+	}
+
+	todoList := findList(app, listID)
+	if todoList == nil {
+		return fmt.Errorf("todo list with ID %s not found", listID)
+	}
+	todoList.Grouping = grouping
+	return nil
+}
+
+// SetListPinned pins a todo list to the top of the sidebar or unpins it. Like
+// the order of the lists, it does not count as an update for merges.
+func (s *DatabaseStorage) SetListPinned(app *models.Application, listID string, pinned bool) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	reordered, err := pinList(app.TodoLists, listID, pinned)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE todo_lists SET pinned = ? WHERE id = ?", pinned, listID); err != nil {
+		return fmt.Errorf("failed to pin todo list: %w", err)
+	}
+	// The lists keep the order they are shown in once loaded again
+	for i, list := range reordered {
+		if _, err := tx.Exec("UPDATE todo_lists SET sort_order = ? WHERE id = ?", i, list.ID); err != nil {
+			return fmt.Errorf("failed to reorder todo list: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	app.TodoLists = reordered
+	return nil
+}
+
+// SetListGroup files a todo list under a sidebar group; an empty group ungroups it
+func (s *DatabaseStorage) SetListGroup(app *models.Application, listID, group string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	_, err := s.conn().Exec(`
+		UPDATE todo_lists
+		SET group_name = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, group, listID)
+	if err != nil {
+		return fmt.Errorf("failed to update list group: %w", err)
+	}
+
+	// Update in-memory structure
+	for i := range app.TodoLists {
+		if app.TodoLists[i].ID == listID {
+			app.TodoLists[i].Group = group
 			app.TodoLists[i].UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return fmt.Errorf("todo list with ID %s not found", listID)
+}
+
+// DeleteTodoList deletes a todo list and all its tasks
+func (s *DatabaseStorage) DeleteTodoList(app *models.Application, listID string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	_, err := s.conn().Exec("DELETE FROM todo_lists WHERE id = ?", listID)
+	if err != nil {
+		return fmt.Errorf("failed to delete todo list: %w", err)
+	}
+	if list := findList(app, listID); list != nil {
+		s.logListChange(models.ActionDeleted, listID, list.Name)
+	}
+
+	// Remove from in-memory structure
+	for i, list := range app.TodoLists {
+		if list.ID == listID {
+			app.TodoLists = append(app.TodoLists[:i], app.TodoLists[i+1:]...)
 			break
 		}
 	}
 
-	return taskID, nil
+	return nil
+}
+
+// CreateTemplate saves a named set of tasks as a template
+func (s *DatabaseStorage) CreateTemplate(app *models.Application, name string, tasks []models.TemplateTask) (string, error) {
+	if s.readOnly {
+		return "", ErrReadOnly
+	}
+	name, err := models.NormalizeTitle(name)
+	if err != nil {
+		return "", err
+	}
+
+	id := s.newID()
+
+	tx, err := s.begin()
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("INSERT INTO templates (id, name) VALUES (?, ?)", id, name); err != nil {
+		return "", fmt.Errorf("failed to create template: %w", err)
+	}
+
+	for i, task := range tasks {
+		var offset sql.NullInt64
+		if task.DeadlineOffset != nil {
+			offset = sql.NullInt64{Int64: int64(*task.DeadlineOffset / time.Second), Valid: true}
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO template_tasks (template_id, position, title, description, priority, label, deadline_offset)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, id, i, task.Title, task.Description, int(task.Priority), task.Label, offset)
+		if err != nil {
+			return "", fmt.Errorf("failed to add task to template: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Add to in-memory structure
+	app.Templates = append(app.Templates, models.Template{
+		ID:        id,
+		Name:      name,
+		Tasks:     append([]models.TemplateTask(nil), tasks...),
+		CreatedAt: time.Now(),
+	})
+
+	return id, nil
+}
+
+// RenameTemplate changes the name of a template
+func (s *DatabaseStorage) RenameTemplate(app *models.Application, templateID, name string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+	name, err := models.NormalizeTitle(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.conn().Exec("UPDATE templates SET name = ? WHERE id = ?", name, templateID); err != nil {
+		return fmt.Errorf("failed to rename template: %w", err)
+	}
+
+	// Update in-memory structure
+	template, err := findTemplate(app, templateID)
+	if err != nil {
+		return fmt.Errorf("template not found in memory")
+	}
+	template.Name = name
+
+	return nil
+}
+
+// DeleteTemplate deletes a template and its tasks
+func (s *DatabaseStorage) DeleteTemplate(app *models.Application, templateID string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	if _, err := s.conn().Exec("DELETE FROM templates WHERE id = ?", templateID); err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+
+	// Remove from in-memory structure
+	for i, template := range app.Templates {
+		if template.ID == templateID {
+			app.Templates = append(app.Templates[:i], app.Templates[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+// CreateListFromTemplate creates a todo list holding the tasks of a template,
+// with deadlines resolved from today, in a single transaction
+func (s *DatabaseStorage) CreateListFromTemplate(app *models.Application, templateID, name, description, color string) (string, error) {
+	if s.readOnly {
+		return "", ErrReadOnly
+	}
+	name, err := models.NormalizeTitle(name)
+	if err != nil {
+		return "", err
+	}
+	description = models.NormalizeText(description)
+
+	template, err := findTemplate(app, templateID)
+	if err != nil {
+		return "", err
+	}
+	if err := checkListName(app, name, ""); err != nil {
+		return "", err
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	listID := s.newID()
+	_, err = tx.Exec(`
+		INSERT INTO todo_lists (id, name, description, color, sort_order) 
+		VALUES (?, ?, ?, ?, (SELECT COALESCE(MAX(sort_order), -1) + 1 FROM todo_lists))
+	`, listID, name, description, color)
+	if err != nil {
+		return "", fmt.Errorf("failed to create todo list: %w", err)
+	}
+
+	now := time.Now()
+	newList := models.TodoList{
+		ID:          listID,
+		Name:        name,
+		Description: description,
+		Color:       color,
+		Tasks:       []models.Task{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	for _, templateTask := range template.Tasks {
+		task := models.Task{
+			ID:          s.newID(),
+			Title:       templateTask.Title,
+			Description: templateTask.Description,
+			Priority:    templateTask.Priority,
+			Deadline:    templateTask.ResolveDeadline(now),
+			Label:       templateTask.Label,
+			Source:      models.SourceTemplate,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+
+		var deadlineStr sql.NullString
+		if task.Deadline != nil {
+			deadlineStr = sql.NullString{String: task.Deadline.Format("2006-01-02 15:04:05"), Valid: true}
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO tasks (id, list_id, title, description, priority, deadline, label, source, position) 
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, `+nextTaskPosition+`)
+		`, task.ID, listID, task.Title, task.Description, int(task.Priority), deadlineStr, task.Label, task.Source, listID)
+		if err != nil {
+			return "", fmt.Errorf("failed to create task: %w", err)
+		}
+
+		newList.Tasks = append(newList.Tasks, task)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Add to in-memory structure
+	app.TodoLists = append(app.TodoLists, newList)
+	s.logListChange(models.ActionCreated, listID, name)
+
+	return listID, nil
+}
+
+// CreateTask creates a new task and returns it as the database stored it
+func (s *DatabaseStorage) CreateTask(app *models.Application, listID, title, description string, priority models.Priority, deadline *time.Time, label, source string) (models.Task, error) {
+	if s.readOnly {
+		return models.Task{}, ErrReadOnly
+	}
+	title, err := models.NormalizeTitle(title)
+	if err != nil {
+		return models.Task{}, err
+	}
+	description = models.NormalizeText(description)
+
+	taskID := s.newID()
+
+	var deadlineStr sql.NullString
+	if deadline != nil {
+		deadlineStr = sql.NullString{String: deadline.Format("2006-01-02 15:04:05"), Valid: true}
+	}
+
+	_, err = s.conn().Exec(`
+		INSERT INTO tasks (id, list_id, title, description, priority, deadline, label, source, position) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, `+nextTaskPosition+`)
+	`, taskID, listID, title, description, int(priority), deadlineStr, label, source, listID)
+
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to create task: %w", err)
+	}
+	s.logTaskChange(models.ActionCreated, listID, models.Task{ID: taskID, Title: title})
+
+	return s.getTask(listID, taskID)
+}
+
+// DuplicateTask copies a task's row under a new ID, keeping the columns
+// Task.Duplicate keeps, and moves the tasks after it down to make room
+func (s *DatabaseStorage) DuplicateTask(app *models.Application, listID, taskID string) (models.Task, error) {
+	if s.readOnly {
+		return models.Task{}, ErrReadOnly
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var position int
+	err = tx.QueryRow("SELECT position FROM tasks WHERE id = ? AND list_id = ? AND deleted_at IS NULL", taskID, listID).Scan(&position)
+	if err == sql.ErrNoRows {
+		return models.Task{}, fmt.Errorf("task with ID %s not found in list %s", taskID, listID)
+	}
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to read task: %w", err)
+	}
+
+	if _, err := tx.Exec("UPDATE tasks SET position = position + 1 WHERE list_id = ? AND position > ?", listID, position); err != nil {
+		return models.Task{}, fmt.Errorf("failed to make room for the copy: %w", err)
+	}
+
+	duplicateID := s.newID()
+	_, err = tx.Exec(`
+		INSERT INTO tasks (id, list_id, title, description, priority, deadline, label, estimate, link, reminder_offset, source, position, created_at, updated_at)
+		SELECT ?, list_id, title, description, priority, deadline, label, estimate, link, reminder_offset, source, position + 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+		FROM tasks
+		WHERE id = ?
+	`, duplicateID, taskID)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to duplicate task: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Task{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	duplicate, err := s.getTask(listID, duplicateID)
+	if err == nil {
+		s.logTaskChange(models.ActionCreated, listID, duplicate)
+	}
+	return duplicate, err
 }
 
 // UpdateTask updates an existing task
-func (s *DatabaseStorage) UpdateTask(app *models.Application, listID, taskID, title, description string, priority models.Priority, deadline *time.Time) error {
+func (s *DatabaseStorage) UpdateTask(app *models.Application, listID, taskID, title, description string, priority models.Priority, deadline *time.Time, label string) (models.Task, error) {
+	if s.readOnly {
+		return models.Task{}, ErrReadOnly
+	}
+	title, err := models.NormalizeTitle(title)
+	if err != nil {
+		return models.Task{}, err
+	}
+	description = models.NormalizeText(description)
+
 	var deadlineStr sql.NullString
 	if deadline != nil {
 		deadlineStr = sql.NullString{String: deadline.Format("2006-01-02 15:04:05"), Valid: true}
 	}
 
-	_, err := s.db.Exec(`
+	// Read as it was, for the activity log
+	before, beforeErr := s.getTask(listID, taskID)
+
+	_, err = s.conn().Exec(`
 		UPDATE tasks 
-		SET title = ?, description = ?, priority = ?, deadline = ? 
+		SET title = ?, description = ?, priority = ?, deadline = ?, label = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ? AND list_id = ?
-	`, title, description, int(priority), deadlineStr, taskID, listID)
+	`, title, description, int(priority), deadlineStr, label, taskID, listID)
 
 	if err != nil {
-		return fmt.Errorf("failed to update task: %w", err)
+		return models.Task{}, fmt.Errorf("failed to update task: %w", err)
 	}
 
-	// Update in-memory structure
+	task, err := s.getTask(listID, taskID)
+	if err == nil && beforeErr == nil {
+		if old, new := describeTaskEdit(before, task); old != "" {
+			s.logChange(models.Change{Action: models.ActionEdited, Entity: models.EntityTask, EntityID: taskID, ListID: listID,
+				Title: task.Title, Old: old, New: new})
+		}
+	}
+	return task, err
+}
+
+// SnoozeTask moves the deadline of a task and counts the snooze
+func (s *DatabaseStorage) SnoozeTask(app *models.Application, listID, taskID string, deadline time.Time) (models.Task, error) {
+	if s.readOnly {
+		return models.Task{}, ErrReadOnly
+	}
+
+	_, err := s.conn().Exec(`
+		UPDATE tasks
+		SET deadline = ?, snooze_count = snooze_count + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND list_id = ?
+	`, deadline.Format("2006-01-02 15:04:05"), taskID, listID)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to snooze task: %w", err)
+	}
+
+	return s.getTask(listID, taskID)
+}
+
+// UpdateTaskTime sets the estimate and time spent of a task
+func (s *DatabaseStorage) UpdateTaskTime(app *models.Application, listID, taskID string, estimate, spent time.Duration) (models.Task, error) {
+	if s.readOnly {
+		return models.Task{}, ErrReadOnly
+	}
+
+	_, err := s.conn().Exec(`
+		UPDATE tasks
+		SET estimate = ?, spent = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND list_id = ?
+	`, durationSeconds(estimate), durationSeconds(spent), taskID, listID)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to update task time: %w", err)
+	}
+
+	return s.getTask(listID, taskID)
+}
+
+// SetTaskLink sets the URL or file reference of a task; an empty link removes it
+func (s *DatabaseStorage) SetTaskLink(app *models.Application, listID, taskID, link string) (models.Task, error) {
+	if s.readOnly {
+		return models.Task{}, ErrReadOnly
+	}
+
+	_, err := s.conn().Exec(`
+		UPDATE tasks
+		SET link = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND list_id = ?
+	`, link, taskID, listID)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to update task link: %w", err)
+	}
+
+	return s.getTask(listID, taskID)
+}
+
+// SetTaskReminder sets how long before its deadline a task is reminded about;
+// a nil offset goes back to the global setting
+func (s *DatabaseStorage) SetTaskReminder(app *models.Application, listID, taskID string, offset *time.Duration) (models.Task, error) {
+	if s.readOnly {
+		return models.Task{}, ErrReadOnly
+	}
+
+	_, err := s.conn().Exec(`
+		UPDATE tasks
+		SET reminder_offset = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND list_id = ?
+	`, offsetSeconds(offset), taskID, listID)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to update task reminder: %w", err)
+	}
+
+	return s.getTask(listID, taskID)
+}
+
+// SetTaskProgress sets how far a task is done, in percent
+func (s *DatabaseStorage) SetTaskProgress(app *models.Application, listID, taskID string, progress int) (models.Task, error) {
+	if s.readOnly {
+		return models.Task{}, ErrReadOnly
+	}
+
+	_, err := s.conn().Exec(`
+		UPDATE tasks
+		SET progress = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND list_id = ?
+	`, models.ClampProgress(progress), taskID, listID)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to update task progress: %w", err)
+	}
+
+	return s.getTask(listID, taskID)
+}
+
+// SetTaskStarred stars a task for the daily plan or unstars it
+func (s *DatabaseStorage) SetTaskStarred(app *models.Application, listID, taskID string, starred bool) (models.Task, error) {
+	if s.readOnly {
+		return models.Task{}, ErrReadOnly
+	}
+
+	_, err := s.conn().Exec(`
+		UPDATE tasks
+		SET starred = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND list_id = ?
+	`, starred, taskID, listID)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to update task star: %w", err)
+	}
+
+	return s.getTask(listID, taskID)
+}
+
+// SetTasksPriority sets the priority of several tasks of a list in a single transaction
+func (s *DatabaseStorage) SetTasksPriority(app *models.Application, listID string, taskIDs []string, priority models.Priority) ([]models.Task, error) {
+	return s.updateTasks(listID, taskIDs, "priority = ?", int(priority))
+}
+
+// SetTasksDeadline sets or, with a nil deadline, clears the deadline of
+// several tasks of a list in a single transaction
+func (s *DatabaseStorage) SetTasksDeadline(app *models.Application, listID string, taskIDs []string, deadline *time.Time) ([]models.Task, error) {
+	var deadlineStr sql.NullString
+	if deadline != nil {
+		deadlineStr = sql.NullString{String: deadline.Format(timestampLayout), Valid: true}
+	}
+	return s.updateTasks(listID, taskIDs, "deadline = ?", deadlineStr)
+}
+
+// updateTasks applies the assignment set, taking value, to several tasks of a
+// list in a single transaction that is rolled back when one of them is
+// missing, and returns the tasks as stored
+func (s *DatabaseStorage) updateTasks(listID string, taskIDs []string, set string, value any) ([]models.Task, error) {
+	if s.readOnly {
+		return nil, ErrReadOnly
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, taskID := range taskIDs {
+		result, err := tx.Exec(`
+			UPDATE tasks
+			SET `+set+`, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND list_id = ? AND deleted_at IS NULL
+		`, value, taskID, listID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update task %s: %w", taskID, err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return nil, fmt.Errorf("task with ID %s not found in list %s", taskID, listID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	tasks := make([]models.Task, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		task, err := s.getTask(listID, taskID)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// ShiftDeadlines moves the deadline of every incomplete task in a list by
+// delta in a single transaction
+func (s *DatabaseStorage) ShiftDeadlines(app *models.Application, listID string, delta time.Duration) (int, error) {
+	if s.readOnly {
+		return 0, ErrReadOnly
+	}
+
+	// Make sure the in-memory list is loaded before changing it
+	if err := s.LoadTasks(app, listID); err != nil {
+		return 0, err
+	}
+
+	var todoList *models.TodoList
 	for i := range app.TodoLists {
 		if app.TodoLists[i].ID == listID {
-			for j := range app.TodoLists[i].Tasks {
-				if app.TodoLists[i].Tasks[j].ID == taskID {
-					app.TodoLists[i].Tasks[j].Title = title
-					app.TodoLists[i].Tasks[j].Description = description
-					app.TodoLists[i].Tasks[j].Priority = priority
-					app.TodoLists[i].Tasks[j].Deadline = deadline
-					app.TodoLists[i].Tasks[j].UpdatedAt = time.Now()
-					app.TodoLists[i].UpdatedAt = time.Now()
-					return nil
-				}
-			}
+			todoList = &app.TodoLists[i]
+			break
+		}
+	}
+	if todoList == nil {
+		return 0, fmt.Errorf("todo list with ID %s not found", listID)
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Deadlines are computed from memory so they are written in the usual format
+	shifted := make(map[string]time.Time)
+	for _, task := range todoList.Tasks {
+		if task.Completed || task.Deadline == nil {
+			continue
+		}
+		deadline := task.Deadline.Add(delta)
+		if _, err := tx.Exec(`
+			UPDATE tasks
+			SET deadline = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND list_id = ?
+		`, deadline.Format(timestampLayout), task.ID, listID); err != nil {
+			return 0, fmt.Errorf("failed to shift deadline of %s: %w", task.Title, err)
+		}
+		shifted[task.ID] = deadline
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Update in-memory structure
+	for i := range todoList.Tasks {
+		if deadline, ok := shifted[todoList.Tasks[i].ID]; ok {
+			todoList.Tasks[i].Deadline = &deadline
+			todoList.Tasks[i].UpdatedAt = time.Now()
 		}
 	}
+	if len(shifted) > 0 {
+		todoList.UpdatedAt = time.Now()
+	}
+
+	return len(shifted), nil
+}
+
+// durationSeconds converts an estimate or time spent to the whole seconds stored in the database
+func durationSeconds(d time.Duration) int64 {
+	return int64(d / time.Second)
+}
+
+// offsetSeconds converts a reminder offset to the seconds stored in the
+// database, with NULL for tasks that use the global setting
+func offsetSeconds(offset *time.Duration) sql.NullInt64 {
+	if offset == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: durationSeconds(*offset), Valid: true}
+}
 
-	return fmt.Errorf("task not found in memory")
+// offsetFromSeconds reads a reminder offset stored by offsetSeconds
+func offsetFromSeconds(seconds sql.NullInt64) *time.Duration {
+	if !seconds.Valid {
+		return nil
+	}
+	offset := time.Duration(seconds.Int64) * time.Second
+	return &offset
 }
 
 // ToggleTask toggles the completion status of a task
-func (s *DatabaseStorage) ToggleTask(app *models.Application, listID, taskID string) error {
+func (s *DatabaseStorage) ToggleTask(app *models.Application, listID, taskID string) (models.Task, error) {
+	if s.readOnly {
+		return models.Task{}, ErrReadOnly
+	}
+
 	// First get current status
 	var completed bool
-	err := s.db.QueryRow("SELECT completed FROM tasks WHERE id = ? AND list_id = ?", taskID, listID).Scan(&completed)
+	err := s.conn().QueryRow("SELECT completed FROM tasks WHERE id = ? AND list_id = ?", taskID, listID).Scan(&completed)
 	if err != nil {
-		return fmt.Errorf("failed to get task status: %w", err)
+		return models.Task{}, fmt.Errorf("failed to get task status: %w", err)
 	}
 
-	// Toggle it
+	// Toggle it, recording when it was completed
 	newCompleted := !completed
-	_, err = s.db.Exec("UPDATE tasks SET completed = ? WHERE id = ? AND list_id = ?", newCompleted, taskID, listID)
+	var completedAt *time.Time
+	if newCompleted {
+		now := time.Now()
+		completedAt = &now
+	}
+	_, err = s.conn().Exec("UPDATE tasks SET completed = ?, completed_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND list_id = ?",
+		newCompleted, nullTimestamp(completedAt), taskID, listID)
 	if err != nil {
-		return fmt.Errorf("failed to toggle task: %w", err)
+		return models.Task{}, fmt.Errorf("failed to toggle task: %w", err)
 	}
 
-	// Update in-memory structure
-	for i := range app.TodoLists {
-		if app.TodoLists[i].ID == listID {
-			for j := range app.TodoLists[i].Tasks {
-				if app.TodoLists[i].Tasks[j].ID == taskID {
-					app.TodoLists[i].Tasks[j].Completed = newCompleted
-					app.TodoLists[i].Tasks[j].UpdatedAt = time.Now()
-					app.TodoLists[i].UpdatedAt = time.Now()
-					return nil
-				}
-			}
+	task, err := s.getTask(listID, taskID)
+	if err == nil {
+		action := models.ActionReopened
+		if task.Completed {
+			action = models.ActionCompleted
 		}
+		s.logTaskChange(action, listID, task)
 	}
-
-	return fmt.Errorf("task not found in memory")
+	return task, err
 }
 
-// DeleteTask deletes a task from a todo list
+// DeleteTask moves a task of a todo list to the trash. Its row stays with a
+// deleted_at timestamp, and updated_at is kept so a restored task is unchanged.
 func (s *DatabaseStorage) DeleteTask(app *models.Application, listID, taskID string) error {
-	_, err := s.db.Exec("DELETE FROM tasks WHERE id = ? AND list_id = ?", taskID, listID)
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	result, err := s.conn().Exec("UPDATE tasks SET deleted_at = ? WHERE id = ? AND list_id = ? AND deleted_at IS NULL",
+		time.Now().UTC().Format(timestampLayout), taskID, listID)
 	if err != nil {
 		return fmt.Errorf("failed to delete task: %w", err)
 	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("task with ID %s not found in list %s", taskID, listID)
+	}
+	s.logTaskChange(models.ActionDeleted, listID, models.Task{ID: taskID, Title: s.taskTitle(listID, taskID)})
+
+	return nil
+}
+
+// TrashedTasks returns the tasks in the trash across all lists, most recently deleted first
+func (s *DatabaseStorage) TrashedTasks(app *models.Application) ([]models.ListTask, error) {
+	rows, err := s.conn().Query(`
+		SELECT t.id, t.title, t.completed, t.priority, t.deadline, t.label, t.deleted_at, t.created_at, t.updated_at, l.id, l.name
+		FROM tasks t
+		JOIN todo_lists l ON l.id = t.list_id
+		WHERE t.deleted_at IS NOT NULL
+		ORDER BY t.deleted_at DESC, t.id DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trash: %w", err)
+	}
+	defer rows.Close()
+
+	var trash []models.ListTask
+	for rows.Next() {
+		var entry models.ListTask
+		var deadline sql.NullString
+		var deletedAt, createdAt, updatedAt string
+		task := &entry.Task
+		if err := rows.Scan(
+			&task.ID, &task.Title, &task.Completed, &task.Priority, &deadline, &task.Label,
+			&deletedAt, &createdAt, &updatedAt, &entry.ListID, &entry.ListName,
+		); err != nil {
+			s.skipRow("task", err)
+			continue
+		}
+		task.Priority = task.Priority.Clamp()
+
+		if dl, ok := parseTimestamp(deadline.String); ok {
+			task.Deadline = &dl
+		}
+		if deleted, ok := parseTimestamp(deletedAt); ok {
+			task.DeletedAt = &deleted
+		}
+		task.CreatedAt, _ = parseTimestamp(createdAt)
+		task.UpdatedAt, _ = parseTimestamp(updatedAt)
+		trash = append(trash, entry)
+	}
+
+	return trash, rows.Err()
+}
+
+// RestoreTask moves a task from the trash back into its list
+func (s *DatabaseStorage) RestoreTask(app *models.Application, listID, taskID string) (models.Task, error) {
+	if s.readOnly {
+		return models.Task{}, ErrReadOnly
+	}
+
+	result, err := s.conn().Exec("UPDATE tasks SET deleted_at = NULL WHERE id = ? AND list_id = ? AND deleted_at IS NOT NULL",
+		taskID, listID)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to restore task: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return models.Task{}, fmt.Errorf("task with ID %s is not in the trash", taskID)
+	}
+
+	// The task comes back with its notes
+	task, err := s.getTask(listID, taskID)
+	if err == nil {
+		s.logTaskChange(models.ActionRestored, listID, task)
+	}
+	return task, err
+}
+
+// PurgeTrash permanently deletes the tasks that were moved to the trash
+// before the given time, with their notes, and returns how many there were
+func (s *DatabaseStorage) PurgeTrash(app *models.Application, before time.Time) (int, error) {
+	if s.readOnly {
+		return 0, ErrReadOnly
+	}
+
+	result, err := s.conn().Exec("DELETE FROM tasks WHERE deleted_at IS NOT NULL AND deleted_at < ?",
+		before.UTC().Format(timestampLayout))
+	if err != nil {
+		return 0, fmt.Errorf("failed to empty trash: %w", err)
+	}
+
+	purged, _ := result.RowsAffected()
+	return int(purged), nil
+}
+
+// AddNote appends a note to a task
+func (s *DatabaseStorage) AddNote(app *models.Application, listID, taskID, body string) (string, error) {
+	if s.readOnly {
+		return "", ErrReadOnly
+	}
+	body = models.NormalizeText(body)
+
+	noteID := s.newID()
+	now := time.Now()
+
+	_, err := s.conn().Exec(`
+		INSERT INTO task_notes (id, task_id, body, created_at)
+		VALUES (?, ?, ?, ?)
+	`, noteID, taskID, body, now.UTC().Format(timestampLayout))
+
+	if err != nil {
+		return "", fmt.Errorf("failed to add note: %w", err)
+	}
+
+	// Add to in-memory structure
+	task, err := findTask(app, listID, taskID)
+	if err != nil {
+		return "", fmt.Errorf("task not found in memory")
+	}
+	task.Notes = append(task.Notes, models.Note{
+		ID:        noteID,
+		TaskID:    taskID,
+		Body:      body,
+		CreatedAt: now,
+	})
+
+	return noteID, nil
+}
+
+// DeleteNote removes a note from a task
+func (s *DatabaseStorage) DeleteNote(app *models.Application, listID, taskID, noteID string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	_, err := s.conn().Exec("DELETE FROM task_notes WHERE id = ? AND task_id = ?", noteID, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
 
 	// Remove from in-memory structure
-	for i := range app.TodoLists {
-		if app.TodoLists[i].ID == listID {
-			for j, task := range app.TodoLists[i].Tasks {
-				if task.ID == taskID {
-					app.TodoLists[i].Tasks = append(app.TodoLists[i].Tasks[:j], app.TodoLists[i].Tasks[j+1:]...)
-					app.TodoLists[i].UpdatedAt = time.Now()
-					return nil
-				}
+	task, err := findTask(app, listID, taskID)
+	if err != nil {
+		return fmt.Errorf("task not found in memory")
+	}
+	for i, note := range task.Notes {
+		if note.ID == noteID {
+			task.Notes = append(task.Notes[:i], task.Notes[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("note not found in memory")
+}
+
+// ListNotes returns the notes of a task in the order they were added
+func (s *DatabaseStorage) ListNotes(app *models.Application, listID, taskID string) ([]models.Note, error) {
+	rows, err := s.conn().Query(`
+		SELECT id, task_id, body, created_at
+		FROM task_notes
+		WHERE task_id = ?
+		ORDER BY created_at ASC, id ASC
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notes: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []models.Note
+	for rows.Next() {
+		note, err := scanNote(rows)
+		if err != nil {
+			s.skipRow("note", err)
+			continue
+		}
+		notes = append(notes, note)
+	}
+
+	return notes, nil
+}
+
+// Merge merges incoming data in a single transaction, keeping the incoming
+// IDs and timestamps, then reloads app from the database
+func (s *DatabaseStorage) Merge(app *models.Application, incoming *models.Application) (MergeResult, error) {
+	if s.readOnly {
+		return MergeResult{}, ErrReadOnly
+	}
+
+	for _, list := range app.TodoLists {
+		if err := s.LoadTasks(app, list.ID); err != nil {
+			return MergeResult{}, err
+		}
+	}
+
+	plan := planMerge(app, incoming)
+	if !plan.result().Changed() {
+		return MergeResult{}, nil
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		return MergeResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, list := range plan.newLists {
+		_, err := tx.Exec(`
+			INSERT INTO todo_lists (id, name, description, color, group_name, grouping, pinned, sort_order, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, (SELECT COALESCE(MAX(sort_order), -1) + 1 FROM todo_lists), ?, ?)
+		`, list.ID, list.Name, list.Description, list.Color, list.Group, list.Grouping, list.Pinned,
+			list.CreatedAt.UTC().Format(timestampLayout),
+			list.UpdatedAt.UTC().Format(timestampLayout))
+		if err != nil {
+			return MergeResult{}, fmt.Errorf("failed to insert todo list %s: %w", list.Name, err)
+		}
+
+		for _, task := range list.Tasks {
+			if err := insertTask(tx, list.ID, task); err != nil {
+				return MergeResult{}, err
 			}
 		}
 	}
 
-	return fmt.Errorf("task not found in memory")
+	for _, list := range plan.updatedLists {
+		_, err := tx.Exec("UPDATE todo_lists SET name = ?, description = ?, color = ?, group_name = ?, updated_at = ? WHERE id = ?",
+			list.Name, list.Description, list.Color, list.Group, list.UpdatedAt.UTC().Format(timestampLayout), list.ID)
+		if err != nil {
+			return MergeResult{}, fmt.Errorf("failed to update todo list %s: %w", list.Name, err)
+		}
+	}
+
+	for _, entry := range plan.newTasks {
+		if err := insertTask(tx, entry.ListID, entry.Task); err != nil {
+			return MergeResult{}, err
+		}
+	}
+
+	for _, entry := range plan.updatedTasks {
+		task := entry.Task
+		var deadlineStr sql.NullString
+		if task.Deadline != nil {
+			deadlineStr = sql.NullString{String: task.Deadline.Format(timestampLayout), Valid: true}
+		}
+
+		_, err := tx.Exec(`
+			UPDATE tasks
+			SET title = ?, description = ?, completed = ?, priority = ?, deadline = ?, label = ?, snooze_count = ?,
+				estimate = ?, spent = ?, link = ?, reminder_offset = ?, progress = ?, starred = ?, completed_at = ?, updated_at = ?
+			WHERE id = ?
+		`, task.Title, task.Description, task.Completed, int(task.Priority), deadlineStr, task.Label,
+			task.SnoozeCount, durationSeconds(task.Estimate), durationSeconds(task.Spent), task.Link,
+			offsetSeconds(task.ReminderOffset), task.Progress, task.Starred, nullTimestamp(task.CompletedAt),
+			task.UpdatedAt.UTC().Format(timestampLayout), task.ID)
+		if err != nil {
+			return MergeResult{}, fmt.Errorf("failed to update task %s: %w", task.Title, err)
+		}
+	}
+
+	for _, note := range plan.newNotes {
+		if err := insertNote(tx, note); err != nil {
+			return MergeResult{}, err
+		}
+	}
+
+	for _, template := range plan.newTemplates {
+		if err := insertTemplate(tx, template); err != nil {
+			return MergeResult{}, err
+		}
+	}
+
+	s.logMerge(tx, app, plan)
+
+	if err := tx.Commit(); err != nil {
+		return MergeResult{}, fmt.Errorf("failed to commit merge: %w", err)
+	}
+
+	// Reload rather than patching memory, so app matches the database exactly
+	fresh, err := s.Load()
+	if err != nil {
+		return MergeResult{}, err
+	}
+	app.TodoLists = fresh.TodoLists
+	app.Templates = fresh.Templates
+
+	return plan.result(), nil
+}
+
+// newID returns the ID of a record the operation creates
+func (s *DatabaseStorage) newID() string {
+	return s.assigned.next()
 }
 
-// generateDatabaseID generates a simple unique ID for database records
-func generateDatabaseID() string {
-	return fmt.Sprintf("%d", time.Now().UnixNano())
+// withIDs returns a copy of s whose operation creates its records with the
+// IDs given, in order
+func (s *DatabaseStorage) withIDs(ids []string) StorageInterface {
+	assigned := *s
+	assigned.assigned = &idQueue{ids: ids}
+	return &assigned
 }
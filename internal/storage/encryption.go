@@ -0,0 +1,384 @@
+package storage
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// EncryptedDatabaseName is the file holding the database when at-rest encryption is enabled
+const EncryptedDatabaseName = "lazytodo.db.enc"
+
+// PassphraseEnv names the environment variable read for the encryption passphrase
+const PassphraseEnv = "LAZYTODO_PASSPHRASE"
+
+var (
+	// ErrPassphraseRequired is returned when opening an encrypted database without a passphrase
+	ErrPassphraseRequired = errors.New("database is encrypted: a passphrase is required")
+
+	// ErrWrongPassphrase is returned when the passphrase does not decrypt the database
+	ErrWrongPassphrase = errors.New("incorrect passphrase for encrypted database")
+)
+
+const (
+	encryptionMagic = "LAZYTODO-ENC1\n"
+	saltSize        = 16
+	keySize         = 32 // AES-256
+	keyIterations   = 200000
+)
+
+// EncryptionEnabled reports whether the database in the user's data directory is encrypted
+func EncryptionEnabled() bool {
+	_, err := os.Stat(filepath.Join(resolveDataDir(io.Discard), EncryptedDatabaseName))
+	return err == nil
+}
+
+// openEncryptedDatabase decrypts the database file into an in-memory SQLite
+// database. Plaintext never touches the disk; Save and Close write an
+// encrypted snapshot back to encryptedPath.
+func openEncryptedDatabase(encryptedPath string, opts Options, readOnly bool) (*DatabaseStorage, error) {
+	if opts.Passphrase == "" {
+		return nil, ErrPassphraseRequired
+	}
+
+	data, err := os.ReadFile(encryptedPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encrypted database: %w", err)
+	}
+
+	image, key, salt, err := decryptImage(data, opts.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite3", "file::memory:?_foreign_keys=on")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Every connection to :memory: is a separate database, so keep exactly one
+	db.SetMaxOpenConns(1)
+
+	if err := restoreImage(db, image); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to load decrypted database: %w", err)
+	}
+
+	storage := &DatabaseStorage{
+		db:             db,
+		dataPath:       encryptedPath,
+		readOnly:       readOnly,
+		out:            opts.output(),
+		encryptionKey:  key,
+		encryptionSalt: salt,
+	}
+
+	if !readOnly {
+		if err := storage.runMigrations(); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to run migrations: %w", err)
+		}
+	}
+
+	return storage, nil
+}
+
+// encrypted reports whether the storage is backed by an encrypted file
+func (s *DatabaseStorage) encrypted() bool {
+	return s.encryptionKey != nil
+}
+
+// writeEncrypted replaces the encrypted file with a snapshot of the in-memory database
+func (s *DatabaseStorage) writeEncrypted() error {
+	image, err := serializeImage(s.db)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+
+	data, err := encryptImage(image, s.encryptionKey, s.encryptionSalt)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.dataPath, data)
+}
+
+// EncryptDatabase converts the plain database into an encrypted one protected
+// by passphrase and removes the plain file. It returns the encrypted file path.
+func EncryptDatabase(passphrase string) (string, error) {
+	if passphrase == "" {
+		return "", ErrPassphraseRequired
+	}
+	if EncryptionEnabled() {
+		return "", errors.New("database is already encrypted")
+	}
+
+	// Opening the plain database creates it and brings the schema up to date
+	dbStorage, err := NewDatabase(Options{})
+	if err != nil {
+		return "", err
+	}
+	plainPath := dbStorage.dataPath
+
+	image, err := serializeImage(dbStorage.db)
+	dbStorage.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to read database: %w", err)
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	data, err := encryptImage(image, deriveKey(passphrase, salt), salt)
+	if err != nil {
+		return "", err
+	}
+
+	encryptedPath := filepath.Join(filepath.Dir(plainPath), EncryptedDatabaseName)
+	if err := writeFileAtomic(encryptedPath, data); err != nil {
+		return "", err
+	}
+
+	if err := os.Remove(plainPath); err != nil {
+		return "", fmt.Errorf("encrypted database written but failed to remove plain copy: %w", err)
+	}
+
+	return encryptedPath, nil
+}
+
+// DecryptDatabase converts the encrypted database back into a plain SQLite
+// file and removes the encrypted file. It returns the plain file path.
+func DecryptDatabase(passphrase string) (string, error) {
+	if !EncryptionEnabled() {
+		return "", errors.New("database is not encrypted")
+	}
+
+	dbStorage, err := NewDatabase(Options{Passphrase: passphrase})
+	if err != nil {
+		return "", err
+	}
+	defer dbStorage.Close()
+
+	image, err := serializeImage(dbStorage.db)
+	if err != nil {
+		return "", fmt.Errorf("failed to read database: %w", err)
+	}
+
+	encryptedPath := dbStorage.dataPath
+	plainPath := filepath.Join(filepath.Dir(encryptedPath), DatabaseName)
+	if err := writeFileAtomic(plainPath, image); err != nil {
+		return "", err
+	}
+
+	// Stop Close from writing the encrypted file back
+	dbStorage.encryptionKey = nil
+	if err := os.Remove(encryptedPath); err != nil {
+		return "", fmt.Errorf("plain database written but failed to remove encrypted copy: %w", err)
+	}
+
+	return plainPath, nil
+}
+
+// encryptImage seals a database image with AES-256-GCM. The file layout is
+// magic | salt | nonce | ciphertext, with the magic authenticated as well.
+func encryptImage(image, key, salt []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	data := make([]byte, 0, len(encryptionMagic)+len(salt)+len(nonce)+len(image)+gcm.Overhead())
+	data = append(data, encryptionMagic...)
+	data = append(data, salt...)
+	data = append(data, nonce...)
+	return gcm.Seal(data, nonce, image, []byte(encryptionMagic)), nil
+}
+
+// decryptImage opens data written by encryptImage and returns the database
+// image along with the derived key and salt for re-encrypting it later
+func decryptImage(data []byte, passphrase string) (image, key, salt []byte, err error) {
+	if len(data) < len(encryptionMagic)+saltSize || string(data[:len(encryptionMagic)]) != encryptionMagic {
+		return nil, nil, nil, errors.New("file is not an encrypted LazyTodo database")
+	}
+	data = data[len(encryptionMagic):]
+
+	salt, data = data[:saltSize], data[saltSize:]
+	key = deriveKey(passphrase, salt)
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, nil, nil, errors.New("encrypted database is truncated")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	image, err = gcm.Open(nil, nonce, ciphertext, []byte(encryptionMagic))
+	if err != nil {
+		// GCM cannot tell a wrong key from tampering; a wrong passphrase is by far the likely cause
+		return nil, nil, nil, ErrWrongPassphrase
+	}
+
+	return image, key, append([]byte(nil), salt...), nil
+}
+
+// newGCM creates an AES-GCM cipher for key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// deriveKey derives an AES-256 key from a passphrase with PBKDF2-HMAC-SHA256.
+// Files encrypted so far depend on its parameters: changing them needs a new
+// encryptionMagic, so that older files are still opened with these.
+func deriveKey(passphrase string, salt []byte) []byte {
+	return pbkdf2.Key([]byte(passphrase), salt, keyIterations, keySize, sha256.New)
+}
+
+// serializeImage returns the SQLite file image of the database's connection
+func serializeImage(db *sql.DB) ([]byte, error) {
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var image []byte
+	err = conn.Raw(func(driverConn any) error {
+		sqliteConn, ok := driverConn.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+
+		image, err = sqliteConn.Serialize("")
+		return err
+	})
+
+	return image, err
+}
+
+// restoreImage copies a SQLite file image into the database. The image is
+// deserialized into a scratch connection and copied with the backup API,
+// since a deserialized database has a fixed size and could not grow.
+func restoreImage(db *sql.DB, image []byte) error {
+	scratch, err := sql.Open("sqlite3", "file::memory:")
+	if err != nil {
+		return err
+	}
+	defer scratch.Close()
+
+	ctx := context.Background()
+	srcConn, err := scratch.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	destConn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+
+	return srcConn.Raw(func(srcDriverConn any) error {
+		src, ok := srcDriverConn.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", srcDriverConn)
+		}
+		if err := src.Deserialize(image, ""); err != nil {
+			return err
+		}
+
+		return destConn.Raw(func(destDriverConn any) error {
+			dest, ok := destDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected driver connection %T", destDriverConn)
+			}
+
+			backup, err := dest.Backup("main", src, "main")
+			if err != nil {
+				return err
+			}
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return err
+			}
+			return backup.Finish()
+		})
+	})
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path so a
+// crash never leaves a half-written database behind. The data reaches the disk
+// before the rename, and the rename before it returns.
+func writeFileAtomic(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	if err := writeFileSynced(tmpPath, data); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write %s: %w", tmpPath, err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeFileSynced writes data to the file at path and flushes it to disk
+func writeFileSynced(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// syncDir flushes the entries of directory dir, such as a rename, to disk.
+// Windows cannot sync a directory, and makes renames durable by itself.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDeriveKeyUnchanged pins the keys of files encrypted so far, derived
+// before deriveKey used x/crypto/pbkdf2
+func TestDeriveKeyUnchanged(t *testing.T) {
+	tests := []struct {
+		passphrase, salt, want string
+	}{
+		{"correct horse battery staple", "0123456789abcdef", "7f2c954f85f5934bde900ac77e9dfba6f55a39244eb24496bbac967f5ef3a251"},
+		{"", "", "bdc93b20887580a86bedb4d5f69ccf5aa6951d091ca16fd6055cc2894983efcc"},
+	}
+	for _, test := range tests {
+		if got := hex.EncodeToString(deriveKey(test.passphrase, []byte(test.salt))); got != test.want {
+			t.Errorf("deriveKey(%q, %q) = %s, want %s", test.passphrase, test.salt, got, test.want)
+		}
+	}
+}
+
+func TestEncryptedDatabaseRoundTrip(t *testing.T) {
+	store := openBackend(t, "database")
+	app, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	mustCreateList(t, store, app, "Secrets")
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, err := EncryptDatabase("hunter2"); err != nil {
+		t.Fatalf("EncryptDatabase: %v", err)
+	}
+	dir := filepath.Dir(store.GetDataPath())
+	if _, err := os.Stat(filepath.Join(dir, EncryptedDatabaseName+".tmp")); !os.IsNotExist(err) {
+		t.Errorf("temporary file after encrypting: %v, want none", err)
+	}
+
+	if _, err := NewDatabase(Options{Output: io.Discard, Passphrase: "wrong"}); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("opening with the wrong passphrase: %v, want %v", err, ErrWrongPassphrase)
+	}
+	encrypted, err := NewDatabase(Options{Output: io.Discard, Passphrase: "hunter2"})
+	if err != nil {
+		t.Fatalf("opening the encrypted database: %v", err)
+	}
+	defer encrypted.Close()
+	if got := describeLists(t, encrypted); got != "Secrets" {
+		t.Errorf("lists of the encrypted database = %q, want %q", got, "Secrets")
+	}
+}
@@ -0,0 +1,433 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// ExportVersion identifies the layout of export files
+const ExportVersion = 1
+
+// ExportFile is the canonical JSON document written by Export and read by ParseExport.
+// Settings are left out on purpose: they describe one machine, not the shared data.
+type ExportFile struct {
+	Version   int               `json:"version"`
+	TodoLists []models.TodoList `json:"todo_lists"`
+	Templates []models.Template `json:"templates,omitempty"`
+}
+
+// MergeResult counts what merging an export changed
+type MergeResult struct {
+	ListsAdded     int
+	ListsUpdated   int
+	TasksAdded     int
+	TasksUpdated   int
+	NotesAdded     int
+	TemplatesAdded int
+}
+
+// Changed reports whether the merge changed anything
+func (r MergeResult) Changed() bool {
+	return r != MergeResult{}
+}
+
+func (r MergeResult) String() string {
+	var parts []string
+	add := func(count int, what string) {
+		if count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, what))
+		}
+	}
+	add(r.ListsAdded, "lists added")
+	add(r.ListsUpdated, "lists updated")
+	add(r.TasksAdded, "tasks added")
+	add(r.TasksUpdated, "tasks updated")
+	add(r.NotesAdded, "notes added")
+	add(r.TemplatesAdded, "templates added")
+
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Export renders everything in storage as a canonical JSON export. Data is read
+// back from storage rather than taken from memory, and lists, tasks, notes and
+// templates are ordered by creation so the same data always gives the same bytes.
+func Export(s StorageInterface) ([]byte, error) {
+	app, err := s.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data: %w", err)
+	}
+	for _, list := range app.TodoLists {
+		if err := s.LoadTasks(app, list.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	export := ExportFile{
+		Version:   ExportVersion,
+		TodoLists: make([]models.TodoList, len(app.TodoLists)),
+		Templates: app.Templates,
+	}
+	copy(export.TodoLists, app.TodoLists)
+
+	for i := range export.TodoLists {
+		list := &export.TodoLists[i]
+		list.CreatedAt = canonicalTime(list.CreatedAt)
+		list.UpdatedAt = canonicalTime(list.UpdatedAt)
+		if list.Tasks == nil {
+			list.Tasks = []models.Task{}
+		}
+
+		for j := range list.Tasks {
+			task := &list.Tasks[j]
+			task.CreatedAt = canonicalTime(task.CreatedAt)
+			task.UpdatedAt = canonicalTime(task.UpdatedAt)
+			if task.CompletedAt != nil {
+				completed := canonicalTime(*task.CompletedAt)
+				task.CompletedAt = &completed
+			}
+			for k := range task.Notes {
+				task.Notes[k].CreatedAt = canonicalTime(task.Notes[k].CreatedAt)
+			}
+			sort.SliceStable(task.Notes, func(a, b int) bool {
+				return createdBefore(task.Notes[a].CreatedAt, task.Notes[a].ID, task.Notes[b].CreatedAt, task.Notes[b].ID)
+			})
+		}
+		sort.SliceStable(list.Tasks, func(a, b int) bool {
+			return createdBefore(list.Tasks[a].CreatedAt, list.Tasks[a].ID, list.Tasks[b].CreatedAt, list.Tasks[b].ID)
+		})
+	}
+	sort.SliceStable(export.TodoLists, func(a, b int) bool {
+		return createdBefore(export.TodoLists[a].CreatedAt, export.TodoLists[a].ID, export.TodoLists[b].CreatedAt, export.TodoLists[b].ID)
+	})
+
+	for i := range export.Templates {
+		export.Templates[i].CreatedAt = canonicalTime(export.Templates[i].CreatedAt)
+	}
+	sort.SliceStable(export.Templates, func(a, b int) bool {
+		return createdBefore(export.Templates[a].CreatedAt, export.Templates[a].ID, export.Templates[b].CreatedAt, export.Templates[b].ID)
+	})
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal export: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// ParseExport reads an export file into an application ready to be merged,
+// with its titles and text normalized as if they had been entered
+func ParseExport(data []byte) (*models.Application, error) {
+	var export ExportFile
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("failed to parse export: %w", err)
+	}
+	if export.Version > ExportVersion {
+		return nil, fmt.Errorf("export version %d is newer than supported version %d", export.Version, ExportVersion)
+	}
+
+	incoming := &models.Application{
+		TodoLists: export.TodoLists,
+		Templates: export.Templates,
+	}
+	incoming.Normalize()
+	return incoming, nil
+}
+
+// canonicalTime drops the precision and zone that differ between memory and storage
+func canonicalTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
+
+// createdBefore orders records by creation time, then by ID for records created together
+func createdBefore(aCreated time.Time, aID string, bCreated time.Time, bID string) bool {
+	if !aCreated.Equal(bCreated) {
+		return aCreated.Before(bCreated)
+	}
+	return aID < bID
+}
+
+// mergePlan lists the changes needed to merge incoming data into existing data
+type mergePlan struct {
+	newLists     []models.TodoList // Complete with tasks and notes
+	updatedLists []models.TodoList // Name, description and color only
+	newTasks     []models.ListTask // Complete with notes
+	updatedTasks []models.ListTask
+	newNotes     []models.Note
+	newTemplates []models.Template
+}
+
+// planMerge compares incoming data against existing data, whose tasks must all be
+// loaded. Records are matched by ID: unknown lists, tasks, notes and templates are
+// added, a known list or task is replaced when the incoming copy was updated later
+// and differs, and nothing is ever deleted. An unknown list named like a list
+// there already is merged into that one instead, as is one named like a list
+// added before it, so an export from a machine that created its own "Work"
+// list does not leave two of them.
+func planMerge(existing, incoming *models.Application) mergePlan {
+	var plan mergePlan
+
+	lists := make(map[string]*models.TodoList)
+	named := make(map[string]*models.TodoList) // The first list with each name
+	addedNamed := make(map[string]int)         // Index in plan.newLists of the list added with each name
+	tasks := make(map[string]*models.Task)
+	taskLists := make(map[string]*models.TodoList)
+	notes := make(map[string]bool)
+	for i := range existing.TodoLists {
+		list := &existing.TodoLists[i]
+		lists[list.ID] = list
+		if _, taken := named[listNameKey(list.Name)]; !taken {
+			named[listNameKey(list.Name)] = list
+		}
+		for j := range list.Tasks {
+			tasks[list.Tasks[j].ID] = &list.Tasks[j]
+			taskLists[list.Tasks[j].ID] = list
+			for _, note := range list.Tasks[j].Notes {
+				notes[note.ID] = true
+			}
+		}
+	}
+
+	for _, incomingList := range incoming.TodoLists {
+		list, ok := lists[incomingList.ID]
+		if !ok {
+			if sameName, found := named[listNameKey(incomingList.Name)]; found {
+				// Its tasks join the list of the same name, whose details stay
+				plan.planTasks(sameName, incomingList.Tasks, tasks, taskLists, notes)
+				continue
+			}
+			if i, found := addedNamed[listNameKey(incomingList.Name)]; found {
+				for _, task := range incomingList.Tasks {
+					if tasks[task.ID] == nil {
+						plan.newLists[i].Tasks = append(plan.newLists[i].Tasks, importedTask(task))
+					}
+				}
+				continue
+			}
+
+			// Tasks that already exist elsewhere are not duplicated
+			newList := incomingList
+			newList.Summary = nil
+			newList.Tasks = nil
+			for _, task := range incomingList.Tasks {
+				if tasks[task.ID] == nil {
+					newList.Tasks = append(newList.Tasks, importedTask(task))
+				}
+			}
+			addedNamed[listNameKey(newList.Name)] = len(plan.newLists)
+			plan.newLists = append(plan.newLists, newList)
+			continue
+		}
+
+		if isNewer(incomingList.UpdatedAt, list.UpdatedAt) &&
+			(incomingList.Name != list.Name || incomingList.Description != list.Description || incomingList.Color != list.Color ||
+				incomingList.Group != list.Group) {
+			plan.updatedLists = append(plan.updatedLists, incomingList)
+		}
+		plan.planTasks(list, incomingList.Tasks, tasks, taskLists, notes)
+	}
+
+	templates := make(map[string]bool)
+	for _, template := range existing.Templates {
+		templates[template.ID] = true
+	}
+	for _, template := range incoming.Templates {
+		if !templates[template.ID] {
+			plan.newTemplates = append(plan.newTemplates, template)
+		}
+	}
+
+	return plan
+}
+
+// planTasks plans the incoming tasks of an existing list: unknown ones are
+// added to list, and known ones are updated where they already are
+func (plan *mergePlan) planTasks(list *models.TodoList, incomingTasks []models.Task, tasks map[string]*models.Task,
+	taskLists map[string]*models.TodoList, notes map[string]bool) {
+	for _, incomingTask := range incomingTasks {
+		task, ok := tasks[incomingTask.ID]
+		if !ok {
+			plan.newTasks = append(plan.newTasks, models.ListTask{ListID: list.ID, ListName: list.Name, Task: importedTask(incomingTask)})
+			continue
+		}
+
+		// Tasks are never moved between lists, so edits apply where the task already is
+		if isNewer(incomingTask.UpdatedAt, task.UpdatedAt) && !sameTaskContent(*task, incomingTask) {
+			// Where a task was created never changes
+			incomingTask.Source = task.Source
+			owner := taskLists[task.ID]
+			plan.updatedTasks = append(plan.updatedTasks, models.ListTask{ListID: owner.ID, ListName: owner.Name, Task: incomingTask})
+		}
+		for _, note := range incomingTask.Notes {
+			if !notes[note.ID] {
+				note.TaskID = task.ID
+				plan.newNotes = append(plan.newNotes, note)
+			}
+		}
+	}
+}
+
+// importedTask marks an incoming task that has no source of its own as imported.
+// Tasks from another machine's export keep the source recorded there.
+func importedTask(task models.Task) models.Task {
+	if task.Source == "" {
+		task.Source = models.SourceImport
+	}
+	return task
+}
+
+// result counts the changes in the plan
+func (p mergePlan) result() MergeResult {
+	result := MergeResult{
+		ListsAdded:     len(p.newLists),
+		ListsUpdated:   len(p.updatedLists),
+		TasksAdded:     len(p.newTasks),
+		TasksUpdated:   len(p.updatedTasks),
+		NotesAdded:     len(p.newNotes),
+		TemplatesAdded: len(p.newTemplates),
+	}
+	for _, list := range p.newLists {
+		result.TasksAdded += len(list.Tasks)
+		for _, task := range list.Tasks {
+			result.NotesAdded += len(task.Notes)
+		}
+	}
+	for _, entry := range p.newTasks {
+		result.NotesAdded += len(entry.Task.Notes)
+	}
+	return result
+}
+
+// isNewer compares timestamps at the precision kept by storage
+func isNewer(incoming, existing time.Time) bool {
+	return canonicalTime(incoming).After(canonicalTime(existing))
+}
+
+// sameTaskContent reports whether two copies of a task differ only in timestamps and notes
+func sameTaskContent(a, b models.Task) bool {
+	return len(taskChanges(a, b)) == 0
+}
+
+// taskChanges names the fields two copies of a task differ in, leaving out
+// timestamps and notes
+func taskChanges(a, b models.Task) []string {
+	sameDeadline := (a.Deadline == nil && b.Deadline == nil) ||
+		(a.Deadline != nil && b.Deadline != nil && a.Deadline.Equal(*b.Deadline))
+
+	var changes []string
+	for _, field := range []struct {
+		name string
+		same bool
+	}{
+		{"title", a.Title == b.Title},
+		{"description", a.Description == b.Description},
+		{"completed", a.Completed == b.Completed},
+		{"priority", a.Priority == b.Priority},
+		{"deadline", sameDeadline},
+		{"label", a.Label == b.Label},
+		{"snoozes", a.SnoozeCount == b.SnoozeCount},
+		{"estimate", a.Estimate == b.Estimate},
+		{"time spent", a.Spent == b.Spent},
+		{"link", a.Link == b.Link},
+		{"reminder", sameOffset(a.ReminderOffset, b.ReminderOffset)},
+		{"progress", a.Progress == b.Progress},
+		{"star", a.Starred == b.Starred},
+	} {
+		if !field.same {
+			changes = append(changes, field.name)
+		}
+	}
+	return changes
+}
+
+// sameOffset reports whether two reminder offsets are equal, nil meaning the global setting
+func sameOffset(a, b *time.Duration) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+// nullTimestamp formats an optional time for a DATETIME column, NULL when unset
+func nullTimestamp(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: t.UTC().Format(timestampLayout), Valid: true}
+}
+
+// insertTask writes a task keeping its own ID and timestamps, in the trash if it
+// was deleted. A task already stored keeps its place in the list; a new one is
+// added at the end.
+func insertTask(tx dbConn, listID string, task models.Task) error {
+	var deadline sql.NullString
+	if task.Deadline != nil {
+		deadline = sql.NullString{String: task.Deadline.Format(timestampLayout), Valid: true}
+	}
+
+	_, err := tx.Exec(`
+		INSERT OR REPLACE INTO tasks
+		(id, list_id, title, description, completed, priority, deadline, label, snooze_count, estimate, spent, link, reminder_offset, progress, starred, source,
+			deleted_at, completed_at, created_at, updated_at, position)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+			COALESCE((SELECT position FROM tasks WHERE id = ? AND list_id = ?), `+nextTaskPosition+`))
+	`, task.ID, listID, task.Title, task.Description, task.Completed,
+		int(task.Priority), deadline, task.Label, task.SnoozeCount,
+		durationSeconds(task.Estimate), durationSeconds(task.Spent), task.Link, offsetSeconds(task.ReminderOffset),
+		models.ClampProgress(task.Progress), task.Starred, task.CreationSource(), nullTimestamp(task.DeletedAt), nullTimestamp(task.CompletedAt), task.CreatedAt.UTC().Format(timestampLayout),
+		task.UpdatedAt.UTC().Format(timestampLayout), task.ID, listID, listID)
+	if err != nil {
+		return fmt.Errorf("failed to insert task %s: %w", task.Title, err)
+	}
+
+	for _, note := range task.Notes {
+		note.TaskID = task.ID
+		if err := insertNote(tx, note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertNote writes a note keeping its own ID and timestamp
+func insertNote(tx dbConn, note models.Note) error {
+	_, err := tx.Exec(`
+		INSERT OR REPLACE INTO task_notes (id, task_id, body, created_at)
+		VALUES (?, ?, ?, ?)
+	`, note.ID, note.TaskID, note.Body, note.CreatedAt.UTC().Format(timestampLayout))
+	if err != nil {
+		return fmt.Errorf("failed to insert note: %w", err)
+	}
+	return nil
+}
+
+// insertTemplate writes a template and its tasks keeping the template's ID
+func insertTemplate(tx dbConn, template models.Template) error {
+	_, err := tx.Exec("INSERT OR REPLACE INTO templates (id, name, created_at) VALUES (?, ?, ?)",
+		template.ID, template.Name, template.CreatedAt.UTC().Format(timestampLayout))
+	if err != nil {
+		return fmt.Errorf("failed to insert template %s: %w", template.Name, err)
+	}
+
+	for i, task := range template.Tasks {
+		var offset *int64
+		if task.DeadlineOffset != nil {
+			seconds := int64(*task.DeadlineOffset / time.Second)
+			offset = &seconds
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO template_tasks (template_id, position, title, description, priority, label, deadline_offset)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, template.ID, i, task.Title, task.Description, int(task.Priority), task.Label, offset)
+		if err != nil {
+			return fmt.Errorf("failed to insert template task %s: %w", task.Title, err)
+		}
+	}
+	return nil
+}
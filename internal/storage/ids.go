@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// idSequenceMax is the largest sequence number that fits in the 12 bits a
+// UUIDv7 leaves between the timestamp and the random part
+const idSequenceMax = 1<<12 - 1
+
+// idGenerator hands out UUIDv7 IDs: a millisecond timestamp, a sequence number
+// and random bits. The sequence counts IDs made within the same millisecond,
+// so IDs never collide and sort in the order they were made even when the
+// clock is coarse (about 16ms on Windows) or briefly goes backwards.
+type idGenerator struct {
+	mu   sync.Mutex
+	now  func() time.Time
+	last int64 // Timestamp of the previous ID, in milliseconds
+	seq  uint64
+}
+
+// ids generates the IDs of every new list, task, note and template
+var ids = &idGenerator{now: time.Now}
+
+// next returns a new ID that sorts after every ID returned before it
+func (g *idGenerator) next() string {
+	g.mu.Lock()
+	ms := g.now().UnixMilli()
+	if ms > g.last {
+		g.last, g.seq = ms, 0
+	} else if g.seq < idSequenceMax {
+		g.seq++
+	} else {
+		// The sequence ran out within one tick: borrow the next millisecond
+		g.last, g.seq = g.last+1, 0
+	}
+	ms, seq := g.last, g.seq
+	g.mu.Unlock()
+
+	random := rand.Uint64()
+	return fmt.Sprintf("%08x-%04x-7%03x-%04x-%012x",
+		uint64(ms)>>16, uint64(ms)&0xffff, seq,
+		0x8000|(random>>48)&0x3fff, random&0xffffffffffff)
+}
+
+// idQueue hands out the IDs chosen for an operation before it ran, so that the
+// journal can log them first and a replay creates the same records. Once they
+// run out, and without a queue, new IDs are generated.
+type idQueue struct {
+	ids []string
+}
+
+// next returns the next ID chosen in advance, or a new one
+func (q *idQueue) next() string {
+	if q == nil || len(q.ids) == 0 {
+		return ids.next()
+	}
+	id := q.ids[0]
+	q.ids = q.ids[1:]
+	return id
+}
+
+// newIDs returns n new IDs, for an operation to create its records with
+func newIDs(n int) []string {
+	generated := make([]string, n)
+	for i := range generated {
+		generated[i] = ids.next()
+	}
+	return generated
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// coarseClock ticks like the Windows clock, in steps of about 16ms, every
+// perTick readings, and goes back a tick once after backAt readings
+type coarseClock struct {
+	at             time.Time
+	perTick, reads int
+	backAt         int
+}
+
+func (c *coarseClock) now() time.Time {
+	c.reads++
+	switch {
+	case c.reads == c.backAt:
+		c.at = c.at.Add(-16 * time.Millisecond)
+	case c.reads%c.perTick == 0:
+		c.at = c.at.Add(16 * time.Millisecond)
+	}
+	return c.at
+}
+
+// checkIDs fails unless ids are distinct UUIDv7s in the order they were made
+func checkIDs(t *testing.T, generated []string) {
+	t.Helper()
+	seen := make(map[string]bool, len(generated))
+	for i, id := range generated {
+		if seen[id] {
+			t.Fatalf("ID %d, %s, was made before", i, id)
+		}
+		seen[id] = true
+		if len(id) != 36 || id[14] != '7' || !strings.ContainsRune("89ab", rune(id[19])) {
+			t.Fatalf("ID %d, %s, is not a UUIDv7", i, id)
+		}
+		if i > 0 && id <= generated[i-1] {
+			t.Fatalf("ID %d, %s, sorts before or with the one made before it, %s", i, id, generated[i-1])
+		}
+	}
+}
+
+func TestIDsOnCoarseClock(t *testing.T) {
+	tests := []struct {
+		name  string
+		clock *coarseClock
+	}{
+		{"ticking every 100 IDs", &coarseClock{at: time.Now(), perTick: 100, backAt: 5000}},
+		// More IDs per tick than the 12 bits of the sequence hold
+		{"ticking every 10000 IDs", &coarseClock{at: time.Now(), perTick: 10000, backAt: -1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := &idGenerator{now: test.clock.now}
+			generated := make([]string, 10000)
+			for i := range generated {
+				generated[i] = g.next()
+			}
+			checkIDs(t, generated)
+		})
+	}
+}
+
+func TestTasksMadeWithinOneTickKeepTheirOrder(t *testing.T) {
+	clock := &coarseClock{at: time.Now(), perTick: 1000, backAt: -1}
+	generator := ids
+	ids = &idGenerator{now: clock.now}
+	t.Cleanup(func() { ids = generator })
+
+	const count = 2000
+	forEachBackend(t, func(t *testing.T, store StorageInterface, app *models.Application) {
+		listID := mustCreateList(t, store, app, "Import")
+		var want []string
+		err := store.WithTransaction(app, func(tx StorageInterface) error {
+			for range count {
+				task, err := tx.CreateTask(app, listID, "Same time", "", models.Medium, nil, "", models.SourceImport)
+				if err != nil {
+					return err
+				}
+				findList(app, listID).PutTask(task)
+				want = append(want, task.ID)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("creating %d tasks: %v", count, err)
+		}
+		checkIDs(t, want)
+
+		for range 2 {
+			app = mustReload(t, store, app)
+			if err := store.LoadTasks(app, listID); err != nil {
+				t.Fatalf("LoadTasks: %v", err)
+			}
+			var got []string
+			for _, task := range findList(app, listID).Tasks {
+				got = append(got, task.ID)
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("tasks after reloading are out of order")
+			}
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// Info is what lazytodo --info reports about the data. Scripts read it as
+// JSON, so its field names stay as they are.
+type Info struct {
+	Backend       string     `json:"backend"` // "database" or "json"
+	Path          string     `json:"path"`
+	Profile       string     `json:"profile"`
+	ReadOnly      bool       `json:"read_only"`
+	Encrypted     bool       `json:"encrypted"`
+	SizeBytes     int64      `json:"size_bytes"`
+	SchemaVersion int        `json:"schema_version"` // 0 for the JSON file
+	ModifiedAt    *time.Time `json:"modified_at"`    // When the data file was last written; null if unknown
+	Lists         []ListInfo `json:"lists"`
+	Totals        TaskCounts `json:"totals"`
+
+	// Settings are shown by the text report only
+	Settings models.Settings `json:"-"`
+}
+
+// ListInfo holds the task counts of one list
+type ListInfo struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	TaskCounts
+}
+
+// TaskCounts counts the tasks of a list, or of all of them, leaving out the trash
+type TaskCounts struct {
+	Total       int `json:"total"`
+	Completed   int `json:"completed"`
+	Pending     int `json:"pending"`
+	Overdue     int `json:"overdue"`
+	DueThisWeek int `json:"due_this_week"` // Open and due from now to the end of Sunday
+
+	EstimateSeconds  int64 `json:"estimate_seconds"`
+	RemainingSeconds int64 `json:"remaining_seconds"` // Estimate of the open tasks
+	SpentSeconds     int64 `json:"spent_seconds"`
+}
+
+// add counts c into the totals of t
+func (t *TaskCounts) add(c TaskCounts) {
+	t.Total += c.Total
+	t.Completed += c.Completed
+	t.Pending += c.Pending
+	t.Overdue += c.Overdue
+	t.DueThisWeek += c.DueThisWeek
+	t.EstimateSeconds += c.EstimateSeconds
+	t.RemainingSeconds += c.RemainingSeconds
+	t.SpentSeconds += c.SpentSeconds
+}
+
+// endOfWeek returns when the week of now ends, at midnight after Sunday, in
+// the deadline representation
+func endOfWeek(now time.Time) time.Time {
+	start, _ := models.CalendarDay(models.WallClock(now))
+	daysLeft := (7 - int(start.Weekday())) % 7 // Sunday counts as the last day
+	return start.AddDate(0, 0, daysLeft+1)
+}
+
+// CollectInfo gathers the --info report of store at now. The database counts
+// tasks with one grouped query instead of loading them; the JSON file has
+// nothing to query, so it is loaded.
+func CollectInfo(store StorageInterface, now time.Time) (Info, error) {
+	info := Info{
+		Path:     store.GetDataPath(),
+		Profile:  Profile(),
+		ReadOnly: store.IsReadOnly(),
+		Lists:    []ListInfo{},
+	}
+
+	var err error
+	if db := unwrapDatabase(store); db != nil {
+		info.Backend = "database"
+		err = db.collectInfo(&info, now)
+	} else {
+		info.Backend = "json"
+		err = collectJSONInfo(store, &info, now)
+	}
+	if err != nil {
+		return info, err
+	}
+
+	if stat, err := os.Stat(info.Path); err == nil {
+		info.SizeBytes = stat.Size()
+		modified := stat.ModTime()
+		// Writes to the database land in its write-ahead log first
+		if wal, err := os.Stat(info.Path + "-wal"); err == nil && wal.ModTime().After(modified) {
+			modified = wal.ModTime()
+		}
+		info.ModifiedAt = &modified
+	}
+
+	for _, list := range info.Lists {
+		info.Totals.add(list.TaskCounts)
+	}
+	return info, nil
+}
+
+// collectInfo fills in info from the database
+func (s *DatabaseStorage) collectInfo(info *Info, now time.Time) error {
+	if s.encryptionKey != nil {
+		info.Encrypted = true
+		info.Path = filepath.Join(filepath.Dir(s.dataPath), EncryptedDatabaseName)
+	}
+
+	settings, err := s.loadSettings()
+	if err != nil {
+		return fmt.Errorf("failed to load settings: %w", err)
+	}
+	info.Settings = settings
+
+	if err := s.conn().QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&info.SchemaVersion); err != nil {
+		return fmt.Errorf("failed to read the schema version: %w", err)
+	}
+
+	// Overdue as CountOverdue counts it, so the two never disagree
+	rows, err := s.conn().Query(`
+		SELECT l.id, l.name,
+			COUNT(t.id), COALESCE(SUM(t.completed), 0),
+			COALESCE(SUM(t.completed = 0 AND t.deadline < ?), 0),
+			COALESCE(SUM(t.completed = 0 AND t.deadline >= ? AND t.deadline < ?), 0),
+			COALESCE(SUM(t.estimate), 0), COALESCE(SUM(CASE WHEN t.completed = 0 THEN t.estimate ELSE 0 END), 0),
+			COALESCE(SUM(t.spent), 0)
+		FROM todo_lists l
+		LEFT JOIN tasks t ON t.list_id = l.id AND t.deleted_at IS NULL
+		GROUP BY l.id
+		ORDER BY l.pinned DESC, l.sort_order ASC, l.created_at ASC, l.id ASC
+	`, deadlineArg(now), deadlineArg(now), deadlineArg(endOfWeek(now)))
+	if err != nil {
+		return fmt.Errorf("failed to count tasks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var list ListInfo
+		c := &list.TaskCounts
+		if err := rows.Scan(&list.ID, &list.Name, &c.Total, &c.Completed, &c.Overdue, &c.DueThisWeek,
+			&c.EstimateSeconds, &c.RemainingSeconds, &c.SpentSeconds); err != nil {
+			s.skipRow("list", err)
+			continue
+		}
+		c.Pending = c.Total - c.Completed
+		info.Lists = append(info.Lists, list)
+	}
+	return rows.Err()
+}
+
+// collectJSONInfo fills in info from the JSON file, loading it
+func collectJSONInfo(store StorageInterface, info *Info, now time.Time) error {
+	app, err := store.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load data: %w", err)
+	}
+	info.Settings = app.Settings
+
+	weekEnd := endOfWeek(now)
+	for _, list := range app.TodoLists {
+		entry := ListInfo{ID: list.ID, Name: list.Name}
+		c := &entry.TaskCounts
+		for _, task := range list.Tasks {
+			c.Total++
+			c.EstimateSeconds += int64(task.Estimate / time.Second)
+			c.SpentSeconds += int64(task.Spent / time.Second)
+			if task.Completed {
+				c.Completed++
+				continue
+			}
+			c.RemainingSeconds += int64(task.Estimate / time.Second)
+			switch {
+			case task.Deadline == nil:
+			case task.IsOverdueAt(now):
+				c.Overdue++
+			case task.Deadline.Before(weekEnd):
+				c.DueThisWeek++
+			}
+		}
+		c.Pending = c.Total - c.Completed
+		info.Lists = append(info.Lists, entry)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares got with the golden file name in testdata, or rewrites
+// the file with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("writing %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s (run with -update to write it): %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs; run with -update if the change is intended\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestInfoJSON(t *testing.T) {
+	// A Wednesday, so the week has deadlines left in it and after it
+	now := time.Date(2026, time.March, 11, 12, 0, 0, 0, time.UTC)
+	forEachBackend(t, func(t *testing.T, store StorageInterface, app *models.Application) {
+		work := mustCreateList(t, store, app, "Work")
+		mustCreateList(t, store, app, "Empty")
+		yesterday, friday, nextWeek := now.AddDate(0, 0, -1), now.AddDate(0, 0, 2), now.AddDate(0, 0, 7)
+		mustCreateTask(t, store, app, work, "Overdue", &yesterday)
+		mustCreateTask(t, store, app, work, "Due this week", &friday)
+		mustCreateTask(t, store, app, work, "Due next week", &nextWeek)
+		done := mustCreateTask(t, store, app, work, "Done", &yesterday)
+		timed, err := store.UpdateTaskTime(app, work, done.ID, 2*time.Hour, 90*time.Minute)
+		if err != nil {
+			t.Fatalf("UpdateTaskTime: %v", err)
+		}
+		findList(app, work).PutTask(timed)
+		mustToggle(t, store, app, work, done.ID)
+		estimated := mustCreateTask(t, store, app, work, "Estimated", nil)
+		if timed, err = store.UpdateTaskTime(app, work, estimated.ID, 30*time.Minute, 0); err != nil {
+			t.Fatalf("UpdateTaskTime: %v", err)
+		}
+		findList(app, work).PutTask(timed)
+		if err := store.Save(app); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		info, err := CollectInfo(store, now)
+		if err != nil {
+			t.Fatalf("CollectInfo: %v", err)
+		}
+		if info.Path == "" || info.SizeBytes == 0 || info.ModifiedAt == nil {
+			t.Errorf("path %q, size %d, modified at %v: want the data file's", info.Path, info.SizeBytes, info.ModifiedAt)
+		}
+
+		// What differs from run to run is replaced, leaving the shape and the
+		// counts. A new migration changes the database's schema_version.
+		modified := now
+		info.Path, info.SizeBytes, info.ModifiedAt = "PATH", 1024, &modified
+		for i := range info.Lists {
+			info.Lists[i].ID = fmt.Sprintf("LIST-%d", i+1)
+		}
+		var out bytes.Buffer
+		encoder := json.NewEncoder(&out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(info); err != nil {
+			t.Fatalf("encoding: %v", err)
+		}
+		checkGolden(t, "info-"+info.Backend+".json", out.Bytes())
+	})
+}
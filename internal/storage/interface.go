@@ -1,11 +1,41 @@
 package storage
 
 import (
+	"errors"
+	"io"
+	"os"
 	"time"
 
 	"github.com/DhirajZope/lazytodo/internal/models"
 )
 
+// ErrReadOnly is returned by mutating operations when storage was opened read-only
+var ErrReadOnly = errors.New("storage is read-only")
+
+// Options controls how a storage backend is opened
+type Options struct {
+	// ReadOnly opens the data file without write access; all mutations return ErrReadOnly
+	ReadOnly bool
+
+	// Passphrase unlocks an encrypted database; it is only ever held in memory
+	Passphrase string
+
+	// Output receives progress and warning messages; defaults to os.Stdout
+	Output io.Writer
+
+	// MigrateJSON agrees to migrating a v1.x JSON file that has no database
+	// yet; without it NewWithMigration returns ErrMigrationPending
+	MigrateJSON bool
+}
+
+// output returns the writer for progress and warning messages
+func (o Options) output() io.Writer {
+	if o.Output != nil {
+		return o.Output
+	}
+	return os.Stdout
+}
+
 // StorageInterface defines the interface that all storage implementations must satisfy
 type StorageInterface interface {
 	// Load loads the application data
@@ -17,17 +47,135 @@ type StorageInterface interface {
 	// GetDataPath returns the path to the data storage
 	GetDataPath() string
 
+	// IsReadOnly reports whether mutations are disabled
+	IsReadOnly() bool
+
+	// DefersWrites reports whether changes reach the disk only when Save is
+	// called; backends that write each change as it is made return false
+	DefersWrites() bool
+
+	// LoadTasks makes sure a list's tasks are loaded into app; backends that
+	// load lazily fetch them on first use and cache them on the list
+	LoadTasks(app *models.Application, listID string) error
+
+	// DueTasks returns incomplete tasks across all lists whose reminder is due
+	// at now: the deadline is ahead but within the task's reminder offset, or
+	// within defaultLead for tasks without one
+	DueTasks(app *models.Application, now time.Time, defaultLead time.Duration) ([]models.Task, error)
+
+	// CountOverdue returns the number of overdue tasks across all lists
+	CountOverdue(app *models.Application) (int, error)
+
+	// OverdueTasks returns the overdue tasks across all lists, oldest deadline first
+	OverdueTasks(app *models.Application) ([]models.ListTask, error)
+
+	// TasksDueBetween returns the incomplete tasks across all lists due from
+	// from up to, but not including, to; earliest deadline first
+	TasksDueBetween(app *models.Application, from, to time.Time) ([]models.ListTask, error)
+
+	// StarredTasks returns the starred tasks across all lists, open ones first,
+	// then by deadline
+	StarredTasks(app *models.Application) ([]models.ListTask, error)
+
+	// ClearCompletedStars unstars the tasks across all lists completed before
+	// the given time and returns them as stored, so callers can put them in
+	// their in-memory lists
+	ClearCompletedStars(app *models.Application, before time.Time) ([]models.ListTask, error)
+
+	// TrashCompleted moves the tasks across all lists completed before the
+	// given time to the trash and returns them as they were, so callers can
+	// drop them from their in-memory lists with TodoList.DropTask
+	TrashCompleted(app *models.Application, before time.Time) ([]models.ListTask, error)
+
+	// WelcomeDigest returns the tasks that became overdue between since and
+	// now, those due during the rest of now's day, and those completed since
+	WelcomeDigest(app *models.Application, since, now time.Time) (models.Digest, error)
+
+	// RecentActivity returns up to limit of the most recent changes across all lists, newest first
+	RecentActivity(app *models.Application, limit int) ([]models.Activity, error)
+
+	// ActivityLog returns up to limit of the changes recorded since the given
+	// time, newest first. Only the database keeps the log; the JSON backend
+	// returns ErrNoActivityLog.
+	ActivityLog(app *models.Application, since time.Time, limit int) ([]models.Change, error)
+
+	// CompletionTimes returns when each completed task was completed, across all lists and the trash
+	CompletionTimes(app *models.Application) ([]time.Time, error)
+
+	// CompletionCounts returns how many tasks were completed on each day from
+	// from up to, but not including, to, as models.CompletionCounts counts them
+	CompletionCounts(app *models.Application, from, to time.Time) (map[string]int, error)
+
 	// Todo List operations
-	CreateTodoList(app *models.Application, name, description string) string
-	UpdateTodoList(app *models.Application, listID, name, description string) error
+	CreateTodoList(app *models.Application, name, description, color string) (string, error)
+	UpdateTodoList(app *models.Application, listID, name, description, color string) error
 	DeleteTodoList(app *models.Application, listID string) error
+	ReorderList(app *models.Application, listID string, offset int) error
+	SetListGroup(app *models.Application, listID, group string) error
+	SetListPinned(app *models.Application, listID string, pinned bool) error
+	SetListGrouping(app *models.Application, listID, grouping string) error
+
+	// Template operations
+	CreateTemplate(app *models.Application, name string, tasks []models.TemplateTask) (string, error)
+	RenameTemplate(app *models.Application, templateID, name string) error
+	DeleteTemplate(app *models.Application, templateID string) error
+	CreateListFromTemplate(app *models.Application, templateID, name, description, color string) (string, error)
+
+	// Task operations return the task as it was stored. The backend is the
+	// authority on it: the JSON file backend changes app, which is its data,
+	// while the database leaves app alone, so callers keep their in-memory
+	// lists current with TodoList.PutTask and TodoList.RemoveTask.
+	// CreateTask records where the task came from in source, one of the
+	// models.Source constants.
+	CreateTask(app *models.Application, listID, title, description string, priority models.Priority, deadline *time.Time, label, source string) (models.Task, error)
+	UpdateTask(app *models.Application, listID, taskID, title, description string, priority models.Priority, deadline *time.Time, label string) (models.Task, error)
+	SnoozeTask(app *models.Application, listID, taskID string, deadline time.Time) (models.Task, error)
+	UpdateTaskTime(app *models.Application, listID, taskID string, estimate, spent time.Duration) (models.Task, error)
+	SetTaskLink(app *models.Application, listID, taskID, link string) (models.Task, error)
+	SetTaskReminder(app *models.Application, listID, taskID string, offset *time.Duration) (models.Task, error)
+	SetTaskProgress(app *models.Application, listID, taskID string, progress int) (models.Task, error)
+	SetTaskStarred(app *models.Application, listID, taskID string, starred bool) (models.Task, error)
 
-	// Task operations
-	CreateTask(app *models.Application, listID, title, description string, priority models.Priority, deadline *time.Time) (string, error)
-	UpdateTask(app *models.Application, listID, taskID, title, description string, priority models.Priority, deadline *time.Time) error
-	ToggleTask(app *models.Application, listID, taskID string) error
+	// DuplicateTask adds an open copy of a task, as made by Task.Duplicate,
+	// right after the original; callers add it with TodoList.PutTaskAfter
+	DuplicateTask(app *models.Application, listID, taskID string) (models.Task, error)
+
+	// SetTasksPriority and SetTasksDeadline change several tasks of a list at
+	// once, changing none of them when one is missing, and return them as stored
+	SetTasksPriority(app *models.Application, listID string, taskIDs []string, priority models.Priority) ([]models.Task, error)
+	SetTasksDeadline(app *models.Application, listID string, taskIDs []string, deadline *time.Time) ([]models.Task, error)
+
+	// ShiftDeadlines moves the deadline of every incomplete task in a list by
+	// delta and returns how many tasks were moved
+	ShiftDeadlines(app *models.Application, listID string, delta time.Duration) (int, error)
+	ToggleTask(app *models.Application, listID, taskID string) (models.Task, error)
+
+	// DeleteTask moves a task to the trash, which keeps it out of every other query
 	DeleteTask(app *models.Application, listID, taskID string) error
 
+	// TrashedTasks returns the tasks in the trash across all lists, most recently deleted first
+	TrashedTasks(app *models.Application) ([]models.ListTask, error)
+	RestoreTask(app *models.Application, listID, taskID string) (models.Task, error)
+
+	// PurgeTrash permanently deletes the tasks moved to the trash before the
+	// given time and returns how many there were
+	PurgeTrash(app *models.Application, before time.Time) (int, error)
+
+	// Task note operations
+	AddNote(app *models.Application, listID, taskID, body string) (string, error)
+	DeleteNote(app *models.Application, listID, taskID, noteID string) error
+	ListNotes(app *models.Application, listID, taskID string) ([]models.Note, error)
+
+	// Merge adds lists, tasks, notes and templates from incoming that app does not
+	// have yet and applies newer edits of the ones it does; nothing is deleted
+	Merge(app *models.Application, incoming *models.Application) (MergeResult, error)
+
+	// WithTransaction runs fn with a storage to make several changes through
+	// at once: they all take effect when fn returns nil, and none of them
+	// when it returns an error, which WithTransaction returns after putting
+	// app back as it was. Only tx is used inside fn.
+	WithTransaction(app *models.Application, fn func(tx StorageInterface) error) error
+
 	// Close closes any resources (for database connections)
 	Close() error
 }
@@ -0,0 +1,908 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// JournalName is the operations log kept next to the data file
+const JournalName = "journal.jsonl"
+
+// Journal layers crash safety over a storage backend. Every mutation is
+// appended to a JSON Lines log and synced before it is applied, followed by a
+// line recording its outcome. A successful save cuts the log back and a clean
+// shutdown removes it, so a log found at startup belongs to a session that did
+// not shut down cleanly; OpenJournal replays what it did not persist.
+type Journal struct {
+	StorageInterface
+	*journalLog
+
+	// txSeqs collects the sequence numbers logged through the Journal that
+	// WithTransaction hands its function; nil outside a transaction
+	txSeqs *[]int
+}
+
+// journalLog is the log a Journal and the Journals of its transactions share
+type journalLog struct {
+	path string
+	file *os.File
+
+	// Backends that persist each operation as it is applied only need the
+	// operations that never completed replayed; others need everything since
+	// the last save
+	writeThrough bool
+
+	mu      sync.Mutex
+	seq     int
+	pending []journalLine
+	app     *models.Application // Saved on Close when changes are still pending
+}
+
+// journalLine is a log line that is not yet covered by a save
+type journalLine struct {
+	seq  int
+	data []byte
+}
+
+// journalEntry is one line of the log: an operation with its arguments, or
+// the outcome of the operation with the same sequence number
+type journalEntry struct {
+	Seq  int        `json:"seq"`
+	Op   string     `json:"op,omitempty"`
+	Time *time.Time `json:"time,omitempty"`
+
+	// Outcome; ID is the ID an operation created
+	Done   bool   `json:"done,omitempty"`
+	Failed bool   `json:"failed,omitempty"`
+	ID     string `json:"id,omitempty"`
+
+	// IDs are the IDs of the records an operation creates, chosen before it
+	// runs so that a replay creates the same ones or finds them persisted
+	IDs []string `json:"ids,omitempty"`
+
+	ListID      string                `json:"list_id,omitempty"`
+	TaskID      string                `json:"task_id,omitempty"`
+	TaskIDs     []string              `json:"task_ids,omitempty"` // Tasks of a bulk change
+	TemplateID  string                `json:"template_id,omitempty"`
+	NoteID      string                `json:"note_id,omitempty"`
+	Name        string                `json:"name,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Color       string                `json:"color,omitempty"`
+	Group       string                `json:"group,omitempty"`
+	Grouping    string                `json:"grouping,omitempty"`
+	Pinned      bool                  `json:"pinned,omitempty"`
+	Title       string                `json:"title,omitempty"`
+	Priority    models.Priority       `json:"priority,omitempty"`
+	Deadline    *time.Time            `json:"deadline,omitempty"`
+	Label       string                `json:"label,omitempty"`
+	Link        string                `json:"link,omitempty"`
+	Reminder    *time.Duration        `json:"reminder,omitempty"`
+	Progress    int                   `json:"progress,omitempty"`
+	Starred     bool                  `json:"starred,omitempty"`
+	Source      string                `json:"source,omitempty"`
+	Before      *time.Time            `json:"before,omitempty"` // Cutoff of a trash purge or star clearing
+	Body        string                `json:"body,omitempty"`
+	Offset      int                   `json:"offset,omitempty"`
+	Position    *int                  `json:"position,omitempty"` // Where a reordered list ends up
+	Delta       time.Duration         `json:"delta,omitempty"`
+	Shifts      []deadlineShift       `json:"shifts,omitempty"` // What shifting deadlines changes
+	Estimate    time.Duration         `json:"estimate,omitempty"`
+	Spent       time.Duration         `json:"spent,omitempty"`
+	Completed   bool                  `json:"completed,omitempty"` // State a toggle leads to
+	Tasks       []models.TemplateTask `json:"tasks,omitempty"`
+	Incoming    *models.Application   `json:"incoming,omitempty"`
+}
+
+// deadlineShift is the change shifting deadlines makes to one task, logged as
+// absolute times so that a replay cannot shift a deadline twice
+type deadlineShift struct {
+	TaskID string    `json:"task_id"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+}
+
+// idAssigner is a backend that can run an operation creating its records with
+// IDs chosen in advance
+type idAssigner interface {
+	withIDs(ids []string) StorageInterface
+}
+
+// errAlreadyApplied is returned by apply for an operation the backend had
+// persisted before its outcome was logged
+var errAlreadyApplied = errors.New("operation already applied")
+
+// OpenJournal wraps s in a Journal, first replaying into app whatever the log
+// left by an unclean shutdown holds, and returns how many operations it
+// replayed. Read-only and encrypted storage are returned unwrapped: nothing
+// can change in the former, and the log would keep plaintext next to the latter.
+func OpenJournal(s StorageInterface, app *models.Application) (StorageInterface, int, error) {
+	if s.IsReadOnly() {
+		return s, 0, nil
+	}
+	writeThrough := true
+	switch backend := s.(type) {
+	case *DatabaseStorage:
+		if backend.encryptionKey != nil {
+			return s, 0, nil
+		}
+	case *Storage:
+		writeThrough = false
+	}
+
+	j := &Journal{
+		StorageInterface: s,
+		journalLog: &journalLog{
+			path:         filepath.Join(filepath.Dir(s.GetDataPath()), JournalName),
+			writeThrough: writeThrough,
+		},
+	}
+
+	replayed, err := j.replay(app)
+	if err != nil {
+		return nil, 0, err
+	}
+	if replayed > 0 {
+		if err := s.Save(app); err != nil {
+			return nil, 0, fmt.Errorf("failed to save replayed journal: %w", err)
+		}
+	}
+
+	j.file, err = os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open journal: %w", err)
+	}
+	return j, replayed, nil
+}
+
+// replay applies the operations of a left over log to app
+func (j *Journal) replay(app *models.Application) (int, error) {
+	data, err := os.ReadFile(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	var ops []journalEntry
+	outcomes := make(map[int]journalEntry)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			break // A line cut short by the crash ends the log
+		}
+		if entry.Op != "" {
+			ops = append(ops, entry)
+		} else {
+			outcomes[entry.Seq] = entry
+		}
+	}
+
+	// Operations replayed against a backend that did not persist them create
+	// new IDs, which later operations are translated to
+	ids := make(map[string]string)
+	translate := func(id string) string {
+		if newID, ok := ids[id]; ok {
+			return newID
+		}
+		return id
+	}
+
+	replayed := 0
+	for _, entry := range ops {
+		outcome, finished := outcomes[entry.Seq]
+		if outcome.Failed || (finished && j.writeThrough) {
+			continue
+		}
+
+		entry.ListID = translate(entry.ListID)
+		entry.TaskID = translate(entry.TaskID)
+		for i := range entry.TaskIDs {
+			entry.TaskIDs[i] = translate(entry.TaskIDs[i])
+		}
+		entry.TemplateID = translate(entry.TemplateID)
+		entry.NoteID = translate(entry.NoteID)
+
+		id, err := j.apply(app, entry)
+		if err != nil && !errors.Is(err, errAlreadyApplied) {
+			continue // The data it referred to is gone; nothing to recover
+		}
+		if id != "" && outcome.ID != "" {
+			ids[outcome.ID] = id
+		}
+		if err == nil {
+			replayed++
+		}
+	}
+	return replayed, nil
+}
+
+// putTask keeps app current with the task a replayed operation returned, as
+// the database backend does not change app itself, and passes on its ID
+func putTask(app *models.Application, listID string, task models.Task, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	if list := findList(app, listID); list != nil {
+		list.PutTask(task)
+	}
+	return task.ID, nil
+}
+
+// putTasks is putTask for the tasks of a bulk change
+func putTasks(app *models.Application, listID string, tasks []models.Task, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	if list := findList(app, listID); list != nil {
+		for _, task := range tasks {
+			list.PutTask(task)
+		}
+	}
+	return "", nil
+}
+
+// creating returns the backend to run an operation with that creates records
+// with the IDs logged for it
+func (j *Journal) creating(ids []string) StorageInterface {
+	if backend, ok := j.StorageInterface.(idAssigner); ok && len(ids) > 0 {
+		return backend.withIDs(ids)
+	}
+	return j.StorageInterface
+}
+
+// persisted reports whether the records an operation creates exist already,
+// as they do when the backend persisted it before its outcome was logged
+func (j *Journal) persisted(app *models.Application, e journalEntry) bool {
+	if len(e.IDs) == 0 {
+		return false
+	}
+	id := e.IDs[0]
+	switch e.Op {
+	case "create_list", "create_list_from_template":
+		return findList(app, id) != nil
+	case "create_template":
+		_, err := findTemplate(app, id)
+		return err == nil
+	case "create_task", "duplicate_task":
+		if err := j.StorageInterface.LoadTasks(app, e.ListID); err != nil {
+			return false
+		}
+		_, err := findTask(app, e.ListID, id)
+		return err == nil
+	case "add_note":
+		task, err := findTask(app, e.ListID, e.TaskID)
+		return err == nil && slices.ContainsFunc(task.Notes, func(note models.Note) bool { return note.ID == id })
+	}
+	return false
+}
+
+// listIndex returns where the list listID is among lists, or -1
+func listIndex(lists []models.TodoList, listID string) int {
+	return slices.IndexFunc(lists, func(list models.TodoList) bool { return list.ID == listID })
+}
+
+// apply runs an operation read from the log on the wrapped backend. Applying
+// an operation the backend persisted already changes nothing: records it
+// creates are looked up by the IDs logged for it, and changes are logged as
+// the values they lead to rather than relative to the old ones.
+func (j *Journal) apply(app *models.Application, e journalEntry) (string, error) {
+	s := j.StorageInterface
+	if e.TaskID != "" || len(e.TaskIDs) > 0 {
+		if err := s.LoadTasks(app, e.ListID); err != nil {
+			return "", err
+		}
+	}
+	if j.persisted(app, e) {
+		return e.IDs[0], errAlreadyApplied
+	}
+	creating := j.creating(e.IDs)
+
+	switch e.Op {
+	case "create_list":
+		return creating.CreateTodoList(app, e.Name, e.Description, e.Color)
+	case "update_list":
+		return "", s.UpdateTodoList(app, e.ListID, e.Name, e.Description, e.Color)
+	case "delete_list":
+		return "", s.DeleteTodoList(app, e.ListID)
+	case "set_list_group":
+		return "", s.SetListGroup(app, e.ListID, e.Group)
+	case "set_list_grouping":
+		return "", s.SetListGrouping(app, e.ListID, e.Grouping)
+	case "set_list_pinned":
+		return "", s.SetListPinned(app, e.ListID, e.Pinned)
+	case "reorder_list":
+		offset := e.Offset
+		if e.Position != nil {
+			offset = *e.Position - listIndex(app.TodoLists, e.ListID)
+			if offset == 0 {
+				return "", errAlreadyApplied
+			}
+		}
+		return "", s.ReorderList(app, e.ListID, offset)
+	case "create_template":
+		return creating.CreateTemplate(app, e.Name, e.Tasks)
+	case "rename_template":
+		return "", s.RenameTemplate(app, e.TemplateID, e.Name)
+	case "delete_template":
+		return "", s.DeleteTemplate(app, e.TemplateID)
+	case "create_list_from_template":
+		return creating.CreateListFromTemplate(app, e.TemplateID, e.Name, e.Description, e.Color)
+	case "create_task":
+		task, err := creating.CreateTask(app, e.ListID, e.Title, e.Description, e.Priority, e.Deadline, e.Label, e.Source)
+		return putTask(app, e.ListID, task, err)
+	case "duplicate_task":
+		task, err := creating.DuplicateTask(app, e.ListID, e.TaskID)
+		if err != nil {
+			return "", err
+		}
+		if list := findList(app, e.ListID); list != nil {
+			list.PutTaskAfter(task, e.TaskID)
+		}
+		return task.ID, nil
+	case "update_task":
+		task, err := s.UpdateTask(app, e.ListID, e.TaskID, e.Title, e.Description, e.Priority, e.Deadline, e.Label)
+		return putTask(app, e.ListID, task, err)
+	case "snooze_task":
+		if e.Deadline == nil {
+			return "", errors.New("snooze without a deadline")
+		}
+		task, err := s.SnoozeTask(app, e.ListID, e.TaskID, *e.Deadline)
+		return putTask(app, e.ListID, task, err)
+	case "update_task_time":
+		task, err := s.UpdateTaskTime(app, e.ListID, e.TaskID, e.Estimate, e.Spent)
+		return putTask(app, e.ListID, task, err)
+	case "set_task_link":
+		task, err := s.SetTaskLink(app, e.ListID, e.TaskID, e.Link)
+		return putTask(app, e.ListID, task, err)
+	case "set_task_reminder":
+		task, err := s.SetTaskReminder(app, e.ListID, e.TaskID, e.Reminder)
+		return putTask(app, e.ListID, task, err)
+	case "set_task_progress":
+		task, err := s.SetTaskProgress(app, e.ListID, e.TaskID, e.Progress)
+		return putTask(app, e.ListID, task, err)
+	case "set_task_starred":
+		task, err := s.SetTaskStarred(app, e.ListID, e.TaskID, e.Starred)
+		return putTask(app, e.ListID, task, err)
+	case "clear_completed_stars":
+		if e.Before == nil {
+			return "", errors.New("star clearing without a cutoff")
+		}
+		cleared, err := s.ClearCompletedStars(app, *e.Before)
+		if err != nil {
+			return "", err
+		}
+		for _, entry := range cleared {
+			if list := findList(app, entry.ListID); list != nil {
+				list.PutTask(entry.Task)
+			}
+		}
+		return "", nil
+	case "trash_completed":
+		if e.Before == nil {
+			return "", errors.New("completed task purge without a cutoff")
+		}
+		trashed, err := s.TrashCompleted(app, *e.Before)
+		if err != nil {
+			return "", err
+		}
+		for _, entry := range trashed {
+			if list := findList(app, entry.ListID); list != nil {
+				list.DropTask(entry.Task)
+			}
+		}
+		return "", nil
+	case "set_tasks_priority":
+		tasks, err := s.SetTasksPriority(app, e.ListID, e.TaskIDs, e.Priority)
+		return putTasks(app, e.ListID, tasks, err)
+	case "set_tasks_deadline":
+		tasks, err := s.SetTasksDeadline(app, e.ListID, e.TaskIDs, e.Deadline)
+		return putTasks(app, e.ListID, tasks, err)
+	case "shift_deadlines":
+		if err := s.LoadTasks(app, e.ListID); err != nil {
+			return "", err
+		}
+		if e.Shifts == nil {
+			_, err := s.ShiftDeadlines(app, e.ListID, e.Delta)
+			return "", err
+		}
+		// Only deadlines still where they were are moved where the shift took them
+		shifted := 0
+		for _, shift := range e.Shifts {
+			current, err := findTask(app, e.ListID, shift.TaskID)
+			if err != nil || current.Deadline == nil || !current.Deadline.Equal(shift.From) {
+				continue
+			}
+			tasks, err := s.SetTasksDeadline(app, e.ListID, []string{shift.TaskID}, &shift.To)
+			if _, err := putTasks(app, e.ListID, tasks, err); err != nil {
+				return "", err
+			}
+			shifted++
+		}
+		if shifted == 0 {
+			return "", errAlreadyApplied
+		}
+		return "", nil
+	case "toggle_task":
+		// Toggling twice would undo it, so only toggle towards the logged state
+		current, err := findTask(app, e.ListID, e.TaskID)
+		if err != nil || current.Completed == e.Completed {
+			return "", err
+		}
+		task, err := s.ToggleTask(app, e.ListID, e.TaskID)
+		return putTask(app, e.ListID, task, err)
+	case "delete_task":
+		if err := s.DeleteTask(app, e.ListID, e.TaskID); err != nil {
+			return "", err
+		}
+		if list := findList(app, e.ListID); list != nil {
+			list.RemoveTask(e.TaskID)
+		}
+		return "", nil
+	case "restore_task":
+		task, err := s.RestoreTask(app, e.ListID, e.TaskID)
+		return putTask(app, e.ListID, task, err)
+	case "purge_trash":
+		if e.Before == nil {
+			return "", errors.New("trash purge without a cutoff")
+		}
+		_, err := s.PurgeTrash(app, *e.Before)
+		return "", err
+	case "add_note":
+		return creating.AddNote(app, e.ListID, e.TaskID, e.Body)
+	case "delete_note":
+		return "", s.DeleteNote(app, e.ListID, e.TaskID, e.NoteID)
+	case "merge":
+		if e.Incoming == nil {
+			return "", errors.New("merge without data")
+		}
+		_, err := s.Merge(app, e.Incoming)
+		return "", err
+	default:
+		return "", fmt.Errorf("unknown journal operation %q", e.Op)
+	}
+}
+
+// record logs an operation, applies it and logs its outcome
+func (j *Journal) record(app *models.Application, entry journalEntry, apply func() (string, error)) error {
+	j.mu.Lock()
+	j.app = app
+	j.seq++
+	now := time.Now()
+	entry.Seq = j.seq
+	entry.Time = &now
+	if j.txSeqs != nil {
+		*j.txSeqs = append(*j.txSeqs, entry.Seq)
+	}
+	err := j.write(entry)
+	j.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+
+	id, err := apply()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	outcome := journalEntry{Seq: entry.Seq, Done: err == nil, Failed: err != nil, ID: id}
+	if writeErr := j.write(outcome); writeErr != nil && err == nil {
+		err = fmt.Errorf("failed to write journal: %w", writeErr)
+	}
+	return err
+}
+
+// recordTask is record for operations that return the task they changed
+func (j *Journal) recordTask(app *models.Application, entry journalEntry, apply func() (models.Task, error)) (models.Task, error) {
+	var task models.Task
+	err := j.record(app, entry, func() (string, error) {
+		var err error
+		task, err = apply()
+		return "", err
+	})
+	return task, err
+}
+
+// write appends an entry to the log and syncs it to disk; j.mu must be held
+func (j *Journal) write(entry journalEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if _, err := j.file.Write(data); err != nil {
+		return err
+	}
+	j.pending = append(j.pending, journalLine{seq: entry.Seq, data: data})
+	return j.file.Sync()
+}
+
+// checkpoint drops the log lines up to seq, which a save has covered; j.mu must be held
+func (j *Journal) checkpoint(seq int) error {
+	keep := j.pending[:0]
+	for _, line := range j.pending {
+		if line.seq > seq {
+			keep = append(keep, line)
+		}
+	}
+	j.pending = keep
+
+	if err := j.file.Truncate(0); err != nil {
+		return err
+	}
+	for _, line := range j.pending {
+		if _, err := j.file.Write(line.data); err != nil {
+			return err
+		}
+	}
+	return j.file.Sync()
+}
+
+// Save saves the application data and cuts the log back to the operations
+// that happened while saving
+func (j *Journal) Save(app *models.Application) error {
+	j.mu.Lock()
+	seq := j.seq
+	j.mu.Unlock()
+
+	if err := j.StorageInterface.Save(app); err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.checkpoint(seq); err != nil {
+		return fmt.Errorf("failed to truncate journal: %w", err)
+	}
+	return nil
+}
+
+// Close saves what is still pending, removes the log and closes the backend.
+// The log is kept when the save fails so the next start recovers it.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	dirty := len(j.pending) > 0 && j.app != nil
+	app := j.app
+	j.mu.Unlock()
+
+	var saveErr error
+	if dirty {
+		saveErr = j.Save(app)
+	}
+
+	j.file.Close()
+	if saveErr == nil {
+		os.Remove(j.path)
+	}
+
+	if err := j.StorageInterface.Close(); err != nil {
+		return err
+	}
+	return saveErr
+}
+
+// Todo list operations
+
+func (j *Journal) CreateTodoList(app *models.Application, name, description, color string) (string, error) {
+	var id string
+	entry := journalEntry{Op: "create_list", IDs: newIDs(1), Name: name, Description: description, Color: color}
+	err := j.record(app, entry, func() (string, error) {
+		var err error
+		id, err = j.creating(entry.IDs).CreateTodoList(app, name, description, color)
+		return id, err
+	})
+	return id, err
+}
+
+func (j *Journal) UpdateTodoList(app *models.Application, listID, name, description, color string) error {
+	return j.record(app, journalEntry{Op: "update_list", ListID: listID, Name: name, Description: description, Color: color}, func() (string, error) {
+		return "", j.StorageInterface.UpdateTodoList(app, listID, name, description, color)
+	})
+}
+
+func (j *Journal) SetListGroup(app *models.Application, listID, group string) error {
+	return j.record(app, journalEntry{Op: "set_list_group", ListID: listID, Group: group}, func() (string, error) {
+		return "", j.StorageInterface.SetListGroup(app, listID, group)
+	})
+}
+
+func (j *Journal) SetListGrouping(app *models.Application, listID, grouping string) error {
+	return j.record(app, journalEntry{Op: "set_list_grouping", ListID: listID, Grouping: grouping}, func() (string, error) {
+		return "", j.StorageInterface.SetListGrouping(app, listID, grouping)
+	})
+}
+
+func (j *Journal) SetListPinned(app *models.Application, listID string, pinned bool) error {
+	return j.record(app, journalEntry{Op: "set_list_pinned", ListID: listID, Pinned: pinned}, func() (string, error) {
+		return "", j.StorageInterface.SetListPinned(app, listID, pinned)
+	})
+}
+
+func (j *Journal) DeleteTodoList(app *models.Application, listID string) error {
+	return j.record(app, journalEntry{Op: "delete_list", ListID: listID}, func() (string, error) {
+		return "", j.StorageInterface.DeleteTodoList(app, listID)
+	})
+}
+
+func (j *Journal) ReorderList(app *models.Application, listID string, offset int) error {
+	entry := journalEntry{Op: "reorder_list", ListID: listID, Offset: offset}
+	if reordered, err := moveList(app.TodoLists, listID, offset); err == nil {
+		position := listIndex(reordered, listID)
+		entry.Position = &position
+	}
+	return j.record(app, entry, func() (string, error) {
+		return "", j.StorageInterface.ReorderList(app, listID, offset)
+	})
+}
+
+// Template operations
+
+func (j *Journal) CreateTemplate(app *models.Application, name string, tasks []models.TemplateTask) (string, error) {
+	var id string
+	entry := journalEntry{Op: "create_template", IDs: newIDs(1), Name: name, Tasks: tasks}
+	err := j.record(app, entry, func() (string, error) {
+		var err error
+		id, err = j.creating(entry.IDs).CreateTemplate(app, name, tasks)
+		return id, err
+	})
+	return id, err
+}
+
+func (j *Journal) RenameTemplate(app *models.Application, templateID, name string) error {
+	return j.record(app, journalEntry{Op: "rename_template", TemplateID: templateID, Name: name}, func() (string, error) {
+		return "", j.StorageInterface.RenameTemplate(app, templateID, name)
+	})
+}
+
+func (j *Journal) DeleteTemplate(app *models.Application, templateID string) error {
+	return j.record(app, journalEntry{Op: "delete_template", TemplateID: templateID}, func() (string, error) {
+		return "", j.StorageInterface.DeleteTemplate(app, templateID)
+	})
+}
+
+func (j *Journal) CreateListFromTemplate(app *models.Application, templateID, name, description, color string) (string, error) {
+	var id string
+	entry := journalEntry{Op: "create_list_from_template", TemplateID: templateID, Name: name, Description: description, Color: color}
+	if template, err := findTemplate(app, templateID); err == nil {
+		entry.IDs = newIDs(1 + len(template.Tasks)) // The list, then its tasks
+	}
+	err := j.record(app, entry, func() (string, error) {
+		var err error
+		id, err = j.creating(entry.IDs).CreateListFromTemplate(app, templateID, name, description, color)
+		return id, err
+	})
+	return id, err
+}
+
+// Task operations
+
+func (j *Journal) CreateTask(app *models.Application, listID, title, description string, priority models.Priority, deadline *time.Time, label, source string) (models.Task, error) {
+	var task models.Task
+	entry := journalEntry{Op: "create_task", IDs: newIDs(1), ListID: listID, Title: title, Description: description, Priority: priority, Deadline: deadline, Label: label, Source: source}
+	err := j.record(app, entry, func() (string, error) {
+		var err error
+		task, err = j.creating(entry.IDs).CreateTask(app, listID, title, description, priority, deadline, label, source)
+		return task.ID, err
+	})
+	return task, err
+}
+
+func (j *Journal) DuplicateTask(app *models.Application, listID, taskID string) (models.Task, error) {
+	var task models.Task
+	entry := journalEntry{Op: "duplicate_task", IDs: newIDs(1), ListID: listID, TaskID: taskID}
+	err := j.record(app, entry, func() (string, error) {
+		var err error
+		task, err = j.creating(entry.IDs).DuplicateTask(app, listID, taskID)
+		return task.ID, err
+	})
+	return task, err
+}
+
+func (j *Journal) UpdateTask(app *models.Application, listID, taskID, title, description string, priority models.Priority, deadline *time.Time, label string) (models.Task, error) {
+	entry := journalEntry{Op: "update_task", ListID: listID, TaskID: taskID, Title: title, Description: description, Priority: priority, Deadline: deadline, Label: label}
+	return j.recordTask(app, entry, func() (models.Task, error) {
+		return j.StorageInterface.UpdateTask(app, listID, taskID, title, description, priority, deadline, label)
+	})
+}
+
+func (j *Journal) SnoozeTask(app *models.Application, listID, taskID string, deadline time.Time) (models.Task, error) {
+	return j.recordTask(app, journalEntry{Op: "snooze_task", ListID: listID, TaskID: taskID, Deadline: &deadline}, func() (models.Task, error) {
+		return j.StorageInterface.SnoozeTask(app, listID, taskID, deadline)
+	})
+}
+
+func (j *Journal) UpdateTaskTime(app *models.Application, listID, taskID string, estimate, spent time.Duration) (models.Task, error) {
+	return j.recordTask(app, journalEntry{Op: "update_task_time", ListID: listID, TaskID: taskID, Estimate: estimate, Spent: spent}, func() (models.Task, error) {
+		return j.StorageInterface.UpdateTaskTime(app, listID, taskID, estimate, spent)
+	})
+}
+
+func (j *Journal) SetTaskLink(app *models.Application, listID, taskID, link string) (models.Task, error) {
+	return j.recordTask(app, journalEntry{Op: "set_task_link", ListID: listID, TaskID: taskID, Link: link}, func() (models.Task, error) {
+		return j.StorageInterface.SetTaskLink(app, listID, taskID, link)
+	})
+}
+
+func (j *Journal) SetTaskReminder(app *models.Application, listID, taskID string, offset *time.Duration) (models.Task, error) {
+	return j.recordTask(app, journalEntry{Op: "set_task_reminder", ListID: listID, TaskID: taskID, Reminder: offset}, func() (models.Task, error) {
+		return j.StorageInterface.SetTaskReminder(app, listID, taskID, offset)
+	})
+}
+
+func (j *Journal) SetTaskProgress(app *models.Application, listID, taskID string, progress int) (models.Task, error) {
+	return j.recordTask(app, journalEntry{Op: "set_task_progress", ListID: listID, TaskID: taskID, Progress: progress}, func() (models.Task, error) {
+		return j.StorageInterface.SetTaskProgress(app, listID, taskID, progress)
+	})
+}
+
+func (j *Journal) SetTaskStarred(app *models.Application, listID, taskID string, starred bool) (models.Task, error) {
+	return j.recordTask(app, journalEntry{Op: "set_task_starred", ListID: listID, TaskID: taskID, Starred: starred}, func() (models.Task, error) {
+		return j.StorageInterface.SetTaskStarred(app, listID, taskID, starred)
+	})
+}
+
+func (j *Journal) ClearCompletedStars(app *models.Application, before time.Time) ([]models.ListTask, error) {
+	var cleared []models.ListTask
+	err := j.record(app, journalEntry{Op: "clear_completed_stars", Before: &before}, func() (string, error) {
+		var err error
+		cleared, err = j.StorageInterface.ClearCompletedStars(app, before)
+		return "", err
+	})
+	return cleared, err
+}
+
+func (j *Journal) TrashCompleted(app *models.Application, before time.Time) ([]models.ListTask, error) {
+	var trashed []models.ListTask
+	err := j.record(app, journalEntry{Op: "trash_completed", Before: &before}, func() (string, error) {
+		var err error
+		trashed, err = j.StorageInterface.TrashCompleted(app, before)
+		return "", err
+	})
+	return trashed, err
+}
+
+func (j *Journal) SetTasksPriority(app *models.Application, listID string, taskIDs []string, priority models.Priority) ([]models.Task, error) {
+	var tasks []models.Task
+	err := j.record(app, journalEntry{Op: "set_tasks_priority", ListID: listID, TaskIDs: taskIDs, Priority: priority}, func() (string, error) {
+		var err error
+		tasks, err = j.StorageInterface.SetTasksPriority(app, listID, taskIDs, priority)
+		return "", err
+	})
+	return tasks, err
+}
+
+func (j *Journal) SetTasksDeadline(app *models.Application, listID string, taskIDs []string, deadline *time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := j.record(app, journalEntry{Op: "set_tasks_deadline", ListID: listID, TaskIDs: taskIDs, Deadline: deadline}, func() (string, error) {
+		var err error
+		tasks, err = j.StorageInterface.SetTasksDeadline(app, listID, taskIDs, deadline)
+		return "", err
+	})
+	return tasks, err
+}
+
+func (j *Journal) ShiftDeadlines(app *models.Application, listID string, delta time.Duration) (int, error) {
+	entry := journalEntry{Op: "shift_deadlines", ListID: listID, Delta: delta, Shifts: []deadlineShift{}}
+	if err := j.StorageInterface.LoadTasks(app, listID); err != nil {
+		return 0, err
+	}
+	if list := findList(app, listID); list != nil {
+		for _, task := range list.Tasks {
+			if !task.Completed && task.Deadline != nil {
+				entry.Shifts = append(entry.Shifts, deadlineShift{TaskID: task.ID, From: *task.Deadline, To: task.Deadline.Add(delta)})
+			}
+		}
+	}
+
+	var shifted int
+	err := j.record(app, entry, func() (string, error) {
+		var err error
+		shifted, err = j.StorageInterface.ShiftDeadlines(app, listID, delta)
+		return "", err
+	})
+	return shifted, err
+}
+
+func (j *Journal) ToggleTask(app *models.Application, listID, taskID string) (models.Task, error) {
+	entry := journalEntry{Op: "toggle_task", ListID: listID, TaskID: taskID}
+	if task, err := findTask(app, listID, taskID); err == nil {
+		entry.Completed = !task.Completed
+	}
+	return j.recordTask(app, entry, func() (models.Task, error) {
+		return j.StorageInterface.ToggleTask(app, listID, taskID)
+	})
+}
+
+func (j *Journal) DeleteTask(app *models.Application, listID, taskID string) error {
+	return j.record(app, journalEntry{Op: "delete_task", ListID: listID, TaskID: taskID}, func() (string, error) {
+		return "", j.StorageInterface.DeleteTask(app, listID, taskID)
+	})
+}
+
+func (j *Journal) RestoreTask(app *models.Application, listID, taskID string) (models.Task, error) {
+	return j.recordTask(app, journalEntry{Op: "restore_task", ListID: listID, TaskID: taskID}, func() (models.Task, error) {
+		return j.StorageInterface.RestoreTask(app, listID, taskID)
+	})
+}
+
+func (j *Journal) PurgeTrash(app *models.Application, before time.Time) (int, error) {
+	var purged int
+	err := j.record(app, journalEntry{Op: "purge_trash", Before: &before}, func() (string, error) {
+		var err error
+		purged, err = j.StorageInterface.PurgeTrash(app, before)
+		return "", err
+	})
+	return purged, err
+}
+
+// Task note operations
+
+func (j *Journal) AddNote(app *models.Application, listID, taskID, body string) (string, error) {
+	var id string
+	entry := journalEntry{Op: "add_note", IDs: newIDs(1), ListID: listID, TaskID: taskID, Body: body}
+	err := j.record(app, entry, func() (string, error) {
+		var err error
+		id, err = j.creating(entry.IDs).AddNote(app, listID, taskID, body)
+		return id, err
+	})
+	return id, err
+}
+
+func (j *Journal) DeleteNote(app *models.Application, listID, taskID, noteID string) error {
+	return j.record(app, journalEntry{Op: "delete_note", ListID: listID, TaskID: taskID, NoteID: noteID}, func() (string, error) {
+		return "", j.StorageInterface.DeleteNote(app, listID, taskID, noteID)
+	})
+}
+
+func (j *Journal) Merge(app *models.Application, incoming *models.Application) (MergeResult, error) {
+	var result MergeResult
+	err := j.record(app, journalEntry{Op: "merge", Incoming: incoming}, func() (string, error) {
+		var err error
+		result, err = j.StorageInterface.Merge(app, incoming)
+		return "", err
+	})
+	return result, err
+}
+
+// WithTransaction runs fn in a transaction of the backend, logging its
+// operations as usual. fn is handed a Journal of its own over the
+// transaction, sharing the log, so operations running meanwhile on j are
+// neither made inside the transaction nor taken for its operations. When the
+// transaction fails, its operations are logged as failed afterwards, so that
+// a replay leaves them out as the backend did.
+func (j *Journal) WithTransaction(app *models.Application, fn func(tx StorageInterface) error) error {
+	if j.txSeqs != nil {
+		// A transaction inside another joins it, and fails with it
+		return j.StorageInterface.WithTransaction(app, func(tx StorageInterface) error {
+			return fn(&Journal{StorageInterface: tx, journalLog: j.journalLog, txSeqs: j.txSeqs})
+		})
+	}
+
+	var seqs []int
+	err := j.StorageInterface.WithTransaction(app, func(tx StorageInterface) error {
+		return fn(&Journal{StorageInterface: tx, journalLog: j.journalLog, txSeqs: &seqs})
+	})
+	if err == nil {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, seq := range seqs {
+		if writeErr := j.write(journalEntry{Seq: seq, Failed: true}); writeErr != nil {
+			return fmt.Errorf("%w (and failed to write journal: %v)", err, writeErr)
+		}
+	}
+	return err
+}
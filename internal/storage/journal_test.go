@@ -0,0 +1,328 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// journalFixture is the data each crash test starts from
+type journalFixture struct {
+	work, home string // List IDs
+	dated      string // Task of Work with a deadline
+	template   string
+}
+
+// setUpJournalFixture fills an empty store with a few lists, tasks and a
+// template, and saves them
+func setUpJournalFixture(t *testing.T, store StorageInterface, app *models.Application) journalFixture {
+	t.Helper()
+	var f journalFixture
+	f.work = mustCreateList(t, store, app, "Work")
+	f.home = mustCreateList(t, store, app, "Home")
+	mustCreateList(t, store, app, "Errands")
+
+	deadline := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	f.dated = mustCreateTask(t, store, app, f.work, "Write report", &deadline).ID
+	mustCreateTask(t, store, app, f.work, "Tidy desk", nil)
+	done := mustCreateTask(t, store, app, f.work, "Book flights", &deadline)
+	toggled, err := store.ToggleTask(app, f.work, done.ID)
+	if err != nil {
+		t.Fatalf("ToggleTask: %v", err)
+	}
+	findList(app, f.work).PutTask(toggled)
+
+	id, err := store.CreateTemplate(app, "Trip", []models.TemplateTask{{Title: "Pack"}, {Title: "Water plants"}})
+	if err != nil {
+		t.Fatalf("CreateTemplate: %v", err)
+	}
+	f.template = id
+
+	if err := store.Save(app); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	return f
+}
+
+// describeData sums up the data of store in a line per list, task, note and
+// template, in order and leaving out times that change from run to run
+func describeData(t *testing.T, store StorageInterface) string {
+	t.Helper()
+	app, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	var b strings.Builder
+	for _, list := range app.TodoLists {
+		if err := store.LoadTasks(app, list.ID); err != nil {
+			t.Fatalf("LoadTasks: %v", err)
+		}
+		list := findList(app, list.ID)
+		fmt.Fprintf(&b, "list %s %s\n", list.ID, list.Name)
+		for _, task := range list.Tasks {
+			deadline := "-"
+			if task.Deadline != nil {
+				deadline = task.Deadline.Format(timestampLayout)
+			}
+			fmt.Fprintf(&b, "  task %s %s %s %v\n", task.ID, task.Title, deadline, task.Completed)
+			for _, note := range task.Notes {
+				fmt.Fprintf(&b, "    note %s %s\n", note.ID, note.Body)
+			}
+		}
+	}
+	for _, template := range app.Templates {
+		fmt.Fprintf(&b, "template %s %s %d\n", template.ID, template.Name, len(template.Tasks))
+	}
+	return b.String()
+}
+
+// dropOutcomes removes the outcome lines from the journal at path, as if the
+// process died after each operation was applied but before it was marked done
+func dropOutcomes(t *testing.T, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading journal: %v", err)
+	}
+	var kept []byte
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		var entry journalEntry
+		if json.Unmarshal(line, &entry) == nil && entry.Op == "" {
+			continue
+		}
+		kept = append(kept, line...)
+	}
+	if err := os.WriteFile(path, kept, 0644); err != nil {
+		t.Fatalf("writing journal: %v", err)
+	}
+}
+
+// copyDataFiles copies the data files of dir, lazytodo.json or lazytodo.db,
+// into another directory, or back with the arguments swapped
+func copyDataFiles(t *testing.T, from, to string) {
+	t.Helper()
+	for _, name := range []string{DataFileName, DatabaseName} {
+		data, err := os.ReadFile(filepath.Join(from, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(to, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// journalOps are the operations whose replay used to create or change things
+// twice, each run through a journal
+var journalOps = map[string]func(j StorageInterface, app *models.Application, f journalFixture) error{
+	"create_task": func(j StorageInterface, app *models.Application, f journalFixture) error {
+		_, err := j.CreateTask(app, f.work, "Call back", "", models.High, nil, "", models.SourceTUI)
+		return err
+	},
+	"duplicate_task": func(j StorageInterface, app *models.Application, f journalFixture) error {
+		_, err := j.DuplicateTask(app, f.work, f.dated)
+		return err
+	},
+	"create_list": func(j StorageInterface, app *models.Application, f journalFixture) error {
+		_, err := j.CreateTodoList(app, "Garden", "", "")
+		return err
+	},
+	"create_template": func(j StorageInterface, app *models.Application, f journalFixture) error {
+		_, err := j.CreateTemplate(app, "Week", []models.TemplateTask{{Title: "Plan"}})
+		return err
+	},
+	"create_list_from_template": func(j StorageInterface, app *models.Application, f journalFixture) error {
+		_, err := j.CreateListFromTemplate(app, f.template, "Lisbon", "", "")
+		return err
+	},
+	"add_note": func(j StorageInterface, app *models.Application, f journalFixture) error {
+		if err := j.LoadTasks(app, f.work); err != nil {
+			return err
+		}
+		_, err := j.AddNote(app, f.work, f.dated, "Numbers are in the shared folder")
+		return err
+	},
+	"shift_deadlines": func(j StorageInterface, app *models.Application, f journalFixture) error {
+		_, err := j.ShiftDeadlines(app, f.work, 48*time.Hour)
+		return err
+	},
+	"reorder_list": func(j StorageInterface, app *models.Application, f journalFixture) error {
+		return j.ReorderList(app, f.work, 1)
+	},
+}
+
+// TestJournalReplayAfterCrash runs each operation through a journal and then
+// loses the end of the session in the ways a crash can: for the database,
+// before or after the operation was committed, and for the JSON file, before
+// it was saved. Replaying the journal must leave the data as the operation
+// left it, made once.
+func TestJournalReplayAfterCrash(t *testing.T) {
+	crashes := []struct {
+		name, backend string
+		committed     bool // Whether the operation reached the data file
+	}{
+		{"database after commit", "database", true},
+		{"database before commit", "database", false},
+		{"json before save", "json", false},
+	}
+
+	for op, run := range journalOps {
+		for _, crash := range crashes {
+			t.Run(op+"/"+crash.name, func(t *testing.T) {
+				store := openBackend(t, crash.backend)
+				dir := filepath.Dir(store.GetDataPath())
+				app, err := store.Load()
+				if err != nil {
+					t.Fatalf("Load: %v", err)
+				}
+				f := setUpJournalFixture(t, store, app)
+				before := t.TempDir()
+				copyDataFiles(t, dir, before)
+
+				journal, _, err := OpenJournal(store, app)
+				if err != nil {
+					t.Fatalf("OpenJournal: %v", err)
+				}
+				if err := run(journal, app, f); err != nil {
+					t.Fatalf("%s: %v", op, err)
+				}
+				j := journal.(*Journal)
+				logged, err := os.ReadFile(j.path)
+				if err != nil {
+					t.Fatalf("reading journal: %v", err)
+				}
+				// Saved only to read back what the operation leads to
+				if err := journal.Save(app); err != nil {
+					t.Fatalf("Save: %v", err)
+				}
+				want := describeData(t, store)
+
+				// The process dies: the journal stays, and outcomes not yet written are lost
+				j.file.Close()
+				store.Close()
+				if !crash.committed {
+					copyDataFiles(t, before, dir)
+				}
+				if err := os.WriteFile(j.path, logged, 0644); err != nil {
+					t.Fatal(err)
+				}
+				dropOutcomes(t, j.path)
+
+				restarted := reopenBackend(t, crash.backend)
+				defer restarted.Close()
+				app, err = restarted.Load()
+				if err != nil {
+					t.Fatalf("Load after the crash: %v", err)
+				}
+				recovered, _, err := OpenJournal(restarted, app)
+				if err != nil {
+					t.Fatalf("OpenJournal after the crash: %v", err)
+				}
+				defer recovered.Close()
+
+				if got := describeData(t, restarted); got != want {
+					t.Errorf("after replaying the journal the data is\n%s\nwant\n%s", got, want)
+				}
+			})
+		}
+	}
+}
+
+// TestJournalTransactionLeavesConcurrentOperationsOut checks that an
+// operation made on the journal while a transaction is open is neither rolled
+// back with it nor logged as failed
+func TestJournalTransactionLeavesConcurrentOperationsOut(t *testing.T) {
+	store := openBackend(t, "database")
+	app, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	journal, _, err := OpenJournal(store, app)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	j := journal.(*Journal)
+
+	rollBack := errors.New("rolled back")
+	done := make(chan error, 1)
+	err = journal.WithTransaction(app, func(tx StorageInterface) error {
+		if _, err := tx.CreateTodoList(app, "Inside", "", ""); err != nil {
+			return err
+		}
+		go func() {
+			_, err := journal.CreateTodoList(&models.Application{}, "Meanwhile", "", "")
+			done <- err
+		}()
+		// Give the other operation the time to go through the transaction
+		// if it were going to
+		select {
+		case err := <-done:
+			done <- err
+		case <-time.After(200 * time.Millisecond):
+		}
+		return rollBack
+	})
+	if !errors.Is(err, rollBack) {
+		t.Fatalf("WithTransaction = %v, want %v", err, rollBack)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("CreateTodoList meanwhile: %v", err)
+	}
+
+	if got, want := describeLists(t, store), "Meanwhile"; got != want {
+		t.Errorf("lists after the rollback = %q, want %q", got, want)
+	}
+
+	data, err := os.ReadFile(j.path)
+	if err != nil {
+		t.Fatalf("reading journal: %v", err)
+	}
+	names := make(map[int]string)
+	failed := make(map[int]bool)
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		var entry journalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatalf("journal line %q: %v", line, err)
+		}
+		if entry.Op != "" {
+			names[entry.Seq] = entry.Name
+		} else {
+			failed[entry.Seq] = entry.Failed
+		}
+	}
+	for seq, name := range names {
+		if want := name == "Inside"; failed[seq] != want {
+			t.Errorf("creating %s logged as failed = %v, want %v", name, failed[seq], want)
+		}
+	}
+
+	if err := journal.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+// describeLists returns the names of the lists store has, comma-separated
+func describeLists(t *testing.T, store StorageInterface) string {
+	t.Helper()
+	app, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var names []string
+	for _, list := range app.TodoLists {
+		names = append(names, list.Name)
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sahilm/fuzzy"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// ErrDuplicateListName is returned when creating or renaming a list would give
+// it another list's name while the unique_list_names setting is on
+var ErrDuplicateListName = errors.New("a list with this name already exists")
+
+// ListNameTaken reports whether a list other than the one with ID exceptID is
+// called name, ignoring case and surrounding spaces
+func ListNameTaken(app *models.Application, name, exceptID string) bool {
+	want := listNameKey(name)
+	for _, todoList := range app.TodoLists {
+		if todoList.ID != exceptID && listNameKey(todoList.Name) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// checkListName refuses a name another list has when the settings ask for
+// unique list names; otherwise a duplicate is left for the caller to warn about
+func checkListName(app *models.Application, name, exceptID string) error {
+	if app.Settings.UniqueListNames && ListNameTaken(app, name, exceptID) {
+		return fmt.Errorf("%w: %q", ErrDuplicateListName, name)
+	}
+	return nil
+}
+
+// ResolveList finds the list called name, ignoring case, or the list with
+// name as its ID. A name that matches no list exactly may be part of exactly
+// one list's name. The error suggests the lists that were meant when the name
+// is ambiguous or matches nothing.
+func ResolveList(app *models.Application, name string) (*models.TodoList, error) {
+	if todoList := findList(app, strings.TrimSpace(name)); todoList != nil {
+		return todoList, nil
+	}
+	want := strings.ToLower(strings.TrimSpace(name))
+
+	var exact, partial []*models.TodoList
+	for i := range app.TodoLists {
+		todoList := &app.TodoLists[i]
+		switch listName := strings.ToLower(todoList.Name); {
+		case listName == want:
+			exact = append(exact, todoList)
+		case strings.Contains(listName, want):
+			partial = append(partial, todoList)
+		}
+	}
+
+	matches := exact
+	if len(matches) == 0 {
+		matches = partial
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		names := make([]string, len(app.TodoLists))
+		for i, todoList := range app.TodoLists {
+			names[i] = todoList.Name
+		}
+		var suggestions []string
+		for _, match := range fuzzy.Find(name, names) {
+			suggestions = append(suggestions, match.Str)
+		}
+		if len(suggestions) == 0 {
+			if len(names) == 0 {
+				return nil, fmt.Errorf("no list named %q; there are no lists yet", name)
+			}
+			return nil, fmt.Errorf("no list named %q; the lists are %s", name, strings.Join(quoteAll(names), ", "))
+		}
+		return nil, fmt.Errorf("no list named %q; did you mean %s?", name, strings.Join(quoteAll(suggestions), ", "))
+	default:
+		names := make([]string, len(matches))
+		for i, todoList := range matches {
+			names[i] = todoList.Name
+		}
+		return nil, fmt.Errorf("%q matches several lists: %s", name, strings.Join(quoteAll(names), ", "))
+	}
+}
+
+func quoteAll(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	return quoted
+}
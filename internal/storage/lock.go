@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// LockName is the file a TUI session keeps in the data directory while it
+// may write the data; it holds the session's process ID
+const LockName = "lazytodo.lock"
+
+// ErrInstanceRunning is returned by AcquireLock when another LazyTodo session
+// holds the lock. Saving from a second session would overwrite the first
+// one's changes, so it can only browse the data read-only.
+var ErrInstanceRunning = errors.New("LazyTodo is already running with this data")
+
+// Lock is the data directory's lock held by this process. The lock file
+// stays open while it is held, under a lock of the operating system, which
+// goes away with the process however it ends.
+type Lock struct {
+	path string
+	file *os.File
+}
+
+// errLocked is returned by lockFile when another open file holds the lock
+var errLocked = errors.New("lock file is locked")
+
+// AcquireLock takes the data directory's lock for this process. A lock file
+// left behind by a process that is no longer running is not locked any more
+// and taken over. Without a writable data directory there is nothing to
+// protect, and a nil lock is returned.
+func AcquireLock() (*Lock, error) {
+	dataDir := resolveDataDir(io.Discard)
+	if !prepareDataDir(dataDir) {
+		return nil, nil
+	}
+	return acquireLock(filepath.Join(dataDir, LockName), os.Getpid())
+}
+
+// acquireLock locks the lock file at path and writes pid into it
+func acquireLock(path string, pid int) (*Lock, error) {
+	for {
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+		// Whoever holds the lock decides; the PID in the file only tells who
+		// that is, so a stale one is simply overwritten
+		if err := lockFile(file); err != nil {
+			file.Close()
+			if !errors.Is(err, errLocked) {
+				return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+			}
+			owner, readErr := readLockOwner(path)
+			if readErr != nil || owner <= 0 {
+				return nil, fmt.Errorf("%w (another session holds %s)", ErrInstanceRunning, path)
+			}
+			return nil, fmt.Errorf("%w (process %d holds %s)", ErrInstanceRunning, owner, path)
+		}
+
+		// A session releasing the lock removes the file before unlocking
+		// it; when that happened after it was opened here, the file locked
+		// is no longer the lock, and the one now at path is tried instead
+		opened, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to check lock file: %w", err)
+		}
+		if current, err := os.Stat(path); err != nil || !os.SameFile(opened, current) {
+			file.Close()
+			continue
+		}
+
+		if err := file.Truncate(0); err == nil {
+			_, err = fmt.Fprintf(file, "%d\n", pid)
+		}
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to write lock file: %w", err)
+		}
+		return &Lock{path: path, file: file}, nil
+	}
+}
+
+// readLockOwner returns the process ID kept in the lock file at path, or 0
+// when the file does not hold one
+func readLockOwner(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read lock file: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, nil
+	}
+	return pid, nil
+}
+
+// Release removes the lock file and unlocks it. Releasing a nil lock, or one
+// released already, does nothing.
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	defer func() { l.file = nil }()
+	if runtime.GOOS == "windows" {
+		// Windows removes no file that is open, so the lock is released
+		// first; a session that opened the file meanwhile keeps it there
+		err := l.file.Close()
+		os.Remove(l.path)
+		return err
+	}
+	// Removing the file first means whoever locks it next sees it gone and
+	// creates a new one, rather than a second session locking this one
+	removeErr := os.Remove(l.path)
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	if removeErr != nil && !os.IsNotExist(removeErr) {
+		return fmt.Errorf("failed to remove lock file: %w", removeErr)
+	}
+	return nil
+}
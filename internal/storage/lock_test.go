@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAcquireLockTakesOverStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockName)
+	// The session that wrote this is gone, and with it its lock
+	if err := os.WriteFile(path, []byte("999999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := acquireLock(path, 100)
+	if err != nil {
+		t.Fatalf("acquireLock over a stale lock: %v", err)
+	}
+	defer lock.Release()
+	if owner, err := readLockOwner(path); err != nil || owner != 100 {
+		t.Errorf("lock owner = %d, %v; want 100", owner, err)
+	}
+}
+
+func TestAcquireLockRefusesHeldLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockName)
+	lock, err := acquireLock(path, 100)
+	if err != nil {
+		t.Fatalf("acquireLock: %v", err)
+	}
+
+	_, err = acquireLock(path, 200)
+	if !errors.Is(err, ErrInstanceRunning) || !strings.Contains(err.Error(), "process 100") {
+		t.Fatalf("acquireLock while held = %v, want %v naming process 100", err, ErrInstanceRunning)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release again: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock file after Release: %v, want it removed", err)
+	}
+
+	lock, err = acquireLock(path, 200)
+	if err != nil {
+		t.Fatalf("acquireLock after Release: %v", err)
+	}
+	lock.Release()
+}
+
+func TestAcquireLockOnceAtATime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockName)
+	if err := os.WriteFile(path, []byte("999999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Sessions starting together over a stale lock, and one releasing it
+	// meanwhile, never hold it at the same time
+	var mu sync.Mutex
+	var held, most int
+	var wg sync.WaitGroup
+	for pid := 1; pid <= 20; pid++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock, err := acquireLock(path, pid)
+			if errors.Is(err, ErrInstanceRunning) {
+				return
+			}
+			if err != nil {
+				t.Errorf("acquireLock: %v", err)
+				return
+			}
+			mu.Lock()
+			held++
+			most = max(most, held)
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			held--
+			mu.Unlock()
+			if err := lock.Release(); err != nil {
+				t.Errorf("Release: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if most != 1 {
+		t.Errorf("sessions holding the lock at once = %d, want 1", most)
+	}
+}
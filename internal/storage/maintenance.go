@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// backupsKept is how many of the newest JSON backups maintenance leaves in
+// the data directory; older ones are removed
+const backupsKept = 3
+
+// TableRows is the number of rows of a database table
+type TableRows struct {
+	Table string
+	Rows  int
+}
+
+// MaintenanceReport describes what Maintain did to the database
+type MaintenanceReport struct {
+	Path          string      // Database file
+	SizeBefore    int64       // File size in bytes before maintenance
+	SizeAfter     int64       // File size in bytes afterwards
+	RowsBefore    []TableRows // Rows per table before maintenance, by table name
+	RowsAfter     []TableRows // Rows per table afterwards, by table name
+	PurgedTasks   int         // Tasks deleted for good for having been in the trash too long
+	PrunedBackups []string    // Old JSON backups removed
+}
+
+// Maintain tidies up the database: it checks its integrity, deletes the tasks
+// that have been in the trash longer than the trash_days setting, updates the
+// statistics SQLite plans queries with (ANALYZE), rebuilds the file to give
+// back the space deleted rows left (VACUUM) and removes all but the newest
+// backupsKept JSON backups. A damaged database is left alone and reported as
+// ErrDatabaseCorrupt. Nothing else may write to the database meanwhile.
+func Maintain(store StorageInterface, app *models.Application) (MaintenanceReport, error) {
+	if store.IsReadOnly() {
+		return MaintenanceReport{}, ErrReadOnly
+	}
+	db := unwrapDatabase(store)
+	if db == nil {
+		return MaintenanceReport{}, errors.New("maintenance only applies to the database; the JSON file has nothing to compact")
+	}
+
+	report := MaintenanceReport{Path: db.dataPath}
+	if err := db.checkIntegrity(); err != nil {
+		return report, err
+	}
+
+	var err error
+	if report.SizeBefore, err = fileSize(db.dataPath); err != nil {
+		return report, err
+	}
+	if report.RowsBefore, err = db.tableRows(); err != nil {
+		return report, err
+	}
+
+	// Purged through store, so that a journal keeps the change too
+	if days := app.Settings.TrashDays; days > 0 {
+		if report.PurgedTasks, err = store.PurgeTrash(app, time.Now().AddDate(0, 0, -days)); err != nil {
+			return report, err
+		}
+	}
+
+	if err := db.compact(); err != nil {
+		return report, err
+	}
+
+	if report.SizeAfter, err = fileSize(db.dataPath); err != nil {
+		return report, err
+	}
+	if report.RowsAfter, err = db.tableRows(); err != nil {
+		return report, err
+	}
+
+	report.PrunedBackups, err = pruneBackups(backupsKept)
+	return report, err
+}
+
+// unwrapDatabase returns the database beneath store, or nil for the JSON backend
+func unwrapDatabase(store StorageInterface) *DatabaseStorage {
+	switch s := store.(type) {
+	case *Journal:
+		return unwrapDatabase(s.StorageInterface)
+	case *DatabaseStorage:
+		return s
+	default:
+		return nil
+	}
+}
+
+// checkIntegrity runs SQLite's full integrity check, which reads every page
+// and index, unlike the quick check made when the database is opened
+func (s *DatabaseStorage) checkIntegrity() error {
+	rows, err := s.db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return fmt.Errorf("failed to check the database: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return fmt.Errorf("failed to check the database: %w", err)
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to check the database: %w", err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s: %s", ErrDatabaseCorrupt, s.dataPath, problems[0])
+	}
+	return nil
+}
+
+// tableRows counts the rows of every table, by table name
+func (s *DatabaseStorage) tableRows() ([]TableRows, error) {
+	rows, err := s.db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	// The tables are counted once the listing is closed, as an encrypted
+	// database has a single connection
+	counts := make([]TableRows, 0, len(tables))
+	for _, table := range tables {
+		count := TableRows{Table: table}
+		if err := s.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %q", table)).Scan(&count.Rows); err != nil {
+			return nil, fmt.Errorf("failed to count the rows of %s: %w", table, err)
+		}
+		counts = append(counts, count)
+	}
+	return counts, nil
+}
+
+// compact updates the query planner statistics and rebuilds the database
+// file without the free pages deleted rows left; an encrypted database is
+// then written out again so that its file shrinks too
+func (s *DatabaseStorage) compact() error {
+	if _, err := s.db.Exec("ANALYZE"); err != nil {
+		return fmt.Errorf("failed to analyze the database: %w", err)
+	}
+	if _, err := s.db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum the database: %w", err)
+	}
+	if s.encrypted() {
+		return s.writeEncrypted()
+	}
+	return nil
+}
+
+// pruneBackups removes all but the newest keep JSON backups from the data
+// directory and returns the ones it removed
+func pruneBackups(keep int) ([]string, error) {
+	backups := Backups()
+	if len(backups) <= keep {
+		return nil, nil
+	}
+
+	var pruned []string
+	for _, path := range backups[keep:] {
+		if err := os.Remove(path); err != nil {
+			return pruned, fmt.Errorf("failed to remove old backup: %w", err)
+		}
+		pruned = append(pruned, path)
+	}
+	return pruned, nil
+}
+
+// fileSize returns the size of the file at path in bytes
+func fileSize(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the database file size: %w", err)
+	}
+	return info.Size(), nil
+}
@@ -2,7 +2,9 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
@@ -12,19 +14,18 @@ import (
 
 // MigrateFromJSON migrates data from the old JSON file format to the database
 func MigrateFromJSON(dbStorage *DatabaseStorage) error {
-	// Check if JSON file exists
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get user home directory: %w", err)
+	if dbStorage.readOnly {
+		return ErrReadOnly
 	}
 
-	jsonPath := filepath.Join(homeDir, ".lazytodo", "lazytodo.json")
+	// Check if JSON file exists
+	jsonPath := LegacyJSONPath()
 	if _, err := os.Stat(jsonPath); os.IsNotExist(err) {
 		// No JSON file to migrate
 		return nil
 	}
 
-	fmt.Printf("Found existing JSON data file. Migrating to database...\n")
+	fmt.Fprintf(dbStorage.out, "Found existing JSON data file. Migrating to database...\n")
 
 	// Read JSON file
 	data, err := os.ReadFile(jsonPath)
@@ -37,6 +38,7 @@ func MigrateFromJSON(dbStorage *DatabaseStorage) error {
 	if err := json.Unmarshal(data, &jsonApp); err != nil {
 		return fmt.Errorf("failed to parse JSON data: %w", err)
 	}
+	jsonApp.Normalize()
 
 	// Start database transaction
 	tx, err := dbStorage.db.Begin()
@@ -46,11 +48,7 @@ func MigrateFromJSON(dbStorage *DatabaseStorage) error {
 	defer tx.Rollback()
 
 	// Migrate settings
-	for key, value := range map[string]string{
-		"reminder_minutes": fmt.Sprintf("%d", jsonApp.Settings.ReminderMinutes),
-		"show_completed":   fmt.Sprintf("%t", jsonApp.Settings.ShowCompleted),
-		"auto_save":        fmt.Sprintf("%t", jsonApp.Settings.AutoSave),
-	} {
+	for key, value := range settingsValues(jsonApp.Settings) {
 		_, err := tx.Exec("INSERT OR REPLACE INTO settings (key, value) VALUES (?, ?)", key, value)
 		if err != nil {
 			return fmt.Errorf("failed to migrate setting %s: %w", key, err)
@@ -58,12 +56,12 @@ func MigrateFromJSON(dbStorage *DatabaseStorage) error {
 	}
 
 	// Migrate todo lists and tasks
-	for _, list := range jsonApp.TodoLists {
-		// Insert todo list
+	for i, list := range jsonApp.TodoLists {
+		// Insert todo list, keeping the JSON file's order
 		_, err := tx.Exec(`
-			INSERT OR REPLACE INTO todo_lists (id, name, description, created_at, updated_at) 
-			VALUES (?, ?, ?, ?, ?)
-		`, list.ID, list.Name, list.Description,
+			INSERT OR REPLACE INTO todo_lists (id, name, description, color, group_name, grouping, pinned, sort_order, created_at, updated_at) 
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, list.ID, list.Name, list.Description, list.Color, list.Group, list.Grouping, list.Pinned, i,
 			list.CreatedAt.Format("2006-01-02 15:04:05"),
 			list.UpdatedAt.Format("2006-01-02 15:04:05"))
 
@@ -71,26 +69,25 @@ func MigrateFromJSON(dbStorage *DatabaseStorage) error {
 			return fmt.Errorf("failed to migrate todo list %s: %w", list.Name, err)
 		}
 
-		// Insert tasks for this list
+		// Insert tasks and their notes for this list
 		for _, task := range list.Tasks {
-			var deadlineStr *string
-			if task.Deadline != nil {
-				dl := task.Deadline.Format("2006-01-02 15:04:05")
-				deadlineStr = &dl
+			if err := insertTask(tx, list.ID, task); err != nil {
+				return fmt.Errorf("failed to migrate: %w", err)
 			}
+		}
+	}
 
-			_, err := tx.Exec(`
-				INSERT OR REPLACE INTO tasks 
-				(id, list_id, title, description, completed, priority, deadline, created_at, updated_at) 
-				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-			`, task.ID, list.ID, task.Title, task.Description, task.Completed,
-				int(task.Priority), deadlineStr,
-				task.CreatedAt.Format("2006-01-02 15:04:05"),
-				task.UpdatedAt.Format("2006-01-02 15:04:05"))
-
-			if err != nil {
-				return fmt.Errorf("failed to migrate task %s: %w", task.Title, err)
-			}
+	// Migrate the trash; deleting a list also empties its trash, so the lists exist
+	for _, entry := range jsonApp.Trash {
+		if err := insertTask(tx, entry.ListID, entry.Task); err != nil {
+			return fmt.Errorf("failed to migrate: %w", err)
+		}
+	}
+
+	// Migrate templates
+	for _, template := range jsonApp.Templates {
+		if err := insertTemplate(tx, template); err != nil {
+			return fmt.Errorf("failed to migrate: %w", err)
 		}
 	}
 
@@ -102,22 +99,108 @@ func MigrateFromJSON(dbStorage *DatabaseStorage) error {
 	// Create backup of JSON file and remove original
 	backupPath := jsonPath + ".backup." + time.Now().Format("20060102-150405")
 	if err := os.Rename(jsonPath, backupPath); err != nil {
-		fmt.Printf("Warning: failed to backup JSON file: %v\n", err)
+		fmt.Fprintf(dbStorage.out, "Warning: failed to backup JSON file: %v\n", err)
 	} else {
-		fmt.Printf("Migration completed! JSON file backed up to: %s\n", backupPath)
+		fmt.Fprintf(dbStorage.out, "Migration completed! JSON file backed up to: %s\n", backupPath)
+	}
+
+	// The database is in use now, even if keeping the JSON file was chosen before
+	if err := os.Remove(keepJSONPath()); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(dbStorage.out, "Warning: failed to remove %s: %v\n", keepJSONPath(), err)
+	}
+
+	fmt.Fprintf(dbStorage.out, "Successfully migrated %d todo lists to database.\n", len(jsonApp.TodoLists))
+	return nil
+}
+
+// KeepJSONName marks a data directory whose v1.x JSON file stays in use
+// because migrating it to the database was declined
+const KeepJSONName = "lazytodo.json.keep"
+
+// ErrMigrationPending is returned by NewWithMigration when a v1.x JSON file
+// has no database yet and migrating it was neither agreed to nor declined
+var ErrMigrationPending = errors.New("JSON data from LazyTodo v1.x has not been migrated to the database")
+
+// HasLegacyJSON reports whether a v1.x JSON data file exists
+func HasLegacyJSON() bool {
+	_, err := os.Stat(LegacyJSONPath())
+	return err == nil
+}
+
+// MigrationPending reports whether a v1.x JSON data file has no database yet
+// and nobody chose whether to migrate it
+func MigrationPending() bool {
+	if !HasLegacyJSON() || keepsJSON() {
+		return false
 	}
 
-	fmt.Printf("Successfully migrated %d todo lists to database.\n", len(jsonApp.TodoLists))
+	return !databaseExists()
+}
+
+// databaseExists reports whether there is a database, plain or encrypted
+func databaseExists() bool {
+	dataDir := resolveDataDir(io.Discard)
+	for _, name := range []string{DatabaseName, EncryptedDatabaseName} {
+		if _, err := os.Stat(filepath.Join(dataDir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// KeepJSON records that the v1.x JSON file stays in use, so NewWithMigration
+// opens it instead of asking again. Running --migrate later undoes the choice.
+func KeepJSON() error {
+	if err := os.WriteFile(keepJSONPath(), nil, 0644); err != nil {
+		return fmt.Errorf("failed to save the storage choice: %w", err)
+	}
 	return nil
 }
 
-// NewWithMigration creates a new database storage and automatically migrates from JSON if needed
-func NewWithMigration() (StorageInterface, error) {
-	dbStorage, err := NewDatabase()
+// keepsJSON reports whether the v1.x JSON file was chosen over the database
+func keepsJSON() bool {
+	_, err := os.Stat(keepJSONPath())
+	return err == nil && HasLegacyJSON()
+}
+
+// LegacyJSONPath returns the location of the v1.x JSON data file
+func LegacyJSONPath() string {
+	return filepath.Join(resolveDataDir(io.Discard), DataFileName)
+}
+
+// keepJSONPath returns the location of the marker written by KeepJSON
+func keepJSONPath() string {
+	return filepath.Join(resolveDataDir(io.Discard), KeepJSONName)
+}
+
+// NewWithMigration creates a new database storage and migrates from JSON if
+// needed. A v1.x JSON file without a database is only migrated when
+// opts.MigrateJSON agrees to it; otherwise ErrMigrationPending is returned.
+// When KeepJSON declined the migration the JSON file is opened instead.
+func NewWithMigration(opts Options) (StorageInterface, error) {
+	if keepsJSON() {
+		return New(opts)
+	}
+	if MigrationPending() {
+		// A read-only session cannot migrate, so it browses the JSON file
+		if opts.ReadOnly {
+			return New(opts)
+		}
+		if !opts.MigrateJSON {
+			return nil, ErrMigrationPending
+		}
+	}
+
+	dbStorage, err := NewDatabase(opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create database storage: %w", err)
 	}
 
+	// A read-only database cannot receive migrated data
+	if dbStorage.readOnly {
+		return dbStorage, nil
+	}
+
 	// Check if we need to migrate from JSON
 	if err := MigrateFromJSON(dbStorage); err != nil {
 		dbStorage.Close()
@@ -130,6 +213,8 @@ func NewWithMigration() (StorageInterface, error) {
 // GetStorageInfo returns information about the current storage backend
 func GetStorageInfo(storage StorageInterface) string {
 	switch s := storage.(type) {
+	case *Journal:
+		return GetStorageInfo(s.StorageInterface)
 	case *DatabaseStorage:
 		return fmt.Sprintf("Database: %s", s.GetDataPath())
 	case *Storage:
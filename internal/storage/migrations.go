@@ -0,0 +1,287 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationsDir is where the migration files are read from, relative to the
+// working directory
+const migrationsDir = "migrations"
+
+// Where an applied migration's SQL came from, as recorded in schema_migrations
+const (
+	sourceFile    = "file"
+	sourceBuiltin = "builtin"
+)
+
+// migration is a schema change: a migrations/*.up.sql file, or its built-in
+// copy when the directory is unavailable
+type migration struct {
+	version int
+	name    string // The file name, or "built-in 003" for a copy
+	sql     string
+	source  string // sourceFile or sourceBuiltin
+}
+
+// checksum returns the SHA-256 of the migration's SQL, in hex
+func (mg migration) checksum() string {
+	sum := sha256.Sum256([]byte(mg.sql))
+	return hex.EncodeToString(sum[:])
+}
+
+// MigrationState is where a migration stands in the database
+type MigrationState struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	Checksum  string // Recorded when it was applied; empty if that was before checksums were kept
+	Modified  bool   // Its SQL no longer matches the checksum recorded
+}
+
+// appliedMigration is a row of schema_migrations
+type appliedMigration struct {
+	appliedAt time.Time
+	checksum  string
+	source    string
+}
+
+// availableMigrations returns the migrations in version order: the files of
+// the migrations directory when it can be read, the built-in copies otherwise
+func availableMigrations() ([]migration, error) {
+	entries, err := os.ReadDir(migrationsDir)
+	if err != nil {
+		migrations := []migration{{version: 1, name: "built-in 001", sql: initialSchema, source: sourceBuiltin}}
+		for _, builtin := range builtinMigrations {
+			migrations = append(migrations, migration{
+				version: builtin.version,
+				name:    fmt.Sprintf("built-in %03d", builtin.version),
+				sql:     builtin.sql,
+				source:  sourceBuiltin,
+			})
+		}
+		return migrations, nil
+	}
+
+	var migrations []migration
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".up.sql") {
+			continue
+		}
+		// Extract version number from filename (e.g., "001_initial_schema.up.sql" -> 1)
+		version, err := strconv.Atoi(strings.Split(entry.Name(), "_")[0])
+		if err != nil {
+			continue // Skip invalid migration files
+		}
+		migrationSQL, err := os.ReadFile(filepath.Join(migrationsDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		migrations = append(migrations, migration{version: version, name: entry.Name(), sql: string(migrationSQL), source: sourceFile})
+	}
+	slices.SortFunc(migrations, func(a, b migration) int { return a.version - b.version })
+	return migrations, nil
+}
+
+// runMigrations applies the migrations not applied yet, each in a
+// transaction of its own together with its row in schema_migrations, so a
+// migration that fails leaves neither a half-changed schema nor a record of
+// it. It first refuses a database whose applied migrations were modified
+// since.
+func (s *DatabaseStorage) runMigrations() error {
+	if err := s.createMigrationsTable(); err != nil {
+		return err
+	}
+
+	migrations, err := availableMigrations()
+	if err != nil {
+		return err
+	}
+	applied, err := s.appliedMigrations()
+	if err != nil {
+		return err
+	}
+	if err := s.verifyMigrations(migrations, applied); err != nil {
+		return err
+	}
+
+	for _, mg := range migrations {
+		if _, ok := applied[mg.version]; ok {
+			continue
+		}
+		if err := s.applyMigration(mg); err != nil {
+			return err
+		}
+	}
+
+	return s.checkUniqueIDs()
+}
+
+// createMigrationsTable creates schema_migrations, and adds the checksum
+// columns to one made before they were kept
+func (s *DatabaseStorage) createMigrationsTable() error {
+	_, err := s.conn().Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			checksum TEXT NOT NULL DEFAULT '',
+			source TEXT NOT NULL DEFAULT ''
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	hasChecksums, err := s.hasColumn("schema_migrations", "checksum")
+	if err != nil {
+		return err
+	}
+	if !hasChecksums {
+		_, err := s.conn().Exec(`
+			ALTER TABLE schema_migrations ADD COLUMN checksum TEXT NOT NULL DEFAULT '';
+			ALTER TABLE schema_migrations ADD COLUMN source TEXT NOT NULL DEFAULT '';
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to add checksums to the migrations table: %w", err)
+		}
+	}
+	return nil
+}
+
+// hasColumn reports whether a table has the given column
+func (s *DatabaseStorage) hasColumn(table, column string) (bool, error) {
+	var count int
+	err := s.conn().QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to read the columns of %s: %w", table, err)
+	}
+	return count > 0, nil
+}
+
+// appliedMigrations returns the rows of schema_migrations by version. A
+// database last opened before checksums were kept has none to return.
+func (s *DatabaseStorage) appliedMigrations() (map[int]appliedMigration, error) {
+	columns := "version, applied_at, '', ''"
+	if hasChecksums, err := s.hasColumn("schema_migrations", "checksum"); err != nil {
+		return nil, err
+	} else if hasChecksums {
+		columns = "version, applied_at, checksum, source"
+	}
+
+	rows, err := s.conn().Query("SELECT " + columns + " FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedMigration)
+	for rows.Next() {
+		var version int
+		var appliedAt string
+		var row appliedMigration
+		if err := rows.Scan(&version, &appliedAt, &row.checksum, &row.source); err != nil {
+			return nil, fmt.Errorf("failed to scan migration version: %w", err)
+		}
+		row.appliedAt, _ = parseTimestamp(appliedAt)
+		applied[version] = row
+	}
+	return applied, rows.Err()
+}
+
+// verifyMigrations checks the applied migrations against their checksums.
+// Only a checksum taken from the same source, the files or the built-in
+// copies, can be compared. Migrations applied before checksums were kept get
+// the checksum of their SQL as it is now.
+func (s *DatabaseStorage) verifyMigrations(migrations []migration, applied map[int]appliedMigration) error {
+	for _, mg := range migrations {
+		row, ok := applied[mg.version]
+		if !ok {
+			continue
+		}
+
+		if row.checksum == "" {
+			_, err := s.conn().Exec("UPDATE schema_migrations SET checksum = ?, source = ? WHERE version = ?",
+				mg.checksum(), mg.source, mg.version)
+			if err != nil {
+				return fmt.Errorf("failed to record the checksum of migration %03d: %w", mg.version, err)
+			}
+			continue
+		}
+		if row.source == mg.source && row.checksum != mg.checksum() {
+			return fmt.Errorf("migration %03d has been modified since it was applied (%s); restore it as it was, or add the change as a new migration", mg.version, mg.name)
+		}
+	}
+	return nil
+}
+
+// applyMigration runs a migration and records it as applied in one transaction
+func (s *DatabaseStorage) applyMigration(mg migration) error {
+	tx, err := s.begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %s: %w", mg.name, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(mg.sql); err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", mg.name, err)
+	}
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version, checksum, source) VALUES (?, ?, ?)",
+		mg.version, mg.checksum(), mg.source); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", mg.name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", mg.name, err)
+	}
+	return nil
+}
+
+// MigrationStatus returns every migration known to this binary or applied to
+// the database, in version order, without applying any
+func (s *DatabaseStorage) MigrationStatus() ([]MigrationState, error) {
+	migrations, err := availableMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]appliedMigration)
+	var exists int
+	if err := s.conn().QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'").Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look for the migrations table: %w", err)
+	}
+	if exists > 0 {
+		if applied, err = s.appliedMigrations(); err != nil {
+			return nil, err
+		}
+	}
+
+	var states []MigrationState
+	known := make(map[int]bool, len(migrations))
+	for _, mg := range migrations {
+		known[mg.version] = true
+		state := MigrationState{Version: mg.version, Name: mg.name}
+		if row, ok := applied[mg.version]; ok {
+			state.Applied = true
+			state.AppliedAt = row.appliedAt
+			state.Checksum = row.checksum
+			state.Modified = row.checksum != "" && row.source == mg.source && row.checksum != mg.checksum()
+		}
+		states = append(states, state)
+	}
+	for version, row := range applied {
+		if !known[version] {
+			// Applied by a newer version of LazyTodo
+			states = append(states, MigrationState{Version: version, Name: "unknown", Applied: true, AppliedAt: row.appliedAt, Checksum: row.checksum})
+		}
+	}
+	slices.SortFunc(states, func(a, b MigrationState) int { return a.Version - b.Version })
+	return states, nil
+}
@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// Kinds of a PlannedChange
+const (
+	PlanAdd = iota
+	PlanUpdate
+)
+
+// PlannedChange is one list, task, template or setting an import would add or change
+type PlannedChange struct {
+	Kind    int
+	What    string // "list", "task", "template" or "setting"
+	Name    string // Name of the list or template, title of the task or key of the setting
+	List    string // List a task is in
+	Details string // What changes, e.g. "deadline, priority", or why to look twice
+}
+
+// ImportPlan describes what merging incoming data would change, so it can be
+// previewed before anything is written
+type ImportPlan struct {
+	Result       MergeResult     // The counts the merge would report
+	TasksSkipped int             // Known tasks left as they are: unchanged, or not edited after the existing copy
+	TasksTrashed int             // Deleted tasks the incoming data brings along, which go to the trash
+	Settings     int             // Settings that would change
+	Changes      []PlannedChange // Lists, then tasks, templates and settings
+}
+
+// Changed reports whether applying the plan would change anything
+func (p ImportPlan) Changed() bool {
+	return len(p.Changes) > 0 || p.TasksTrashed > 0
+}
+
+// Summary counts the changes, e.g. "2 lists added, 4 tasks added, 1 settings changed"
+func (p ImportPlan) Summary() string {
+	var parts []string
+	if p.Result.Changed() {
+		parts = append(parts, p.Result.String())
+	}
+	add := func(count int, what string) {
+		if count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, what))
+		}
+	}
+	add(p.TasksTrashed, "deleted tasks kept in the trash")
+	add(p.Settings, "settings changed")
+	if len(parts) == 0 {
+		parts = append(parts, "no changes")
+	}
+	add(p.TasksSkipped, "tasks unchanged")
+	return strings.Join(parts, ", ")
+}
+
+// PlanImport works out what merging incoming into existing would change,
+// following the same rules as Merge; the tasks of every existing list must be
+// loaded. A list named like an existing one shows its tasks as added to that
+// list, which the merge puts them in. Settings are compared only when incoming
+// carries any, which exports don't.
+func PlanImport(existing, incoming *models.Application) ImportPlan {
+	merge := planMerge(existing, incoming)
+	plan := ImportPlan{Result: merge.result(), TasksTrashed: len(incoming.Trash)}
+
+	tasks := make(map[string]models.Task)
+	for _, list := range existing.TodoLists {
+		for _, task := range list.Tasks {
+			tasks[task.ID] = task
+		}
+	}
+
+	newLists := make(map[string]bool)
+	for _, list := range merge.newLists {
+		newLists[list.ID] = true
+		plan.Changes = append(plan.Changes, PlannedChange{Kind: PlanAdd, What: "list", Name: list.Name, Details: taskCount(len(list.Tasks))})
+	}
+	for _, list := range merge.updatedLists {
+		plan.Changes = append(plan.Changes, PlannedChange{Kind: PlanUpdate, What: "list", Name: list.Name})
+	}
+
+	updated := make(map[string]bool)
+	for _, entry := range merge.newTasks {
+		plan.Changes = append(plan.Changes, PlannedChange{Kind: PlanAdd, What: "task", Name: entry.Task.Title, List: entry.ListName})
+	}
+	for _, entry := range merge.updatedTasks {
+		updated[entry.Task.ID] = true
+		plan.Changes = append(plan.Changes, PlannedChange{Kind: PlanUpdate, What: "task", Name: entry.Task.Title, List: entry.ListName,
+			Details: strings.Join(taskChanges(tasks[entry.Task.ID], entry.Task), ", ")})
+	}
+	for _, list := range incoming.TodoLists {
+		if newLists[list.ID] {
+			continue
+		}
+		for _, task := range list.Tasks {
+			if _, known := tasks[task.ID]; known && !updated[task.ID] {
+				plan.TasksSkipped++
+			}
+		}
+	}
+
+	for _, template := range merge.newTemplates {
+		plan.Changes = append(plan.Changes, PlannedChange{Kind: PlanAdd, What: "template", Name: template.Name})
+	}
+
+	if incoming.Settings != (models.Settings{}) {
+		current, wanted := settingsValues(existing.Settings), settingsValues(incoming.Settings)
+		keys := make([]string, 0, len(wanted))
+		for key := range wanted {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if current[key] != wanted[key] {
+				plan.Settings++
+				plan.Changes = append(plan.Changes, PlannedChange{Kind: PlanUpdate, What: "setting", Name: key,
+					Details: fmt.Sprintf("%q → %q", current[key], wanted[key])})
+			}
+		}
+	}
+
+	return plan
+}
+
+// PlanMigration works out what migrating the v1.x JSON file would add to the
+// database, without creating or changing it. An existing database is opened
+// read-only to compare against.
+func PlanMigration(opts Options) (ImportPlan, error) {
+	data, err := os.ReadFile(LegacyJSONPath())
+	if err != nil {
+		return ImportPlan{}, fmt.Errorf("failed to read JSON file: %w", err)
+	}
+	var jsonApp models.Application
+	if err := json.Unmarshal(data, &jsonApp); err != nil {
+		return ImportPlan{}, fmt.Errorf("failed to parse JSON data: %w", err)
+	}
+	jsonApp.Normalize()
+
+	existing := &models.Application{Settings: models.DefaultSettings()}
+	if databaseExists() {
+		opts.ReadOnly = true
+		dbStorage, err := NewDatabase(opts)
+		if err != nil {
+			return ImportPlan{}, err
+		}
+		defer dbStorage.Close()
+		if existing, err = loadEverything(dbStorage); err != nil {
+			return ImportPlan{}, err
+		}
+	}
+	return PlanImport(existing, &jsonApp), nil
+}
+
+// LoadForPreview loads everything in storage, the tasks of every list
+// included, without changing anything: storage is opened read-only, and while
+// there is no data yet an empty application with the default settings stands
+// in for it
+func LoadForPreview(opts Options) (*models.Application, error) {
+	if !HasLegacyJSON() && !databaseExists() {
+		return &models.Application{Settings: models.DefaultSettings()}, nil
+	}
+
+	opts.ReadOnly = true
+	storage, err := NewWithMigration(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer storage.Close()
+	return loadEverything(storage)
+}
+
+// loadEverything loads the application data with the tasks of every list
+func loadEverything(storage StorageInterface) (*models.Application, error) {
+	app, err := storage.Load()
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range app.TodoLists {
+		if err := storage.LoadTasks(app, list.ID); err != nil {
+			return nil, err
+		}
+	}
+	return app, nil
+}
+
+// listNameKey is the form of a list name that duplicates are compared in
+func listNameKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// taskCount describes a number of tasks, e.g. "1 task" or "3 tasks"
+func taskCount(n int) string {
+	if n == 1 {
+		return "1 task"
+	}
+	return fmt.Sprintf("%d tasks", n)
+}
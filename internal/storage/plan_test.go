@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// planFixture returns data with a Work list holding one task with a
+// deadline, and an empty Home list
+func planFixture() *models.Application {
+	created := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	deadline := time.Date(2026, time.March, 13, 17, 0, 0, 0, time.UTC)
+	return &models.Application{
+		Settings: models.DefaultSettings(),
+		TodoLists: []models.TodoList{
+			{ID: "work", Name: "Work", CreatedAt: created, UpdatedAt: created, Tasks: []models.Task{
+				{ID: "report", Title: "Write report", Priority: models.High, Deadline: &deadline, CreatedAt: created, UpdatedAt: created},
+			}},
+			{ID: "home", Name: "Home", CreatedAt: created, UpdatedAt: created},
+		},
+	}
+}
+
+// describeChanges renders the changes of a plan as the preview lists them
+func describeChanges(plan ImportPlan) []string {
+	var lines []string
+	for _, change := range plan.Changes {
+		line := "+ "
+		if change.Kind == PlanUpdate {
+			line = "~ "
+		}
+		line += change.What + " " + change.Name
+		if change.List != "" {
+			line += " in " + change.List
+		}
+		if change.Details != "" {
+			line += " (" + change.Details + ")"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestPlanImport(t *testing.T) {
+	later := time.Date(2026, time.March, 5, 9, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name    string
+		edit    func(incoming *models.Application) // Changes a copy of the fixture
+		changes []string
+		result  MergeResult
+		skipped int
+	}{
+		{
+			name: "new list",
+			edit: func(incoming *models.Application) {
+				incoming.TodoLists = append(incoming.TodoLists, models.TodoList{ID: "errands", Name: "Errands", UpdatedAt: later,
+					Tasks: []models.Task{{ID: "milk", Title: "Buy milk", UpdatedAt: later}}})
+			},
+			changes: []string{"+ list Errands (1 task)"},
+			result:  MergeResult{ListsAdded: 1, TasksAdded: 1},
+			skipped: 1,
+		},
+		{
+			name: "duplicate list name",
+			edit: func(incoming *models.Application) {
+				incoming.TodoLists = append(incoming.TodoLists, models.TodoList{ID: "other-work", Name: " work ", UpdatedAt: later,
+					Tasks: []models.Task{{ID: "slides", Title: "Make slides", UpdatedAt: later}, incoming.TodoLists[0].Tasks[0]}})
+			},
+			changes: []string{"+ task Make slides in Work"},
+			result:  MergeResult{TasksAdded: 1},
+			skipped: 2,
+		},
+		{
+			name: "two new lists of one name",
+			edit: func(incoming *models.Application) {
+				incoming.TodoLists = append(incoming.TodoLists,
+					models.TodoList{ID: "errands-1", Name: "Errands", Tasks: []models.Task{{ID: "milk", Title: "Buy milk"}}},
+					models.TodoList{ID: "errands-2", Name: "ERRANDS", Tasks: []models.Task{{ID: "bread", Title: "Buy bread"}}})
+			},
+			changes: []string{"+ list Errands (2 tasks)"},
+			result:  MergeResult{ListsAdded: 1, TasksAdded: 2},
+			skipped: 1,
+		},
+		{
+			name: "only the deadline changed",
+			edit: func(incoming *models.Application) {
+				task := &incoming.TodoLists[0].Tasks[0]
+				moved := task.Deadline.AddDate(0, 0, 1)
+				task.Deadline, task.UpdatedAt = &moved, later
+			},
+			changes: []string{"~ task Write report in Work (deadline)"},
+			result:  MergeResult{TasksUpdated: 1},
+		},
+		{
+			name: "deadline removed",
+			edit: func(incoming *models.Application) {
+				task := &incoming.TodoLists[0].Tasks[0]
+				task.Deadline, task.UpdatedAt = nil, later
+			},
+			changes: []string{"~ task Write report in Work (deadline)"},
+			result:  MergeResult{TasksUpdated: 1},
+		},
+		{
+			name: "unchanged task saved again",
+			edit: func(incoming *models.Application) {
+				incoming.TodoLists[0].Tasks[0].UpdatedAt = later
+			},
+			skipped: 1,
+		},
+		{
+			name: "edit older than the existing copy",
+			edit: func(incoming *models.Application) {
+				task := &incoming.TodoLists[0].Tasks[0]
+				task.Title, task.UpdatedAt = "Old title", task.UpdatedAt.Add(-time.Hour)
+			},
+			skipped: 1,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			incoming := planFixture()
+			incoming.Settings = models.Settings{} // Exports carry no settings
+			tt.edit(incoming)
+
+			plan := PlanImport(planFixture(), incoming)
+			if got := describeChanges(plan); !slices.Equal(got, tt.changes) {
+				t.Errorf("changes = %q, want %q", got, tt.changes)
+			}
+			if plan.Result != tt.result {
+				t.Errorf("result = %+v, want %+v", plan.Result, tt.result)
+			}
+			if plan.TasksSkipped != tt.skipped {
+				t.Errorf("%d tasks skipped, want %d", plan.TasksSkipped, tt.skipped)
+			}
+			if plan.Changed() != (len(tt.changes) > 0) {
+				t.Errorf("Changed() = %v with changes %q", plan.Changed(), tt.changes)
+			}
+		})
+	}
+}
+
+func TestMergeJoinsListsOfTheSameName(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store StorageInterface, app *models.Application) {
+		work := mustCreateList(t, store, app, "Work")
+		mustCreateTask(t, store, app, work, "Write report", nil)
+		incoming := &models.Application{TodoLists: []models.TodoList{
+			{ID: "from-another-machine", Name: "work", Tasks: []models.Task{{ID: "slides", Title: "Make slides"}}},
+		}}
+
+		plan := PlanImport(app, incoming)
+		result, err := store.Merge(app, incoming)
+		if err != nil {
+			t.Fatalf("Merge: %v", err)
+		}
+		if result != plan.Result {
+			t.Errorf("Merge = %+v, but the plan said %+v", result, plan.Result)
+		}
+
+		reloaded := mustReload(t, store, app)
+		loadAllTasks(t, store, reloaded)
+		if names := listNames(reloaded); names != "Work" {
+			t.Fatalf("lists = %s, want only Work", names)
+		}
+		var titles []string
+		for _, task := range reloaded.TodoLists[0].Tasks {
+			titles = append(titles, task.Title)
+		}
+		if got := strings.Join(titles, ", "); got != "Write report, Make slides" {
+			t.Errorf("tasks of Work = %s, want both", got)
+		}
+	})
+}
+
+func TestPlanMigrationOnEmptyDatabase(t *testing.T) {
+	SetDataDir(t.TempDir())
+	t.Cleanup(func() { SetDataDir("") })
+
+	legacy := planFixture()
+	legacy.Settings.ReminderMinutes = 15
+	data, err := json.Marshal(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(LegacyJSONPath(), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := PlanMigration(Options{Output: io.Discard})
+	if err != nil {
+		t.Fatalf("PlanMigration: %v", err)
+	}
+	want := []string{"+ list Work (1 task)", "+ list Home (0 tasks)",
+		fmt.Sprintf("~ setting reminder_minutes (%q → %q)", "60", "15")}
+	if got := describeChanges(plan); !slices.Equal(got, want) {
+		t.Errorf("changes = %q, want %q", got, want)
+	}
+	if plan.Result != (MergeResult{ListsAdded: 2, TasksAdded: 1}) || plan.Settings != 1 {
+		t.Errorf("result = %+v with %d settings, want 2 lists, 1 task and 1 setting", plan.Result, plan.Settings)
+	}
+	if databaseExists() {
+		t.Error("planning the migration created the database")
+	}
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	// ProfilesDir holds one data directory per profile inside the data directory
+	ProfilesDir = "profiles"
+
+	// DefaultProfile is the profile kept in the data directory itself, where
+	// LazyTodo kept its data before there were profiles
+	DefaultProfile = "default"
+)
+
+// ErrInvalidProfile is returned for a profile name that is not a plain directory name
+var ErrInvalidProfile = errors.New("profile names may only hold letters, digits, - and _")
+
+// activeProfile is the profile chosen with SetProfile; empty is the default one
+var activeProfile string
+
+// ValidateProfile checks that name can name a profile's directory
+func ValidateProfile(name string) error {
+	if name == "" || len(name) > 64 {
+		return fmt.Errorf("%w: %q", ErrInvalidProfile, name)
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return fmt.Errorf("%w: %q", ErrInvalidProfile, name)
+		}
+	}
+	return nil
+}
+
+// SetProfile makes every backend, lock and backup of the process use the data
+// directory of the profile name, profiles/<name> inside the data directory.
+// An empty name or DefaultProfile goes back to the data directory itself.
+func SetProfile(name string) error {
+	if name == "" || name == DefaultProfile {
+		activeProfile = ""
+		return nil
+	}
+	if err := ValidateProfile(name); err != nil {
+		return err
+	}
+	activeProfile = name
+	return nil
+}
+
+// Profile returns the name of the profile in use
+func Profile() string {
+	if activeProfile == "" {
+		return DefaultProfile
+	}
+	return activeProfile
+}
+
+// Profiles returns the default profile followed by the others that have a
+// data directory, in name order
+func Profiles() []string {
+	var names []string
+	entries, _ := os.ReadDir(filepath.Join(baseDataDir(io.Discard), ProfilesDir))
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != DefaultProfile && ValidateProfile(entry.Name()) == nil {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...)
+}
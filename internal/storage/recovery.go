@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// ErrDatabaseCorrupt is returned by NewDatabase when the database file is
+// damaged or is not a SQLite database at all; SetAsideDatabase moves it out
+// of the way so that a fresh one is created
+var ErrDatabaseCorrupt = errors.New("the database file is damaged or is not a SQLite database")
+
+// ErrDatabaseLocked is returned by NewDatabase when another program keeps the
+// database locked for longer than opening it waits
+var ErrDatabaseLocked = errors.New("the database is locked by another program; close other LazyTodo windows using it and try again")
+
+// checkDatabase reads the database through once, so that a damaged file is
+// reported when it is opened rather than by whichever query runs into it first
+func (s *DatabaseStorage) checkDatabase() error {
+	var result string
+	if err := s.db.QueryRow("PRAGMA quick_check(1)").Scan(&result); err != nil {
+		return classifyOpenError(s.dataPath, err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("%w: %s: %s", ErrDatabaseCorrupt, s.dataPath, result)
+	}
+	return nil
+}
+
+// classifyOpenError wraps err, met while opening the database at path, in
+// ErrDatabaseCorrupt or ErrDatabaseLocked when SQLite says it is one of those
+func classifyOpenError(path string, err error) error {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+	switch sqliteErr.Code {
+	case sqlite3.ErrNotADB, sqlite3.ErrCorrupt:
+		return fmt.Errorf("%w: %s: %v", ErrDatabaseCorrupt, path, err)
+	case sqlite3.ErrBusy, sqlite3.ErrLocked:
+		return fmt.Errorf("%w: %s: %v", ErrDatabaseLocked, path, err)
+	}
+	return err
+}
+
+// SetAsideDatabase renames a damaged database to lazytodo.db.corrupt.<date>,
+// together with the journal files SQLite keeps next to it and the operations
+// journal of the last session, which cannot be replayed onto an empty
+// database. The next start then creates a fresh database. It returns the new
+// name of the database file.
+func SetAsideDatabase() (string, error) {
+	dataPath := filepath.Join(resolveDataDir(io.Discard), DatabaseName)
+	return setAsideDatabase(dataPath + ".corrupt." + time.Now().Format("20060102-150405"))
+}
+
+// setAsideDatabase renames the database of the data directory to asidePath,
+// together with its journal files and the operations journal
+func setAsideDatabase(asidePath string) (string, error) {
+	dataDir := resolveDataDir(io.Discard)
+	dataPath := filepath.Join(dataDir, DatabaseName)
+
+	if err := os.Rename(dataPath, asidePath); err != nil {
+		return "", fmt.Errorf("failed to move the database aside: %w", err)
+	}
+
+	companions := map[string]string{
+		dataPath + "-wal":                   asidePath + "-wal",
+		dataPath + "-shm":                   asidePath + "-shm",
+		dataPath + "-journal":               asidePath + "-journal",
+		filepath.Join(dataDir, JournalName): asidePath + "." + JournalName,
+	}
+	for from, to := range companions {
+		if err := os.Rename(from, to); err != nil && !os.IsNotExist(err) {
+			return asidePath, fmt.Errorf("failed to move %s aside: %w", filepath.Base(from), err)
+		}
+	}
+	return asidePath, nil
+}
+
+// Backups returns the JSON copies of the data in the data directory, newest
+// first: the v1.x file kept by the migration and the JSON backend's backup.
+// `lazytodo --import` merges one of them into the database.
+func Backups() []string {
+	matches, _ := filepath.Glob(filepath.Join(resolveDataDir(io.Discard), DataFileName+".backup*"))
+
+	modified := make(map[string]time.Time, len(matches))
+	for _, path := range matches {
+		if info, err := os.Stat(path); err == nil {
+			modified[path] = info.ModTime()
+		}
+	}
+	slices.SortFunc(matches, func(a, b string) int {
+		return modified[b].Compare(modified[a])
+	})
+	return matches
+}
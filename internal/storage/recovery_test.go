@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// damagedDatabase writes a database holding a few lists and overwrites part
+// of it, as a disk failure would, and returns its path
+func damagedDatabase(t *testing.T) string {
+	t.Helper()
+	store := reopenBackend(t, "database")
+	app, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for _, name := range []string{"Work", "Home", "Errands"} {
+		listID := mustCreateList(t, store, app, name)
+		for range 50 {
+			mustCreateTask(t, store, app, listID, strings.Repeat(name, 20), nil)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	path := filepath.Join(resolveDataDir(io.Discard), DatabaseName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading the database: %v", err)
+	}
+	// Wreck the last pages, which hold tasks that opening the file never reads
+	copy(data[len(data)-8192:], bytes.Repeat([]byte{0xA5}, 8192))
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("damaging the database: %v", err)
+	}
+	return path
+}
+
+func TestNewDatabaseReportsCorruptFiles(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		damage func(t *testing.T) string
+	}{
+		{"not a database", func(t *testing.T) string {
+			path := filepath.Join(resolveDataDir(io.Discard), DatabaseName)
+			if err := os.WriteFile(path, bytes.Repeat([]byte("not sqlite\n"), 1000), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			return path
+		}},
+		{"damaged pages", damagedDatabase},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			SetDataDir(t.TempDir())
+			t.Cleanup(func() { SetDataDir("") })
+			tt.damage(t)
+
+			store, err := NewDatabase(Options{Output: io.Discard})
+			if err == nil {
+				store.Close()
+				t.Fatal("NewDatabase opened the damaged file")
+			}
+			if !errors.Is(err, ErrDatabaseCorrupt) {
+				t.Fatalf("NewDatabase: %v, want ErrDatabaseCorrupt", err)
+			}
+		})
+	}
+}
+
+func TestSetAsideDatabaseStartsFresh(t *testing.T) {
+	SetDataDir(t.TempDir())
+	t.Cleanup(func() { SetDataDir("") })
+	path := damagedDatabase(t)
+	damaged, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	journal := filepath.Join(resolveDataDir(io.Discard), JournalName)
+	if err := os.WriteFile(journal, []byte("{}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+"-wal", []byte("wal"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	asidePath, err := SetAsideDatabase()
+	if err != nil {
+		t.Fatalf("SetAsideDatabase: %v", err)
+	}
+	if kept, err := os.ReadFile(asidePath); err != nil || !bytes.Equal(kept, damaged) {
+		t.Errorf("the damaged database was not kept as %s: %v", asidePath, err)
+	}
+	for _, moved := range []string{path, path + "-wal", journal} {
+		if _, err := os.Stat(moved); !os.IsNotExist(err) {
+			t.Errorf("%s is still in the data directory: %v", filepath.Base(moved), err)
+		}
+	}
+	for _, kept := range []string{asidePath + "-wal", asidePath + "." + JournalName} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Errorf("%s was not set aside: %v", filepath.Base(kept), err)
+		}
+	}
+
+	store := reopenBackend(t, "database")
+	defer store.Close()
+	app, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(app.TodoLists) != 0 {
+		t.Errorf("the fresh database holds %d lists", len(app.TodoLists))
+	}
+}
+
+func TestClassifyOpenError(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want error
+	}{
+		{sqlite3.Error{Code: sqlite3.ErrNotADB}, ErrDatabaseCorrupt},
+		{sqlite3.Error{Code: sqlite3.ErrCorrupt}, ErrDatabaseCorrupt},
+		{sqlite3.Error{Code: sqlite3.ErrBusy}, ErrDatabaseLocked},
+		{sqlite3.Error{Code: sqlite3.ErrLocked}, ErrDatabaseLocked},
+	} {
+		if got := classifyOpenError("lazytodo.db", tt.err); !errors.Is(got, tt.want) {
+			t.Errorf("classifyOpenError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+	other := sqlite3.Error{Code: sqlite3.ErrPerm}
+	if got := classifyOpenError("lazytodo.db", other); got != error(other) {
+		t.Errorf("classifyOpenError(%v) = %v, want it unchanged", other, got)
+	}
+}
@@ -3,8 +3,11 @@ package storage
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/DhirajZope/lazytodo/internal/models"
@@ -15,30 +18,97 @@ const (
 	DataDir      = ".lazytodo"
 )
 
+// HomeEnv overrides the data directory; it is used as-is instead of ~/.lazytodo
+const HomeEnv = "LAZYTODO_HOME"
+
+// dataDirOverride is the data directory chosen with SetDataDir
+var dataDirOverride string
+
+// SetDataDir makes every backend, lock and backup of the process use dir as
+// the data directory, ahead of $LAZYTODO_HOME; an empty dir goes back to the
+// usual choice
+func SetDataDir(dir string) {
+	dataDirOverride = dir
+}
+
 // Storage handles data persistence
 type Storage struct {
 	dataPath string
+	readOnly bool
+	out      io.Writer
+
+	// Set on the copy withIDs returns; see journal.go
+	assigned *idQueue
 }
 
 // New creates a new Storage instance
-func New() (*Storage, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user home directory: %w", err)
-	}
-
-	dataDir := filepath.Join(homeDir, DataDir)
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create data directory: %w", err)
+func New(opts Options) (*Storage, error) {
+	dataDir := resolveDataDir(opts.output())
+	readOnly := opts.ReadOnly
+	if !readOnly && !prepareDataDir(dataDir) {
+		fmt.Fprintf(opts.output(), "Warning: data directory %s is not writable, opening read-only\n", dataDir)
+		readOnly = true
 	}
 
 	dataPath := filepath.Join(dataDir, DataFileName)
 
 	return &Storage{
 		dataPath: dataPath,
+		readOnly: readOnly,
+		out:      opts.output(),
 	}, nil
 }
 
+// resolveDataDir picks the data directory of the profile in use: the base
+// data directory for the default profile, profiles/<name> inside it otherwise
+func resolveDataDir(warn io.Writer) string {
+	dataDir := baseDataDir(warn)
+	if activeProfile != "" {
+		return filepath.Join(dataDir, ProfilesDir, activeProfile)
+	}
+	return dataDir
+}
+
+// baseDataDir picks the base data directory: the one set with SetDataDir, then
+// $LAZYTODO_HOME, ~/.lazytodo, the user config directory and finally the temp
+// directory. Falling back past the home directory is reported on warn, since
+// data may not persist there.
+func baseDataDir(warn io.Writer) string {
+	if dataDirOverride != "" {
+		return dataDirOverride
+	}
+	if dir := os.Getenv(HomeEnv); dir != "" {
+		return dir
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err == nil {
+		return filepath.Join(homeDir, DataDir)
+	}
+
+	dataDir := filepath.Join(os.TempDir(), "lazytodo")
+	if configDir, configErr := os.UserConfigDir(); configErr == nil {
+		dataDir = filepath.Join(configDir, "lazytodo")
+	}
+	fmt.Fprintf(warn, "Warning: %v; storing data in %s (set %s to choose a location)\n", err, dataDir, HomeEnv)
+	return dataDir
+}
+
+// prepareDataDir creates the data directory if needed and reports whether it is writable
+func prepareDataDir(dataDir string) bool {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return false
+	}
+
+	probe, err := os.CreateTemp(dataDir, ".write-test-*")
+	if err != nil {
+		return false
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return true
+}
+
 // Load loads the application data from file
 func (s *Storage) Load() (*models.Application, error) {
 	// Check if file exists
@@ -55,14 +125,20 @@ func (s *Storage) Load() (*models.Application, error) {
 		return nil, fmt.Errorf("failed to read data file: %w", err)
 	}
 
-	var app models.Application
+	// Settings the file leaves out keep their defaults, but a stored zero stays
+	app := models.Application{Settings: models.DefaultSettings()}
 	if err := json.Unmarshal(data, &app); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal data: %w", err)
 	}
+	// Pinned lists come first, as the database loads them
+	sort.SliceStable(app.TodoLists, func(i, j int) bool { return app.TodoLists[i].Pinned && !app.TodoLists[j].Pinned })
 
-	// Ensure settings have default values if missing
-	if app.Settings.ReminderMinutes == 0 {
-		app.Settings = models.DefaultSettings()
+	// An empty icon set or date format means the default one
+	if app.Settings.Icons == "" {
+		app.Settings.Icons = models.DefaultSettings().Icons
+	}
+	if app.Settings.DateFormat == "" {
+		app.Settings.DateFormat = models.DefaultSettings().DateFormat
 	}
 
 	return &app, nil
@@ -70,6 +146,10 @@ func (s *Storage) Load() (*models.Application, error) {
 
 // Save saves the application data to file
 func (s *Storage) Save(app *models.Application) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
 	data, err := json.MarshalIndent(app, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal data: %w", err)
@@ -80,7 +160,7 @@ func (s *Storage) Save(app *models.Application) error {
 		backupPath := s.dataPath + ".backup"
 		if err := s.copyFile(s.dataPath, backupPath); err != nil {
 			// Log warning but don't fail the save operation
-			fmt.Printf("Warning: failed to create backup: %v\n", err)
+			fmt.Fprintf(s.out, "Warning: failed to create backup: %v\n", err)
 		}
 	}
 
@@ -105,28 +185,125 @@ func (s *Storage) GetDataPath() string {
 	return s.dataPath
 }
 
+// IsReadOnly reports whether mutations are disabled
+func (s *Storage) IsReadOnly() bool {
+	return s.readOnly
+}
+
+// DefersWrites reports true: changes are made to app and written by Save
+func (s *Storage) DefersWrites() bool {
+	return true
+}
+
 // CreateTodoList creates a new todo list
-func (s *Storage) CreateTodoList(app *models.Application, name, description string) string {
-	id := generateID()
+func (s *Storage) CreateTodoList(app *models.Application, name, description, color string) (string, error) {
+	if s.readOnly {
+		return "", ErrReadOnly
+	}
+	name, err := models.NormalizeTitle(name)
+	if err != nil {
+		return "", err
+	}
+	description = models.NormalizeText(description)
+	if err := checkListName(app, name, ""); err != nil {
+		return "", err
+	}
+
+	id := s.newID()
 	newList := models.TodoList{
 		ID:          id,
 		Name:        name,
 		Description: description,
+		Color:       color,
 		Tasks:       []models.Task{},
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
 	app.TodoLists = append(app.TodoLists, newList)
-	return id
+	return id, nil
+}
+
+// ReorderList moves a todo list by offset positions (negative moves it up)
+func (s *Storage) ReorderList(app *models.Application, listID string, offset int) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	reordered, err := moveList(app.TodoLists, listID, offset)
+	if err != nil {
+		return err
+	}
+
+	app.TodoLists = reordered
+	return nil
 }
 
 // UpdateTodoList updates an existing todo list
-func (s *Storage) UpdateTodoList(app *models.Application, listID, name, description string) error {
+func (s *Storage) UpdateTodoList(app *models.Application, listID, name, description, color string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+	name, err := models.NormalizeTitle(name)
+	if err != nil {
+		return err
+	}
+	description = models.NormalizeText(description)
+	if err := checkListName(app, name, listID); err != nil {
+		return err
+	}
+
 	for i := range app.TodoLists {
 		if app.TodoLists[i].ID == listID {
 			app.TodoLists[i].Name = name
 			app.TodoLists[i].Description = description
+			app.TodoLists[i].Color = color
+			app.TodoLists[i].UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return fmt.Errorf("todo list with ID %s not found", listID)
+}
+
+// SetListGrouping sets how the tasks of a todo list are divided into sections.
+// It is a view preference, so the list does not count as updated for merges.
+func (s *Storage) SetListGrouping(app *models.Application, listID, grouping string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	todoList := findList(app, listID)
+	if todoList == nil {
+		return fmt.Errorf("todo list with ID %s not found", listID)
+	}
+	todoList.Grouping = grouping
+	return nil
+}
+
+// SetListPinned pins a todo list to the top of the sidebar or unpins it. Like
+// the order of the lists, it does not count as an update for merges.
+func (s *Storage) SetListPinned(app *models.Application, listID string, pinned bool) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	reordered, err := pinList(app.TodoLists, listID, pinned)
+	if err != nil {
+		return err
+	}
+	app.TodoLists = reordered
+	return nil
+}
+
+// SetListGroup files a todo list under a sidebar group; an empty group ungroups it
+func (s *Storage) SetListGroup(app *models.Application, listID, group string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	for i := range app.TodoLists {
+		if app.TodoLists[i].ID == listID {
+			app.TodoLists[i].Group = group
 			app.TodoLists[i].UpdatedAt = time.Now()
 			return nil
 		}
@@ -136,22 +313,122 @@ func (s *Storage) UpdateTodoList(app *models.Application, listID, name, descript
 
 // DeleteTodoList deletes a todo list
 func (s *Storage) DeleteTodoList(app *models.Application, listID string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
 	for i, list := range app.TodoLists {
 		if list.ID == listID {
 			app.TodoLists = append(app.TodoLists[:i], app.TodoLists[i+1:]...)
+
+			// The list's trashed tasks go with it, as in the database
+			trash := app.Trash[:0]
+			for _, entry := range app.Trash {
+				if entry.ListID != listID {
+					trash = append(trash, entry)
+				}
+			}
+			app.Trash = trash
 			return nil
 		}
 	}
 	return fmt.Errorf("todo list with ID %s not found", listID)
 }
 
-// CreateTask creates a new task in a todo list
-func (s *Storage) CreateTask(app *models.Application, listID, title, description string, priority models.Priority, deadline *time.Time) (string, error) {
+// CreateTemplate saves a named set of tasks as a template
+func (s *Storage) CreateTemplate(app *models.Application, name string, tasks []models.TemplateTask) (string, error) {
+	if s.readOnly {
+		return "", ErrReadOnly
+	}
+	name, err := models.NormalizeTitle(name)
+	if err != nil {
+		return "", err
+	}
+
+	id := s.newID()
+	app.Templates = append(app.Templates, models.Template{
+		ID:        id,
+		Name:      name,
+		Tasks:     append([]models.TemplateTask(nil), tasks...),
+		CreatedAt: time.Now(),
+	})
+	return id, nil
+}
+
+// RenameTemplate changes the name of a template
+func (s *Storage) RenameTemplate(app *models.Application, templateID, name string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+	name, err := models.NormalizeTitle(name)
+	if err != nil {
+		return err
+	}
+
+	template, err := findTemplate(app, templateID)
+	if err != nil {
+		return err
+	}
+	template.Name = name
+	return nil
+}
+
+// DeleteTemplate deletes a template
+func (s *Storage) DeleteTemplate(app *models.Application, templateID string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	for i, template := range app.Templates {
+		if template.ID == templateID {
+			app.Templates = append(app.Templates[:i], app.Templates[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("template with ID %s not found", templateID)
+}
+
+// CreateListFromTemplate creates a todo list holding the tasks of a template,
+// with deadlines resolved from today
+func (s *Storage) CreateListFromTemplate(app *models.Application, templateID, name, description, color string) (string, error) {
+	if s.readOnly {
+		return "", ErrReadOnly
+	}
+
+	template, err := findTemplate(app, templateID)
+	if err != nil {
+		return "", err
+	}
+
+	listID, err := s.CreateTodoList(app, name, description, color)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	for _, task := range template.Tasks {
+		if _, err := s.CreateTask(app, listID, task.Title, task.Description, task.Priority,
+			task.ResolveDeadline(now), task.Label, models.SourceTemplate); err != nil {
+			return "", err
+		}
+	}
+	return listID, nil
+}
+
+// CreateTask creates a new task in a todo list, recording where it was created
+func (s *Storage) CreateTask(app *models.Application, listID, title, description string, priority models.Priority, deadline *time.Time, label, source string) (models.Task, error) {
+	if s.readOnly {
+		return models.Task{}, ErrReadOnly
+	}
+	title, err := models.NormalizeTitle(title)
+	if err != nil {
+		return models.Task{}, err
+	}
+	description = models.NormalizeText(description)
+
 	for i := range app.TodoLists {
 		if app.TodoLists[i].ID == listID {
-			taskID := generateID()
 			newTask := models.Task{
-				ID:          taskID,
+				ID:          s.newID(),
 				Title:       title,
 				Description: description,
 				Completed:   false,
@@ -159,63 +436,209 @@ func (s *Storage) CreateTask(app *models.Application, listID, title, description
 				CreatedAt:   time.Now(),
 				UpdatedAt:   time.Now(),
 				Deadline:    deadline,
+				Label:       label,
+				Source:      source,
 			}
 
 			app.TodoLists[i].Tasks = append(app.TodoLists[i].Tasks, newTask)
 			app.TodoLists[i].UpdatedAt = time.Now()
-			return taskID, nil
+			return newTask, nil
 		}
 	}
-	return "", fmt.Errorf("todo list with ID %s not found", listID)
+	return models.Task{}, fmt.Errorf("todo list with ID %s not found", listID)
+}
+
+// DuplicateTask adds a copy of a task right after it
+func (s *Storage) DuplicateTask(app *models.Application, listID, taskID string) (models.Task, error) {
+	if s.readOnly {
+		return models.Task{}, ErrReadOnly
+	}
+
+	task, err := findTask(app, listID, taskID)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	duplicate := task.Duplicate(s.newID(), time.Now())
+	findList(app, listID).PutTaskAfter(duplicate, taskID)
+	return duplicate, nil
 }
 
 // UpdateTask updates an existing task
-func (s *Storage) UpdateTask(app *models.Application, listID, taskID, title, description string, priority models.Priority, deadline *time.Time) error {
+func (s *Storage) UpdateTask(app *models.Application, listID, taskID, title, description string, priority models.Priority, deadline *time.Time, label string) (models.Task, error) {
+	title, err := models.NormalizeTitle(title)
+	if err != nil {
+		return models.Task{}, err
+	}
+	description = models.NormalizeText(description)
+	return s.editTask(app, listID, taskID, func(task *models.Task) {
+		task.Title = title
+		task.Description = description
+		task.Priority = priority
+		task.Deadline = deadline
+		task.Label = label
+	})
+}
+
+// SnoozeTask moves the deadline of a task and counts the snooze
+func (s *Storage) SnoozeTask(app *models.Application, listID, taskID string, deadline time.Time) (models.Task, error) {
+	return s.editTask(app, listID, taskID, func(task *models.Task) {
+		task.Deadline = &deadline
+		task.SnoozeCount++
+	})
+}
+
+// UpdateTaskTime sets the estimate and time spent of a task
+func (s *Storage) UpdateTaskTime(app *models.Application, listID, taskID string, estimate, spent time.Duration) (models.Task, error) {
+	return s.editTask(app, listID, taskID, func(task *models.Task) {
+		task.Estimate = estimate
+		task.Spent = spent
+	})
+}
+
+// SetTaskLink sets the URL or file reference of a task; an empty link removes it
+func (s *Storage) SetTaskLink(app *models.Application, listID, taskID, link string) (models.Task, error) {
+	return s.editTask(app, listID, taskID, func(task *models.Task) {
+		task.Link = link
+	})
+}
+
+// ShiftDeadlines moves the deadline of every incomplete task in a list by delta
+func (s *Storage) ShiftDeadlines(app *models.Application, listID string, delta time.Duration) (int, error) {
+	if s.readOnly {
+		return 0, ErrReadOnly
+	}
+
 	for i := range app.TodoLists {
-		if app.TodoLists[i].ID == listID {
-			for j := range app.TodoLists[i].Tasks {
-				if app.TodoLists[i].Tasks[j].ID == taskID {
-					app.TodoLists[i].Tasks[j].Title = title
-					app.TodoLists[i].Tasks[j].Description = description
-					app.TodoLists[i].Tasks[j].Priority = priority
-					app.TodoLists[i].Tasks[j].Deadline = deadline
-					app.TodoLists[i].Tasks[j].UpdatedAt = time.Now()
-					app.TodoLists[i].UpdatedAt = time.Now()
-					return nil
-				}
+		if app.TodoLists[i].ID != listID {
+			continue
+		}
+
+		shifted := 0
+		for j := range app.TodoLists[i].Tasks {
+			task := &app.TodoLists[i].Tasks[j]
+			if task.Completed || task.Deadline == nil {
+				continue
 			}
-			return fmt.Errorf("task with ID %s not found in list %s", taskID, listID)
+			deadline := task.Deadline.Add(delta)
+			task.Deadline = &deadline
+			task.UpdatedAt = time.Now()
+			shifted++
+		}
+		if shifted > 0 {
+			app.TodoLists[i].UpdatedAt = time.Now()
 		}
+		return shifted, nil
 	}
-	return fmt.Errorf("todo list with ID %s not found", listID)
+	return 0, fmt.Errorf("todo list with ID %s not found", listID)
+}
+
+// SetTaskReminder sets how long before its deadline a task is reminded about;
+// a nil offset goes back to the global setting
+func (s *Storage) SetTaskReminder(app *models.Application, listID, taskID string, offset *time.Duration) (models.Task, error) {
+	return s.editTask(app, listID, taskID, func(task *models.Task) {
+		task.ReminderOffset = offset
+	})
+}
+
+// SetTaskProgress sets how far a task is done, in percent
+func (s *Storage) SetTaskProgress(app *models.Application, listID, taskID string, progress int) (models.Task, error) {
+	return s.editTask(app, listID, taskID, func(task *models.Task) {
+		task.Progress = models.ClampProgress(progress)
+	})
+}
+
+// SetTaskStarred stars a task for the daily plan or unstars it
+func (s *Storage) SetTaskStarred(app *models.Application, listID, taskID string, starred bool) (models.Task, error) {
+	return s.editTask(app, listID, taskID, func(task *models.Task) {
+		task.Starred = starred
+	})
+}
+
+// SetTasksPriority sets the priority of several tasks of a list
+func (s *Storage) SetTasksPriority(app *models.Application, listID string, taskIDs []string, priority models.Priority) ([]models.Task, error) {
+	return s.editTasks(app, listID, taskIDs, func(task *models.Task) {
+		task.Priority = priority
+	})
+}
+
+// SetTasksDeadline sets or, with a nil deadline, clears the deadline of several tasks of a list
+func (s *Storage) SetTasksDeadline(app *models.Application, listID string, taskIDs []string, deadline *time.Time) ([]models.Task, error) {
+	return s.editTasks(app, listID, taskIDs, func(task *models.Task) {
+		task.Deadline = deadline
+	})
 }
 
 // ToggleTask toggles the completion status of a task
-func (s *Storage) ToggleTask(app *models.Application, listID, taskID string) error {
+func (s *Storage) ToggleTask(app *models.Application, listID, taskID string) (models.Task, error) {
+	return s.editTask(app, listID, taskID, func(task *models.Task) {
+		task.Completed = !task.Completed
+		task.CompletedAt = nil
+		if task.Completed {
+			now := time.Now()
+			task.CompletedAt = &now
+		}
+	})
+}
+
+// editTask applies edit to a task in app, marks it and its list updated and
+// returns the edited task
+func (s *Storage) editTask(app *models.Application, listID, taskID string, edit func(task *models.Task)) (models.Task, error) {
+	if s.readOnly {
+		return models.Task{}, ErrReadOnly
+	}
+
+	task, err := findTask(app, listID, taskID)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	edit(task)
+	task.UpdatedAt = time.Now()
 	for i := range app.TodoLists {
 		if app.TodoLists[i].ID == listID {
-			for j := range app.TodoLists[i].Tasks {
-				if app.TodoLists[i].Tasks[j].ID == taskID {
-					app.TodoLists[i].Tasks[j].Completed = !app.TodoLists[i].Tasks[j].Completed
-					app.TodoLists[i].Tasks[j].UpdatedAt = time.Now()
-					app.TodoLists[i].UpdatedAt = time.Now()
-					return nil
-				}
-			}
-			return fmt.Errorf("task with ID %s not found in list %s", taskID, listID)
+			app.TodoLists[i].UpdatedAt = time.Now()
 		}
 	}
-	return fmt.Errorf("todo list with ID %s not found", listID)
+	return *task, nil
+}
+
+// editTasks is editTask for several tasks of a list; when one of them is
+// missing none are edited
+func (s *Storage) editTasks(app *models.Application, listID string, taskIDs []string, edit func(task *models.Task)) ([]models.Task, error) {
+	for _, taskID := range taskIDs {
+		if _, err := findTask(app, listID, taskID); err != nil {
+			return nil, err
+		}
+	}
+
+	tasks := make([]models.Task, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		task, err := s.editTask(app, listID, taskID, edit)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
 }
 
 // DeleteTask deletes a task from a todo list
 func (s *Storage) DeleteTask(app *models.Application, listID, taskID string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
 	for i := range app.TodoLists {
 		if app.TodoLists[i].ID == listID {
 			for j, task := range app.TodoLists[i].Tasks {
 				if task.ID == taskID {
 					app.TodoLists[i].Tasks = append(app.TodoLists[i].Tasks[:j], app.TodoLists[i].Tasks[j+1:]...)
 					app.TodoLists[i].UpdatedAt = time.Now()
+
+					now := time.Now()
+					task.DeletedAt = &now
+					app.Trash = append(app.Trash, models.ListTask{ListID: listID, ListName: app.TodoLists[i].Name, Task: task})
 					return nil
 				}
 			}
@@ -225,12 +648,552 @@ func (s *Storage) DeleteTask(app *models.Application, listID, taskID string) err
 	return fmt.Errorf("todo list with ID %s not found", listID)
 }
 
+// TrashedTasks returns the tasks in the trash, most recently deleted first
+func (s *Storage) TrashedTasks(app *models.Application) ([]models.ListTask, error) {
+	trash := make([]models.ListTask, 0, len(app.Trash))
+	for _, entry := range app.Trash {
+		// The list may have been renamed since
+		for _, list := range app.TodoLists {
+			if list.ID == entry.ListID {
+				entry.ListName = list.Name
+			}
+		}
+		trash = append(trash, entry)
+	}
+	sort.SliceStable(trash, func(i, j int) bool {
+		return trash[i].Task.DeletedAt.After(*trash[j].Task.DeletedAt)
+	})
+	return trash, nil
+}
+
+// RestoreTask moves a task from the trash back into its list
+func (s *Storage) RestoreTask(app *models.Application, listID, taskID string) (models.Task, error) {
+	if s.readOnly {
+		return models.Task{}, ErrReadOnly
+	}
+
+	for i, entry := range app.Trash {
+		if entry.ListID != listID || entry.Task.ID != taskID {
+			continue
+		}
+		for j := range app.TodoLists {
+			if app.TodoLists[j].ID == listID {
+				task := entry.Task
+				task.DeletedAt = nil
+				app.TodoLists[j].Tasks = append(app.TodoLists[j].Tasks, task)
+				app.TodoLists[j].UpdatedAt = time.Now()
+				app.Trash = append(app.Trash[:i], app.Trash[i+1:]...)
+				return task, nil
+			}
+		}
+		return models.Task{}, fmt.Errorf("todo list with ID %s not found", listID)
+	}
+	return models.Task{}, fmt.Errorf("task with ID %s is not in the trash", taskID)
+}
+
+// PurgeTrash permanently deletes the tasks that were moved to the trash
+// before the given time and returns how many there were
+func (s *Storage) PurgeTrash(app *models.Application, before time.Time) (int, error) {
+	if s.readOnly {
+		return 0, ErrReadOnly
+	}
+
+	kept := app.Trash[:0]
+	for _, entry := range app.Trash {
+		if entry.Task.DeletedAt == nil || !entry.Task.DeletedAt.Before(before) {
+			kept = append(kept, entry)
+		}
+	}
+	purged := len(app.Trash) - len(kept)
+	app.Trash = kept
+	return purged, nil
+}
+
+// AddNote appends a note to a task
+func (s *Storage) AddNote(app *models.Application, listID, taskID, body string) (string, error) {
+	if s.readOnly {
+		return "", ErrReadOnly
+	}
+	body = models.NormalizeText(body)
+
+	task, err := findTask(app, listID, taskID)
+	if err != nil {
+		return "", err
+	}
+
+	noteID := s.newID()
+	task.Notes = append(task.Notes, models.Note{
+		ID:        noteID,
+		TaskID:    taskID,
+		Body:      body,
+		CreatedAt: time.Now(),
+	})
+	return noteID, nil
+}
+
+// DeleteNote removes a note from a task
+func (s *Storage) DeleteNote(app *models.Application, listID, taskID, noteID string) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	task, err := findTask(app, listID, taskID)
+	if err != nil {
+		return err
+	}
+
+	for i, note := range task.Notes {
+		if note.ID == noteID {
+			task.Notes = append(task.Notes[:i], task.Notes[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("note with ID %s not found on task %s", noteID, taskID)
+}
+
+// ListNotes returns the notes of a task in the order they were added
+func (s *Storage) ListNotes(app *models.Application, listID, taskID string) ([]models.Note, error) {
+	task, err := findTask(app, listID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	notes := make([]models.Note, len(task.Notes))
+	copy(notes, task.Notes)
+	return notes, nil
+}
+
+// LoadTasks is a no-op for file storage, which loads every task up front
+func (s *Storage) LoadTasks(app *models.Application, listID string) error {
+	return nil
+}
+
+// DueTasks returns incomplete tasks whose reminder is due at now
+func (s *Storage) DueTasks(app *models.Application, now time.Time, defaultLead time.Duration) ([]models.Task, error) {
+	var tasks []models.Task
+	for _, list := range app.TodoLists {
+		for _, task := range list.Tasks {
+			if !task.Completed && reminderDue(&task, now, defaultLead) {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	return tasks, nil
+}
+
+// reminderDue reports whether now falls between a task's reminder time and its deadline
+func reminderDue(task *models.Task, now time.Time, defaultLead time.Duration) bool {
+	now = models.WallClock(now)
+	if task.Deadline == nil || !task.Deadline.After(now) {
+		return false
+	}
+	return !task.Deadline.After(now.Add(task.ReminderLead(defaultLead)))
+}
+
+// CountOverdue returns the number of overdue tasks across all lists
+func (s *Storage) CountOverdue(app *models.Application) (int, error) {
+	tasks, err := s.OverdueTasks(app)
+	return len(tasks), err
+}
+
+// OverdueTasks returns the overdue tasks across all lists, oldest deadline first
+func (s *Storage) OverdueTasks(app *models.Application) ([]models.ListTask, error) {
+	var overdue []models.ListTask
+	for _, list := range app.TodoLists {
+		for _, task := range list.Tasks {
+			if task.IsOverdue() {
+				overdue = append(overdue, models.ListTask{ListID: list.ID, ListName: list.Name, Task: task})
+			}
+		}
+	}
+
+	sort.SliceStable(overdue, func(i, j int) bool {
+		return overdue[i].Task.Deadline.Before(*overdue[j].Task.Deadline)
+	})
+	return overdue, nil
+}
+
+// TasksDueBetween returns the incomplete tasks across all lists due from from
+// up to, but not including, to; earliest deadline first
+func (s *Storage) TasksDueBetween(app *models.Application, from, to time.Time) ([]models.ListTask, error) {
+	var due []models.ListTask
+	for _, list := range app.TodoLists {
+		for _, task := range list.Tasks {
+			if !task.Completed && task.Deadline != nil && !task.Deadline.Before(from) && task.Deadline.Before(to) {
+				due = append(due, models.ListTask{ListID: list.ID, ListName: list.Name, Task: task})
+			}
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].Task.Deadline.Before(*due[j].Task.Deadline)
+	})
+	return due, nil
+}
+
+// StarredTasks returns the starred tasks across all lists, open ones first,
+// then by deadline
+func (s *Storage) StarredTasks(app *models.Application) ([]models.ListTask, error) {
+	var starred []models.ListTask
+	for _, list := range app.TodoLists {
+		for _, task := range list.Tasks {
+			if task.Starred {
+				starred = append(starred, models.ListTask{ListID: list.ID, ListName: list.Name, Task: task})
+			}
+		}
+	}
+
+	sort.SliceStable(starred, func(i, j int) bool {
+		a, b := starred[i].Task, starred[j].Task
+		if a.Completed != b.Completed {
+			return !a.Completed
+		}
+		if (a.Deadline == nil) != (b.Deadline == nil) {
+			return a.Deadline != nil
+		}
+		return a.Deadline != nil && a.Deadline.Before(*b.Deadline)
+	})
+	return starred, nil
+}
+
+// ClearCompletedStars unstars the tasks across all lists completed before
+// the given time; tasks completed before completion times were kept count too
+func (s *Storage) ClearCompletedStars(app *models.Application, before time.Time) ([]models.ListTask, error) {
+	if s.readOnly {
+		return nil, ErrReadOnly
+	}
+
+	var cleared []models.ListTask
+	for _, list := range app.TodoLists {
+		for _, task := range list.Tasks {
+			if !task.Starred || !task.Completed || (task.CompletedAt != nil && !task.CompletedAt.Before(before)) {
+				continue
+			}
+			edited, err := s.editTask(app, list.ID, task.ID, func(task *models.Task) {
+				task.Starred = false
+			})
+			if err != nil {
+				return cleared, err
+			}
+			cleared = append(cleared, models.ListTask{ListID: list.ID, ListName: list.Name, Task: edited})
+		}
+	}
+	return cleared, nil
+}
+
+// TrashCompleted moves the tasks across all lists completed before the given
+// time to the trash. Tasks completed before completion times were kept are
+// left alone, since there is no telling how old they are.
+func (s *Storage) TrashCompleted(app *models.Application, before time.Time) ([]models.ListTask, error) {
+	if s.readOnly {
+		return nil, ErrReadOnly
+	}
+
+	var trashed []models.ListTask
+	for _, list := range app.TodoLists {
+		for _, task := range list.Tasks {
+			if task.Completed && task.CompletedAt != nil && task.CompletedAt.Before(before) {
+				trashed = append(trashed, models.ListTask{ListID: list.ID, ListName: list.Name, Task: task})
+			}
+		}
+	}
+	for i, entry := range trashed {
+		if err := s.DeleteTask(app, entry.ListID, entry.Task.ID); err != nil {
+			return trashed[:i], err
+		}
+	}
+	return trashed, nil
+}
+
+// WelcomeDigest returns the tasks that became overdue between since and now,
+// those due during the rest of now's day, and those completed since
+func (s *Storage) WelcomeDigest(app *models.Application, since, now time.Time) (models.Digest, error) {
+	wallSince, wallNow := models.WallClock(since), models.WallClock(now)
+	_, endOfDay := models.CalendarDay(wallNow)
+
+	var digest models.Digest
+	for _, list := range app.TodoLists {
+		for _, task := range list.Tasks {
+			entry := models.ListTask{ListID: list.ID, ListName: list.Name, Task: task}
+			switch {
+			case task.Completed:
+				if task.CompletedAt != nil && !task.CompletedAt.Before(since) {
+					digest.Completed = append(digest.Completed, entry)
+				}
+			case task.Deadline == nil:
+			case !task.Deadline.Before(wallSince) && task.Deadline.Before(wallNow):
+				digest.Overdue = append(digest.Overdue, entry)
+			case !task.Deadline.Before(wallNow) && task.Deadline.Before(endOfDay):
+				digest.DueToday = append(digest.DueToday, entry)
+			}
+		}
+	}
+
+	byDeadline := func(tasks []models.ListTask) func(i, j int) bool {
+		return func(i, j int) bool { return tasks[i].Task.Deadline.Before(*tasks[j].Task.Deadline) }
+	}
+	sort.SliceStable(digest.Overdue, byDeadline(digest.Overdue))
+	sort.SliceStable(digest.DueToday, byDeadline(digest.DueToday))
+	sort.SliceStable(digest.Completed, func(i, j int) bool {
+		return digest.Completed[i].Task.CompletedAt.After(*digest.Completed[j].Task.CompletedAt)
+	})
+	return digest, nil
+}
+
+// CompletionTimes returns when each completed task was completed, across all lists and the trash
+func (s *Storage) CompletionTimes(app *models.Application) ([]time.Time, error) {
+	var times []time.Time
+	add := func(task *models.Task) {
+		if task.Completed && task.CompletedAt != nil {
+			times = append(times, *task.CompletedAt)
+		}
+	}
+	for i := range app.TodoLists {
+		for j := range app.TodoLists[i].Tasks {
+			add(&app.TodoLists[i].Tasks[j])
+		}
+	}
+	for i := range app.Trash {
+		add(&app.Trash[i].Task)
+	}
+	return times, nil
+}
+
+// CompletionCounts returns how many tasks were completed on each day from from up to to
+func (s *Storage) CompletionCounts(app *models.Application, from, to time.Time) (map[string]int, error) {
+	times, err := s.CompletionTimes(app)
+	if err != nil {
+		return nil, err
+	}
+	return models.CompletionCounts(times, from, to), nil
+}
+
+// RecentActivity returns the most recent changes across all lists, newest first
+func (s *Storage) RecentActivity(app *models.Application, limit int) ([]models.Activity, error) {
+	var activity []models.Activity
+	for _, list := range app.TodoLists {
+		activity = append(activity, listActivity(list.ID, list.Name, list.CreatedAt))
+		for _, task := range list.Tasks {
+			activity = append(activity, taskActivity(task, list.ID, list.Name))
+		}
+	}
+	return newestActivity(activity, limit), nil
+}
+
+// Merge merges incoming data into memory and saves the file
+func (s *Storage) Merge(app *models.Application, incoming *models.Application) (MergeResult, error) {
+	if s.readOnly {
+		return MergeResult{}, ErrReadOnly
+	}
+
+	plan := planMerge(app, incoming)
+	if !plan.result().Changed() {
+		return MergeResult{}, nil
+	}
+
+	for _, list := range plan.newLists {
+		if list.Tasks == nil {
+			list.Tasks = []models.Task{}
+		}
+		app.TodoLists = append(app.TodoLists, list)
+	}
+
+	for _, update := range plan.updatedLists {
+		for i := range app.TodoLists {
+			list := &app.TodoLists[i]
+			if list.ID == update.ID {
+				list.Name = update.Name
+				list.Description = update.Description
+				list.Color = update.Color
+				list.Group = update.Group
+				list.UpdatedAt = update.UpdatedAt
+			}
+		}
+	}
+
+	for _, entry := range plan.newTasks {
+		for i := range app.TodoLists {
+			if app.TodoLists[i].ID == entry.ListID {
+				app.TodoLists[i].Tasks = append(app.TodoLists[i].Tasks, entry.Task)
+			}
+		}
+	}
+
+	for _, entry := range plan.updatedTasks {
+		task, err := findTask(app, entry.ListID, entry.Task.ID)
+		if err != nil {
+			return MergeResult{}, err
+		}
+		notes := task.Notes
+		*task = entry.Task
+		task.Notes = notes
+	}
+
+	for _, note := range plan.newNotes {
+		for i := range app.TodoLists {
+			for j := range app.TodoLists[i].Tasks {
+				task := &app.TodoLists[i].Tasks[j]
+				if task.ID == note.TaskID {
+					task.Notes = append(task.Notes, note)
+				}
+			}
+		}
+	}
+
+	app.Templates = append(app.Templates, plan.newTemplates...)
+
+	if err := s.Save(app); err != nil {
+		return MergeResult{}, err
+	}
+	return plan.result(), nil
+}
+
 // Close is a no-op for file storage (satisfies StorageInterface)
 func (s *Storage) Close() error {
 	return nil
 }
 
-// generateID generates a simple unique ID
-func generateID() string {
-	return fmt.Sprintf("%d", time.Now().UnixNano())
+// listActivity describes the creation of a list
+func listActivity(listID, name string, createdAt time.Time) models.Activity {
+	return models.Activity{
+		Kind:     models.ListCreated,
+		ListID:   listID,
+		ListName: name,
+		Title:    name,
+		At:       createdAt,
+	}
+}
+
+// taskActivity derives the latest change of a task from its timestamps. A task
+// counts as edited once UpdatedAt is more than a second past CreatedAt, which
+// separates real edits from the two timestamps being set a moment apart.
+func taskActivity(task models.Task, listID, listName string) models.Activity {
+	activity := models.Activity{
+		Kind:     models.TaskCreated,
+		ListID:   listID,
+		ListName: listName,
+		TaskID:   task.ID,
+		Title:    task.Title,
+		At:       task.CreatedAt,
+	}
+
+	switch {
+	case task.Completed:
+		activity.Kind = models.TaskCompleted
+		activity.At = task.UpdatedAt
+	case task.UpdatedAt.Sub(task.CreatedAt) > time.Second:
+		activity.Kind = models.TaskEdited
+		activity.At = task.UpdatedAt
+	}
+
+	return activity
+}
+
+// newestActivity sorts activity newest first and keeps at most limit entries
+func newestActivity(activity []models.Activity, limit int) []models.Activity {
+	sort.SliceStable(activity, func(i, j int) bool {
+		return activity[i].At.After(activity[j].At)
+	})
+
+	if limit > 0 && len(activity) > limit {
+		activity = activity[:limit]
+	}
+	return activity
+}
+
+// moveList returns a copy of lists with the given list moved by offset
+// positions, clamped to the ends of the slice
+func moveList(lists []models.TodoList, listID string, offset int) ([]models.TodoList, error) {
+	from := -1
+	for i := range lists {
+		if lists[i].ID == listID {
+			from = i
+			break
+		}
+	}
+	if from == -1 {
+		return nil, fmt.Errorf("todo list with ID %s not found", listID)
+	}
+
+	to := from + offset
+	if to < 0 {
+		to = 0
+	}
+	if to > len(lists)-1 {
+		to = len(lists) - 1
+	}
+
+	reordered := make([]models.TodoList, 0, len(lists))
+	reordered = append(reordered, lists[:from]...)
+	reordered = append(reordered, lists[from+1:]...)
+	reordered = append(reordered[:to], append([]models.TodoList{lists[from]}, reordered[to:]...)...)
+	return reordered, nil
+}
+
+// pinList returns lists with the list listID pinned or unpinned and the
+// pinned lists moved ahead of the others, each keeping their order
+func pinList(lists []models.TodoList, listID string, pinned bool) ([]models.TodoList, error) {
+	reordered := slices.Clone(lists)
+	found := false
+	for i := range reordered {
+		if reordered[i].ID == listID {
+			reordered[i].Pinned = pinned
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("todo list with ID %s not found", listID)
+	}
+
+	sort.SliceStable(reordered, func(i, j int) bool { return reordered[i].Pinned && !reordered[j].Pinned })
+	return reordered, nil
+}
+
+// findList returns the in-memory list with the given ID, or nil
+func findList(app *models.Application, listID string) *models.TodoList {
+	for i := range app.TodoLists {
+		if app.TodoLists[i].ID == listID {
+			return &app.TodoLists[i]
+		}
+	}
+	return nil
+}
+
+// findTask locates a task inside the in-memory application state
+func findTask(app *models.Application, listID, taskID string) (*models.Task, error) {
+	for i := range app.TodoLists {
+		if app.TodoLists[i].ID == listID {
+			for j := range app.TodoLists[i].Tasks {
+				if app.TodoLists[i].Tasks[j].ID == taskID {
+					return &app.TodoLists[i].Tasks[j], nil
+				}
+			}
+			return nil, fmt.Errorf("task with ID %s not found in list %s", taskID, listID)
+		}
+	}
+	return nil, fmt.Errorf("todo list with ID %s not found", listID)
+}
+
+// findTemplate returns a pointer to the template with the given ID
+func findTemplate(app *models.Application, templateID string) (*models.Template, error) {
+	for i := range app.Templates {
+		if app.Templates[i].ID == templateID {
+			return &app.Templates[i], nil
+		}
+	}
+	return nil, fmt.Errorf("template with ID %s not found", templateID)
+}
+
+// newID returns the ID of a record the operation creates
+func (s *Storage) newID() string {
+	return s.assigned.next()
+}
+
+// withIDs returns a copy of s whose operation creates its records with the
+// IDs given, in order
+func (s *Storage) withIDs(ids []string) StorageInterface {
+	assigned := *s
+	assigned.assigned = &idQueue{ids: ids}
+	return &assigned
 }
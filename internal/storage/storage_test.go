@@ -0,0 +1,689 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// openBackend opens an empty store of the named backend, "json" or
+// "database", in a data directory of its own
+func openBackend(t *testing.T, backend string) StorageInterface {
+	t.Helper()
+	SetDataDir(t.TempDir())
+	t.Cleanup(func() { SetDataDir("") })
+
+	store := reopenBackend(t, backend)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// reopenBackend opens the store of the named backend in the data directory
+// openBackend chose, as a new session would; the caller closes it
+func reopenBackend(t testing.TB, backend string) StorageInterface {
+	t.Helper()
+	opts := Options{Output: io.Discard}
+	var store StorageInterface
+	var err error
+	if backend == "json" {
+		store, err = New(opts)
+	} else {
+		store, err = NewDatabase(opts)
+	}
+	if err != nil {
+		t.Fatalf("opening the %s backend: %v", backend, err)
+	}
+	return store
+}
+
+// forEachBackend runs test against an empty store and its loaded data, once
+// for the JSON file and once for the database
+func forEachBackend(t *testing.T, test func(t *testing.T, store StorageInterface, app *models.Application)) {
+	for _, backend := range []string{"json", "database"} {
+		t.Run(backend, func(t *testing.T) {
+			store := openBackend(t, backend)
+			app, err := store.Load()
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			test(t, store, app)
+		})
+	}
+}
+
+// mustCreateList creates a list called name and returns its ID
+func mustCreateList(t *testing.T, store StorageInterface, app *models.Application, name string) string {
+	t.Helper()
+	id, err := store.CreateTodoList(app, name, "", "")
+	if err != nil {
+		t.Fatalf("CreateTodoList(%q): %v", name, err)
+	}
+	return id
+}
+
+// mustCreateTask creates a task with a deadline, or none for nil, and puts it
+// in app as the UI does
+func mustCreateTask(t *testing.T, store StorageInterface, app *models.Application, listID, title string, deadline *time.Time) models.Task {
+	t.Helper()
+	task, err := store.CreateTask(app, listID, title, "", models.Medium, deadline, "", models.SourceTUI)
+	if err != nil {
+		t.Fatalf("CreateTask(%q): %v", title, err)
+	}
+	findList(app, listID).PutTask(task)
+	return task
+}
+
+// mustReload saves app, which the JSON file needs to hold the changes made,
+// and loads the data again
+func mustReload(t *testing.T, store StorageInterface, app *models.Application) *models.Application {
+	t.Helper()
+	if err := store.Save(app); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	reloaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return reloaded
+}
+
+// mustReopen saves app, closes the store and loads the data in a new
+// session of the same backend, which the test then goes on with
+func mustReopen(t *testing.T, store StorageInterface, app *models.Application) (StorageInterface, *models.Application) {
+	t.Helper()
+	if err := store.Save(app); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	backend := "json"
+	if unwrapDatabase(store) != nil {
+		backend = "database"
+	}
+	reopened := reopenBackend(t, backend)
+	t.Cleanup(func() { reopened.Close() })
+	reloaded, err := reopened.Load()
+	if err != nil {
+		t.Fatalf("Load after reopening: %v", err)
+	}
+	return reopened, reloaded
+}
+
+// inTimeZone runs the test with name as the local time zone
+func inTimeZone(t *testing.T, name string) {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", name, err)
+	}
+	local := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = local })
+}
+
+func taskIDs(entries []models.ListTask) []string {
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.Task.ID
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestOverdueCountsAgree(t *testing.T) {
+	for _, zone := range []string{"UTC", "America/New_York", "Asia/Kolkata"} {
+		t.Run(zone, func(t *testing.T) {
+			inTimeZone(t, zone)
+			forEachBackend(t, func(t *testing.T, store StorageInterface, app *models.Application) {
+				listID := mustCreateList(t, store, app, "Work")
+				now := time.Now()
+				wall := models.WallClock(now)
+				past, soon := wall.Add(-2*time.Hour), wall.Add(2*time.Hour)
+				overdue := mustCreateTask(t, store, app, listID, "Two hours ago", &past)
+				mustCreateTask(t, store, app, listID, "In two hours", &soon)
+				mustCreateTask(t, store, app, listID, "Whenever", nil)
+
+				want := []string{overdue.ID}
+				tasks, err := store.OverdueTasks(app)
+				if err != nil {
+					t.Fatalf("OverdueTasks: %v", err)
+				}
+				if got := taskIDs(tasks); !slices.Equal(got, want) {
+					t.Errorf("OverdueTasks = %v, want %v", got, want)
+				}
+				if count, err := store.CountOverdue(app); err != nil || count != 1 {
+					t.Errorf("CountOverdue = %d, %v; want 1", count, err)
+				}
+				digest, err := store.WelcomeDigest(app, now.Add(-24*time.Hour), now)
+				if err != nil {
+					t.Fatalf("WelcomeDigest: %v", err)
+				}
+				if got := taskIDs(digest.Overdue); !slices.Equal(got, want) {
+					t.Errorf("WelcomeDigest overdue = %v, want %v", got, want)
+				}
+
+				reloaded := mustReload(t, store, app)
+				if len(reloaded.TodoLists) != 1 {
+					t.Fatalf("reloaded %d lists, want 1", len(reloaded.TodoLists))
+				}
+				for _, list := range reloaded.TodoLists {
+					if overdue, _ := list.GetDeadlineCounts(time.Hour); overdue != 1 {
+						t.Errorf("list %s counts %d overdue, want 1", list.Name, overdue)
+					}
+				}
+				info, err := CollectInfo(store, now)
+				if err != nil {
+					t.Fatalf("CollectInfo: %v", err)
+				}
+				if info.Totals.Overdue != 1 {
+					t.Errorf("CollectInfo counts %d overdue, want 1", info.Totals.Overdue)
+				}
+			})
+		})
+	}
+}
+
+func TestUpdateTaskClearsDeadline(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store StorageInterface, app *models.Application) {
+		listID := mustCreateList(t, store, app, "Work")
+		deadline := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+		task := mustCreateTask(t, store, app, listID, "Write report", &deadline)
+
+		// An emptied deadline field gives no deadline
+		cleared, err := models.ParseDeadline("  ", models.DeadlineLayout)
+		if err != nil || cleared != nil {
+			t.Fatalf("ParseDeadline of an empty field = %v, %v; want nil", cleared, err)
+		}
+		updated, err := store.UpdateTask(app, listID, task.ID, task.Title, task.Description, task.Priority, cleared, task.Label)
+		if err != nil {
+			t.Fatalf("UpdateTask: %v", err)
+		}
+		if updated.Deadline != nil {
+			t.Errorf("updated task deadline = %v, want none", updated.Deadline)
+		}
+		findList(app, listID).PutTask(updated)
+
+		if db := unwrapDatabase(store); db != nil {
+			var isNull bool
+			if err := db.db.QueryRow("SELECT deadline IS NULL FROM tasks WHERE id = ?", task.ID).Scan(&isNull); err != nil {
+				t.Fatalf("reading the deadline column: %v", err)
+			}
+			if !isNull {
+				t.Error("deadline column is not NULL")
+			}
+		}
+
+		reloaded := mustReload(t, store, app)
+		if err := store.LoadTasks(reloaded, listID); err != nil {
+			t.Fatalf("LoadTasks: %v", err)
+		}
+		if got := findList(reloaded, listID).Tasks[0].Deadline; got != nil {
+			t.Errorf("reloaded task deadline = %v, want none", got)
+		}
+	})
+}
+
+// loadAllTasks loads the tasks of every list of app
+func loadAllTasks(t testing.TB, store StorageInterface, app *models.Application) {
+	t.Helper()
+	for _, list := range app.TodoLists {
+		if err := store.LoadTasks(app, list.ID); err != nil {
+			t.Fatalf("LoadTasks(%s): %v", list.Name, err)
+		}
+	}
+}
+
+func TestLoadKeepsTasksInTheirListsInOrder(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store StorageInterface, app *models.Application) {
+		names := []string{"Work", "Home", "Errands"}
+		var ids []string
+		for _, name := range names {
+			ids = append(ids, mustCreateList(t, store, app, name))
+		}
+		// Created across the lists in turn, so no list's tasks are contiguous
+		want := make(map[string][]string)
+		for i := range 12 {
+			listID := ids[i%len(ids)]
+			task := mustCreateTask(t, store, app, listID, fmt.Sprintf("Task %d", i), nil)
+			want[listID] = append(want[listID], task.ID)
+		}
+
+		reloaded := mustReload(t, store, app)
+		loadAllTasks(t, store, reloaded)
+		for i, list := range reloaded.TodoLists {
+			if list.Name != names[i] {
+				t.Errorf("list %d = %s, want %s", i, list.Name, names[i])
+			}
+			var got []string
+			for _, task := range list.Tasks {
+				got = append(got, task.ID)
+			}
+			if !slices.Equal(got, want[list.ID]) {
+				t.Errorf("tasks of %s = %v, want %v", list.Name, got, want[list.ID])
+			}
+		}
+	})
+}
+
+// benchmarkBackends runs bench for each backend on a store holding 200
+// lists of 50 tasks each, 10,000 tasks in all
+func benchmarkBackends(b *testing.B, bench func(b *testing.B, store StorageInterface)) {
+	for _, backend := range []string{"json", "database"} {
+		b.Run(backend, func(b *testing.B) {
+			SetDataDir(b.TempDir())
+			b.Cleanup(func() { SetDataDir("") })
+			store := reopenBackend(b, backend)
+			defer store.Close()
+
+			app, err := store.Load()
+			if err != nil {
+				b.Fatalf("Load: %v", err)
+			}
+			incoming := &models.Application{}
+			created := time.Now().UTC().Truncate(time.Second)
+			for i := range 200 {
+				list := models.TodoList{ID: fmt.Sprintf("list-%03d", i), Name: fmt.Sprintf("List %d", i), CreatedAt: created, UpdatedAt: created}
+				for j := range 50 {
+					list.Tasks = append(list.Tasks, models.Task{ID: fmt.Sprintf("task-%03d-%02d", i, j),
+						Title: fmt.Sprintf("Task %d", j), Priority: models.Medium, CreatedAt: created, UpdatedAt: created})
+				}
+				incoming.TodoLists = append(incoming.TodoLists, list)
+			}
+			if _, err := store.Merge(app, incoming); err != nil {
+				b.Fatalf("Merge: %v", err)
+			}
+			if err := store.Save(app); err != nil {
+				b.Fatalf("Save: %v", err)
+			}
+
+			b.ResetTimer()
+			bench(b, store)
+		})
+	}
+}
+
+// BenchmarkLoadMetadataOnly loads what the TUI shows at start: the database
+// reads the lists with their counts, the JSON file has to be read whole
+func BenchmarkLoadMetadataOnly(b *testing.B) {
+	benchmarkBackends(b, func(b *testing.B, store StorageInterface) {
+		for range b.N {
+			if _, err := store.Load(); err != nil {
+				b.Fatalf("Load: %v", err)
+			}
+		}
+	})
+}
+
+// BenchmarkLoadEager loads the data and then every list's tasks, as an
+// export does
+func BenchmarkLoadEager(b *testing.B) {
+	benchmarkBackends(b, func(b *testing.B, store StorageInterface) {
+		for range b.N {
+			loaded, err := store.Load()
+			if err != nil {
+				b.Fatalf("Load: %v", err)
+			}
+			loadAllTasks(b, store, loaded)
+		}
+	})
+}
+
+// listCounts sums up what the sidebar shows of a list
+type listCounts struct {
+	total, completed, overdue, dueSoon int
+	estimate, spent, remaining         time.Duration
+}
+
+func countsOf(list *models.TodoList, window time.Duration) listCounts {
+	c := listCounts{total: list.GetTotalCount(), completed: list.GetCompletedCount(), remaining: list.GetRemainingEstimate()}
+	c.overdue, c.dueSoon = list.GetDeadlineCounts(window)
+	c.estimate, c.spent = list.GetTimeTotals()
+	return c
+}
+
+func TestListSummariesMatchLoadedTasks(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store StorageInterface, app *models.Application) {
+		work := mustCreateList(t, store, app, "Work")
+		home := mustCreateList(t, store, app, "Home")
+		mustCreateTask(t, store, app, home, "Water plants", nil)
+
+		wall := models.WallClock(time.Now())
+		past, soon, later := wall.Add(-2*time.Hour), wall.Add(2*time.Hour), wall.AddDate(0, 0, 10)
+		mustCreateTask(t, store, app, work, "Overdue", &past)
+		mustCreateTask(t, store, app, work, "Due soon", &soon)
+		mustCreateTask(t, store, app, work, "Later", &later)
+		estimated := mustCreateTask(t, store, app, work, "Estimated", nil)
+		timed, err := store.UpdateTaskTime(app, work, estimated.ID, 2*time.Hour, 30*time.Minute)
+		if err != nil {
+			t.Fatalf("UpdateTaskTime: %v", err)
+		}
+		findList(app, work).PutTask(timed)
+		done := mustCreateTask(t, store, app, work, "Done", &past)
+		if timed, err = store.UpdateTaskTime(app, work, done.ID, time.Hour, 0); err != nil {
+			t.Fatalf("UpdateTaskTime: %v", err)
+		}
+		findList(app, work).PutTask(timed)
+		completed, err := store.ToggleTask(app, work, done.ID)
+		if err != nil {
+			t.Fatalf("ToggleTask: %v", err)
+		}
+		findList(app, work).PutTask(completed)
+		trashed := mustCreateTask(t, store, app, work, "Trashed", &past)
+		if err := store.DeleteTask(app, work, trashed.ID); err != nil {
+			t.Fatalf("DeleteTask: %v", err)
+		}
+		findList(app, work).RemoveTask(trashed.ID)
+
+		want := listCounts{total: 5, completed: 1, overdue: 1, dueSoon: 1,
+			estimate: 3 * time.Hour, spent: 30 * time.Minute, remaining: 2 * time.Hour}
+		window := app.Settings.DueSoonWindow()
+
+		reloaded := mustReload(t, store, app)
+		list := findList(reloaded, work)
+		lazy := unwrapDatabase(store) != nil
+		if list.TasksLoaded() == lazy {
+			t.Fatalf("tasks loaded at start = %v, want %v", list.TasksLoaded(), !lazy)
+		}
+		if got := countsOf(list, window); got != want {
+			t.Errorf("counts before loading the tasks = %+v, want %+v", got, want)
+		}
+
+		if err := store.LoadTasks(reloaded, work); err != nil {
+			t.Fatalf("LoadTasks: %v", err)
+		}
+		list = findList(reloaded, work)
+		if !list.TasksLoaded() || len(list.Tasks) != want.total {
+			t.Fatalf("after LoadTasks: loaded %v with %d tasks, want %d", list.TasksLoaded(), len(list.Tasks), want.total)
+		}
+		if got := countsOf(list, window); got != want {
+			t.Errorf("counts of the loaded tasks = %+v, want %+v", got, want)
+		}
+		if findList(reloaded, home).TasksLoaded() == lazy {
+			t.Error("loading the tasks of one list loaded another's")
+		}
+	})
+}
+
+// mustToggle completes or reopens a task and puts it in app
+func mustToggle(t *testing.T, store StorageInterface, app *models.Application, listID, taskID string) models.Task {
+	t.Helper()
+	task, err := store.ToggleTask(app, listID, taskID)
+	if err != nil {
+		t.Fatalf("ToggleTask: %v", err)
+	}
+	findList(app, listID).PutTask(task)
+	return task
+}
+
+func TestCompletionsCountTheTrashButNotReopenedTasks(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store StorageInterface, app *models.Application) {
+		listID := mustCreateList(t, store, app, "Work")
+		before := time.Now().Add(-time.Second)
+		done := mustCreateTask(t, store, app, listID, "Done", nil)
+		trashed := mustCreateTask(t, store, app, listID, "Done and trashed", nil)
+		reopened := mustCreateTask(t, store, app, listID, "Reopened", nil)
+		mustCreateTask(t, store, app, listID, "Open", nil)
+		for _, task := range []models.Task{done, trashed, reopened} {
+			mustToggle(t, store, app, listID, task.ID)
+		}
+		mustToggle(t, store, app, listID, reopened.ID)
+		if err := store.DeleteTask(app, listID, trashed.ID); err != nil {
+			t.Fatalf("DeleteTask: %v", err)
+		}
+		findList(app, listID).RemoveTask(trashed.ID)
+		after := time.Now().Add(time.Second)
+
+		times, err := store.CompletionTimes(app)
+		if err != nil {
+			t.Fatalf("CompletionTimes: %v", err)
+		}
+		if len(times) != 2 {
+			t.Fatalf("CompletionTimes() = %v, want 2 of them", times)
+		}
+		for _, completed := range times {
+			if completed.Before(before.Truncate(time.Second)) || completed.After(after) {
+				t.Errorf("completion at %s, want between %s and %s", completed, before, after)
+			}
+		}
+
+		today, tomorrow := models.CalendarDay(time.Now())
+		counts, err := store.CompletionCounts(app, today.AddDate(0, 0, -7), tomorrow)
+		if err != nil {
+			t.Fatalf("CompletionCounts: %v", err)
+		}
+		if got := counts[today.Format(models.StreakDayLayout)]; got != 2 {
+			t.Errorf("completions today = %d, want 2", got)
+		}
+		if streak, _ := models.CompletionStreak(times, time.Now()); streak != 1 {
+			t.Errorf("streak = %d, want 1", streak)
+		}
+	})
+}
+
+func TestPrioritiesOutOfRangeAreClamped(t *testing.T) {
+	store := openBackend(t, "database")
+	app, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	listID := mustCreateList(t, store, app, "Work")
+	low := mustCreateTask(t, store, app, listID, "Below the levels", nil)
+	high := mustCreateTask(t, store, app, listID, "Above the levels", nil)
+	db := unwrapDatabase(store).db
+	for id, priority := range map[string]int{low.ID: -1, high.ID: 9} {
+		if _, err := db.Exec("UPDATE tasks SET priority = ? WHERE id = ?", priority, id); err != nil {
+			t.Fatalf("setting priority %d: %v", priority, err)
+		}
+	}
+
+	want := map[string]models.Priority{low.ID: models.Low, high.ID: models.Critical}
+	reloaded := mustReload(t, store, app)
+	loadAllTasks(t, store, reloaded)
+	for _, task := range findList(reloaded, listID).Tasks {
+		if task.Priority != want[task.ID] {
+			t.Errorf("%s has priority %v, want %v", task.Title, task.Priority, want[task.ID])
+		}
+	}
+}
+
+// storedTask finds a task in the data loaded by a new session
+func storedTask(t *testing.T, store StorageInterface, app *models.Application, listID, taskID string) models.Task {
+	t.Helper()
+	reloaded := mustReload(t, store, app)
+	if err := store.LoadTasks(reloaded, listID); err != nil {
+		t.Fatalf("LoadTasks: %v", err)
+	}
+	for _, task := range findList(reloaded, listID).Tasks {
+		if task.ID == taskID {
+			return task
+		}
+	}
+	t.Fatalf("task %s is not stored", taskID)
+	return models.Task{}
+}
+
+// sameTask reports the fields in which a task returned by a mutation differs
+// from the stored one, comparing times as instants
+func sameTask(t *testing.T, op string, returned, stored models.Task) {
+	t.Helper()
+	encode := func(task models.Task) string {
+		task.Notes = nil
+		for _, at := range []*time.Time{&task.CreatedAt, &task.UpdatedAt} {
+			*at = at.UTC()
+		}
+		for _, at := range []**time.Time{&task.Deadline, &task.CompletedAt, &task.DeletedAt} {
+			if *at != nil {
+				utc := (*at).UTC()
+				*at = &utc
+			}
+		}
+		data, err := json.Marshal(task)
+		if err != nil {
+			t.Fatalf("encoding the task: %v", err)
+		}
+		return string(data)
+	}
+	if got, want := encode(returned), encode(stored); got != want {
+		t.Errorf("%s returned\n%s\nbut stored\n%s", op, got, want)
+	}
+}
+
+func TestTaskMutationsReturnTheStoredTask(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store StorageInterface, app *models.Application) {
+		listID := mustCreateList(t, store, app, "Work")
+		deadline := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+		created, err := store.CreateTask(app, listID, "Write report", "Quarterly", models.High, &deadline, "📝", models.SourceCLI)
+		if err != nil {
+			t.Fatalf("CreateTask: %v", err)
+		}
+		findList(app, listID).PutTask(created)
+		sameTask(t, "CreateTask", created, storedTask(t, store, app, listID, created.ID))
+
+		offset := 30 * time.Minute
+		later := deadline.Add(24 * time.Hour)
+		steps := []struct {
+			op     string
+			mutate func() (models.Task, error)
+		}{
+			{"UpdateTask", func() (models.Task, error) {
+				return store.UpdateTask(app, listID, created.ID, "Write the report", "", models.Critical, nil, "")
+			}},
+			{"SnoozeTask", func() (models.Task, error) { return store.SnoozeTask(app, listID, created.ID, later) }},
+			{"UpdateTaskTime", func() (models.Task, error) {
+				return store.UpdateTaskTime(app, listID, created.ID, 2*time.Hour, 45*time.Minute)
+			}},
+			{"SetTaskLink", func() (models.Task, error) {
+				return store.SetTaskLink(app, listID, created.ID, "https://example.com/report")
+			}},
+			{"SetTaskReminder", func() (models.Task, error) { return store.SetTaskReminder(app, listID, created.ID, &offset) }},
+			{"ToggleTask", func() (models.Task, error) { return store.ToggleTask(app, listID, created.ID) }},
+			{"ToggleTask again", func() (models.Task, error) { return store.ToggleTask(app, listID, created.ID) }},
+		}
+		for _, step := range steps {
+			returned, err := step.mutate()
+			if err != nil {
+				t.Fatalf("%s: %v", step.op, err)
+			}
+			findList(app, listID).PutTask(returned)
+			sameTask(t, step.op, returned, storedTask(t, store, app, listID, created.ID))
+		}
+
+		if err := store.DeleteTask(app, listID, created.ID); err != nil {
+			t.Fatalf("DeleteTask: %v", err)
+		}
+		findList(app, listID).RemoveTask(created.ID)
+		restored, err := store.RestoreTask(app, listID, created.ID)
+		if err != nil {
+			t.Fatalf("RestoreTask: %v", err)
+		}
+		findList(app, listID).PutTask(restored)
+		sameTask(t, "RestoreTask", restored, storedTask(t, store, app, listID, created.ID))
+	})
+}
+
+func TestDueSoonCountsFollowTheSetting(t *testing.T) {
+	want := map[int]int{-1: 0, 0: 0, 1: 0, 3: 1, 24: 1, math.MaxInt: 2}
+	got := make(map[string]map[int]int) // Due-soon counts by backend, then hours
+	forEachBackend(t, func(t *testing.T, store StorageInterface, app *models.Application) {
+		work := mustCreateList(t, store, app, "Work")
+		wall := models.WallClock(time.Now())
+		past, soon, later := wall.Add(-2*time.Hour), wall.Add(2*time.Hour), wall.AddDate(0, 0, 10)
+		mustCreateTask(t, store, app, work, "Overdue", &past)
+		mustCreateTask(t, store, app, work, "Due soon", &soon)
+		mustCreateTask(t, store, app, work, "Later", &later)
+
+		counts := make(map[int]int)
+		got[path.Base(t.Name())] = counts
+		for hours, dueSoon := range want {
+			app.Settings.DueSoonHours = hours
+			store, app = mustReopen(t, store, app)
+			if app.Settings.DueSoonHours != hours {
+				t.Fatalf("due_soon_hours read back as %d, want %d", app.Settings.DueSoonHours, hours)
+			}
+			overdue, gotDueSoon := findList(app, work).GetDeadlineCounts(app.Settings.DueSoonWindow())
+			counts[hours] = gotDueSoon
+			if overdue != 1 || gotDueSoon != dueSoon {
+				t.Errorf("with due_soon_hours %d: %d overdue and %d due soon, want 1 and %d", hours, overdue, gotDueSoon, dueSoon)
+			}
+		}
+	})
+	if !maps.Equal(got["json"], got["database"]) {
+		t.Errorf("due-soon counts by hours differ: %v from the JSON file, %v from the database", got["json"], got["database"])
+	}
+}
+
+func TestExportWritesPrioritiesAsTheUIShowsThem(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store StorageInterface, app *models.Application) {
+		listID := mustCreateList(t, store, app, "Work")
+		mustCreateTask(t, store, app, listID, "Medium task", nil)
+		if err := store.Save(app); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		data, err := Export(store)
+		if err != nil {
+			t.Fatalf("Export: %v", err)
+		}
+		if want := `"priority": "` + models.Medium.String() + `"`; !strings.Contains(string(data), want) {
+			t.Errorf("export does not hold %s:\n%s", want, data)
+		}
+
+		// Exports written before the names were capitalized still import
+		legacy := strings.ReplaceAll(string(data), `"Medium"`, `"medium"`)
+		parsed, err := ParseExport([]byte(legacy))
+		if err != nil {
+			t.Fatalf("ParseExport: %v", err)
+		}
+		if got := parsed.TodoLists[0].Tasks[0].Priority; got != models.Medium {
+			t.Errorf("a lowercase priority imports as %v, want Medium", got)
+		}
+	})
+}
+
+func TestZeroSettingsSurviveAReopen(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store StorageInterface, app *models.Application) {
+		app.Settings.ReminderMinutes = 0
+		app.Settings.TrashDays = 0
+		app.Settings.DayTaskLimit = 0
+		app.Settings.DueSoonHours = 0
+		app.Settings.ReviewHour = 0
+		app.Settings.StaleDays = 0
+		app.Settings.CriticalRepeatMinutes = 0
+		app.Settings.BulkConfirmThreshold = 0
+		app.Settings.WelcomeBackHours = 0
+		app.Settings.ShowCompleted = false
+		want := app.Settings
+
+		_, reloaded := mustReopen(t, store, app)
+		if reloaded.Settings != want {
+			t.Errorf("settings read back as\n%+v\nwant\n%+v", reloaded.Settings, want)
+		}
+	})
+}
+
+func TestMissingSettingsTakeTheirDefaults(t *testing.T) {
+	store := openBackend(t, "json")
+	data := `{"todo_lists": [], "settings": {"reminder_minutes": 15, "trash_days": 0}}`
+	if err := os.WriteFile(LegacyJSONPath(), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	app, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := models.DefaultSettings()
+	want.ReminderMinutes, want.TrashDays = 15, 0
+	if app.Settings != want {
+		t.Errorf("settings read as\n%+v\nwant\n%+v", app.Settings, want)
+	}
+}
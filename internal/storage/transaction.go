@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// dbConn runs statements, either straight on the database or inside a transaction
+type dbConn interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// dbTx is a transaction of a single operation: a transaction of its own, or a
+// savepoint when the operation runs inside WithTransaction
+type dbTx interface {
+	dbConn
+	Commit() error
+	Rollback() error
+}
+
+// conn returns what statements run on: the transaction of WithTransaction
+// while inside one, and the database otherwise
+func (s *DatabaseStorage) conn() dbConn {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.db
+}
+
+// begin starts the transaction of an operation. Inside WithTransaction it is a
+// savepoint, so that a failed operation still undoes only its own statements.
+func (s *DatabaseStorage) begin() (dbTx, error) {
+	if s.tx != nil {
+		if _, err := s.tx.Exec("SAVEPOINT operation"); err != nil {
+			return nil, err
+		}
+		return &savepoint{Tx: s.tx}, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// savepoint is an operation's part of the transaction of WithTransaction;
+// like sql.Tx, it can be ended only once, so a deferred Rollback after Commit
+// does nothing
+type savepoint struct {
+	*sql.Tx
+	done bool
+}
+
+// Commit keeps the operation's statements in the transaction
+func (sp *savepoint) Commit() error {
+	if sp.done {
+		return sql.ErrTxDone
+	}
+	sp.done = true
+	_, err := sp.Exec("RELEASE operation")
+	return err
+}
+
+// Rollback undoes the operation's statements and nothing else of the transaction
+func (sp *savepoint) Rollback() error {
+	if sp.done {
+		return sql.ErrTxDone
+	}
+	sp.done = true
+	if _, err := sp.Exec("ROLLBACK TO operation"); err != nil {
+		return err
+	}
+	_, err := sp.Exec("RELEASE operation")
+	return err
+}
+
+// WithTransaction runs fn with a storage whose operations all take effect
+// when fn returns nil and none of them when it returns an error, in which
+// case app is put back as it was too. A transaction inside another joins it.
+func (s *DatabaseStorage) WithTransaction(app *models.Application, fn func(tx StorageInterface) error) error {
+	if s.tx != nil {
+		return fn(s)
+	}
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	snapshot := snapshotApp(app)
+	txStorage := *s
+	txStorage.tx = tx
+	if err := fn(&txStorage); err != nil {
+		tx.Rollback()
+		*app = *snapshot
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		*app = *snapshot
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// WithTransaction runs fn like the database does. The JSON file is only
+// written on save, so a failed transaction puts app, which is the data, back
+// from a copy taken before fn.
+func (s *Storage) WithTransaction(app *models.Application, fn func(tx StorageInterface) error) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	snapshot := snapshotApp(app)
+	if err := fn(s); err != nil {
+		*app = *snapshot
+		return err
+	}
+	return nil
+}
+
+// snapshotApp copies app deep enough to be put back after a failed
+// transaction. Operations replace times and summaries rather than change
+// them in place, so those are shared with the copy.
+func snapshotApp(app *models.Application) *models.Application {
+	snapshot := *app
+	snapshot.TodoLists = slices.Clone(app.TodoLists)
+	for i := range snapshot.TodoLists {
+		snapshot.TodoLists[i].Tasks = snapshotTasks(snapshot.TodoLists[i].Tasks)
+	}
+	snapshot.Templates = slices.Clone(app.Templates)
+	for i := range snapshot.Templates {
+		snapshot.Templates[i].Tasks = slices.Clone(snapshot.Templates[i].Tasks)
+	}
+	snapshot.Trash = slices.Clone(app.Trash)
+	for i := range snapshot.Trash {
+		snapshot.Trash[i].Task.Notes = slices.Clone(snapshot.Trash[i].Task.Notes)
+	}
+	return &snapshot
+}
+
+// snapshotTasks copies tasks and their notes for snapshotApp
+func snapshotTasks(tasks []models.Task) []models.Task {
+	tasks = slices.Clone(tasks)
+	for i := range tasks {
+		tasks[i].Notes = slices.Clone(tasks[i].Notes)
+	}
+	return tasks
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// describeTasks returns the titles of a list's tasks, with a mark on the
+// completed ones, from app and from the data a new session loads
+func describeTasks(t *testing.T, store StorageInterface, app *models.Application, listID string) (inApp, stored string) {
+	t.Helper()
+	describe := func(app *models.Application) string {
+		var text string
+		for _, task := range findList(app, listID).Tasks {
+			if text != "" {
+				text += ", "
+			}
+			text += task.Title
+			if task.Completed {
+				text += " ✓"
+			}
+		}
+		return text
+	}
+	inApp = describe(app)
+	reloaded := mustReload(t, store, app)
+	if err := store.LoadTasks(reloaded, listID); err != nil {
+		t.Fatalf("LoadTasks: %v", err)
+	}
+	return inApp, describe(reloaded)
+}
+
+// listNames returns the names of app's lists, comma-separated
+func listNames(app *models.Application) string {
+	var names []string
+	for _, list := range app.TodoLists {
+		names = append(names, list.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// changeInTransaction adds a task and a list and edits and completes task,
+// all through tx and kept in app as the UI does, then returns result
+func changeInTransaction(t *testing.T, tx StorageInterface, app *models.Application, listID string, task models.Task, result error) error {
+	t.Helper()
+	mustCreateTask(t, tx, app, listID, "Added", nil)
+	edited, err := tx.UpdateTask(app, listID, task.ID, "Edited", "", task.Priority, nil, "")
+	if err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	findList(app, listID).PutTask(edited)
+	mustToggle(t, tx, app, listID, task.ID)
+	mustCreateList(t, tx, app, "Home")
+	return result
+}
+
+func TestWithTransaction(t *testing.T) {
+	rollBack := errors.New("roll back")
+	for _, tt := range []struct {
+		name  string
+		run   func(t *testing.T, store StorageInterface, app *models.Application, listID string, task models.Task) error
+		err   error
+		tasks string
+		lists string
+	}{
+		{"commit", func(t *testing.T, store StorageInterface, app *models.Application, listID string, task models.Task) error {
+			return store.WithTransaction(app, func(tx StorageInterface) error {
+				return changeInTransaction(t, tx, app, listID, task, nil)
+			})
+		}, nil, "Edited ✓, Added", "Work, Home"},
+		{"roll back", func(t *testing.T, store StorageInterface, app *models.Application, listID string, task models.Task) error {
+			return store.WithTransaction(app, func(tx StorageInterface) error {
+				return changeInTransaction(t, tx, app, listID, task, rollBack)
+			})
+		}, rollBack, "Write report", "Work"},
+		{"nested roll back", func(t *testing.T, store StorageInterface, app *models.Application, listID string, task models.Task) error {
+			return store.WithTransaction(app, func(tx StorageInterface) error {
+				mustCreateTask(t, tx, app, listID, "Outer", nil)
+				return tx.WithTransaction(app, func(inner StorageInterface) error {
+					return changeInTransaction(t, inner, app, listID, task, rollBack)
+				})
+			})
+		}, rollBack, "Write report", "Work"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachBackend(t, func(t *testing.T, store StorageInterface, app *models.Application) {
+				listID := mustCreateList(t, store, app, "Work")
+				task := mustCreateTask(t, store, app, listID, "Write report", nil)
+
+				if err := tt.run(t, store, app, listID, task); !errors.Is(err, tt.err) {
+					t.Fatalf("WithTransaction = %v, want %v", err, tt.err)
+				}
+				if lists := listNames(app); lists != tt.lists {
+					t.Errorf("lists in app = %s, want %s", lists, tt.lists)
+				}
+				inApp, stored := describeTasks(t, store, app, listID)
+				if inApp != tt.tasks || stored != tt.tasks {
+					t.Errorf("tasks in app = %s, stored = %s, want %s", inApp, stored, tt.tasks)
+				}
+				if lists := listNames(mustReload(t, store, app)); lists != tt.lists {
+					t.Errorf("stored lists = %s, want %s", lists, tt.lists)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// activityLogLimit is how many of the latest changes the activity log view reads
+const activityLogLimit = 500
+
+// openActivityLog shows the changes recorded in the activity log, newest first
+func (m *Model) openActivityLog() {
+	m.activityLogToday = false
+	if !m.loadActivityLog() {
+		return
+	}
+	m.state = ActivityLogView
+	m.layout.SetFocus(MainWindow)
+}
+
+// loadActivityLog reads the changes the view shows: today's, or the latest
+// ones when not filtered to today. It reports false, with the error shown,
+// when they cannot be read.
+func (m *Model) loadActivityLog() bool {
+	var since time.Time
+	if m.activityLogToday {
+		since, _ = models.CalendarDay(m.now())
+	}
+
+	changes, err := m.storage.ActivityLog(m.app, since, activityLogLimit)
+	if errors.Is(err, storage.ErrNoActivityLog) {
+		m.showMessageWithType("The activity log is only kept in the database", "info")
+		return false
+	}
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return false
+	}
+	m.activityLog = changes
+	m.activityLogCursor = 0
+	return true
+}
+
+// Activity log view - scroll through the changes made and jump to one
+func (m *Model) updateActivityLogView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = TasksView
+	case key.Matches(msg, m.keys.Up):
+		if m.activityLogCursor > 0 {
+			m.activityLogCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.activityLogCursor < len(m.activityLog)-1 {
+			m.activityLogCursor++
+		}
+	case key.Matches(msg, m.keys.Tab):
+		m.activityLogToday = !m.activityLogToday
+		m.loadActivityLog()
+	case key.Matches(msg, m.keys.Enter):
+		if m.activityLogCursor < len(m.activityLog) {
+			change := m.activityLog[m.activityLogCursor]
+			taskID := ""
+			if change.Entity == models.EntityTask {
+				taskID = change.EntityID
+			}
+			m.jumpToTask(change.ListID, taskID)
+		}
+	}
+	return m, nil
+}
+
+// changeActionStyle colors an activity log entry by what the change did
+func changeActionStyle(action models.ChangeAction) lipgloss.Style {
+	switch action {
+	case models.ActionCompleted:
+		return lipgloss.NewStyle().Foreground(AccentColor)
+	case models.ActionEdited, models.ActionMoved:
+		return lipgloss.NewStyle().Foreground(WarningColor)
+	case models.ActionDeleted:
+		return lipgloss.NewStyle().Foreground(ErrorColor)
+	case models.ActionCreated, models.ActionRestored:
+		return lipgloss.NewStyle().Foreground(InfoColor)
+	default:
+		return lipgloss.NewStyle().Foreground(TextSecondary)
+	}
+}
+
+// changeTime gives when a change was made: the time for today's changes and
+// the date as well for older ones
+func changeTime(at, now time.Time) string {
+	at = at.Local()
+	if today, _ := models.CalendarDay(now); !at.Before(today) {
+		return at.Format("15:04")
+	}
+	return at.Format("Jan 2 15:04")
+}
+
+// renderActivityLogContent renders the activity log, newest change first
+func (m *Model) renderActivityLogContent() string {
+	m.layout.SetWindowTitle(MainWindow, withIcon(icons.Activity, "Activity Log"))
+
+	heading := "Activity Log"
+	if m.activityLogToday {
+		heading = "Activity Log - Today"
+	}
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render(withIcon(icons.Activity, fmt.Sprintf("%s (%d)", heading, len(m.activityLog)))))
+	lines = append(lines, "")
+
+	if len(m.activityLog) == 0 {
+		if m.activityLogToday {
+			lines = append(lines, BaseSubtitleStyle.Render("Nothing has changed today"))
+		} else {
+			lines = append(lines, BaseSubtitleStyle.Render("No changes recorded yet"))
+		}
+	}
+
+	// Keep the cursor inside the rows that fit in the window
+	visible, width := 10, 60
+	if mainWindow := m.layout.GetWindow(MainWindow); mainWindow != nil {
+		visible = max(visible, mainWindow.Position.Height-10)
+		width = max(20, mainWindow.Position.Width-6)
+	}
+	start := 0
+	if m.activityLogCursor >= visible {
+		start = m.activityLogCursor - visible + 1
+	}
+	end := min(start+visible, len(m.activityLog))
+
+	now := m.now()
+	timeStyle := lipgloss.NewStyle().Foreground(TextMuted)
+	for i := start; i < end; i++ {
+		change := m.activityLog[i]
+		line := ansi.Truncate(changeActionStyle(change.Action).Render(change.Describe())+
+			timeStyle.Render(" — "+changeTime(change.At, now)), width, "…")
+
+		if i == m.activityLogCursor {
+			lines = append(lines, ListItemSelected.Render(line))
+		} else {
+			lines = append(lines, ListItemNormal.Render(line))
+		}
+	}
+
+	lines = append(lines, "")
+	filter := "Tab: today only"
+	if m.activityLogToday {
+		filter = "Tab: all changes"
+	}
+	lines = append(lines, DescStyle.Render("↑/↓: scroll • "+filter+" • Enter: jump to item • Esc: back to tasks"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,362 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// bulkConfirmChoices are the numbers of tasks a bulk completion may change
+// before it asks first the settings view cycles through; -1 never asks
+var bulkConfirmChoices = []int{-1, 1, 5, 10, 25, 50}
+
+// nextBulkConfirmThreshold returns the threshold step places away from
+// tasks; a threshold set outside the choices starts from the default
+func nextBulkConfirmThreshold(tasks, step int) int {
+	for i, choice := range bulkConfirmChoices {
+		if choice == tasks {
+			return bulkConfirmChoices[(i+step+len(bulkConfirmChoices))%len(bulkConfirmChoices)]
+		}
+	}
+	return models.DefaultSettings().BulkConfirmThreshold
+}
+
+// bulkConfirmLabel describes the threshold for the settings view
+func bulkConfirmLabel(tasks int) string {
+	switch {
+	case tasks < 0:
+		return "Never"
+	case tasks == 1:
+		return "for more than 1 task"
+	}
+	return fmt.Sprintf("for more than %d tasks", tasks)
+}
+
+// markedCount describes how many tasks a bulk change applies to
+func markedCount(n int) string {
+	if n == 1 {
+		return "1 marked task"
+	}
+	return fmt.Sprintf("%d marked tasks", n)
+}
+
+// toggleMark marks or unmarks the highlighted task and moves on to the next
+// one, so a run of tasks is marked by pressing the key repeatedly
+func (m *Model) toggleMark() {
+	item, ok := m.tasksList.SelectedItem().(taskItem)
+	if !ok {
+		return
+	}
+
+	if m.marked[item.id] {
+		delete(m.marked, item.id)
+	} else {
+		m.marked[item.id] = true
+	}
+	m.updateTasksList()
+	m.tasksList.CursorDown()
+	m.skipTaskHeader(1)
+
+	if len(m.marked) > 0 {
+		m.showMessageWithType(markedCount(len(m.marked))+" • space: complete • p: priority • D: deadline • Esc: clear", "info")
+	}
+}
+
+// clearMarks unmarks every task
+func (m *Model) clearMarks() {
+	clear(m.marked)
+	m.updateTasksList()
+	m.showMessageWithType("Marks cleared", "info")
+}
+
+// pruneMarks forgets marks of tasks that are no longer in todoList, e.g.
+// after they were deleted or moved
+func (m *Model) pruneMarks(todoList *models.TodoList) {
+	if len(m.marked) == 0 {
+		return
+	}
+	present := make(map[string]bool, len(todoList.Tasks))
+	for _, task := range todoList.Tasks {
+		present[task.ID] = true
+	}
+	for id := range m.marked {
+		if !present[id] {
+			delete(m.marked, id)
+		}
+	}
+}
+
+// bulkTargets returns the IDs of the marked tasks in list order, or the
+// highlighted task when none are marked
+func (m *Model) bulkTargets() []string {
+	if len(m.marked) == 0 {
+		if item, ok := m.tasksList.SelectedItem().(taskItem); ok {
+			return []string{item.id}
+		}
+		return nil
+	}
+
+	currentList := m.getCurrentList()
+	if currentList == nil {
+		return nil
+	}
+	var ids []string
+	for _, task := range currentList.Tasks {
+		if m.marked[task.ID] {
+			ids = append(ids, task.ID)
+		}
+	}
+	return ids
+}
+
+// openBulkDeadlinePrompt opens the deadline prompt for the marked tasks,
+// pre-filled with their deadline when they all share one
+func (m *Model) openBulkDeadlinePrompt() {
+	m.editingTaskID = ""
+	m.snoozing = false
+	m.bulkDeadline = true
+	m.deadlineInput.SetValue("")
+
+	var shared *time.Time
+	for i, id := range m.bulkTargets() {
+		task := m.getTask(id)
+		if task == nil {
+			continue
+		}
+		if i == 0 {
+			shared = task.Deadline
+		} else if !sameDeadline(shared, task.Deadline) {
+			shared = nil
+			break
+		}
+	}
+	if shared != nil {
+		m.deadlineInput.SetValue(formatDeadline(*shared))
+	}
+	m.deadlineInput.CursorEnd()
+	m.deadlineInput.Focus()
+	m.state = SetDeadlineView
+}
+
+// setMarkedDeadline gives every marked task deadline, or clears theirs when it is nil
+func (m *Model) setMarkedDeadline(deadline *time.Time) tea.Cmd {
+	ids := m.bulkTargets()
+	updated, err := m.storage.SetTasksDeadline(m.app, m.currentListID, ids, deadline)
+	m.bulkDeadline = false
+	m.state = TasksView
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return nil
+	}
+	for _, task := range updated {
+		m.putTask(m.currentListID, task)
+		// Remind again about the new deadline
+		delete(m.reminded, task.ID)
+	}
+
+	clear(m.marked)
+	m.updateTasksList()
+	if deadline == nil {
+		m.showMessageWithType(fmt.Sprintf("Deadline cleared for %d tasks", len(updated)), "success")
+	} else {
+		m.showDeadlineSaved(fmt.Sprintf("Deadline set to %s for %d tasks", formatDeadline(*deadline), len(updated)), deadline)
+	}
+	return m.saveData()
+}
+
+// completeMarked completes the marked tasks, or reopens them when every one
+// of them is completed already
+func (m *Model) completeMarked() tea.Cmd {
+	ids := m.bulkTargets()
+	completed := false
+	for _, id := range ids {
+		if task := m.getTask(id); task != nil && !task.Completed {
+			completed = true
+			break
+		}
+	}
+	return m.setTasksCompleted(ids, completed, "marked tasks")
+}
+
+// completeList completes every open task of the current list
+func (m *Model) completeList() tea.Cmd {
+	currentList := m.getCurrentList()
+	if currentList == nil {
+		m.showMessageWithType("Select a list first", "warning")
+		return nil
+	}
+	var ids []string
+	for _, task := range currentList.Tasks {
+		if !task.Completed {
+			ids = append(ids, task.ID)
+		}
+	}
+	if len(ids) == 0 {
+		m.showMessageWithType(fmt.Sprintf("Every task of '%s' is completed already", currentList.Name), "info")
+		return nil
+	}
+	return m.setTasksCompleted(ids, true, fmt.Sprintf("open tasks of '%s'", currentList.Name))
+}
+
+// setTasksCompleted completes, or reopens, the tasks with IDs ids of the
+// current list in one transaction. When that changes more tasks than the
+// bulk_confirm_threshold setting allows, it asks first in the confirmation
+// overlay, where what names the tasks after their number.
+func (m *Model) setTasksCompleted(ids []string, completed bool, what string) tea.Cmd {
+	var change []string
+	for _, id := range ids {
+		if task := m.getTask(id); task != nil && task.Completed != completed {
+			change = append(change, id)
+		}
+	}
+	if len(change) == 0 {
+		return nil
+	}
+
+	verb := "Complete"
+	if !completed {
+		verb = "Reopen"
+	}
+	apply := func() tea.Cmd { return m.applyTasksCompleted(change, completed) }
+	if threshold := m.app.Settings.BulkConfirmThreshold; threshold >= 0 && len(change) > threshold {
+		m.askConfirmation(confirmation{
+			title:  verb + " Tasks",
+			prompt: fmt.Sprintf("%s %d %s?", verb, len(change), what),
+			action: strings.ToLower(verb),
+			run:    apply,
+		})
+		return nil
+	}
+	return apply()
+}
+
+// applyTasksCompleted toggles the tasks with IDs ids of the current list,
+// all or none of them
+func (m *Model) applyTasksCompleted(ids []string, completed bool) tea.Cmd {
+	var updated []models.Task
+	err := m.storage.WithTransaction(m.app, func(tx storage.StorageInterface) error {
+		updated = updated[:0]
+		for _, id := range ids {
+			task, err := tx.ToggleTask(m.app, m.currentListID, id)
+			if err != nil {
+				return err
+			}
+			updated = append(updated, task)
+		}
+		return nil
+	})
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v - no task was changed", err), "error")
+		return nil
+	}
+	for _, task := range updated {
+		m.putTask(m.currentListID, task)
+	}
+
+	clear(m.marked)
+	m.updateTasksList()
+	m.refreshStreak()
+	m.refreshHeatmap()
+	if !completed {
+		m.showMessageWithType(fmt.Sprintf("Reopened %d tasks", len(updated)), "info")
+		return m.saveData()
+	}
+	m.showMessageWithType(fmt.Sprintf("Completed %d tasks", len(updated)), "success")
+	return tea.Batch(m.saveData(), m.celebrateIfListDone(m.currentListID))
+}
+
+// openPriorityChooser asks for the priority of the marked tasks, or of the
+// highlighted task when none are marked
+func (m *Model) openPriorityChooser() {
+	ids := m.bulkTargets()
+	if len(ids) == 0 {
+		return
+	}
+
+	m.priorityCursor = models.Low
+	if task := m.getTask(ids[0]); task != nil {
+		m.priorityCursor = task.Priority.Clamp()
+	}
+	m.state = PriorityView
+}
+
+// Priority chooser - sets the priority of the marked tasks in one change
+func (m *Model) updatePriorityChooser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	choice := models.Priority(-1)
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = TasksView
+		return m, nil
+	case key.Matches(msg, m.keys.Up):
+		if m.priorityCursor > models.Low {
+			m.priorityCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.priorityCursor < models.Critical {
+			m.priorityCursor++
+		}
+	case key.Matches(msg, m.keys.Enter):
+		choice = m.priorityCursor
+	default:
+		// Number keys pick a priority directly
+		if n, err := strconv.Atoi(msg.String()); err == nil && models.Priority(n-1).Valid() {
+			choice = models.Priority(n - 1)
+		}
+	}
+	if !choice.Valid() {
+		return m, nil
+	}
+
+	m.state = TasksView
+	updated, err := m.storage.SetTasksPriority(m.app, m.currentListID, m.bulkTargets(), choice)
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return m, nil
+	}
+	for _, task := range updated {
+		m.putTask(m.currentListID, task)
+	}
+
+	clear(m.marked)
+	m.updateTasksList()
+	if len(updated) == 1 {
+		m.showMessageWithType("Priority set to "+choice.String(), "success")
+	} else {
+		m.showMessageWithType(fmt.Sprintf("Priority set to %s for %d tasks", choice, len(updated)), "success")
+	}
+	return m, m.saveData()
+}
+
+// renderPriorityChooserContent renders the priority levels to choose from
+func (m *Model) renderPriorityChooserContent() string {
+	m.layout.SetWindowTitle(FormWindow, withIcon(icons.PriorityMedium, "Set Priority"))
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render("Set Priority"))
+	if len(m.marked) > 0 {
+		lines = append(lines, DescStyle.Render(markedCount(len(m.marked))))
+	} else if item, ok := m.tasksList.SelectedItem().(taskItem); ok {
+		lines = append(lines, DescStyle.Render(item.title))
+	}
+	lines = append(lines, "")
+	for p := models.Low; p <= models.Critical; p++ {
+		line := fmt.Sprintf("%d  %s", p+1, priorityStyles[p].Render(p.String()))
+		if p == m.priorityCursor {
+			lines = append(lines, ListItemSelected.Render(line))
+		} else {
+			lines = append(lines, ListItemNormal.Render(line))
+		}
+	}
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render("↑/↓: select • Enter or 1-4: set • Esc: cancel"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,275 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// calendarWeekdays are the column headings of the calendar, Monday first
+var calendarWeekdays = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// calendarBusy is how many tasks due make a day busy, marked in the warning color
+const calendarBusy = 3
+
+// openCalendarView shows the month of today in the main window, with today selected
+func (m *Model) openCalendarView() {
+	today, _ := models.CalendarDay(m.now())
+	m.calendarDay = today
+	m.calendarCursor = 0
+	if !m.loadCalendarMonth() {
+		return
+	}
+	m.state = CalendarView
+	m.layout.SetFocus(MainWindow)
+}
+
+// calendarMonthStart returns the first day of the month of day
+func calendarMonthStart(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+}
+
+// calendarGridStart returns the Monday the calendar of the month of day starts
+// on, which may fall in the month before
+func calendarGridStart(day time.Time) time.Time {
+	first := calendarMonthStart(day)
+	return first.AddDate(0, 0, -((int(first.Weekday()) + 6) % 7))
+}
+
+// calendarWeeks returns how many weeks the calendar of the month of day
+// shows: every week with a day of the month in it
+func calendarWeeks(day time.Time) int {
+	start := calendarGridStart(day)
+	last := calendarMonthStart(day).AddDate(0, 1, -1)
+	return models.DaysBetween(start, last)/7 + 1
+}
+
+// loadCalendarMonth reads the open tasks due in the weeks the calendar shows
+// and files them by day; it reports false, with the error shown, when they
+// cannot be read
+func (m *Model) loadCalendarMonth() bool {
+	start := calendarGridStart(m.calendarDay)
+	end := start.AddDate(0, 0, 7*calendarWeeks(m.calendarDay))
+
+	// Deadlines are filed by the day they show, so a day more is read on both
+	// sides in case the clock they were entered on differs from this one
+	due, err := m.storage.TasksDueBetween(m.app, start.AddDate(0, 0, -1), end.AddDate(0, 0, 1))
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return false
+	}
+
+	m.calendarDue = make(map[string][]models.ListTask)
+	for _, entry := range due {
+		day := entry.Task.Deadline.Format(models.StreakDayLayout)
+		m.calendarDue[day] = append(m.calendarDue[day], entry)
+	}
+	return true
+}
+
+// calendarSelectedTasks returns the tasks due on the selected day, earliest first
+func (m *Model) calendarSelectedTasks() []models.ListTask {
+	return m.calendarDue[m.calendarDay.Format(models.StreakDayLayout)]
+}
+
+// moveCalendarDay selects the day days away from the selected one, turning
+// to its month when it is in another
+func (m *Model) moveCalendarDay(days int) {
+	m.selectCalendarDay(m.calendarDay.AddDate(0, 0, days))
+}
+
+// shiftMonth returns the day step months from day, keeping the day of the
+// month when the other month has it and taking its last day otherwise
+func shiftMonth(day time.Time, step int) time.Time {
+	first := calendarMonthStart(day).AddDate(0, step, 0)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day.Day(), lastDay)-1)
+}
+
+// moveCalendarMonth turns step months forward or back, as shiftMonth does
+func (m *Model) moveCalendarMonth(step int) {
+	m.selectCalendarDay(shiftMonth(m.calendarDay, step))
+}
+
+// selectCalendarDay selects day, reading the tasks of its month when it is
+// not the month shown
+func (m *Model) selectCalendarDay(day time.Time) {
+	sameMonth := day.Year() == m.calendarDay.Year() && day.Month() == m.calendarDay.Month()
+	m.calendarDay = day
+	m.calendarCursor = 0
+	if !sameMonth {
+		m.loadCalendarMonth()
+	}
+}
+
+// Calendar view - browse the deadlines of a month and jump to a task due
+func (m *Model) updateCalendarView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = TasksView
+	case key.Matches(msg, m.keys.Left):
+		m.moveCalendarDay(-1)
+	case key.Matches(msg, m.keys.Right):
+		m.moveCalendarDay(1)
+	case key.Matches(msg, m.keys.Up):
+		m.moveCalendarDay(-7)
+	case key.Matches(msg, m.keys.Down):
+		m.moveCalendarDay(7)
+	case key.Matches(msg, m.keys.PrevMonth):
+		m.moveCalendarMonth(-1)
+	case key.Matches(msg, m.keys.NextMonth):
+		m.moveCalendarMonth(1)
+	case key.Matches(msg, m.keys.Tab):
+		if tasks := m.calendarSelectedTasks(); len(tasks) > 0 {
+			m.calendarCursor = (m.calendarCursor + 1) % len(tasks)
+		}
+	case key.Matches(msg, m.keys.ShiftTab):
+		if tasks := m.calendarSelectedTasks(); len(tasks) > 0 {
+			m.calendarCursor = (m.calendarCursor - 1 + len(tasks)) % len(tasks)
+		}
+	case key.Matches(msg, m.keys.Enter):
+		if tasks := m.calendarSelectedTasks(); m.calendarCursor < len(tasks) {
+			entry := tasks[m.calendarCursor]
+			m.jumpToTask(entry.ListID, entry.Task.ID)
+		}
+	case key.Matches(msg, m.keys.Star):
+		if tasks := m.calendarSelectedTasks(); m.calendarCursor < len(tasks) {
+			return m, m.toggleEntryStar(&tasks[m.calendarCursor])
+		}
+	}
+	return m, nil
+}
+
+// calendarDayStyle returns the style of a day of the calendar with count
+// tasks due: colored by how many there are, red when any of them is overdue,
+// and muted for the days of the months before and after
+func calendarDayStyle(count int, overdue, inMonth bool) lipgloss.Style {
+	style := lipgloss.NewStyle().Foreground(TextSecondary)
+	if !inMonth {
+		style = style.Foreground(TextMuted)
+	}
+	switch {
+	case overdue:
+		return style.Foreground(ErrorColor).Bold(true)
+	case count >= calendarBusy:
+		return style.Foreground(WarningColor).Bold(true)
+	case count > 0:
+		return style.Foreground(InfoColor).Bold(true)
+	}
+	return style
+}
+
+// anyOverdue reports whether any of the tasks is overdue
+func anyOverdue(tasks []models.ListTask) bool {
+	for _, entry := range tasks {
+		if entry.Task.IsOverdue() {
+			return true
+		}
+	}
+	return false
+}
+
+// renderCalendarGrid renders the weeks of the selected day's month in seven
+// columns that share width; a day shows how many open tasks are due on it
+func (m *Model) renderCalendarGrid(width int) []string {
+	cellWidth := max(4, width/len(calendarWeekdays))
+	today, _ := models.CalendarDay(m.now())
+
+	var header strings.Builder
+	for _, weekday := range calendarWeekdays {
+		header.WriteString(lipgloss.NewStyle().Width(cellWidth).Render(ansi.Truncate(weekday, cellWidth-1, "")))
+	}
+	lines := []string{FormLabel.Render(header.String())}
+
+	start := calendarGridStart(m.calendarDay)
+	for week := 0; week < calendarWeeks(m.calendarDay); week++ {
+		var row strings.Builder
+		for weekday := range calendarWeekdays {
+			day := start.AddDate(0, 0, week*7+weekday)
+			due := m.calendarDue[day.Format(models.StreakDayLayout)]
+
+			text := fmt.Sprintf("%2d", day.Day())
+			if len(due) > 0 {
+				if marker := fmt.Sprintf(" %s%d", icons.Due, len(due)); lipgloss.Width(text+marker) < cellWidth {
+					text += marker
+				} else {
+					text += icons.Due
+				}
+			}
+
+			style := calendarDayStyle(len(due), anyOverdue(due), day.Month() == m.calendarDay.Month())
+			if day.Equal(today) {
+				style = style.Underline(true)
+			}
+			if day.Equal(m.calendarDay) {
+				style = style.Reverse(true)
+			}
+			row.WriteString(style.Render(text) + strings.Repeat(" ", max(1, cellWidth-lipgloss.Width(text))))
+		}
+		lines = append(lines, row.String())
+	}
+	return lines
+}
+
+// renderCalendarContent renders the month grid and the tasks due on the selected day
+func (m *Model) renderCalendarContent() string {
+	m.layout.SetWindowTitle(MainWindow, withIcon(icons.Deadline, "Calendar"))
+
+	width, height := 56, 24
+	if mainWindow := m.layout.GetWindow(MainWindow); mainWindow != nil {
+		width = max(28, mainWindow.Position.Width-6)
+		height = mainWindow.Position.Height
+	}
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render(withIcon(icons.Deadline, m.calendarDay.Format("January 2006"))))
+	lines = append(lines, "")
+	lines = append(lines, m.renderCalendarGrid(width)...)
+
+	mutedStyle := lipgloss.NewStyle().Foreground(TextMuted)
+	lines = append(lines, mutedStyle.Render(fmt.Sprintf("%s due • %s %d+ due • %s overdue",
+		calendarDayStyle(1, false, true).Render(icons.Due),
+		calendarDayStyle(calendarBusy, false, true).Render(icons.Due), calendarBusy,
+		calendarDayStyle(1, true, true).Render(icons.Due))))
+	lines = append(lines, "")
+
+	tasks := m.calendarSelectedTasks()
+	heading := m.calendarDay.Format("Monday, January 2")
+	if len(tasks) == 0 {
+		lines = append(lines, FormLabel.Render(heading))
+		lines = append(lines, BaseSubtitleStyle.Render("Nothing is due"))
+	} else {
+		lines = append(lines, FormLabel.Render(fmt.Sprintf("%s - %s due", heading, taskCountLabel(len(tasks)))))
+	}
+
+	// Keep the cursor inside the rows that fit under the grid
+	visible := max(3, height-len(lines)-6)
+	start := 0
+	if m.calendarCursor >= visible {
+		start = m.calendarCursor - visible + 1
+	}
+	end := min(start+visible, len(tasks))
+	for i := start; i < end; i++ {
+		entry := tasks[i]
+		line := ansi.Truncate(GetDeadlineStyle(entry.Task.IsOverdue(), false).Render(entry.Task.Deadline.Format("15:04"))+" "+
+			starredTitle(entry.Task)+mutedStyle.Render(" · "+entry.ListName), width, "…")
+		if i == m.calendarCursor {
+			lines = append(lines, ListItemSelected.Render(line))
+		} else {
+			lines = append(lines, ListItemNormal.Render(line))
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render(fmt.Sprintf("Arrows: day • %s/%s: month • Tab: next task • Enter: jump to task • %s: star • Esc: back",
+		m.keys.PrevMonth.Help().Key, m.keys.NextMonth.Help().Key, m.keys.Star.Help().Key)))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
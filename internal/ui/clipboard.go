@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// maxPastedTasks caps how many tasks one paste creates, so pasting a whole
+// document by mistake does not flood the list
+const maxPastedTasks = 100
+
+// listMarker matches the bullet or number a line copied from a list starts
+// with, such as "- ", "* ", "• ", "[ ] " or "3. "
+var listMarker = regexp.MustCompile(`^(?:[-*+•]\s+)?(?:\[[ xX]\]\s+)?(?:\d+[.)]\s+)?`)
+
+// clipboardTitles splits clipboard text into task titles, one per non-blank
+// line, without the list markers the lines were copied with
+func clipboardTitles(text string) []string {
+	var titles []string
+	for _, line := range strings.Split(text, "\n") {
+		title := strings.TrimSpace(listMarker.ReplaceAllString(strings.TrimSpace(line), ""))
+		if title != "" {
+			titles = append(titles, title)
+		}
+	}
+	return titles
+}
+
+// pasteTasks creates a task in the current list for each line of text on the
+// system clipboard, without going through the task form
+func (m *Model) pasteTasks() tea.Cmd {
+	if m.getCurrentList() == nil {
+		m.showMessageWithType("Select a list first", "warning")
+		return nil
+	}
+
+	if clipboard.Unsupported {
+		m.showMessageWithType("Cannot read the clipboard: install xclip, xsel or wl-clipboard", "warning")
+		return nil
+	}
+	text, err := clipboard.ReadAll()
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Cannot read the clipboard: %v", err), "warning")
+		return nil
+	}
+	titles := clipboardTitles(text)
+	if len(titles) == 0 {
+		m.showMessageWithType("The clipboard holds no text to make a task of", "warning")
+		return nil
+	}
+	skipped := 0
+	if len(titles) > maxPastedTasks {
+		skipped = len(titles) - maxPastedTasks
+		titles = titles[:maxPastedTasks]
+	}
+
+	// The lines are pasted together or not at all, so a failure part way
+	// leaves no half of the clipboard behind
+	var last models.Task
+	err = m.storage.WithTransaction(m.app, func(tx storage.StorageInterface) error {
+		for _, title := range titles {
+			task, err := tx.CreateTask(m.app, m.currentListID, title, "", models.Medium, nil, "", models.SourceTUI)
+			if err != nil {
+				return err
+			}
+			m.putTask(m.currentListID, task)
+			last = task
+		}
+		return nil
+	})
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v - nothing was pasted", err), "error")
+		return nil
+	}
+
+	m.updateTasksList()
+	selectItem(&m.tasksList, last.ID)
+	switch {
+	case skipped > 0:
+		m.showMessageWithType(fmt.Sprintf("Created %d tasks from the clipboard; the %d lines after the first %d were left out",
+			len(titles), skipped, maxPastedTasks), "warning")
+	case len(titles) == 1:
+		m.showMessageWithType(fmt.Sprintf("Created '%s' from the clipboard", last.Title), "success")
+	default:
+		m.showMessageWithType(fmt.Sprintf("Created %d tasks from the clipboard", len(titles)), "success")
+	}
+	return m.saveData()
+}
@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+
+	"github.com/DhirajZope/lazytodo/internal/config"
+)
+
+// KeyActions returns the names the keys table of the config file rebinds
+// actions by: each field of KeyMap in snake_case, such as new_task
+func KeyActions() []string {
+	fields := reflect.TypeOf(KeyMap{})
+	actions := make([]string, fields.NumField())
+	for i := range actions {
+		actions[i] = snakeCase(fields.Field(i).Name)
+	}
+	return actions
+}
+
+// snakeCase turns a field name such as NewTask into new_task
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// applyKeyOverrides binds the actions named in the keys table of cfg to the
+// keys given there instead of their defaults, and shows those keys in help
+func applyKeyOverrides(keys *KeyMap, cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	fields := reflect.ValueOf(keys).Elem()
+	for i := 0; i < fields.NumField(); i++ {
+		bound, ok := cfg.Keys[snakeCase(fields.Type().Field(i).Name)]
+		if !ok {
+			continue
+		}
+		binding := fields.Field(i).Addr().Interface().(*key.Binding)
+		binding.SetKeys(bound...)
+		binding.SetHelp(strings.Join(bound, "/"), binding.Help().Desc)
+	}
+}
+
+// configKeys names the setting of the config file behind each setting of the
+// settings view that the file can set
+var configKeys = map[int]string{
+	settingIcons:      "icons",
+	settingDateFormat: "date_format",
+}
+
+// applyConfigSettings puts the settings the config file sets over those loaded
+// from storage, which only count where the file leaves them out
+func (m *Model) applyConfigSettings() {
+	cfg := m.opts.Config
+	if cfg == nil {
+		return
+	}
+	if cfg.Icons != "" {
+		m.app.Settings.Icons = cfg.Icons
+	}
+	if cfg.DateFormat != "" {
+		m.app.Settings.DateFormat = cfg.DateFormat
+	}
+}
+
+// configLabel marks a row of the settings view whose setting comes from the
+// config file
+func (m *Model) configLabel(setting int, label string) string {
+	if name, ok := configKeys[setting]; ok && m.opts.Config != nil && m.opts.Config.FileScoped(name) {
+		return label + " [config.toml]"
+	}
+	return label
+}
+
+// writeConfigSetting writes a setting changed in the settings view back to the
+// config file when the file sets it, since the file would otherwise win over
+// the change on the next start
+func (m *Model) writeConfigSetting(setting int) {
+	name, ok := configKeys[setting]
+	cfg := m.opts.Config
+	if !ok || cfg == nil || !cfg.FileScoped(name) {
+		return
+	}
+
+	value := m.app.Settings.Icons
+	if setting == settingDateFormat {
+		value = m.app.Settings.DateFormat
+	}
+	if err := cfg.Set(name, value); err != nil {
+		m.log.add(logError, fmt.Sprintf("Writing %s to %s: %v", name, cfg.Path, err))
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return
+	}
+	m.showMessageWithType(fmt.Sprintf("Saved %s = %s to %s", name, value, cfg.Path), "success")
+}
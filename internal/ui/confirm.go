@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// confirmation is an action held back until it is confirmed in the
+// confirmation overlay
+type confirmation struct {
+	title  string
+	prompt string
+	action string // What confirming does, e.g. "complete"
+	run    func() tea.Cmd
+	back   ViewState // The view both answers return to
+}
+
+// askConfirmation opens the confirmation overlay for c, over the current view
+func (m *Model) askConfirmation(c confirmation) {
+	c.back = m.state
+	m.confirming = c
+	m.state = ConfirmView
+}
+
+// Confirmation overlay - y or Enter runs the action, n or Esc drops it
+func (m *Model) updateConfirmView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := m.confirming
+	switch {
+	case key.Matches(msg, m.keys.Enter) || msg.String() == "y":
+		m.confirming = confirmation{}
+		m.state = c.back
+		return m, c.run()
+	case key.Matches(msg, m.keys.Back) || msg.String() == "n":
+		m.confirming = confirmation{}
+		m.state = c.back
+		m.showMessageWithType("Cancelled", "info")
+	}
+	return m, nil
+}
+
+// renderConfirmContent renders the question of the confirmation overlay
+func (m *Model) renderConfirmContent() string {
+	m.layout.SetWindowTitle(FormWindow, withIcon(icons.Warning, m.confirming.title))
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render(m.confirming.title))
+	lines = append(lines, "")
+	lines = append(lines, lipgloss.NewStyle().Foreground(TextPrimary).Render(m.confirming.prompt))
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render("y or Enter: "+m.confirming.action+" • n or Esc: cancel"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// datePickerStep is how far one press of ↑/↓ moves the picked time of day
+const datePickerStep = 15 * time.Minute
+
+// datePickedMsg carries the deadline picked with the date picker
+type datePickedMsg struct {
+	deadline time.Time
+}
+
+// datePickerClosedMsg reports that the date picker was closed without a pick
+type datePickerClosedMsg struct{}
+
+// datePickerKeyMap defines the keys of the date picker
+type datePickerKeyMap struct {
+	Left      key.Binding
+	Right     key.Binding
+	Up        key.Binding
+	Down      key.Binding
+	PrevMonth key.Binding
+	NextMonth key.Binding
+	Pick      key.Binding
+	Back      key.Binding
+}
+
+// defaultDatePickerKeyMap returns the default keys of the date picker
+func defaultDatePickerKeyMap() datePickerKeyMap {
+	return datePickerKeyMap{
+		Left:      key.NewBinding(key.WithKeys("left", "h"), key.WithHelp("h", "previous day")),
+		Right:     key.NewBinding(key.WithKeys("right", "l"), key.WithHelp("l", "next day")),
+		Up:        key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("k", "previous week")),
+		Down:      key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("j", "next week")),
+		PrevMonth: key.NewBinding(key.WithKeys("<"), key.WithHelp("<", "previous month")),
+		NextMonth: key.NewBinding(key.WithKeys(">"), key.WithHelp(">", "next month")),
+		Pick:      key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "pick")),
+		Back:      key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+	}
+}
+
+// datePicker picks a deadline with the keyboard: a day from a month grid,
+// then the time of day in datePickerStep steps. Like deadlines, its times
+// are wall-clock times in UTC. A pick is sent as a datePickedMsg, and
+// closing it without one as a datePickerClosedMsg.
+type datePicker struct {
+	day     time.Time     // The selected day, at midnight
+	clock   time.Duration // The selected time of day
+	today   time.Time     // Marked in the grid
+	picking bool          // The day is picked and the time of day is being set
+
+	keys datePickerKeyMap
+}
+
+// newDatePicker returns a date picker at start, with its time of day rounded
+// down to a step, and with the day of now marked as today
+func newDatePicker(start, now time.Time) datePicker {
+	return datePicker{
+		day:   dateOf(start),
+		clock: start.Sub(dateOf(start)).Truncate(datePickerStep),
+		today: dateOf(now),
+		keys:  defaultDatePickerKeyMap(),
+	}
+}
+
+// dateOf returns midnight of the day of t
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// Value returns the deadline the picker is at
+func (p datePicker) Value() time.Time {
+	return p.day.Add(p.clock)
+}
+
+// Update moves the picker for a key press
+func (p datePicker) Update(msg tea.Msg) (datePicker, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+
+	if p.picking {
+		switch {
+		case key.Matches(keyMsg, p.keys.Up):
+			p.clock = wrapClock(p.clock + datePickerStep)
+		case key.Matches(keyMsg, p.keys.Down):
+			p.clock = wrapClock(p.clock - datePickerStep)
+		case key.Matches(keyMsg, p.keys.Right):
+			p.clock = wrapClock(p.clock + time.Hour)
+		case key.Matches(keyMsg, p.keys.Left):
+			p.clock = wrapClock(p.clock - time.Hour)
+		case key.Matches(keyMsg, p.keys.Pick):
+			deadline := p.Value()
+			return p, func() tea.Msg { return datePickedMsg{deadline: deadline} }
+		case key.Matches(keyMsg, p.keys.Back):
+			p.picking = false
+		}
+		return p, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, p.keys.Left):
+		p.day = p.day.AddDate(0, 0, -1)
+	case key.Matches(keyMsg, p.keys.Right):
+		p.day = p.day.AddDate(0, 0, 1)
+	case key.Matches(keyMsg, p.keys.Up):
+		p.day = p.day.AddDate(0, 0, -7)
+	case key.Matches(keyMsg, p.keys.Down):
+		p.day = p.day.AddDate(0, 0, 7)
+	case key.Matches(keyMsg, p.keys.PrevMonth):
+		p.day = shiftMonth(p.day, -1)
+	case key.Matches(keyMsg, p.keys.NextMonth):
+		p.day = shiftMonth(p.day, 1)
+	case key.Matches(keyMsg, p.keys.Pick):
+		p.picking = true
+	case key.Matches(keyMsg, p.keys.Back):
+		return p, func() tea.Msg { return datePickerClosedMsg{} }
+	}
+	return p, nil
+}
+
+// wrapClock keeps a time of day within the day, so that stepping past
+// midnight comes round to the other end
+func wrapClock(clock time.Duration) time.Duration {
+	const day = 24 * time.Hour
+	return (clock%day + day) % day
+}
+
+// View renders the month grid of the selected day with the time row under it
+func (p datePicker) View() string {
+	const cellWidth = 4
+	var lines []string
+	lines = append(lines, FormLabel.Render(p.day.Format("January 2006")))
+
+	var header strings.Builder
+	for _, weekday := range calendarWeekdays {
+		header.WriteString(fmt.Sprintf("%-*s", cellWidth, weekday))
+	}
+	lines = append(lines, lipgloss.NewStyle().Foreground(TextSecondary).Render(header.String()))
+
+	start := calendarGridStart(p.day)
+	for week := 0; week < calendarWeeks(p.day); week++ {
+		var row strings.Builder
+		for weekday := range calendarWeekdays {
+			day := start.AddDate(0, 0, week*7+weekday)
+			style := lipgloss.NewStyle().Foreground(TextPrimary)
+			if day.Month() != p.day.Month() {
+				style = style.Foreground(TextMuted)
+			}
+			if day.Equal(p.today) {
+				style = style.Underline(true).Bold(true)
+			}
+			if day.Equal(p.day) {
+				style = style.Reverse(true)
+			}
+			row.WriteString(style.Render(fmt.Sprintf("%2d", day.Day())) + strings.Repeat(" ", cellWidth-2))
+		}
+		lines = append(lines, row.String())
+	}
+	lines = append(lines, "")
+
+	clock := fmt.Sprintf("‹ %s ›", p.Value().Format("15:04"))
+	if p.picking {
+		lines = append(lines, FormLabel.Render("Time: ")+lipgloss.NewStyle().Foreground(AccentColor).Bold(true).Reverse(true).Render(clock))
+		lines = append(lines, DescStyle.Render("↑/↓: 15 min • ←/→: hour • Enter: use • Esc: back"))
+	} else {
+		lines = append(lines, FormLabel.Render("Time: ")+lipgloss.NewStyle().Foreground(TextMuted).Render(clock))
+		lines = append(lines, DescStyle.Render(fmt.Sprintf("h/j/k/l: day • %s/%s: month • Enter: pick • Esc: close",
+			p.keys.PrevMonth.Help().Key, p.keys.NextMonth.Help().Key)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// openDatePicker opens the date picker over the deadline field, at the
+// deadline entered there or else at the next step of the clock today
+func (m *Model) openDatePicker() {
+	now := models.WallClock(m.now())
+	start := now.Truncate(datePickerStep).Add(datePickerStep)
+	if deadline, err := models.ParseDeadline(m.deadlineInput.Value(), dateLayout); err == nil && deadline != nil {
+		start = *deadline
+	}
+	m.datePicker = newDatePicker(start, now)
+	m.datePicking = true
+}
+
+// pickDeadline fills the deadline field with the deadline picked
+func (m *Model) pickDeadline(deadline time.Time) {
+	m.datePicking = false
+	m.deadlineInput.SetValue(formatDeadline(deadline))
+	m.deadlineInput.CursorEnd()
+}
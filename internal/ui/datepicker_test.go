@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestShiftMonthKeepsTheDayWithinTheMonth(t *testing.T) {
+	for _, tt := range []struct {
+		from time.Time
+		step int
+		want time.Time
+	}{
+		{date(2024, time.January, 31), 1, date(2024, time.February, 29)},
+		{date(2023, time.January, 31), 1, date(2023, time.February, 28)},
+		{date(2024, time.March, 31), -1, date(2024, time.February, 29)},
+		{date(2024, time.February, 29), 12, date(2025, time.February, 28)},
+		{date(2100, time.January, 29), 1, date(2100, time.February, 28)},
+		{date(2000, time.January, 29), 1, date(2000, time.February, 29)},
+		{date(2025, time.December, 31), 1, date(2026, time.January, 31)},
+		{date(2026, time.January, 15), -1, date(2025, time.December, 15)},
+		{date(2024, time.May, 31), 1, date(2024, time.June, 30)},
+	} {
+		if got := shiftMonth(tt.from, tt.step); !got.Equal(tt.want) {
+			t.Errorf("shiftMonth(%s, %d) = %s, want %s", tt.from.Format(time.DateOnly), tt.step, got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestCalendarWeeks(t *testing.T) {
+	for _, tt := range []struct {
+		month time.Time
+		start time.Time // Monday of the first week
+		weeks int
+	}{
+		{date(2021, time.February, 10), date(2021, time.February, 1), 4}, // Starts on a Monday, 28 days
+		{date(2026, time.February, 10), date(2026, time.January, 26), 5},
+		{date(2026, time.March, 10), date(2026, time.February, 23), 6}, // Starts on a Sunday, 31 days
+		{date(2024, time.February, 29), date(2024, time.January, 29), 5},
+	} {
+		if got := calendarGridStart(tt.month); !got.Equal(tt.start) {
+			t.Errorf("calendarGridStart(%s) = %s, want %s", tt.month.Format("2006-01"), got.Format(time.DateOnly), tt.start.Format(time.DateOnly))
+		}
+		if got := calendarWeeks(tt.month); got != tt.weeks {
+			t.Errorf("calendarWeeks(%s) = %d, want %d", tt.month.Format("2006-01"), got, tt.weeks)
+		}
+	}
+}
+
+func TestDatePickerKeys(t *testing.T) {
+	keys := func(p datePicker, values ...string) datePicker {
+		for _, value := range values {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(value)}
+			switch value {
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case "esc":
+				msg = tea.KeyMsg{Type: tea.KeyEscape}
+			}
+			p, _ = p.Update(msg)
+		}
+		return p
+	}
+	start := time.Date(2024, time.February, 28, 23, 50, 0, 0, time.UTC)
+	p := newDatePicker(start, start)
+	for _, tt := range []struct {
+		keys []string
+		want time.Time
+	}{
+		{nil, time.Date(2024, time.February, 28, 23, 45, 0, 0, time.UTC)},
+		{[]string{"l"}, time.Date(2024, time.February, 29, 23, 45, 0, 0, time.UTC)},
+		{[]string{"l"}, time.Date(2024, time.March, 1, 23, 45, 0, 0, time.UTC)},
+		{[]string{"k"}, time.Date(2024, time.February, 23, 23, 45, 0, 0, time.UTC)},
+		{[]string{"<", "<"}, time.Date(2023, time.December, 23, 23, 45, 0, 0, time.UTC)},
+		{[]string{"j", "j", "h"}, time.Date(2024, time.January, 5, 23, 45, 0, 0, time.UTC)},
+		// Picking the day moves on to the time, which comes round at midnight
+		{[]string{"enter", "k"}, time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC)},
+		{[]string{"j", "j"}, time.Date(2024, time.January, 5, 23, 30, 0, 0, time.UTC)},
+		{[]string{"l"}, time.Date(2024, time.January, 5, 0, 30, 0, 0, time.UTC)},
+		{[]string{"esc", "l"}, time.Date(2024, time.January, 6, 0, 30, 0, 0, time.UTC)},
+	} {
+		p = keys(p, tt.keys...)
+		if got := p.Value(); !got.Equal(tt.want) {
+			t.Errorf("after %v: %s, want %s", tt.keys, got.Format(time.DateTime), tt.want.Format(time.DateTime))
+		}
+	}
+
+	p = keys(p, "enter")
+	_, cmd := p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg, ok := cmd().(datePickedMsg); !ok || !msg.deadline.Equal(p.Value()) {
+		t.Errorf("Enter on the time sent %v, want the deadline %s", msg, p.Value())
+	}
+}
+
+func TestDatePickerShowsTheMonth(t *testing.T) {
+	day := time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC)
+	view := newDatePicker(day, day).View()
+	for _, want := range []string{"February 2024", "29", "09:00"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not show %q:\n%s", want, view)
+		}
+	}
+	// The month, the weekdays, five weeks, a gap, the time and the keys
+	if rows := strings.Count(view, "\n") + 1; rows != 10 {
+		t.Errorf("view has %d rows, want 10 for the five weeks of February 2024:\n%s", rows, view)
+	}
+}
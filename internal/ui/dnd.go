@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// dndChoices are the do not disturb durations offered, in the order they are
+// listed; zero keeps reminders quiet until do not disturb is turned off
+var dndChoices = []struct {
+	label    string
+	duration time.Duration
+}{
+	{"30 minutes", 30 * time.Minute},
+	{"1 hour", time.Hour},
+	{"2 hours", 2 * time.Hour},
+	{"4 hours", 4 * time.Hour},
+	{"Until I turn it off", 0},
+}
+
+// toggleDoNotDisturb turns do not disturb off when it is on, and otherwise
+// asks for how long to turn it on
+func (m *Model) toggleDoNotDisturb() {
+	if m.dnd {
+		m.endDoNotDisturb("Do not disturb off - reminders resume")
+		return
+	}
+	m.dndCursor = 0
+	m.dndReturn = m.state
+	m.state = DoNotDisturbView
+}
+
+// dndActive reports whether do not disturb holds back reminders at now
+func (m *Model) dndActive(now time.Time) bool {
+	return m.dnd && (m.dndUntil.IsZero() || now.Before(m.dndUntil))
+}
+
+// expireDoNotDisturb turns do not disturb off once its time is up
+func (m *Model) expireDoNotDisturb(now time.Time) {
+	if m.dnd && !m.dndActive(now) {
+		m.endDoNotDisturb("Do not disturb ended - reminders resume")
+	}
+}
+
+// endDoNotDisturb turns do not disturb off. Reminders held back meanwhile
+// come up on the next check, since they were never marked as sent.
+func (m *Model) endDoNotDisturb(message string) {
+	m.dnd = false
+	m.dndUntil = time.Time{}
+	m.lastReminderCheck = time.Time{}
+	m.showMessageWithType(message, "info")
+}
+
+// dndBadge describes do not disturb for the status bar, or is empty while it is off
+func (m *Model) dndBadge() string {
+	if !m.dnd {
+		return ""
+	}
+	if m.dndUntil.IsZero() {
+		return withIcon(icons.Quiet, "DND")
+	}
+	return withIcon(icons.Quiet, "DND until "+m.dndUntil.Format("15:04"))
+}
+
+// Do not disturb chooser - holds back reminders for the chosen time
+func (m *Model) updateDoNotDisturbView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	choice := -1
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = m.dndReturn
+		return m, nil
+	case key.Matches(msg, m.keys.Up):
+		if m.dndCursor > 0 {
+			m.dndCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.dndCursor < len(dndChoices)-1 {
+			m.dndCursor++
+		}
+	case key.Matches(msg, m.keys.Enter):
+		choice = m.dndCursor
+	default:
+		// Number keys pick a duration directly
+		if n, err := strconv.Atoi(msg.String()); err == nil && n >= 1 && n <= len(dndChoices) {
+			choice = n - 1
+		}
+	}
+	if choice < 0 {
+		return m, nil
+	}
+
+	m.state = m.dndReturn
+	m.dnd = true
+	m.dndUntil = time.Time{}
+	if duration := dndChoices[choice].duration; duration > 0 {
+		m.dndUntil = m.now().Add(duration)
+		m.showMessageWithType(withIcon(icons.Quiet, fmt.Sprintf("Do not disturb until %s - reminders are held back", m.dndUntil.Format("15:04"))), "success")
+	} else {
+		m.showMessageWithType(withIcon(icons.Quiet, fmt.Sprintf("Do not disturb on - %s turns it off", m.keys.DoNotDisturb.Help().Key)), "success")
+	}
+	return m, nil
+}
+
+// renderDoNotDisturbContent renders the do not disturb durations to choose from
+func (m *Model) renderDoNotDisturbContent() string {
+	m.layout.SetWindowTitle(FormWindow, withIcon(icons.Quiet, "Do Not Disturb"))
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render("Do Not Disturb"))
+	lines = append(lines, DescStyle.Render("Hold back reminders and desktop notifications for:"))
+	lines = append(lines, "")
+	for i, choice := range dndChoices {
+		line := fmt.Sprintf("%d  %s", i+1, choice.label)
+		if i == m.dndCursor {
+			lines = append(lines, ListItemSelected.Render(line))
+		} else {
+			lines = append(lines, ListItemNormal.Render(line))
+		}
+	}
+	lines = append(lines, "")
+	lines = append(lines, lipgloss.NewStyle().Foreground(TextMuted).Render("Reminders held back come up once it ends"))
+	lines = append(lines, DescStyle.Render(fmt.Sprintf("↑/↓: select • Enter or 1-%d: turn on • Esc: cancel", len(dndChoices))))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
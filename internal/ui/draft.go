@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// formValues holds what the task and list forms hold, to tell whether a form
+// was changed since it opened and to keep a discarded new task as a draft
+type formValues struct {
+	title       string
+	description string
+	deadline    string
+	group       string
+	priority    models.Priority
+	label       int
+	color       int
+	template    int
+}
+
+// formSnapshot returns what the open form holds
+func (m *Model) formSnapshot() formValues {
+	return formValues{
+		title:       m.titleInput.Value(),
+		description: m.descriptionInput.Value(),
+		deadline:    m.deadlineInput.Value(),
+		group:       m.groupInput.Value(),
+		priority:    m.editingPriority,
+		label:       m.labelIndex,
+		color:       m.colorIndex,
+		template:    m.templateIndex,
+	}
+}
+
+// markFormPristine takes what the form holds now as its initial values, for
+// when it has been filled in for opening
+func (m *Model) markFormPristine() {
+	m.formInitial = m.formSnapshot()
+}
+
+// formChanged reports whether any field of the open form differs from its
+// initial value
+func (m *Model) formChanged() bool {
+	return m.formSnapshot() != m.formInitial
+}
+
+// cancelForm leaves the open form through leave. A form with changes asks
+// first, and a new task it discards is kept as the draft the next new task
+// offers to restore.
+func (m *Model) cancelForm(leave func()) tea.Cmd {
+	if !m.formChanged() {
+		leave()
+		return nil
+	}
+
+	what := "this list"
+	switch m.state {
+	case CreateTaskView:
+		what = "this new task"
+	case EditTaskView:
+		what = "this task"
+	case CreateListView:
+		what = "this new list"
+	}
+	m.askConfirmation(confirmation{
+		title:  "Discard Changes",
+		prompt: fmt.Sprintf("Discard changes to %s? (y/n)", what),
+		action: "discard",
+		run: func() tea.Cmd {
+			if m.state == CreateTaskView {
+				draft := m.formSnapshot()
+				m.taskDraft = &draft
+			}
+			leave()
+			m.showMessageWithType("Changes discarded", "info")
+			return nil
+		},
+	})
+	return nil
+}
+
+// openNewTaskForm opens an empty task form, first offering to restore the
+// draft of a new task discarded earlier in the session
+func (m *Model) openNewTaskForm() {
+	m.resetForm()
+	m.state = CreateTaskView
+	if m.taskDraft == nil {
+		return
+	}
+
+	draft := *m.taskDraft
+	title := plainLine(draft.title)
+	if title == "" {
+		title = "without a title"
+	} else {
+		title = fmt.Sprintf("\"%s\"", title)
+	}
+	m.askConfirmation(confirmation{
+		title:  "Restore Draft",
+		prompt: fmt.Sprintf("Restore the draft %s you discarded? (y/n)", title),
+		action: "restore",
+		run: func() tea.Cmd {
+			m.titleInput.SetValue(draft.title)
+			m.descriptionInput.SetValue(draft.description)
+			m.deadlineInput.SetValue(draft.deadline)
+			m.editingPriority = draft.priority
+			m.labelIndex = draft.label
+			return nil
+		},
+	})
+}
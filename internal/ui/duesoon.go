@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// dueSoonHourChoices are the due-soon windows the settings view cycles
+// through; -1 turns due-soon markers off
+var dueSoonHourChoices = []int{-1, 1, 3, 6, 12, 24, 48, 72, 168}
+
+// nextDueSoonHours returns the due-soon window step places away from hours; a
+// window set outside the choices starts from the default
+func nextDueSoonHours(hours, step int) int {
+	for i, choice := range dueSoonHourChoices {
+		if choice == hours {
+			return dueSoonHourChoices[(i+step+len(dueSoonHourChoices))%len(dueSoonHourChoices)]
+		}
+	}
+	return models.DefaultSettings().DueSoonHours
+}
+
+// dueSoonLabel describes the due-soon window for the settings view
+func dueSoonLabel(hours int) string {
+	switch {
+	case hours <= 0:
+		return "Off"
+	case hours == 1:
+		return "within 1 hour"
+	case hours == 24:
+		return "within 1 day"
+	case hours%24 == 0:
+		return fmt.Sprintf("within %d days", hours/24)
+	default:
+		return fmt.Sprintf("within %d hours", hours)
+	}
+}
+
+// isDueSoon reports whether task is due within the due_soon_hours setting
+func (m *Model) isDueSoon(task *models.Task) bool {
+	return task.IsDueSoonWithin(m.app.Settings.DueSoonWindow())
+}
+
+// recountDueSoon loads the tasks of lists that have not been opened yet, whose
+// due-soon counts were taken with the window in effect when they were loaded
+func (m *Model) recountDueSoon() {
+	for i := range m.app.TodoLists {
+		if m.app.TodoLists[i].TasksLoaded() {
+			continue
+		}
+		if err := m.storage.LoadTasks(m.app, m.app.TodoLists[i].ID); err != nil {
+			m.showMessageWithType(fmt.Sprintf("Error loading tasks: %v", err), "error")
+			return
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// criticalBannerRows is how many tasks the critical overdue banner lists
+// before it counts the rest
+const criticalBannerRows = 3
+
+// criticalRepeatChoices are the minutes between repeated reminders about
+// overdue Critical tasks the settings view cycles through; -1 turns them off
+var criticalRepeatChoices = []int{-1, 10, 15, 30, 60, 120}
+
+// nextCriticalRepeat returns the repeat interval step places away from
+// minutes; an interval set outside the choices starts from the default
+func nextCriticalRepeat(minutes, step int) int {
+	for i, choice := range criticalRepeatChoices {
+		if choice == minutes {
+			return criticalRepeatChoices[(i+step+len(criticalRepeatChoices))%len(criticalRepeatChoices)]
+		}
+	}
+	return models.DefaultSettings().CriticalRepeatMinutes
+}
+
+// criticalRepeatLabel describes the repeat interval for the settings view
+func criticalRepeatLabel(minutes int) string {
+	if minutes <= 0 {
+		return "Off"
+	}
+	return fmt.Sprintf("every %d minutes", minutes)
+}
+
+// escalation is where the reminders of an overdue Critical task stand, for
+// the deadline it had then
+type escalation struct {
+	deadline  time.Time
+	notified  time.Time // When it was last reminded about
+	dismissed bool      // Dismissed with x: left out of the banner and not repeated
+}
+
+// refreshEscalations updates the overdue Critical tasks the banner lists,
+// across all lists. A task gets a fresh escalation, not dismissed, whenever
+// its deadline changes, and loses it once it is completed or no longer
+// overdue.
+func (m *Model) refreshEscalations() {
+	overdue, err := m.storage.OverdueTasks(m.app)
+	if err != nil {
+		return // Keep the last known banner
+	}
+
+	shown := len(m.criticalOverdue)
+	escalations := make(map[string]escalation)
+	m.criticalOverdue = nil
+	for _, entry := range overdue {
+		if entry.Task.Priority != models.Critical {
+			continue
+		}
+		state, ok := m.escalations[entry.Task.ID]
+		if !ok || !state.deadline.Equal(*entry.Task.Deadline) {
+			// Overdue reminders already told about it, so repeats count from now
+			state = escalation{deadline: *entry.Task.Deadline, notified: m.now()}
+		}
+		escalations[entry.Task.ID] = state
+		if !state.dismissed {
+			m.criticalOverdue = append(m.criticalOverdue, entry)
+		}
+	}
+	m.escalations = escalations
+
+	if len(m.criticalOverdue) != shown {
+		m.updateListDimensions()
+	}
+}
+
+// repeatEscalations reminds again about the overdue Critical tasks not
+// dismissed once the critical_repeat_minutes setting has passed since they
+// were last reminded about, in the status bar and, with desktop
+// notifications on, on the desktop. Do not disturb and quiet hours hold the
+// repeats back like other reminders.
+func (m *Model) repeatEscalations(now time.Time) tea.Cmd {
+	minutes := m.app.Settings.CriticalRepeatMinutes
+	if minutes <= 0 || m.remindersHeld(now) {
+		return nil
+	}
+
+	var due []string
+	for _, entry := range m.criticalOverdue {
+		state := m.escalations[entry.Task.ID]
+		if now.Sub(state.notified) < time.Duration(minutes)*time.Minute {
+			continue
+		}
+		state.notified = now
+		m.escalations[entry.Task.ID] = state
+		due = append(due, entry.Task.Title)
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	status := fmt.Sprintf("Critical task '%s' is still overdue!", due[0])
+	if len(due) > 1 {
+		status += fmt.Sprintf(" (+%d more)", len(due)-1)
+	}
+	m.showMessageWithType(withIcon(icons.Overdue, status), "error")
+
+	if !m.app.Settings.DesktopNotify {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, title := range due {
+		cmds = append(cmds, desktopNotify("LazyTodo: critical task overdue", fmt.Sprintf("Task '%s' is still overdue!", title)))
+	}
+	return tea.Batch(cmds...)
+}
+
+// dismissEscalations stops the banner and the repeated reminders about the
+// selected task when it is in the banner, and about every task in it
+// otherwise. A dismissed task comes back when its deadline changes and it is
+// still overdue.
+func (m *Model) dismissEscalations() {
+	if len(m.criticalOverdue) == 0 {
+		m.showMessageWithType("No overdue critical tasks to dismiss", "info")
+		return
+	}
+
+	dismiss := m.criticalOverdue
+	if item, ok := m.tasksList.SelectedItem().(taskItem); ok {
+		for _, entry := range m.criticalOverdue {
+			if entry.Task.ID == item.id {
+				dismiss = []models.ListTask{entry}
+				break
+			}
+		}
+	}
+
+	for _, entry := range dismiss {
+		state := m.escalations[entry.Task.ID]
+		state.dismissed = true
+		m.escalations[entry.Task.ID] = state
+	}
+	if len(dismiss) == 1 {
+		m.showMessageWithType(fmt.Sprintf("Stopped reminding about '%s' for its current deadline", dismiss[0].Task.Title), "success")
+	} else {
+		m.showMessageWithType(fmt.Sprintf("Stopped reminding about %d critical tasks for their current deadlines", len(dismiss)), "success")
+	}
+	m.refreshEscalations()
+}
+
+// criticalBannerHeight is how many lines the critical overdue banner takes
+// above the tasks, none while it is not shown
+func (m *Model) criticalBannerHeight() int {
+	if len(m.criticalOverdue) == 0 {
+		return 0
+	}
+	rows := min(len(m.criticalOverdue), criticalBannerRows)
+	if len(m.criticalOverdue) > criticalBannerRows {
+		rows++ // "+N more"
+	}
+	return rows + 2 // The heading and the gap under the banner
+}
+
+// renderCriticalBanner renders the banner listing the overdue Critical tasks
+// not dismissed, in at most width columns, or "" when there are none
+func (m *Model) renderCriticalBanner(width int) string {
+	if len(m.criticalOverdue) == 0 {
+		return ""
+	}
+
+	style := lipgloss.NewStyle().Foreground(ErrorColor)
+	width = max(width-2, 10) // The banner's border and padding
+	heading := fmt.Sprintf("Critical and overdue (%d) • %s: dismiss", len(m.criticalOverdue), m.keys.Dismiss.Help().Key)
+	lines := []string{style.Bold(true).Render(ansi.Truncate(withIcon(icons.PriorityCritical, heading), width, "…"))}
+
+	for i, entry := range m.criticalOverdue {
+		if i == criticalBannerRows {
+			lines = append(lines, style.Render(fmt.Sprintf("+%d more", len(m.criticalOverdue)-criticalBannerRows)))
+			break
+		}
+		line := fmt.Sprintf("%s (%s) — was due %s", entry.Task.Title, entry.ListName, formatDeadline(*entry.Task.Deadline))
+		lines = append(lines, style.Render(ansi.Truncate(line, width, "…")))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.ThickBorder(), false, false, false, true).
+		BorderForeground(ErrorColor).
+		PaddingLeft(1).
+		Render(strings.Join(lines, "\n")) + "\n\n"
+}
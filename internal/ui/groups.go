@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// ungroupedHeading is the sidebar heading of lists without a group
+const ungroupedHeading = "Ungrouped"
+
+// groupItem is a sidebar heading that lists of the same group are shown under
+type groupItem struct {
+	name      string // Empty for the ungrouped lists
+	count     int
+	collapsed bool
+}
+
+func (i groupItem) FilterValue() string { return "" }
+func (i groupItem) itemID() string      { return "\x00group:" + i.name }
+func (i groupItem) Title() string       { return groupHeading(i.name) }
+func (i groupItem) Description() string {
+	if i.count == 1 {
+		return "1 list"
+	}
+	return fmt.Sprintf("%d lists", i.count)
+}
+
+// groupHeading returns the heading a group is shown under
+func groupHeading(group string) string {
+	if group == "" {
+		return ungroupedHeading
+	}
+	return group
+}
+
+// renderGroupItem draws a group heading with a marker telling whether it is collapsed
+func (d todoListDelegate) renderGroupItem(w io.Writer, m list.Model, index int, item groupItem) {
+	titleStyle, descStyle := d.Styles.NormalTitle, d.Styles.NormalDesc
+	if index == m.Index() {
+		titleStyle, descStyle = d.Styles.SelectedTitle, d.Styles.SelectedDesc
+	}
+
+	marker := icons.GroupOpen
+	if item.collapsed {
+		marker = icons.GroupClosed
+	}
+	textWidth := m.Width() - titleStyle.GetHorizontalFrameSize()
+	title := titleStyle.Bold(true).Render(ansi.Truncate(marker+" "+strings.ToUpper(item.Title()), textWidth, "…"))
+	desc := descStyle.Render(ansi.Truncate(item.Description(), m.Width()-descStyle.GetHorizontalFrameSize(), "…"))
+
+	fmt.Fprintf(w, "%s\n%s", title, desc)
+}
+
+// listGroups returns the groups in use in sidebar order: named groups
+// alphabetically, then the ungrouped lists. It is empty when no list has a
+// group, in which case the sidebar shows no headings at all.
+func listGroups(todoLists []models.TodoList) []string {
+	seen := make(map[string]bool)
+	var groups []string
+	for _, todoList := range todoLists {
+		if !seen[todoList.Group] {
+			seen[todoList.Group] = true
+			groups = append(groups, todoList.Group)
+		}
+	}
+	if len(groups) == 1 && groups[0] == "" {
+		return nil
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i] == "" || groups[j] == "" {
+			return groups[j] == ""
+		}
+		return strings.ToLower(groups[i]) < strings.ToLower(groups[j])
+	})
+	return groups
+}
+
+// groupSidebarItems interleaves group headings with the list items, keeping
+// each group's lists in their own order and leaving out collapsed ones
+func (m *Model) groupSidebarItems(items []list.Item) []list.Item {
+	groups := listGroups(m.app.TodoLists)
+	if len(groups) == 0 {
+		return items
+	}
+
+	grouped := make([]list.Item, 0, len(items)+len(groups))
+	for _, group := range groups {
+		var lists []list.Item
+		for i := range m.app.TodoLists {
+			if m.app.TodoLists[i].Group == group {
+				lists = append(lists, items[i])
+			}
+		}
+
+		collapsed := m.collapsedGroups[group]
+		grouped = append(grouped, groupItem{name: group, count: len(lists), collapsed: collapsed})
+		if !collapsed {
+			grouped = append(grouped, lists...)
+		}
+	}
+	return grouped
+}
+
+// toggleGroup collapses or expands a sidebar group, keeping its heading selected
+func (m *Model) toggleGroup(group string) {
+	m.collapsedGroups[group] = !m.collapsedGroups[group]
+	if !m.collapsedGroups[group] {
+		delete(m.collapsedGroups, group)
+	}
+	m.updateTodoListsList()
+}
+
+// expandGroupOf shows the lists of the group a list is in, so it can be selected
+func (m *Model) expandGroupOf(todoList *models.TodoList) {
+	if m.collapsedGroups[todoList.Group] {
+		delete(m.collapsedGroups, todoList.Group)
+		m.updateTodoListsList()
+	}
+}
+
+// groupMoveOffset turns moving a list one place up or down into an offset in
+// the order of all lists, skipping lists of other groups so the list swaps
+// places with its neighbor under the same heading. Pinned lists likewise move
+// only among the pinned ones, and the others below them.
+func groupMoveOffset(todoLists []models.TodoList, listID string, step int) int {
+	index := -1
+	for i := range todoLists {
+		if todoLists[i].ID == listID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return step
+	}
+
+	for i := index + step; i >= 0 && i < len(todoLists); i += step {
+		if todoLists[i].Group == todoLists[index].Group && todoLists[i].Pinned == todoLists[index].Pinned {
+			return i - index
+		}
+	}
+	return 0
+}
+
+// togglePin pins the selected list to the top of the sidebar, or of its
+// group once lists are grouped, or unpins it. The list stays selected.
+func (m *Model) togglePin() tea.Cmd {
+	item, ok := m.todoListsList.SelectedItem().(listItem)
+	if !ok {
+		m.showMessageWithType("Select a list first", "warning")
+		return nil
+	}
+	todoList := m.getList(item.id)
+	if todoList == nil {
+		return nil
+	}
+
+	pinned := !todoList.Pinned
+	if err := m.storage.SetListPinned(m.app, item.id, pinned); err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return nil
+	}
+
+	m.updateTodoListsList()
+	if pinned {
+		m.showMessageWithType(fmt.Sprintf("Pinned '%s' to the top", item.title), "success")
+	} else {
+		m.showMessageWithType(fmt.Sprintf("Unpinned '%s'", item.title), "info")
+	}
+	return m.saveData()
+}
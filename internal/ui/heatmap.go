@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// heatmapWeeks is how many weeks the completion heatmap shows when the
+// sidebar is wide enough
+const heatmapWeeks = 12
+
+// heatmapCellWidth is how many columns one week of the heatmap takes: a
+// colored cell and a gap
+const heatmapCellWidth = 2
+
+// heatmapHeight is how many lines the heatmap takes: a row per weekday and a caption
+const heatmapHeight = 8
+
+// heatmapLevels are the colors of the days, from nothing completed to the
+// most completed on one day of the weeks shown; greens deepen toward the
+// most on light terminals and brighten on dark ones
+var heatmapLevels = []lipgloss.TerminalColor{
+	SurfaceColor,
+	lipgloss.AdaptiveColor{Dark: "#065F46", Light: "#A7F3D0"},
+	lipgloss.AdaptiveColor{Dark: "#047857", Light: "#6EE7B7"},
+	lipgloss.AdaptiveColor{Dark: "#059669", Light: "#10B981"},
+	AccentColor,
+}
+
+// refreshHeatmap reloads the completions the sidebar heatmap shows, or drops
+// them while the heatmap is off. When they cannot be read the heatmap is
+// left out and the error logged.
+func (m *Model) refreshHeatmap() {
+	m.heatmap = nil
+	if !m.app.Settings.ShowHeatmap {
+		return
+	}
+
+	now := time.Now()
+	_, end := models.CalendarDay(now)
+	counts, err := m.storage.CompletionCounts(m.app, heatmapStart(now, heatmapWeeks), end)
+	if err != nil {
+		m.log.add(logWarning, fmt.Sprintf("Cannot show the completion heatmap: %v", err))
+		return
+	}
+	m.heatmap = counts
+}
+
+// heatmapShown reports whether the heatmap goes at the top of the sidebar:
+// it is on, and the sidebar is tall enough to keep room for the lists
+func (m *Model) heatmapShown() bool {
+	if m.app == nil || !m.app.Settings.ShowHeatmap || m.heatmap == nil {
+		return false
+	}
+	sidebarWindow := m.layout.GetWindow(SidebarWindow)
+	return sidebarWindow != nil && sidebarWindow.Position.Height-6-heatmapHeight-1 >= 5
+}
+
+// heatmapStart returns the Monday that starts the first of the given number
+// of weeks up to and including now's
+func heatmapStart(now time.Time, weeks int) time.Time {
+	today, _ := models.CalendarDay(now)
+	sinceMonday := (int(today.Weekday()) + 6) % 7
+	return today.AddDate(0, 0, -sinceMonday-7*(weeks-1))
+}
+
+// heatmapLevel returns the index into heatmapLevels for a day with count
+// completions, when the busiest day of the weeks shown had most
+func heatmapLevel(count, most int) int {
+	if count <= 0 || most <= 0 {
+		return 0
+	}
+	top := len(heatmapLevels) - 1
+	return min(max((count*top+most-1)/most, 1), top)
+}
+
+// renderHeatmap renders the completions of the last weeks within width
+// columns, a column per week from Monday down to Sunday. A narrow sidebar
+// shows fewer weeks rather than wrapping.
+func (m *Model) renderHeatmap(width int) string {
+	weeks := min(heatmapWeeks, width/heatmapCellWidth)
+	if weeks < 1 {
+		return ""
+	}
+
+	now := time.Now()
+	today, _ := models.CalendarDay(now)
+	start := heatmapStart(now, weeks)
+
+	total, most := 0, 0
+	for day := start; !day.After(today); day = day.AddDate(0, 0, 1) {
+		count := m.heatmap[day.Format(models.StreakDayLayout)]
+		total += count
+		most = max(most, count)
+	}
+
+	cell, gap := " ", strings.Repeat(" ", heatmapCellWidth-1)
+	rows := make([]string, 7)
+	for weekday := range rows {
+		var row strings.Builder
+		for week := 0; week < weeks; week++ {
+			day := start.AddDate(0, 0, week*7+weekday)
+			if day.After(today) {
+				row.WriteString(cell + gap)
+				continue
+			}
+			level := heatmapLevel(m.heatmap[day.Format(models.StreakDayLayout)], most)
+			row.WriteString(lipgloss.NewStyle().Background(heatmapLevels[level]).Render(cell) + gap)
+		}
+		rows[weekday] = row.String()
+	}
+
+	caption := fmt.Sprintf("%s completed in %d weeks", taskCountLabel(total), weeks)
+	rows = append(rows, lipgloss.NewStyle().Foreground(TextMuted).Render(ansi.Truncate(caption, width, "…")))
+	return strings.Join(rows, "\n")
+}
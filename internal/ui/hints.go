@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// statusHintLimit is how many key hints the status bar shows for the context
+const statusHintLimit = 4
+
+// relabel returns binding with its help text describing what it does here
+func relabel(binding key.Binding, desc string) key.Binding {
+	binding.SetHelp(binding.Help().Key, desc)
+	return binding
+}
+
+// contextBindings returns the bindings most worth knowing about in the
+// current view and window, most useful first. A pending confirmation or the
+// marks of a bulk change take over, since other keys end them. Forms and the
+// help cover the whole screen, status bar included, and list their own keys.
+func (m *Model) contextBindings() []key.Binding {
+	k := m.keys
+	switch {
+	case m.state == TrashView && m.trashConfirm:
+		return []key.Binding{relabel(k.EmptyTrash, "confirm emptying"), relabel(k.Back, "cancel")}
+	case len(m.marked) > 0 && (m.state == ListsView || m.state == TasksView) && m.layout.GetFocusedWindowID() == MainWindow:
+		return []key.Binding{relabel(k.Mark, "mark/unmark"), relabel(k.Toggle, "complete"), k.SetPriority, relabel(k.Back, "clear marks")}
+	}
+
+	switch m.state {
+	case SettingsView:
+		return []key.Binding{relabel(k.Up, "pick"), relabel(k.Right, "change"), relabel(k.Back, "back")}
+	case TaskDetailView:
+		return []key.Binding{k.AddNote, k.Timer, k.EditTime, relabel(k.Back, "back")}
+	case TrashView:
+		return []key.Binding{relabel(k.Enter, "restore"), k.EmptyTrash, relabel(k.Back, "back")}
+	case OverdueView:
+		return []key.Binding{relabel(k.Enter, "jump to task"), k.Snooze, k.Star, relabel(k.Back, "back")}
+	case StarredView:
+		return []key.Binding{relabel(k.Enter, "jump to task"), relabel(k.Star, "unstar"), relabel(k.Back, "back")}
+	case CalendarView:
+		return []key.Binding{k.PrevMonth, k.NextMonth, relabel(k.Tab, "next task"), relabel(k.Enter, "jump to task"), relabel(k.Back, "back")}
+	case ActivityView:
+		return []key.Binding{relabel(k.Enter, "jump to item"), relabel(k.Back, "back")}
+	case ActivityLogView:
+		return []key.Binding{relabel(k.Tab, "today only"), relabel(k.Enter, "jump to item"), relabel(k.Back, "back")}
+	case LogView:
+		return []key.Binding{relabel(k.Down, "scroll"), relabel(k.Back, "back")}
+	}
+
+	if m.layout.GetFocusedWindowID() == SidebarWindow {
+		return []key.Binding{relabel(k.Enter, "open"), k.NewList, k.Edit, k.Delete}
+	}
+	if len(m.criticalOverdue) > 0 {
+		return []key.Binding{k.Dismiss, k.NewTask, k.Toggle, k.Edit}
+	}
+	return []key.Binding{k.NewTask, k.Toggle, k.Edit, k.Delete}
+}
+
+// renderKeyHints renders the context's key hints in at most width columns,
+// leaving out the last ones with an ellipsis when they do not fit. "? more"
+// always stays, even when that alone is wider.
+func (m *Model) renderKeyHints(width int) string {
+	var hints []string
+	for _, binding := range m.contextBindings() {
+		if len(hints) == statusHintLimit {
+			break
+		}
+		help := binding.Help()
+		hints = append(hints, KeyStyle.Render(help.Key)+" "+help.Desc)
+	}
+
+	more := KeyStyle.Render(m.keys.Help.Help().Key) + " more"
+	render := func(hints []string, cut bool) string {
+		parts := append([]string(nil), hints...)
+		if cut {
+			parts = append(parts, "…")
+		}
+		return strings.Join(append(parts, more), "  ")
+	}
+
+	rendered := render(hints, false)
+	for len(hints) > 0 && lipgloss.Width(rendered) > width {
+		hints = hints[:len(hints)-1]
+		rendered = render(hints, true)
+	}
+	return rendered
+}
@@ -0,0 +1,327 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ASCIIEnv forces the ascii icon set when true, or turns off its auto-detection when false
+const ASCIIEnv = "LAZYTODO_ASCII"
+
+// Icons holds every glyph the interface draws. Renderers use the active set
+// instead of literal emoji so the UI works on terminals without emoji fonts.
+type Icons struct {
+	// Task status and indicators
+	Incomplete       string
+	Complete         string
+	PriorityMedium   string
+	PriorityHigh     string
+	PriorityCritical string
+	Deadline         string
+	DueSoon          string
+	Overdue          string
+	ListBullet       string
+	GroupOpen        string // Sidebar group heading whose lists are shown
+	GroupClosed      string
+	Timer            string
+	Link             string
+	Times            string // Multiplier in counters such as "snoozed ×2"
+	Streak           string
+	Celebrate        string
+	Marked           string // Task marked for a bulk change
+	Quiet            string // Do not disturb is on
+	Unsaved          string // There are changes not saved yet
+	Stale            string // Open task sitting longer than stale_days
+	Due              string // Day of the calendar with tasks due
+	Pinned           string // List pinned to the top of the sidebar
+	Starred          string // Task starred for the daily plan
+
+	// Status message prefixes
+	Success string
+	Warning string
+	Failure string
+	Info    string
+
+	// Window titles and headings
+	App       string
+	Start     string
+	Lists     string
+	Tasks     string
+	Details   string
+	Activity  string
+	Templates string
+	Trash     string
+	Settings  string
+	Help      string
+	General   string
+	Palette   string
+	Create    string
+	Edit      string
+	ReadOnly  string
+	Error     string
+
+	// Welcome screen decorations
+	Sparkle  string
+	Progress string
+	Done     string
+	Storage  string
+	Theme    string
+}
+
+// emojiIcons is the default set
+var emojiIcons = Icons{
+	Incomplete:       "○",
+	Complete:         "✓",
+	PriorityMedium:   "⚡",
+	PriorityHigh:     "🔥",
+	PriorityCritical: "🚨",
+	Deadline:         "📅",
+	DueSoon:          "⏰",
+	Overdue:          "⚠️",
+	ListBullet:       "●",
+	GroupOpen:        "▾",
+	GroupClosed:      "▸",
+	Timer:            "⏱️",
+	Link:             "🔗",
+	Times:            "×",
+	Streak:           "🔥",
+	Celebrate:        "🎉",
+	Marked:           "☑",
+	Quiet:            "🔕",
+	Unsaved:          "●",
+	Stale:            "🕸",
+	Due:              "•",
+	Pinned:           "📌",
+	Starred:          "★",
+
+	Success: "✓",
+	Warning: "⚠",
+	Failure: "✗",
+	Info:    "ℹ",
+
+	App:       "🎯",
+	Start:     "🚀",
+	Lists:     "📋",
+	Tasks:     "📝",
+	Details:   "📓",
+	Activity:  "🕘",
+	Templates: "🧩",
+	Trash:     "🗑",
+	Settings:  "⚙️",
+	Help:      "❓",
+	General:   "🌐",
+	Palette:   "🔎",
+	Create:    "➕",
+	Edit:      "✏️",
+	ReadOnly:  "🔒",
+	Error:     "❌",
+
+	Sparkle:  "✨",
+	Progress: "📊",
+	Done:     "✅",
+	Storage:  "💾",
+	Theme:    "🎨",
+}
+
+// nerdIcons uses Font Awesome glyphs from a patched Nerd Font, which are one cell wide
+var nerdIcons = Icons{
+	Incomplete:       "\uf10c", // circle-o
+	Complete:         "\uf058", // check-circle
+	PriorityMedium:   "\uf0e7", // bolt
+	PriorityHigh:     "\uf06d", // fire
+	PriorityCritical: "\uf06a", // exclamation-circle
+	Deadline:         "\uf073", // calendar
+	DueSoon:          "\uf017", // clock-o
+	Overdue:          "\uf071", // exclamation-triangle
+	ListBullet:       "\uf111", // circle
+	GroupOpen:        "\uf107", // angle-down
+	GroupClosed:      "\uf105", // angle-right
+	Timer:            "\uf252", // hourglass-half
+	Link:             "\uf0c1", // link
+	Times:            "×",
+	Streak:           "\uf06d", // fire
+	Celebrate:        "\uf091", // trophy
+	Marked:           "\uf14a", // check-square
+	Quiet:            "\uf1f6", // bell-slash
+	Unsaved:          "\uf111", // circle
+	Stale:            "\uf1da", // history
+	Due:              "\uf111", // circle
+	Pinned:           "\uf08d", // thumb-tack
+	Starred:          "\uf005", // star
+
+	Success: "\uf00c", // check
+	Warning: "\uf071", // exclamation-triangle
+	Failure: "\uf00d", // times
+	Info:    "\uf05a", // info-circle
+
+	App:       "\uf140", // bullseye
+	Start:     "\uf135", // rocket
+	Lists:     "\uf03a", // list
+	Tasks:     "\uf0ae", // tasks
+	Details:   "\uf02d", // book
+	Activity:  "\uf1da", // history
+	Templates: "\uf24d", // clone
+	Trash:     "\uf1f8", // trash
+	Settings:  "\uf013", // cog
+	Help:      "\uf059", // question-circle
+	General:   "\uf0ac", // globe
+	Palette:   "\uf002", // search
+	Create:    "\uf067", // plus
+	Edit:      "\uf040", // pencil
+	ReadOnly:  "\uf023", // lock
+	Error:     "\uf057", // times-circle
+
+	Sparkle:  "\uf005", // star
+	Progress: "\uf080", // bar-chart
+	Done:     "\uf046", // check-square-o
+	Storage:  "\uf1c0", // database
+	Theme:    "\uf1fc", // paint-brush
+}
+
+// asciiIcons is plain ASCII; decorations are left out entirely
+var asciiIcons = Icons{
+	Incomplete:       "[ ]",
+	Complete:         "[x]",
+	PriorityMedium:   "!",
+	PriorityHigh:     "!!",
+	PriorityCritical: "!!!",
+	Deadline:         "@",
+	DueSoon:          "(soon)",
+	Overdue:          "[!]",
+	ListBullet:       "*",
+	GroupOpen:        "v",
+	GroupClosed:      ">",
+	Timer:            "(t)",
+	Link:             "(link)",
+	Times:            "x",
+	Marked:           "[*]",
+	Quiet:            "(dnd)",
+	Unsaved:          "*",
+	Stale:            "(stale)",
+	Due:              "#",
+	Pinned:           "^",
+	Starred:          "*",
+
+	Success: "+",
+	Warning: "!",
+	Failure: "x",
+	Info:    "i",
+
+	ReadOnly: "[ro]",
+	Error:    "[!]",
+}
+
+// IconSetNames lists the values accepted by the icons setting, in the order the settings view cycles through them
+var IconSetNames = []string{"emoji", "nerd", "ascii"}
+
+var iconSets = map[string]Icons{
+	"emoji": emojiIcons,
+	"nerd":  nerdIcons,
+	"ascii": asciiIcons,
+}
+
+// asciiLabels spells out the task label markers for the ascii set
+var asciiLabels = map[string]string{
+	"🔴": "(red)",
+	"🟠": "(orange)",
+	"🟡": "(yellow)",
+	"🟢": "(green)",
+	"🔵": "(blue)",
+	"🟣": "(purple)",
+	"⭐": "(star)",
+	"📌": "(pin)",
+	"💡": "(idea)",
+	"🐛": "(bug)",
+}
+
+// icons is the active icon set
+var icons = emojiIcons
+
+// asciiMode is set while the ascii icon set is active
+var asciiMode bool
+
+// SetIconSet activates the named icon set, falling back to emoji for unknown names
+func SetIconSet(name string) {
+	set, ok := iconSets[name]
+	if !ok {
+		name, set = "emoji", emojiIcons
+	}
+	icons = set
+	asciiMode = name == "ascii"
+}
+
+// DetectASCII reports whether the terminal is unlikely to draw emoji: the
+// Linux console and dumb terminals can't, and neither can a non-UTF-8 locale.
+// ASCIIEnv overrides the guess either way.
+func DetectASCII() bool {
+	if value, ok := os.LookupEnv(ASCIIEnv); ok {
+		ascii, err := strconv.ParseBool(value)
+		return err == nil && ascii
+	}
+
+	switch os.Getenv("TERM") {
+	case "linux", "dumb", "vt100", "vt220":
+		return true
+	}
+	if runtime.GOOS == "windows" {
+		return false
+	}
+
+	// The first locale variable that is set decides, as in setlocale
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			locale = strings.ToLower(locale)
+			return !strings.Contains(locale, "utf-8") && !strings.Contains(locale, "utf8")
+		}
+	}
+	return false
+}
+
+// labelText returns a task label as the active set draws it; in ascii mode the
+// emoji markers are spelled out and other non-ASCII labels are replaced
+func labelText(label string) string {
+	if !asciiMode || label == "" {
+		return label
+	}
+	if text, ok := asciiLabels[label]; ok {
+		return text
+	}
+	for _, r := range label {
+		if r > 127 {
+			return "(label)"
+		}
+	}
+	return label
+}
+
+// withIcon prefixes text with an icon, leaving the text alone when the set has no icon for it
+func withIcon(icon, text string) string {
+	if icon == "" {
+		return text
+	}
+	return icon + " " + text
+}
+
+// snoozeBadge describes how often a task was snoozed
+func snoozeBadge(count int) string {
+	return fmt.Sprintf("snoozed %s%d", icons.Times, count)
+}
+
+// indicatorLegend explains the task indicators of the active set for the help window
+func indicatorLegend() map[string]string {
+	return map[string]string{
+		icons.Incomplete:       "Open task",
+		icons.Complete:         "Completed task",
+		icons.PriorityMedium:   "Medium priority",
+		icons.PriorityHigh:     "High priority",
+		icons.PriorityCritical: "Critical priority",
+		icons.DueSoon:          "Due soon",
+		icons.Overdue:          "Overdue",
+		icons.Timer:            "Time spent/estimate",
+		icons.Link:             "Has a link",
+		icons.Stale:            "Open for long, in days",
+	}
+}
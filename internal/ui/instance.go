@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// Choices of the prompt shown while another session holds the lock, in the
+// order they are listed
+const (
+	instanceReadOnly = iota
+	instanceQuit
+	instanceChoices
+)
+
+// instanceChoiceLabels names the choices of the prompt shown while another
+// session holds the lock
+var instanceChoiceLabels = [instanceChoices]string{"Open read-only", "Quit"}
+
+// instanceRunningMsg reports that another session holds the data directory's
+// lock, so loading waits for the user to say whether to browse read-only
+type instanceRunningMsg struct{ err error }
+
+// lockData takes the data directory's lock unless the session is read-only
+// or holds it already. It returns ErrInstanceRunning when another session
+// has it; other failures only leave the data unprotected, so they are logged.
+func (m *Model) lockData() error {
+	if m.opts.ReadOnly || m.lock != nil {
+		return nil
+	}
+	lock, err := storage.AcquireLock()
+	if errors.Is(err, storage.ErrInstanceRunning) {
+		return err
+	}
+	if err != nil {
+		m.log.add(logWarning, fmt.Sprintf("Another LazyTodo window could overwrite changes: %v", err))
+		return nil
+	}
+	m.lock = lock
+	return nil
+}
+
+// Instance prompt - asks whether to browse read-only while another session writes the data
+func (m *Model) updateInstancePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit, m.keys.Back):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Up, m.keys.ShiftTab):
+		m.instanceCursor = (m.instanceCursor + instanceChoices - 1) % instanceChoices
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down, m.keys.Tab):
+		m.instanceCursor = (m.instanceCursor + 1) % instanceChoices
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		if m.instanceCursor != instanceReadOnly {
+			return m, tea.Quit
+		}
+		m.instanceErr = nil
+		m.opts.ReadOnly = true
+		return m, m.loadData()
+	}
+
+	return m, nil
+}
+
+// renderInstancePrompt renders the full-screen question shown while another
+// session holds the data directory's lock
+func (m *Model) renderInstancePrompt() string {
+	explanation := lipgloss.NewStyle().
+		Width(m.width * 2 / 3).
+		Render(fmt.Sprintf("%v.\n\n"+
+			"Saving from two windows at once would overwrite the changes of one with the other's, "+
+			"so only one of them can change the data. Open it read-only to browse it here, "+
+			"or quit and use the other window.\n\n"+
+			"If no other LazyTodo is running, the lock was left by one that was killed; "+
+			"it is taken over once its process has exited.", m.instanceErr))
+
+	lines := []string{
+		BaseTitleStyle.Render(withIcon(icons.Warning, "LazyTodo is already running")),
+		"",
+		explanation,
+		"",
+	}
+	for i, label := range instanceChoiceLabels {
+		if i == m.instanceCursor {
+			lines = append(lines, ListItemSelected.Render("> "+label))
+		} else {
+			lines = append(lines, ListItemNormal.Render("  "+label))
+		}
+	}
+	lines = append(lines, "", DescStyle.Render("↑/↓: choose • Enter: confirm • Esc: quit"))
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -1,7 +1,10 @@
 package ui
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // WindowID represents different window types
@@ -51,8 +54,12 @@ type Layout struct {
 	currentFocus int
 	screenWidth  int
 	screenHeight int
+	sidebarWidth int // Columns asked for the sidebar; 0 sizes it to the screen
 }
 
+// minSetSidebarWidth is the narrowest a sidebar width that was set can make the sidebar
+const minSetSidebarWidth = 20
+
 // NewLayout creates a new layout manager
 func NewLayout() *Layout {
 	return &Layout{
@@ -69,6 +76,21 @@ func (l *Layout) SetScreenSize(width, height int) {
 	l.calculateLayout()
 }
 
+// SetSidebarWidth sets the sidebar width in columns and recalculates layout;
+// 0 goes back to sizing it to the screen
+func (l *Layout) SetSidebarWidth(width int) {
+	l.sidebarWidth = width
+	l.calculateLayout()
+}
+
+// SidebarWidth returns the columns the sidebar takes up after clamping, 0 when hidden
+func (l *Layout) SidebarWidth() int {
+	if sidebar := l.windows[SidebarWindow]; sidebar != nil && sidebar.Visible {
+		return sidebar.Position.Width
+	}
+	return 0
+}
+
 // AddWindow adds a window to the layout
 func (l *Layout) AddWindow(window *Window) {
 	l.windows[window.ID] = window
@@ -166,20 +188,24 @@ func (l *Layout) GetFocusedWindowID() WindowID {
 
 // SetFocus sets focus to a specific window
 func (l *Layout) SetFocus(id WindowID) {
+	// Hidden windows cannot take focus, so keep the current one
+	window := l.windows[id]
+	if window == nil || !window.Visible {
+		return
+	}
+
 	// Clear all focus
-	for _, window := range l.windows {
-		window.Focused = false
+	for _, other := range l.windows {
+		other.Focused = false
 	}
 
 	// Set focus to target window
-	if window := l.windows[id]; window != nil && window.Visible {
-		window.Focused = true
-		// Update current focus index
-		for i, focusID := range l.focusOrder {
-			if focusID == id {
-				l.currentFocus = i
-				break
-			}
+	window.Focused = true
+	// Update current focus index
+	for i, focusID := range l.focusOrder {
+		if focusID == id {
+			l.currentFocus = i
+			break
 		}
 	}
 }
@@ -214,6 +240,19 @@ func (l *Layout) calculateLayout() {
 		sidebarWidth = 50
 	}
 
+	// A width that was set is kept within what leaves the main window usable
+	if l.sidebarWidth > 0 {
+		sidebarWidth = max(min(l.sidebarWidth, l.screenWidth-minMainWidth), min(minSetSidebarWidth, minSidebarWidth))
+	}
+
+	// Hidden panes give their space to the main window
+	if sidebar := l.windows[SidebarWindow]; sidebar != nil && !sidebar.Visible {
+		sidebarWidth = 0
+	}
+	if status := l.windows[StatusWindow]; status != nil && !status.Visible {
+		statusHeight = 0
+	}
+
 	mainWidth := l.screenWidth - sidebarWidth
 	mainHeight := l.screenHeight - statusHeight
 
@@ -361,17 +400,34 @@ func (l *Layout) renderWindow(window *Window) string {
 		borderStyle = window.Style.Unfocused
 	}
 
+	// Calculate available dimensions
+	availableWidth := window.Position.Width
+	availableHeight := window.Position.Height
+
 	content := window.Content
+	styled := content != "" && window.Style.Content.GetForeground() != lipgloss.Color("")
+
+	// Content is wrapped inside the padding, long words broken; lines past the
+	// bottom of a tiled window are cut, so it cannot grow taller than the
+	// screen and shear the columns apart. Overlays size to their content.
+	innerWidth, innerHeight := availableWidth, availableHeight
+	if window.Border {
+		innerWidth, innerHeight = innerWidth-2, innerHeight-2
+	}
+	if styled {
+		innerWidth -= window.Style.Content.GetHorizontalPadding()
+		innerHeight -= window.Style.Content.GetVerticalPadding()
+	}
+	if window.ID == HelpWindow || window.ID == FormWindow {
+		innerHeight = 0
+	}
+	content = fitContent(content, innerWidth, innerHeight)
 
 	// Apply content styling if content exists
-	if content != "" && window.Style.Content.GetForeground() != lipgloss.Color("") {
+	if styled {
 		content = window.Style.Content.Render(content)
 	}
 
-	// Calculate available dimensions
-	availableWidth := window.Position.Width
-	availableHeight := window.Position.Height
-
 	// Apply border and sizing
 	if window.Border {
 		content = borderStyle.
@@ -387,3 +443,17 @@ func (l *Layout) renderWindow(window *Window) string {
 
 	return content
 }
+
+// fitContent wraps content to width columns and keeps at most height lines of
+// it; a width or height that is not positive leaves that dimension alone
+func fitContent(content string, width, height int) string {
+	if width > 0 {
+		content = ansi.Wrap(content, width, "")
+	}
+	if height > 0 {
+		if lines := strings.Split(content, "\n"); len(lines) > height {
+			content = strings.Join(lines[:height], "\n")
+		}
+	}
+	return content
+}
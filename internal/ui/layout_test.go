@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+func TestFitContent(t *testing.T) {
+	word := strings.Repeat("abcdefghij", 5)
+	colored := "\x1b[31m" + word + "\x1b[0m"
+	for _, tt := range []struct {
+		name          string
+		content       string
+		width, height int
+		lines         int
+	}{
+		{"long word", word, 10, 0, 5},
+		{"long word in color", colored, 10, 0, 5},
+		{"wide characters", strings.Repeat("日本", 10), 10, 0, 4},
+		{"cut at the bottom", word, 10, 3, 3},
+		{"short lines", "one\ntwo", 10, 5, 2},
+		{"no width", word, 0, 0, 1},
+	} {
+		got := fitContent(tt.content, tt.width, tt.height)
+		lines := strings.Split(got, "\n")
+		if len(lines) != tt.lines {
+			t.Errorf("%s: %d lines, want %d:\n%s", tt.name, len(lines), tt.lines, got)
+		}
+		for _, line := range lines {
+			if tt.width > 0 && ansi.StringWidth(line) > tt.width {
+				t.Errorf("%s: line %q is %d columns, want at most %d", tt.name, line, ansi.StringWidth(line), tt.width)
+			}
+		}
+	}
+	// The color carries on across the wrapped lines rather than being cut up
+	if got := ansi.Strip(fitContent(colored, 10, 0)); got != ansi.Strip(fitContent(word, 10, 0)) {
+		t.Errorf("escape sequences changed the wrapping:\n%s", got)
+	}
+}
+
+// checkScreen fails unless view fills exactly the width and height of the
+// terminal, without control characters that would move the cursor
+func checkScreen(t *testing.T, name string, view string, width, height int) {
+	t.Helper()
+	lines := strings.Split(view, "\n")
+	if len(lines) != height {
+		t.Errorf("%s at %dx%d: %d lines, want %d", name, width, height, len(lines), height)
+	}
+	for i, line := range lines {
+		if got := ansi.StringWidth(line); got > width {
+			t.Errorf("%s at %dx%d: line %d is %d columns wide: %q", name, width, height, i+1, got, ansi.Strip(line))
+			break
+		}
+		if strings.ContainsAny(ansi.Strip(line), "\t\r\a") {
+			t.Errorf("%s at %dx%d: line %d has control characters: %q", name, width, height, i+1, ansi.Strip(line))
+			break
+		}
+	}
+}
+
+func TestViewKeepsToTheScreenWithUnrulyTitles(t *testing.T) {
+	url := "https://example.com/" + strings.Repeat("very-long-path-segment/", 13)
+	// Titles stored before they were cleaned on entry may carry all of these
+	titles := []string{
+		url,
+		"\x1b[31mRed\x1b[0m title with \x1b[1mbold\x1b[0m",
+		"Tabbed\ttitle\rwith a carriage return",
+		strings.Repeat("Supercalifragilistic", 10),
+	}
+
+	for _, width := range []int{60, 100, 140} {
+		t.Run(fmt.Sprint(width), func(t *testing.T) {
+			const height = 30
+			m := newTestModel(t, &testClock{now: time.Now()})
+			m.Update(tea.WindowSizeMsg{Width: width, Height: height})
+			overdue := models.WallClock(time.Now()).Add(-time.Hour)
+			for i, title := range titles {
+				task := mustAddTask(t, m, fmt.Sprintf("Task %d", i), &overdue)
+				task.Title = title
+				task.Description = url + "\n\x1b[32m" + title + "\x1b[0m"
+				task.Starred = true
+				m.putTask(m.currentListID, task)
+			}
+			m.switchToList(m.currentListID)
+			checkScreen(t, "task list", m.View(), width, height)
+
+			for i := range titles {
+				press(m, tea.KeyMsg{Type: tea.KeyEnter})
+				if m.state != TaskDetailView {
+					t.Fatalf("Enter on task %d did not open its details", i)
+				}
+				checkScreen(t, fmt.Sprintf("details of task %d", i), m.View(), width, height)
+				press(m, tea.KeyMsg{Type: tea.KeyEscape}, keyDown)
+			}
+
+			m.openOverdueView()
+			checkScreen(t, "overdue view", m.View(), width, height)
+			m.openStarredView()
+			checkScreen(t, "starred view", m.View(), width, height)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// lingerChoices are the seconds the settings view cycles through for how long
+// a task just completed stays listed while completed tasks are hidden; 0
+// hides it at once
+var lingerChoices = []int{0, 3, 5, 10, 30}
+
+// nextCompletedLinger returns the linger time step places away from seconds;
+// a time set outside the choices starts from off
+func nextCompletedLinger(seconds, step int) int {
+	for i, choice := range lingerChoices {
+		if choice == seconds {
+			return lingerChoices[(i+step+len(lingerChoices))%len(lingerChoices)]
+		}
+	}
+	return lingerChoices[0]
+}
+
+// lingerLabel describes the linger time for the settings view
+func lingerLabel(seconds int) string {
+	if seconds <= 0 {
+		return "hidden at once"
+	}
+	return fmt.Sprintf("greyed out for %d seconds", seconds)
+}
+
+// lingerExpiredMsg asks for the completed tasks whose linger time is up to be
+// hidden
+type lingerExpiredMsg struct{}
+
+// lingerCompleted keeps a task just completed in its place in the tasks list,
+// greyed out and struck through, for the completed_linger setting while
+// completed tasks are hidden, so that it does not vanish from under the
+// cursor. It returns the command that hides it once the time is up.
+func (m *Model) lingerCompleted(task models.Task) tea.Cmd {
+	seconds := m.app.Settings.CompletedLinger
+	if !task.Completed || m.app.Settings.ShowCompleted || seconds <= 0 {
+		return nil
+	}
+
+	linger := time.Duration(seconds) * time.Second
+	m.lingering[task.ID] = m.now().Add(linger)
+	return tea.Tick(linger, func(time.Time) tea.Msg {
+		return lingerExpiredMsg{}
+	})
+}
+
+// expireLingering hides the completed tasks whose linger time is up
+func (m *Model) expireLingering() {
+	now := m.now()
+	expired := false
+	for taskID, until := range m.lingering {
+		if !now.Before(until) {
+			delete(m.lingering, taskID)
+			expired = true
+		}
+	}
+	if expired {
+		m.updateTasksList()
+	}
+}
@@ -0,0 +1,226 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// openLink opens a task link with the default browser or application
+func openLink(link string) tea.Cmd {
+	var name string
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		name, args = "open", []string{link}
+	case "windows":
+		name, args = "rundll32", []string{"url.dll,FileProtocolHandler", link}
+	default:
+		name, args = "xdg-open", []string{link}
+	}
+
+	return func() tea.Msg {
+		cmd := exec.Command(name, args...)
+		if err := cmd.Start(); err != nil {
+			return errorMsg(fmt.Sprintf("Failed to open link: %v", err))
+		}
+		// Openers may stay around until the application closes
+		go cmd.Wait()
+		return nil
+	}
+}
+
+// openTaskLink opens the link of a task, if it has one
+func (m *Model) openTaskLink(task *models.Task) tea.Cmd {
+	if task == nil {
+		m.showMessageWithType("Select a task first", "warning")
+		return nil
+	}
+	if task.Link == "" {
+		m.showMessageWithType("Task has no link", "warning")
+		return nil
+	}
+
+	m.showMessageWithType("Opening "+task.Link, "info")
+	return openLink(task.Link)
+}
+
+// openLinkForm opens the link prompt for the detail task
+func (m *Model) openLinkForm() {
+	task := m.getDetailTask()
+	if task == nil {
+		return
+	}
+
+	m.linkInput.SetValue(task.Link)
+	m.linkInput.CursorEnd()
+	m.linkInput.Focus()
+	m.state = EditLinkView
+}
+
+// Link form - sets or clears the link of the detail task
+func (m *Model) updateLinkForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.linkInput.Blur()
+		m.state = TaskDetailView
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		link, err := models.ParseLink(m.linkInput.Value())
+		if err != nil {
+			m.showMessageWithType("Invalid link: use a URL such as https://… or an existing path", "warning")
+			return m, nil
+		}
+
+		task := m.getDetailTask()
+		if task == nil {
+			m.state = TasksView
+			return m, nil
+		}
+
+		updated, err := m.storage.SetTaskLink(m.app, m.currentListID, task.ID, link)
+		if err != nil {
+			m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+			return m, nil
+		}
+		m.putTask(m.currentListID, updated)
+
+		m.linkInput.Blur()
+		m.updateTasksList()
+		m.state = TaskDetailView
+		if link == "" {
+			m.showMessageWithType("Link removed", "success")
+		} else {
+			m.showMessageWithType("Link saved", "success")
+		}
+		return m, m.saveData()
+	}
+
+	var cmd tea.Cmd
+	m.linkInput, cmd = m.linkInput.Update(msg)
+	return m, cmd
+}
+
+// renderLinkFormContent renders the link prompt
+func (m *Model) renderLinkFormContent() string {
+	m.layout.SetWindowTitle(FormWindow, withIcon(icons.Link, "Task Link"))
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render("Task Link"))
+	lines = append(lines, "")
+	lines = append(lines, FormLabel.Render("URL or file path (empty to remove):"))
+	lines = append(lines, FormFieldFocused.Render(m.linkInput.View()))
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render("Enter: Save • Esc: Cancel"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// openTaskURL opens the URL in a task's title or description, asking which
+// one when there are several
+func (m *Model) openTaskURL(task *models.Task) tea.Cmd {
+	if task == nil {
+		m.showMessageWithType("Select a task first", "warning")
+		return nil
+	}
+
+	urls := task.URLs()
+	switch len(urls) {
+	case 0:
+		m.showMessageWithType("No URL in the task's title or description", "warning")
+		return nil
+	case 1:
+		m.showMessageWithType("Opening "+urls[0], "info")
+		return openLink(urls[0])
+	}
+
+	m.urlChoices = urls
+	m.urlCursor = 0
+	m.urlReturn = m.state
+	m.state = URLChooserView
+	return nil
+}
+
+// URL chooser - picks which of a task's URLs to open
+func (m *Model) updateURLChooser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	choice := -1
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = m.urlReturn
+		return m, nil
+	case key.Matches(msg, m.keys.Up):
+		if m.urlCursor > 0 {
+			m.urlCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.urlCursor < len(m.urlChoices)-1 {
+			m.urlCursor++
+		}
+	case key.Matches(msg, m.keys.Enter):
+		choice = m.urlCursor
+	default:
+		// Number keys pick a URL directly
+		if n, err := strconv.Atoi(msg.String()); err == nil && n >= 1 && n <= len(m.urlChoices) {
+			choice = n - 1
+		}
+	}
+	if choice < 0 {
+		return m, nil
+	}
+
+	m.state = m.urlReturn
+	m.showMessageWithType("Opening "+m.urlChoices[choice], "info")
+	return m, openLink(m.urlChoices[choice])
+}
+
+// renderURLChooserContent renders the list of URLs to open
+func (m *Model) renderURLChooserContent() string {
+	m.layout.SetWindowTitle(FormWindow, withIcon(icons.Link, "Open URL"))
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render("Open URL"))
+	lines = append(lines, "")
+	for i, u := range m.urlChoices {
+		line := fmt.Sprintf("%d  %s", i+1, u)
+		if i == m.urlCursor {
+			lines = append(lines, ListItemSelected.Render(line))
+		} else {
+			lines = append(lines, ListItemNormal.Render(line))
+		}
+	}
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render("↑/↓: select • Enter or 1-9: open • Esc: cancel"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// highlightURLs renders text with style, drawing the URLs in it with URLStyle.
+// Lines are rendered one by one so styled pieces are not padded to a block.
+func highlightURLs(text string, style lipgloss.Style) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		var b strings.Builder
+		last := 0
+		for _, span := range models.URLIndexes(line) {
+			if span[0] > last {
+				b.WriteString(style.Render(line[last:span[0]]))
+			}
+			b.WriteString(URLStyle.Render(line[span[0]:span[1]]))
+			last = span[1]
+		}
+		if last < len(line) || last == 0 {
+			b.WriteString(style.Render(line[last:]))
+		}
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
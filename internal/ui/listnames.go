@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// showListError shows why a list could not be created or renamed; a name
+// refused for being taken is the user's to fix, so it is only a warning
+func (m *Model) showListError(err error) {
+	if errors.Is(err, storage.ErrDuplicateListName) {
+		m.showMessageWithType(fmt.Sprintf("%v - pick another name", err), "warning")
+		return
+	}
+	m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+}
+
+// warnDuplicateListName warns when another list has the name the list with
+// ID listID was just given, since lookups by name then have to ask which one
+// is meant. It reports whether it did.
+func (m *Model) warnDuplicateListName(listID, name string) bool {
+	if !storage.ListNameTaken(m.app, name, listID) {
+		return false
+	}
+	m.showMessageWithType(fmt.Sprintf("Another list is also named %q; lookups by name will ask which one is meant", name), "warning")
+	return true
+}
+
+// uniqueListNamesLabel describes the unique list names setting
+func uniqueListNamesLabel(unique bool) string {
+	if unique {
+		return "refused"
+	}
+	return "warned about"
+}
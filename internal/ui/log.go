@@ -0,0 +1,174 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// logCapacity is how many entries the log keeps; older ones are dropped
+const logCapacity = 200
+
+// Severities of log entries
+const (
+	logInfo    = "info"
+	logWarning = "warning"
+	logError   = "error"
+)
+
+// logEntry is one message kept in the session log
+type logEntry struct {
+	time  time.Time
+	level string
+	text  string
+}
+
+// logBuffer keeps the latest log entries in a ring. It is also the writer
+// storage reports progress and warnings to, which would be invisible behind
+// the alt screen; storage writes from background commands, hence the lock.
+type logBuffer struct {
+	mu      sync.Mutex
+	entries []logEntry
+	next    int    // Slot the next entry goes into once the ring is full
+	partial string // Text written without its line break yet
+}
+
+// add appends an entry, dropping the oldest one when the log is full
+func (b *logBuffer) add(level, text string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.addLocked(level, text)
+}
+
+// addLocked is add for callers that hold the lock
+func (b *logBuffer) addLocked(level, text string) {
+	entry := logEntry{time: time.Now(), level: level, text: text}
+	if len(b.entries) < logCapacity {
+		b.entries = append(b.entries, entry)
+		return
+	}
+	b.entries[b.next] = entry
+	b.next = (b.next + 1) % logCapacity
+}
+
+// Write logs each complete line, with the severity its prefix names
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lines := strings.Split(b.partial+string(p), "\n")
+	b.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		level := logInfo
+		if text, ok := strings.CutPrefix(line, "Warning: "); ok {
+			level, line = logWarning, text
+		} else if text, ok := strings.CutPrefix(line, "Error: "); ok {
+			level, line = logError, text
+		}
+		b.addLocked(level, line)
+	}
+	return len(p), nil
+}
+
+// newestFirst returns a copy of the entries, most recent first
+func (b *logBuffer) newestFirst() []logEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries := make([]logEntry, 0, len(b.entries))
+	for i := len(b.entries) - 1; i >= 0; i-- {
+		entries = append(entries, b.entries[(b.next+i)%len(b.entries)])
+	}
+	return entries
+}
+
+// openLogView shows the session log in the main window
+func (m *Model) openLogView() {
+	m.logEntries = m.log.newestFirst()
+	m.logCursor = 0
+	m.state = LogView
+	m.layout.SetFocus(MainWindow)
+}
+
+// Log view - browse the storage messages and errors of this session
+func (m *Model) updateLogView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = TasksView
+	case key.Matches(msg, m.keys.Up):
+		if m.logCursor > 0 {
+			m.logCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.logCursor < len(m.logEntries)-1 {
+			m.logCursor++
+		}
+	}
+	return m, nil
+}
+
+// renderLogContent renders the session log, newest entry first
+func (m *Model) renderLogContent() string {
+	m.layout.SetWindowTitle(MainWindow, withIcon(icons.Warning, "Log"))
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render(withIcon(icons.Warning, fmt.Sprintf("Log (%d)", len(m.logEntries)))))
+	lines = append(lines, BaseSubtitleStyle.Render(fmt.Sprintf("Storage messages and errors of this session; the last %d are kept", logCapacity)))
+	lines = append(lines, "")
+
+	if len(m.logEntries) == 0 {
+		lines = append(lines, BaseSubtitleStyle.Render("Nothing has gone wrong so far"))
+	}
+
+	// Keep the cursor inside the rows that fit in the window, leaving room
+	// for the selected entry in full below them
+	visible, width := 8, 60
+	if mainWindow := m.layout.GetWindow(MainWindow); mainWindow != nil {
+		visible = max(visible, mainWindow.Position.Height-16)
+		width = max(20, mainWindow.Position.Width-6)
+	}
+	start := 0
+	if m.logCursor >= visible {
+		start = m.logCursor - visible + 1
+	}
+	end := min(start+visible, len(m.logEntries))
+
+	mutedStyle := lipgloss.NewStyle().Foreground(TextMuted)
+	levelStyles := map[string]lipgloss.Style{
+		logInfo:    lipgloss.NewStyle().Foreground(InfoColor),
+		logWarning: lipgloss.NewStyle().Foreground(WarningColor),
+		logError:   lipgloss.NewStyle().Foreground(ErrorColor).Bold(true),
+	}
+	for i := start; i < end; i++ {
+		entry := m.logEntries[i]
+		line := ansi.Truncate(mutedStyle.Render(entry.time.Format("15:04:05"))+" "+
+			levelStyles[entry.level].Render(fmt.Sprintf("%-7s", strings.ToUpper(entry.level)))+" "+entry.text, width, "…")
+
+		if i == m.logCursor {
+			lines = append(lines, ListItemSelected.Render(line))
+		} else {
+			lines = append(lines, ListItemNormal.Render(line))
+		}
+	}
+
+	if m.logCursor < len(m.logEntries) {
+		lines = append(lines, "")
+		lines = append(lines, DescStyle.Width(width).Render(m.logEntries[m.logCursor].text))
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render("↑/↓: scroll • Esc: back to tasks"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// maintenanceDoneMsg reports a finished maintenance run
+type maintenanceDoneMsg struct {
+	report storage.MaintenanceReport
+	err    error
+}
+
+// startMaintenance checks and compacts the database in the background, as
+// lazytodo --maintenance does. It waits for saves and a git sync to finish
+// first, and changes are held back until it is done.
+func (m *Model) startMaintenance() tea.Cmd {
+	switch {
+	case m.readOnly:
+		m.showMessageWithType("Read-only mode: changes are disabled", "warning")
+		return nil
+	case m.maintaining:
+		m.showMessageWithType("Maintenance is already running", "warning")
+		return nil
+	case m.savesPending > 0 || m.syncing:
+		m.showMessageWithType("Changes are still being written - try again in a moment", "warning")
+		return nil
+	}
+
+	m.maintaining = true
+	m.showMessage("Running maintenance...")
+
+	store, app := m.storage, m.app
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		report, err := storage.Maintain(store, app)
+		return maintenanceDoneMsg{report: report, err: err}
+	})
+}
+
+// finishMaintenance reports a finished maintenance run and saves the changes
+// held back meanwhile
+func (m *Model) finishMaintenance(msg maintenanceDoneMsg) tea.Cmd {
+	m.maintaining = false
+	if msg.err != nil {
+		m.maintenanceResult = "failed"
+		m.showMessageWithType(fmt.Sprintf("Maintenance failed: %v", msg.err), "error")
+		m.log.add(logError, fmt.Sprintf("Maintenance failed: %v", msg.err))
+	} else {
+		m.maintenanceResult = fmt.Sprintf("%s, %s to %s, %d purged from the trash",
+			m.now().Format("15:04"), models.FormatBytes(msg.report.SizeBefore), models.FormatBytes(msg.report.SizeAfter), msg.report.PurgedTasks)
+		m.showMessageWithType(fmt.Sprintf("Maintenance done: %s to %s", models.FormatBytes(msg.report.SizeBefore), models.FormatBytes(msg.report.SizeAfter)), "success")
+		if len(msg.report.PrunedBackups) > 0 {
+			m.log.add(logInfo, fmt.Sprintf("Maintenance removed %d old backup(s)", len(msg.report.PrunedBackups)))
+		}
+	}
+
+	return m.saveHeldChanges()
+}
+
+// changesHeld reports, in the status bar as well, that changes wait while
+// maintenance or a git sync runs, either of which works on the data in the
+// background
+func (m *Model) changesHeld() bool {
+	switch {
+	case m.maintaining:
+		m.showMessageWithType("Maintenance is running - changes wait until it is done", "warning")
+	case m.syncing:
+		m.showMessageWithType("Git sync is running - changes wait until it is done", "warning")
+	default:
+		return false
+	}
+	return true
+}
+
+// maintenanceLabel describes the maintenance row of the settings view
+func (m *Model) maintenanceLabel() string {
+	switch {
+	case m.maintaining:
+		return m.spinner.View() + " running"
+	case m.maintenanceResult != "":
+		return "last run " + m.maintenanceResult + " (Enter to run again)"
+	default:
+		return "press Enter to check and compact the database"
+	}
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// Choices of the migration prompt, in the order they are listed
+const (
+	migrateNow = iota
+	migrateKeepJSON
+	migrateQuit
+	migrateChoices
+)
+
+// migrationChoiceLabels names the migration prompt's choices
+var migrationChoiceLabels = [migrateChoices]string{"Migrate now", "Keep using JSON", "Quit"}
+
+// migrationPendingMsg reports that v1.x JSON data was found without a database,
+// so loading waits for the user to say what to do with it. It carries what
+// migrating would add.
+type migrationPendingMsg struct {
+	plan storage.ImportPlan
+}
+
+// Migration prompt - asks whether to migrate v1.x JSON data to the database
+func (m *Model) updateMigrationPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit, m.keys.Back):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Up, m.keys.ShiftTab):
+		m.migrationCursor = (m.migrationCursor + migrateChoices - 1) % migrateChoices
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down, m.keys.Tab):
+		m.migrationCursor = (m.migrationCursor + 1) % migrateChoices
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		return m, m.chooseMigration(m.migrationCursor)
+	}
+
+	return m, nil
+}
+
+// chooseMigration acts on the migration prompt's answer and resumes loading
+func (m *Model) chooseMigration(choice int) tea.Cmd {
+	switch choice {
+	case migrateNow:
+		m.opts.MigrateJSON = true
+		m.loadingText = "Migrating data…"
+	case migrateKeepJSON:
+		if err := storage.KeepJSON(); err != nil {
+			m.migrationPrompt = false
+			m.loading = false
+			m.loadErr = err
+			return nil
+		}
+	default:
+		return tea.Quit
+	}
+
+	m.migrationPrompt = false
+	return m.loadData()
+}
+
+// renderMigrationPrompt renders the full-screen question shown when v1.x JSON
+// data has not been migrated to the database
+func (m *Model) renderMigrationPrompt() string {
+	jsonPath := storage.LegacyJSONPath()
+	dataDir := filepath.Dir(jsonPath)
+
+	explanation := lipgloss.NewStyle().
+		Width(m.width * 2 / 3).
+		Render(fmt.Sprintf("Found data from LazyTodo v1.x in %s.\n\n"+
+			"Migrating copies every list, task and template into the SQLite database %s "+
+			"and renames the JSON file to %s.backup.<date>, so it is kept as a backup.\n\n"+
+			"Keeping JSON goes on using the file as it is; run lazytodo --migrate to migrate it later.",
+			jsonPath, filepath.Join(dataDir, storage.DatabaseName), storage.DataFileName))
+	preview := ""
+	if m.migrationPlan.Changed() {
+		preview = lipgloss.NewStyle().Foreground(SuccessColor).Width(m.width * 2 / 3).
+			Render("Preview: " + m.migrationPlan.Summary())
+	}
+
+	lines := []string{
+		BaseTitleStyle.Render(withIcon(icons.App, "Migrate to the database?")),
+		"",
+		explanation,
+		"",
+	}
+	if preview != "" {
+		lines = append(lines, preview, "")
+	}
+	for i, label := range migrationChoiceLabels {
+		if i == m.migrationCursor {
+			lines = append(lines, ListItemSelected.Render("> "+label))
+		} else {
+			lines = append(lines, ListItemNormal.Render("  "+label))
+		}
+	}
+	lines = append(lines, "", DescStyle.Render("↑/↓: choose • Enter: confirm • Esc: quit"))
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
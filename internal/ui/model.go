@@ -1,15 +1,20 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/DhirajZope/lazytodo/internal/config"
 	"github.com/DhirajZope/lazytodo/internal/models"
 	"github.com/DhirajZope/lazytodo/internal/storage"
 )
@@ -26,13 +31,93 @@ const (
 	EditTaskView
 	SettingsView
 	HelpView
+	TaskDetailView
+	AddNoteView
+	CommandPaletteView
+	SetDeadlineView
+	ActivityView
+	TemplateNameView
+	TemplatesView
+	SnoozeView
+	OverdueView
+	EditTimeView
+	SetupView
+	EditLinkView
+	ShiftDeadlinesView
+	EditReminderView
+	TrashView
+	URLChooserView
+	PriorityView
+	LogView
+	DoNotDisturbView
+	CalendarView
+	ActivityLogView
+	ConfirmView
+	WelcomeView
+	StarredView
 )
 
+// Options configures how the application model is created
+type Options struct {
+	// ReadOnly opens storage without write access and disables mutating actions
+	ReadOnly bool
+
+	// Passphrase unlocks an encrypted database
+	Passphrase string
+
+	// OpenListID is the list whose tasks are shown first instead of the first list's
+	OpenListID string
+
+	// OpenTaskID is the task selected at start, in whichever list it is;
+	// an unknown ID only warns
+	OpenTaskID string
+
+	// ASCII draws plain ASCII markers whatever the icons setting says, for
+	// terminals that render emoji as boxes or at the wrong width
+	ASCII bool
+
+	// Light draws the palette in its shades for light terminal backgrounds
+	Light bool
+
+	// MigrateJSON migrates v1.x JSON data to the database without asking
+	MigrateJSON bool
+
+	// Config holds the settings of the config file, which win over those
+	// stored in the database; nil when there is none
+	Config *config.Config
+}
+
 // Model represents the main application model
 type Model struct {
 	// Application state
-	app     *models.Application
-	storage storage.StorageInterface
+	app      *models.Application
+	storage  storage.StorageInterface
+	readOnly bool
+
+	// Startup loading; storage and app are nil until loading finishes
+	opts        Options
+	loading     bool
+	loadingText string
+	loadErr     error
+	spinner     spinner.Model
+
+	// Whether the migration prompt is shown instead, and its selected choice
+	migrationPrompt bool
+	migrationCursor int
+	migrationPlan   storage.ImportPlan
+
+	// Why the database could not be opened while the damaged-database prompt
+	// is shown, its selected choice, and where the damaged file was moved
+	corruptErr     error
+	recoveryCursor int
+	setAsidePath   string
+
+	// The data directory's lock while this session may write the data; the
+	// error of another session holding it and the selected choice while the
+	// prompt about it is shown
+	lock           *storage.Lock
+	instanceErr    error
+	instanceCursor int
 
 	// Current view state
 	state         ViewState
@@ -42,22 +127,148 @@ type Model struct {
 	layout       *Layout
 	windowStyles map[WindowID]WindowStyle
 
+	// Scrolls the help window's content when it is taller than the window
+	helpViewport viewport.Model
+
 	// List views
 	todoListsList list.Model
 	tasksList     list.Model
 
+	// List whose tasks tasksList holds, empty when no list is selected
+	tasksListID string
+
 	// Currently selected list
 	currentListID string
 
+	// Task shown in the detail view and the selected note within it
+	detailTaskID string
+	noteCursor   int
+
+	// Recent activity feed and the selected entry within it
+	activity       []models.Activity
+	activityCursor int
+
+	// Activity log view: the changes shown, the selected one, and whether
+	// only today's are shown
+	activityLog       []models.Change
+	activityLogCursor int
+	activityLogToday  bool
+
+	// Overdue tasks across all lists: the cached count shown in the status bar,
+	// and the entries and selection of the overdue view
+	overdueCount  int
+	overdue       []models.ListTask
+	overdueCursor int
+
+	// Starred view: the starred tasks across all lists and the selected one,
+	// and the day completed stars were last cleared, as YYYY-MM-DD
+	starred         []models.ListTask
+	starredCursor   int
+	starsClearedDay string
+
+	// Calendar view: the selected day, whose month is shown, the selected task
+	// due on it, and the open tasks due in the weeks shown, by day
+	calendarDay    time.Time
+	calendarCursor int
+	calendarDue    map[string][]models.ListTask
+
+	// Deleted tasks shown in the trash view, the selection, and whether
+	// emptying the trash is waiting for confirmation
+	trash        []models.ListTask
+	trashCursor  int
+	trashConfirm bool
+
+	// The action the confirmation overlay asks about while it is shown
+	confirming confirmation
+
+	// The summary of what happened since the last session, while it is shown
+	welcome welcomeBack
+
 	// Form inputs
-	titleInput       textinput.Model
-	descriptionInput textinput.Model
-	deadlineInput    textinput.Model
+	titleInput          textinput.Model
+	descriptionInput    textinput.Model
+	deadlineInput       textinput.Model
+	noteInput           textinput.Model
+	estimateInput       textinput.Model
+	spentInput          textinput.Model
+	reminderInput       textinput.Model
+	linkInput           textinput.Model
+	reminderOffsetInput textinput.Model
+	shiftInput          textinput.Model
+	groupInput          textinput.Model
+
+	// The date picker open over the deadline field with Ctrl+K
+	datePicker  datePicker
+	datePicking bool
+
+	// The task or list title renamed in its row with r
+	inline *inlineEdit
 
 	// Form states
-	formFocusIndex int
-	editing        bool
-	editingTaskID  string
+	formFocusIndex  int
+	formSubmitted   bool // Saving the open form was tried, so its errors are shown
+	editing         bool
+	editingTaskID   string
+	editingPriority models.Priority
+	labelIndex      int
+	colorIndex      int
+	templateIndex   int // Template picked in the list form; 0 means none
+
+	// What the open form held when it opened, and the new task last
+	// discarded with changes, until a new task is saved
+	formInitial formValues
+	taskDraft   *formValues
+
+	// Templates overlay: selected template, list being saved and template being renamed
+	templateCursor     int
+	templateListID     string
+	renamingTemplateID string
+
+	// Snooze chooser: selected option, whether the deadline prompt is a custom
+	// snooze, and the view to return to afterwards
+	snoozeCursor int
+	snoozing     bool
+	snoozeReturn ViewState
+
+	// Warnings and errors of the session, and the entries and selection of the log view
+	log        *logBuffer
+	logEntries []logEntry
+	logCursor  int
+
+	// URLs found in a task for the URL chooser, the selection, and the view to return to
+	urlChoices []string
+	urlCursor  int
+	urlReturn  ViewState
+
+	// List whose deadlines the shift prompt moves
+	shiftListID string
+
+	// Sidebar groups whose lists are hidden under their heading
+	collapsedGroups map[string]bool
+
+	// Completion streak in days, and the list complete overlay while it is shown
+	streak         int
+	celebration    string
+	celebrationSeq int
+
+	// Completions per day shown by the sidebar heatmap; nil while it is off
+	heatmap map[string]int
+
+	// Running timer: the task it tracks and when it was started
+	timerTaskID string
+	timerListID string
+	timerStart  time.Time
+
+	// Selected editable row in the settings view
+	settingsCursor int
+
+	// First-run setup wizard: current step and the answers so far
+	setupStep     int
+	setupSettings models.Settings
+
+	// Git sync: whether a sync is running, and the latest debounced export request
+	syncing       bool
+	syncExportSeq int
 
 	// UI dimensions
 	width  int
@@ -68,8 +279,69 @@ type Model struct {
 	messageTime time.Time
 	messageType string
 
-	// Reminder system
+	// Focus mode hides everything but the main window
+	focusMode bool
+
+	// Tasks just completed that stay listed while completed tasks are hidden,
+	// until the time they are hidden at
+	lingering map[string]time.Time
+
+	// Tasks of the current list marked for a bulk change, and the state of the
+	// forms that apply one
+	marked         map[string]bool
+	bulkDeadline   bool // The deadline prompt sets the deadline of the marked tasks
+	priorityCursor models.Priority
+
+	// Do not disturb holds back reminders while on, until dndUntil or, when
+	// that is zero, until it is turned off; the chooser's selection and the
+	// view to return to afterwards
+	dnd       bool
+	dndUntil  time.Time
+	dndCursor int
+	dndReturn ViewState
+
+	// Changes not saved yet: set by saveData on backends that defer writes
+	// to Save and cleared once the latest save, counted by saves, has
+	// succeeded; savesPending have not finished
+	dirty        bool
+	saves        int
+	savesPending int
+
+	// Database maintenance runs in the background from the settings view;
+	// maintenanceResult sums up the last run there
+	maintaining       bool
+	maintenanceResult string
+
+	// Changes to tasks that u undoes, latest last, and the undone ones that
+	// Ctrl+R redoes
+	undoHistory []undoEntry
+	redoHistory []undoEntry
+
+	// Resizing the sidebar with Ctrl+←/→ after Ctrl+W, or by dragging its border
+	resizing        bool
+	draggingDivider bool
+
+	// Command palette registry and overlay state
+	commands           []paletteCommand
+	paletteInput       textinput.Model
+	paletteMatches     []paletteCommand
+	paletteCursor      int
+	paletteReturnState ViewState
+	paletteLists       bool // The palette is the list switcher, offering only lists
+	paletteProfiles    bool // The palette is the profile switcher, offering only profiles
+
+	// Reminder system. reminded holds how far each task's reminders got for
+	// its current deadline, and only deadlines passing after remindersSince
+	// get an overdue reminder. now is the clock reminders go by.
 	lastReminderCheck time.Time
+	reminded          map[string]reminderState
+	remindersSince    time.Time
+	now               func() time.Time
+
+	// Overdue Critical tasks: the escalation of each by task ID, and the ones
+	// not dismissed, which the banner above the tasks lists
+	escalations     map[string]escalation
+	criticalOverdue []models.ListTask
 
 	// Key bindings
 	keys KeyMap
@@ -88,6 +360,7 @@ type KeyMap struct {
 	NewList      key.Binding
 	NewTask      key.Binding
 	Edit         key.Binding
+	Rename       key.Binding
 	Delete       key.Binding
 	Toggle       key.Binding
 	Settings     key.Binding
@@ -97,6 +370,57 @@ type KeyMap struct {
 	PrevWindow   key.Binding
 	FocusMain    key.Binding
 	FocusSidebar key.Binding
+	FocusMode    key.Binding
+	Narrower     key.Binding
+	Wider        key.Binding
+	ResizeMode   key.Binding
+	Mark         key.Binding
+	Duplicate    key.Binding
+	GroupTasks   key.Binding
+	PasteTasks   key.Binding
+	SetPriority  key.Binding
+	AddNote      key.Binding
+	SetDeadline  key.Binding
+	Snooze       key.Binding
+	Timer        key.Binding
+	EditTime     key.Binding
+	SetLink      key.Binding
+	SetReminder  key.Binding
+	OpenLink     key.Binding
+	OpenURL      key.Binding
+	HideDone     key.Binding
+	Activity     key.Binding
+	ActivityLog  key.Binding
+	SaveTemplate key.Binding
+	Shift        key.Binding
+	Templates    key.Binding
+	Trash        key.Binding
+	Log          key.Binding
+	EmptyTrash   key.Binding
+	MoveUp       key.Binding
+	MoveDown     key.Binding
+	Pin          key.Binding
+
+	// Overlay navigation that leaves letters free for text input
+	MenuUp         key.Binding
+	MenuDown       key.Binding
+	CommandPalette key.Binding
+	SwitchList     key.Binding
+	GoToList       key.Binding
+	Overdue        key.Binding
+	Sync           key.Binding
+	DoNotDisturb   key.Binding
+	SaveNow        key.Binding
+	Undo           key.Binding
+	Redo           key.Binding
+	Calendar       key.Binding
+	PrevMonth      key.Binding
+	NextMonth      key.Binding
+	DatePicker     key.Binding
+	Dismiss        key.Binding
+	ProgressUp     key.Binding
+	ProgressDown   key.Binding
+	Star           key.Binding
 }
 
 // DefaultKeyMap returns the default key mappings
@@ -146,6 +470,10 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("e"),
 			key.WithHelp("e", "edit"),
 		),
+		Rename: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "rename"),
+		),
 		Delete: key.NewBinding(
 			key.WithKeys("d"),
 			key.WithHelp("d", "delete"),
@@ -182,20 +510,210 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("ctrl+s"),
 			key.WithHelp("ctrl+s", "focus sidebar"),
 		),
+		FocusMode: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "focus mode"),
+		),
+		Narrower: key.NewBinding(
+			key.WithKeys("<"),
+			key.WithHelp("<", "narrower sidebar"),
+		),
+		Wider: key.NewBinding(
+			key.WithKeys(">"),
+			key.WithHelp(">", "wider sidebar"),
+		),
+		ResizeMode: key.NewBinding(
+			key.WithKeys("ctrl+w"),
+			key.WithHelp("ctrl+w", "resize sidebar"),
+		),
+		Mark: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "mark for bulk change"),
+		),
+		SetPriority: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "set priority"),
+		),
+		Duplicate: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "duplicate task"),
+		),
+		GroupTasks: key.NewBinding(
+			key.WithKeys("G"),
+			key.WithHelp("G", "group tasks"),
+		),
+		PasteTasks: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "paste tasks"),
+		),
+		AddNote: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "add note"),
+		),
+		SetDeadline: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "set deadline"),
+		),
+		Snooze: key.NewBinding(
+			key.WithKeys("z"),
+			key.WithHelp("z", "snooze"),
+		),
+		Timer: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "start/stop timer"),
+		),
+		EditTime: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "edit estimate/time spent"),
+		),
+		SetLink: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "set link"),
+		),
+		OpenLink: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "open link"),
+		),
+		OpenURL: key.NewBinding(
+			key.WithKeys("O"),
+			key.WithHelp("O", "open URL in task"),
+		),
+		HideDone: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "show/hide completed"),
+		),
+		SetReminder: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "set reminder"),
+		),
+		Activity: key.NewBinding(
+			key.WithKeys("A"),
+			key.WithHelp("A", "recent activity"),
+		),
+		ActivityLog: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "activity log"),
+		),
+		Shift: key.NewBinding(
+			key.WithKeys("ctrl+d"),
+			key.WithHelp("ctrl+d", "shift list deadlines"),
+		),
+		SaveTemplate: key.NewBinding(
+			key.WithKeys("ctrl+t"),
+			key.WithHelp("ctrl+t", "save as template"),
+		),
+		Templates: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "templates"),
+		),
+		Trash: key.NewBinding(
+			key.WithKeys("X"),
+			key.WithHelp("X", "trash"),
+		),
+		Log: key.NewBinding(
+			key.WithKeys("W"),
+			key.WithHelp("W", "warnings and errors"),
+		),
+		EmptyTrash: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "empty trash"),
+		),
+		MoveUp: key.NewBinding(
+			key.WithKeys("shift+up", "K"),
+			key.WithHelp("K", "move up"),
+		),
+		MoveDown: key.NewBinding(
+			key.WithKeys("shift+down", "J"),
+			key.WithHelp("J", "move down"),
+		),
+		Pin: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "pin/unpin"),
+		),
+		MenuUp: key.NewBinding(
+			key.WithKeys("up"),
+			key.WithHelp("↑", "previous"),
+		),
+		MenuDown: key.NewBinding(
+			key.WithKeys("down"),
+			key.WithHelp("↓", "next"),
+		),
+		Overdue: key.NewBinding(
+			key.WithKeys("ctrl+o"),
+			key.WithHelp("ctrl+o", "overdue tasks"),
+		),
+		DoNotDisturb: key.NewBinding(
+			key.WithKeys("N"),
+			key.WithHelp("N", "do not disturb"),
+		),
+		SaveNow: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "save now"),
+		),
+		Undo: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "undo"),
+		),
+		Redo: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "redo"),
+		),
+		Calendar: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "calendar"),
+		),
+		PrevMonth: key.NewBinding(
+			key.WithKeys("["),
+			key.WithHelp("[", "previous month"),
+		),
+		NextMonth: key.NewBinding(
+			key.WithKeys("]"),
+			key.WithHelp("]", "next month"),
+		),
+		DatePicker: key.NewBinding(
+			key.WithKeys("ctrl+k"),
+			key.WithHelp("ctrl+k", "pick a date"),
+		),
+		Dismiss: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "dismiss critical"),
+		),
+		ProgressUp: key.NewBinding(
+			key.WithKeys("+", "="),
+			key.WithHelp("+", "more progress"),
+		),
+		ProgressDown: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "less progress"),
+		),
+		Star: key.NewBinding(
+			key.WithKeys("*"),
+			key.WithHelp("*", "star"),
+		),
+		CommandPalette: key.NewBinding(
+			key.WithKeys("ctrl+p"),
+			key.WithHelp("ctrl+p", "command palette"),
+		),
+		SwitchList: key.NewBinding(
+			key.WithKeys("ctrl+j"),
+			key.WithHelp("ctrl+j", "switch list"),
+		),
+		GoToList: key.NewBinding(
+			key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+			key.WithHelp("1-9", "go to list"),
+		),
+		Sync: key.NewBinding(
+			key.WithKeys("ctrl+g"),
+			key.WithHelp("ctrl+g", "git sync"),
+		),
 	}
 }
 
-// NewModel creates a new application model
-func NewModel() (*Model, error) {
-	storage, err := storage.NewWithMigration()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create storage: %w", err)
-	}
-
-	app, err := storage.Load()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load application data: %w", err)
-	}
+// NewModel creates a new application model. It returns immediately in a
+// loading state; storage is opened in the background by the command from Init.
+func NewModel(opts Options) *Model {
+	usePalette(opts.Light)
+	inline := newInlineEdit()
 
 	// Create text inputs
 	titleInput := textinput.New()
@@ -206,44 +724,246 @@ func NewModel() (*Model, error) {
 	descriptionInput.Placeholder = "Enter description (optional)..."
 
 	deadlineInput := textinput.New()
-	deadlineInput.Placeholder = "Enter deadline (YYYY-MM-DD HH:MM) (optional)..."
+	deadlineInput.Placeholder = "Enter deadline (optional)..."
+
+	noteInput := textinput.New()
+	noteInput.Placeholder = "What happened?"
+
+	estimateInput := textinput.New()
+	estimateInput.Placeholder = "e.g. 2h"
+
+	spentInput := textinput.New()
+	spentInput.Placeholder = "e.g. 45m"
+
+	paletteInput := textinput.New()
+	paletteInput.Placeholder = "Type a command..."
+
+	reminderInput := textinput.New()
+	reminderInput.Placeholder = "60"
+	reminderInput.CharLimit = 5
+
+	linkInput := textinput.New()
+	linkInput.Placeholder = "https://… or ~/path/to/file"
+
+	reminderOffsetInput := textinput.New()
+	reminderOffsetInput.Placeholder = "default"
+	reminderOffsetInput.CharLimit = 10
+
+	shiftInput := textinput.New()
+	shiftInput.Placeholder = "+3d"
+
+	groupInput := textinput.New()
+	groupInput.Placeholder = "e.g. Work (optional)"
 
 	// Create layout and window styles
 	layout := NewLayout()
 	windowStyles := CreateWindowStyles()
 
+	loadingSpinner := spinner.New()
+	loadingSpinner.Spinner = spinner.Dot
+	loadingSpinner.Style = lipgloss.NewStyle().Foreground(PrimaryColor)
+
+	loadingText := "Loading…"
+	if opts.MigrateJSON && storage.MigrationPending() {
+		loadingText = "Migrating data…"
+	}
+
 	model := &Model{
-		app:               app,
-		storage:           storage,
-		state:             ListsView,
-		layout:            layout,
-		windowStyles:      windowStyles,
-		titleInput:        titleInput,
-		descriptionInput:  descriptionInput,
-		deadlineInput:     deadlineInput,
-		keys:              DefaultKeyMap(),
-		lastReminderCheck: time.Now(),
-		width:             80, // Default width
-		height:            24, // Default height
-		messageType:       "info",
+		opts:                opts,
+		loading:             true,
+		loadingText:         loadingText,
+		spinner:             loadingSpinner,
+		helpViewport:        viewport.New(0, 0),
+		state:               ListsView,
+		layout:              layout,
+		windowStyles:        windowStyles,
+		titleInput:          titleInput,
+		descriptionInput:    descriptionInput,
+		deadlineInput:       deadlineInput,
+		noteInput:           noteInput,
+		estimateInput:       estimateInput,
+		spentInput:          spentInput,
+		reminderInput:       reminderInput,
+		linkInput:           linkInput,
+		reminderOffsetInput: reminderOffsetInput,
+		shiftInput:          shiftInput,
+		groupInput:          groupInput,
+		paletteInput:        paletteInput,
+		inline:              inline,
+		todoListsList:       newListModel(todoListDelegate{newItemDelegate(), inline}),
+		tasksList:           newTasksListModel(inline),
+		keys:                DefaultKeyMap(),
+		lastReminderCheck:   time.Now(),
+		reminded:            make(map[string]reminderState),
+		escalations:         make(map[string]escalation),
+		remindersSince:      time.Now(),
+		now:                 time.Now,
+		marked:              make(map[string]bool),
+		lingering:           make(map[string]time.Time),
+		collapsedGroups:     make(map[string]bool),
+		log:                 &logBuffer{},
+		width:               80, // Default width
+		height:              24, // Default height
+		messageType:         "info",
 	}
 
+	applyKeyOverrides(&model.keys, opts.Config)
+	model.commands = model.defaultCommands()
+
 	// Set initial layout dimensions
 	model.layout.SetScreenSize(model.width, model.height)
 
 	// Initialize layout windows
 	model.initializeWindows()
+	model.updateListDimensions()
+
+	return model
+}
+
+// loadData opens storage, running any pending migration, and loads the application data
+func (m *Model) loadData() tea.Cmd {
+	// Only one session at a time writes the data, or saves would overwrite each other
+	if err := m.lockData(); err != nil {
+		return func() tea.Msg { return instanceRunningMsg{err} }
+	}
+
+	opts := storage.Options{
+		ReadOnly:    m.opts.ReadOnly,
+		Passphrase:  m.opts.Passphrase,
+		Output:      m.log, // The TUI owns the terminal, so messages go to the log view
+		MigrateJSON: m.opts.MigrateJSON,
+	}
+
+	return func() tea.Msg {
+		store, err := storage.NewWithMigration(opts)
+		if errors.Is(err, storage.ErrMigrationPending) {
+			// The prompt previews what migrating would add; it still asks without one
+			plan, _ := storage.PlanMigration(opts)
+			return migrationPendingMsg{plan: plan}
+		}
+		if errors.Is(err, storage.ErrDatabaseCorrupt) && !opts.ReadOnly {
+			return databaseCorruptMsg{err}
+		}
+		if err != nil {
+			return loadErrorMsg{fmt.Errorf("failed to create storage: %w", err)}
+		}
+
+		app, err := store.Load()
+		if err != nil {
+			store.Close()
+			return loadErrorMsg{fmt.Errorf("failed to load application data: %w", err)}
+		}
+
+		// Recover changes a crashed session did not persist, then log new ones
+		journaled, recovered, err := storage.OpenJournal(store, app)
+		if err != nil {
+			store.Close()
+			return loadErrorMsg{err}
+		}
+
+		// Old completed tasks go to the trash first, where they are kept like any other
+		purged, err := purgeCompleted(journaled, app)
+		if err != nil {
+			journaled.Close()
+			return loadErrorMsg{fmt.Errorf("failed to move old completed tasks to the trash: %w", err)}
+		}
+
+		// Deleted tasks are kept for the retention window only
+		if days := app.Settings.TrashDays; days > 0 && !journaled.IsReadOnly() {
+			if _, err := journaled.PurgeTrash(app, time.Now().AddDate(0, 0, -days)); err != nil {
+				journaled.Close()
+				return loadErrorMsg{fmt.Errorf("failed to purge the trash: %w", err)}
+			}
+		}
+
+		return dataLoadedMsg{storage: journaled, app: app, recovered: recovered, purged: purged}
+	}
+}
+
+// finishLoading installs the loaded data and sets up the initial view
+func (m *Model) finishLoading(msg dataLoadedMsg) {
+	m.loading = false
+	m.storage = msg.storage
+	m.app = msg.app
+	m.readOnly = msg.storage.IsReadOnly()
+	m.applyConfigSettings()
+	m.applyDisplaySettings()
+	m.refreshOverdueCount()
+	m.refreshStreak()
+	m.refreshHeatmap()
 
 	// Initialize lists
-	model.updateTodoListsList()
+	m.updateTodoListsList()
+	m.updateListDimensions()
+
+	// Auto-select first list if available, which is under the first group heading once lists are grouped
+	if len(m.app.TodoLists) > 0 && m.currentListID == "" {
+		m.currentListID = m.app.TodoLists[0].ID
+		for _, item := range m.todoListsList.Items() {
+			if item, ok := item.(listItem); ok {
+				m.currentListID = item.id
+				selectItem(&m.todoListsList, item.id)
+				break
+			}
+		}
+		m.updateTasksList()
+	}
 
-	// Auto-select first list if available
-	if len(model.app.TodoLists) > 0 && model.currentListID == "" {
-		model.currentListID = model.app.TodoLists[0].ID
-		model.updateTasksList()
+	// Start in the list or at the task given on the command line
+	if m.opts.OpenListID != "" && m.getList(m.opts.OpenListID) != nil {
+		m.switchToList(m.opts.OpenListID)
+	}
+	if m.opts.OpenTaskID != "" {
+		m.openTaskByID(m.opts.OpenTaskID)
 	}
 
-	return model, nil
+	// Storage falls back to read-only on its own when the data directory is not writable
+	if m.readOnly && !m.opts.ReadOnly {
+		m.showMessageWithType("Data directory is not writable - opened read-only", "warning")
+	}
+	m.reportPurgedCompleted(msg.purged)
+	if msg.recovered > 0 {
+		m.showMessageWithType(fmt.Sprintf("Recovered %d unsaved change(s) from the last session", msg.recovered), "warning")
+	}
+	if m.setAsidePath != "" {
+		m.log.add(logWarning, "Started a fresh database; the damaged one was moved to "+m.setAsidePath)
+		m.showMessageWithType("Started fresh - the damaged database is kept as "+filepath.Base(m.setAsidePath), "warning")
+		m.setAsidePath = ""
+	}
+
+	if m.needsSetup() {
+		m.openSetupWizard()
+	}
+}
+
+// Close releases the storage backend, flushing anything it still holds in memory,
+// and then the data directory's lock. A running timer is stopped first so the
+// tracked time is kept, and changes auto save held back are saved.
+func (m *Model) Close() error {
+	defer m.lock.Release()
+	return m.closeStorage()
+}
+
+// closeStorage stops a running timer, saves changes auto save held back and
+// closes the storage backend, leaving the lock alone
+func (m *Model) closeStorage() error {
+	if m.storage == nil {
+		return nil
+	}
+	if m.timerTaskID != "" {
+		if _, err := m.stopTimer(); err != nil {
+			m.storage.Close()
+			return fmt.Errorf("failed to save timer: %w", err)
+		}
+	}
+	// Settings reach a write-through backend only on Save as well
+	if (m.dirty || !m.storage.DefersWrites()) && !m.readOnly {
+		if err := m.storage.Save(m.app); err != nil {
+			m.storage.Close()
+			return fmt.Errorf("failed to save: %w", err)
+		}
+	}
+	return m.storage.Close()
 }
 
 // initializeWindows sets up the initial windows in the layout
@@ -251,7 +971,7 @@ func (m *Model) initializeWindows() {
 	// Create sidebar window
 	sidebarWindow := &Window{
 		ID:      SidebarWindow,
-		Title:   "📋 Todo Lists",
+		Title:   withIcon(icons.Lists, "Todo Lists"),
 		Content: "",
 		Focused: false,
 		Visible: true,
@@ -263,7 +983,7 @@ func (m *Model) initializeWindows() {
 	// Create main window
 	mainWindow := &Window{
 		ID:      MainWindow,
-		Title:   "📝 Tasks",
+		Title:   withIcon(icons.Tasks, "Tasks"),
 		Content: "",
 		Focused: true,
 		Visible: true,
@@ -299,7 +1019,7 @@ func (m *Model) initializeWindows() {
 	// Create help window (initially hidden)
 	helpWindow := &Window{
 		ID:      HelpWindow,
-		Title:   "❓ Help",
+		Title:   withIcon(icons.Help, "Help"),
 		Content: "",
 		Focused: false,
 		Visible: false,
@@ -309,6 +1029,29 @@ func (m *Model) initializeWindows() {
 	m.layout.AddWindow(helpWindow)
 }
 
+// filteredList returns the focused bubbles list when it has a filter
+func (m *Model) filteredList() *list.Model {
+	switch m.layout.GetFocusedWindowID() {
+	case SidebarWindow:
+		if m.todoListsList.FilterState() != list.Unfiltered {
+			return &m.todoListsList
+		}
+	case MainWindow:
+		if (m.state == ListsView || m.state == TasksView) && m.tasksList.FilterState() != list.Unfiltered {
+			return &m.tasksList
+		}
+	}
+	return nil
+}
+
+// filteringList returns the focused bubbles list while its filter is being typed
+func (m *Model) filteringList() *list.Model {
+	if filtered := m.filteredList(); filtered != nil && filtered.SettingFilter() {
+		return filtered
+	}
+	return nil
+}
+
 // updateListDimensions updates the list component dimensions based on window sizes
 func (m *Model) updateListDimensions() {
 	// Get sidebar window dimensions for lists
@@ -316,6 +1059,9 @@ func (m *Model) updateListDimensions() {
 	if sidebarWindow != nil {
 		listWidth := sidebarWindow.Position.Width - 4   // Account for borders and padding
 		listHeight := sidebarWindow.Position.Height - 6 // Account for borders and title
+		if m.heatmapShown() {
+			listHeight -= heatmapHeight + 1
+		}
 
 		// Ensure minimum dimensions
 		if listWidth < 10 {
@@ -325,9 +1071,7 @@ func (m *Model) updateListDimensions() {
 			listHeight = 5
 		}
 
-		if m.todoListsList.Items() != nil {
-			m.todoListsList.SetSize(listWidth, listHeight)
-		}
+		m.todoListsList.SetSize(listWidth, listHeight)
 	}
 
 	// Get main window dimensions for task list
@@ -335,6 +1079,7 @@ func (m *Model) updateListDimensions() {
 	if mainWindow != nil {
 		listWidth := mainWindow.Position.Width - 4   // Account for borders and padding
 		listHeight := mainWindow.Position.Height - 6 // Account for borders and title
+		listHeight -= m.criticalBannerHeight()
 
 		// Ensure minimum dimensions
 		if listWidth < 10 {
@@ -344,12 +1089,21 @@ func (m *Model) updateListDimensions() {
 			listHeight = 5
 		}
 
-		if m.tasksList.Items() != nil {
-			m.tasksList.SetSize(listWidth, listHeight)
-		}
+		m.tasksList.SetSize(listWidth, listHeight)
 	}
 }
 
+// toggleFocusMode switches between the full layout and a full-width main window
+func (m *Model) toggleFocusMode() {
+	m.focusMode = !m.focusMode
+
+	m.layout.SetWindowVisible(SidebarWindow, !m.focusMode)
+	m.layout.SetWindowVisible(StatusWindow, !m.focusMode)
+	m.layout.SetFocus(MainWindow)
+
+	m.updateListDimensions()
+}
+
 // toggleHelp shows or hides the help window
 func (m *Model) toggleHelp() {
 	helpWindow := m.layout.GetWindow(HelpWindow)
@@ -362,8 +1116,10 @@ func (m *Model) toggleHelp() {
 			// Show help window
 			m.layout.SetWindowVisible(HelpWindow, true)
 			m.layout.SetFocus(HelpWindow)
-			// Update help content
+			// Update help content, scrolled to the top
 			m.updateHelpContent()
+			m.helpViewport.GotoTop()
+			m.renderHelpWindow()
 		}
 	}
 }
@@ -376,17 +1132,67 @@ func (m *Model) updateHelpContent() {
 		"Ctrl+→/←": "Navigate windows",
 		"Ctrl+m":   "Focus main window",
 		"Ctrl+s":   "Focus sidebar",
+		"f":        "Toggle focus mode",
+		"</>":      "Resize sidebar",
+		"Ctrl+w":   "Resize mode (Ctrl+→/← move the divider)",
+		"Ctrl+p":   "Command palette",
+		"Ctrl+o":   "Overdue tasks",
+		"C":        "Calendar of deadlines ([/] change month)",
+		"N":        "Do not disturb: hold back reminders for a while",
+		"S":        "Save now",
+		"Ctrl+j":   "Switch to a list by name",
 	}
 
 	listBindings := map[string]string{
 		"↑/↓":   "Navigate items",
 		"Enter": "Select/Open item",
-		"n":     "New todo list",
-		"a":     "Add task",
-		"e":     "Edit item",
-		"d":     "Delete item",
-		"Space": "Toggle task completion",
+		"1-9":   "Open the 1st to 9th list shown (sidebar)",
 		"Esc":   "Go back",
+		"A":     "Recent activity",
+		"L":     "Activity log of every change (Tab: today only)",
+		"T":     "Manage templates",
+		"X":     "Trash (Enter restores a task)",
+		"W":     "Log of warnings and errors",
+		"o":     "Open task link",
+		"O":     "Open a URL from the task's title or description",
+		"m":     "Mark task for a bulk change",
+		"x":     "Dismiss the critical overdue banner (selected task, or all)",
+	}
+
+	mutatingBindings := map[string]string{
+		"n":        "New todo list",
+		"a":        "Add task",
+		"e":        "Edit item",
+		"r":        "Rename item in place (Enter saves, Esc cancels)",
+		"d":        "Delete item",
+		"Space":    "Toggle task completion (the marked tasks, if any)",
+		"D":        "Set task deadline (of marked tasks)",
+		"p":        "Set task priority (of marked tasks)",
+		"y":        "Duplicate task",
+		"G":        "Group tasks: none, by priority, by deadline",
+		"v":        "Add a task per line of the clipboard",
+		"z":        "Snooze task deadline",
+		"t":        "Start/stop task timer",
+		"+/-":      "Move task progress up/down by 10%",
+		"*":        "Star/unstar task for the daily plan (Starred view in the sidebar)",
+		"c":        "Show/hide completed tasks",
+		"Ctrl+T":   "Save list as template",
+		"Ctrl+D":   "Shift a list's open deadlines",
+		"K/J":      "Move list up/down (sidebar; also Shift+↑/↓)",
+		"P":        "Pin list to the top of the sidebar, or unpin it",
+		"u/Ctrl+R": "Undo/redo a task edit, completion or deletion",
+		"Ctrl+g":   "Git sync",
+	}
+
+	detailBindings := map[string]string{
+		"Enter": "Open task details",
+		"n":     "Add note",
+		"↑/↓":   "Select note",
+		"d":     "Delete selected note",
+		"E":     "Edit estimate and time spent",
+		"b":     "Set or remove link",
+		"R":     "Set reminder lead time",
+		"Esc":   "Back to tasks",
 	}
 
 	formBindings := map[string]string{
@@ -396,18 +1202,54 @@ func (m *Model) updateHelpContent() {
 		"Esc":       "Cancel",
 	}
 
-	content := CreateHelpSection("🌐 General", generalBindings) + "\n\n" +
-		CreateHelpSection("📋 Lists & Tasks", listBindings) + "\n\n" +
-		CreateHelpSection("📝 Forms", formBindings) + "\n\n" +
-		DescStyle.Render("Press ? or Esc to close help")
+	var editSection string
+	if m.readOnly {
+		editSection = CreateMutedHelpSection(withIcon(icons.ReadOnly, "Editing (disabled in read-only mode)"), mutatingBindings)
+	} else {
+		for k, v := range mutatingBindings {
+			listBindings[k] = v
+		}
+	}
+
+	content := CreateHelpSection(withIcon(icons.General, "General"), generalBindings) + "\n\n" +
+		CreateHelpSection(withIcon(icons.Lists, "Lists & Tasks"), listBindings) + "\n\n"
+	if editSection != "" {
+		content += editSection + "\n\n"
+	}
+	content += CreateHelpSection(withIcon(icons.Details, "Task Details"), detailBindings) + "\n\n" +
+		CreateHelpSection(withIcon(icons.Tasks, "Forms"), formBindings) + "\n\n" +
+		CreateHelpSection(withIcon(icons.Theme, "Indicators"), indicatorLegend())
+
+	m.helpViewport.SetContent(content)
+	m.renderHelpWindow()
+}
+
+// renderHelpWindow fits the help viewport to the help window and shows the
+// part of the help it is scrolled to, with the keys to scroll and close
+// under it
+func (m *Model) renderHelpWindow() {
+	helpWindow := m.layout.GetWindow(HelpWindow)
+	if helpWindow == nil {
+		return
+	}
+
+	// Leave out the border, the content padding and the footer
+	m.helpViewport.Width = max(helpWindow.Position.Width-6, 1)
+	m.helpViewport.Height = max(helpWindow.Position.Height-8, 1)
 
-	m.layout.SetWindowContent(HelpWindow, content)
+	footer := "Press ? or Esc to close help"
+	if !m.helpViewport.AtTop() || !m.helpViewport.AtBottom() {
+		footer = fmt.Sprintf("↑/↓/PgUp/PgDn: scroll (%d%%) • %s", int(m.helpViewport.ScrollPercent()*100), footer)
+	}
+	m.layout.SetWindowContent(HelpWindow, m.helpViewport.View()+"\n\n"+DescStyle.Render(footer))
 }
 
 // Init initializes the model
 func (m *Model) Init() tea.Cmd {
 	return tea.Batch(
 		textinput.Blink,
+		m.spinner.Tick,
+		m.loadData(),
 		m.checkReminders(),
 	)
 }
@@ -426,13 +1268,106 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Update list dimensions based on window sizes
 		m.updateListDimensions()
+		m.renderHelpWindow()
+
+	case tea.MouseMsg:
+		// Clicking elsewhere leaves a title being renamed as it was
+		if m.inline.active() && msg.Action == tea.MouseActionPress {
+			m.cancelRename()
+		}
+		if !m.loading && m.loadErr == nil {
+			return m, m.updateDividerDrag(msg)
+		}
+
+	case spinner.TickMsg:
+		if m.loading || m.maintaining {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+
+	case dataLoadedMsg:
+		m.finishLoading(msg)
+		return m, tea.Batch(m.clearStarsDaily(), m.openWelcomeBack())
+
+	case list.FilterMatchesMsg:
+		// Filter results come back asynchronously to the list being filtered
+		if filtered := m.filteredList(); filtered != nil {
+			*filtered, _ = filtered.Update(msg)
+		}
+		return m, nil
+
+	case loadErrorMsg:
+		m.loading = false
+		m.loadErr = msg.err
+		return m, nil
+
+	case migrationPendingMsg:
+		m.migrationPrompt = true
+		m.migrationPlan = msg.plan
+		return m, nil
+
+	case instanceRunningMsg:
+		m.instanceErr = msg.err
+		m.instanceCursor = instanceReadOnly
+		return m, nil
+
+	case databaseCorruptMsg:
+		m.corruptErr = msg.err
+		m.recoveryCursor = recoverStartFresh
+		return m, nil
 
 	case tea.KeyMsg:
-		// Global keys
+		if m.migrationPrompt {
+			return m.updateMigrationPrompt(msg)
+		}
+		if m.corruptErr != nil {
+			return m.updateRecoveryPrompt(msg)
+		}
+		if m.instanceErr != nil {
+			return m.updateInstancePrompt(msg)
+		}
+
+		// Only quitting is possible until data has loaded
+		if m.loading || m.loadErr != nil {
+			if key.Matches(msg, m.keys.Quit) || (m.loadErr != nil && key.Matches(msg, m.keys.Back)) {
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+
+		// The welcome-back summary closes on any key
+		if m.state == WelcomeView && msg.Type != tea.KeyCtrlC {
+			return m.updateWelcomeView(msg)
+		}
+
+		// The palette captures every key so typed letters reach its input
+		if m.state == CommandPaletteView {
+			return m.updateCommandPalette(msg)
+		}
+
+		// So does a list filter while it is being typed
+		if filtering := m.filteringList(); filtering != nil && msg.Type != tea.KeyCtrlC {
+			var cmd tea.Cmd
+			*filtering, cmd = filtering.Update(msg)
+			return m, cmd
+		}
+
+		// And a title being renamed in its row
+		if m.inline.active() && msg.Type != tea.KeyCtrlC {
+			return m.updateRename(msg)
+		}
+
+		// Resize mode takes the window keys for moving the divider
+		if m.resizing && msg.Type != tea.KeyCtrlC {
+			return m, m.updateResizeMode(msg)
+		}
+
+		// Global keys; forms take q and ? as text, so only Ctrl+C quits there
 		switch {
-		case key.Matches(msg, m.keys.Quit):
+		case key.Matches(msg, m.keys.Quit) && (msg.Type == tea.KeyCtrlC || !m.isInFormState()):
 			return m, tea.Quit
-		case key.Matches(msg, m.keys.Help):
+		case key.Matches(msg, m.keys.Help) && !m.isInFormState():
 			m.toggleHelp()
 			return m, nil
 		case key.Matches(msg, m.keys.NextWindow):
@@ -447,6 +1382,54 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.keys.FocusSidebar):
 			m.layout.SetFocus(SidebarWindow)
 			return m, nil
+		case key.Matches(msg, m.keys.ResizeMode) && !m.isInFormState():
+			m.toggleResizeMode()
+			return m, nil
+		case key.Matches(msg, m.keys.CommandPalette) && !m.isInFormState():
+			m.openCommandPalette()
+			return m, nil
+		case key.Matches(msg, m.keys.SwitchList) && !m.isInFormState():
+			m.openListSwitcher()
+			return m, nil
+		case key.Matches(msg, m.keys.Overdue) && !m.isInFormState():
+			m.openOverdueView()
+			return m, nil
+		case key.Matches(msg, m.keys.Calendar) && !m.isInFormState():
+			m.openCalendarView()
+			return m, nil
+		case key.Matches(msg, m.keys.DoNotDisturb) && !m.isInFormState():
+			m.toggleDoNotDisturb()
+			return m, nil
+		case key.Matches(msg, m.keys.SaveNow) && !m.isInFormState():
+			if m.readOnly {
+				m.showMessageWithType("Read-only mode: changes are disabled", "warning")
+				return m, nil
+			}
+			if m.changesHeld() {
+				return m, nil
+			}
+			return m, m.saveNow()
+		case key.Matches(msg, m.keys.Undo, m.keys.Redo) && !m.isInFormState():
+			if m.readOnly {
+				m.showMessageWithType("Read-only mode: changes are disabled", "warning")
+				return m, nil
+			}
+			if m.changesHeld() {
+				return m, nil
+			}
+			if key.Matches(msg, m.keys.Undo) {
+				return m, m.undo()
+			}
+			return m, m.redo()
+		case key.Matches(msg, m.keys.Sync) && !m.isInFormState():
+			if m.readOnly {
+				m.showMessageWithType("Read-only mode: changes are disabled", "warning")
+				return m, nil
+			}
+			if m.changesHeld() {
+				return m, nil
+			}
+			return m, m.startSync()
 		}
 
 		// State-specific handling based on focus and current state
@@ -459,6 +1442,34 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m.updateListForm(msg)
 			case CreateTaskView, EditTaskView:
 				return m.updateTaskForm(msg)
+			case AddNoteView:
+				return m.updateNoteForm(msg)
+			case SetDeadlineView:
+				return m.updateDeadlineForm(msg)
+			case SnoozeView:
+				return m.updateSnoozeView(msg)
+			case EditTimeView:
+				return m.updateTimeForm(msg)
+			case TemplateNameView:
+				return m.updateTemplateNameForm(msg)
+			case TemplatesView:
+				return m.updateTemplatesView(msg)
+			case SetupView:
+				return m.updateSetupView(msg)
+			case EditLinkView:
+				return m.updateLinkForm(msg)
+			case EditReminderView:
+				return m.updateReminderForm(msg)
+			case ShiftDeadlinesView:
+				return m.updateShiftForm(msg)
+			case URLChooserView:
+				return m.updateURLChooser(msg)
+			case PriorityView:
+				return m.updatePriorityChooser(msg)
+			case DoNotDisturbView:
+				return m.updateDoNotDisturbView(msg)
+			case ConfirmView:
+				return m.updateConfirmView(msg)
 			}
 		}
 
@@ -468,6 +1479,18 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.toggleHelp()
 				return m, nil
 			}
+			var cmd tea.Cmd
+			m.helpViewport, cmd = m.helpViewport.Update(msg)
+			m.renderHelpWindow()
+			return m, cmd
+		}
+
+		// Mutating actions are unavailable in read-only mode
+		if m.readOnly && m.isMutatingKey(msg) {
+			m.showMessageWithType("Read-only mode: changes are disabled", "warning")
+			return m, nil
+		}
+		if m.isMutatingKey(msg) && m.changesHeld() {
 			return m, nil
 		}
 
@@ -479,27 +1502,99 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch m.state {
 			case SettingsView:
 				return m.updateSettingsView(msg)
+			case TaskDetailView:
+				return m.updateTaskDetailView(msg)
+			case ActivityView:
+				return m.updateActivityView(msg)
+			case ActivityLogView:
+				return m.updateActivityLogView(msg)
+			case OverdueView:
+				return m.updateOverdueView(msg)
+			case StarredView:
+				return m.updateStarredView(msg)
+			case CalendarView:
+				return m.updateCalendarView(msg)
+			case TrashView:
+				return m.updateTrashView(msg)
+			case LogView:
+				return m.updateLogView(msg)
 			default:
 				return m.updateTasksView(msg)
 			}
 		}
 
 	case reminderMsg:
-		m.checkForDueReminders()
-		return m, m.checkReminders()
+		var notify tea.Cmd
+		if m.app != nil {
+			m.expireDoNotDisturb(m.now())
+			// The weekly review comes last so its nudge is the one shown
+			notify = tea.Batch(m.checkForDueReminders(), m.repeatEscalations(m.now()), m.checkWeeklyReview(), m.clearStarsDaily())
+			m.refreshOverdueCount()
+			m.refreshTodoListItems()
+		}
+		return m, tea.Batch(notify, m.checkReminders())
+
+	case syncExportMsg:
+		return m, m.writeSyncExport(msg)
+
+	case lingerExpiredMsg:
+		m.expireLingering()
+		return m, nil
+
+	case datePickedMsg:
+		m.pickDeadline(msg.deadline)
+		return m, nil
+
+	case datePickerClosedMsg:
+		m.datePicking = false
+		return m, nil
+
+	case celebrationDoneMsg:
+		if msg.seq == m.celebrationSeq {
+			m.celebration = ""
+		}
+		return m, nil
+
+	case syncDoneMsg:
+		return m, m.finishSync(msg)
 
 	case errorMsg:
-		m.showMessage(string(msg))
+		m.showMessageWithType(string(msg), "error")
+		return m, nil
+
+	case savedMsg:
+		m.saved(msg)
 		return m, nil
+
+	case maintenanceDoneMsg:
+		return m, m.finishMaintenance(msg)
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
+// isMutatingKey reports whether a key triggers an action that changes data
+func (m *Model) isMutatingKey(msg tea.KeyMsg) bool {
+	return key.Matches(msg, m.keys.NewList, m.keys.NewTask, m.keys.Edit, m.keys.Rename, m.keys.Delete, m.keys.Toggle, m.keys.ProgressUp, m.keys.ProgressDown, m.keys.Star, m.keys.AddNote,
+		m.keys.SetDeadline, m.keys.SetPriority, m.keys.Duplicate, m.keys.GroupTasks, m.keys.PasteTasks, m.keys.Snooze, m.keys.Timer, m.keys.EditTime, m.keys.SetLink, m.keys.SetReminder, m.keys.HideDone, m.keys.Shift, m.keys.SaveTemplate, m.keys.MoveUp, m.keys.MoveDown, m.keys.Pin)
+}
+
 // View renders the multi-window layout
 func (m *Model) View() string {
-	if m.app == nil {
-		return "Loading application..."
+	if m.loadErr != nil {
+		return m.renderLoadError()
+	}
+	if m.migrationPrompt {
+		return m.renderMigrationPrompt()
+	}
+	if m.corruptErr != nil {
+		return m.renderRecoveryPrompt()
+	}
+	if m.instanceErr != nil {
+		return m.renderInstancePrompt()
+	}
+	if m.loading {
+		return m.renderLoading()
 	}
 
 	// Update window contents based on current state
@@ -528,6 +1623,9 @@ func (m *Model) updateWindowContents() {
 		formContent := m.renderFormContent()
 		m.layout.SetWindowContent(FormWindow, formContent)
 		m.layout.SetWindowVisible(FormWindow, true)
+	} else if m.celebration != "" {
+		m.layout.SetWindowContent(FormWindow, m.renderCelebrationContent())
+		m.layout.SetWindowVisible(FormWindow, true)
 	} else {
 		m.layout.SetWindowVisible(FormWindow, false)
 	}
@@ -575,28 +1673,79 @@ func (m *Model) getStatusBar() string {
 
 // getCurrentList returns the currently selected todo list
 func (m *Model) getCurrentList() *models.TodoList {
+	return m.getList(m.currentListID)
+}
+
+// getList returns a todo list by ID
+func (m *Model) getList(listID string) *models.TodoList {
 	for i := range m.app.TodoLists {
-		if m.app.TodoLists[i].ID == m.currentListID {
+		if m.app.TodoLists[i].ID == listID {
 			return &m.app.TodoLists[i]
 		}
 	}
 	return nil
 }
 
+// putTask keeps the in-memory list current with a task storage returned
+func (m *Model) putTask(listID string, task models.Task) {
+	if todoList := m.getList(listID); todoList != nil {
+		todoList.PutTask(task)
+	}
+}
+
+// getDetailTask returns the task currently shown in the detail view
+func (m *Model) getDetailTask() *models.Task {
+	return m.getTask(m.detailTaskID)
+}
+
+// getTask returns a task of the currently selected list by ID
+func (m *Model) getTask(taskID string) *models.Task {
+	currentList := m.getCurrentList()
+	if currentList == nil {
+		return nil
+	}
+
+	for i := range currentList.Tasks {
+		if currentList.Tasks[i].ID == taskID {
+			return &currentList.Tasks[i]
+		}
+	}
+	return nil
+}
+
 // saveData saves the application data
 func (m *Model) saveData() tea.Cmd {
-	return func() tea.Msg {
-		if err := m.storage.Save(m.app); err != nil {
-			return errorMsg(fmt.Sprintf("Failed to save: %v", err))
-		}
+	// Every change is followed by a save, so this keeps the cached counts current
+	m.refreshOverdueCount()
+	m.refreshTodoListItems()
+
+	// Write-through backends hold every change already
+	if m.storage.DefersWrites() {
+		m.dirty = true
+	}
+	if !m.app.Settings.AutoSave || m.maintaining || m.syncing {
 		return nil
 	}
+	return tea.Batch(m.save(false), m.scheduleSyncExport())
 }
 
 // Message types
 type reminderMsg struct{}
 type errorMsg string
 
+// dataLoadedMsg delivers storage and data opened in the background at startup
+type dataLoadedMsg struct {
+	storage   storage.StorageInterface
+	app       *models.Application
+	recovered int               // Operations replayed from the journal of a crashed session
+	purged    []models.ListTask // Completed tasks moved to the trash for their age
+}
+
+// loadErrorMsg reports that storage could not be opened or loaded at startup
+type loadErrorMsg struct {
+	err error
+}
+
 // checkReminders returns a command to check for reminders periodically
 func (m *Model) checkReminders() tea.Cmd {
 	return tea.Tick(time.Minute, func(t time.Time) tea.Msg {
@@ -604,24 +1753,40 @@ func (m *Model) checkReminders() tea.Cmd {
 	})
 }
 
-// checkForDueReminders checks for tasks that need reminders
-func (m *Model) checkForDueReminders() {
-	if time.Since(m.lastReminderCheck) < time.Minute {
-		return
+// checkForDueReminders gives the reminders that are due, once per task and
+// stage: when the deadline comes within the reminder lead time and when it
+// passes. The status bar shows the first one; with desktop notifications on,
+// each is also sent to the desktop. Do not disturb and quiet hours hold them
+// back, and they all come up on the first check after.
+func (m *Model) checkForDueReminders() tea.Cmd {
+	now := m.now()
+	if now.Sub(m.lastReminderCheck) < time.Minute || m.remindersHeld(now) {
+		return nil
 	}
 
-	m.lastReminderCheck = time.Now()
-	reminderWindow := time.Duration(m.app.Settings.ReminderMinutes) * time.Minute
+	m.lastReminderCheck = now
+
+	pending, err := m.pendingReminders(now)
+	if err != nil || len(pending) == 0 {
+		return nil
+	}
 
-	for _, list := range m.app.TodoLists {
-		for _, task := range list.Tasks {
-			if task.Deadline != nil && !task.Completed {
-				timeUntilDeadline := time.Until(*task.Deadline)
-				if timeUntilDeadline > 0 && timeUntilDeadline <= reminderWindow {
-					m.showMessage(fmt.Sprintf("⏰ Task '%s' is due in %s!", task.Title, timeUntilDeadline.Round(time.Minute)))
-					return
-				}
+	var cmds []tea.Cmd
+	for i, reminder := range pending {
+		text, icon := reminder.text(now)
+		if i == 0 {
+			status := text
+			if len(pending) > 1 {
+				status += fmt.Sprintf(" (+%d more)", len(pending)-1)
 			}
+			m.showMessage(withIcon(icon, status))
+		}
+
+		m.reminded[reminder.task.ID] = reminderState{deadline: *reminder.task.Deadline, stage: reminder.stage}
+		if m.app.Settings.DesktopNotify {
+			cmds = append(cmds, desktopNotify("LazyTodo reminder", text))
 		}
 	}
+
+	return tea.Batch(cmds...)
 }
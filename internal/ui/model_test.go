@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// testClock is the time a test model sees, moved by the test
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// newTestModel returns a model with a database of its own loaded, whose clock
+// is clock
+func newTestModel(t *testing.T, clock *testClock) *Model {
+	t.Helper()
+	storage.SetDataDir(t.TempDir())
+	t.Cleanup(func() { storage.SetDataDir("") })
+
+	store, err := storage.NewDatabase(storage.Options{Output: io.Discard})
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+	return newTestModelOn(t, clock, store)
+}
+
+// newTestModelOn returns a loaded model like newTestModel, on the given store
+func newTestModelOn(t *testing.T, clock *testClock, store storage.StorageInterface) *Model {
+	t.Helper()
+	t.Cleanup(func() { store.Close() })
+	app, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	m := NewModel(Options{})
+	m.now = func() time.Time { return clock.now }
+	m.remindersSince = clock.now
+	m.lastReminderCheck = clock.now.Add(-time.Minute) // Check right away
+	m.finishLoading(dataLoadedMsg{storage: store, app: app})
+	return m
+}
+
+// mustAddTask creates a task with a deadline, or none for nil, in a list of
+// its own
+func mustAddTask(t *testing.T, m *Model, title string, deadline *time.Time) models.Task {
+	t.Helper()
+	listID := m.currentListID
+	if listID == "" {
+		var err error
+		if listID, err = m.storage.CreateTodoList(m.app, "Work", "", ""); err != nil {
+			t.Fatalf("CreateTodoList: %v", err)
+		}
+		m.currentListID = listID
+	}
+	task, err := m.storage.CreateTask(m.app, listID, title, "", models.Medium, deadline, "", models.SourceTUI)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	m.putTask(listID, task)
+	return task
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/DhirajZope/lazytodo/internal/models"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // renderSidebarContent renders the sidebar content (todo lists)
@@ -18,19 +19,19 @@ func (m *Model) renderSidebarContent() string {
 	if len(m.app.TodoLists) == 0 {
 		welcomeTitle := BaseTitleStyle.Copy().
 			Foreground(PrimaryColor).
-			Render("🎯 Welcome to LazyTodo!")
+			Render(withIcon(icons.App, "Welcome to LazyTodo!"))
 
 		emptyMsg := BaseSubtitleStyle.Copy().
 			Foreground(AccentColor).
-			Render("📋 Ready to get organized?")
+			Render(withIcon(icons.Lists, "Ready to get organized?"))
 
 		instructions := []string{
-			"✨ Create your first todo list to get started",
+			withIcon(icons.Sparkle, "Create your first todo list to get started"),
 			"",
-			"📝 Press 'n' to create a new list",
-			"🎯 Organize tasks by projects or contexts",
-			"⚡ Set priorities and deadlines",
-			"📊 Track your progress",
+			withIcon(icons.Tasks, "Press 'n' to create a new list"),
+			withIcon(icons.App, "Organize tasks by projects or contexts"),
+			withIcon(icons.PriorityMedium, "Set priorities and deadlines"),
+			withIcon(icons.Progress, "Track your progress"),
 		}
 
 		instructionText := DescStyle.Render(strings.Join(instructions, "\n"))
@@ -45,42 +46,41 @@ func (m *Model) renderSidebarContent() string {
 		)
 	}
 
-	// Use the bubble tea list for sidebar if we're in list view
-	var content string
-	if m.todoListsList.Items() != nil {
-		content = m.todoListsList.View()
-	} else {
-		// Fallback rendering
-		var lines []string
-		for i, todoList := range m.app.TodoLists {
-			icon := "📋"
-			title := todoList.Name
-			subtitle := fmt.Sprintf("%.0f%% complete (%d tasks)",
-				todoList.GetProgress(), todoList.GetTotalCount())
-
-			selected := (m.currentListID == todoList.ID)
-			item := RenderEnhancedListItem(icon, title, subtitle, selected, false)
-
-			if i == 0 && m.currentListID == "" {
-				m.currentListID = todoList.ID
-				m.updateTasksList()
-			}
-
-			lines = append(lines, item)
-		}
-		content = lipgloss.JoinVertical(lipgloss.Left, lines...)
+	if m.heatmapShown() {
+		return lipgloss.JoinVertical(lipgloss.Left, m.renderHeatmap(m.todoListsList.Width()), "", m.todoListsList.View())
 	}
-
-	return content
+	return m.todoListsList.View()
 }
 
 // renderMainContent renders the main window content based on current state
 func (m *Model) renderMainContent() string {
-	switch m.state {
+	state := m.state
+	if state == URLChooserView {
+		// The URL chooser is drawn over the view it was opened from
+		state = m.urlReturn
+	}
+
+	switch state {
 	case ListsView, TasksView:
-		return m.renderTasksContent()
+		return m.renderCriticalBanner(m.tasksList.Width()) + m.renderTasksContent()
 	case SettingsView:
 		return m.renderSettingsContent()
+	case TaskDetailView, AddNoteView, EditTimeView, EditLinkView, EditReminderView:
+		return m.renderTaskDetailContent()
+	case ActivityView:
+		return m.renderActivityContent()
+	case ActivityLogView:
+		return m.renderActivityLogContent()
+	case OverdueView:
+		return m.renderOverdueContent()
+	case StarredView:
+		return m.renderStarredContent()
+	case CalendarView:
+		return m.renderCalendarContent()
+	case TrashView:
+		return m.renderTrashContent()
+	case LogView:
+		return m.renderLogContent()
 	default:
 		return m.renderTasksContent()
 	}
@@ -95,23 +95,23 @@ func (m *Model) renderTasksContent() string {
 	if len(m.app.TodoLists) == 0 {
 		welcomeTitle := BaseTitleStyle.Copy().
 			Foreground(PrimaryColor).
-			Render("🚀 Let's Get Started!")
+			Render(withIcon(icons.Start, "Let's Get Started!"))
 
 		emptyMsg := BaseSubtitleStyle.Copy().
 			Foreground(AccentColor).
-			Render("📝 No todo lists yet")
+			Render(withIcon(icons.Tasks, "No todo lists yet"))
 
 		hint := DescStyle.Render("Create your first list in the sidebar (Press Ctrl+S to focus sidebar)")
 
 		features := []string{
-			"🎯 LazyTodo Features:",
+			withIcon(icons.App, "LazyTodo Features:"),
 			"",
-			"📋 Multiple todo lists for different projects",
-			"⚡ Priority levels (Low, Medium, High, Critical)",
-			"📅 Deadline tracking with smart reminders",
-			"✅ Progress tracking and completion stats",
-			"💾 Automatic SQLite database storage",
-			"🎨 Beautiful multi-window interface",
+			withIcon(icons.Lists, "Multiple todo lists for different projects"),
+			withIcon(icons.PriorityMedium, "Priority levels (Low, Medium, High, Critical)"),
+			withIcon(icons.Deadline, "Deadline tracking with smart reminders"),
+			withIcon(icons.Done, "Progress tracking and completion stats"),
+			withIcon(icons.Storage, "Automatic SQLite database storage"),
+			withIcon(icons.Theme, "Beautiful multi-window interface"),
 		}
 
 		featureText := DescStyle.Render(strings.Join(features, "\n"))
@@ -130,13 +130,13 @@ func (m *Model) renderTasksContent() string {
 
 	currentList := m.getCurrentList()
 	if currentList == nil {
-		emptyMsg := BaseSubtitleStyle.Render("📋 Select a todo list from the sidebar")
+		emptyMsg := BaseSubtitleStyle.Render(withIcon(icons.Lists, "Select a todo list from the sidebar"))
 		hint := DescStyle.Render("Use Ctrl+S to focus the sidebar")
 		return lipgloss.JoinVertical(lipgloss.Left, emptyMsg, "", hint)
 	}
 
 	// Update main window title
-	m.layout.SetWindowTitle(MainWindow, fmt.Sprintf("📝 %s", currentList.Name))
+	m.layout.SetWindowTitle(MainWindow, withIcon(icons.Tasks, currentList.Name))
 
 	if len(currentList.Tasks) == 0 {
 		emptyMsg := BaseSubtitleStyle.Render("No tasks yet")
@@ -144,87 +144,231 @@ func (m *Model) renderTasksContent() string {
 		return lipgloss.JoinVertical(lipgloss.Center, emptyMsg, "", hint)
 	}
 
-	// Use the bubble tea list for main content if available
-	if m.tasksList.Items() != nil {
-		return m.tasksList.View()
+	// Every task is completed and completed tasks are hidden
+	if len(m.tasksList.Items()) == 0 {
+		emptyMsg := BaseSubtitleStyle.Render("All tasks completed!")
+		hint := DescStyle.Render("Toggle 'Show Completed' in settings to see completed tasks")
+		return lipgloss.JoinVertical(lipgloss.Center, emptyMsg, "", hint)
 	}
 
-	// Fallback rendering
+	return m.tasksList.View()
+}
+
+// renderTaskDetailContent renders a single task with its notes
+func (m *Model) renderTaskDetailContent() string {
+	task := m.getDetailTask()
+	if task == nil {
+		return BaseSubtitleStyle.Render("Task not found")
+	}
+
+	m.layout.SetWindowTitle(MainWindow, withIcon(icons.Details, "Task Details"))
+
 	var lines []string
-	for _, task := range currentList.Tasks {
-		if !m.app.Settings.ShowCompleted && task.Completed {
-			continue
-		}
+	// The full title and text are shown here, wrapped, where lists cut them short
+	lines = append(lines, BaseTitleStyle.Render(m.wrapText(highlightURLs(plainLine(task.Title), BaseTitleStyle.UnsetPadding()))))
+	lines = append(lines, "")
 
-		icon := "○"
-		if task.Completed {
-			icon = "✓"
+	status := withIcon(icons.Incomplete, "Open")
+	if task.Completed {
+		status = withIcon(icons.Complete, "Completed")
+	}
+	if task.Starred {
+		status += " " + withIcon(icons.Starred, "Starred")
+	}
+	lines = append(lines, FormLabel.Render("Status: ")+DescStyle.Render(status))
+	lines = append(lines, FormLabel.Render("Priority: ")+DescStyle.Render(task.Priority.String()))
+	if task.Label != "" {
+		lines = append(lines, FormLabel.Render("Label: ")+labelText(task.Label))
+	}
+
+	if task.Deadline != nil {
+		deadline := formatDeadline(*task.Deadline)
+		if task.IsOverdue() {
+			deadline += " (OVERDUE)"
+		} else if m.isDueSoon(task) {
+			deadline += " (SOON)"
+		}
+		lines = append(lines, FormLabel.Render("Deadline: ")+
+			GetDeadlineStyle(task.IsOverdue(), m.isDueSoon(task)).Render(deadline))
+	}
+	if task.Deadline != nil || task.ReminderOffset != nil {
+		lines = append(lines, FormLabel.Render("Reminder: ")+DescStyle.Render(m.reminderSummary(task)))
+	}
+	if task.Progress > 0 {
+		lines = append(lines, FormLabel.Render("Progress: ")+progressBadge(task.Progress))
+	}
+	if task.SnoozeCount > 0 {
+		lines = append(lines, FormLabel.Render("Snoozed: ")+DescStyle.Render(snoozeBadge(task.SnoozeCount)))
+	}
+	if task.Estimate > 0 {
+		lines = append(lines, FormLabel.Render("Estimate: ")+DescStyle.Render(models.FormatDuration(task.Estimate)))
+	}
+	if task.Spent > 0 || m.timerRunning(task.ID) {
+		spent := models.FormatDuration(task.Spent)
+		if task.Estimate > 0 {
+			spent += fmt.Sprintf(" (%.0f%% of estimate)", float64(task.Spent)/float64(task.Estimate)*100)
+		}
+		if m.timerRunning(task.ID) {
+			spent += " • timer running for " + models.FormatDuration(time.Since(m.timerStart))
 		}
+		lines = append(lines, FormLabel.Render("Spent: ")+DescStyle.Render(spent))
+	}
+	if task.Link != "" {
+		lines = append(lines, FormLabel.Render("Link: ")+DescStyle.Render(withIcon(icons.Link, task.Link)))
+	}
 
-		title := task.Title
-		subtitle := ""
+	if !task.CreatedAt.IsZero() {
+		lines = append(lines, FormLabel.Render("Created: ")+DescStyle.Render(formatRelativeTime(task.CreatedAt)))
+	}
+	lines = append(lines, FormLabel.Render("Source: ")+DescStyle.Render(task.CreationSource()))
 
-		// Add description if present
-		if task.Description != "" {
-			subtitle = task.Description
-		}
+	if task.Description != "" {
+		lines = append(lines, "")
+		lines = append(lines, FormLabel.Render("Description:"))
+		lines = append(lines, m.wrapText(highlightURLs(plainText(task.Description), DescStyle)))
+	}
 
-		// Add deadline info
-		if task.Deadline != nil {
-			deadlineStr := task.Deadline.Format("2006-01-02 15:04")
-			if task.IsOverdue() {
-				deadlineStr = "⚠️ Due: " + deadlineStr + " (OVERDUE)"
-			} else if task.IsDueSoon() {
-				deadlineStr = "⏰ Due: " + deadlineStr + " (SOON)"
-			} else {
-				deadlineStr = "📅 Due: " + deadlineStr
-			}
+	lines = append(lines, "")
+	lines = append(lines, FormLabel.Render(withIcon(icons.Details, fmt.Sprintf("Notes (%d)", len(task.Notes)))))
 
-			if subtitle != "" {
-				subtitle += " • " + deadlineStr
-			} else {
-				subtitle = deadlineStr
-			}
+	if len(task.Notes) == 0 {
+		lines = append(lines, BaseSubtitleStyle.Render("No notes yet. Press 'n' to add one."))
+	}
+
+	timeStyle := lipgloss.NewStyle().Foreground(TextMuted)
+	for i, note := range notesNewestFirst(task.Notes) {
+		line := m.wrapText(timeStyle.Render(fmt.Sprintf("%-10s", formatRelativeTime(note.CreatedAt))) + " " + plainText(note.Body))
+		if i == m.noteCursor {
+			lines = append(lines, ListItemSelected.Render(line))
+		} else {
+			lines = append(lines, ListItemNormal.Render(line))
 		}
+	}
 
-		// Add priority indicator
-		if task.Priority != models.Low {
-			priorityStr := ""
-			switch task.Priority {
-			case models.Medium:
-				priorityStr = "⚡ Medium"
-			case models.High:
-				priorityStr = "🔥 High"
-			case models.Critical:
-				priorityStr = "🚨 Critical"
-			}
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render("n: add note • d: delete note • E: time • t: timer • b: link • o: open • O: open URL • R: reminder • Esc: back"))
 
-			if subtitle != "" {
-				subtitle += " • " + priorityStr
-			} else {
-				subtitle = priorityStr
-			}
-		}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// renderActivityContent renders the recent activity feed
+func (m *Model) renderActivityContent() string {
+	m.layout.SetWindowTitle(MainWindow, withIcon(icons.Activity, "Recent Activity"))
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render(withIcon(icons.Activity, "Recent Activity")))
+	lines = append(lines, "")
 
-		item := RenderEnhancedListItem(icon, title, subtitle, false, task.Completed)
-		lines = append(lines, item)
+	if len(m.activity) == 0 {
+		lines = append(lines, BaseSubtitleStyle.Render("No activity yet"))
 	}
 
-	if len(lines) == 0 {
-		emptyMsg := BaseSubtitleStyle.Render("All tasks completed!")
-		hint := DescStyle.Render("Toggle 'Show Completed' in settings to see completed tasks")
-		return lipgloss.JoinVertical(lipgloss.Center, emptyMsg, "", hint)
+	// Keep the cursor inside the rows that fit in the window
+	visible := 10
+	if mainWindow := m.layout.GetWindow(MainWindow); mainWindow != nil && mainWindow.Position.Height-10 > visible {
+		visible = mainWindow.Position.Height - 10
+	}
+	start := 0
+	if m.activityCursor >= visible {
+		start = m.activityCursor - visible + 1
 	}
+	end := start + visible
+	if end > len(m.activity) {
+		end = len(m.activity)
+	}
+
+	timeStyle := lipgloss.NewStyle().Foreground(TextMuted)
+	for i := start; i < end; i++ {
+		entry := m.activity[i]
+		line := timeStyle.Render(fmt.Sprintf("%-10s", formatRelativeTime(entry.At))) + " " +
+			activityKindStyle(entry.Kind).Render(fmt.Sprintf("%-12s", entry.Kind)) + " " +
+			plainLine(entry.Title)
+		if entry.TaskID != "" {
+			line += timeStyle.Render(" · " + entry.ListName)
+		}
+		line = m.fitRow(line)
+
+		if i == m.activityCursor {
+			lines = append(lines, ListItemSelected.Render(line))
+		} else {
+			lines = append(lines, ListItemNormal.Render(line))
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, BaseSubtitleStyle.Render("Derived from timestamps: each item shows only its latest change, deleted items are not listed"))
+	lines = append(lines, DescStyle.Render("↑/↓: select • Enter: jump to item • Esc: back to tasks"))
 
 	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
+// activityKindStyle colors an activity entry by what happened
+func activityKindStyle(kind models.ActivityKind) lipgloss.Style {
+	switch kind {
+	case models.TaskCompleted:
+		return lipgloss.NewStyle().Foreground(AccentColor)
+	case models.TaskEdited:
+		return lipgloss.NewStyle().Foreground(WarningColor)
+	case models.ListCreated:
+		return lipgloss.NewStyle().Foreground(PrimaryColor)
+	default:
+		return lipgloss.NewStyle().Foreground(InfoColor)
+	}
+}
+
+// formatRelativeTime describes a past timestamp relative to now
+func formatRelativeTime(t time.Time) string {
+	elapsed := time.Since(t)
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed.Minutes()))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(elapsed.Hours()))
+	case elapsed < 7*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(elapsed.Hours()/24))
+	default:
+		return t.Local().Format("2006-01-02")
+	}
+}
+
+// dateLayout is the active deadline format, resolved from Settings.DateFormat
+var dateLayout = models.DeadlineLayout
+
+// formatDeadline formats a deadline in the configured date format
+func formatDeadline(deadline time.Time) string {
+	return deadline.Format(dateLayout)
+}
+
+// deadlineExample shows the configured date format filled in with the current time
+func deadlineExample() string {
+	return formatDeadline(models.WallClock(time.Now()))
+}
+
+// formatTimeUntil describes a deadline relative to now, e.g. "in 3h"
+func formatTimeUntil(deadline time.Time) string {
+	remaining := deadline.Sub(models.WallClock(time.Now()))
+	switch {
+	case remaining < 0:
+		return "overdue"
+	case remaining < time.Minute:
+		return "now"
+	case remaining < time.Hour:
+		return fmt.Sprintf("in %dm", int(remaining.Minutes()))
+	case remaining < 24*time.Hour:
+		return fmt.Sprintf("in %dh", int(remaining.Round(time.Hour).Hours()))
+	default:
+		return fmt.Sprintf("in %dd", int(remaining.Round(24*time.Hour).Hours()/24))
+	}
+}
+
 // renderSettingsContent renders the settings view
 func (m *Model) renderSettingsContent() string {
-	m.layout.SetWindowTitle(MainWindow, "⚙️ Settings")
+	m.layout.SetWindowTitle(MainWindow, withIcon(icons.Settings, "Settings"))
 
 	var lines []string
-	lines = append(lines, BaseTitleStyle.Render("📝 Application Settings"))
+	lines = append(lines, BaseTitleStyle.Render(withIcon(icons.Tasks, "Application Settings")))
 	lines = append(lines, "")
 
 	// Settings display
@@ -232,14 +376,46 @@ func (m *Model) renderSettingsContent() string {
 		fmt.Sprintf("Reminder Minutes: %d", m.app.Settings.ReminderMinutes),
 		fmt.Sprintf("Show Completed: %v", m.app.Settings.ShowCompleted),
 		fmt.Sprintf("Auto Save: %v", m.app.Settings.AutoSave),
+		fmt.Sprintf("Git Sync: %s", syncRepoLabel(m.app.Settings.SyncRepo)),
 	}
 
 	for _, setting := range settings {
 		lines = append(lines, "  "+DescStyle.Render(setting))
 	}
 
+	// Editable settings, in the order of the settingIcons... constants
+	editable := []string{
+		m.configLabel(settingIcons, fmt.Sprintf("Icons: %s", iconsLabel(m.app.Settings.Icons, m.opts.ASCII))),
+		m.configLabel(settingDateFormat, fmt.Sprintf("Date Format: %s (%s)", m.app.Settings.DateFormat, deadlineExample())),
+		fmt.Sprintf("Desktop Notifications: %s", notifyLabel(m.app.Settings.DesktopNotify)),
+		fmt.Sprintf("Keep Deleted Tasks: %d days", m.app.Settings.TrashDays),
+		fmt.Sprintf("Busy Day Warning: %s", dayTaskLimitLabel(m.app.Settings.DayTaskLimit)),
+		fmt.Sprintf("Due Soon: %s", dueSoonLabel(m.app.Settings.DueSoonHours)),
+		fmt.Sprintf("Weekly Review: %s", reviewLabel(m.app.Settings.ReviewDay, m.app.Settings.ReviewHour)),
+		fmt.Sprintf("Review Time: %02d:00", m.app.Settings.ReviewHour),
+		fmt.Sprintf("Duplicate List Names: %s", uniqueListNamesLabel(m.app.Settings.UniqueListNames)),
+		fmt.Sprintf("Quiet Hours: %s", quietHoursLabel(m.app.Settings.QuietHours)),
+		fmt.Sprintf("Completion Heatmap: %s", notifyLabel(m.app.Settings.ShowHeatmap)),
+		fmt.Sprintf("Stale Tasks: %s", staleLabel(m.app.Settings.StaleDays)),
+		fmt.Sprintf("Completed While Hidden: %s", lingerLabel(m.app.Settings.CompletedLinger)),
+		fmt.Sprintf("Critical Overdue Reminders: %s", criticalRepeatLabel(m.app.Settings.CriticalRepeatMinutes)),
+		fmt.Sprintf("Complete at 100%% Progress: %s", notifyLabel(m.app.Settings.CompleteAtFullProgress)),
+		fmt.Sprintf("Confirm Bulk Completion: %s", bulkConfirmLabel(m.app.Settings.BulkConfirmThreshold)),
+		fmt.Sprintf("Welcome Back Summary: %s", welcomeBackLabel(m.app.Settings.WelcomeBackHours)),
+		fmt.Sprintf("Clear Stars Daily: %s", notifyLabel(m.app.Settings.ClearStarsDaily)),
+		fmt.Sprintf("Move Old Completed Tasks to Trash: %s", purgeCompletedLabel(m.app.Settings.PurgeCompletedDays)),
+		fmt.Sprintf("Run Maintenance: %s", m.maintenanceLabel()),
+	}
+	for i, setting := range editable {
+		if i == m.settingsCursor {
+			lines = append(lines, ListItemSelected.Render(setting))
+		} else {
+			lines = append(lines, "  "+DescStyle.Render(setting))
+		}
+	}
+
 	lines = append(lines, "")
-	lines = append(lines, BaseSubtitleStyle.Render("Settings can be modified by editing the database directly"))
+	lines = append(lines, BaseSubtitleStyle.Render("Use ↑/↓ to pick a setting and ←/→ to change it; others can be modified by editing the database directly"))
 	lines = append(lines, DescStyle.Render("Press Esc to go back to task view"))
 
 	return lipgloss.JoinVertical(lipgloss.Left, lines...)
@@ -247,6 +423,11 @@ func (m *Model) renderSettingsContent() string {
 
 // renderStatusContent renders the status bar content
 func (m *Model) renderStatusContent() string {
+	if m.resizing {
+		return StyleStatusMessage(fmt.Sprintf("Resizing the sidebar (%d columns): Ctrl+→/← move the divider • Esc to finish",
+			m.layout.SidebarWidth()), "info")
+	}
+
 	// Show message if recent
 	if time.Since(m.messageTime) < 3*time.Second && m.message != "" {
 		return StyleStatusMessage(m.message, m.messageType)
@@ -255,6 +436,30 @@ func (m *Model) renderStatusContent() string {
 	// Build status information
 	var statusParts []string
 
+	if m.readOnly {
+		statusParts = append(statusParts, ReadOnlyBadge.Render(withIcon(icons.ReadOnly, "READ-ONLY")))
+	}
+	if badge := profileBadge(); badge != "" {
+		statusParts = append(statusParts, badge)
+	}
+	if badge := m.dndBadge(); badge != "" {
+		statusParts = append(statusParts, badge)
+	}
+	if m.dirty {
+		statusParts = append(statusParts, UnsavedBadge.Render(withIcon(icons.Unsaved, "unsaved"))+
+			" "+KeyStyle.Render(m.keys.SaveNow.Help().Key))
+	}
+	if m.timerTaskID != "" {
+		statusParts = append(statusParts, withIcon(icons.Timer, models.FormatDuration(time.Since(m.timerStart))))
+	}
+	if m.overdueCount > 0 {
+		statusParts = append(statusParts, OverdueBadge.Render(withIcon(icons.Warning, fmt.Sprintf("%d overdue", m.overdueCount)))+
+			" "+KeyStyle.Render("Ctrl+O"))
+	}
+	if badge := m.streakBadge(); badge != "" {
+		statusParts = append(statusParts, badge)
+	}
+
 	// Current state info
 	if m.app != nil {
 		switch m.state {
@@ -267,9 +472,33 @@ func (m *Model) renderStatusContent() string {
 					fmt.Sprintf("Tasks: %d/%d",
 						currentList.GetCompletedCount(),
 						currentList.GetTotalCount()))
+
+				if estimate, spent := currentList.GetTimeTotals(); estimate > 0 || spent > 0 {
+					statusParts = append(statusParts, fmt.Sprintf("Time: %s/%s",
+						models.FormatDuration(spent), models.FormatDuration(estimate)))
+				}
+				if remaining := currentList.GetRemainingEstimate(); remaining > 0 {
+					statusParts = append(statusParts, "Left: "+models.FormatDuration(remaining))
+				}
 			}
 		case SettingsView:
 			statusParts = append(statusParts, "Settings")
+		case TaskDetailView, AddNoteView, EditTimeView, EditLinkView, EditReminderView:
+			statusParts = append(statusParts, "Task Details")
+		case ActivityView:
+			statusParts = append(statusParts, "Recent Activity")
+		case ActivityLogView:
+			statusParts = append(statusParts, "Activity Log")
+		case OverdueView:
+			statusParts = append(statusParts, "Overdue Tasks")
+		case StarredView:
+			statusParts = append(statusParts, "Starred Tasks")
+		case CalendarView:
+			statusParts = append(statusParts, "Calendar")
+		case TrashView:
+			statusParts = append(statusParts, "Trash")
+		case LogView:
+			statusParts = append(statusParts, "Log")
 		}
 	}
 
@@ -286,21 +515,46 @@ func (m *Model) renderStatusContent() string {
 		statusParts = append(statusParts, "Focus: Help")
 	}
 
-	// Key hints
-	keyHints := []string{
-		KeyStyle.Render("?") + " Help",
-		KeyStyle.Render("Ctrl+→/←") + " Windows",
-		KeyStyle.Render("q") + " Quit",
-	}
-
-	// Combine status parts and key hints
+	// Key hints for the context get the room the status leaves, down to "? more";
+	// the status gives way when even that does not fit
+	const gap = "    "
 	status := strings.Join(statusParts, " • ")
-	hints := strings.Join(keyHints, "  ")
+	available := max(m.width-2, 0) // The status window pads its content
+	hints := m.renderKeyHints(max(available-lipgloss.Width(status)-len(gap), 0))
+	status = ansi.Truncate(status, max(available-lipgloss.Width(hints)-len(gap), 0), "…")
 
-	// Use available width to balance status and hints
-	totalContent := status + "    " + hints
+	return BaseContentStyle.Render(status + gap + hints)
+}
+
+// renderLoading renders the full-screen spinner shown while data loads
+func (m *Model) renderLoading() string {
+	content := lipgloss.JoinVertical(
+		lipgloss.Center,
+		BaseTitleStyle.Render(withIcon(icons.App, "LazyTodo")),
+		"",
+		m.spinner.View()+" "+BaseSubtitleStyle.Render(m.loadingText),
+	)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
 
-	return BaseContentStyle.Render(totalContent)
+// renderLoadError renders the full-screen error shown when data could not be loaded
+func (m *Model) renderLoadError() string {
+	message := lipgloss.NewStyle().
+		Width(m.width * 2 / 3).
+		Align(lipgloss.Center).
+		Render(m.loadErr.Error())
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Center,
+		BaseTitleStyle.Copy().Foreground(ErrorColor).Render(withIcon(icons.Error, "Could not open LazyTodo data")),
+		"",
+		message,
+		"",
+		DescStyle.Render("Press q or Esc to quit"),
+	)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
 }
 
 // renderFormContent renders form content for overlays
@@ -310,21 +564,100 @@ func (m *Model) renderFormContent() string {
 		return m.renderListFormContent()
 	case CreateTaskView, EditTaskView:
 		return m.renderTaskFormContent()
+	case AddNoteView:
+		return m.renderNoteFormContent()
+	case SetDeadlineView:
+		return m.renderDeadlineFormContent()
+	case SnoozeView:
+		return m.renderSnoozeContent()
+	case EditTimeView:
+		return m.renderTimeFormContent()
+	case TemplateNameView:
+		return m.renderTemplateNameFormContent()
+	case TemplatesView:
+		return m.renderTemplatesContent()
+	case CommandPaletteView:
+		return m.renderCommandPaletteContent()
+	case SetupView:
+		return m.renderSetupContent()
+	case EditLinkView:
+		return m.renderLinkFormContent()
+	case EditReminderView:
+		return m.renderReminderFormContent()
+	case ShiftDeadlinesView:
+		return m.renderShiftFormContent()
+	case URLChooserView:
+		return m.renderURLChooserContent()
+	case PriorityView:
+		return m.renderPriorityChooserContent()
+	case DoNotDisturbView:
+		return m.renderDoNotDisturbContent()
+	case ConfirmView:
+		return m.renderConfirmContent()
+	case WelcomeView:
+		return m.renderWelcomeContent()
 	default:
 		return ""
 	}
 }
 
+// renderNoteFormContent renders the one-line note input
+func (m *Model) renderNoteFormContent() string {
+	m.layout.SetWindowTitle(FormWindow, withIcon(icons.Details, "Add Note"))
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render("Add Note"))
+	lines = append(lines, "")
+	lines = append(lines, FormLabel.Render("Note:"))
+	lines = append(lines, FormFieldFocused.Render(m.noteInput.View()))
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render("Enter: Save • Esc: Cancel"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// renderDeadlineFormContent renders the quick deadline prompt for a task
+func (m *Model) renderDeadlineFormContent() string {
+	title, label := "Set Deadline", fmt.Sprintf("Deadline (e.g. %s, empty to clear):", deadlineExample())
+	if m.snoozing {
+		title, label = "Snooze Until", fmt.Sprintf("New deadline (e.g. %s):", deadlineExample())
+	}
+	m.layout.SetWindowTitle(FormWindow, withIcon(icons.Deadline, title))
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render(title))
+	lines = append(lines, "")
+	if m.bulkDeadline {
+		lines = append(lines, DescStyle.Render(markedCount(len(m.marked))))
+		lines = append(lines, "")
+	} else if task := m.getTask(m.editingTaskID); task != nil {
+		lines = append(lines, DescStyle.Render(task.Title))
+		lines = append(lines, "")
+	}
+	lines = append(lines, FormLabel.Render(label))
+	if m.datePicking {
+		lines = append(lines, m.datePicker.View())
+		return lipgloss.JoinVertical(lipgloss.Left, lines...)
+	}
+	lines = append(lines, FormFieldFocused.Render(m.deadlineInput.View()))
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render(fmt.Sprintf("Enter: Save • %s: calendar • Esc: Cancel", m.keys.DatePicker.Help().Key)))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
 // renderListFormContent renders the todo list form
 func (m *Model) renderListFormContent() string {
 	title := "Create New List"
 	if m.editing {
 		title = "Edit List"
-		m.layout.SetWindowTitle(FormWindow, "✏️ Edit List")
+		m.layout.SetWindowTitle(FormWindow, withIcon(icons.Edit, "Edit List"))
 	} else {
-		m.layout.SetWindowTitle(FormWindow, "➕ Create List")
+		m.layout.SetWindowTitle(FormWindow, withIcon(icons.Create, "Create List"))
 	}
 
+	problems := m.formProblems(m.listFormChecks())
+
 	var lines []string
 	lines = append(lines, BaseTitleStyle.Render(title))
 	lines = append(lines, "")
@@ -339,6 +672,7 @@ func (m *Model) renderListFormContent() string {
 	}
 	lines = append(lines, titleLabel)
 	lines = append(lines, titleField)
+	lines = appendFieldProblem(lines, problems, 0)
 	lines = append(lines, "")
 
 	// Description field
@@ -351,8 +685,55 @@ func (m *Model) renderListFormContent() string {
 	}
 	lines = append(lines, descLabel)
 	lines = append(lines, descField)
+	lines = appendFieldProblem(lines, problems, 1)
+	lines = append(lines, "")
+
+	// Group field
+	groupLabel := FormLabel.Render("Group (sidebar heading):")
+	var groupField string
+	if m.formFocusIndex == 2 {
+		groupField = FormFieldFocused.Render(m.groupInput.View())
+	} else {
+		groupField = FormFieldUnfocused.Render(m.groupInput.View())
+	}
+	lines = append(lines, groupLabel)
+	lines = append(lines, groupField)
+	lines = appendFieldProblem(lines, problems, 2)
+	lines = append(lines, "")
+
+	// Color picker
+	colorLabel := FormLabel.Render("Color (←/→ to choose):")
+	choice := listColors[m.colorIndex]
+	colorPicker := "‹ " + lipgloss.NewStyle().Foreground(choice.color).Render(icons.ListBullet) + " " + choice.name + " ›"
+	var colorField string
+	if m.formFocusIndex == 3 {
+		colorField = FormFieldFocused.Render(colorPicker)
+	} else {
+		colorField = FormFieldUnfocused.Render(colorPicker)
+	}
+	lines = append(lines, colorLabel)
+	lines = append(lines, colorField)
 	lines = append(lines, "")
 
+	// Template picker, only offered for new lists
+	if m.listFormFields() > 4 {
+		templateLabel := FormLabel.Render("Template (←/→ to choose):")
+		templateName := "None"
+		if m.templateIndex > 0 {
+			template := m.app.Templates[m.templateIndex-1]
+			templateName = fmt.Sprintf("%s (%d tasks)", template.Name, len(template.Tasks))
+		}
+		var templateField string
+		if m.formFocusIndex == 4 {
+			templateField = FormFieldFocused.Render("‹ " + templateName + " ›")
+		} else {
+			templateField = FormFieldUnfocused.Render("‹ " + templateName + " ›")
+		}
+		lines = append(lines, templateLabel)
+		lines = append(lines, templateField)
+		lines = append(lines, "")
+	}
+
 	// Help text
 	helpText := CreateHelpSection("Form Controls", map[string]string{
 		"Tab/Shift+Tab": "Navigate fields",
@@ -369,11 +750,13 @@ func (m *Model) renderTaskFormContent() string {
 	title := "Create New Task"
 	if m.editing {
 		title = "Edit Task"
-		m.layout.SetWindowTitle(FormWindow, "✏️ Edit Task")
+		m.layout.SetWindowTitle(FormWindow, withIcon(icons.Edit, "Edit Task"))
 	} else {
-		m.layout.SetWindowTitle(FormWindow, "➕ Create Task")
+		m.layout.SetWindowTitle(FormWindow, withIcon(icons.Create, "Create Task"))
 	}
 
+	problems := m.formProblems(m.taskFormChecks())
+
 	var lines []string
 	lines = append(lines, BaseTitleStyle.Render(title))
 	lines = append(lines, "")
@@ -388,6 +771,7 @@ func (m *Model) renderTaskFormContent() string {
 	}
 	lines = append(lines, titleLabel)
 	lines = append(lines, titleField)
+	lines = appendFieldProblem(lines, problems, 0)
 	lines = append(lines, "")
 
 	// Description field
@@ -400,10 +784,16 @@ func (m *Model) renderTaskFormContent() string {
 	}
 	lines = append(lines, descLabel)
 	lines = append(lines, descField)
+	lines = appendFieldProblem(lines, problems, 1)
 	lines = append(lines, "")
 
-	// Deadline field
-	deadlineLabel := FormLabel.Render("Deadline (YYYY-MM-DD HH:MM):")
+	// Deadline field, or the date picker open over it
+	deadlineLabel := FormLabel.Render(fmt.Sprintf("Deadline (e.g. %s):", deadlineExample()))
+	if m.datePicking {
+		lines = append(lines, deadlineLabel)
+		lines = append(lines, m.datePicker.View())
+		return lipgloss.JoinVertical(lipgloss.Left, lines...)
+	}
 	var deadlineField string
 	if m.formFocusIndex == 2 {
 		deadlineField = FormFieldFocused.Render(m.deadlineInput.View())
@@ -412,12 +802,34 @@ func (m *Model) renderTaskFormContent() string {
 	}
 	lines = append(lines, deadlineLabel)
 	lines = append(lines, deadlineField)
+	lines = appendFieldProblem(lines, problems, 2)
+	if m.editing {
+		lines = append(lines, lipgloss.NewStyle().Foreground(TextMuted).Render("Leave empty to remove the deadline"))
+	}
+	lines = append(lines, "")
+
+	// Label picker
+	labelLabel := FormLabel.Render("Label (←/→ to choose):")
+	labelValue := labelText(taskLabels[m.labelIndex])
+	if labelValue == "" {
+		labelValue = "none"
+	}
+	labelPicker := "‹ " + labelValue + " ›"
+	var labelField string
+	if m.formFocusIndex == 3 {
+		labelField = FormFieldFocused.Render(labelPicker)
+	} else {
+		labelField = FormFieldUnfocused.Render(labelPicker)
+	}
+	lines = append(lines, labelLabel)
+	lines = append(lines, labelField)
 	lines = append(lines, "")
 
 	// Help text
 	helpText := CreateHelpSection("Form Controls", map[string]string{
 		"Tab/Shift+Tab": "Navigate fields",
 		"Enter":         "Save",
+		"Ctrl+K":        "Pick the deadline from a calendar",
 		"Esc":           "Cancel",
 	})
 	lines = append(lines, helpText)
@@ -428,7 +840,9 @@ func (m *Model) renderTaskFormContent() string {
 // isInFormState checks if we're currently in a form state
 func (m *Model) isInFormState() bool {
 	switch m.state {
-	case CreateListView, EditListView, CreateTaskView, EditTaskView:
+	case CreateListView, EditListView, CreateTaskView, EditTaskView, AddNoteView, SetDeadlineView, SnoozeView, EditTimeView, PriorityView,
+		TemplateNameView, TemplatesView, CommandPaletteView, SetupView, EditLinkView, ShiftDeadlinesView,
+		EditReminderView, URLChooserView, DoNotDisturbView, ConfirmView, WelcomeView:
 		return true
 	default:
 		return false
@@ -440,4 +854,9 @@ func (m *Model) showMessageWithType(msg, msgType string) {
 	m.message = msg
 	m.messageType = msgType
 	m.messageTime = time.Now()
+
+	// Errors stay readable in the log view after the status bar moves on
+	if msgType == "error" {
+		m.log.add(logError, msg)
+	}
 }
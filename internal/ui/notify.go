@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// desktopNotify shows a desktop notification in the background using the
+// platform's notifier: notify-send on Linux and the BSDs, osascript on macOS.
+// Failures are ignored since the reminder is also shown in the status bar.
+func desktopNotify(title, body string) tea.Cmd {
+	var name string
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		name, args = "osascript", []string{"-e", fmt.Sprintf("display notification %q with title %q", body, title)}
+	case "windows", "plan9":
+		return nil
+	default:
+		name, args = "notify-send", []string{"--app-name=LazyTodo", title, body}
+	}
+
+	return func() tea.Msg {
+		exec.Command(name, args...).Run()
+		return nil
+	}
+}
@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// refreshOverdueCount updates the cached overdue count shown in the status bar,
+// and the banner of overdue Critical tasks
+func (m *Model) refreshOverdueCount() {
+	if m.storage == nil || m.app == nil {
+		return
+	}
+
+	count, err := m.storage.CountOverdue(m.app)
+	if err != nil {
+		return // Keep the last known count
+	}
+	m.overdueCount = count
+	m.refreshEscalations()
+}
+
+// openOverdueView lists the overdue tasks of all lists in the main window
+func (m *Model) openOverdueView() {
+	overdue, err := m.storage.OverdueTasks(m.app)
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return
+	}
+
+	m.overdue = overdue
+	m.overdueCount = len(overdue)
+	m.overdueCursor = 0
+	m.state = OverdueView
+	m.layout.SetFocus(MainWindow)
+}
+
+// Overdue view - browse overdue tasks and jump to the selected one
+func (m *Model) updateOverdueView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = TasksView
+		return m, nil
+
+	case key.Matches(msg, m.keys.Up):
+		if m.overdueCursor > 0 {
+			m.overdueCursor--
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down):
+		if m.overdueCursor < len(m.overdue)-1 {
+			m.overdueCursor++
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		if m.overdueCursor < len(m.overdue) {
+			entry := m.overdue[m.overdueCursor]
+			m.jumpToTask(entry.ListID, entry.Task.ID)
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Snooze):
+		m.snoozeOverdueEntry()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Star):
+		if m.overdueCursor < len(m.overdue) {
+			return m, m.toggleEntryStar(&m.overdue[m.overdueCursor])
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// renderOverdueContent renders the overdue tasks, oldest deadline first
+func (m *Model) renderOverdueContent() string {
+	m.layout.SetWindowTitle(MainWindow, withIcon(icons.Overdue, "Overdue Tasks"))
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render(withIcon(icons.Overdue, fmt.Sprintf("Overdue Tasks (%d)", len(m.overdue)))))
+	lines = append(lines, "")
+
+	if len(m.overdue) == 0 {
+		lines = append(lines, BaseSubtitleStyle.Render("Nothing is overdue"))
+	}
+
+	// Keep the cursor inside the rows that fit in the window
+	visible := 10
+	if mainWindow := m.layout.GetWindow(MainWindow); mainWindow != nil && mainWindow.Position.Height-10 > visible {
+		visible = mainWindow.Position.Height - 10
+	}
+	start := 0
+	if m.overdueCursor >= visible {
+		start = m.overdueCursor - visible + 1
+	}
+	end := start + visible
+	if end > len(m.overdue) {
+		end = len(m.overdue)
+	}
+
+	mutedStyle := lipgloss.NewStyle().Foreground(TextMuted)
+	for i := start; i < end; i++ {
+		entry := m.overdue[i]
+		line := m.fitRow(GetDeadlineStyle(true, false).Render(formatDeadline(*entry.Task.Deadline)) + " " +
+			starredTitle(entry.Task) + mutedStyle.Render(" · "+entry.ListName))
+
+		if i == m.overdueCursor {
+			lines = append(lines, ListItemSelected.Render(line))
+		} else {
+			lines = append(lines, ListItemNormal.Render(line))
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render("↑/↓: select • Enter: jump to task • z: snooze • *: star • Esc: back to tasks"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// dayTaskLimitChoices are the overload thresholds the settings view cycles
+// through; -1 turns the warning off
+var dayTaskLimitChoices = []int{-1, 3, 5, 8, 10, 15}
+
+// nextDayTaskLimit returns the overload threshold step places away from limit
+func nextDayTaskLimit(limit, step int) int {
+	for i, choice := range dayTaskLimitChoices {
+		if choice == limit {
+			return dayTaskLimitChoices[(i+step+len(dayTaskLimitChoices))%len(dayTaskLimitChoices)]
+		}
+	}
+	return dayTaskLimitChoices[0]
+}
+
+// dayTaskLimitLabel describes the overload threshold for the settings view
+func dayTaskLimitLabel(limit int) string {
+	if limit < 0 {
+		return "Off"
+	}
+	return fmt.Sprintf("more than %d tasks a day", limit)
+}
+
+// overloadWarning returns a heads-up when more incomplete tasks than the
+// day_task_limit setting are due on the calendar day of deadline, counting
+// the task that was just given it; empty when the day is not overloaded
+func (m *Model) overloadWarning(deadline *time.Time) string {
+	limit := m.app.Settings.DayTaskLimit
+	if deadline == nil || limit < 0 {
+		return ""
+	}
+
+	start, end := models.CalendarDay(*deadline)
+	due, err := m.storage.TasksDueBetween(m.app, start, end)
+	if err != nil || len(due) <= limit {
+		return ""
+	}
+	return fmt.Sprintf("%d tasks are due %s (limit %d)", len(due), start.Format("Mon Jan 2"), limit)
+}
+
+// sameDeadline reports whether two deadlines are both unset or the same time
+func sameDeadline(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// showDeadlineSaved shows message for a saved task, turned into a warning when
+// the deadline it was given overloads that day. Pass a nil deadline when it
+// did not change, so editing other fields does not repeat the warning.
+func (m *Model) showDeadlineSaved(message string, deadline *time.Time) {
+	if warning := m.overloadWarning(deadline); warning != "" {
+		m.showMessageWithType(message+" • "+warning, "warning")
+		return
+	}
+	m.showMessageWithType(message, "success")
+}
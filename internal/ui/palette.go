@@ -0,0 +1,418 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+// paletteVisibleItems is the number of matches shown at once in the palette
+const paletteVisibleItems = 8
+
+// paletteNameWidth is the column width of command names, after which key hints are shown
+const paletteNameWidth = 34
+
+// paletteCommand is an action that can be run from the command palette
+type paletteCommand struct {
+	name     string
+	binding  *key.Binding // Shortcut shown as a hint; points into the KeyMap so rebinding updates it
+	mutating bool         // Unavailable in read-only mode
+	run      func() tea.Cmd
+}
+
+// defaultCommands builds the static part of the command registry
+func (m *Model) defaultCommands() []paletteCommand {
+	return []paletteCommand{
+		{name: "New Task", binding: &m.keys.NewTask, mutating: true, run: func() tea.Cmd {
+			if m.getCurrentList() == nil {
+				m.showMessageWithType("Select a list first", "warning")
+				return nil
+			}
+			m.openNewTaskForm()
+			return nil
+		}},
+		{name: "Edit Task", binding: &m.keys.Edit, mutating: true, run: func() tea.Cmd {
+			item, ok := m.tasksList.SelectedItem().(taskItem)
+			if !ok {
+				m.showMessageWithType("Select a task first", "warning")
+				return nil
+			}
+			m.editingTaskID = item.id
+			m.prepareEditTaskForm()
+			m.state = EditTaskView
+			return nil
+		}},
+		{name: "Rename Task", binding: &m.keys.Rename, mutating: true, run: func() tea.Cmd {
+			item, ok := m.tasksList.SelectedItem().(taskItem)
+			if !ok {
+				m.showMessageWithType("Select a task first", "warning")
+				return nil
+			}
+			m.state = TasksView
+			m.layout.SetFocus(MainWindow)
+			return m.startRename(item.id, item.title, false)
+		}},
+		{name: "Set Deadline", binding: &m.keys.SetDeadline, mutating: true, run: func() tea.Cmd {
+			if len(m.marked) > 0 {
+				m.openBulkDeadlinePrompt()
+			} else if !m.openDeadlinePrompt() {
+				m.showMessageWithType("Select a task first", "warning")
+			}
+			return nil
+		}},
+		{name: "Set Priority", binding: &m.keys.SetPriority, mutating: true, run: func() tea.Cmd {
+			if len(m.bulkTargets()) == 0 {
+				m.showMessageWithType("Select a task first", "warning")
+				return nil
+			}
+			m.openPriorityChooser()
+			return nil
+		}},
+		{name: "Duplicate Task", binding: &m.keys.Duplicate, mutating: true, run: func() tea.Cmd {
+			return m.duplicateSelectedTask()
+		}},
+		{name: "Group Tasks", binding: &m.keys.GroupTasks, mutating: true, run: func() tea.Cmd {
+			return m.cycleTaskGrouping()
+		}},
+		{name: "Paste Tasks from Clipboard", binding: &m.keys.PasteTasks, mutating: true, run: func() tea.Cmd {
+			return m.pasteTasks()
+		}},
+		{name: "Complete Marked Tasks", mutating: true, run: func() tea.Cmd {
+			if len(m.marked) == 0 {
+				m.showMessageWithType("Mark tasks first with "+m.keys.Mark.Help().Key, "warning")
+				return nil
+			}
+			return m.completeMarked()
+		}},
+		{name: "Complete All Tasks in List", mutating: true, run: func() tea.Cmd {
+			return m.completeList()
+		}},
+		{name: "Clear Marks", run: func() tea.Cmd {
+			m.clearMarks()
+			return nil
+		}},
+		{name: "Show Overdue Tasks", binding: &m.keys.Overdue, run: func() tea.Cmd {
+			m.openOverdueView()
+			return nil
+		}},
+		{name: "Star/Unstar Task", binding: &m.keys.Star, mutating: true, run: func() tea.Cmd {
+			if _, ok := m.tasksList.SelectedItem().(taskItem); !ok {
+				m.showMessageWithType("Select a task first", "warning")
+				return nil
+			}
+			return m.toggleStar()
+		}},
+		{name: "Show Starred Tasks", run: func() tea.Cmd {
+			m.openStarredView()
+			return nil
+		}},
+		{name: "Show Calendar", binding: &m.keys.Calendar, run: func() tea.Cmd {
+			m.openCalendarView()
+			return nil
+		}},
+		{name: "Do Not Disturb", binding: &m.keys.DoNotDisturb, run: func() tea.Cmd {
+			m.toggleDoNotDisturb()
+			return nil
+		}},
+		{name: "Snooze Task", binding: &m.keys.Snooze, mutating: true, run: func() tea.Cmd {
+			if !m.openSnoozeChooser() {
+				m.showMessageWithType("Select a task first", "warning")
+			}
+			return nil
+		}},
+		{name: "Dismiss Critical Overdue", binding: &m.keys.Dismiss, run: func() tea.Cmd {
+			m.dismissEscalations()
+			return nil
+		}},
+		{name: "Start/Stop Timer", binding: &m.keys.Timer, mutating: true, run: func() tea.Cmd {
+			item, ok := m.tasksList.SelectedItem().(taskItem)
+			if !ok {
+				m.showMessageWithType("Select a task first", "warning")
+				return nil
+			}
+			return m.toggleTimer(item.id)
+		}},
+		{name: "Open Task Link", binding: &m.keys.OpenLink, run: func() tea.Cmd {
+			item, ok := m.tasksList.SelectedItem().(taskItem)
+			if !ok {
+				m.showMessageWithType("Select a task first", "warning")
+				return nil
+			}
+			return m.openTaskLink(m.getTask(item.id))
+		}},
+		{name: "Open URL in Task", binding: &m.keys.OpenURL, run: func() tea.Cmd {
+			item, ok := m.tasksList.SelectedItem().(taskItem)
+			if !ok {
+				m.showMessageWithType("Select a task first", "warning")
+				return nil
+			}
+			return m.openTaskURL(m.getTask(item.id))
+		}},
+		{name: "New List", binding: &m.keys.NewList, mutating: true, run: func() tea.Cmd {
+			m.resetForm()
+			m.state = CreateListView
+			return nil
+		}},
+		{name: "Pin/Unpin List", binding: &m.keys.Pin, mutating: true, run: func() tea.Cmd {
+			return m.togglePin()
+		}},
+		{name: "Toggle Show Completed", binding: &m.keys.HideDone, mutating: true, run: func() tea.Cmd {
+			return m.toggleShowCompleted()
+		}},
+		{name: "Shift List Deadlines", binding: &m.keys.Shift, mutating: true, run: func() tea.Cmd {
+			m.openShiftPrompt(m.currentListID)
+			return nil
+		}},
+		{name: "Save List as Template", binding: &m.keys.SaveTemplate, mutating: true, run: func() tea.Cmd {
+			m.openSaveTemplatePrompt(m.currentListID)
+			return nil
+		}},
+		{name: "Manage Templates", binding: &m.keys.Templates, run: func() tea.Cmd {
+			m.openTemplates()
+			return nil
+		}},
+		{name: "Switch List", binding: &m.keys.SwitchList, run: func() tea.Cmd {
+			m.openListSwitcher()
+			return nil
+		}},
+		{name: "Switch Profile", run: func() tea.Cmd {
+			m.openProfileSwitcher()
+			return nil
+		}},
+		{name: "Show Recent Activity", binding: &m.keys.Activity, run: func() tea.Cmd {
+			m.openActivityFeed()
+			return nil
+		}},
+		{name: "Show Activity Log", binding: &m.keys.ActivityLog, run: func() tea.Cmd {
+			m.openActivityLog()
+			return nil
+		}},
+		{name: "Open Trash", binding: &m.keys.Trash, run: func() tea.Cmd {
+			m.openTrashView()
+			return nil
+		}},
+		{name: "Show Log", binding: &m.keys.Log, run: func() tea.Cmd {
+			m.openLogView()
+			return nil
+		}},
+		{name: "Empty Trash", mutating: true, run: func() tea.Cmd {
+			return m.emptyTrash()
+		}},
+		{name: "Save Now", binding: &m.keys.SaveNow, mutating: true, run: func() tea.Cmd {
+			return m.saveNow()
+		}},
+		{name: "Undo", binding: &m.keys.Undo, mutating: true, run: func() tea.Cmd {
+			return m.undo()
+		}},
+		{name: "Redo", binding: &m.keys.Redo, mutating: true, run: func() tea.Cmd {
+			return m.redo()
+		}},
+		{name: "Git Sync", binding: &m.keys.Sync, mutating: true, run: func() tea.Cmd {
+			return m.startSync()
+		}},
+		{name: "Open Settings", binding: &m.keys.Settings, run: func() tea.Cmd {
+			m.previousState = m.state
+			m.state = SettingsView
+			m.layout.SetFocus(MainWindow)
+			return nil
+		}},
+		{name: "Toggle Focus Mode", binding: &m.keys.FocusMode, run: func() tea.Cmd {
+			m.toggleFocusMode()
+			return nil
+		}},
+		{name: "Resize Sidebar", binding: &m.keys.ResizeMode, run: func() tea.Cmd {
+			m.toggleResizeMode()
+			return nil
+		}},
+		{name: "Reset Sidebar Width", run: func() tea.Cmd {
+			return m.setSidebarWidth(0)
+		}},
+		{name: "Focus Main Window", binding: &m.keys.FocusMain, run: func() tea.Cmd {
+			m.layout.SetFocus(MainWindow)
+			return nil
+		}},
+		{name: "Focus Sidebar", binding: &m.keys.FocusSidebar, run: func() tea.Cmd {
+			m.layout.SetFocus(SidebarWindow)
+			return nil
+		}},
+		{name: "Show Help", binding: &m.keys.Help, run: func() tea.Cmd {
+			m.toggleHelp()
+			return nil
+		}},
+		{name: "Quit", binding: &m.keys.Quit, run: func() tea.Cmd {
+			return tea.Quit
+		}},
+	}
+}
+
+// paletteCommands returns every command currently available, including one
+// entry per todo list for switching to it
+func (m *Model) paletteCommands() []paletteCommand {
+	commands := make([]paletteCommand, 0, len(m.commands)+len(m.app.TodoLists))
+	commands = append(commands, m.commands...)
+
+	for _, todoList := range m.app.TodoLists {
+		listID := todoList.ID
+		commands = append(commands, paletteCommand{
+			name: fmt.Sprintf("Switch to list \"%s\"", todoList.Name),
+			run: func() tea.Cmd {
+				m.switchToList(listID)
+				return nil
+			},
+		})
+	}
+
+	return commands
+}
+
+// openCommandPalette shows the palette overlay with an empty query
+func (m *Model) openCommandPalette() {
+	if !m.paletteLists && !m.paletteProfiles {
+		m.paletteInput.Placeholder = "Type a command..."
+	}
+	m.paletteReturnState = m.state
+	m.paletteInput.SetValue("")
+	m.paletteInput.Focus()
+	m.paletteCursor = 0
+	m.filterPalette()
+	m.state = CommandPaletteView
+}
+
+// closeCommandPalette hides the palette and returns to the previous view
+func (m *Model) closeCommandPalette() {
+	m.paletteLists = false
+	m.paletteProfiles = false
+	m.paletteInput.Blur()
+	m.state = m.paletteReturnState
+}
+
+// filterPalette fuzzy-matches the query against all command names
+func (m *Model) filterPalette() {
+	commands := m.paletteCommands()
+	if m.paletteLists {
+		commands = m.listSwitchCommands()
+	}
+	if m.paletteProfiles {
+		commands = m.profileSwitchCommands()
+	}
+	query := m.paletteInput.Value()
+
+	if query == "" {
+		m.paletteMatches = commands
+	} else {
+		names := make([]string, len(commands))
+		for i, command := range commands {
+			names[i] = command.name
+		}
+
+		m.paletteMatches = nil
+		for _, match := range fuzzy.Find(query, names) {
+			m.paletteMatches = append(m.paletteMatches, commands[match.Index])
+		}
+	}
+
+	if m.paletteCursor >= len(m.paletteMatches) {
+		m.paletteCursor = 0
+	}
+}
+
+// updateCommandPalette handles input while the palette is open
+func (m *Model) updateCommandPalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back, m.keys.CommandPalette, m.keys.SwitchList):
+		m.closeCommandPalette()
+		return m, nil
+
+	case key.Matches(msg, m.keys.MenuUp):
+		if m.paletteCursor > 0 {
+			m.paletteCursor--
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.MenuDown):
+		if m.paletteCursor < len(m.paletteMatches)-1 {
+			m.paletteCursor++
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		if len(m.paletteMatches) == 0 {
+			return m, nil
+		}
+
+		command := m.paletteMatches[m.paletteCursor]
+		m.closeCommandPalette()
+
+		if m.readOnly && command.mutating {
+			m.showMessageWithType("Read-only mode: changes are disabled", "warning")
+			return m, nil
+		}
+		if command.mutating && m.changesHeld() {
+			return m, nil
+		}
+		return m, command.run()
+	}
+
+	var cmd tea.Cmd
+	m.paletteInput, cmd = m.paletteInput.Update(msg)
+	m.filterPalette()
+	return m, cmd
+}
+
+// renderCommandPaletteContent renders the palette query and matching commands
+func (m *Model) renderCommandPaletteContent() string {
+	title, empty, hint := "Command Palette", "No matching commands", "↑/↓: Select • Enter: Run • Esc: Close"
+	if m.paletteLists {
+		title, empty, hint = "Switch List", "No matching lists", "↑/↓: Select • Enter: Open • Esc: Close"
+	}
+	if m.paletteProfiles {
+		title, empty, hint = "Switch Profile", "No matching profiles", "↑/↓: Select • Enter: Switch • Esc: Close"
+	}
+	m.layout.SetWindowTitle(FormWindow, withIcon(icons.Palette, title))
+
+	var lines []string
+	lines = append(lines, FormFieldFocused.Render(m.paletteInput.View()))
+	lines = append(lines, "")
+
+	if len(m.paletteMatches) == 0 {
+		lines = append(lines, BaseSubtitleStyle.Render(empty))
+	}
+
+	// Keep the cursor inside the visible window of matches
+	start := 0
+	if m.paletteCursor >= paletteVisibleItems {
+		start = m.paletteCursor - paletteVisibleItems + 1
+	}
+	end := start + paletteVisibleItems
+	if end > len(m.paletteMatches) {
+		end = len(m.paletteMatches)
+	}
+
+	mutedStyle := lipgloss.NewStyle().Foreground(TextMuted)
+	for i := start; i < end; i++ {
+		command := m.paletteMatches[i]
+		name := command.name
+		if m.readOnly && command.mutating {
+			name = mutedStyle.Render(name + " (read-only)")
+		}
+		if command.binding != nil && command.binding.Enabled() {
+			padding := max(1, paletteNameWidth-lipgloss.Width(name))
+			name += strings.Repeat(" ", padding) + KeyStyle.Render(command.binding.Help().Key)
+		}
+
+		if i == m.paletteCursor {
+			lines = append(lines, ListItemSelected.Render(name))
+		} else {
+			lines = append(lines, ListItemNormal.Render(name))
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render(hint))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
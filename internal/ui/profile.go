@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// openProfileSwitcher shows the palette overlay with only the profiles to pick
+// from; typing a name no profile has offers to create it
+func (m *Model) openProfileSwitcher() {
+	m.paletteProfiles = true
+	m.paletteInput.Placeholder = "Type a profile name, or a new one..."
+	m.openCommandPalette()
+}
+
+// profileSwitchCommands returns one palette entry per profile, and one that
+// creates the profile named by the query when there is none by that name
+func (m *Model) profileSwitchCommands() []paletteCommand {
+	profiles := storage.Profiles()
+	commands := make([]paletteCommand, 0, len(profiles)+1)
+	for _, profile := range profiles {
+		name := profile
+		if profile == storage.Profile() {
+			name += " (current)"
+		}
+		commands = append(commands, paletteCommand{
+			name: name,
+			run: func() tea.Cmd {
+				return m.switchProfile(profile)
+			},
+		})
+	}
+
+	query := strings.TrimSpace(m.paletteInput.Value())
+	if storage.ValidateProfile(query) == nil && !slices.Contains(profiles, query) {
+		commands = append(commands, paletteCommand{
+			name: fmt.Sprintf("Create profile \"%s\"", query),
+			run: func() tea.Cmd {
+				return m.switchProfile(query)
+			},
+		})
+	}
+	return commands
+}
+
+// switchProfile saves and closes the data of the current profile and reopens
+// storage against the data directory of profile, reloading the application
+// data as at startup. The lock moves with it, so another session may use the
+// profile left behind.
+func (m *Model) switchProfile(profile string) tea.Cmd {
+	if profile == storage.Profile() {
+		m.showMessageWithType(fmt.Sprintf("Already using profile %s", profile), "info")
+		return nil
+	}
+	// Saves and syncs run against m.storage, which would be gone under them
+	if m.savesPending > 0 || m.syncing || m.maintaining {
+		m.showMessageWithType("Still saving - switch profiles again in a moment", "warning")
+		return nil
+	}
+
+	previous := storage.Profile()
+	if err := storage.SetProfile(profile); err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return nil
+	}
+	// The passphrase of an encrypted profile is only asked for at startup
+	if storage.EncryptionEnabled() {
+		storage.SetProfile(previous)
+		m.showMessageWithType(fmt.Sprintf("Profile %s is encrypted - start it with lazytodo --profile %s", profile, profile), "warning")
+		return nil
+	}
+
+	if err := m.closeStorage(); err != nil {
+		m.log.add(logError, fmt.Sprintf("Closing profile %s: %v", previous, err))
+	}
+	m.lock.Release()
+	m.lock = nil
+	m.resetProfileState()
+
+	m.log.add(logInfo, fmt.Sprintf("Switched from profile %s to %s", previous, profile))
+	return tea.Batch(m.spinner.Tick, m.loadData())
+}
+
+// resetProfileState forgets everything the model holds about the data of the
+// profile left behind, so loading the next one starts as a fresh session would
+func (m *Model) resetProfileState() {
+	m.storage = nil
+	m.app = nil
+	m.loading = true
+	m.loadingText = "Loading…"
+	m.dirty = false
+	m.syncExportSeq++ // A pending export of the previous profile is dropped
+
+	m.state = ListsView
+	m.focusMode = false
+	m.currentListID = ""
+	m.tasksListID = ""
+	m.detailTaskID = ""
+	m.editingTaskID = ""
+	m.overdue, m.overdueCursor = nil, 0
+	m.starred, m.starredCursor = nil, 0
+	m.starsClearedDay = ""
+	m.calendarDue = nil
+	m.trash, m.trashCursor = nil, 0
+	m.activity, m.activityLog = nil, nil
+	m.criticalOverdue = nil
+	m.undoHistory, m.redoHistory = nil, nil
+	m.taskDraft = nil
+	m.timerTaskID, m.timerListID = "", ""
+
+	m.marked = make(map[string]bool)
+	m.lingering = make(map[string]time.Time)
+	m.collapsedGroups = make(map[string]bool)
+	m.reminded = make(map[string]reminderState)
+	m.escalations = make(map[string]escalation)
+	m.remindersSince = m.now()
+
+	// The list and task given on the command line belong to the first profile
+	m.opts.OpenListID, m.opts.OpenTaskID = "", ""
+
+	m.todoListsList.SetItems(nil)
+	m.tasksList.SetItems(nil)
+	m.message = ""
+}
+
+// profileBadge names the profile in use for the status bar, or is empty for
+// the default one
+func profileBadge() string {
+	if profile := storage.Profile(); profile != storage.DefaultProfile {
+		return withIcon(icons.Lists, "profile: "+profile)
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// progressStep is how far one press of +/- moves a task's progress, in percent
+const progressStep = 10
+
+// progressBarWidth is the width of the progress bar in a task's row
+const progressBarWidth = 10
+
+// nudgeProgress moves the progress of the selected task by step percent.
+// Reaching 100% completes the task when the complete_at_full_progress
+// setting is on.
+func (m *Model) nudgeProgress(step int) tea.Cmd {
+	item, ok := m.tasksList.SelectedItem().(taskItem)
+	if !ok {
+		return nil
+	}
+	task := m.getTask(item.id)
+	if task == nil {
+		return nil
+	}
+
+	progress := models.ClampProgress(task.Progress + step)
+	if progress == task.Progress {
+		return nil
+	}
+	updated, err := m.storage.SetTaskProgress(m.app, m.currentListID, task.ID, progress)
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return nil
+	}
+	m.putTask(m.currentListID, updated)
+
+	if updated.Progress == 100 && !updated.Completed && m.app.Settings.CompleteAtFullProgress {
+		return m.toggleTask(updated.ID)
+	}
+	m.updateTasksList()
+	m.showMessageWithType(fmt.Sprintf("Progress of '%s': %d%%", updated.Title, updated.Progress), "info")
+	return m.saveData()
+}
+
+// progressBadge shows how far a task is done as a small bar and the percentage
+func progressBadge(progress int) string {
+	return RenderProgressBar(progress, 100, progressBarWidth) + fmt.Sprintf(" %d%%", progress)
+}
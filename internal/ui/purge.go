@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// purgeCompletedChoices are the ages of completed tasks the settings view
+// cycles through for moving them to the trash; 0 turns it off
+var purgeCompletedChoices = []int{0, 30, 60, 90, 180, 365}
+
+// nextPurgeCompletedDays returns the purge age step places away from days; an
+// age set outside the choices starts from Off
+func nextPurgeCompletedDays(days, step int) int {
+	for i, choice := range purgeCompletedChoices {
+		if choice == days {
+			return purgeCompletedChoices[(i+step+len(purgeCompletedChoices))%len(purgeCompletedChoices)]
+		}
+	}
+	return purgeCompletedChoices[0]
+}
+
+// purgeCompletedLabel describes the purge age for the settings view
+func purgeCompletedLabel(days int) string {
+	if days <= 0 {
+		return "Off"
+	}
+	return fmt.Sprintf("completed more than %d days ago", days)
+}
+
+// purgeCompleted moves the tasks completed longer ago than the
+// purge_completed_days setting to the trash, where they stay for trash_days
+// like any deleted task, and drops them from app. It runs while loading, so
+// it only touches store and app.
+func purgeCompleted(store storage.StorageInterface, app *models.Application) ([]models.ListTask, error) {
+	days := app.Settings.PurgeCompletedDays
+	if days <= 0 || store.IsReadOnly() {
+		return nil, nil
+	}
+
+	trashed, err := store.TrashCompleted(app, time.Now().AddDate(0, 0, -days))
+	for _, entry := range trashed {
+		for i := range app.TodoLists {
+			if app.TodoLists[i].ID == entry.ListID {
+				app.TodoLists[i].DropTask(entry.Task)
+			}
+		}
+	}
+	return trashed, err
+}
+
+// reportPurgedCompleted logs each task the startup purge moved to the trash
+// and says how many there were, so no history goes unnoticed
+func (m *Model) reportPurgedCompleted(trashed []models.ListTask) {
+	if len(trashed) == 0 {
+		return
+	}
+
+	days := m.app.Settings.PurgeCompletedDays
+	for _, entry := range trashed {
+		m.log.add(logInfo, fmt.Sprintf("Moved %q from %s to the trash: completed more than %d days ago",
+			plainLine(entry.Task.Title), plainLine(entry.ListName), days))
+	}
+	tasks := "tasks"
+	if len(trashed) == 1 {
+		tasks = "task"
+	}
+	m.showMessageWithType(fmt.Sprintf("Moved %d completed %s older than %d days to the trash (%s to restore, %s for the log)",
+		len(trashed), tasks, days, m.keys.Trash.Help().Key, m.keys.Log.Help().Key), "info")
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// Choices of the damaged-database prompt, in the order they are listed
+const (
+	recoverStartFresh = iota
+	recoverQuit
+	recoverChoices
+)
+
+// recoveryChoiceLabels names the damaged-database prompt's choices
+var recoveryChoiceLabels = [recoverChoices]string{"Back up the damaged file and start fresh", "Quit"}
+
+// databaseCorruptMsg reports that the database could not be opened because
+// the file is damaged, so loading waits for the user to say what to do
+type databaseCorruptMsg struct{ err error }
+
+// Damaged-database prompt - asks whether to set the database aside and start fresh
+func (m *Model) updateRecoveryPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit, m.keys.Back):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Up, m.keys.ShiftTab):
+		m.recoveryCursor = (m.recoveryCursor + recoverChoices - 1) % recoverChoices
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down, m.keys.Tab):
+		m.recoveryCursor = (m.recoveryCursor + 1) % recoverChoices
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		return m, m.chooseRecovery(m.recoveryCursor)
+	}
+
+	return m, nil
+}
+
+// chooseRecovery acts on the damaged-database prompt's answer
+func (m *Model) chooseRecovery(choice int) tea.Cmd {
+	if choice != recoverStartFresh {
+		return tea.Quit
+	}
+
+	m.corruptErr = nil
+	asidePath, err := storage.SetAsideDatabase()
+	if err != nil {
+		m.loading = false
+		m.loadErr = err
+		return nil
+	}
+	m.setAsidePath = asidePath
+	return m.loadData()
+}
+
+// renderRecoveryPrompt renders the full-screen question shown when the
+// database file is damaged
+func (m *Model) renderRecoveryPrompt() string {
+	// Leave out the "failed to create storage" every loading error starts with
+	var text strings.Builder
+	fmt.Fprintf(&text, "LazyTodo cannot open the database:\n%v\n\n", errors.Unwrap(m.corruptErr))
+	fmt.Fprintf(&text, "Starting fresh renames it to %s.corrupt.<date>, so nothing is deleted, and creates an empty database.", storage.DatabaseName)
+	if backups := storage.Backups(); len(backups) > 0 {
+		text.WriteString("\n\nThese backups can be merged into the new database with lazytodo --import FILE:")
+		for _, path := range backups {
+			text.WriteString("\n  " + path)
+		}
+	} else {
+		text.WriteString("\n\nA file saved with lazytodo --export can be merged into the new database with lazytodo --import FILE.")
+	}
+
+	lines := []string{
+		BaseTitleStyle.Render(withIcon(icons.Warning, "The database is damaged")),
+		"",
+		lipgloss.NewStyle().Width(m.width * 2 / 3).Render(text.String()),
+		"",
+	}
+	for i, label := range recoveryChoiceLabels {
+		if i == m.recoveryCursor {
+			lines = append(lines, ListItemSelected.Render("> "+label))
+		} else {
+			lines = append(lines, ListItemNormal.Render("  "+label))
+		}
+	}
+	lines = append(lines, "", DescStyle.Render("↑/↓: choose • Enter: confirm • Esc: quit"))
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,198 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// defaultReminderLead is the global reminder setting as a duration
+func (m *Model) defaultReminderLead() time.Duration {
+	return time.Duration(m.app.Settings.ReminderMinutes) * time.Minute
+}
+
+// reminderSummary describes when a task is reminded about, e.g. "1d before"
+// or "1h before (default)"
+func (m *Model) reminderSummary(task *models.Task) string {
+	summary := models.FormatReminderOffset(task.ReminderLead(m.defaultReminderLead())) + " before"
+	if task.ReminderOffset == nil {
+		summary += " (default)"
+	}
+	return summary
+}
+
+// openReminderForm opens the reminder prompt for the detail task
+func (m *Model) openReminderForm() {
+	task := m.getDetailTask()
+	if task == nil {
+		return
+	}
+
+	m.reminderOffsetInput.SetValue("")
+	if task.ReminderOffset != nil {
+		m.reminderOffsetInput.SetValue(models.FormatReminderOffset(*task.ReminderOffset))
+	}
+	m.reminderOffsetInput.CursorEnd()
+	m.reminderOffsetInput.Focus()
+	m.state = EditReminderView
+}
+
+// Reminder form - sets or clears the reminder offset of the detail task
+func (m *Model) updateReminderForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.reminderOffsetInput.Blur()
+		m.state = TaskDetailView
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		offset, err := models.ParseReminderOffset(m.reminderOffsetInput.Value())
+		if err != nil {
+			m.showMessageWithType("Invalid reminder: use e.g. 10m, 2h, 1h30m or 1d", "warning")
+			return m, nil
+		}
+
+		task := m.getDetailTask()
+		if task == nil {
+			m.state = TasksView
+			return m, nil
+		}
+
+		updated, err := m.storage.SetTaskReminder(m.app, m.currentListID, task.ID, offset)
+		if err != nil {
+			m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+			return m, nil
+		}
+		m.putTask(m.currentListID, updated)
+
+		// A new lead time may make the task due for a reminder again
+		delete(m.reminded, task.ID)
+
+		m.reminderOffsetInput.Blur()
+		m.updateTasksList()
+		m.state = TaskDetailView
+		if offset == nil {
+			m.showMessageWithType(fmt.Sprintf("Reminder uses the default (%d minutes before)", m.app.Settings.ReminderMinutes), "success")
+		} else {
+			m.showMessageWithType(fmt.Sprintf("Reminder set to %s before the deadline", models.FormatReminderOffset(*offset)), "success")
+		}
+		return m, m.saveData()
+	}
+
+	var cmd tea.Cmd
+	m.reminderOffsetInput, cmd = m.reminderOffsetInput.Update(msg)
+	return m, cmd
+}
+
+// renderReminderFormContent renders the reminder prompt
+func (m *Model) renderReminderFormContent() string {
+	m.layout.SetWindowTitle(FormWindow, withIcon(icons.DueSoon, "Task Reminder"))
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render("Task Reminder"))
+	lines = append(lines, "")
+	lines = append(lines, FormLabel.Render("Remind this long before the deadline (10m, 2h, 1d):"))
+	lines = append(lines, FormFieldFocused.Render(m.reminderOffsetInput.View()))
+	lines = append(lines, lipgloss.NewStyle().Foreground(TextMuted).Render(
+		fmt.Sprintf("Leave empty to use the default of %d minutes", m.app.Settings.ReminderMinutes)))
+	if task := m.getDetailTask(); task != nil && task.Deadline == nil {
+		lines = append(lines, lipgloss.NewStyle().Foreground(WarningColor).Render("This task has no deadline yet"))
+	}
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render("Enter: Save • Esc: Cancel"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// Stages of a task's reminders; each is given once per deadline
+const (
+	reminderNone    = iota
+	reminderWindow  // The deadline came within the reminder lead time
+	reminderOverdue // The deadline passed during the session
+)
+
+// reminderState is the last stage a task was reminded about, for the deadline it had then
+type reminderState struct {
+	deadline time.Time
+	stage    int
+}
+
+// dueReminder is a reminder that is due and has not been given yet
+type dueReminder struct {
+	task  models.Task
+	stage int
+}
+
+// text returns the reminder's message at now and the icon it is shown with
+func (r dueReminder) text(now time.Time) (string, string) {
+	if r.stage == reminderOverdue {
+		return fmt.Sprintf("Task '%s' is now overdue!", r.task.Title), icons.Overdue
+	}
+	return fmt.Sprintf("Task '%s' is due in %s!", r.task.Title, r.task.Deadline.Sub(models.WallClock(now)).Round(time.Minute)), icons.DueSoon
+}
+
+// pendingReminders returns the reminders due at now that were not given yet,
+// overdue tasks first. Only deadlines passing during the session count as
+// newly overdue; the ones that had passed already are in the overdue count.
+func (m *Model) pendingReminders(now time.Time) ([]dueReminder, error) {
+	// Query storage so lists that have not been opened yet are included
+	overdue, err := m.storage.OverdueTasks(m.app)
+	if err != nil {
+		return nil, err
+	}
+	var pending []dueReminder
+	since, wallNow := models.WallClock(m.remindersSince), models.WallClock(now)
+	for _, entry := range overdue {
+		deadline := entry.Task.Deadline
+		if deadline.After(since) && !deadline.After(wallNow) && m.reminderStage(&entry.Task) < reminderOverdue {
+			pending = append(pending, dueReminder{task: entry.Task, stage: reminderOverdue})
+		}
+	}
+
+	// Tasks with a reminder offset of their own use it instead of the global setting
+	dueTasks, err := m.storage.DueTasks(m.app, now, m.defaultReminderLead())
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range dueTasks {
+		if m.reminderStage(&task) < reminderWindow {
+			pending = append(pending, dueReminder{task: task, stage: reminderWindow})
+		}
+	}
+	return pending, nil
+}
+
+// reminderStage returns how far the reminders of task got for its current deadline
+func (m *Model) reminderStage(task *models.Task) int {
+	state, ok := m.reminded[task.ID]
+	if !ok || task.Deadline == nil || !state.deadline.Equal(*task.Deadline) {
+		return reminderNone
+	}
+	return state.stage
+}
+
+// remindersHeld reports whether reminders wait at now, for do not disturb or quiet hours
+func (m *Model) remindersHeld(now time.Time) bool {
+	return m.dndActive(now) || m.app.Settings.InQuietHours(now)
+}
+
+// quietHoursChoices are the quiet hours the settings view cycles through;
+// others can be set in the database
+var quietHoursChoices = []string{"", "21:00-07:00", "22:00-07:00", "23:00-07:00", "22:00-08:00", "00:00-08:00"}
+
+// quietHoursLabel describes the quiet hours setting
+func quietHoursLabel(value string) string {
+	if value == "" {
+		return "Off"
+	}
+	q, err := models.ParseQuietHours(value)
+	if err != nil {
+		return value + " (invalid, ignored)"
+	}
+	return q.String()
+}
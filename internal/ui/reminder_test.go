@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// remindersGiven runs the minute's reminder check at the clock's time and
+// returns the stages it reminded task about
+func remindersGiven(t *testing.T, m *Model, taskID string) []int {
+	t.Helper()
+	pending, err := m.pendingReminders(m.now())
+	if err != nil {
+		t.Fatalf("pendingReminders: %v", err)
+	}
+	m.checkForDueReminders()
+
+	var stages []int
+	for _, reminder := range pending {
+		if reminder.task.ID == taskID {
+			stages = append(stages, reminder.stage)
+		}
+	}
+	return stages
+}
+
+func TestRemindersFollowDeadlineChanges(t *testing.T) {
+	// Overdue tasks are queried against the real time, so the clock starts there
+	clock := &testClock{now: time.Now()}
+	start := clock.now
+	m := newTestModel(t, clock)
+	m.app.Settings.ReminderMinutes = 60
+	// Only deadlines passing during the session are reminded about as overdue
+	m.remindersSince = start.Add(-10 * time.Minute)
+
+	deadline := models.WallClock(clock.now).Add(30 * time.Minute)
+	task := mustAddTask(t, m, "Write report", &deadline)
+
+	steps := []struct {
+		name     string
+		deadline *time.Time // Moved to before the check; nil leaves it
+		want     []int
+	}{
+		{"within the lead time", nil, []int{reminderWindow}},
+		{"a minute later", nil, nil},
+		{"another minute later", nil, nil},
+		{"deadline moved", timePtr(deadline.Add(15 * time.Minute)), []int{reminderWindow}},
+		{"moved deadline a minute later", nil, nil},
+		{"deadline moved into the past", timePtr(models.WallClock(start).Add(-time.Minute)), []int{reminderOverdue}},
+		{"overdue a minute later", nil, nil},
+	}
+	for _, step := range steps {
+		if step.deadline != nil {
+			updated, err := m.storage.UpdateTask(m.app, m.currentListID, task.ID, task.Title, "", task.Priority, step.deadline, "")
+			if err != nil {
+				t.Fatalf("%s: UpdateTask: %v", step.name, err)
+			}
+			m.putTask(m.currentListID, updated)
+		}
+
+		got := remindersGiven(t, m, task.ID)
+		if len(got) != len(step.want) || (len(got) > 0 && got[0] != step.want[0]) {
+			t.Errorf("%s: reminders = %v, want %v", step.name, got, step.want)
+		}
+		clock.advance(time.Minute)
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+// checkReminders runs the minute's reminder check at the clock's time and
+// returns the stage each task has been reminded about since
+func checkReminders(m *Model, tasks ...models.Task) []int {
+	m.checkForDueReminders()
+	stages := make([]int, len(tasks))
+	for i, task := range tasks {
+		stages[i] = m.reminderStage(&task)
+	}
+	return stages
+}
+
+func TestRemindersComeOnceOnTheClock(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	m := newTestModel(t, clock)
+	m.app.Settings.ReminderMinutes = 60
+
+	wall := models.WallClock(clock.now)
+	inWindow := mustAddTask(t, m, "Due in the window", timePtr(wall.Add(30*time.Minute)))
+	ownOffset := mustAddTask(t, m, "Own lead time", timePtr(wall.Add(40*time.Minute)))
+	offset := 10 * time.Minute
+	updated, err := m.storage.SetTaskReminder(m.app, m.currentListID, ownOffset.ID, &offset)
+	if err != nil {
+		t.Fatalf("SetTaskReminder: %v", err)
+	}
+	m.putTask(m.currentListID, updated)
+	later := mustAddTask(t, m, "Due later", timePtr(wall.Add(3*time.Hour)))
+
+	steps := []struct {
+		after time.Duration // Clock moved since the last check
+		want  []int
+	}{
+		{0, []int{reminderWindow, reminderNone, reminderNone}},
+		{time.Minute, []int{reminderWindow, reminderNone, reminderNone}},
+		{30 * time.Minute, []int{reminderWindow, reminderWindow, reminderNone}},
+		{time.Minute, []int{reminderWindow, reminderWindow, reminderNone}},
+		{2 * time.Hour, []int{reminderWindow, reminderWindow, reminderWindow}},
+	}
+	var before []int
+	for i, step := range steps {
+		clock.advance(step.after)
+		m.message = ""
+		got := checkReminders(m, inWindow, updated, later)
+		if !slices.Equal(got, step.want) {
+			t.Errorf("step %d: stages = %v, want %v", i, got, step.want)
+		}
+		// Each stage is given once, so a check that reaches no new stage is quiet
+		if slices.Equal(got, before) && m.message != "" {
+			t.Errorf("step %d: reminded again: %q", i, m.message)
+		}
+		before = got
+	}
+	if got := checkReminders(m, inWindow); got[0] != reminderWindow {
+		t.Errorf("a check within the minute changed the stage to %d", got[0])
+	}
+}
+
+func TestQuietHoursHoldReminders(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	m := newTestModel(t, clock)
+	m.app.Settings.ReminderMinutes = 4 * 60
+	// Quiet from an hour ago until an hour from now
+	m.app.Settings.QuietHours = clock.now.Add(-time.Hour).Format("15:04") + "-" + clock.now.Add(time.Hour).Format("15:04")
+	task := mustAddTask(t, m, "Write report", timePtr(models.WallClock(clock.now).Add(3*time.Hour)))
+
+	for range 3 {
+		if got := checkReminders(m, task); got[0] != reminderNone {
+			t.Fatalf("reminded during quiet hours %s at %s", m.app.Settings.QuietHours, clock.now.Format("15:04"))
+		}
+		clock.advance(10 * time.Minute)
+	}
+
+	// The first check after quiet hours gives what came due meanwhile, once
+	clock.advance(31 * time.Minute)
+	if got := checkReminders(m, task); got[0] != reminderWindow {
+		t.Fatalf("not reminded after quiet hours ended at %s", clock.now.Format("15:04"))
+	}
+	if !strings.Contains(m.message, task.Title) {
+		t.Errorf("message = %q, want the reminder about %q", m.message, task.Title)
+	}
+	m.message = ""
+	clock.advance(time.Minute)
+	checkReminders(m, task)
+	if strings.Contains(m.message, task.Title) {
+		t.Errorf("reminded again a minute later: %q", m.message)
+	}
+}
@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// inlineEdit is a title being renamed in place, in its row of the tasks list
+// or the sidebar. The model and the delegates drawing the rows share it.
+type inlineEdit struct {
+	id     string // The task or list being renamed; empty while none is
+	isList bool
+	input  textinput.Model
+}
+
+// newInlineEdit returns the inline edit, with nothing being renamed
+func newInlineEdit() *inlineEdit {
+	input := textinput.New()
+	input.Prompt = ""
+	input.CharLimit = maxTitleLength
+	return &inlineEdit{input: input}
+}
+
+// active reports whether a title is being renamed
+func (e *inlineEdit) active() bool {
+	return e.id != ""
+}
+
+// renaming reports whether the row of the task, or of the list when isList
+// is set, with ID id is the one being renamed
+func (e *inlineEdit) renaming(id string, isList bool) bool {
+	return e.id != "" && e.id == id && e.isList == isList
+}
+
+// render draws the row being renamed: the input in place of the title in the
+// delegate's selected style, and the row's description under it as usual
+func (e *inlineEdit) render(w io.Writer, m list.Model, styles list.DefaultItemStyles, desc string) {
+	titleStyle, descStyle := styles.SelectedTitle, styles.SelectedDesc
+	e.input.Width = max(1, m.Width()-titleStyle.GetHorizontalFrameSize()-1)
+	title := titleStyle.Render(e.input.View())
+	desc = descStyle.Render(ansi.Truncate(desc, m.Width()-descStyle.GetHorizontalFrameSize(), "…"))
+	fmt.Fprintf(w, "%s\n%s", title, desc)
+}
+
+// startRename turns the title of the task or list with ID id into an input
+// filled with it, where Enter saves the new title and Esc cancels
+func (m *Model) startRename(id, title string, isList bool) tea.Cmd {
+	m.inline.id = id
+	m.inline.isList = isList
+	m.inline.input.SetValue(title)
+	m.inline.input.CursorEnd()
+	return m.inline.input.Focus()
+}
+
+// cancelRename leaves the title being renamed as it was
+func (m *Model) cancelRename() {
+	m.inline.id = ""
+	m.inline.input.Blur()
+}
+
+// updateRename takes every key while a title is renamed, so that typed
+// letters reach the input rather than acting as commands
+func (m *Model) updateRename(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.cancelRename()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		title := m.inline.input.Value()
+		for _, check := range []validator{required("Title"), maxLength(maxTitleLength)} {
+			if problem := check(title); problem.message != "" {
+				m.showMessageWithType(problem.message, "warning")
+				return m, nil
+			}
+		}
+		if m.inline.isList {
+			return m, m.renameList(m.inline.id, title)
+		}
+		return m, m.renameTask(m.inline.id, title)
+	}
+
+	var cmd tea.Cmd
+	m.inline.input, cmd = m.inline.input.Update(msg)
+	return m, cmd
+}
+
+// renameTask saves the new title of a task of the current list, leaving the
+// rest of it as it is
+func (m *Model) renameTask(taskID, title string) tea.Cmd {
+	task := m.getTask(taskID)
+	if task == nil {
+		m.cancelRename()
+		return nil
+	}
+	if title == task.Title {
+		m.cancelRename()
+		return nil
+	}
+
+	before := *task
+	updated, err := m.storage.UpdateTask(m.app, m.currentListID, task.ID,
+		title, task.Description, task.Priority, task.Deadline, task.Label)
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return nil
+	}
+	m.recordEdit(m.currentListID, before, updated)
+	m.putTask(m.currentListID, updated)
+
+	m.cancelRename()
+	m.updateTasksList()
+	m.showMessageWithType("Task renamed", "success")
+	return m.saveData()
+}
+
+// renameList saves the new name of a todo list, leaving its description and
+// color as they are. A name refused as taken keeps the input open to pick
+// another.
+func (m *Model) renameList(listID, name string) tea.Cmd {
+	todoList := m.getList(listID)
+	if todoList == nil {
+		m.cancelRename()
+		return nil
+	}
+	if name == todoList.Name {
+		m.cancelRename()
+		return nil
+	}
+
+	if err := m.storage.UpdateTodoList(m.app, listID, name, todoList.Description, todoList.Color); err != nil {
+		m.showListError(err)
+		return nil
+	}
+
+	m.cancelRename()
+	m.updateTodoListsList()
+	m.updateTasksList()
+	if !m.warnDuplicateListName(listID, name) {
+		m.showMessageWithType("List renamed", "success")
+	}
+	return m.saveData()
+}
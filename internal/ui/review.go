@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// reviewHourChoices are the hours of the day the settings view offers for
+// the weekly review reminder
+var reviewHourChoices = []int{6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21}
+
+// nextReviewDay returns the weekly review day step places away from day,
+// cycling through Off and Monday to Sunday
+func nextReviewDay(day, step int) int {
+	return ((day+step)%8 + 8) % 8
+}
+
+// nextReviewHour returns the weekly review hour step places away from hour;
+// an hour set outside the choices starts from 9 o'clock
+func nextReviewHour(hour, step int) int {
+	for i, choice := range reviewHourChoices {
+		if choice == hour {
+			return reviewHourChoices[(i+step+len(reviewHourChoices))%len(reviewHourChoices)]
+		}
+	}
+	return 9
+}
+
+// reviewLabel describes the weekly review reminder for the settings view,
+// e.g. "Mondays at 09:00"
+func reviewLabel(day, hour int) string {
+	if day < 1 || day > 7 {
+		return "Off"
+	}
+	return fmt.Sprintf("%ss at %02d:00", time.Weekday(day%7), hour)
+}
+
+// reviewScheduleChanged starts the weekly review schedule over from now, so a
+// newly chosen time that has already passed this week does not fire at once
+func (m *Model) reviewScheduleChanged() {
+	m.app.Settings.LastReview = time.Now().Format(time.RFC3339)
+}
+
+// checkWeeklyReview nudges to review the lists once per weekly period, through
+// the status bar and, when they are on, a desktop notification. The time it
+// fired is kept in the settings so a restart does not repeat it. Do not
+// disturb and quiet hours hold it back until they end.
+func (m *Model) checkWeeklyReview() tea.Cmd {
+	now := m.now()
+	if !m.app.Settings.ReviewDue(now) || m.remindersHeld(now) {
+		return nil
+	}
+
+	m.app.Settings.LastReview = now.Format(time.RFC3339)
+	reminder := "Time for your weekly review: look over your lists and plan the week"
+	m.showMessage(withIcon(icons.Lists, reminder))
+
+	var cmds []tea.Cmd
+	if m.app.Settings.DesktopNotify {
+		cmds = append(cmds, desktopNotify("LazyTodo weekly review", reminder))
+	}
+	if !m.readOnly {
+		cmds = append(cmds, m.saveData())
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// savedMsg reports that save number seq finished, failing with err; manual
+// saves say so in the status bar when they succeed
+type savedMsg struct {
+	seq    int
+	manual bool
+	err    error
+}
+
+// save returns a command that saves the data, reporting a failure in the
+// status bar. The save is numbered so that only the latest one to finish
+// marks the data as saved, and counted until it finishes.
+func (m *Model) save(manual bool) tea.Cmd {
+	m.saves++
+	m.savesPending++
+	seq := m.saves
+	return func() tea.Msg {
+		return savedMsg{seq: seq, manual: manual, err: m.storage.Save(m.app)}
+	}
+}
+
+// saveNow saves the data at once, whether or not auto save is on, and
+// exports it for git sync like any other save
+func (m *Model) saveNow() tea.Cmd {
+	return tea.Batch(m.save(true), m.scheduleSyncExport())
+}
+
+// saveHeldChanges saves the changes auto save held back while maintenance or
+// a git sync ran
+func (m *Model) saveHeldChanges() tea.Cmd {
+	if m.dirty && m.app.Settings.AutoSave {
+		return tea.Batch(m.save(false), m.scheduleSyncExport())
+	}
+	return nil
+}
+
+// saved clears the unsaved changes indicator once the latest save succeeded
+func (m *Model) saved(msg savedMsg) {
+	m.savesPending--
+	if msg.err != nil {
+		m.showMessageWithType(fmt.Sprintf("Failed to save: %v", msg.err), "error")
+		return
+	}
+	if msg.seq == m.saves {
+		m.dirty = false
+	}
+	if msg.manual {
+		m.showMessageWithType("Saved", "success")
+	}
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// openStore opens an empty store of the named backend in a data directory of
+// its own: "json", "database" or "encrypted"
+func openStore(t *testing.T, backend string) storage.StorageInterface {
+	t.Helper()
+	storage.SetDataDir(t.TempDir())
+	t.Cleanup(func() { storage.SetDataDir("") })
+
+	opts := storage.Options{Output: io.Discard}
+	if backend == "json" {
+		store, err := storage.New(opts)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		return store
+	}
+	if backend == "encrypted" {
+		plain, err := storage.NewDatabase(opts)
+		if err != nil {
+			t.Fatalf("NewDatabase: %v", err)
+		}
+		plain.Close()
+		if _, err := storage.EncryptDatabase("hunter2"); err != nil {
+			t.Fatalf("EncryptDatabase: %v", err)
+		}
+		opts.Passphrase = "hunter2"
+	}
+	store, err := storage.NewDatabase(opts)
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+	return store
+}
+
+func TestUnsavedMarkerShowsOnlyForDeferredWrites(t *testing.T) {
+	for _, tt := range []struct {
+		backend string
+		unsaved bool
+	}{
+		{"json", true},
+		{"database", false},
+		{"encrypted", true},
+	} {
+		t.Run(tt.backend, func(t *testing.T) {
+			m := newTestModelOn(t, &testClock{now: time.Now()}, openStore(t, tt.backend))
+			m.app.Settings.AutoSave = false // Nothing clears the marker again
+
+			mustAddTask(t, m, "Write report", nil)
+			m.saveData()
+			if m.dirty != tt.unsaved {
+				t.Errorf("dirty = %v after a change, want %v", m.dirty, tt.unsaved)
+			}
+			if got := strings.Contains(m.renderStatusContent(), "unsaved"); got != tt.unsaved {
+				t.Errorf("status bar shows unsaved: %v, want %v\n%s", got, tt.unsaved, m.renderStatusContent())
+			}
+		})
+	}
+}
+
+func TestSettingsAreSavedOnCloseWithoutAutoSave(t *testing.T) {
+	store := openStore(t, "database")
+	m := newTestModelOn(t, &testClock{now: time.Now()}, store)
+	m.app.Settings.AutoSave = false
+	m.app.Settings.DueSoonHours = 3
+	m.saveData()
+	if err := m.closeStorage(); err != nil {
+		t.Fatalf("closing: %v", err)
+	}
+
+	reopened, err := storage.NewDatabase(storage.Options{Output: io.Discard})
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+	defer reopened.Close()
+	app, err := reopened.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if app.Settings.AutoSave || app.Settings.DueSoonHours != 3 {
+		t.Errorf("auto_save %v and due_soon_hours %d after reopening, want false and 3", app.Settings.AutoSave, app.Settings.DueSoonHours)
+	}
+}
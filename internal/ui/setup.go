@@ -0,0 +1,223 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Steps of the first-run setup wizard, in order
+const (
+	setupReminder = iota
+	setupNotify
+	setupIcons
+	setupFirstList
+	setupSteps
+)
+
+// needsSetup reports whether this is the first launch: there is no data yet
+// and the wizard has never been finished or skipped
+func (m *Model) needsSetup() bool {
+	return !m.readOnly && len(m.app.TodoLists) == 0 && !m.app.Settings.SetupComplete
+}
+
+// openSetupWizard starts the wizard with the current settings as answers
+func (m *Model) openSetupWizard() {
+	m.setupStep = setupReminder
+	m.setupSettings = m.app.Settings
+	m.reminderInput.SetValue(strconv.Itoa(m.app.Settings.ReminderMinutes))
+	m.reminderInput.Focus()
+	m.resetForm()
+	m.titleInput.Blur()
+	m.state = SetupView
+}
+
+// setSetupStep moves to a wizard step and focuses its input
+func (m *Model) setSetupStep(step int) {
+	m.setupStep = step
+	m.reminderInput.Blur()
+	m.titleInput.Blur()
+	switch step {
+	case setupReminder:
+		m.reminderInput.Focus()
+	case setupFirstList:
+		m.titleInput.Focus()
+	}
+}
+
+// Setup wizard - asks for the basic settings and an optional first list
+func (m *Model) updateSetupView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		return m, m.finishSetup(false)
+
+	case key.Matches(msg, m.keys.ShiftTab):
+		if m.setupStep > 0 {
+			m.setSetupStep(m.setupStep - 1)
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter, m.keys.Tab):
+		if m.setupStep == setupReminder {
+			minutes, err := strconv.Atoi(strings.TrimSpace(m.reminderInput.Value()))
+			if err != nil || minutes <= 0 {
+				m.showMessageWithType("Reminder minutes must be a positive number", "warning")
+				return m, nil
+			}
+			m.setupSettings.ReminderMinutes = minutes
+		}
+		if m.setupStep == setupFirstList {
+			return m, m.finishSetup(true)
+		}
+		m.setSetupStep(m.setupStep + 1)
+		return m, nil
+	}
+
+	switch m.setupStep {
+	case setupNotify:
+		if key.Matches(msg, m.keys.Left, m.keys.Right, m.keys.Toggle) {
+			m.setupSettings.DesktopNotify = !m.setupSettings.DesktopNotify
+		}
+		return m, nil
+
+	case setupIcons:
+		if key.Matches(msg, m.keys.Left, m.keys.Right) {
+			step := 1
+			if key.Matches(msg, m.keys.Left) {
+				step = -1
+			}
+			// Preview the icon set while choosing
+			m.setupSettings.Icons = cycleName(IconSetNames, m.setupSettings.Icons, step)
+			m.useIconSet(m.setupSettings.Icons)
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	if m.setupStep == setupReminder {
+		m.reminderInput, cmd = m.reminderInput.Update(msg)
+	} else {
+		m.titleInput, cmd = m.titleInput.Update(msg)
+	}
+	return m, cmd
+}
+
+// finishSetup closes the wizard. Completing it applies the answers and creates
+// the first list if one was named; skipping keeps the defaults. Either way the
+// wizard is not shown again.
+func (m *Model) finishSetup(complete bool) tea.Cmd {
+	m.reminderInput.Blur()
+	m.titleInput.Blur()
+
+	if complete {
+		m.app.Settings = m.setupSettings
+	}
+	m.app.Settings.SetupComplete = true
+	m.applyDisplaySettings()
+	m.state = ListsView
+
+	name := strings.TrimSpace(m.titleInput.Value())
+	if !complete || name == "" {
+		if complete {
+			m.showMessageWithType("Setup complete - press n in the sidebar to create a list", "success")
+		}
+		return m.saveData()
+	}
+
+	listID, err := m.storage.CreateTodoList(m.app, name, "", listColors[0].color.Dark)
+	if err != nil {
+		m.showListError(err)
+		return m.saveData()
+	}
+	m.updateTodoListsList()
+	m.switchToList(listID)
+	m.showMessageWithType(fmt.Sprintf("Setup complete - list \"%s\" created, press a to add a task", name), "success")
+	return m.saveData()
+}
+
+// renderSetupContent renders the current wizard step
+func (m *Model) renderSetupContent() string {
+	m.layout.SetWindowTitle(FormWindow, withIcon(icons.App, "Welcome to LazyTodo"))
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render("Welcome to LazyTodo!"))
+	lines = append(lines, BaseSubtitleStyle.Render(fmt.Sprintf("Quick setup • Step %d of %d", m.setupStep+1, setupSteps)))
+	lines = append(lines, "")
+
+	hint := "Enter: Next • Shift+Tab: Back • Esc: Skip"
+	switch m.setupStep {
+	case setupReminder:
+		lines = append(lines, FormLabel.Render("Remind me this many minutes before a deadline:"))
+		lines = append(lines, FormFieldFocused.Render(m.reminderInput.View()))
+
+	case setupNotify:
+		lines = append(lines, FormLabel.Render("Also show reminders as desktop notifications?"))
+		lines = append(lines, renderChoices([]string{"No", "Yes"}, boolIndex(m.setupSettings.DesktopNotify)))
+		lines = append(lines, DescStyle.Render("Via notify-send on Linux, Notification Center on macOS"))
+		hint = "←/→: Choose • " + hint
+
+	case setupIcons:
+		lines = append(lines, FormLabel.Render("Icon style:"))
+		lines = append(lines, renderChoices(IconSetNames, indexOf(IconSetNames, m.setupSettings.Icons)))
+		lines = append(lines, DescStyle.Render("Preview: "+strings.Join([]string{
+			withIcon(icons.Done, "done"), withIcon(icons.PriorityHigh, "high"), withIcon(icons.Deadline, "deadline"),
+		}, "  ")))
+		if m.opts.ASCII {
+			lines = append(lines, DescStyle.Render("This terminal uses ascii for now (--ascii or "+ASCIIEnv+")"))
+		} else {
+			lines = append(lines, DescStyle.Render("Use nerd with a Nerd Font, ascii if icons show as boxes"))
+		}
+		hint = "←/→: Choose • " + hint
+
+	case setupFirstList:
+		lines = append(lines, FormLabel.Render("Name your first list (leave empty to skip):"))
+		lines = append(lines, FormFieldFocused.Render(m.titleInput.View()))
+		hint = "Enter: Finish • Shift+Tab: Back • Esc: Skip"
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render(hint))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// renderChoices renders options side by side with the selected one highlighted
+func renderChoices(options []string, selected int) string {
+	parts := make([]string, len(options))
+	for i, option := range options {
+		if i == selected {
+			parts[i] = ListItemSelected.Render(option)
+		} else {
+			parts[i] = ListItemNormal.Render(option)
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, parts...)
+}
+
+func boolIndex(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return 0
+}
+
+// notifyLabel describes the desktop notification setting
+func notifyLabel(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// shiftDateLayout is a date without a time, which moves deadlines by whole days
+const shiftDateLayout = "2006-01-02"
+
+// earliestOpenDeadline returns the earliest deadline of the incomplete tasks
+// in a list and how many such tasks have one
+func earliestOpenDeadline(todoList *models.TodoList) (*time.Time, int) {
+	var earliest *time.Time
+	count := 0
+	for i := range todoList.Tasks {
+		task := &todoList.Tasks[i]
+		if task.Completed || task.Deadline == nil {
+			continue
+		}
+		count++
+		if earliest == nil || task.Deadline.Before(*earliest) {
+			earliest = task.Deadline
+		}
+	}
+	return earliest, count
+}
+
+// shiftDelta turns the shift prompt input into a duration. Besides offsets
+// such as "+3d" it takes an absolute date, which moves the earliest deadline
+// there and the others along with it.
+func shiftDelta(value string, earliest time.Time) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if delta, err := models.ParseOffset(value); err == nil {
+		return delta, nil
+	}
+
+	// A bare date keeps each deadline's time of day
+	if date, err := time.Parse(shiftDateLayout, value); err == nil {
+		from := time.Date(earliest.Year(), earliest.Month(), earliest.Day(), 0, 0, 0, 0, time.UTC)
+		return date.Sub(from), nil
+	}
+
+	target, err := models.ParseDeadline(value, dateLayout)
+	if err != nil || target == nil {
+		return 0, fmt.Errorf("invalid shift: %q", value)
+	}
+	return target.Sub(earliest), nil
+}
+
+// formatShift describes a shift such as "+3d" or "-1h30m"
+func formatShift(delta time.Duration) string {
+	sign := "+"
+	if delta < 0 {
+		sign, delta = "-", -delta
+	}
+	if delta%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%s%dd", sign, int(delta/(24*time.Hour)))
+	}
+	return sign + models.FormatDuration(delta)
+}
+
+// openShiftPrompt asks how far to move the deadlines of a list's open tasks
+func (m *Model) openShiftPrompt(listID string) {
+	if err := m.storage.LoadTasks(m.app, listID); err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return
+	}
+
+	todoList := m.getList(listID)
+	if todoList == nil {
+		m.showMessageWithType("Select a list first", "warning")
+		return
+	}
+	if _, count := earliestOpenDeadline(todoList); count == 0 {
+		m.showMessageWithType("List has no open tasks with deadlines", "warning")
+		return
+	}
+
+	m.shiftListID = listID
+	m.previousState = m.state
+	m.shiftInput.SetValue("")
+	m.shiftInput.Focus()
+	m.state = ShiftDeadlinesView
+}
+
+// Shift form - moves every open deadline of a list by the same amount
+func (m *Model) updateShiftForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	todoList := m.getList(m.shiftListID)
+	if todoList == nil || key.Matches(msg, m.keys.Back) {
+		m.shiftInput.Blur()
+		m.state = m.previousState
+		return m, nil
+	}
+
+	if key.Matches(msg, m.keys.Enter) {
+		earliest, _ := earliestOpenDeadline(todoList)
+		if earliest == nil {
+			m.state = m.previousState
+			return m, nil
+		}
+
+		delta, err := shiftDelta(m.shiftInput.Value(), *earliest)
+		if err != nil {
+			m.showMessageWithType(fmt.Sprintf("Invalid shift (e.g. +3d, -1w or %s)", time.Now().Format(shiftDateLayout)), "warning")
+			return m, nil
+		}
+		if delta == 0 {
+			m.showMessageWithType("Deadlines are already there", "warning")
+			return m, nil
+		}
+
+		shifted, err := m.storage.ShiftDeadlines(m.app, todoList.ID, delta)
+		if err != nil {
+			m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+			return m, nil
+		}
+
+		m.shiftInput.Blur()
+		m.updateTasksList()
+		m.state = m.previousState
+		m.showMessageWithType(fmt.Sprintf("Shifted %d deadline(s) in %s by %s", shifted, todoList.Name, formatShift(delta)), "success")
+		return m, m.saveData()
+	}
+
+	var cmd tea.Cmd
+	m.shiftInput, cmd = m.shiftInput.Update(msg)
+	return m, cmd
+}
+
+// renderShiftFormContent renders the shift prompt with a preview of the earliest deadline
+func (m *Model) renderShiftFormContent() string {
+	todoList := m.getList(m.shiftListID)
+	if todoList == nil {
+		return ""
+	}
+	m.layout.SetWindowTitle(FormWindow, withIcon(icons.Deadline, "Shift Deadlines"))
+
+	earliest, count := earliestOpenDeadline(todoList)
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render("Shift Deadlines"))
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render(fmt.Sprintf("%s • %d open task(s) with deadlines", todoList.Name, count)))
+	lines = append(lines, "")
+	lines = append(lines, FormLabel.Render("Offset (+3d, -1w, 2h) or date the earliest moves to:"))
+	lines = append(lines, FormFieldFocused.Render(m.shiftInput.View()))
+
+	if earliest != nil {
+		preview := "Earliest: " + formatDeadline(*earliest)
+		if delta, err := shiftDelta(m.shiftInput.Value(), *earliest); err == nil && delta != 0 {
+			preview += " → " + formatDeadline(earliest.Add(delta))
+		}
+		lines = append(lines, lipgloss.NewStyle().Foreground(TextMuted).Render(preview))
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render("Enter: Shift • Esc: Cancel"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
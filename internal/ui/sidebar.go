@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sidebarStep is how many columns one press of < or > moves the divider
+const sidebarStep = 2
+
+// resizeSidebar moves the divider between the sidebar and the main window
+// one step for the < and > keys, or Ctrl+← and Ctrl+→ in resize mode
+func (m *Model) resizeSidebar(msg tea.KeyMsg) tea.Cmd {
+	if m.focusMode {
+		m.showMessageWithType("The sidebar is hidden in focus mode", "info")
+		return nil
+	}
+
+	step := sidebarStep
+	if key.Matches(msg, m.keys.Narrower, m.keys.PrevWindow) {
+		step = -sidebarStep
+	}
+	return m.setSidebarWidth(m.layout.SidebarWidth() + step)
+}
+
+// toggleResizeMode starts or finishes resizing the sidebar from the keyboard
+func (m *Model) toggleResizeMode() {
+	if !m.resizing && m.focusMode {
+		m.showMessageWithType("The sidebar is hidden in focus mode", "info")
+		return
+	}
+	m.resizing = !m.resizing
+}
+
+// Resize mode - Ctrl+←/→ move the divider until Esc, Enter or Ctrl+W
+func (m *Model) updateResizeMode(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.keys.PrevWindow, m.keys.NextWindow):
+		return m.resizeSidebar(msg)
+	case key.Matches(msg, m.keys.Back, m.keys.Enter, m.keys.ResizeMode):
+		m.resizing = false
+	}
+	return nil
+}
+
+// onDivider reports whether a screen cell is on the border between the
+// sidebar and the main window, which is the sidebar's right edge and the
+// main window's left one
+func (m *Model) onDivider(x, y int) bool {
+	sidebar := m.layout.GetWindow(SidebarWindow)
+	if sidebar == nil || !sidebar.Visible || y >= sidebar.Position.Height {
+		return false
+	}
+	edge := sidebar.Position.X + sidebar.Position.Width
+	return x == edge-1 || x == edge
+}
+
+// updateDividerDrag resizes the sidebar while its border is dragged with the
+// left button, saving the width once the button is released. The layout
+// keeps both windows at their minimum widths however far it is dragged.
+func (m *Model) updateDividerDrag(msg tea.MouseMsg) tea.Cmd {
+	switch msg.Action {
+	case tea.MouseActionPress:
+		if msg.Button == tea.MouseButtonLeft && m.onDivider(msg.X, msg.Y) {
+			m.draggingDivider = true
+		}
+	case tea.MouseActionMotion:
+		if m.draggingDivider {
+			m.layout.SetSidebarWidth(msg.X + 1)
+			m.updateListDimensions()
+		}
+	case tea.MouseActionRelease:
+		if m.draggingDivider {
+			m.draggingDivider = false
+			return m.setSidebarWidth(m.layout.SidebarWidth())
+		}
+	}
+	return nil
+}
+
+// setSidebarWidth sizes the sidebar and keeps the width in the settings; 0
+// goes back to sizing it to the screen
+func (m *Model) setSidebarWidth(width int) tea.Cmd {
+	m.layout.SetSidebarWidth(width)
+	m.updateListDimensions()
+
+	// Keep the width the layout settled on, so a clamped one does not grow
+	// past the limit with each press
+	if width > 0 {
+		width = m.layout.SidebarWidth()
+		m.layout.SetSidebarWidth(width)
+	}
+	m.app.Settings.SidebarWidth = width
+
+	if m.readOnly {
+		m.showMessageWithType("Read-only mode: sidebar width applies to this session only", "warning")
+		return nil
+	}
+	if width == 0 {
+		m.showMessageWithType("Sidebar sized to the screen", "info")
+	} else {
+		m.showMessageWithType(fmt.Sprintf("Sidebar width: %d columns", width), "info")
+	}
+	return m.saveData()
+}
@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// snoozeCustom is the chooser entry after the presets that opens the deadline prompt
+var snoozeCustom = len(models.SnoozePresets)
+
+// openSnoozeChooser shows the snooze options for the selected task
+func (m *Model) openSnoozeChooser() bool {
+	selected := m.tasksList.SelectedItem()
+	if selected == nil {
+		return false
+	}
+	item, ok := selected.(taskItem)
+	if !ok {
+		return false
+	}
+	if item.completed {
+		m.showMessageWithType("Task is already completed", "warning")
+		return true
+	}
+
+	m.editingTaskID = item.id
+	m.snoozeCursor = 0
+	m.snoozeReturn = TasksView
+	m.state = SnoozeView
+	return true
+}
+
+// snoozeOverdueEntry opens the snooze chooser for the selected overdue task;
+// the overdue view comes back afterwards with the task gone from it
+func (m *Model) snoozeOverdueEntry() {
+	if m.overdueCursor >= len(m.overdue) {
+		return
+	}
+	entry := m.overdue[m.overdueCursor]
+
+	m.jumpToTask(entry.ListID, entry.Task.ID)
+	if m.openSnoozeChooser() {
+		m.snoozeReturn = OverdueView
+	}
+}
+
+// Snooze chooser - pushes the deadline back by a preset or opens a custom prompt
+func (m *Model) updateSnoozeView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	task := m.getTask(m.editingTaskID)
+	if task == nil || key.Matches(msg, m.keys.Back) {
+		m.state = m.snoozeReturn
+		return m, nil
+	}
+
+	choice := -1
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.snoozeCursor > 0 {
+			m.snoozeCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.snoozeCursor < snoozeCustom {
+			m.snoozeCursor++
+		}
+	case key.Matches(msg, m.keys.Enter):
+		choice = m.snoozeCursor
+	default:
+		// Number keys pick an option directly
+		if n, err := strconv.Atoi(msg.String()); err == nil && n >= 1 && n <= snoozeCustom+1 {
+			choice = n - 1
+		}
+	}
+
+	switch {
+	case choice < 0:
+		return m, nil
+	case choice == snoozeCustom:
+		m.openDeadlinePrompt()
+		m.snoozing = task.Deadline != nil
+		return m, nil
+	default:
+		return m, m.snoozeTask(task, models.SnoozePresets[choice].Apply(task.Deadline, time.Now()))
+	}
+}
+
+// snoozeTask moves the deadline of task and confirms the new due time. A task
+// without a deadline simply gets one; that is not counted as a snooze.
+func (m *Model) snoozeTask(task *models.Task, deadline time.Time) tea.Cmd {
+	var updated models.Task
+	var err error
+	verb := "Snoozed until"
+	if task.Deadline == nil {
+		verb = "Deadline set to"
+		updated, err = m.storage.UpdateTask(m.app, m.currentListID, task.ID,
+			task.Title, task.Description, task.Priority, &deadline, task.Label)
+	} else {
+		updated, err = m.storage.SnoozeTask(m.app, m.currentListID, task.ID, deadline)
+	}
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return nil
+	}
+	m.putTask(m.currentListID, updated)
+
+	// Remind again about the new deadline
+	delete(m.reminded, task.ID)
+
+	m.snoozing = false
+	m.updateTasksList()
+	m.state = TasksView
+	if m.snoozeReturn == OverdueView {
+		cursor := m.overdueCursor
+		m.openOverdueView()
+		m.overdueCursor = min(cursor, max(len(m.overdue)-1, 0))
+	}
+	m.showMessageWithType(fmt.Sprintf("%s %s (%s)", verb, formatDeadline(deadline), formatTimeUntil(deadline)), "success")
+	return m.saveData()
+}
+
+// renderSnoozeContent renders the snooze chooser with the deadline each option leads to
+func (m *Model) renderSnoozeContent() string {
+	task := m.getTask(m.editingTaskID)
+	if task == nil {
+		return ""
+	}
+
+	title := "Snooze"
+	if task.Deadline == nil {
+		title = "Set a Deadline"
+	}
+	m.layout.SetWindowTitle(FormWindow, withIcon(icons.DueSoon, title))
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render(title))
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render(task.Title))
+	if task.Deadline != nil {
+		due := "Due " + formatDeadline(*task.Deadline)
+		if task.SnoozeCount > 0 {
+			due += " • " + snoozeBadge(task.SnoozeCount)
+		}
+		lines = append(lines, GetDeadlineStyle(task.IsOverdue(), m.isDueSoon(task)).Render(due))
+	} else {
+		lines = append(lines, BaseSubtitleStyle.Render("No deadline yet - pick one relative to now"))
+	}
+	lines = append(lines, "")
+
+	now := time.Now()
+	mutedStyle := lipgloss.NewStyle().Foreground(TextMuted)
+	for i := 0; i <= snoozeCustom; i++ {
+		name, preview := "Custom…", ""
+		if i < snoozeCustom {
+			preset := models.SnoozePresets[i]
+			name = preset.String()
+			preview = formatDeadline(preset.Apply(task.Deadline, now))
+		}
+
+		line := fmt.Sprintf("%d  %-14s", i+1, name) + mutedStyle.Render(preview)
+		if i == m.snoozeCursor {
+			lines = append(lines, ListItemSelected.Render(line))
+		} else {
+			lines = append(lines, ListItemNormal.Render(line))
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render(fmt.Sprintf("1-%d/Enter: Choose • Esc: Cancel", snoozeCustom+1)))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// staleDayChoices are the stale task thresholds the settings view cycles
+// through; -1 turns the indicator off
+var staleDayChoices = []int{-1, 7, 14, 21, 30, 60, 90}
+
+// nextStaleDays returns the stale threshold step places away from days; a
+// threshold set outside the choices starts from the default
+func nextStaleDays(days, step int) int {
+	for i, choice := range staleDayChoices {
+		if choice == days {
+			return staleDayChoices[(i+step+len(staleDayChoices))%len(staleDayChoices)]
+		}
+	}
+	return models.DefaultSettings().StaleDays
+}
+
+// staleLabel describes the stale task threshold for the settings view
+func staleLabel(days int) string {
+	if days <= 0 {
+		return "Off"
+	}
+	return fmt.Sprintf("open for more than %d days", days)
+}
+
+// staleBadge marks how long a stale task has sat open, e.g. "🕸 23d"
+func staleBadge(days int) string {
+	return withIcon(icons.Stale, fmt.Sprintf("%dd", days))
+}
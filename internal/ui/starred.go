@@ -0,0 +1,240 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// starredItem is the sidebar entry of the Starred view, shown above the lists
+// while any task is starred
+type starredItem struct {
+	open int
+	done int
+}
+
+func (i starredItem) FilterValue() string { return "" }
+func (i starredItem) itemID() string      { return "\x00starred" }
+func (i starredItem) Title() string       { return withIcon(icons.Starred, "Starred") }
+func (i starredItem) Description() string {
+	if i.done == 0 {
+		return fmt.Sprintf("%d open", i.open)
+	}
+	return fmt.Sprintf("%d open • %d done", i.open, i.done)
+}
+
+// starredSidebarItem returns the sidebar entry of the Starred view, or false
+// when no task is starred
+func (m *Model) starredSidebarItem() (list.Item, bool) {
+	starred, err := m.storage.StarredTasks(m.app)
+	if err != nil || len(starred) == 0 {
+		return nil, false
+	}
+
+	var item starredItem
+	for _, entry := range starred {
+		if entry.Task.Completed {
+			item.done++
+		} else {
+			item.open++
+		}
+	}
+	return item, true
+}
+
+// setStarred stars or unstars a task of any list and keeps the in-memory list
+// and the task list current; it reports whether the task was changed
+func (m *Model) setStarred(listID string, task models.Task, starred bool) (models.Task, bool) {
+	updated, err := m.storage.SetTaskStarred(m.app, listID, task.ID, starred)
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return task, false
+	}
+
+	m.putTask(listID, updated)
+	if listID == m.currentListID {
+		m.updateTasksList()
+	}
+	if updated.Starred {
+		m.showMessageWithType(withIcon(icons.Starred, fmt.Sprintf("Starred '%s'", updated.Title)), "success")
+	} else {
+		m.showMessageWithType(fmt.Sprintf("Unstarred '%s'", updated.Title), "info")
+	}
+	return updated, true
+}
+
+// starredTitle returns a task's title with the star in front when it is starred
+func starredTitle(task models.Task) string {
+	if task.Starred {
+		return icons.Starred + " " + plainLine(task.Title)
+	}
+	return plainLine(task.Title)
+}
+
+// toggleStar stars or unstars the selected task of the tasks view, which may
+// be one of the results of a filter
+func (m *Model) toggleStar() tea.Cmd {
+	item, ok := m.tasksList.SelectedItem().(taskItem)
+	if !ok {
+		return nil
+	}
+	task := m.getTask(item.id)
+	if task == nil {
+		return nil
+	}
+	if _, ok := m.setStarred(m.currentListID, *task, !task.Starred); !ok {
+		return nil
+	}
+	return m.saveData()
+}
+
+// toggleEntryStar stars or unstars a task listed across lists, as in the
+// overdue and calendar views, and updates the entry
+func (m *Model) toggleEntryStar(entry *models.ListTask) tea.Cmd {
+	updated, ok := m.setStarred(entry.ListID, entry.Task, !entry.Task.Starred)
+	if !ok {
+		return nil
+	}
+	entry.Task = updated
+	return m.saveData()
+}
+
+// openStarredView lists the starred tasks of all lists in the main window
+func (m *Model) openStarredView() {
+	starred, err := m.storage.StarredTasks(m.app)
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return
+	}
+
+	m.starred = starred
+	m.starredCursor = min(m.starredCursor, max(len(starred)-1, 0))
+	m.state = StarredView
+	m.layout.SetFocus(MainWindow)
+}
+
+// Starred view - browse the daily plan, jump to a task or unstar it
+func (m *Model) updateStarredView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = TasksView
+		return m, nil
+
+	case key.Matches(msg, m.keys.Up):
+		if m.starredCursor > 0 {
+			m.starredCursor--
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down):
+		if m.starredCursor < len(m.starred)-1 {
+			m.starredCursor++
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		if m.starredCursor < len(m.starred) {
+			entry := m.starred[m.starredCursor]
+			m.jumpToTask(entry.ListID, entry.Task.ID)
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Star):
+		if m.starredCursor < len(m.starred) {
+			cmd := m.toggleEntryStar(&m.starred[m.starredCursor])
+			m.openStarredView()
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// renderStarredContent renders the starred tasks, open ones first
+func (m *Model) renderStarredContent() string {
+	m.layout.SetWindowTitle(MainWindow, withIcon(icons.Starred, "Starred Tasks"))
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render(withIcon(icons.Starred, fmt.Sprintf("Starred Tasks (%d)", len(m.starred)))))
+	lines = append(lines, "")
+
+	if len(m.starred) == 0 {
+		lines = append(lines, BaseSubtitleStyle.Render(fmt.Sprintf("Nothing is starred: press %s on a task to add it to your plan", m.keys.Star.Help().Key)))
+	}
+
+	// Keep the cursor inside the rows that fit in the window
+	visible := 10
+	if mainWindow := m.layout.GetWindow(MainWindow); mainWindow != nil && mainWindow.Position.Height-10 > visible {
+		visible = mainWindow.Position.Height - 10
+	}
+	start := 0
+	if m.starredCursor >= visible {
+		start = m.starredCursor - visible + 1
+	}
+	end := min(start+visible, len(m.starred))
+
+	mutedStyle := lipgloss.NewStyle().Foreground(TextMuted)
+	dueSoon := m.app.Settings.DueSoonWindow()
+	for i := start; i < end; i++ {
+		entry := m.starred[i]
+		task := entry.Task
+		status := icons.Incomplete
+		if task.Completed {
+			status = icons.Complete
+		}
+		line := status + " " + plainLine(task.Title) + mutedStyle.Render(" · "+entry.ListName)
+		if task.Deadline != nil && !task.Completed {
+			line += " " + GetDeadlineStyle(task.IsOverdue(), task.IsDueSoonWithin(dueSoon)).Render(formatDeadline(*task.Deadline))
+		}
+		line = m.fitRow(line)
+
+		if i == m.starredCursor {
+			lines = append(lines, ListItemSelected.Render(line))
+		} else {
+			lines = append(lines, ListItemNormal.Render(line))
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render(fmt.Sprintf("↑/↓: select • Enter: jump to task • %s: unstar • Esc: back to tasks", m.keys.Star.Help().Key)))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// clearStarsDaily unstars the tasks completed before today, once a day on
+// the first check after midnight or at startup, when the clear_stars_daily
+// setting is on. It returns the save that keeps the change.
+func (m *Model) clearStarsDaily() tea.Cmd {
+	now := m.now()
+	day := now.Format(time.DateOnly)
+	if !m.app.Settings.ClearStarsDaily || m.readOnly || m.starsClearedDay == day {
+		return nil
+	}
+	m.starsClearedDay = day
+
+	startOfDay, _ := models.CalendarDay(now)
+	cleared, err := m.storage.ClearCompletedStars(m.app, startOfDay)
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error clearing stars: %v", err), "error")
+		return nil
+	}
+	if len(cleared) == 0 {
+		return nil
+	}
+
+	for _, entry := range cleared {
+		m.putTask(entry.ListID, entry.Task)
+	}
+	m.updateTasksList()
+	if m.state == StarredView {
+		m.openStarredView()
+	}
+	return m.saveData()
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// celebrationDuration is how long the list complete overlay stays up
+const celebrationDuration = 2 * time.Second
+
+// celebrationDoneMsg hides the celebration it was scheduled for; a newer one stays
+type celebrationDoneMsg struct {
+	seq int
+}
+
+// refreshStreak recomputes the completion streak and keeps it in the settings,
+// which are saved with the next change. When completions cannot be read the
+// saved streak is shown if it is still running.
+func (m *Model) refreshStreak() {
+	now := time.Now()
+	times, err := m.storage.CompletionTimes(m.app)
+	if err != nil {
+		m.streak = m.app.Settings.CurrentStreak(now)
+		return
+	}
+
+	streak, last := models.CompletionStreak(times, now)
+	m.streak = streak
+	m.app.Settings.Streak = streak
+	m.app.Settings.StreakDay = ""
+	if streak > 0 {
+		m.app.Settings.StreakDay = last.Format(models.StreakDayLayout)
+	}
+}
+
+// streakBadge describes the completion streak for the status bar, e.g. "🔥 6-day streak"
+func (m *Model) streakBadge() string {
+	if m.streak <= 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(WarningColor).Render(withIcon(icons.Streak, fmt.Sprintf("%d-day streak", m.streak)))
+}
+
+// celebrateIfListDone shows the celebration overlay when every task of a list
+// is completed, and returns the command that hides it again
+func (m *Model) celebrateIfListDone(listID string) tea.Cmd {
+	todoList := m.getList(listID)
+	if todoList == nil || todoList.GetTotalCount() == 0 || todoList.GetCompletedCount() < todoList.GetTotalCount() {
+		return nil
+	}
+
+	m.celebrationSeq++
+	seq := m.celebrationSeq
+	m.celebration = withIcon(icons.Celebrate, fmt.Sprintf("'%s' complete!", todoList.Name))
+	return tea.Tick(celebrationDuration, func(time.Time) tea.Msg {
+		return celebrationDoneMsg{seq: seq}
+	})
+}
+
+// renderCelebrationContent renders the list complete overlay
+func (m *Model) renderCelebrationContent() string {
+	m.layout.SetWindowTitle(FormWindow, withIcon(icons.Celebrate, "Well done"))
+
+	lines := []string{
+		"",
+		BaseTitleStyle.Render(m.celebration),
+		"",
+	}
+	if m.streak > 0 {
+		lines = append(lines, m.streakBadge(), "")
+	}
+	return lipgloss.JoinVertical(lipgloss.Center, lines...)
+}
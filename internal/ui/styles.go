@@ -1,33 +1,38 @@
 package ui
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
-// Color palette for the elegant theme
+// Color palette for the elegant theme. Each color has a shade for dark
+// terminal backgrounds and a darker one for light backgrounds, which keeps
+// text and status colors at a contrast of at least 4.5:1 against white;
+// see theme.go for how the background is told.
 var (
 	// Primary colors
-	PrimaryColor    = lipgloss.Color("#7C3AED") // Purple
-	AccentColor     = lipgloss.Color("#10B981") // Green
-	BackgroundColor = lipgloss.Color("#1F2937") // Dark gray
-	SurfaceColor    = lipgloss.Color("#374151") // Medium gray
+	PrimaryColor    = lipgloss.AdaptiveColor{Dark: "#7C3AED", Light: "#6D28D9"} // Purple
+	AccentColor     = lipgloss.AdaptiveColor{Dark: "#10B981", Light: "#047857"} // Green
+	BackgroundColor = lipgloss.AdaptiveColor{Dark: "#1F2937", Light: "#F9FAFB"} // Dark gray, near white on light terminals
+	SurfaceColor    = lipgloss.AdaptiveColor{Dark: "#374151", Light: "#E5E7EB"} // Medium gray
 
 	// Text colors
-	TextPrimary   = lipgloss.Color("#F9FAFB") // Light gray
-	TextSecondary = lipgloss.Color("#D1D5DB") // Medium light gray
-	TextMuted     = lipgloss.Color("#9CA3AF") // Muted gray
+	TextPrimary   = lipgloss.AdaptiveColor{Dark: "#F9FAFB", Light: "#111827"} // Light gray, near black on light terminals
+	TextSecondary = lipgloss.AdaptiveColor{Dark: "#D1D5DB", Light: "#374151"} // Medium light gray
+	TextMuted     = lipgloss.AdaptiveColor{Dark: "#9CA3AF", Light: "#6B7280"} // Muted gray
 
 	// Status colors
-	SuccessColor = lipgloss.Color("#10B981") // Green
-	WarningColor = lipgloss.Color("#F59E0B") // Orange
-	ErrorColor   = lipgloss.Color("#EF4444") // Red
-	InfoColor    = lipgloss.Color("#3B82F6") // Blue
+	SuccessColor = lipgloss.AdaptiveColor{Dark: "#10B981", Light: "#047857"} // Green
+	WarningColor = lipgloss.AdaptiveColor{Dark: "#F59E0B", Light: "#B45309"} // Orange
+	ErrorColor   = lipgloss.AdaptiveColor{Dark: "#EF4444", Light: "#B91C1C"} // Red
+	InfoColor    = lipgloss.AdaptiveColor{Dark: "#3B82F6", Light: "#1D4ED8"} // Blue
 
 	// Border colors
-	BorderPrimary   = lipgloss.Color("#7C3AED") // Purple
-	BorderSecondary = lipgloss.Color("#6B7280") // Gray
-	BorderFocused   = lipgloss.Color("#10B981") // Green
-	BorderUnfocused = lipgloss.Color("#4B5563") // Dark gray
+	BorderPrimary   = lipgloss.AdaptiveColor{Dark: "#7C3AED", Light: "#6D28D9"} // Purple
+	BorderSecondary = lipgloss.AdaptiveColor{Dark: "#6B7280", Light: "#9CA3AF"} // Gray
+	BorderFocused   = lipgloss.AdaptiveColor{Dark: "#10B981", Light: "#047857"} // Green
+	BorderUnfocused = lipgloss.AdaptiveColor{Dark: "#4B5563", Light: "#D1D5DB"} // Dark gray
 )
 
 // Border styles
@@ -238,6 +243,22 @@ var (
 			Foreground(InfoColor).
 			Bold(true)
 
+	ReadOnlyBadge = lipgloss.NewStyle().
+			Foreground(BackgroundColor).
+			Background(WarningColor).
+			Bold(true).
+			Padding(0, 1)
+
+	OverdueBadge = lipgloss.NewStyle().
+			Foreground(BackgroundColor).
+			Background(ErrorColor).
+			Bold(true).
+			Padding(0, 1)
+
+	UnsavedBadge = lipgloss.NewStyle().
+			Foreground(WarningColor).
+			Bold(true)
+
 	// Form element styles
 	FormFieldFocused = lipgloss.NewStyle().
 				Border(SubtleBorder).
@@ -253,6 +274,13 @@ var (
 			Foreground(TextSecondary).
 			Bold(true)
 
+	// Problems found with a form field, shown right under it
+	FormError = lipgloss.NewStyle().
+			Foreground(ErrorColor)
+
+	FormWarning = lipgloss.NewStyle().
+			Foreground(WarningColor)
+
 	// Button styles
 	ButtonPrimary = lipgloss.NewStyle().
 			Foreground(BackgroundColor).
@@ -277,13 +305,18 @@ var (
 	DescStyle = lipgloss.NewStyle().
 			Foreground(TextSecondary)
 
+	// URLs found in task titles and descriptions
+	URLStyle = lipgloss.NewStyle().
+			Foreground(InfoColor).
+			Underline(true)
+
 	SeparatorStyle = lipgloss.NewStyle().
 			Foreground(TextMuted)
 )
 
-// Priority styling
+// Priority styling, for a priority named in any case
 func GetPriorityStyle(priority string) lipgloss.Style {
-	switch priority {
+	switch strings.ToLower(priority) {
 	case "low":
 		return lipgloss.NewStyle().Foreground(TextMuted)
 	case "medium":
@@ -330,16 +363,16 @@ func StyleStatusMessage(message string, messageType string) string {
 
 	switch messageType {
 	case "success":
-		icon = "✓"
+		icon = icons.Success
 		style = StatusSuccess
 	case "warning":
-		icon = "⚠"
+		icon = icons.Warning
 		style = StatusWarning
 	case "error":
-		icon = "✗"
+		icon = icons.Failure
 		style = StatusError
 	case "info":
-		icon = "ℹ"
+		icon = icons.Info
 		style = StatusInfo
 	default:
 		icon = "•"
@@ -368,10 +401,25 @@ func CreateHelpSection(title string, bindings map[string]string) string {
 	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
+// Create a help section whose bindings are shown as unavailable
+func CreateMutedHelpSection(title string, bindings map[string]string) string {
+	var lines []string
+
+	mutedStyle := lipgloss.NewStyle().Foreground(TextMuted)
+	lines = append(lines, BaseTitleStyle.Copy().Foreground(TextMuted).Render(title))
+	lines = append(lines, "")
+
+	for key, desc := range bindings {
+		lines = append(lines, mutedStyle.Render(key+" • "+desc))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
 // Create a visual separator
 func CreateSeparator(width int, style string) string {
 	var char string
-	var color lipgloss.Color
+	var color lipgloss.TerminalColor
 
 	switch style {
 	case "thick":
@@ -395,13 +443,14 @@ func CreateSeparator(width int, style string) string {
 }
 
 // Enhanced list rendering with icons and styling
-func RenderEnhancedListItem(icon, title, subtitle string, selected, completed bool) string {
+// The icon is drawn in color unless color is empty or the item is completed.
+func RenderEnhancedListItem(icon, title, subtitle string, color lipgloss.Color, selected, completed bool) string {
 	var style lipgloss.Style
 	var itemIcon string
 
 	if completed {
 		style = ListItemCompleted
-		itemIcon = "✓"
+		itemIcon = icons.Complete
 	} else if selected {
 		style = ListItemSelected
 		itemIcon = icon
@@ -410,6 +459,10 @@ func RenderEnhancedListItem(icon, title, subtitle string, selected, completed bo
 		itemIcon = icon
 	}
 
+	if color != "" && !completed {
+		itemIcon = lipgloss.NewStyle().Foreground(color).Render(itemIcon)
+	}
+
 	// Build the item
 	content := itemIcon + " " + title
 	if subtitle != "" {
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openListSwitcher shows the palette overlay with only the todo lists to pick from
+func (m *Model) openListSwitcher() {
+	if len(m.app.TodoLists) == 0 {
+		m.showMessageWithType("No lists yet - press n in the sidebar to create one", "warning")
+		return
+	}
+
+	m.paletteLists = true
+	m.paletteInput.Placeholder = "Type a list name..."
+	m.openCommandPalette()
+}
+
+// listSwitchCommands returns one palette entry per todo list, named after the list
+func (m *Model) listSwitchCommands() []paletteCommand {
+	commands := make([]paletteCommand, 0, len(m.app.TodoLists))
+	for _, todoList := range m.app.TodoLists {
+		listID := todoList.ID
+		commands = append(commands, paletteCommand{
+			name: todoList.Name,
+			run: func() tea.Cmd {
+				m.switchToList(listID)
+				return nil
+			},
+		})
+	}
+	return commands
+}
+
+// openTaskByID selects the task with ID taskID in whichever list holds it,
+// loading lists as it goes, and warns when no list does
+func (m *Model) openTaskByID(taskID string) {
+	for _, todoList := range m.app.TodoLists {
+		if err := m.storage.LoadTasks(m.app, todoList.ID); err != nil {
+			m.log.add(logError, fmt.Sprintf("Failed to load the tasks of %s: %v", todoList.Name, err))
+			continue
+		}
+		for _, task := range m.getList(todoList.ID).Tasks {
+			if task.ID == taskID {
+				m.jumpToTask(todoList.ID, taskID)
+				return
+			}
+		}
+	}
+	m.showMessageWithType(fmt.Sprintf("No task with ID %s - starting in the first list", taskID), "warning")
+}
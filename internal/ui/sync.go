@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/DhirajZope/lazytodo/internal/gitsync"
+)
+
+// syncExportDelay is how long saves must pause before the sync export is rewritten
+const syncExportDelay = 2 * time.Second
+
+// syncExportMsg fires once saves have paused; only the latest sequence number writes
+type syncExportMsg struct {
+	seq int
+}
+
+// syncDoneMsg reports the outcome of a git sync run in the background
+type syncDoneMsg struct {
+	result gitsync.Result
+	err    error
+}
+
+// syncRepoLabel describes the sync repository setting for the settings view
+func syncRepoLabel(repo string) string {
+	if repo == "" {
+		return "off"
+	}
+	return repo
+}
+
+// scheduleSyncExport debounces rewriting the export after a save. It does
+// nothing unless a sync repository is configured.
+func (m *Model) scheduleSyncExport() tea.Cmd {
+	if m.readOnly || m.app.Settings.SyncRepo == "" {
+		return nil
+	}
+
+	m.syncExportSeq++
+	seq := m.syncExportSeq
+	return tea.Tick(syncExportDelay, func(time.Time) tea.Msg {
+		return syncExportMsg{seq: seq}
+	})
+}
+
+// writeSyncExport rewrites the export if no save happened since msg was scheduled
+func (m *Model) writeSyncExport(msg syncExportMsg) tea.Cmd {
+	// A running sync writes the export itself
+	if msg.seq != m.syncExportSeq || m.syncing {
+		return nil
+	}
+
+	store, repo := m.storage, m.app.Settings.SyncRepo
+	return func() tea.Msg {
+		if err := gitsync.WriteExport(store, repo); err != nil {
+			return errorMsg(fmt.Sprintf("Sync export failed: %v", err))
+		}
+		return nil
+	}
+}
+
+// startSync runs a git sync in the background. The sync loads and merges the
+// data itself, so changes are held back until it is done.
+func (m *Model) startSync() tea.Cmd {
+	if m.app.Settings.SyncRepo == "" {
+		m.showMessageWithType("Git sync is not configured - set sync_repo to a git repository", "warning")
+		return nil
+	}
+	if m.syncing {
+		m.showMessageWithType("Sync already in progress", "warning")
+		return nil
+	}
+
+	m.syncing = true
+	m.showMessage("Syncing with git...")
+
+	store, repo := m.storage, m.app.Settings.SyncRepo
+	return func() tea.Msg {
+		result, err := gitsync.Sync(store, repo)
+		return syncDoneMsg{result: result, err: err}
+	}
+}
+
+// finishSync reports a finished sync, reloads data merged in from the remote
+// and saves the changes held back meanwhile
+func (m *Model) finishSync(msg syncDoneMsg) tea.Cmd {
+	m.syncing = false
+	if msg.err != nil {
+		m.showMessageWithType(fmt.Sprintf("Sync failed: %v", msg.err), "error")
+		return m.saveHeldChanges()
+	}
+
+	m.showMessageWithType("Synced: "+msg.result.String(), "success")
+	if !msg.result.Merged.Changed() {
+		return m.saveHeldChanges()
+	}
+
+	fresh, err := m.storage.Load()
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Synced, but reloading failed: %v", err), "error")
+		return nil
+	}
+	m.app.TodoLists = fresh.TodoLists
+	m.app.Templates = fresh.Templates
+	m.updateTodoListsList()
+	m.updateTasksList()
+
+	// Saving also writes an encrypted database back to disk
+	return m.saveData()
+}
@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestChangesWaitForARunningSync(t *testing.T) {
+	m := newTestModel(t, &testClock{now: time.Now()})
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	task := mustAddTask(t, m, "Write report", nil)
+	m.switchToList(m.currentListID)
+
+	// The sync works on the data in the background, so toggling waits
+	m.syncing = true
+	press(m, keySpace)
+	if m.findTask(m.currentListID, task.ID).Completed {
+		t.Error("task completed while the sync ran")
+	}
+	if !strings.Contains(m.message, "sync is running") {
+		t.Errorf("message = %q, want one saying the sync is running", m.message)
+	}
+
+	m.finishSync(syncDoneMsg{})
+	press(m, keySpace)
+	if !m.findTask(m.currentListID, task.ID).Completed {
+		t.Error("task not completed once the sync was done")
+	}
+}
@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// taskGroupings are the groupings the group key cycles through, starting from none
+var taskGroupings = []string{"", models.GroupByPriority, models.GroupByDeadline}
+
+// completedSection is the heading completed tasks are shown under in a grouped list
+const completedSection = "Completed"
+
+// taskHeaderItem is a section heading of a grouped task list; the cursor never rests on it
+type taskHeaderItem struct {
+	title string
+	count int
+}
+
+func (i taskHeaderItem) FilterValue() string { return "" }
+func (i taskHeaderItem) itemID() string      { return "\x00section:" + i.title }
+func (i taskHeaderItem) Title() string       { return i.title }
+func (i taskHeaderItem) Description() string { return taskCountLabel(i.count) }
+
+// taskCountLabel describes a number of tasks, e.g. "1 task" or "3 tasks"
+func taskCountLabel(n int) string {
+	if n == 1 {
+		return "1 task"
+	}
+	return fmt.Sprintf("%d tasks", n)
+}
+
+// taskDelegate renders tasks like the default delegate, tasks just completed
+// that linger greyed out and struck through, and the section headings of a
+// grouped list in bold capitals
+type taskDelegate struct {
+	list.DefaultDelegate
+	inline *inlineEdit
+}
+
+func (d taskDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if task, ok := item.(taskItem); ok && d.inline.renaming(task.id, false) && m.Width() > 0 {
+		d.inline.render(w, m, d.Styles, task.Description())
+		return
+	}
+
+	if task, ok := item.(taskItem); ok && task.lingering {
+		lingering := d.DefaultDelegate
+		lingering.Styles.NormalTitle = lingering.Styles.NormalTitle.Foreground(TextMuted).Strikethrough(true)
+		lingering.Styles.SelectedTitle = lingering.Styles.SelectedTitle.Foreground(TextMuted).Strikethrough(true)
+		lingering.Styles.NormalDesc = lingering.Styles.NormalDesc.Foreground(TextMuted)
+		lingering.Styles.SelectedDesc = lingering.Styles.SelectedDesc.Foreground(TextMuted)
+		lingering.Render(w, m, index, item)
+		return
+	}
+
+	header, ok := item.(taskHeaderItem)
+	if !ok || m.Width() <= 0 {
+		d.DefaultDelegate.Render(w, m, index, item)
+		return
+	}
+
+	titleStyle, descStyle := d.Styles.NormalTitle, d.Styles.DimmedDesc
+	title := titleStyle.Bold(true).Render(ansi.Truncate(strings.ToUpper(header.Title()), m.Width()-titleStyle.GetHorizontalFrameSize(), "…"))
+	desc := descStyle.Render(ansi.Truncate(header.Description(), m.Width()-descStyle.GetHorizontalFrameSize(), "…"))
+
+	fmt.Fprintf(w, "%s\n%s", title, desc)
+}
+
+// groupingLabel names a task list grouping for the status bar
+func groupingLabel(grouping string) string {
+	switch grouping {
+	case models.GroupByPriority:
+		return "by priority"
+	case models.GroupByDeadline:
+		return "by deadline"
+	default:
+		return "off"
+	}
+}
+
+// prioritySections are the priorities of the sections of a list grouped by
+// priority, most pressing first
+var prioritySections = []models.Priority{models.Critical, models.High, models.Medium, models.Low}
+
+// prioritySectionTitle returns the heading of a priority's section, with the
+// icon its tasks carry
+func prioritySectionTitle(priority models.Priority) string {
+	switch priority {
+	case models.Critical:
+		return withIcon(icons.PriorityCritical, priority.String())
+	case models.High:
+		return withIcon(icons.PriorityHigh, priority.String())
+	case models.Medium:
+		return withIcon(icons.PriorityMedium, priority.String())
+	default:
+		return priority.String()
+	}
+}
+
+// deadlineSectionTitles are the section headings of a list grouped by
+// deadline, indexed by models.DeadlineBucket
+var deadlineSectionTitles = []string{
+	models.BucketOverdue:    "Overdue",
+	models.BucketToday:      "Today",
+	models.BucketThisWeek:   "This week",
+	models.BucketLater:      "Later",
+	models.BucketNoDeadline: "No deadline",
+}
+
+// groupTaskItems divides the task items into sections under headings,
+// keeping the order of the tasks within each section. Completed tasks go
+// into a last section of their own, and empty sections are left out.
+// Without a grouping the items are returned as they are.
+func groupTaskItems(grouping string, tasks []taskItem, now time.Time) []list.Item {
+	var titles []string
+	var section func(taskItem) int
+	switch grouping {
+	case models.GroupByPriority:
+		for _, priority := range prioritySections {
+			titles = append(titles, prioritySectionTitle(priority))
+		}
+		section = func(task taskItem) int {
+			for i, priority := range prioritySections {
+				if priority == task.priority {
+					return i
+				}
+			}
+			return len(prioritySections) - 1
+		}
+	case models.GroupByDeadline:
+		titles = append(titles, deadlineSectionTitles...)
+		section = func(task taskItem) int { return models.DeadlineBucket(task.deadline, now) }
+	default:
+		items := make([]list.Item, len(tasks))
+		for i, task := range tasks {
+			items[i] = task
+		}
+		return items
+	}
+
+	completed := len(titles)
+	titles = append(titles, completedSection)
+	sections := make([][]list.Item, len(titles))
+	for _, task := range tasks {
+		index := completed
+		// A lingering task stays in its section until it is hidden
+		if !task.completed || task.lingering {
+			index = section(task)
+		}
+		sections[index] = append(sections[index], task)
+	}
+
+	items := make([]list.Item, 0, len(tasks)+len(titles))
+	for i, sectionTasks := range sections {
+		if len(sectionTasks) == 0 {
+			continue
+		}
+		items = append(items, taskHeaderItem{title: titles[i], count: len(sectionTasks)})
+		items = append(items, sectionTasks...)
+	}
+	return items
+}
+
+// skipTaskHeader moves the tasks list cursor off a section heading onto the
+// nearest task in the direction of step, or the other way when there is none
+func (m *Model) skipTaskHeader(step int) {
+	items := m.tasksList.VisibleItems()
+	index := m.tasksList.Index()
+	if index < 0 || index >= len(items) {
+		return
+	}
+	if _, ok := items[index].(taskHeaderItem); !ok {
+		return
+	}
+
+	for _, direction := range []int{step, -step} {
+		for i := index + direction; i >= 0 && i < len(items); i += direction {
+			if _, ok := items[i].(taskHeaderItem); !ok {
+				m.tasksList.Select(i)
+				return
+			}
+		}
+	}
+}
+
+// cycleTaskGrouping switches the current list to the next grouping, which is
+// kept with the list
+func (m *Model) cycleTaskGrouping() tea.Cmd {
+	currentList := m.getCurrentList()
+	if currentList == nil {
+		return nil
+	}
+
+	next := taskGroupings[0]
+	for i, grouping := range taskGroupings {
+		if grouping == currentList.Grouping {
+			next = taskGroupings[(i+1)%len(taskGroupings)]
+			break
+		}
+	}
+
+	if err := m.storage.SetListGrouping(m.app, currentList.ID, next); err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return nil
+	}
+	m.updateTasksList()
+	m.showMessageWithType("Grouping "+groupingLabel(next), "info")
+	return m.saveData()
+}
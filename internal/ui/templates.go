@@ -0,0 +1,241 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// openSaveTemplatePrompt asks for a name to save the open tasks of a list as a template
+func (m *Model) openSaveTemplatePrompt(listID string) {
+	if err := m.storage.LoadTasks(m.app, listID); err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return
+	}
+
+	todoList := m.getList(listID)
+	if todoList == nil {
+		m.showMessageWithType("Select a list first", "warning")
+		return
+	}
+	if todoList.GetTotalCount() == todoList.GetCompletedCount() {
+		m.showMessageWithType("List has no open tasks to save", "warning")
+		return
+	}
+
+	m.templateListID = listID
+	m.renamingTemplateID = ""
+	m.previousState = m.state
+	m.openTemplateNamePrompt(todoList.Name)
+}
+
+// openTemplates shows the template management overlay
+func (m *Model) openTemplates() {
+	m.previousState = m.state
+	if m.templateCursor >= len(m.app.Templates) {
+		m.templateCursor = 0
+	}
+	m.state = TemplatesView
+}
+
+// openTemplateNamePrompt shows the template name input pre-filled with name
+func (m *Model) openTemplateNamePrompt(name string) {
+	m.titleInput.SetValue(name)
+	m.titleInput.CursorEnd()
+	m.titleInput.Focus()
+	m.descriptionInput.Blur()
+	m.deadlineInput.Blur()
+	m.state = TemplateNameView
+}
+
+// Template name prompt - saves a list as a new template or renames one
+func (m *Model) updateTemplateNameForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.titleInput.Blur()
+		if m.renamingTemplateID != "" {
+			m.state = TemplatesView
+		} else {
+			m.state = m.previousState
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		name := strings.TrimSpace(m.titleInput.Value())
+		if name == "" {
+			m.showMessageWithType("Name is required", "warning")
+			return m, nil
+		}
+
+		if m.renamingTemplateID != "" {
+			if err := m.storage.RenameTemplate(m.app, m.renamingTemplateID, name); err != nil {
+				m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+				return m, nil
+			}
+			m.titleInput.Blur()
+			m.state = TemplatesView
+			m.showMessageWithType("Template renamed", "success")
+			return m, m.saveData()
+		}
+
+		todoList := m.getList(m.templateListID)
+		if todoList == nil {
+			m.titleInput.Blur()
+			m.state = m.previousState
+			return m, nil
+		}
+
+		now := time.Now()
+		var tasks []models.TemplateTask
+		for _, task := range todoList.Tasks {
+			if !task.Completed {
+				tasks = append(tasks, models.NewTemplateTask(task, now))
+			}
+		}
+
+		if _, err := m.storage.CreateTemplate(m.app, name, tasks); err != nil {
+			m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+			return m, nil
+		}
+
+		m.titleInput.Blur()
+		m.state = m.previousState
+		m.showMessageWithType(fmt.Sprintf("Saved template \"%s\" with %d tasks", name, len(tasks)), "success")
+		return m, m.saveData()
+	}
+
+	var cmd tea.Cmd
+	m.titleInput, cmd = m.titleInput.Update(msg)
+	return m, cmd
+}
+
+// Templates overlay - browse, rename, delete and use templates
+func (m *Model) updateTemplatesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, m.keys.Back) {
+		m.state = m.previousState
+		return m, nil
+	}
+
+	if len(m.app.Templates) == 0 {
+		return m, nil
+	}
+
+	if m.readOnly && key.Matches(msg, m.keys.Enter, m.keys.Edit, m.keys.Delete) {
+		m.showMessageWithType("Read-only mode: changes are disabled", "warning")
+		return m, nil
+	}
+
+	template := m.app.Templates[m.templateCursor]
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.templateCursor > 0 {
+			m.templateCursor--
+		}
+
+	case key.Matches(msg, m.keys.Down):
+		if m.templateCursor < len(m.app.Templates)-1 {
+			m.templateCursor++
+		}
+
+	case key.Matches(msg, m.keys.Enter):
+		// Open the list form with this template picked
+		m.resetForm()
+		m.titleInput.SetValue(template.Name)
+		m.templateIndex = m.templateCursor + 1
+		m.markFormPristine()
+		m.state = CreateListView
+		m.layout.SetFocus(SidebarWindow)
+
+	case key.Matches(msg, m.keys.Edit):
+		m.renamingTemplateID = template.ID
+		m.openTemplateNamePrompt(template.Name)
+
+	case key.Matches(msg, m.keys.Delete):
+		if err := m.storage.DeleteTemplate(m.app, template.ID); err != nil {
+			m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+			return m, nil
+		}
+		if m.templateCursor > 0 && m.templateCursor >= len(m.app.Templates) {
+			m.templateCursor--
+		}
+		m.showMessageWithType("Template deleted", "success")
+		return m, m.saveData()
+	}
+
+	return m, nil
+}
+
+// renderTemplateNameFormContent renders the template name prompt
+func (m *Model) renderTemplateNameFormContent() string {
+	title := "Save as Template"
+	if m.renamingTemplateID != "" {
+		title = "Rename Template"
+		m.layout.SetWindowTitle(FormWindow, withIcon(icons.Edit, "Rename Template"))
+	} else {
+		m.layout.SetWindowTitle(FormWindow, withIcon(icons.Templates, "Save as Template"))
+	}
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render(title))
+	lines = append(lines, "")
+	if todoList := m.getList(m.templateListID); todoList != nil && m.renamingTemplateID == "" {
+		open := todoList.GetTotalCount() - todoList.GetCompletedCount()
+		lines = append(lines, DescStyle.Render(fmt.Sprintf("Saves the %d open tasks of \"%s\"", open, todoList.Name)))
+		lines = append(lines, "")
+	}
+	lines = append(lines, FormLabel.Render("Template name:"))
+	lines = append(lines, FormFieldFocused.Render(m.titleInput.View()))
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render("Enter: Save • Esc: Cancel"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// renderTemplatesContent renders the template list with a preview of the selected template
+func (m *Model) renderTemplatesContent() string {
+	m.layout.SetWindowTitle(FormWindow, withIcon(icons.Templates, "Templates"))
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render("Templates"))
+	lines = append(lines, "")
+
+	if len(m.app.Templates) == 0 {
+		lines = append(lines, BaseSubtitleStyle.Render("No templates yet"))
+		lines = append(lines, DescStyle.Render("Press Ctrl+T on a list to save its open tasks as a template"))
+		lines = append(lines, "")
+		lines = append(lines, DescStyle.Render("Esc: Close"))
+		return lipgloss.JoinVertical(lipgloss.Left, lines...)
+	}
+
+	mutedStyle := lipgloss.NewStyle().Foreground(TextMuted)
+	for i, template := range m.app.Templates {
+		line := template.Name + mutedStyle.Render(fmt.Sprintf(" (%d tasks)", len(template.Tasks)))
+		if i == m.templateCursor {
+			lines = append(lines, ListItemSelected.Render(line))
+		} else {
+			lines = append(lines, ListItemNormal.Render(line))
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, FormLabel.Render("Tasks:"))
+	for _, task := range m.app.Templates[m.templateCursor].Tasks {
+		line := "  • " + task.Title
+		if offset := task.FormatOffset(); offset != "" {
+			line += mutedStyle.Render(" (due " + offset + ")")
+		}
+		lines = append(lines, DescStyle.Render(line))
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render("Enter: New list from template • e: Rename • d: Delete • Esc: Close"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
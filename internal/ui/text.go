@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// plainLine cleans a stored title or description for showing on one line:
+// escape sequences and control characters are dropped, and newlines and
+// tabs become spaces, so a row of a list is never taller or wider than its
+// text looks
+func plainLine(text string) string {
+	return models.CleanText(text, false)
+}
+
+// plainText cleans stored text shown in full, keeping its newlines and tabs
+func plainText(text string) string {
+	return models.CleanText(text, true)
+}
+
+// mainTextWidth returns the columns the text of a row can take in the main
+// window, inside the window's border and padding and the row's own padding
+func (m *Model) mainTextWidth() int {
+	if mainWindow := m.layout.GetWindow(MainWindow); mainWindow != nil {
+		return max(20, mainWindow.Position.Width-6)
+	}
+	return 60
+}
+
+// fitRow cuts a row of a view listing tasks across lists to the main window
+// with an ellipsis, so each entry keeps to one line and the cursor to its row
+func (m *Model) fitRow(row string) string {
+	return ansi.Truncate(row, m.mainTextWidth(), "…")
+}
+
+// fitListTitle cuts a title to the title bar of l, leaving the gap the list
+// puts after it. The list cuts it as well, but without counting the bar's
+// padding, so a long title would wrap onto a second line.
+func fitListTitle(l list.Model, title string) string {
+	width := l.Width() - l.Styles.TitleBar.GetHorizontalFrameSize() - l.Styles.Title.GetHorizontalFrameSize() - 2
+	return ansi.Truncate(title, max(width, 10), "…")
+}
+
+// wrapText breaks text shown in full to the main window, long words included
+func (m *Model) wrapText(text string) string {
+	return ansi.Wrap(text, m.mainTextWidth(), "")
+}
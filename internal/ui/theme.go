@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// LightEnv forces the light palette when true, or the dark one when false,
+// instead of asking the terminal for its background
+const LightEnv = "LAZYTODO_LIGHT"
+
+// DetectLight reports whether the terminal has a light background, so the
+// light shades of the palette are drawn. Bubble Tea asks the terminal when
+// the program is loaded, before any key press is read, and a terminal that
+// does not answer counts as dark. LightEnv overrides the answer either way.
+func DetectLight() bool {
+	if value, ok := os.LookupEnv(LightEnv); ok {
+		light, err := strconv.ParseBool(value)
+		return err == nil && light
+	}
+	return !lipgloss.HasDarkBackground()
+}
+
+// usePalette picks the shades the adaptive colors of the palette draw
+func usePalette(light bool) {
+	lipgloss.SetHasDarkBackground(!light)
+}
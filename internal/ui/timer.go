@@ -0,0 +1,211 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// timeBadge describes time spent against the estimate, e.g. "⏱️ 45m/2h", or
+// the estimate alone, e.g. "⏱️ ~2h", before any time is spent
+func timeBadge(estimate, spent time.Duration) string {
+	switch {
+	case estimate > 0 && spent == 0:
+		return withIcon(icons.Timer, "~"+models.FormatDuration(estimate))
+	case estimate > 0:
+		return withIcon(icons.Timer, models.FormatDuration(spent)+"/"+models.FormatDuration(estimate))
+	case spent > 0:
+		return withIcon(icons.Timer, models.FormatDuration(spent))
+	default:
+		return ""
+	}
+}
+
+// findTask returns a task of any loaded list
+func (m *Model) findTask(listID, taskID string) *models.Task {
+	for i := range m.app.TodoLists {
+		list := &m.app.TodoLists[i]
+		if list.ID != listID {
+			continue
+		}
+		for j := range list.Tasks {
+			if list.Tasks[j].ID == taskID {
+				return &list.Tasks[j]
+			}
+		}
+	}
+	return nil
+}
+
+// timerRunning reports whether the timer is tracking the given task
+func (m *Model) timerRunning(taskID string) bool {
+	return m.timerTaskID != "" && m.timerTaskID == taskID
+}
+
+// toggleTimer starts the timer on a task of the current list, or stops it if
+// it is already running there. Starting on another task stops the old timer first.
+func (m *Model) toggleTimer(taskID string) tea.Cmd {
+	task := m.getTask(taskID)
+	if task == nil {
+		m.showMessageWithType("Select a task first", "warning")
+		return nil
+	}
+
+	if m.timerRunning(taskID) {
+		elapsed, err := m.stopTimer()
+		if err != nil {
+			m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+			return nil
+		}
+		m.updateTasksList()
+		m.showMessageWithType(fmt.Sprintf("Timer stopped: +%s (%s spent)",
+			models.FormatDuration(elapsed), models.FormatDuration(task.Spent)), "success")
+		return m.saveData()
+	}
+
+	var cmd tea.Cmd
+	if m.timerTaskID != "" {
+		if _, err := m.stopTimer(); err != nil {
+			m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+			return nil
+		}
+		cmd = m.saveData()
+	}
+
+	m.timerTaskID = task.ID
+	m.timerListID = m.currentListID
+	m.timerStart = time.Now()
+	m.updateTasksList()
+	m.showMessageWithType("Timer started for "+task.Title, "success")
+	return cmd
+}
+
+// stopTimer stops the running timer and adds the elapsed time to its task
+func (m *Model) stopTimer() (time.Duration, error) {
+	elapsed := time.Since(m.timerStart)
+	listID, taskID := m.timerListID, m.timerTaskID
+	m.timerTaskID, m.timerListID = "", ""
+
+	task := m.findTask(listID, taskID)
+	if task == nil {
+		return 0, fmt.Errorf("timed task no longer exists")
+	}
+	updated, err := m.storage.UpdateTaskTime(m.app, listID, taskID, task.Estimate, task.Spent+elapsed)
+	if err == nil {
+		m.putTask(listID, updated)
+	}
+	return elapsed, err
+}
+
+// openTimeForm opens the estimate and time spent form for the detail task
+func (m *Model) openTimeForm() {
+	task := m.getDetailTask()
+	if task == nil {
+		return
+	}
+
+	m.estimateInput.SetValue("")
+	if task.Estimate > 0 {
+		m.estimateInput.SetValue(models.FormatDuration(task.Estimate))
+	}
+	m.spentInput.SetValue("")
+	if task.Spent > 0 {
+		m.spentInput.SetValue(models.FormatDuration(task.Spent))
+	}
+
+	m.formFocusIndex = 0
+	m.estimateInput.Focus()
+	m.spentInput.Blur()
+	m.state = EditTimeView
+}
+
+// Time form - edits the estimate and time spent of the detail task
+func (m *Model) updateTimeForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.estimateInput.Blur()
+		m.spentInput.Blur()
+		m.state = TaskDetailView
+		return m, nil
+
+	case key.Matches(msg, m.keys.Tab, m.keys.ShiftTab):
+		m.formFocusIndex = 1 - m.formFocusIndex
+		if m.formFocusIndex == 0 {
+			m.estimateInput.Focus()
+			m.spentInput.Blur()
+		} else {
+			m.spentInput.Focus()
+			m.estimateInput.Blur()
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		estimate, err := models.ParseEffort(m.estimateInput.Value())
+		if err != nil {
+			m.showMessageWithType("Invalid estimate (e.g. 30m, 2h, 1h30m or 1d)", "warning")
+			return m, nil
+		}
+		spent, err := models.ParseEffort(m.spentInput.Value())
+		if err != nil {
+			m.showMessageWithType("Invalid time spent (e.g. 30m, 2h, 1h30m or 1d)", "warning")
+			return m, nil
+		}
+
+		task := m.getDetailTask()
+		if task == nil {
+			m.state = TasksView
+			return m, nil
+		}
+
+		updated, err := m.storage.UpdateTaskTime(m.app, m.currentListID, task.ID, estimate, spent)
+		if err != nil {
+			m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+			return m, nil
+		}
+		m.putTask(m.currentListID, updated)
+
+		m.estimateInput.Blur()
+		m.spentInput.Blur()
+		m.updateTasksList()
+		m.state = TaskDetailView
+		m.showMessageWithType("Time updated", "success")
+		return m, m.saveData()
+	}
+
+	var cmd tea.Cmd
+	if m.formFocusIndex == 0 {
+		m.estimateInput, cmd = m.estimateInput.Update(msg)
+	} else {
+		m.spentInput, cmd = m.spentInput.Update(msg)
+	}
+	return m, cmd
+}
+
+// renderTimeFormContent renders the estimate and time spent form
+func (m *Model) renderTimeFormContent() string {
+	m.layout.SetWindowTitle(FormWindow, withIcon(icons.Timer, "Time Tracking"))
+
+	fieldStyle := func(index int) lipgloss.Style {
+		if m.formFocusIndex == index {
+			return FormFieldFocused
+		}
+		return FormFieldUnfocused
+	}
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render("Time Tracking"))
+	lines = append(lines, "")
+	lines = append(lines, FormLabel.Render("Estimate (e.g. 30m, 2h, 1h30m, 1d of 8h):"))
+	lines = append(lines, fieldStyle(0).Render(m.estimateInput.View()))
+	lines = append(lines, FormLabel.Render("Time spent:"))
+	lines = append(lines, fieldStyle(1).Render(m.spentInput.View()))
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render("Tab: Next field • Enter: Save • Esc: Cancel"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
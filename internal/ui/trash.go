@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// trashDayChoices are the retention windows the settings view cycles through
+var trashDayChoices = []int{7, 14, 30, 60, 90, 365}
+
+// nextTrashDays returns the retention window step places away from days
+func nextTrashDays(days, step int) int {
+	for i, choice := range trashDayChoices {
+		if choice == days {
+			return trashDayChoices[(i+step+len(trashDayChoices))%len(trashDayChoices)]
+		}
+	}
+	return trashDayChoices[0]
+}
+
+// openTrashView lists the deleted tasks of all lists in the main window
+func (m *Model) openTrashView() {
+	trash, err := m.storage.TrashedTasks(m.app)
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return
+	}
+
+	m.trash = trash
+	m.trashCursor = 0
+	m.trashConfirm = false
+	m.state = TrashView
+	m.layout.SetFocus(MainWindow)
+}
+
+// refreshTrash reloads the trash entries and keeps the cursor on a row
+func (m *Model) refreshTrash() {
+	if trash, err := m.storage.TrashedTasks(m.app); err == nil {
+		m.trash = trash
+	}
+	m.trashCursor = min(m.trashCursor, max(len(m.trash)-1, 0))
+}
+
+// Trash view - restore deleted tasks or empty the trash
+func (m *Model) updateTrashView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Emptying the trash asks for the key a second time
+	confirming := m.trashConfirm
+	m.trashConfirm = false
+
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = TasksView
+		return m, nil
+
+	case key.Matches(msg, m.keys.Up):
+		if m.trashCursor > 0 {
+			m.trashCursor--
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down):
+		if m.trashCursor < len(m.trash)-1 {
+			m.trashCursor++
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		return m, m.restoreTrashEntry()
+
+	case key.Matches(msg, m.keys.EmptyTrash):
+		if len(m.trash) == 0 {
+			m.showMessageWithType("The trash is empty", "info")
+			return m, nil
+		}
+		if !confirming {
+			m.trashConfirm = true
+			m.showMessageWithType(fmt.Sprintf("Press %s again to delete %d task(s) permanently", m.keys.EmptyTrash.Help().Key, len(m.trash)), "warning")
+			return m, nil
+		}
+		return m, m.emptyTrash()
+	}
+
+	return m, nil
+}
+
+// restoreTrashEntry moves the selected trash entry back into its list
+func (m *Model) restoreTrashEntry() tea.Cmd {
+	if m.trashCursor >= len(m.trash) {
+		return nil
+	}
+
+	entry := m.trash[m.trashCursor]
+	task, err := m.storage.RestoreTask(m.app, entry.ListID, entry.Task.ID)
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return nil
+	}
+	m.putTask(entry.ListID, task)
+
+	m.refreshTrash()
+	if entry.ListID == m.currentListID {
+		m.updateTasksList()
+	}
+	m.showMessageWithType(fmt.Sprintf("Restored '%s' to %s", entry.Task.Title, entry.ListName), "success")
+	return m.saveData()
+}
+
+// emptyTrash permanently deletes every task in the trash
+func (m *Model) emptyTrash() tea.Cmd {
+	// Deletion times are stored to the second, so include the current one
+	purged, err := m.storage.PurgeTrash(m.app, time.Now().Add(time.Second))
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return nil
+	}
+
+	if m.state == TrashView {
+		m.refreshTrash()
+	}
+	if purged == 0 {
+		m.showMessageWithType("The trash is empty", "info")
+		return nil
+	}
+	m.showMessageWithType(fmt.Sprintf("Emptied the trash: %d task(s) deleted permanently", purged), "success")
+	return m.saveData()
+}
+
+// renderTrashContent renders the deleted tasks, most recently deleted first
+func (m *Model) renderTrashContent() string {
+	m.layout.SetWindowTitle(MainWindow, withIcon(icons.Trash, "Trash"))
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render(withIcon(icons.Trash, fmt.Sprintf("Trash (%d)", len(m.trash)))))
+	lines = append(lines, BaseSubtitleStyle.Render(fmt.Sprintf("Deleted tasks are kept for %d days", m.app.Settings.TrashDays)))
+	lines = append(lines, "")
+
+	if len(m.trash) == 0 {
+		lines = append(lines, BaseSubtitleStyle.Render("The trash is empty"))
+	}
+
+	// Keep the cursor inside the rows that fit in the window
+	visible := 10
+	if mainWindow := m.layout.GetWindow(MainWindow); mainWindow != nil && mainWindow.Position.Height-11 > visible {
+		visible = mainWindow.Position.Height - 11
+	}
+	start := 0
+	if m.trashCursor >= visible {
+		start = m.trashCursor - visible + 1
+	}
+	end := min(start+visible, len(m.trash))
+
+	mutedStyle := lipgloss.NewStyle().Foreground(TextMuted)
+	for i := start; i < end; i++ {
+		entry := m.trash[i]
+		deleted := ""
+		if entry.Task.DeletedAt != nil {
+			deleted = formatRelativeTime(*entry.Task.DeletedAt)
+		}
+		line := m.fitRow(mutedStyle.Render(fmt.Sprintf("%-10s", deleted)) + " " +
+			plainLine(entry.Task.Title) + mutedStyle.Render(" · "+entry.ListName))
+
+		if i == m.trashCursor {
+			lines = append(lines, ListItemSelected.Render(line))
+		} else {
+			lines = append(lines, ListItemNormal.Render(line))
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render(fmt.Sprintf("↑/↓: select • Enter: restore • %s: empty trash • Esc: back to tasks", m.keys.EmptyTrash.Help().Key)))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,198 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// undoLimit is how many changes the undo history keeps; older ones are dropped
+const undoLimit = 50
+
+// Kinds of change the undo history records
+const (
+	undoEdit = iota
+	undoToggle
+	undoDelete
+)
+
+// undoEntry is a change to one task that can be undone and redone: the task
+// as it was before the change and as the change left it. A deleted task is
+// put back after the task that preceded it, afterID.
+type undoEntry struct {
+	kind    int
+	listID  string
+	before  models.Task
+	after   models.Task
+	afterID string
+}
+
+// describe names the change for the status bar, e.g. "edit of 'Call Bob'"
+func (e undoEntry) describe() string {
+	var change string
+	switch e.kind {
+	case undoToggle:
+		change = "completing"
+		if e.before.Completed {
+			change = "reopening"
+		}
+	case undoDelete:
+		change = "deleting"
+	default:
+		change = "edit of"
+	}
+	return fmt.Sprintf("%s '%s'", change, e.after.Title)
+}
+
+// recordUndo adds a change to the undo history, dropping the oldest change
+// beyond undoLimit. A new change cannot be redone past, so the redo history
+// is cleared.
+func (m *Model) recordUndo(entry undoEntry) {
+	m.undoHistory = append(m.undoHistory, entry)
+	if len(m.undoHistory) > undoLimit {
+		m.undoHistory = m.undoHistory[len(m.undoHistory)-undoLimit:]
+	}
+	m.redoHistory = nil
+}
+
+// recordEdit adds an edit of a task to the undo history, unless nothing an
+// edit can change did change
+func (m *Model) recordEdit(listID string, before, after models.Task) {
+	if before.Title == after.Title && before.Description == after.Description && before.Priority == after.Priority &&
+		sameDeadline(before.Deadline, after.Deadline) && before.Label == after.Label {
+		return
+	}
+	m.recordUndo(undoEntry{kind: undoEdit, listID: listID, before: before, after: after})
+}
+
+// undo reverses the latest change in the undo history and moves it to the
+// redo history
+func (m *Model) undo() tea.Cmd {
+	if len(m.undoHistory) == 0 {
+		m.showMessageWithType("Nothing to undo", "info")
+		return nil
+	}
+	entry := m.undoHistory[len(m.undoHistory)-1]
+	m.undoHistory = m.undoHistory[:len(m.undoHistory)-1]
+
+	if err := m.replayChange(entry, false); err != nil {
+		m.showMessageWithType(fmt.Sprintf("Cannot undo %s: %v", entry.describe(), err), "error")
+		return nil
+	}
+	m.redoHistory = append(m.redoHistory, entry)
+	m.showMessageWithType("Undid "+entry.describe(), "success")
+	return m.saveData()
+}
+
+// redo makes the latest undone change again and moves it back to the undo history
+func (m *Model) redo() tea.Cmd {
+	if len(m.redoHistory) == 0 {
+		m.showMessageWithType("Nothing to redo", "info")
+		return nil
+	}
+	entry := m.redoHistory[len(m.redoHistory)-1]
+	m.redoHistory = m.redoHistory[:len(m.redoHistory)-1]
+
+	if err := m.replayChange(entry, true); err != nil {
+		m.showMessageWithType(fmt.Sprintf("Cannot redo %s: %v", entry.describe(), err), "error")
+		return nil
+	}
+	m.undoHistory = append(m.undoHistory, entry)
+	m.showMessageWithType("Redid "+entry.describe(), "success")
+	return m.saveData()
+}
+
+// replayChange brings the task of a change back to how the change left it
+// when forward is set, and otherwise to how it was before the change
+func (m *Model) replayChange(entry undoEntry, forward bool) error {
+	target := entry.before
+	if forward {
+		target = entry.after
+	}
+
+	switch entry.kind {
+	case undoDelete:
+		if forward {
+			if err := m.storage.DeleteTask(m.app, entry.listID, target.ID); err != nil {
+				return err
+			}
+			if todoList := m.getList(entry.listID); todoList != nil {
+				todoList.RemoveTask(target.ID)
+			}
+			break
+		}
+		task, err := m.storage.RestoreTask(m.app, entry.listID, target.ID)
+		if err != nil {
+			return err
+		}
+		if todoList := m.getList(entry.listID); todoList != nil {
+			todoList.PutTaskAfter(task, entry.afterID)
+		}
+
+	case undoToggle:
+		// The task may have been toggled since by a change the history does not
+		// keep, such as completing marked tasks
+		if current := m.findListTask(entry.listID, target.ID); current != nil && current.Completed == target.Completed {
+			break
+		}
+		task, err := m.storage.ToggleTask(m.app, entry.listID, target.ID)
+		if err != nil {
+			return err
+		}
+		m.putTask(entry.listID, task)
+		m.refreshStreak()
+		m.refreshHeatmap()
+
+	default:
+		task, err := m.storage.UpdateTask(m.app, entry.listID, target.ID,
+			target.Title, target.Description, target.Priority, target.Deadline, target.Label)
+		if err != nil {
+			return err
+		}
+		m.putTask(entry.listID, task)
+	}
+
+	m.updateTasksList()
+	m.refreshTodoListItems()
+	return nil
+}
+
+// taskSnapshot returns a copy of a task of the current list, to keep as it
+// is before a change; storage may change the task in place
+func (m *Model) taskSnapshot(taskID string) (models.Task, bool) {
+	if task := m.getTask(taskID); task != nil {
+		return *task, true
+	}
+	return models.Task{}, false
+}
+
+// precedingTaskID returns the ID of the task before the one with ID taskID in
+// the current list, or "" for the first
+func (m *Model) precedingTaskID(taskID string) string {
+	currentList := m.getCurrentList()
+	if currentList == nil {
+		return ""
+	}
+	for i := 1; i < len(currentList.Tasks); i++ {
+		if currentList.Tasks[i].ID == taskID {
+			return currentList.Tasks[i-1].ID
+		}
+	}
+	return ""
+}
+
+// findListTask returns a task of a loaded list by ID, or nil
+func (m *Model) findListTask(listID, taskID string) *models.Task {
+	todoList := m.getList(listID)
+	if todoList == nil {
+		return nil
+	}
+	for i := range todoList.Tasks {
+		if todoList.Tasks[i].ID == taskID {
+			return &todoList.Tasks[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// Longest values the forms accept, in characters
+const (
+	maxTitleLength       = models.MaxTitleLength
+	maxDescriptionLength = 2000
+	maxGroupLength       = 50
+)
+
+// fieldProblem is what a validator found wrong with a form field's value. An
+// error keeps the form from being saved; a warning only points something out.
+type fieldProblem struct {
+	message string
+	warning bool
+}
+
+// validator checks a form field's value and returns its problem, or a zero
+// fieldProblem when there is none
+type validator func(value string) fieldProblem
+
+// formField is a form field to validate: its position in the form's focus
+// order, its current value and the validators it must pass
+type formField struct {
+	index      int
+	value      string
+	validators []validator
+}
+
+// required rejects an empty or blank value
+func required(name string) validator {
+	return func(value string) fieldProblem {
+		if strings.TrimSpace(value) == "" {
+			return fieldProblem{message: name + " is required"}
+		}
+		return fieldProblem{}
+	}
+}
+
+// maxLength rejects a value longer than limit characters
+func maxLength(limit int) validator {
+	return func(value string) fieldProblem {
+		if length := utf8.RuneCountInString(value); length > limit {
+			return fieldProblem{message: fmt.Sprintf("At most %d characters (%d now)", limit, length)}
+		}
+		return fieldProblem{}
+	}
+}
+
+// deadlineFormat rejects a deadline that is neither empty nor in the date
+// format of the settings
+func deadlineFormat() validator {
+	return func(value string) fieldProblem {
+		if _, err := models.ParseDeadline(value, dateLayout); err != nil {
+			return fieldProblem{message: "Use a date like " + deadlineExample()}
+		}
+		return fieldProblem{}
+	}
+}
+
+// futureDeadline warns about a deadline that has already passed, which makes
+// the task overdue as soon as it is saved
+func futureDeadline() validator {
+	return func(value string) fieldProblem {
+		deadline, err := models.ParseDeadline(value, dateLayout)
+		if err != nil || deadline == nil || !time.Now().After(*deadline) {
+			return fieldProblem{}
+		}
+		return fieldProblem{message: "This deadline has passed, so the task will be overdue", warning: true}
+	}
+}
+
+// validateFields runs the validators of each field and returns the first
+// problem of each field that has one, by field index
+func validateFields(fields []formField) map[int]fieldProblem {
+	problems := make(map[int]fieldProblem)
+	for _, field := range fields {
+		for _, validate := range field.validators {
+			if problem := validate(field.value); problem.message != "" {
+				problems[field.index] = problem
+				break
+			}
+		}
+	}
+	return problems
+}
+
+// formProblems returns the problems to show under the fields of the open
+// form. Warnings show as soon as they apply, errors only once saving the form
+// has been tried, so a field is not flagged while it is first typed in.
+func (m *Model) formProblems(fields []formField) map[int]fieldProblem {
+	problems := validateFields(fields)
+	if !m.formSubmitted {
+		for index, problem := range problems {
+			if !problem.warning {
+				delete(problems, index)
+			}
+		}
+	}
+	return problems
+}
+
+// submitForm validates the open form on Enter. When a field has an error it
+// moves the focus to the first field with one, through focus, and reports
+// false; the form stays open with the errors shown under their fields.
+func (m *Model) submitForm(fields []formField, focus func()) bool {
+	m.formSubmitted = true
+	problems := validateFields(fields)
+	for _, field := range fields {
+		if problem, ok := problems[field.index]; ok && !problem.warning {
+			m.formFocusIndex = field.index
+			focus()
+			return false
+		}
+	}
+	return true
+}
+
+// appendFieldProblem adds the problem of the field at index, if it has one,
+// to the lines of a form, right under the field
+func appendFieldProblem(lines []string, problems map[int]fieldProblem, index int) []string {
+	problem, ok := problems[index]
+	if !ok {
+		return lines
+	}
+	if problem.warning {
+		return append(lines, FormWarning.Render(withIcon(icons.Warning, problem.message)))
+	}
+	return append(lines, FormError.Render(withIcon(icons.Error, problem.message)))
+}
+
+// taskFormChecks returns the fields of the task form to validate
+func (m *Model) taskFormChecks() []formField {
+	return []formField{
+		{index: 0, value: m.titleInput.Value(), validators: []validator{required("Title"), maxLength(maxTitleLength)}},
+		{index: 1, value: m.descriptionInput.Value(), validators: []validator{maxLength(maxDescriptionLength)}},
+		{index: 2, value: m.deadlineInput.Value(), validators: []validator{deadlineFormat(), futureDeadline()}},
+	}
+}
+
+// listFormChecks returns the fields of the list form to validate
+func (m *Model) listFormChecks() []formField {
+	return []formField{
+		{index: 0, value: m.titleInput.Value(), validators: []validator{required("Title"), maxLength(maxTitleLength)}},
+		{index: 1, value: m.descriptionInput.Value(), validators: []validator{maxLength(maxDescriptionLength)}},
+		{index: 2, value: m.groupInput.Value(), validators: []validator{maxLength(maxGroupLength)}},
+	}
+}
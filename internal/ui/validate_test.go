@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+func TestValidators(t *testing.T) {
+	past := formatDeadline(models.WallClock(time.Now()).AddDate(0, 0, -1))
+	future := formatDeadline(models.WallClock(time.Now()).AddDate(0, 0, 1))
+	for _, tt := range []struct {
+		name     string
+		validate validator
+		value    string
+		problem  bool
+		warning  bool
+	}{
+		{"required, empty", required("Title"), "", true, false},
+		{"required, blank", required("Title"), " \t ", true, false},
+		{"required, given", required("Title"), "Write report", false, false},
+		{"at the limit", maxLength(5), "héllo", false, false},
+		{"past the limit", maxLength(5), "héllo!", true, false},
+		{"wide characters count once", maxLength(3), "日本語", false, false},
+		{"no deadline", deadlineFormat(), "", false, false},
+		{"deadline in the format", deadlineFormat(), future, false, false},
+		{"deadline in another format", deadlineFormat(), "next friday", true, false},
+		{"future deadline", futureDeadline(), future, false, false},
+		{"past deadline", futureDeadline(), past, true, true},
+		{"unparsable deadline is left to deadlineFormat", futureDeadline(), "next friday", false, false},
+	} {
+		problem := tt.validate(tt.value)
+		if (problem.message != "") != tt.problem || problem.warning != tt.warning {
+			t.Errorf("%s: %q gives %+v, want a problem %v, a warning %v", tt.name, tt.value, problem, tt.problem, tt.warning)
+		}
+	}
+}
+
+func TestValidateFieldsReportsTheFirstProblemOfEachField(t *testing.T) {
+	problems := validateFields([]formField{
+		{index: 0, value: "", validators: []validator{required("Title"), maxLength(0)}},
+		{index: 1, value: "fine", validators: []validator{maxLength(10)}},
+		{index: 2, value: "too long", validators: []validator{maxLength(3), required("Group")}},
+	})
+	if len(problems) != 2 {
+		t.Fatalf("problems = %v, want two", problems)
+	}
+	if got := problems[0].message; got != "Title is required" {
+		t.Errorf("problem of field 0 = %q, want the required one", got)
+	}
+	if got := problems[2].message; !strings.HasPrefix(got, "At most 3 characters") {
+		t.Errorf("problem of field 2 = %q, want the length one", got)
+	}
+}
+
+func TestTaskFormBlocksSavingOnErrors(t *testing.T) {
+	m := newTestModel(t, &testClock{now: time.Now()})
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	mustAddTask(t, m, "Existing", nil)
+	m.switchToList(m.currentListID)
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	m.openNewTaskForm()
+	m.descriptionInput.SetValue(strings.Repeat("x", maxDescriptionLength+1))
+	m.deadlineInput.SetValue(formatDeadline(models.WallClock(time.Now()).AddDate(0, 0, -1)))
+	if problems := m.formProblems(m.taskFormChecks()); len(problems) != 1 || !problems[2].warning {
+		t.Errorf("before saving, problems = %v, want only the deadline warning", problems)
+	}
+
+	// The empty title keeps the form open with the focus on it
+	m.formFocusIndex = 2
+	press(m, enter)
+	if m.state != CreateTaskView || m.formFocusIndex != 0 {
+		t.Fatalf("after saving without a title: state %v, focus %d, want the form with the title focused", m.state, m.formFocusIndex)
+	}
+	if problems := m.formProblems(m.taskFormChecks()); len(problems) != 3 {
+		t.Errorf("after saving, problems = %v, want all three", problems)
+	}
+
+	// Then the description that is too long
+	m.titleInput.SetValue("Write report")
+	press(m, enter)
+	if m.state != CreateTaskView || m.formFocusIndex != 1 {
+		t.Fatalf("after saving a long description: state %v, focus %d, want the form with the description focused", m.state, m.formFocusIndex)
+	}
+
+	// A deadline that has passed only warns
+	m.descriptionInput.SetValue("Quarterly")
+	press(m, enter)
+	if m.state == CreateTaskView {
+		t.Fatalf("the form stayed open: %v", m.formProblems(m.taskFormChecks()))
+	}
+	var titles []string
+	for _, task := range m.getCurrentList().Tasks {
+		titles = append(titles, task.Title)
+	}
+	if strings.Join(titles, ", ") != "Existing, Write report" {
+		t.Errorf("tasks = %v, want the new one added", titles)
+	}
+}
@@ -2,6 +2,9 @@ package ui
 
 import (
 	"fmt"
+	"io"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -9,6 +12,7 @@ import (
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 
 	"github.com/DhirajZope/lazytodo/internal/models"
 )
@@ -22,7 +26,7 @@ var (
 
 	headerStyle = lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("86")).
+			Foreground(lipgloss.AdaptiveColor{Dark: "86", Light: "30"}).
 			MarginBottom(1)
 
 	formStyle = lipgloss.NewStyle().
@@ -44,35 +48,162 @@ var (
 			Bold(true)
 
 	priorityStyles = map[models.Priority]lipgloss.Style{
-		models.Low:      lipgloss.NewStyle().Foreground(lipgloss.Color("244")),
-		models.Medium:   lipgloss.NewStyle().Foreground(lipgloss.Color("220")),
-		models.High:     lipgloss.NewStyle().Foreground(lipgloss.Color("208")),
-		models.Critical: lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true),
+		models.Low:      lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Dark: "244", Light: "242"}),
+		models.Medium:   lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Dark: "220", Light: "136"}),
+		models.High:     lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Dark: "208", Light: "166"}),
+		models.Critical: lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Dark: "196", Light: "160"}).Bold(true),
 	}
 )
 
+// taskLabels are the markers offered by the label picker; the empty label means none
+var taskLabels = []string{"", "🔴", "🟠", "🟡", "🟢", "🔵", "🟣", "⭐", "📌", "💡", "🐛"}
+
+// taskFormFields is the number of focusable fields in the task form
+const taskFormFields = 4
+
+// listColors is the palette offered by the list color picker; the first entry
+// is the theme accent, which lists without a color fall back to. Lists store
+// the dark shade, which draws in the light one on light terminals.
+var listColors = []struct {
+	name  string
+	color lipgloss.AdaptiveColor
+}{
+	{"Green", AccentColor},
+	{"Blue", InfoColor},
+	{"Purple", PrimaryColor},
+	{"Orange", WarningColor},
+	{"Red", ErrorColor},
+	{"Pink", lipgloss.AdaptiveColor{Dark: "#EC4899", Light: "#BE185D"}},
+	{"Cyan", lipgloss.AdaptiveColor{Dark: "#06B6D4", Light: "#0E7490"}},
+	{"Yellow", lipgloss.AdaptiveColor{Dark: "#EAB308", Light: "#A16207"}},
+}
+
+// activityFeedLimit is the number of entries shown in the recent activity feed
+const activityFeedLimit = 50
+
 // List item implementations
 type listItem struct {
-	id          string
-	title       string
-	description string
-	progress    float64
-	taskCount   int
+	id           string
+	title        string
+	description  string
+	progress     float64
+	taskCount    int
+	overdueCount int
+	dueSoonCount int
+	remaining    time.Duration // Estimate of the incomplete tasks
+	color        lipgloss.TerminalColor
+	pinned       bool
 }
 
 func (i listItem) FilterValue() string { return i.title }
+func (i listItem) itemID() string      { return i.id }
 func (i listItem) Title() string       { return i.title }
 func (i listItem) Description() string {
 	if i.taskCount == 0 {
 		return i.description
 	}
-	progress := fmt.Sprintf("%.0f%% complete (%d tasks)", i.progress, i.taskCount)
+	progress := fmt.Sprintf("%.0f%% complete (%s)", i.progress, taskCountLabel(i.taskCount))
 	if i.description != "" {
 		return fmt.Sprintf("%s • %s", i.description, progress)
 	}
 	return progress
 }
 
+// deadlineBadges renders the overdue and due soon counts shown after a list's
+// title, and the work estimated for its open tasks
+func (i listItem) deadlineBadges() string {
+	var badges []string
+	if i.overdueCount > 0 {
+		badges = append(badges, lipgloss.NewStyle().Foreground(ErrorColor).Bold(true).
+			Render(fmt.Sprintf("%s %d", icons.Warning, i.overdueCount)))
+	}
+	if i.dueSoonCount > 0 {
+		badges = append(badges, lipgloss.NewStyle().Foreground(WarningColor).
+			Render(fmt.Sprintf("%s %d", icons.DueSoon, i.dueSoonCount)))
+	}
+	if i.remaining > 0 {
+		badges = append(badges, lipgloss.NewStyle().Foreground(TextMuted).
+			Render(withIcon(icons.Timer, models.FormatDuration(i.remaining))))
+	}
+	return strings.Join(badges, " ")
+}
+
+// todoListDelegate renders sidebar lists like the default delegate, with a
+// bullet in each list's accent color in front of the title and the list's
+// overdue and due soon counts and work remaining after it
+type todoListDelegate struct {
+	list.DefaultDelegate
+	inline *inlineEdit
+}
+
+func (d todoListDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if group, ok := item.(groupItem); ok && m.Width() > 0 {
+		d.renderGroupItem(w, m, index, group)
+		return
+	}
+
+	i, ok := item.(listItem)
+	if !ok || m.Width() <= 0 {
+		d.DefaultDelegate.Render(w, m, index, item)
+		return
+	}
+	if d.inline.renaming(i.id, true) {
+		d.inline.render(w, m, d.Styles, i.Description())
+		return
+	}
+
+	titleStyle, descStyle := d.Styles.NormalTitle, d.Styles.NormalDesc
+	if m.FilterState() == list.Filtering && m.FilterValue() == "" {
+		titleStyle, descStyle = d.Styles.DimmedTitle, d.Styles.DimmedDesc
+	} else if index == m.Index() {
+		titleStyle, descStyle = d.Styles.SelectedTitle, d.Styles.SelectedDesc
+	}
+
+	// The bullet carries the style's border and padding so the title can be
+	// rendered separately and keep the delegate's own colors
+	textWidth := m.Width() - titleStyle.GetHorizontalFrameSize()
+	bullet := titleStyle.Foreground(i.color).Render(icons.ListBullet)
+	badges := i.deadlineBadges()
+	if badges != "" {
+		badges = " " + badges
+	}
+	pin := ""
+	if i.pinned {
+		pin = icons.Pinned + " "
+	}
+	title := lipgloss.NewStyle().
+		Foreground(titleStyle.GetForeground()).
+		Bold(titleStyle.GetBold()).
+		Render(" " + pin + ansi.Truncate(i.Title(), textWidth-ansi.StringWidth(icons.ListBullet)-ansi.StringWidth(pin)-ansi.StringWidth(badges)-1, "…"))
+	desc := descStyle.Render(ansi.Truncate(i.Description(), m.Width()-descStyle.GetHorizontalFrameSize(), "…"))
+
+	fmt.Fprintf(w, "%s\n%s", bullet+title+badges, desc)
+}
+
+// listAccent returns the accent color of a todo list, falling back to the
+// theme accent; a color of the picker draws in the shade for the background
+func listAccent(todoList *models.TodoList) lipgloss.TerminalColor {
+	if todoList.Color == "" {
+		return listColors[0].color
+	}
+	for _, candidate := range listColors {
+		if candidate.color.Dark == todoList.Color {
+			return candidate.color
+		}
+	}
+	return lipgloss.Color(todoList.Color)
+}
+
+// listColorIndexOf returns the picker position of a color, or the accent if it is not offered
+func listColorIndexOf(color string) int {
+	for i, candidate := range listColors {
+		if candidate.color.Dark == color {
+			return i
+		}
+	}
+	return 0
+}
+
 type taskItem struct {
 	id          string
 	title       string
@@ -82,27 +213,51 @@ type taskItem struct {
 	deadline    *time.Time
 	overdue     bool
 	dueSoon     bool
+	label       string
+	snoozeCount int
+	estimate    time.Duration
+	spent       time.Duration
+	timing      bool // The timer is running on this task
+	link        string
+	source      string
+	marked      bool // Picked for a bulk change
+	staleDays   int  // Days the task has sat open when it is stale; 0 otherwise
+	progress    int  // Percent done
+	lingering   bool // Just completed, and listed a while although completed tasks are hidden
+	starred     bool // Part of the daily plan
 }
 
-func (i taskItem) FilterValue() string { return i.title }
+// The task filter sees the title and the creation source, see filterTasks
+func (i taskItem) FilterValue() string { return i.title + filterSourceSeparator + i.source }
+func (i taskItem) itemID() string      { return i.id }
 func (i taskItem) Title() string {
-	prefix := "○"
+	prefix := icons.Incomplete
 	if i.completed {
-		prefix = "✓"
+		prefix = icons.Complete
+	}
+
+	if i.starred {
+		prefix = icons.Starred + " " + prefix
+	}
+	if i.marked {
+		prefix = icons.Marked + " " + prefix
 	}
 
 	title := fmt.Sprintf("%s %s", prefix, i.title)
+	if i.label != "" {
+		title = fmt.Sprintf("%s %s %s", prefix, labelText(i.label), i.title)
+	}
 
 	// Add priority indicator
 	if i.priority != models.Low {
 		priorityStr := ""
 		switch i.priority {
 		case models.Medium:
-			priorityStr = "⚡"
+			priorityStr = icons.PriorityMedium
 		case models.High:
-			priorityStr = "🔥"
+			priorityStr = icons.PriorityHigh
 		case models.Critical:
-			priorityStr = "🚨"
+			priorityStr = icons.PriorityCritical
 		}
 		title = fmt.Sprintf("%s %s", title, priorityStr)
 	}
@@ -110,12 +265,16 @@ func (i taskItem) Title() string {
 	// Add deadline indicator
 	if i.deadline != nil {
 		if i.overdue {
-			title = fmt.Sprintf("%s ⚠️", title)
+			title = fmt.Sprintf("%s %s", title, icons.Overdue)
 		} else if i.dueSoon {
-			title = fmt.Sprintf("%s ⏰", title)
+			title = fmt.Sprintf("%s %s", title, icons.DueSoon)
 		}
 	}
 
+	if i.link != "" {
+		title = fmt.Sprintf("%s %s", title, icons.Link)
+	}
+
 	return title
 }
 
@@ -127,7 +286,7 @@ func (i taskItem) Description() string {
 	}
 
 	if i.deadline != nil {
-		deadlineStr := i.deadline.Format("2006-01-02 15:04")
+		deadlineStr := formatDeadline(*i.deadline)
 		if i.overdue {
 			deadlineStr = fmt.Sprintf("Due: %s (OVERDUE)", deadlineStr)
 		} else if i.dueSoon {
@@ -138,121 +297,333 @@ func (i taskItem) Description() string {
 		parts = append(parts, deadlineStr)
 	}
 
-	return strings.Join(parts, " • ")
-}
+	if i.snoozeCount > 0 {
+		parts = append(parts, snoozeBadge(i.snoozeCount))
+	}
 
-// updateTodoListsList updates the todo lists list model
-func (m *Model) updateTodoListsList() {
-	items := make([]list.Item, len(m.app.TodoLists))
-	for i, todoList := range m.app.TodoLists {
-		items[i] = listItem{
-			id:          todoList.ID,
-			title:       todoList.Name,
-			description: todoList.Description,
-			progress:    todoList.GetProgress(),
-			taskCount:   todoList.GetTotalCount(),
-		}
+	if i.staleDays > 0 {
+		parts = append(parts, staleBadge(i.staleDays))
+	}
+
+	if i.progress > 0 && !i.completed {
+		parts = append(parts, progressBadge(i.progress))
+	}
+
+	if i.timing {
+		parts = append(parts, withIcon(icons.Timer, "timing"))
+	} else if badge := timeBadge(i.estimate, i.spent); badge != "" {
+		parts = append(parts, badge)
 	}
 
+	return strings.Join(parts, " • ")
+}
+
+// newItemDelegate returns the item delegate shared by the sidebar and task lists
+func newItemDelegate() list.DefaultDelegate {
 	delegate := list.NewDefaultDelegate()
 	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
 		BorderForeground(lipgloss.Color("62")).
-		Foreground(lipgloss.Color("86"))
+		Foreground(lipgloss.AdaptiveColor{Dark: "86", Light: "30"})
 	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
 		BorderForeground(lipgloss.Color("62")).
 		Foreground(lipgloss.Color("244"))
+	return delegate
+}
 
-	// Calculate dimensions first
-	listWidth := 25  // Default width
-	listHeight := 15 // Default height
+// newListModel creates an empty bubbles list; it is sized by updateListDimensions
+// and filled by SetItems, so it is never used uninitialized
+func newListModel(delegate list.ItemDelegate) list.Model {
+	l := list.New([]list.Item{}, delegate, 0, 0)
+	l.SetShowStatusBar(false)
+	l.SetShowHelp(false)
+	return l
+}
 
-	// Set size based on sidebar window dimensions if available
-	sidebarWindow := m.layout.GetWindow(SidebarWindow)
-	if sidebarWindow != nil && sidebarWindow.Position.Width > 0 && sidebarWindow.Position.Height > 0 {
-		listWidth = sidebarWindow.Position.Width - 4   // Account for borders
-		listHeight = sidebarWindow.Position.Height - 6 // Account for borders and title
-	} else if m.width > 0 && m.height > 0 {
-		// Fallback to model dimensions
-		listWidth = (m.width / 3) - 4
-		listHeight = m.height - 8
+// sourceFilterPrefix narrows the task filter to a creation source, e.g. "source:api"
+const sourceFilterPrefix = "source:"
+
+// filterSourceSeparator separates the title from the source in a task's filter value
+const filterSourceSeparator = "\x00"
+
+// filterTasks is the tasks list filter. It fuzzy matches titles like the
+// default filter, except that a source:NAME word keeps only the tasks created
+// via a source starting with NAME; "source:api fix" finds API tasks about a fix.
+func filterTasks(term string, targets []string) []list.Rank {
+	source := ""
+	var words []string
+	for _, word := range strings.Fields(term) {
+		if name, ok := strings.CutPrefix(strings.ToLower(word), sourceFilterPrefix); ok {
+			source = name
+			continue
+		}
+		words = append(words, word)
 	}
 
-	// Ensure minimum dimensions
-	if listWidth < 10 {
-		listWidth = 10
+	titles := make([]string, 0, len(targets))
+	indexes := make([]int, 0, len(targets))
+	for i, target := range targets {
+		title, taskSource, _ := strings.Cut(target, filterSourceSeparator)
+		if !strings.HasPrefix(taskSource, source) {
+			continue
+		}
+		titles = append(titles, title)
+		indexes = append(indexes, i)
+	}
+
+	if len(words) == 0 {
+		ranks := make([]list.Rank, len(indexes))
+		for i, index := range indexes {
+			ranks[i] = list.Rank{Index: index}
+		}
+		return ranks
+	}
+
+	ranks := list.DefaultFilter(strings.Join(words, " "), titles)
+	for i := range ranks {
+		ranks[i].Index = indexes[ranks[i].Index]
+	}
+	return ranks
+}
+
+// newTasksListModel creates the tasks list, which can also be filtered by
+// source and divided into sections
+func newTasksListModel(inline *inlineEdit) list.Model {
+	l := newListModel(taskDelegate{newItemDelegate(), inline})
+	l.Filter = filterTasks
+	return l
+}
+
+// identifiedItem is a list item backed by a list or task with an ID
+type identifiedItem interface {
+	itemID() string
+}
+
+// setListItems replaces the items of a bubbles list, keeping its active filter
+// and the selection on the same item. When that item is gone the cursor stays
+// at the same position, which is its nearest neighbor.
+func setListItems(l *list.Model, items []list.Item) {
+	selectedID := ""
+	if item, ok := l.SelectedItem().(identifiedItem); ok {
+		selectedID = item.itemID()
+	}
+
+	if cmd := l.SetItems(items); cmd != nil {
+		// Apply the active filter right away rather than showing nothing until it reports back
+		*l, _ = l.Update(cmd())
+	}
+
+	if selectedID != "" && selectItem(l, selectedID) {
+		return
+	}
+	if visible := len(l.VisibleItems()); visible > 0 && l.Index() >= visible {
+		l.Select(visible - 1)
+	}
+}
+
+// selectItem moves the cursor of a bubbles list to the shown item with the given ID
+func selectItem(l *list.Model, id string) bool {
+	for i, item := range l.VisibleItems() {
+		if item, ok := item.(identifiedItem); ok && item.itemID() == id {
+			l.Select(i)
+			return true
+		}
+	}
+	return false
+}
+
+// updateTodoListsList updates the todo lists list model
+func (m *Model) updateTodoListsList() {
+	m.todoListsList.Title = withIcon(icons.Lists, "Todo Lists")
+	setListItems(&m.todoListsList, m.todoListItems())
+}
+
+// todoListItems builds the sidebar items from the todo lists, under group
+// headings once any list has a group
+func (m *Model) todoListItems() []list.Item {
+	items := make([]list.Item, len(m.app.TodoLists))
+	for i := range m.app.TodoLists {
+		todoList := &m.app.TodoLists[i]
+		overdue, dueSoon := todoList.GetDeadlineCounts(m.app.Settings.DueSoonWindow())
+		items[i] = listItem{
+			id:           todoList.ID,
+			title:        plainLine(todoList.Name),
+			description:  plainLine(todoList.Description),
+			progress:     todoList.GetProgress(),
+			taskCount:    todoList.GetTotalCount(),
+			overdueCount: overdue,
+			dueSoonCount: dueSoon,
+			remaining:    todoList.GetRemainingEstimate(),
+			color:        listAccent(todoList),
+			pinned:       todoList.Pinned,
+		}
 	}
-	if listHeight < 5 {
-		listHeight = 5
+	items = m.groupSidebarItems(items)
+	if starred, ok := m.starredSidebarItem(); ok {
+		items = append([]list.Item{starred}, items...)
 	}
+	return items
+}
 
-	// Create list with proper dimensions
-	m.todoListsList = list.New(items, delegate, listWidth, listHeight)
-	m.todoListsList.Title = "📋 Todo Lists"
-	m.todoListsList.SetShowStatusBar(false)
-	m.todoListsList.SetShowHelp(false)
+// refreshTodoListItems updates the sidebar counts in place, keeping the
+// selection, after tasks changed without the set of lists changing
+func (m *Model) refreshTodoListItems() {
+	setListItems(&m.todoListsList, m.todoListItems())
 }
 
 // updateTasksList updates the tasks list model
 func (m *Model) updateTasksList() {
 	currentList := m.getCurrentList()
 	if currentList == nil {
+		// No list selected: the main window shows a placeholder instead
+		m.tasksListID = ""
+		m.tasksList.ResetFilter()
+		setListItems(&m.tasksList, nil)
+		return
+	}
+
+	// Another list starts at the top without the previous list's filter
+	if m.tasksListID != currentList.ID {
+		m.tasksListID = currentList.ID
+		m.tasksList.ResetFilter()
+		m.tasksList.ResetSelected()
+		clear(m.marked)
+		clear(m.lingering)
+	}
+
+	// Tasks are fetched the first time a list is shown
+	if err := m.storage.LoadTasks(m.app, currentList.ID); err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error loading tasks: %v", err), "error")
 		return
 	}
 
-	var items []list.Item
+	now := time.Now()
+	items := []taskItem{}
 	for _, task := range currentList.Tasks {
-		if !m.app.Settings.ShowCompleted && task.Completed {
+		_, lingering := m.lingering[task.ID]
+		if lingering && !task.Completed {
+			// Opened again before its time was up
+			delete(m.lingering, task.ID)
+			lingering = false
+		}
+		if !m.app.Settings.ShowCompleted && task.Completed && !lingering {
 			continue
 		}
 
+		staleDays := 0
+		if age, stale := m.app.Settings.StaleAge(&task, now, false); stale {
+			staleDays = age
+		}
 		items = append(items, taskItem{
 			id:          task.ID,
-			title:       task.Title,
-			description: task.Description,
+			title:       plainLine(task.Title),
+			description: plainLine(task.Description),
 			completed:   task.Completed,
 			priority:    task.Priority,
 			deadline:    task.Deadline,
 			overdue:     task.IsOverdue(),
-			dueSoon:     task.IsDueSoon(),
+			dueSoon:     m.isDueSoon(&task),
+			label:       task.Label,
+			snoozeCount: task.SnoozeCount,
+			estimate:    task.Estimate,
+			spent:       task.Spent,
+			timing:      m.timerRunning(task.ID),
+			link:        task.Link,
+			source:      task.CreationSource(),
+			marked:      m.marked[task.ID],
+			staleDays:   staleDays,
+			progress:    task.Progress,
+			lingering:   lingering,
+			starred:     task.Starred,
 		})
 	}
+	m.pruneMarks(currentList)
 
-	delegate := list.NewDefaultDelegate()
-	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
-		BorderForeground(lipgloss.Color("62")).
-		Foreground(lipgloss.Color("86"))
-	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
-		BorderForeground(lipgloss.Color("62")).
-		Foreground(lipgloss.Color("244"))
+	m.tasksList.Title = fitListTitle(m.tasksList, withIcon(icons.Tasks, plainLine(currentList.Name)))
+	m.tasksList.Styles.Title = m.tasksList.Styles.Title.Background(listAccent(currentList))
+	setListItems(&m.tasksList, groupTaskItems(currentList.Grouping, items, now))
+	m.skipTaskHeader(1)
+}
 
-	// Calculate dimensions first
-	listWidth := 40  // Default width
-	listHeight := 15 // Default height
+// switchToList makes the given list current and shows its tasks
+func (m *Model) switchToList(listID string) {
+	for _, todoList := range m.app.TodoLists {
+		if todoList.ID == listID {
+			m.currentListID = listID
+			m.expandGroupOf(&todoList)
+			if !selectItem(&m.todoListsList, listID) {
+				// The sidebar filter hides the list
+				m.todoListsList.ResetFilter()
+				selectItem(&m.todoListsList, listID)
+			}
+			m.updateTasksList()
+			m.state = TasksView
+			m.layout.SetFocus(MainWindow)
+			m.showMessageWithType("Switched to "+todoList.Name, "success")
+			return
+		}
+	}
+}
 
-	// Set size based on main window dimensions if available
-	mainWindow := m.layout.GetWindow(MainWindow)
-	if mainWindow != nil && mainWindow.Position.Width > 0 && mainWindow.Position.Height > 0 {
-		listWidth = mainWindow.Position.Width - 4   // Account for borders
-		listHeight = mainWindow.Position.Height - 6 // Account for borders and title
-	} else if m.width > 0 && m.height > 0 {
-		// Fallback to model dimensions
-		listWidth = (m.width * 2 / 3) - 4
-		listHeight = m.height - 8
+// goToList opens the nth list shown in the sidebar, counting from 1 and
+// leaving out group headings and the lists of collapsed groups
+func (m *Model) goToList(n int) {
+	shown := 0
+	for _, item := range m.todoListsList.VisibleItems() {
+		if item, ok := item.(listItem); ok {
+			shown++
+			if shown == n {
+				m.switchToList(item.id)
+				return
+			}
+		}
 	}
+	m.showMessageWithType(fmt.Sprintf("No list %d: the sidebar shows %d", n, shown), "info")
+}
 
-	// Ensure minimum dimensions
-	if listWidth < 10 {
-		listWidth = 10
+// toggleTask completes or reopens a task of the current list
+func (m *Model) toggleTask(taskID string) tea.Cmd {
+	before, found := m.taskSnapshot(taskID)
+	task, err := m.storage.ToggleTask(m.app, m.currentListID, taskID)
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return nil
 	}
-	if listHeight < 5 {
-		listHeight = 5
+
+	if found {
+		m.recordUndo(undoEntry{kind: undoToggle, listID: m.currentListID, before: before, after: task})
+	}
+	m.putTask(m.currentListID, task)
+	linger := m.lingerCompleted(task)
+	m.updateTasksList()
+	status := "completed"
+	msgType := "success"
+	if !task.Completed {
+		status = "uncompleted"
+		msgType = "info"
 	}
+	m.showMessageWithType(fmt.Sprintf("Task %s", status), msgType)
+	m.refreshStreak()
+	m.refreshHeatmap()
+	if task.Completed {
+		return tea.Batch(m.saveData(), m.celebrateIfListDone(m.currentListID), linger)
+	}
+	return m.saveData()
+}
 
-	// Create list with proper dimensions
-	m.tasksList = list.New(items, delegate, listWidth, listHeight)
-	m.tasksList.Title = fmt.Sprintf("📝 %s", currentList.Name)
-	m.tasksList.SetShowStatusBar(false)
-	m.tasksList.SetShowHelp(false)
+// toggleShowCompleted flips whether completed tasks are listed and saves the setting
+func (m *Model) toggleShowCompleted() tea.Cmd {
+	m.app.Settings.ShowCompleted = !m.app.Settings.ShowCompleted
+	clear(m.lingering)
+	m.updateTasksList()
+
+	if m.app.Settings.ShowCompleted {
+		m.showMessageWithType("Showing completed tasks", "info")
+	} else if currentList := m.getCurrentList(); currentList != nil && currentList.GetCompletedCount() > 0 {
+		m.showMessageWithType(fmt.Sprintf("Hiding completed tasks (%d hidden in %s)", currentList.GetCompletedCount(), currentList.Name), "info")
+	} else {
+		m.showMessageWithType("Hiding completed tasks", "info")
+	}
+	return m.saveData()
 }
 
 // Lists view - now handles sidebar interaction
@@ -274,18 +645,98 @@ func (m *Model) updateListsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.layout.SetFocus(MainWindow)
 		return m, nil
 
-	case key.Matches(msg, m.keys.Enter):
+	case key.Matches(msg, m.keys.Activity):
+		m.openActivityFeed()
+		return m, nil
+
+	case key.Matches(msg, m.keys.ActivityLog):
+		m.openActivityLog()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Trash):
+		m.openTrashView()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Log):
+		m.openLogView()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Templates):
+		m.openTemplates()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Shift):
 		if selected := m.todoListsList.SelectedItem(); selected != nil {
 			if item, ok := selected.(listItem); ok {
-				m.currentListID = item.id
-				m.updateTasksList()
-				m.state = TasksView
-				m.layout.SetFocus(MainWindow)
-				m.showMessageWithType("Switched to "+item.title, "success")
+				m.openShiftPrompt(item.id)
+			}
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.SaveTemplate):
+		if selected := m.todoListsList.SelectedItem(); selected != nil {
+			if item, ok := selected.(listItem); ok {
+				m.openSaveTemplatePrompt(item.id)
+			}
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.FocusMode):
+		m.toggleFocusMode()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Narrower, m.keys.Wider):
+		return m, m.resizeSidebar(msg)
+
+	case key.Matches(msg, m.keys.GoToList) && m.todoListsList.FilterState() == list.Unfiltered:
+		n, _ := strconv.Atoi(msg.String())
+		m.goToList(n)
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter, m.keys.Toggle):
+		if selected := m.todoListsList.SelectedItem(); selected != nil {
+			if group, ok := selected.(groupItem); ok {
+				m.toggleGroup(group.name)
+				return m, nil
+			}
+			if item, ok := selected.(listItem); ok && key.Matches(msg, m.keys.Enter) {
+				m.switchToList(item.id)
+				return m, nil
+			}
+			if _, ok := selected.(starredItem); ok && key.Matches(msg, m.keys.Enter) {
+				m.openStarredView()
 				return m, nil
 			}
 		}
 
+	case key.Matches(msg, m.keys.MoveUp, m.keys.MoveDown):
+		if selected := m.todoListsList.SelectedItem(); selected != nil {
+			if item, ok := selected.(listItem); ok {
+				step := 1
+				if key.Matches(msg, m.keys.MoveUp) {
+					step = -1
+				}
+
+				// Lists move among the lists under the same heading
+				offset := groupMoveOffset(m.app.TodoLists, item.id, step)
+				if offset == 0 {
+					return m, nil
+				}
+
+				if err := m.storage.ReorderList(m.app, item.id, offset); err != nil {
+					m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+					return m, nil
+				}
+
+				// The moved list stays selected at its new position
+				m.updateTodoListsList()
+				return m, m.saveData()
+			}
+		}
+
+	case key.Matches(msg, m.keys.Pin):
+		return m, m.togglePin()
+
 	case key.Matches(msg, m.keys.Edit):
 		if selected := m.todoListsList.SelectedItem(); selected != nil {
 			if item, ok := selected.(listItem); ok {
@@ -296,6 +747,12 @@ func (m *Model) updateListsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case key.Matches(msg, m.keys.Rename):
+		if item, ok := m.todoListsList.SelectedItem().(listItem); ok {
+			return m, m.startRename(item.id, item.title, true)
+		}
+		return m, nil
+
 	case key.Matches(msg, m.keys.Delete):
 		if selected := m.todoListsList.SelectedItem(); selected != nil {
 			if item, ok := selected.(listItem); ok {
@@ -316,12 +773,7 @@ func (m *Model) updateListsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) renderListsView() string {
-	var listView string
-	if m.todoListsList.Items() != nil {
-		listView = m.todoListsList.View()
-	} else {
-		listView = "Loading..."
-	}
+	listView := m.todoListsList.View()
 
 	content := []string{
 		titleStyle.Render("🎯 LazyTodo - Smart Todo Manager"),
@@ -346,14 +798,40 @@ func (m *Model) updateTasksView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch {
 	case key.Matches(msg, m.keys.Back):
+		// Esc clears the marks, then an applied filter, before leaving the list
+		if len(m.marked) > 0 {
+			m.clearMarks()
+			return m, nil
+		}
+		if m.tasksList.FilterState() == list.FilterApplied {
+			m.tasksList.ResetFilter()
+			m.skipTaskHeader(1)
+			return m, nil
+		}
 		m.layout.SetFocus(SidebarWindow)
 		return m, nil
 
+	case key.Matches(msg, m.keys.FocusMode):
+		m.toggleFocusMode()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Narrower, m.keys.Wider):
+		return m, m.resizeSidebar(msg)
+
 	case key.Matches(msg, m.keys.NewTask):
-		m.resetForm()
-		m.state = CreateTaskView
+		m.openNewTaskForm()
 		return m, nil
 
+	case key.Matches(msg, m.keys.Enter):
+		if selected := m.tasksList.SelectedItem(); selected != nil {
+			if item, ok := selected.(taskItem); ok {
+				m.detailTaskID = item.id
+				m.noteCursor = 0
+				m.state = TaskDetailView
+				return m, nil
+			}
+		}
+
 	case key.Matches(msg, m.keys.Edit):
 		if selected := m.tasksList.SelectedItem(); selected != nil {
 			if item, ok := selected.(taskItem); ok {
@@ -364,33 +842,127 @@ func (m *Model) updateTasksView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case key.Matches(msg, m.keys.Rename):
+		if item, ok := m.tasksList.SelectedItem().(taskItem); ok {
+			return m, m.startRename(item.id, item.title, false)
+		}
+		return m, nil
+
 	case key.Matches(msg, m.keys.Toggle):
-		if selected := m.tasksList.SelectedItem(); selected != nil {
-			if item, ok := selected.(taskItem); ok {
-				if err := m.storage.ToggleTask(m.app, m.currentListID, item.id); err != nil {
-					m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
-				} else {
-					m.updateTasksList()
-					status := "completed"
-					msgType := "success"
-					if item.completed {
-						status = "uncompleted"
-						msgType = "info"
-					}
-					m.showMessageWithType(fmt.Sprintf("Task %s", status), msgType)
-					return m, m.saveData()
-				}
-			}
+		if len(m.marked) > 0 {
+			return m, m.completeMarked()
+		}
+		if item, ok := m.tasksList.SelectedItem().(taskItem); ok {
+			return m, m.toggleTask(item.id)
+		}
+
+	case key.Matches(msg, m.keys.HideDone):
+		return m, m.toggleShowCompleted()
+
+	case key.Matches(msg, m.keys.SetDeadline):
+		if len(m.marked) > 0 {
+			m.openBulkDeadlinePrompt()
+		} else {
+			m.openDeadlinePrompt()
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Mark):
+		m.toggleMark()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Duplicate):
+		return m, m.duplicateSelectedTask()
+
+	case key.Matches(msg, m.keys.GroupTasks):
+		return m, m.cycleTaskGrouping()
+
+	case key.Matches(msg, m.keys.PasteTasks):
+		return m, m.pasteTasks()
+
+	case key.Matches(msg, m.keys.SetPriority):
+		m.openPriorityChooser()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Snooze):
+		m.openSnoozeChooser()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Dismiss):
+		m.dismissEscalations()
+		return m, nil
+
+	case key.Matches(msg, m.keys.ProgressUp):
+		return m, m.nudgeProgress(progressStep)
+
+	case key.Matches(msg, m.keys.ProgressDown):
+		return m, m.nudgeProgress(-progressStep)
+
+	case key.Matches(msg, m.keys.Star):
+		return m, m.toggleStar()
+
+	case key.Matches(msg, m.keys.Timer):
+		if item, ok := m.tasksList.SelectedItem().(taskItem); ok {
+			return m, m.toggleTimer(item.id)
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.OpenLink):
+		if item, ok := m.tasksList.SelectedItem().(taskItem); ok {
+			return m, m.openTaskLink(m.getTask(item.id))
 		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.OpenURL):
+		if item, ok := m.tasksList.SelectedItem().(taskItem); ok {
+			return m, m.openTaskURL(m.getTask(item.id))
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Activity):
+		m.openActivityFeed()
+		return m, nil
+
+	case key.Matches(msg, m.keys.ActivityLog):
+		m.openActivityLog()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Trash):
+		m.openTrashView()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Log):
+		m.openLogView()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Templates):
+		m.openTemplates()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Shift):
+		m.openShiftPrompt(m.currentListID)
+		return m, nil
+
+	case key.Matches(msg, m.keys.SaveTemplate):
+		m.openSaveTemplatePrompt(m.currentListID)
+		return m, nil
 
 	case key.Matches(msg, m.keys.Delete):
 		if selected := m.tasksList.SelectedItem(); selected != nil {
 			if item, ok := selected.(taskItem); ok {
+				before, found := m.taskSnapshot(item.id)
+				afterID := m.precedingTaskID(item.id)
 				if err := m.storage.DeleteTask(m.app, m.currentListID, item.id); err != nil {
 					m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
 				} else {
+					if found {
+						m.recordUndo(undoEntry{kind: undoDelete, listID: m.currentListID, before: before, after: before, afterID: afterID})
+					}
+					if todoList := m.getList(m.currentListID); todoList != nil {
+						todoList.RemoveTask(item.id)
+					}
 					m.updateTasksList()
-					m.showMessageWithType("Task deleted successfully", "success")
+					m.showMessageWithType(fmt.Sprintf("Task moved to the trash (%s to restore it)", m.keys.Trash.Help().Key), "success")
 					return m, m.saveData()
 				}
 			}
@@ -398,20 +970,17 @@ func (m *Model) updateTasksView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 
 	var cmd tea.Cmd
-	// Only update tasksList if it's initialized
-	if m.tasksList.Items() != nil {
-		m.tasksList, cmd = m.tasksList.Update(msg)
+	m.tasksList, cmd = m.tasksList.Update(msg)
+	if key.Matches(msg, m.tasksList.KeyMap.CursorUp) {
+		m.skipTaskHeader(-1)
+	} else {
+		m.skipTaskHeader(1)
 	}
 	return m, cmd
 }
 
 func (m *Model) renderTasksView() string {
-	var taskView string
-	if m.tasksList.Items() != nil {
-		taskView = m.tasksList.View()
-	} else {
-		taskView = "Loading..."
-	}
+	taskView := m.tasksList.View()
 
 	content := []string{
 		titleStyle.Render("🎯 LazyTodo - Task View"),
@@ -433,12 +1002,20 @@ func (m *Model) resetForm() {
 	m.titleInput.SetValue("")
 	m.descriptionInput.SetValue("")
 	m.deadlineInput.SetValue("")
+	m.groupInput.SetValue("")
 	m.formFocusIndex = 0
+	m.formSubmitted = false
 	m.titleInput.Focus()
 	m.descriptionInput.Blur()
 	m.deadlineInput.Blur()
+	m.groupInput.Blur()
 	m.editing = false
 	m.editingTaskID = ""
+	m.editingPriority = models.Medium
+	m.labelIndex = 0
+	m.colorIndex = 0
+	m.templateIndex = 0
+	m.markFormPristine()
 }
 
 func (m *Model) prepareEditListForm() {
@@ -446,11 +1023,16 @@ func (m *Model) prepareEditListForm() {
 		m.titleInput.SetValue(currentList.Name)
 		m.descriptionInput.SetValue(currentList.Description)
 		m.deadlineInput.SetValue("")
+		m.groupInput.SetValue(currentList.Group)
+		m.colorIndex = listColorIndexOf(currentList.Color)
 		m.formFocusIndex = 0
+		m.formSubmitted = false
 		m.titleInput.Focus()
 		m.descriptionInput.Blur()
 		m.deadlineInput.Blur()
+		m.groupInput.Blur()
 		m.editing = true
+		m.markFormPristine()
 	}
 }
 
@@ -465,15 +1047,19 @@ func (m *Model) prepareEditTaskForm() {
 			m.titleInput.SetValue(task.Title)
 			m.descriptionInput.SetValue(task.Description)
 			if task.Deadline != nil {
-				m.deadlineInput.SetValue(task.Deadline.Format("2006-01-02 15:04"))
+				m.deadlineInput.SetValue(formatDeadline(*task.Deadline))
 			} else {
 				m.deadlineInput.SetValue("")
 			}
+			m.editingPriority = task.Priority
+			m.labelIndex = labelIndexOf(task.Label)
 			m.formFocusIndex = 0
+			m.formSubmitted = false
 			m.titleInput.Focus()
 			m.descriptionInput.Blur()
 			m.deadlineInput.Blur()
 			m.editing = true
+			m.markFormPristine()
 			break
 		}
 	}
@@ -483,39 +1069,84 @@ func (m *Model) prepareEditTaskForm() {
 func (m *Model) updateListForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch {
 	case key.Matches(msg, m.keys.Back):
-		m.state = ListsView
-		m.layout.SetFocus(SidebarWindow)
-		return m, nil
+		return m, m.cancelForm(func() {
+			m.state = ListsView
+			m.layout.SetFocus(SidebarWindow)
+		})
 
 	case key.Matches(msg, m.keys.Tab):
-		m.formFocusIndex = (m.formFocusIndex + 1) % 2
-		m.updateFormFocus()
+		m.formFocusIndex = (m.formFocusIndex + 1) % m.listFormFields()
+		m.updateListFormFocus()
 		return m, nil
 
 	case key.Matches(msg, m.keys.ShiftTab):
-		m.formFocusIndex = (m.formFocusIndex - 1 + 2) % 2
-		m.updateFormFocus()
+		m.formFocusIndex = (m.formFocusIndex - 1 + m.listFormFields()) % m.listFormFields()
+		m.updateListFormFocus()
+		return m, nil
+
+	case m.formFocusIndex == 3 && key.Matches(msg, m.keys.Left):
+		m.colorIndex = (m.colorIndex - 1 + len(listColors)) % len(listColors)
+		return m, nil
+
+	case m.formFocusIndex == 3 && key.Matches(msg, m.keys.Right):
+		m.colorIndex = (m.colorIndex + 1) % len(listColors)
+		return m, nil
+
+	case m.formFocusIndex == 4 && key.Matches(msg, m.keys.Left):
+		m.templateIndex = (m.templateIndex - 1 + len(m.app.Templates) + 1) % (len(m.app.Templates) + 1)
+		return m, nil
+
+	case m.formFocusIndex == 4 && key.Matches(msg, m.keys.Right):
+		m.templateIndex = (m.templateIndex + 1) % (len(m.app.Templates) + 1)
 		return m, nil
 
 	case key.Matches(msg, m.keys.Enter):
-		if m.titleInput.Value() == "" {
-			m.showMessageWithType("Title is required", "warning")
+		if !m.submitForm(m.listFormChecks(), m.updateListFormFocus) {
 			return m, nil
 		}
 
+		listID := m.currentListID
 		if m.editing {
 			// Update existing list
-			err := m.storage.UpdateTodoList(m.app, m.currentListID, m.titleInput.Value(), m.descriptionInput.Value())
+			err := m.storage.UpdateTodoList(m.app, m.currentListID, m.titleInput.Value(), m.descriptionInput.Value(),
+				listColors[m.colorIndex].color.Dark)
 			if err != nil {
-				m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+				m.showListError(err)
 				return m, nil
 			}
+			m.updateTasksList()
 			m.showMessageWithType("List updated successfully", "success")
+		} else if m.templateIndex > 0 {
+			// Create new list from the picked template
+			template := m.app.Templates[m.templateIndex-1]
+			id, err := m.storage.CreateListFromTemplate(m.app, template.ID, m.titleInput.Value(), m.descriptionInput.Value(),
+				listColors[m.colorIndex].color.Dark)
+			if err != nil {
+				m.showListError(err)
+				return m, nil
+			}
+			listID = id
+			m.showMessageWithType(fmt.Sprintf("List created from template \"%s\"", template.Name), "success")
 		} else {
 			// Create new list
-			m.storage.CreateTodoList(m.app, m.titleInput.Value(), m.descriptionInput.Value())
+			id, err := m.storage.CreateTodoList(m.app, m.titleInput.Value(), m.descriptionInput.Value(),
+				listColors[m.colorIndex].color.Dark)
+			if err != nil {
+				m.showListError(err)
+				return m, nil
+			}
+			listID = id
 			m.showMessageWithType("List created successfully", "success")
 		}
+		m.warnDuplicateListName(listID, m.titleInput.Value())
+
+		if todoList := m.getList(listID); todoList != nil {
+			if group := strings.TrimSpace(m.groupInput.Value()); group != todoList.Group {
+				if err := m.storage.SetListGroup(m.app, listID, group); err != nil {
+					m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+				}
+			}
+		}
 
 		m.updateTodoListsList()
 		m.state = ListsView
@@ -529,6 +1160,8 @@ func (m *Model) updateListForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.titleInput, cmd = m.titleInput.Update(msg)
 	case 1:
 		m.descriptionInput, cmd = m.descriptionInput.Update(msg)
+	case 2:
+		m.groupInput, cmd = m.groupInput.Update(msg)
 	}
 
 	return m, cmd
@@ -562,56 +1195,85 @@ func (m *Model) renderListForm() string {
 
 // Task form - now works with form window overlay
 func (m *Model) updateTaskForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.datePicking {
+		var cmd tea.Cmd
+		m.datePicker, cmd = m.datePicker.Update(msg)
+		return m, cmd
+	}
+
 	switch {
-	case key.Matches(msg, m.keys.Back):
-		m.state = TasksView
-		m.layout.SetFocus(MainWindow)
+	case m.formFocusIndex == 2 && key.Matches(msg, m.keys.DatePicker):
+		m.openDatePicker()
 		return m, nil
 
+	case key.Matches(msg, m.keys.Back):
+		return m, m.cancelForm(func() {
+			m.state = TasksView
+			m.layout.SetFocus(MainWindow)
+		})
+
 	case key.Matches(msg, m.keys.Tab):
-		m.formFocusIndex = (m.formFocusIndex + 1) % 3
+		m.formFocusIndex = (m.formFocusIndex + 1) % taskFormFields
 		m.updateFormFocus()
 		return m, nil
 
 	case key.Matches(msg, m.keys.ShiftTab):
-		m.formFocusIndex = (m.formFocusIndex - 1 + 3) % 3
+		m.formFocusIndex = (m.formFocusIndex - 1 + taskFormFields) % taskFormFields
 		m.updateFormFocus()
 		return m, nil
 
+	case m.formFocusIndex == 3 && key.Matches(msg, m.keys.Left):
+		m.labelIndex = (m.labelIndex - 1 + len(taskLabels)) % len(taskLabels)
+		return m, nil
+
+	case m.formFocusIndex == 3 && key.Matches(msg, m.keys.Right):
+		m.labelIndex = (m.labelIndex + 1) % len(taskLabels)
+		return m, nil
+
 	case key.Matches(msg, m.keys.Enter):
-		if m.titleInput.Value() == "" {
-			m.showMessageWithType("Title is required", "warning")
+		if !m.submitForm(m.taskFormChecks(), m.updateFormFocus) {
 			return m, nil
 		}
 
-		var deadline *time.Time
-		if m.deadlineInput.Value() != "" {
-			if parsed, err := time.Parse("2006-01-02 15:04", m.deadlineInput.Value()); err != nil {
-				m.showMessageWithType("Invalid deadline format (use YYYY-MM-DD HH:MM)", "warning")
-				return m, nil
-			} else {
-				deadline = &parsed
-			}
-		}
+		// The deadline has passed validation, so it parses
+		deadline, _ := models.ParseDeadline(m.deadlineInput.Value(), dateLayout)
 
 		if m.editing {
+			// Only a changed deadline is checked for overloading its day
+			changed := deadline
+			message := "Task updated successfully"
+			if task := m.getTask(m.editingTaskID); task != nil {
+				if sameDeadline(task.Deadline, deadline) {
+					changed = nil
+				} else if deadline == nil {
+					message = "Task updated - deadline removed"
+				}
+			}
+
 			// Update existing task
-			err := m.storage.UpdateTask(m.app, m.currentListID, m.editingTaskID,
-				m.titleInput.Value(), m.descriptionInput.Value(), models.Medium, deadline)
+			before, found := m.taskSnapshot(m.editingTaskID)
+			task, err := m.storage.UpdateTask(m.app, m.currentListID, m.editingTaskID,
+				m.titleInput.Value(), m.descriptionInput.Value(), m.editingPriority, deadline, taskLabels[m.labelIndex])
 			if err != nil {
 				m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
 				return m, nil
 			}
-			m.showMessageWithType("Task updated successfully", "success")
+			if found {
+				m.recordEdit(m.currentListID, before, task)
+			}
+			m.putTask(m.currentListID, task)
+			m.showDeadlineSaved(message, changed)
 		} else {
 			// Create new task
-			_, err := m.storage.CreateTask(m.app, m.currentListID,
-				m.titleInput.Value(), m.descriptionInput.Value(), models.Medium, deadline)
+			task, err := m.storage.CreateTask(m.app, m.currentListID,
+				m.titleInput.Value(), m.descriptionInput.Value(), models.Medium, deadline, taskLabels[m.labelIndex], models.SourceTUI)
 			if err != nil {
 				m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
 				return m, nil
 			}
-			m.showMessageWithType("Task created successfully", "success")
+			m.putTask(m.currentListID, task)
+			m.showDeadlineSaved("Task created successfully", deadline)
+			m.taskDraft = nil
 		}
 
 		m.updateTasksList()
@@ -676,7 +1338,369 @@ func (m *Model) updateFormFocus() {
 		m.titleInput.Blur()
 		m.descriptionInput.Blur()
 		m.deadlineInput.Focus()
+	default:
+		m.titleInput.Blur()
+		m.descriptionInput.Blur()
+		m.deadlineInput.Blur()
+	}
+}
+
+// listFormFields returns the number of focusable fields in the list form; new
+// lists get a template picker once templates exist
+func (m *Model) listFormFields() int {
+	if !m.editing && len(m.app.Templates) > 0 {
+		return 5
 	}
+	return 4
+}
+
+// updateListFormFocus focuses the list form input at formFocusIndex; the color
+// picker has no text input of its own
+func (m *Model) updateListFormFocus() {
+	m.updateFormFocus()
+	m.deadlineInput.Blur()
+	if m.formFocusIndex == 2 {
+		m.groupInput.Focus()
+	} else {
+		m.groupInput.Blur()
+	}
+}
+
+// labelIndexOf returns the picker position of a label, or none if it is not offered
+func labelIndexOf(label string) int {
+	for i, candidate := range taskLabels {
+		if candidate == label {
+			return i
+		}
+	}
+	return 0
+}
+
+// Task detail view
+func (m *Model) updateTaskDetailView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	task := m.getDetailTask()
+	if task == nil {
+		m.state = TasksView
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = TasksView
+		return m, nil
+
+	case key.Matches(msg, m.keys.AddNote):
+		m.noteInput.SetValue("")
+		m.noteInput.Focus()
+		m.state = AddNoteView
+		return m, nil
+
+	case key.Matches(msg, m.keys.EditTime):
+		m.openTimeForm()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Timer):
+		return m, m.toggleTimer(task.ID)
+
+	case key.Matches(msg, m.keys.Star):
+		if _, ok := m.setStarred(m.currentListID, *task, !task.Starred); ok {
+			return m, m.saveData()
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.SetLink):
+		m.openLinkForm()
+		return m, nil
+
+	case key.Matches(msg, m.keys.SetReminder):
+		m.openReminderForm()
+		return m, nil
+
+	case key.Matches(msg, m.keys.OpenLink):
+		return m, m.openTaskLink(task)
+
+	case key.Matches(msg, m.keys.OpenURL):
+		return m, m.openTaskURL(task)
+
+	case key.Matches(msg, m.keys.Up):
+		if m.noteCursor > 0 {
+			m.noteCursor--
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down):
+		if m.noteCursor < len(task.Notes)-1 {
+			m.noteCursor++
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Delete):
+		notes := notesNewestFirst(task.Notes)
+		if m.noteCursor >= len(notes) {
+			return m, nil
+		}
+		if err := m.storage.DeleteNote(m.app, m.currentListID, task.ID, notes[m.noteCursor].ID); err != nil {
+			m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+			return m, nil
+		}
+		if m.noteCursor > 0 && m.noteCursor >= len(task.Notes) {
+			m.noteCursor--
+		}
+		m.showMessageWithType("Note deleted", "success")
+		return m, m.saveData()
+	}
+
+	return m, nil
+}
+
+// Note form - one-line input appended to the detail task
+func (m *Model) updateNoteForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.noteInput.Blur()
+		m.state = TaskDetailView
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		body := strings.TrimSpace(m.noteInput.Value())
+		if body == "" {
+			m.showMessageWithType("Note is empty", "warning")
+			return m, nil
+		}
+
+		if _, err := m.storage.AddNote(m.app, m.currentListID, m.detailTaskID, body); err != nil {
+			m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+			return m, nil
+		}
+
+		m.noteInput.Blur()
+		m.noteCursor = 0
+		m.state = TaskDetailView
+		m.showMessageWithType("Note added", "success")
+		return m, m.saveData()
+	}
+
+	var cmd tea.Cmd
+	m.noteInput, cmd = m.noteInput.Update(msg)
+	return m, cmd
+}
+
+// duplicateSelectedTask adds an open copy of the selected task right after it
+// and selects the copy
+func (m *Model) duplicateSelectedTask() tea.Cmd {
+	item, ok := m.tasksList.SelectedItem().(taskItem)
+	if !ok {
+		m.showMessageWithType("Select a task first", "warning")
+		return nil
+	}
+
+	duplicate, err := m.storage.DuplicateTask(m.app, m.currentListID, item.id)
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return nil
+	}
+	if todoList := m.getCurrentList(); todoList != nil {
+		todoList.PutTaskAfter(duplicate, item.id)
+	}
+
+	m.updateTasksList()
+	selectItem(&m.tasksList, duplicate.ID)
+	m.showMessageWithType(fmt.Sprintf("Duplicated '%s'", duplicate.Title), "success")
+	return m.saveData()
+}
+
+// openDeadlinePrompt opens the quick deadline prompt for the selected task,
+// pre-filled with its current deadline. It reports whether a task was selected.
+func (m *Model) openDeadlinePrompt() bool {
+	selected := m.tasksList.SelectedItem()
+	if selected == nil {
+		return false
+	}
+	item, ok := selected.(taskItem)
+	if !ok {
+		return false
+	}
+
+	m.editingTaskID = item.id
+	m.snoozing = false
+	m.bulkDeadline = false
+	m.deadlineInput.SetValue("")
+	if item.deadline != nil {
+		m.deadlineInput.SetValue(formatDeadline(*item.deadline))
+	}
+	m.deadlineInput.CursorEnd()
+	m.deadlineInput.Focus()
+	m.state = SetDeadlineView
+	return true
+}
+
+// Deadline prompt - changes only the deadline of a task, leaving other fields as they are
+func (m *Model) updateDeadlineForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.datePicking {
+		var cmd tea.Cmd
+		m.datePicker, cmd = m.datePicker.Update(msg)
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.DatePicker):
+		m.openDatePicker()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Back):
+		m.deadlineInput.Blur()
+		m.state = TasksView
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		deadline, err := models.ParseDeadline(m.deadlineInput.Value(), dateLayout)
+		if err != nil {
+			m.showMessageWithType("Invalid deadline format (e.g. "+deadlineExample()+")", "warning")
+			return m, nil
+		}
+
+		if m.bulkDeadline {
+			m.deadlineInput.Blur()
+			return m, m.setMarkedDeadline(deadline)
+		}
+
+		task := m.getTask(m.editingTaskID)
+		if task == nil {
+			m.deadlineInput.Blur()
+			m.state = TasksView
+			return m, nil
+		}
+
+		if m.snoozing {
+			if deadline == nil {
+				m.showMessageWithType("Enter the new deadline", "warning")
+				return m, nil
+			}
+			m.deadlineInput.Blur()
+			return m, m.snoozeTask(task, *deadline)
+		}
+
+		before := *task
+		updated, err := m.storage.UpdateTask(m.app, m.currentListID, task.ID,
+			task.Title, task.Description, task.Priority, deadline, task.Label)
+		if err != nil {
+			m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+			return m, nil
+		}
+		m.recordEdit(m.currentListID, before, updated)
+		m.putTask(m.currentListID, updated)
+
+		m.deadlineInput.Blur()
+		m.updateTasksList()
+		m.state = TasksView
+		if deadline == nil {
+			m.showMessageWithType("Deadline cleared", "success")
+		} else {
+			m.showDeadlineSaved("Deadline set to "+formatDeadline(*deadline), deadline)
+		}
+		return m, m.saveData()
+	}
+
+	var cmd tea.Cmd
+	m.deadlineInput, cmd = m.deadlineInput.Update(msg)
+	return m, cmd
+}
+
+// openActivityFeed loads the most recent changes and shows them in the main window
+func (m *Model) openActivityFeed() {
+	activity, err := m.storage.RecentActivity(m.app, activityFeedLimit)
+	if err != nil {
+		m.showMessageWithType(fmt.Sprintf("Error: %v", err), "error")
+		return
+	}
+
+	m.activity = activity
+	m.activityCursor = 0
+	m.state = ActivityView
+	m.layout.SetFocus(MainWindow)
+}
+
+// Activity feed - browse recent changes and jump to the selected one
+func (m *Model) updateActivityView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = TasksView
+		return m, nil
+
+	case key.Matches(msg, m.keys.Up):
+		if m.activityCursor > 0 {
+			m.activityCursor--
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down):
+		if m.activityCursor < len(m.activity)-1 {
+			m.activityCursor++
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		if m.activityCursor < len(m.activity) {
+			entry := m.activity[m.activityCursor]
+			m.jumpToTask(entry.ListID, entry.TaskID)
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// jumpToTask opens a list and selects one of its tasks; an empty taskID just opens
+// the list. Tasks hidden from the list, such as completed ones, are opened in the
+// detail view.
+func (m *Model) jumpToTask(listID, taskID string) {
+	found := false
+	for _, todoList := range m.app.TodoLists {
+		if todoList.ID == listID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		m.showMessageWithType("List no longer exists", "warning")
+		return
+	}
+
+	m.switchToList(listID)
+	if taskID == "" {
+		return
+	}
+
+	if selectItem(&m.tasksList, taskID) {
+		return
+	}
+	if m.tasksList.FilterState() != list.Unfiltered {
+		m.tasksList.ResetFilter()
+		if selectItem(&m.tasksList, taskID) {
+			return
+		}
+	}
+
+	if m.getTask(taskID) != nil {
+		m.detailTaskID = taskID
+		m.noteCursor = 0
+		m.state = TaskDetailView
+		return
+	}
+	m.showMessageWithType("Task no longer exists", "warning")
+}
+
+// notesNewestFirst returns a copy of notes ordered from most to least recent
+func notesNewestFirst(notes []models.Note) []models.Note {
+	// Start from reverse insertion order so notes with equal timestamps stay newest-first
+	sorted := make([]models.Note, len(notes))
+	for i, note := range notes {
+		sorted[len(notes)-1-i] = note
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+	return sorted
 }
 
 // Settings view
@@ -686,11 +1710,149 @@ func (m *Model) updateSettingsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.state = TasksView
 		m.layout.SetFocus(MainWindow)
 		return m, nil
+
+	case key.Matches(msg, m.keys.Up):
+		if m.settingsCursor > 0 {
+			m.settingsCursor--
+		}
+
+	case key.Matches(msg, m.keys.Down):
+		if m.settingsCursor < settingsEditable-1 {
+			m.settingsCursor++
+		}
+
+	case key.Matches(msg, m.keys.Enter) && m.settingsCursor == settingMaintenance:
+		return m, m.startMaintenance()
+
+	case key.Matches(msg, m.keys.Left, m.keys.Right) && m.settingsCursor != settingMaintenance:
+		step := 1
+		if key.Matches(msg, m.keys.Left) {
+			step = -1
+		}
+		switch m.settingsCursor {
+		case settingIcons:
+			m.app.Settings.Icons = cycleName(IconSetNames, m.app.Settings.Icons, step)
+		case settingDateFormat:
+			m.app.Settings.DateFormat = cycleName(models.DateFormatNames, m.app.Settings.DateFormat, step)
+		case settingDesktopNotify:
+			m.app.Settings.DesktopNotify = !m.app.Settings.DesktopNotify
+		case settingTrashDays:
+			m.app.Settings.TrashDays = nextTrashDays(m.app.Settings.TrashDays, step)
+		case settingDayTaskLimit:
+			m.app.Settings.DayTaskLimit = nextDayTaskLimit(m.app.Settings.DayTaskLimit, step)
+		case settingDueSoon:
+			m.app.Settings.DueSoonHours = nextDueSoonHours(m.app.Settings.DueSoonHours, step)
+			m.recountDueSoon()
+		case settingReviewDay:
+			m.app.Settings.ReviewDay = nextReviewDay(m.app.Settings.ReviewDay, step)
+			m.reviewScheduleChanged()
+		case settingReviewHour:
+			m.app.Settings.ReviewHour = nextReviewHour(m.app.Settings.ReviewHour, step)
+			m.reviewScheduleChanged()
+		case settingUniqueListNames:
+			m.app.Settings.UniqueListNames = !m.app.Settings.UniqueListNames
+		case settingQuietHours:
+			m.app.Settings.QuietHours = cycleName(quietHoursChoices, m.app.Settings.QuietHours, step)
+		case settingShowHeatmap:
+			m.app.Settings.ShowHeatmap = !m.app.Settings.ShowHeatmap
+			m.refreshHeatmap()
+		case settingStaleDays:
+			m.app.Settings.StaleDays = nextStaleDays(m.app.Settings.StaleDays, step)
+		case settingCompletedLinger:
+			m.app.Settings.CompletedLinger = nextCompletedLinger(m.app.Settings.CompletedLinger, step)
+		case settingCriticalRepeat:
+			m.app.Settings.CriticalRepeatMinutes = nextCriticalRepeat(m.app.Settings.CriticalRepeatMinutes, step)
+		case settingCompleteAtFullProgress:
+			m.app.Settings.CompleteAtFullProgress = !m.app.Settings.CompleteAtFullProgress
+		case settingBulkConfirm:
+			m.app.Settings.BulkConfirmThreshold = nextBulkConfirmThreshold(m.app.Settings.BulkConfirmThreshold, step)
+		case settingWelcomeBack:
+			m.app.Settings.WelcomeBackHours = nextWelcomeBackHours(m.app.Settings.WelcomeBackHours, step)
+		case settingClearStars:
+			m.app.Settings.ClearStarsDaily = !m.app.Settings.ClearStarsDaily
+			m.starsClearedDay = "" // Cleared on the next reminder check
+		case settingPurgeCompleted:
+			m.app.Settings.PurgeCompletedDays = nextPurgeCompletedDays(m.app.Settings.PurgeCompletedDays, step)
+		}
+		m.applyDisplaySettings()
+		m.updateTodoListsList()
+		m.updateTasksList()
+
+		if m.readOnly {
+			m.showMessageWithType("Read-only mode: setting applies to this session only", "warning")
+			return m, nil
+		}
+		m.writeConfigSetting(m.settingsCursor)
+		return m, m.saveData()
 	}
 
 	return m, nil
 }
 
+// Settings that can be changed in the settings view, in display order
+const (
+	settingIcons = iota
+	settingDateFormat
+	settingDesktopNotify
+	settingTrashDays
+	settingDayTaskLimit
+	settingDueSoon
+	settingReviewDay
+	settingReviewHour
+	settingUniqueListNames
+	settingQuietHours
+	settingShowHeatmap
+	settingStaleDays
+	settingCompletedLinger
+	settingCriticalRepeat
+	settingCompleteAtFullProgress
+	settingBulkConfirm
+	settingWelcomeBack
+	settingClearStars
+	settingPurgeCompleted
+	settingMaintenance // Not a setting: Enter runs database maintenance
+	settingsEditable
+)
+
+// cycleName returns the name step places away from current, wrapping around;
+// an unknown current value starts from the first name
+func cycleName(names []string, current string, step int) string {
+	for i, name := range names {
+		if name == current {
+			return names[(i+step+len(names))%len(names)]
+		}
+	}
+	return names[0]
+}
+
+// useIconSet activates the named icon set unless ascii markers are forced
+func (m *Model) useIconSet(name string) {
+	if m.opts.ASCII {
+		name = "ascii"
+	}
+	SetIconSet(name)
+}
+
+// iconsLabel describes the icons setting, noting when ascii is forced over it
+func iconsLabel(name string, forced bool) string {
+	if forced && name != "ascii" {
+		return fmt.Sprintf("%s (ascii forced by --ascii or %s)", name, ASCIIEnv)
+	}
+	return name
+}
+
+// applyDisplaySettings activates the configured icon set and date format and
+// refreshes the titles that are set once
+func (m *Model) applyDisplaySettings() {
+	m.useIconSet(m.app.Settings.Icons)
+	dateLayout = models.ResolveDateFormat(m.app.Settings.DateFormat)
+	m.layout.SetWindowTitle(SidebarWindow, withIcon(icons.Lists, "Todo Lists"))
+	m.layout.SetWindowTitle(HelpWindow, withIcon(icons.Help, "Help"))
+	m.layout.SetSidebarWidth(m.app.Settings.SidebarWidth)
+	m.updateListDimensions()
+	m.updateHelpContent()
+}
+
 func (m *Model) renderSettingsView() string {
 	settings := []string{
 		fmt.Sprintf("Reminder Minutes: %d", m.app.Settings.ReminderMinutes),
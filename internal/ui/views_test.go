@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// press sends a key to the model as the terminal would
+func press(m *Model, keys ...tea.KeyMsg) {
+	for _, k := range keys {
+		m.Update(k)
+	}
+}
+
+var (
+	keyDown  = tea.KeyMsg{Type: tea.KeyDown}
+	keySpace = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+)
+
+// selectedTaskTitle returns the title of the task under the cursor
+func selectedTaskTitle(m *Model) string {
+	if item, ok := m.tasksList.SelectedItem().(taskItem); ok {
+		return item.title
+	}
+	return ""
+}
+
+func TestTaskSelectionSurvivesListUpdates(t *testing.T) {
+	m := newTestModel(t, &testClock{now: time.Now()})
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.app.Settings.ShowCompleted = true
+	for i := range 10 {
+		mustAddTask(t, m, fmt.Sprintf("Task %d", i), nil)
+	}
+	m.switchToList(m.currentListID)
+
+	press(m, keyDown, keyDown, keyDown, keyDown)
+	if got := selectedTaskTitle(m); got != "Task 4" {
+		t.Fatalf("selected after moving down = %q, want Task 4", got)
+	}
+
+	// Completing tasks one after another works down the list
+	press(m, keySpace)
+	if got := selectedTaskTitle(m); got != "Task 4" {
+		t.Errorf("selected after toggling = %q, want Task 4", got)
+	}
+	press(m, keyDown, keySpace)
+	if got := selectedTaskTitle(m); got != "Task 5" {
+		t.Errorf("selected after toggling the next one = %q, want Task 5", got)
+	}
+
+	// A task deleted elsewhere leaves the cursor on its neighbor
+	item := m.tasksList.SelectedItem().(taskItem)
+	if err := m.storage.DeleteTask(m.app, m.currentListID, item.id); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	m.getCurrentList().RemoveTask(item.id)
+	m.updateTasksList()
+	if got := selectedTaskTitle(m); got != "Task 6" {
+		t.Errorf("selected after deleting Task 5 = %q, want Task 6", got)
+	}
+}
+
+func TestListSelectionSurvivesSidebarUpdates(t *testing.T) {
+	m := newTestModel(t, &testClock{now: time.Now()})
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	var ids []string
+	for _, name := range []string{"Work", "Home", "Errands"} {
+		id, err := m.storage.CreateTodoList(m.app, name, "", "")
+		if err != nil {
+			t.Fatalf("CreateTodoList: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	m.updateTodoListsList()
+	m.switchToList(ids[1])
+
+	if err := m.storage.UpdateTodoList(m.app, ids[1], "House", "", ""); err != nil {
+		t.Fatalf("UpdateTodoList: %v", err)
+	}
+	m.updateTodoListsList()
+	if item, ok := m.todoListsList.SelectedItem().(listItem); !ok || item.id != ids[1] || item.title != "House" {
+		t.Errorf("selected list after renaming = %+v, want House", m.todoListsList.SelectedItem())
+	}
+}
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+)
+
+// welcomeBackChoices are the hours away the settings view offers before the
+// welcome-back summary shows; -1 turns it off
+var welcomeBackChoices = []int{-1, 4, 12, 24, 72}
+
+// welcomeBackRows is how many tasks each section of the summary lists
+const welcomeBackRows = 5
+
+// nextWelcomeBackHours returns the hours away step places away from hours;
+// hours set outside the choices start from the default
+func nextWelcomeBackHours(hours, step int) int {
+	for i, choice := range welcomeBackChoices {
+		if choice == hours {
+			return welcomeBackChoices[(i+step+len(welcomeBackChoices))%len(welcomeBackChoices)]
+		}
+	}
+	return models.DefaultSettings().WelcomeBackHours
+}
+
+// welcomeBackLabel describes when the welcome-back summary shows, for the settings view
+func welcomeBackLabel(hours int) string {
+	switch {
+	case hours < 0:
+		return "Off"
+	case hours%24 == 0:
+		return fmt.Sprintf("after %d day(s) away", hours/24)
+	default:
+		return fmt.Sprintf("after %d hours away", hours)
+	}
+}
+
+// welcomeBack is the summary shown at startup after some time away
+type welcomeBack struct {
+	digest models.Digest
+	since  time.Time // When the previous session started
+	back   ViewState // The view closing it returns to
+}
+
+// openWelcomeBack records when this session started and, when the last one
+// started longer ago than the welcome_back_hours setting, shows what happened
+// since in the welcome-back overlay. It returns the save that keeps the time.
+func (m *Model) openWelcomeBack() tea.Cmd {
+	now := m.now()
+	since, err := time.Parse(time.RFC3339, m.app.Settings.LastOpenedAt)
+	hours := m.app.Settings.WelcomeBackHours
+	if err == nil && hours >= 0 && now.Sub(since) >= time.Duration(hours)*time.Hour && !m.needsSetup() {
+		if digest, err := m.storage.WelcomeDigest(m.app, since, now); err == nil && !digest.Empty() {
+			m.welcome = welcomeBack{digest: digest, since: since, back: m.state}
+			m.state = WelcomeView
+		}
+	}
+
+	if m.readOnly {
+		return nil
+	}
+	m.app.Settings.LastOpenedAt = now.Format(time.RFC3339)
+	return m.saveData()
+}
+
+// Welcome-back overlay - any key closes it
+func (m *Model) updateWelcomeView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.state = m.welcome.back
+	m.welcome = welcomeBack{}
+	return m, nil
+}
+
+// renderWelcomeContent renders the welcome-back summary, a few tasks per section
+func (m *Model) renderWelcomeContent() string {
+	m.layout.SetWindowTitle(FormWindow, withIcon(icons.App, "Welcome Back"))
+	digest := m.welcome.digest
+
+	var lines []string
+	lines = append(lines, BaseTitleStyle.Render("Welcome back!"))
+	lines = append(lines, BaseSubtitleStyle.Render("Your last session started "+formatRelativeTime(m.welcome.since)))
+
+	mutedStyle := lipgloss.NewStyle().Foreground(TextMuted)
+	section := func(icon, heading string, tasks []models.ListTask, describe func(models.Task) string) {
+		if len(tasks) == 0 {
+			return
+		}
+		lines = append(lines, "")
+		lines = append(lines, lipgloss.NewStyle().Bold(true).Foreground(TextPrimary).Render(withIcon(icon, fmt.Sprintf("%s (%d)", heading, len(tasks)))))
+		for i, entry := range tasks {
+			if i == welcomeBackRows {
+				lines = append(lines, mutedStyle.Render(fmt.Sprintf("  …and %d more", len(tasks)-welcomeBackRows)))
+				break
+			}
+			lines = append(lines, "  "+describe(entry.Task)+" "+entry.Task.Title+mutedStyle.Render(" · "+entry.ListName))
+		}
+	}
+
+	section(icons.Overdue, "Became overdue", digest.Overdue, func(task models.Task) string {
+		return GetDeadlineStyle(true, false).Render(formatDeadline(*task.Deadline))
+	})
+	section(icons.Deadline, "Due today", digest.DueToday, func(task models.Task) string {
+		return GetDeadlineStyle(false, true).Render(task.Deadline.Format("15:04"))
+	})
+	section(icons.Done, "Completed last session", digest.Completed, func(task models.Task) string {
+		return lipgloss.NewStyle().Foreground(SuccessColor).Render(task.CompletedAt.Local().Format("Mon 15:04"))
+	})
+
+	lines = append(lines, "")
+	lines = append(lines, DescStyle.Render("Press any key to continue"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_task_notes_task_id;
+DROP TABLE IF EXISTS task_notes;
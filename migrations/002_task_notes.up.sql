@@ -0,0 +1,10 @@
+-- Create task_notes table
+CREATE TABLE task_notes (
+    id TEXT PRIMARY KEY,
+    task_id TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_notes_task_id ON task_notes(task_id);
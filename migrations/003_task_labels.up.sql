@@ -0,0 +1,2 @@
+-- Add a user-chosen emoji/color label to tasks
+ALTER TABLE tasks ADD COLUMN label TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+-- Let users reorder todo lists; existing lists keep their creation order
+ALTER TABLE todo_lists ADD COLUMN sort_order INTEGER NOT NULL DEFAULT 0;
+
+UPDATE todo_lists SET sort_order = (
+    SELECT COUNT(*) FROM todo_lists AS earlier
+    WHERE earlier.created_at < todo_lists.created_at
+       OR (earlier.created_at = todo_lists.created_at AND earlier.id < todo_lists.id)
+);
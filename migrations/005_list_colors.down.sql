@@ -0,0 +1 @@
+ALTER TABLE todo_lists DROP COLUMN color;
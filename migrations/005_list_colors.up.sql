@@ -0,0 +1,2 @@
+-- Per-list accent color; existing lists get the theme's accent green
+ALTER TABLE todo_lists ADD COLUMN color TEXT NOT NULL DEFAULT '#10B981';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_template_tasks_template_id;
+DROP TABLE IF EXISTS template_tasks;
+DROP TABLE IF EXISTS templates;
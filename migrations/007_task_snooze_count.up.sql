@@ -0,0 +1,2 @@
+-- Count how often a task's deadline has been snoozed
+ALTER TABLE tasks ADD COLUMN snooze_count INTEGER NOT NULL DEFAULT 0;
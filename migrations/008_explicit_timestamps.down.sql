@@ -0,0 +1,13 @@
+CREATE TRIGGER IF NOT EXISTS update_todo_lists_timestamp
+    AFTER UPDATE ON todo_lists
+    FOR EACH ROW
+    BEGIN
+        UPDATE todo_lists SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+    END;
+
+CREATE TRIGGER IF NOT EXISTS update_tasks_timestamp
+    AFTER UPDATE ON tasks
+    FOR EACH ROW
+    BEGIN
+        UPDATE tasks SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+    END;
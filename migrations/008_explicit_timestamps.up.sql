@@ -0,0 +1,3 @@
+-- updated_at is set by the application, so rows merged from an export keep their own timestamps
+DROP TRIGGER IF EXISTS update_todo_lists_timestamp;
+DROP TRIGGER IF EXISTS update_tasks_timestamp;
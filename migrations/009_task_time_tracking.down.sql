@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP COLUMN spent;
+ALTER TABLE tasks DROP COLUMN estimate;
@@ -0,0 +1,3 @@
+-- Planned effort and time tracked per task, in seconds
+ALTER TABLE tasks ADD COLUMN estimate INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN spent INTEGER NOT NULL DEFAULT 0;
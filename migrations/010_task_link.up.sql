@@ -0,0 +1,2 @@
+-- URL or file reference attached to a task
+ALTER TABLE tasks ADD COLUMN link TEXT NOT NULL DEFAULT '';
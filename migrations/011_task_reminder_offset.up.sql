@@ -0,0 +1,2 @@
+-- Per-task reminder lead time in seconds; NULL uses the global setting
+ALTER TABLE tasks ADD COLUMN reminder_offset INTEGER;
@@ -0,0 +1,3 @@
+-- Where each task was created; rows from before this migration are unknown
+ALTER TABLE tasks ADD COLUMN source TEXT NOT NULL DEFAULT 'tui';
+UPDATE tasks SET source = 'unknown';
@@ -0,0 +1,3 @@
+-- Deleted tasks stay in the trash until they are purged
+ALTER TABLE tasks ADD COLUMN deleted_at DATETIME;
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(deleted_at);
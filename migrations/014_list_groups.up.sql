@@ -0,0 +1,2 @@
+-- Lists can be filed under a heading in the sidebar
+ALTER TABLE todo_lists ADD COLUMN group_name TEXT NOT NULL DEFAULT '';
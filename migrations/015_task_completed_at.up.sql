@@ -0,0 +1,3 @@
+-- When each task was last completed; already completed tasks use their last change
+ALTER TABLE tasks ADD COLUMN completed_at DATETIME;
+UPDATE tasks SET completed_at = updated_at WHERE completed = 1;
@@ -0,0 +1,9 @@
+-- Let a task be placed inside its list; existing tasks keep their creation order
+ALTER TABLE tasks ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+
+UPDATE tasks SET position = (
+    SELECT COUNT(*) FROM tasks AS earlier
+    WHERE earlier.list_id = tasks.list_id
+      AND (earlier.created_at < tasks.created_at
+       OR (earlier.created_at = tasks.created_at AND earlier.id < tasks.id))
+);
@@ -0,0 +1,2 @@
+-- Remember per list how its task list is divided into sections
+ALTER TABLE todo_lists ADD COLUMN grouping TEXT NOT NULL DEFAULT '';
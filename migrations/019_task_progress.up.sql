@@ -0,0 +1,2 @@
+-- How far a task is done, in percent
+ALTER TABLE tasks ADD COLUMN progress INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Keep chosen lists at the top of the sidebar
+ALTER TABLE todo_lists ADD COLUMN pinned INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Starred tasks make up a daily plan across lists
+ALTER TABLE tasks ADD COLUMN starred INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,181 @@
+package lazytodo
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+// openBackend prepares an empty data directory for the named backend:
+// "database", or "json" for a v1.x JSON file kept in use with KeepJSON
+func openBackend(t *testing.T, backend string) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Cleanup(func() { storage.SetDataDir("") })
+	if backend == "json" {
+		storage.SetDataDir(dir)
+		if err := os.WriteFile(storage.LegacyJSONPath(), []byte(`{"todo_lists": []}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := storage.KeepJSON(); err != nil {
+			t.Fatalf("KeepJSON: %v", err)
+		}
+	}
+	return dir
+}
+
+// openStorage opens the data in dir through the storage layer, as the TUI
+// does, and loads every list's tasks
+func openStorage(t *testing.T, dir string) (storage.StorageInterface, *models.Application) {
+	t.Helper()
+	storage.SetDataDir(dir)
+	store, err := storage.NewWithMigration(storage.Options{Output: io.Discard})
+	if err != nil {
+		t.Fatalf("NewWithMigration: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	app, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for _, list := range app.TodoLists {
+		if err := store.LoadTasks(app, list.ID); err != nil {
+			t.Fatalf("LoadTasks: %v", err)
+		}
+	}
+	return store, app
+}
+
+// storedList returns the list with ID listID of app, or nil
+func storedList(app *models.Application, listID string) *models.TodoList {
+	for i := range app.TodoLists {
+		if app.TodoLists[i].ID == listID {
+			return &app.TodoLists[i]
+		}
+	}
+	return nil
+}
+
+// sameTime reports whether two optional times are both unset or equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// checkTask compares a task the client returned with the copy in storage
+func checkTask(t *testing.T, got Task, listID string, want *models.Task) {
+	t.Helper()
+	if want == nil {
+		t.Errorf("task %q is not in storage", got.Title)
+		return
+	}
+	if got.ID != want.ID || got.ListID != listID || got.Title != want.Title || got.Description != want.Description ||
+		got.Completed != want.Completed || got.Priority != Priority(want.Priority) || got.Label != want.Label ||
+		got.Source != want.Source || !sameTime(got.Deadline, want.Deadline) || !sameTime(got.CompletedAt, want.CompletedAt) ||
+		!got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("client task\n%+v\ndiffers from the stored one\n%+v", got, *want)
+	}
+}
+
+// TestClientMatchesStorage checks that the client and the storage layer the
+// TUI works on agree about the same data, whichever backend holds it
+func TestClientMatchesStorage(t *testing.T) {
+	for _, backend := range []string{"json", "database"} {
+		t.Run(backend, func(t *testing.T) {
+			dir := openBackend(t, backend)
+
+			// Changes made through the client are what storage loads
+			client, err := Open(dir, Options{})
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			work, err := client.CreateList("Work", "Day job")
+			if err != nil {
+				t.Fatalf("CreateList: %v", err)
+			}
+			deadline := time.Date(2026, time.October, 20, 17, 0, 0, 0, time.UTC)
+			report, err := client.CreateTask(work.ID, NewTask{Title: " Write report ", Description: "Q3", Priority: PriorityHigh, Deadline: &deadline, Label: "🔴"})
+			if err != nil {
+				t.Fatalf("CreateTask: %v", err)
+			}
+			call, err := client.CreateTask(work.ID, NewTask{Title: "Call back"})
+			if err != nil {
+				t.Fatalf("CreateTask: %v", err)
+			}
+			if call, err = client.CompleteTask(work.ID, call.ID); err != nil {
+				t.Fatalf("CompleteTask: %v", err)
+			}
+			if err := client.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			store, app := openStorage(t, dir)
+			if backend == "json" && storage.GetStorageInfo(store) != "JSON File: "+storage.LegacyJSONPath() {
+				t.Fatalf("storage opened %s, want the JSON file", storage.GetStorageInfo(store))
+			}
+			stored := storedList(app, work.ID)
+			if len(app.TodoLists) != 1 || stored == nil || stored.Name != "Work" || stored.Description != "Day job" {
+				t.Fatalf("storage holds %d lists, want the Work list created through the client", len(app.TodoLists))
+			}
+			if len(stored.Tasks) != 2 {
+				t.Fatalf("storage holds %d tasks in Work, want 2", len(stored.Tasks))
+			}
+			checkTask(t, report, work.ID, findTask(stored, report.ID))
+			checkTask(t, call, work.ID, findTask(stored, call.ID))
+			if task := findTask(stored, report.ID); task != nil && task.Source != models.SourceAPI {
+				t.Errorf("source = %q, want %q", task.Source, models.SourceAPI)
+			}
+
+			// Changes made through storage are what the client reads
+			homeID, err := store.CreateTodoList(app, "Home", "", "")
+			if err != nil {
+				t.Fatalf("CreateTodoList: %v", err)
+			}
+			plumber, err := store.CreateTask(app, homeID, "Call plumber", "", models.Critical, nil, "", models.SourceTUI)
+			if err != nil {
+				t.Fatalf("CreateTask: %v", err)
+			}
+			storedList(app, homeID).PutTask(plumber)
+			if err := store.Save(app); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			if err := store.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			client, err = Open(dir, Options{})
+			if err != nil {
+				t.Fatalf("Open again: %v", err)
+			}
+			defer client.Close()
+			_, app = openStorage(t, dir)
+			lists := client.Lists()
+			if len(lists) != len(app.TodoLists) {
+				t.Fatalf("client has %d lists, storage %d", len(lists), len(app.TodoLists))
+			}
+			for i, list := range lists {
+				want := &app.TodoLists[i]
+				if list.ID != want.ID || list.Name != want.Name || list.Tasks != want.GetTotalCount() || list.Completed != want.GetCompletedCount() {
+					t.Errorf("client list %d = %+v, storage has %s with %d tasks, %d completed", i, list, want.Name, want.GetTotalCount(), want.GetCompletedCount())
+				}
+				tasks, err := client.Tasks(list.ID)
+				if err != nil {
+					t.Fatalf("Tasks(%s): %v", list.Name, err)
+				}
+				if len(tasks) != len(want.Tasks) {
+					t.Errorf("client has %d tasks in %s, storage %d", len(tasks), list.Name, len(want.Tasks))
+					continue
+				}
+				for j := range tasks {
+					checkTask(t, tasks[j], list.ID, &want.Tasks[j])
+				}
+			}
+		})
+	}
+}
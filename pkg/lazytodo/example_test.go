@@ -0,0 +1,49 @@
+package lazytodo_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/DhirajZope/lazytodo/pkg/lazytodo"
+)
+
+func Example() {
+	dir, err := os.MkdirTemp("", "lazytodo")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	client, err := lazytodo.Open(dir, lazytodo.Options{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	list, err := client.CreateList("Work", "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	report, err := client.CreateTask(list.ID, lazytodo.NewTask{Title: "File the weekly report", Priority: lazytodo.PriorityHigh})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := client.CreateTask(list.ID, lazytodo.NewTask{Title: "Book the team lunch"}); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := client.CompleteTask(list.ID, report.ID); err != nil {
+		log.Fatal(err)
+	}
+
+	tasks, err := client.Tasks(list.ID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, task := range tasks {
+		fmt.Printf("%-22s %-6s done: %v\n", task.Title, task.Priority, task.Completed)
+	}
+	// Output:
+	// File the weekly report High   done: true
+	// Book the team lunch    Low    done: false
+}
@@ -0,0 +1,368 @@
+// Package lazytodo reads and changes LazyTodo's lists and tasks from other
+// programs, such as a cron job that files recurring tasks or a shell prompt
+// that shows how many tasks are open. It works on the same data as the TUI,
+// through the same storage layer, so it follows the database schema and the
+// v1.x JSON file as they change.
+//
+//	client, err := lazytodo.Open("", lazytodo.Options{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer client.Close()
+//
+//	list, err := client.FindList("Work")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	task, err := client.CreateTask(list.ID, lazytodo.NewTask{Title: "File the weekly report", Priority: lazytodo.PriorityHigh})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println("Created", task.ID)
+//
+// The data is read once, when the client is opened; open a new client to see
+// changes made elsewhere since. Changes are saved as they are made, and a TUI
+// that is running picks them up the next time it loads the data.
+//
+// lazytodo list is built on the client. The TUI is not: it needs far more of
+// the storage layer than the client offers, and works on it directly.
+package lazytodo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/DhirajZope/lazytodo/internal/models"
+	"github.com/DhirajZope/lazytodo/internal/storage"
+)
+
+var (
+	// ErrListNotFound is returned when no list has the given ID
+	ErrListNotFound = errors.New("list not found")
+
+	// ErrTaskNotFound is returned when the list has no task with the given ID
+	ErrTaskNotFound = errors.New("task not found")
+
+	// ErrEmptyTitle is returned when creating a task without a title, or
+	// with one that is only blanks and control characters
+	ErrEmptyTitle = models.ErrEmptyTitle
+
+	// ErrTitleTooLong is returned for a title longer than models.MaxTitleLength characters
+	ErrTitleTooLong = models.ErrTitleTooLong
+
+	// ErrDuplicateListName is returned when creating a list with the name of
+	// one that exists already, while the unique_list_names setting is on
+	ErrDuplicateListName = storage.ErrDuplicateListName
+
+	// ErrReadOnly is returned by changes to a client opened read-only, or to
+	// a data directory that is not writable
+	ErrReadOnly = storage.ErrReadOnly
+
+	// ErrMigrationPending is returned by Open while the data is still in a
+	// v1.x JSON file that has no database yet; run lazytodo --migrate, or
+	// open the client with Options.MigrateJSON
+	ErrMigrationPending = storage.ErrMigrationPending
+)
+
+// Options controls how a client opens the data
+type Options struct {
+	// ReadOnly opens the data without write access; every change returns ErrReadOnly
+	ReadOnly bool
+
+	// Passphrase unlocks an encrypted database
+	Passphrase string
+
+	// MigrateJSON agrees to migrating a v1.x JSON file that has no database
+	// yet, instead of returning ErrMigrationPending
+	MigrateJSON bool
+
+	// Output receives progress and warning messages, such as those of a
+	// migration; they are discarded by default
+	Output io.Writer
+}
+
+// Priority is how pressing a task is
+type Priority int
+
+// Priorities, from least to most pressing
+const (
+	PriorityLow      = Priority(models.Low)
+	PriorityMedium   = Priority(models.Medium)
+	PriorityHigh     = Priority(models.High)
+	PriorityCritical = Priority(models.Critical)
+)
+
+// String names the priority, e.g. "High"
+func (p Priority) String() string {
+	return models.Priority(p).String()
+}
+
+// MarshalJSON writes the priority by name, e.g. "High", as LazyTodo's exports do
+func (p Priority) MarshalJSON() ([]byte, error) {
+	return models.Priority(p).MarshalJSON()
+}
+
+// ParsePriority parses a priority given by name ("high"), by its first letter
+// ("h") or by its number ("2"), ignoring case
+func ParsePriority(value string) (Priority, error) {
+	priority, err := models.ParsePriority(value)
+	return Priority(priority), err
+}
+
+// List is a todo list
+type List struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Color       string    `json:"color,omitempty"` // Accent color as a hex string, e.g. "#3B82F6"
+	Group       string    `json:"group,omitempty"` // Sidebar heading the list is shown under; empty for none
+	Tasks       int       `json:"tasks"`           // Number of tasks, open and completed
+	Completed   int       `json:"completed"`       // Number of completed tasks
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Open returns the number of tasks of the list still to do
+func (l List) Open() int {
+	return l.Tasks - l.Completed
+}
+
+// Task is a task of a list
+type Task struct {
+	ID          string        `json:"id"`
+	ListID      string        `json:"list_id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Completed   bool          `json:"completed"`
+	Priority    Priority      `json:"priority"`
+	Deadline    *time.Time    `json:"deadline,omitempty"`
+	Label       string        `json:"label,omitempty"`    // User-chosen emoji/color marker
+	Link        string        `json:"link,omitempty"`     // URL or file path the task refers to
+	Estimate    time.Duration `json:"estimate,omitempty"` // Planned effort
+	Spent       time.Duration `json:"spent,omitempty"`    // Time tracked so far
+	Progress    int           `json:"progress,omitempty"` // How far the task is done, 0 to 100 percent
+	Starred     bool          `json:"starred,omitempty"`  // Part of the daily plan
+	Source      string        `json:"source,omitempty"`   // Where the task was created, e.g. "tui" or "api"
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty"` // When the task was last completed; nil while open
+}
+
+// Overdue reports whether the task is open and its deadline has passed
+func (t Task) Overdue() bool {
+	return !t.Completed && t.Deadline != nil && models.WallClock(time.Now()).After(*t.Deadline)
+}
+
+// NewTask describes a task to create
+type NewTask struct {
+	Title       string // Required; surrounding spaces are dropped
+	Description string
+	Priority    Priority
+	Deadline    *time.Time // Nil for a task without a deadline
+	Label       string
+}
+
+// Client reads and changes the lists and tasks of a LazyTodo data directory
+type Client struct {
+	storage storage.StorageInterface
+	app     *models.Application
+}
+
+// Open opens the LazyTodo data in dir, or in the directory LazyTodo itself
+// uses ($LAZYTODO_HOME or ~/.lazytodo) when dir is empty. The data directory
+// applies to the whole process, so a program opens one of them at a time.
+// Close the client when done with it.
+func Open(dir string, opts Options) (*Client, error) {
+	output := opts.Output
+	if output == nil {
+		output = io.Discard
+	}
+	storage.SetDataDir(dir)
+
+	storageInstance, err := storage.NewWithMigration(storage.Options{
+		ReadOnly:    opts.ReadOnly,
+		Passphrase:  opts.Passphrase,
+		Output:      output,
+		MigrateJSON: opts.MigrateJSON,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	app, err := storageInstance.Load()
+	if err != nil {
+		storageInstance.Close()
+		return nil, fmt.Errorf("failed to load data: %w", err)
+	}
+	return &Client{storage: storageInstance, app: app}, nil
+}
+
+// Close releases the data; the client cannot be used afterwards
+func (c *Client) Close() error {
+	return c.storage.Close()
+}
+
+// Lists returns every list, in the order of the sidebar
+func (c *Client) Lists() []List {
+	lists := make([]List, len(c.app.TodoLists))
+	for i := range c.app.TodoLists {
+		lists[i] = newList(&c.app.TodoLists[i])
+	}
+	return lists
+}
+
+// FindList finds the list called name, ignoring case, or the one list whose
+// name contains it. The error suggests the lists that were meant when the
+// name matches none or several of them.
+func (c *Client) FindList(name string) (List, error) {
+	todoList, err := storage.ResolveList(c.app, name)
+	if err != nil {
+		return List{}, err
+	}
+	return newList(todoList), nil
+}
+
+// CreateList adds a list to the end of the sidebar and returns it as stored
+func (c *Client) CreateList(name, description string) (List, error) {
+	id, err := c.storage.CreateTodoList(c.app, name, description, "")
+	if err != nil {
+		return List{}, err
+	}
+	if err := c.storage.Save(c.app); err != nil {
+		return List{}, err
+	}
+	todoList, err := c.loadList(id)
+	if err != nil {
+		return List{}, err
+	}
+	return newList(todoList), nil
+}
+
+// Tasks returns the tasks of the list with ID listID, in the list's order
+func (c *Client) Tasks(listID string) ([]Task, error) {
+	todoList, err := c.loadList(listID)
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]Task, len(todoList.Tasks))
+	for i := range todoList.Tasks {
+		tasks[i] = newTask(listID, &todoList.Tasks[i])
+	}
+	return tasks, nil
+}
+
+// CreateTask adds an open task to the end of the list with ID listID and
+// returns it as stored
+func (c *Client) CreateTask(listID string, task NewTask) (Task, error) {
+	title := strings.TrimSpace(task.Title)
+	if title == "" {
+		return Task{}, ErrEmptyTitle
+	}
+	if !models.Priority(task.Priority).Valid() {
+		return Task{}, fmt.Errorf("invalid priority %d: use PriorityLow to PriorityCritical", int(task.Priority))
+	}
+	todoList, err := c.loadList(listID)
+	if err != nil {
+		return Task{}, err
+	}
+
+	created, err := c.storage.CreateTask(c.app, listID, title, strings.TrimSpace(task.Description),
+		models.Priority(task.Priority), task.Deadline, task.Label, models.SourceAPI)
+	if err != nil {
+		return Task{}, err
+	}
+	todoList.PutTask(created)
+	if err := c.storage.Save(c.app); err != nil {
+		return Task{}, err
+	}
+	return newTask(listID, &created), nil
+}
+
+// CompleteTask marks the task with ID taskID of the list with ID listID as
+// completed and returns it as stored. A task completed already is left as it is.
+func (c *Client) CompleteTask(listID, taskID string) (Task, error) {
+	todoList, err := c.loadList(listID)
+	if err != nil {
+		return Task{}, err
+	}
+	task := findTask(todoList, taskID)
+	if task == nil {
+		return Task{}, fmt.Errorf("%w: %s in list %s", ErrTaskNotFound, taskID, listID)
+	}
+	if task.Completed {
+		return newTask(listID, task), nil
+	}
+
+	completed, err := c.storage.ToggleTask(c.app, listID, taskID)
+	if err != nil {
+		return Task{}, err
+	}
+	todoList.PutTask(completed)
+	if err := c.storage.Save(c.app); err != nil {
+		return Task{}, err
+	}
+	return newTask(listID, &completed), nil
+}
+
+// loadList returns the list with ID listID with its tasks loaded
+func (c *Client) loadList(listID string) (*models.TodoList, error) {
+	for i := range c.app.TodoLists {
+		if c.app.TodoLists[i].ID != listID {
+			continue
+		}
+		if err := c.storage.LoadTasks(c.app, listID); err != nil {
+			return nil, fmt.Errorf("failed to load tasks: %w", err)
+		}
+		return &c.app.TodoLists[i], nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrListNotFound, listID)
+}
+
+// findTask returns the task with ID taskID of a loaded list, or nil
+func findTask(todoList *models.TodoList, taskID string) *models.Task {
+	for i := range todoList.Tasks {
+		if todoList.Tasks[i].ID == taskID {
+			return &todoList.Tasks[i]
+		}
+	}
+	return nil
+}
+
+func newList(todoList *models.TodoList) List {
+	return List{
+		ID:          todoList.ID,
+		Name:        todoList.Name,
+		Description: todoList.Description,
+		Color:       todoList.Color,
+		Group:       todoList.Group,
+		Tasks:       todoList.GetTotalCount(),
+		Completed:   todoList.GetCompletedCount(),
+		CreatedAt:   todoList.CreatedAt,
+		UpdatedAt:   todoList.UpdatedAt,
+	}
+}
+
+func newTask(listID string, task *models.Task) Task {
+	return Task{
+		ID:          task.ID,
+		ListID:      listID,
+		Title:       task.Title,
+		Description: task.Description,
+		Completed:   task.Completed,
+		Priority:    Priority(task.Priority),
+		Deadline:    task.Deadline,
+		Label:       task.Label,
+		Link:        task.Link,
+		Estimate:    task.Estimate,
+		Spent:       task.Spent,
+		Progress:    task.Progress,
+		Starred:     task.Starred,
+		Source:      task.Source,
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+		CompletedAt: task.CompletedAt,
+	}
+}
@@ -0,0 +1,72 @@
+package lazytodo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClientErrors(t *testing.T) {
+	client, err := Open(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer client.Close()
+
+	list, err := client.CreateList("Work", "")
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{"tasks of a missing list", func() error {
+			_, err := client.Tasks("missing")
+			return err
+		}, ErrListNotFound},
+		{"task in a missing list", func() error {
+			_, err := client.CreateTask("missing", NewTask{Title: "Call"})
+			return err
+		}, ErrListNotFound},
+		{"completing a missing task", func() error {
+			_, err := client.CompleteTask(list.ID, "missing")
+			return err
+		}, ErrTaskNotFound},
+		{"blank title", func() error {
+			_, err := client.CreateTask(list.ID, NewTask{Title: "  "})
+			return err
+		}, ErrEmptyTitle},
+	}
+	for _, test := range tests {
+		if err := test.call(); !errors.Is(err, test.want) {
+			t.Errorf("%s: error = %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+func TestReadOnlyClient(t *testing.T) {
+	dir := t.TempDir()
+	client, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	list, err := client.CreateList("Work", "")
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	client.Close()
+
+	client, err = Open(dir, Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("Open read-only: %v", err)
+	}
+	defer client.Close()
+	if _, err := client.CreateTask(list.ID, NewTask{Title: "Call"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("CreateTask read-only: error = %v, want %v", err, ErrReadOnly)
+	}
+	if lists := client.Lists(); len(lists) != 1 || lists[0].Name != "Work" {
+		t.Errorf("Lists read-only = %v, want the Work list", lists)
+	}
+}